@@ -180,6 +180,7 @@ func (c *cliApp) handleActions(line string) {
 		{"service", c.service},
 		{"stake", c.stake},
 		{"mmn", c.mmnApiKey},
+		{"ledger", c.ledger},
 	}
 
 	for _, cmd := range staticCmds {
@@ -325,7 +326,7 @@ func (c *cliApp) serviceGet(id string) {
 func (c *cliApp) connect(argsString string) {
 	args := strings.Fields(argsString)
 
-	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [dns=auto|provider|system|1.1.1.1] [disable-kill-switch]"
+	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity>|--best <service-type> [dns=auto|provider|system|1.1.1.1] [country=<code>] [disable-kill-switch]"
 	if len(args) < 3 {
 		info(helpMsg)
 		return
@@ -335,8 +336,13 @@ func (c *cliApp) connect(argsString string) {
 
 	var disableKillSwitch bool
 	var dns connection.DNSOption
+	var country string
 	var err error
 	for _, arg := range args[3:] {
+		if strings.HasPrefix(arg, "country=") {
+			country = strings.TrimPrefix(arg, "country=")
+			continue
+		}
 		if strings.HasPrefix(arg, "dns=") {
 			kv := strings.Split(arg, "=")
 			dns, err = connection.NewDNSOption(kv[1])
@@ -372,6 +378,22 @@ func (c *cliApp) connect(argsString string) {
 		success("New identity created:", consumerID)
 	}
 
+	if providerID == "--best" {
+		proposals, err := c.tequilapi.ProposalsByScore(serviceType, country)
+		if err != nil {
+			warn(err)
+			return
+		}
+		if len(proposals) == 0 {
+			warn("No proposals found for service type:", serviceType)
+			return
+		}
+		providerID = proposals[0].ProviderID
+		if proposals[0].Score != nil {
+			info(fmt.Sprintf("Best proposal scored %.2f", proposals[0].Score.Total))
+		}
+	}
+
 	status("CONNECTING", "from:", consumerID, "to:", providerID)
 
 	hermesID := config.GetString(config.FlagHermesID)
@@ -525,6 +547,9 @@ func (c *cliApp) natStatus() {
 	} else {
 		infof("NAT traversal status: %q (error: %q)\n", status.Status, status.Error)
 	}
+	if status.Type != "" {
+		infof("NAT type: %q (port allocation: %q)\n", status.Type, status.PortAllocation)
+	}
 }
 
 func (c *cliApp) proposals(filter string) {
@@ -661,6 +686,12 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []contract.P
 					readline.PcItem("openvpn", connectOpts...),
 					readline.PcItem("wireguard", connectOpts...),
 				),
+				readline.PcItem(
+					"--best",
+					readline.PcItem("noop", connectOpts...),
+					readline.PcItem("openvpn", connectOpts...),
+					readline.PcItem("wireguard", connectOpts...),
+				),
 			),
 		),
 		readline.PcItem(
@@ -699,6 +730,13 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []contract.P
 			readline.PcItem("get-all"),
 			readline.PcItem("currencies"),
 		),
+		readline.PcItem("ledger",
+			readline.PcItem("list"),
+			readline.PcItem("export",
+				readline.PcItem("csv"),
+				readline.PcItem("json"),
+			),
+		),
 		readline.PcItem("healthcheck"),
 		readline.PcItem("nat"),
 		readline.PcItem("proposals"),
@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/pkg/errors"
+)
+
+const ledgerFilterHelp = "[identity=<address>] [from=<YYYY-MM-DD>] [to=<YYYY-MM-DD>] [types=session,invoice,promise,settlement] [sort=time|amount|type] [order=asc|desc]"
+
+// ledgerQueryParams maps CLI arguments to ledger API query parameters.
+var ledgerQueryParams = map[string]string{
+	"identity":  "identity",
+	"from":      "date_from",
+	"to":        "date_to",
+	"types":     "types",
+	"sort":      "sort_by",
+	"order":     "order",
+	"page":      "page",
+	"page-size": "page_size",
+}
+
+func (c *cliApp) ledger(argsString string) {
+	var usage = strings.Join([]string{
+		"Usage: ledger <action> [args]",
+		"Available actions:",
+		"  " + usageLedgerList,
+		"  " + usageLedgerExport,
+	}, "\n")
+
+	if len(argsString) == 0 {
+		info(usage)
+		return
+	}
+
+	args := strings.Fields(argsString)
+	action := args[0]
+	actionArgs := args[1:]
+
+	switch action {
+	case "list":
+		c.ledgerList(actionArgs)
+	case "export":
+		c.ledgerExport(actionArgs)
+	default:
+		warnf("Unknown sub-command '%s'\n", argsString)
+		info(usage)
+	}
+}
+
+const usageLedgerList = "list " + ledgerFilterHelp + " [page=<number>] [page-size=<number>]"
+
+func (c *cliApp) ledgerList(args []string) {
+	query, err := parseLedgerQuery(args)
+	if err != nil {
+		warn(err)
+		info("Usage: " + usageLedgerList)
+		return
+	}
+
+	resp, err := c.tequilapi.Ledger(query)
+	if err != nil {
+		warn(errors.Wrap(err, "could not get ledger"))
+		return
+	}
+
+	status("Ledger entries", fmt.Sprintf("%d (page %d of %d)", resp.TotalItems, resp.Page, resp.TotalPages))
+	for _, entry := range resp.Items {
+		status(
+			entry.Time,
+			"Type: "+entry.Type,
+			"Identity: "+entry.Identity,
+			"Direction: "+entry.Direction,
+			"Reference: "+entry.Reference,
+			fmt.Sprintf("Amount: %s", money.NewMoney(entry.Amount, money.CurrencyMyst)),
+		)
+	}
+}
+
+const usageLedgerExport = "export <csv|json> <file> " + ledgerFilterHelp
+
+func (c *cliApp) ledgerExport(args []string) {
+	if len(args) < 2 {
+		info("Usage: " + usageLedgerExport)
+		return
+	}
+
+	format, file := args[0], args[1]
+	query, err := parseLedgerQuery(args[2:])
+	if err != nil {
+		warn(err)
+		info("Usage: " + usageLedgerExport)
+		return
+	}
+	query.Set("format", format)
+
+	data, err := c.tequilapi.LedgerExport(query)
+	if err != nil {
+		warn(errors.Wrap(err, "could not export ledger"))
+		return
+	}
+
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		warn(errors.Wrap(err, "could not write ledger export"))
+		return
+	}
+	success("Ledger exported to", file)
+}
+
+func parseLedgerQuery(args []string) (url.Values, error) {
+	query := url.Values{}
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		param, ok := ledgerQueryParams[kv[0]]
+		if len(kv) != 2 || !ok {
+			return nil, fmt.Errorf("unexpected arg: %s", arg)
+		}
+		query.Set(param, kv[1])
+	}
+	return query, nil
+}
@@ -19,18 +19,22 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/connection/connectiontrace"
 	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/discovery/dhtdiscovery"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/pilvytis"
 
@@ -38,19 +42,23 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	appconfig "github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/ledger"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/consumer/statistics"
 	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/scoring"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/metrics"
 	"github.com/mysteriumnetwork/node/core/node"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/core/state"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
@@ -67,6 +75,7 @@ import (
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/mmn"
 	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/nat/behavior"
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
@@ -90,6 +99,16 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// userConfigWatchInterval defines how often user config file is checked for changes
+const userConfigWatchInterval = 5 * time.Second
+
+const (
+	// connectionTraceRecordings defines how many latest connection traces are kept
+	connectionTraceRecordings = 20
+	// connectionTraceEntries defines how many latest traffic entries of an established connection are kept in a single trace
+	connectionTraceEntries = 1000
+)
+
 // UIServer represents our web server
 type UIServer interface {
 	Serve() error
@@ -113,7 +132,7 @@ type Dependencies struct {
 
 	NATService       nat.NATService
 	Storage          *boltdb.Bolt
-	Keystore         *identity.Keystore
+	Keystore         identity.KeystoreBackend
 	IdentityManager  identity.Manager
 	SignerFactory    identity.SignerFactory
 	IdentityRegistry identity_registry.IdentityRegistry
@@ -122,6 +141,7 @@ type Dependencies struct {
 	DiscoveryFactory   service.DiscoveryFactory
 	ProposalRepository proposal.Repository
 	DiscoveryWorker    discovery.Worker
+	DHTNode            *dhtdiscovery.Node
 
 	QualityClient *quality.MysteriumMORQA
 
@@ -132,27 +152,38 @@ type Dependencies struct {
 
 	StatisticsReporter               *statistics.SessionStatisticsReporter
 	SessionStorage                   *consumer_session.Storage
+	LedgerStorage                    *ledger.Storage
+	Ledger                           *ledger.Ledger
 	SessionConnectivityStatusStorage connectivity.StatusStorage
 
-	EventBus eventbus.EventBus
+	EventBus        eventbus.EventBus
+	MetricsExporter *metrics.Exporter
+	MetricsServer   *http.Server
 
-	ConnectionManager  connection.Manager
-	ConnectionRegistry *connection.Registry
+	ConnectionManager       connection.Manager
+	MultiConnectionManager  *connection.MultiManager
+	ConnectionRegistry      *connection.Registry
+	ConnectionTraceRecorder *connectiontrace.Recorder
 
 	ServicesManager *service.Manager
 	ServiceRegistry *service.Registry
 	ServiceSessions *service.SessionPool
 	ServiceFirewall firewall.IncomingTrafficFirewall
+	ShaperLimits    *shaper.Limits
 
-	NATPinger  traversal.NATPinger
-	NATTracker *event.Tracker
-	PortPool   *port.Pool
-	PortMapper mapping.PortMapper
+	NATPinger           traversal.NATPinger
+	NATTracker          *event.Tracker
+	NATBehaviorDetector *behavior.Detector
+	PortPool            *port.Pool
+	PortMapper          mapping.PortMapper
 
 	StateKeeper *state.Keeper
 
-	P2PDialer   p2p.Dialer
-	P2PListener p2p.Listener
+	P2PDialer       p2p.Dialer
+	P2PListener     p2p.Listener
+	P2PStreamServer *p2p.StreamServer
+	P2PSignalServer *p2p.SignalServer
+	P2PRelayServer  *p2p.RelayServer
 
 	Authenticator     *auth.Authenticator
 	JWTAuthenticator  *auth.JWTAuthenticator
@@ -164,17 +195,22 @@ type Dependencies struct {
 	LogCollector *logconfig.Collector
 	Reporter     *feedback.Reporter
 
-	ProviderInvoiceStorage   *pingpong.ProviderInvoiceStorage
-	ConsumerTotalsStorage    *pingpong.ConsumerTotalsStorage
-	HermesPromiseStorage     *pingpong.HermesPromiseStorage
-	ConsumerBalanceTracker   *pingpong.ConsumerBalanceTracker
-	HermesChannelRepository  *pingpong.HermesChannelRepository
-	HermesPromiseSettler     pingpong.HermesPromiseSettler
-	HermesURLGetter          *pingpong.HermesURLGetter
-	HermesCaller             *pingpong.HermesCaller
-	ChannelAddressCalculator *pingpong.ChannelAddressCalculator
-	HermesPromiseHandler     *pingpong.HermesPromiseHandler
-	SettlementHistoryStorage *pingpong.SettlementHistoryStorage
+	ConfigAuditLog  *appconfig.AuditLog
+	stopConfigWatch func()
+
+	ProviderInvoiceStorage      *pingpong.ProviderInvoiceStorage
+	ConsumerTotalsStorage       *pingpong.ConsumerTotalsStorage
+	ConsumerPaymentStateStorage *pingpong.ConsumerPaymentStateStorage
+	ConsumerSpendingLimiter     *pingpong.SpendingLimiter
+	HermesPromiseStorage        *pingpong.HermesPromiseStorage
+	ConsumerBalanceTracker      *pingpong.ConsumerBalances
+	HermesChannelRepository     *pingpong.HermesChannelRepository
+	HermesPromiseSettler        pingpong.HermesPromiseSettler
+	HermesURLGetter             *pingpong.HermesURLGetter
+	HermesCaller                *pingpong.HermesCaller
+	ChannelAddressCalculator    *pingpong.ChannelAddressCalculator
+	HermesPromiseHandler        *pingpong.HermesPromiseHandler
+	SettlementHistoryStorage    *pingpong.SettlementHistoryStorage
 
 	MMN         *mmn.MMN
 	PilvytisAPI *pilvytis.API
@@ -205,6 +241,9 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	}
 
 	di.bootstrapEventBus()
+	if err := di.bootstrapMetrics(); err != nil {
+		return err
+	}
 
 	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
 		return err
@@ -216,7 +255,9 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
-	di.bootstrapIdentityComponents(nodeOptions)
+	if err := di.bootstrapIdentityComponents(nodeOptions); err != nil {
+		return err
+	}
 
 	if err := di.bootstrapDiscoveryComponents(nodeOptions.Discovery); err != nil {
 		return err
@@ -244,8 +285,11 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		di.PortMapper = mapping.NewNoopPortMapper(di.EventBus)
 	}
 
-	di.bootstrapP2P(nodeOptions.P2PPorts)
+	if err := di.bootstrapP2P(nodeOptions.P2PPorts, nodeOptions.P2PStream, nodeOptions.P2PSignal, nodeOptions.P2PRelay); err != nil {
+		return err
+	}
 	di.SessionConnectivityStatusStorage = connectivity.NewStatusStorage()
+	di.ShaperLimits = shaper.NewLimits(di.EventBus)
 
 	if err := di.bootstrapServices(nodeOptions); err != nil {
 		return err
@@ -258,6 +302,7 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	if err := di.bootstrapNodeComponents(nodeOptions, tequilaListener); err != nil {
 		return err
 	}
+	di.NATBehaviorDetector.Start()
 
 	di.registerConnections(nodeOptions)
 	if err = di.handleConnStateChange(); err != nil {
@@ -268,12 +313,17 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	}
 
 	appconfig.Current.EnableEventPublishing(di.EventBus)
+	di.stopConfigWatch = appconfig.Current.WatchUserConfig(userConfigWatchInterval, func(changes []appconfig.Change) {
+		if err := di.ConfigAuditLog.Record(appconfig.AuditSourceFile, "", "", changes); err != nil {
+			log.Error().Err(err).Msg("Failed to record user config changes")
+		}
+	})
 
 	log.Info().Msg("Mysterium node started!")
 	return nil
 }
 
-func (di *Dependencies) bootstrapP2P(p2pPorts *port.Range) {
+func (di *Dependencies) bootstrapP2P(p2pPorts *port.Range, streamOptions node.OptionsP2PStream, signalOptions node.OptionsP2PSignal, relayOptions node.OptionsP2PRelay) error {
 	portPool := di.PortPool
 	natPinger := di.NATPinger
 	identityVerifier := identity.NewVerifierSigned()
@@ -283,8 +333,36 @@ func (di *Dependencies) bootstrapP2P(p2pPorts *port.Range) {
 		natPinger = traversal.NewNoopPinger()
 	}
 
-	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool, di.PortMapper)
-	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool)
+	if streamOptions.Port > 0 {
+		di.P2PStreamServer = p2p.NewStreamServer(streamOptions.Port, streamOptions.TLS)
+		if err := di.P2PStreamServer.Start(); err != nil {
+			return errors.Wrap(err, "could not start p2p stream server")
+		}
+	}
+
+	// DHT node carries p2p signaling between its peers, when DHT discovery is used.
+	var signalHost p2p.SignalHost
+	if di.DHTNode != nil {
+		signalHost = di.DHTNode
+	}
+
+	if signalOptions.Port > 0 || signalHost != nil {
+		di.P2PSignalServer = p2p.NewSignalServer(signalOptions.Port, signalHost)
+		if err := di.P2PSignalServer.Start(); err != nil {
+			return errors.Wrap(err, "could not start p2p signal server")
+		}
+	}
+
+	if relayOptions.Port > 0 {
+		di.P2PRelayServer = p2p.NewRelayServer(relayOptions.Port)
+		if err := di.P2PRelayServer.Start(); err != nil {
+			return errors.Wrap(err, "could not start p2p relay server")
+		}
+	}
+
+	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool, di.PortMapper, di.P2PStreamServer, di.P2PSignalServer, relayOptions.Addresses, di.NATBehaviorDetector)
+	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, identityVerifier, di.IPResolver, natPinger, portPool, di.NATBehaviorDetector, signalHost)
+	return nil
 }
 
 func (di *Dependencies) createTequilaListener(nodeOptions node.Options) (net.Listener, error) {
@@ -317,6 +395,7 @@ func (di *Dependencies) bootstrapStateKeeper(options node.Options) error {
 		BalanceProvider:           di.ConsumerBalanceTracker,
 		EarningsProvider:          di.HermesChannelRepository,
 		ChainID:                   options.ChainID,
+		HermesID:                  common.HexToAddress(options.Hermes.HermesID),
 	}
 	di.StateKeeper = state.NewKeeper(deps, state.DefaultDebounceDuration)
 	return di.StateKeeper.Subscribe(di.EventBus)
@@ -361,6 +440,10 @@ func (di *Dependencies) Shutdown() (err error) {
 		}
 	}
 
+	if di.MultiConnectionManager != nil {
+		di.MultiConnectionManager.DisconnectAll()
+	}
+
 	if di.ServicesManager != nil {
 		if err := di.ServicesManager.Kill(); err != nil {
 			errs = append(errs, err)
@@ -379,6 +462,21 @@ func (di *Dependencies) Shutdown() (err error) {
 	if di.DiscoveryWorker != nil {
 		di.DiscoveryWorker.Stop()
 	}
+	if di.NATBehaviorDetector != nil {
+		di.NATBehaviorDetector.Stop()
+	}
+	if di.P2PStreamServer != nil {
+		di.P2PStreamServer.Stop()
+	}
+	if di.P2PSignalServer != nil {
+		di.P2PSignalServer.Stop()
+	}
+	if di.stopConfigWatch != nil {
+		di.stopConfigWatch()
+	}
+	if di.P2PRelayServer != nil {
+		di.P2PRelayServer.Stop()
+	}
 	if di.BrokerConnection != nil {
 		di.BrokerConnection.Close()
 	}
@@ -392,6 +490,12 @@ func (di *Dependencies) Shutdown() (err error) {
 	}
 	firewall.Reset()
 
+	if di.MetricsServer != nil {
+		if err := di.MetricsServer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	if di.Storage != nil {
 		if err := di.Storage.Close(); err != nil {
 			errs = append(errs, err)
@@ -422,13 +526,22 @@ func (di *Dependencies) bootstrapStorage(path string) error {
 	invoiceStorage := pingpong.NewInvoiceStorage(di.Storage)
 	di.ProviderInvoiceStorage = pingpong.NewProviderInvoiceStorage(invoiceStorage)
 	di.ConsumerTotalsStorage = pingpong.NewConsumerTotalsStorage(di.Storage, di.EventBus)
+	di.ConsumerPaymentStateStorage = pingpong.NewConsumerPaymentStateStorage(di.Storage)
 	di.HermesPromiseStorage = pingpong.NewHermesPromiseStorage(di.Storage)
 	di.SessionStorage = consumer_session.NewSessionStorage(di.Storage)
 	di.SettlementHistoryStorage = pingpong.NewSettlementHistoryStorage(di.Storage)
-	return di.SessionStorage.Subscribe(di.EventBus)
+	if err := di.SessionStorage.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	di.LedgerStorage = ledger.NewStorage(di.Storage)
+	di.Ledger = ledger.NewLedger(di.LedgerStorage, di.SessionStorage, di.SettlementHistoryStorage)
+	return di.LedgerStorage.Subscribe(di.EventBus)
 }
 
 func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequilaListener net.Listener) error {
+	di.ConfigAuditLog = appconfig.NewAuditLog(filepath.Join(nodeOptions.Directories.Data, "config-audit.log"))
+
 	// Consumer current session bandwidth
 	bandwidthTracker := bandwidth.NewTracker(di.EventBus)
 	if err := bandwidthTracker.Subscribe(di.EventBus); err != nil {
@@ -472,17 +585,49 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 	}
 
 	di.HermesCaller = pingpong.NewHermesCaller(di.HTTPClient, hermesURL)
-	di.ConsumerBalanceTracker = pingpong.NewConsumerBalanceTracker(
-		di.EventBus,
-		common.HexToAddress(nodeOptions.Payments.MystSCAddress),
-		common.HexToAddress(nodeOptions.Hermes.HermesID),
-		di.BCHelper,
-		di.ChannelAddressCalculator,
-		di.ConsumerTotalsStorage,
-		di.HermesCaller,
-		di.Transactor,
-		di.IdentityRegistry,
-	)
+	hermesIDs := nodeOptions.Hermes.HermesIDs()
+	balanceTrackers := map[common.Address]*pingpong.ConsumerBalanceTracker{
+		hermesIDs[0]: pingpong.NewConsumerBalanceTracker(
+			di.EventBus,
+			common.HexToAddress(nodeOptions.Payments.MystSCAddress),
+			hermesIDs[0],
+			di.BCHelper,
+			di.ChannelAddressCalculator,
+			di.ConsumerTotalsStorage,
+			di.HermesCaller,
+			di.Transactor,
+			di.IdentityRegistry,
+		),
+	}
+	for _, hermesID := range hermesIDs[1:] {
+		url, err := di.HermesURLGetter.GetHermesURL(hermesID)
+		if err != nil {
+			return err
+		}
+		if _, err := firewall.AllowURLAccess(url); err != nil {
+			return err
+		}
+		if _, err := di.ServiceFirewall.AllowURLAccess(url); err != nil {
+			return err
+		}
+		// registration bounties are paid to the registration hermes channel only, so transactor is not consulted here
+		balanceTrackers[hermesID] = pingpong.NewConsumerBalanceTracker(
+			di.EventBus,
+			common.HexToAddress(nodeOptions.Payments.MystSCAddress),
+			hermesID,
+			di.BCHelper,
+			pingpong.NewChannelAddressCalculator(
+				hermesID.Hex(),
+				nodeOptions.Transactor.ChannelImplementation,
+				nodeOptions.Transactor.RegistryAddress,
+			),
+			di.ConsumerTotalsStorage,
+			pingpong.NewHermesCaller(di.HTTPClient, url),
+			nil,
+			di.IdentityRegistry,
+		)
+	}
+	di.ConsumerBalanceTracker = pingpong.NewConsumerBalances(hermesIDs, balanceTrackers)
 
 	err = di.ConsumerBalanceTracker.Subscribe(di.EventBus)
 	if err != nil {
@@ -504,32 +649,54 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		return err
 	}
 
+	di.ConsumerSpendingLimiter = pingpong.NewSpendingLimiter(di.Storage, di.EventBus, pingpong.SpendingLimits{
+		Session:        nodeOptions.Payments.ConsumerSessionLimit,
+		Daily:          nodeOptions.Payments.ConsumerDailyLimit,
+		Monthly:        nodeOptions.Payments.ConsumerMonthlyLimit,
+		AlertThreshold: nodeOptions.Payments.ConsumerLimitAlertThreshold,
+	})
+
 	di.ConnectionRegistry = connection.NewRegistry()
-	di.ConnectionManager = connection.NewManager(
-		pingpong.ExchangeFactoryFunc(
-			di.Keystore,
-			di.SignerFactory,
-			di.ConsumerTotalsStorage,
-			nodeOptions.Transactor.ChannelImplementation,
-			nodeOptions.Transactor.RegistryAddress,
+	newConnectionManager := func() connection.Manager {
+		return connection.NewManager(
+			pingpong.ExchangeFactoryFunc(
+				di.Keystore,
+				di.SignerFactory,
+				di.ConsumerTotalsStorage,
+				di.ConsumerPaymentStateStorage,
+				nodeOptions.Transactor.ChannelImplementation,
+				nodeOptions.Transactor.RegistryAddress,
+				di.EventBus,
+				nodeOptions.Payments.ConsumerDataLeewayMegabytes,
+				di.ConsumerSpendingLimiter,
+			),
+			di.ConnectionRegistry.CreateConnection,
 			di.EventBus,
-			nodeOptions.Payments.ConsumerDataLeewayMegabytes,
-		),
-		di.ConnectionRegistry.CreateConnection,
-		di.EventBus,
-		di.IPResolver,
-		di.LocationResolver,
-		connection.DefaultConfig(),
-		connection.DefaultStatsReportInterval,
-		connection.NewValidator(
-			di.ConsumerBalanceTracker,
-			di.IdentityManager,
-		),
-		di.P2PDialer,
-	)
+			di.IPResolver,
+			di.LocationResolver,
+			connection.DefaultConfig(),
+			connection.DefaultStatsReportInterval,
+			connection.NewValidator(
+				di.ConsumerBalanceTracker,
+				di.IdentityManager,
+			),
+			di.P2PDialer,
+			connection.NewQualityFailoverPolicy(di.ProposalRepository, di.QualityClient),
+		)
+	}
+	di.ConnectionManager = newConnectionManager()
+	di.MultiConnectionManager = connection.NewMultiManager(newConnectionManager)
+	if err := di.MultiConnectionManager.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	di.ConnectionTraceRecorder = connectiontrace.NewRecorder(connectionTraceRecordings, connectionTraceEntries)
+	if err := di.ConnectionTraceRecorder.Subscribe(di.EventBus); err != nil {
+		return err
+	}
 
 	di.LogCollector = logconfig.NewCollector(&logconfig.CurrentLogOptions)
-	reporter, err := feedback.NewReporter(di.LogCollector, di.IdentityManager, nodeOptions.FeedbackURL)
+	reporter, err := feedback.NewReporter(di.LogCollector, di.IdentityManager, di.ConnectionTraceRecorder, nodeOptions.FeedbackURL)
 	if err != nil {
 		return err
 	}
@@ -574,20 +741,26 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 	tequilapi_endpoints.AddRoutesForAuthentication(router, di.Authenticator, di.JWTAuthenticator)
 	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.ChannelAddressCalculator, di.HermesChannelRepository, di.BCHelper, di.Transactor)
 	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry)
+	tequilapi_endpoints.AddRoutesForMultiConnection(router, di.MultiConnectionManager, di.ProposalRepository, di.IdentityRegistry)
 	tequilapi_endpoints.AddRoutesForSessions(router, di.SessionStorage)
+	tequilapi_endpoints.AddRoutesForLedger(router, di.Ledger)
 	tequilapi_endpoints.AddRoutesForConnectionLocation(router, di.IPResolver, di.LocationResolver, di.LocationResolver)
-	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityClient)
+	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityClient, scoring.NewRanker(di.QualityClient, di.SessionStorage, scoring.DefaultWeights()))
 	tequilapi_endpoints.AddRoutesForService(router, di.ServicesManager, services.JSONParsersByType)
 	tequilapi_endpoints.AddRoutesForPayout(router, di.IdentityManager, di.SignerFactory, di.MysteriumAPI)
 	tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, router, config.GetString(config.FlagAccessPolicyAddress))
 	tequilapi_endpoints.AddRoutesForNAT(router, di.StateKeeper)
 	tequilapi_endpoints.AddRoutesForTransactor(router, di.Transactor, di.HermesPromiseSettler, di.SettlementHistoryStorage, common.HexToAddress(nodeOptions.Hermes.HermesID))
-	tequilapi_endpoints.AddRoutesForConfig(router)
+	tequilapi_endpoints.AddRoutesForConfig(router, di.ConfigAuditLog, di.JWTAuthenticator)
 	tequilapi_endpoints.AddRoutesForMMN(router, di.MMN)
 	tequilapi_endpoints.AddRoutesForFeedback(router, di.Reporter)
+	tequilapi_endpoints.AddRoutesForConnectionTraces(router, di.ConnectionTraceRecorder)
 	tequilapi_endpoints.AddRoutesForConnectivityStatus(router, di.SessionConnectivityStatusStorage)
 	tequilapi_endpoints.AddRoutesForCurrencyExchange(router, di.Exchange)
 	tequilapi_endpoints.AddRoutesForPilvytis(router, di.PilvytisAPI)
+	tequilapi_endpoints.AddRoutesForShaper(router, di.ShaperLimits)
+	tequilapi_endpoints.AddRoutesForSpendingLimits(router, di.ConsumerSpendingLimiter)
+	tequilapi_endpoints.AddRoutesForMetrics(router, di.MetricsExporter.Handler())
 	if err := tequilapi_endpoints.AddRoutesForSSE(router, di.StateKeeper, di.EventBus); err != nil {
 		return nil, err
 	}
@@ -706,17 +879,74 @@ func (di *Dependencies) bootstrapEventBus() {
 	di.EventBus = eventbus.New()
 }
 
-func (di *Dependencies) bootstrapIdentityComponents(options node.Options) {
-	var ks *keystore.KeyStore
+func (di *Dependencies) bootstrapMetrics() error {
+	di.MetricsExporter = metrics.NewExporter(di.EventBus)
+	if err := di.MetricsExporter.Subscribe(di.EventBus); err != nil {
+		return errors.Wrap(err, "could not subscribe metrics exporter to events")
+	}
+
+	address := config.GetString(config.FlagMetricsAddress)
+	if address == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.Wrapf(err, "could not listen for metrics on %s", address)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", di.MetricsExporter.Handler())
+	di.MetricsServer = &http.Server{Handler: mux}
+	go func() {
+		if err := di.MetricsServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Error().Err(err).Msg("Metrics server stopped")
+		}
+	}()
+	log.Info().Msgf("Serving metrics on %s", address)
+	return nil
+}
+
+func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error {
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
 	if options.Keystore.UseLightweight {
 		log.Debug().Msg("Using lightweight keystore")
-		ks = keystore.NewKeyStore(options.Directories.Keystore, keystore.LightScryptN, keystore.LightScryptP)
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
 	} else {
 		log.Debug().Msg("Using heavyweight keystore")
-		ks = keystore.NewKeyStore(options.Directories.Keystore, keystore.StandardScryptN, keystore.StandardScryptP)
 	}
 
-	di.Keystore = identity.NewKeystoreFilesystem(options.Directories.Keystore, ks)
+	switch options.Keystore.Backend {
+	case identity.KeystoreBackendFilesystem, "":
+		ks := keystore.NewKeyStore(options.Directories.Keystore, scryptN, scryptP)
+		di.Keystore = identity.NewKeystoreFilesystem(options.Directories.Keystore, ks)
+	case identity.KeystoreBackendVault:
+		vaultFile := options.Keystore.VaultFile
+		if vaultFile == "" {
+			vaultFile = filepath.Join(options.Directories.Keystore, "vault.json")
+		}
+		log.Info().Msgf("Using keystore vault %s", vaultFile)
+		di.Keystore = identity.NewKeystoreVault(vaultFile, scryptN, scryptP)
+	case identity.KeystoreBackendRemote:
+		if options.Keystore.RemoteURL == "" {
+			return errors.New("remote keystore requires signer URL")
+		}
+		if _, err := firewall.AllowURLAccess(options.Keystore.RemoteURL); err != nil {
+			return err
+		}
+		var token string
+		if options.Keystore.RemoteTokenFile != "" {
+			tokenBytes, err := ioutil.ReadFile(options.Keystore.RemoteTokenFile)
+			if err != nil {
+				return fmt.Errorf("could not read remote signer token: %w", err)
+			}
+			token = strings.TrimSpace(string(tokenBytes))
+		}
+		log.Info().Msgf("Using remote signer %s", options.Keystore.RemoteURL)
+		di.Keystore = identity.NewKeystoreRemote(options.Keystore.RemoteURL, token, 20*time.Second)
+	default:
+		return fmt.Errorf("unknown keystore backend: %s", options.Keystore.Backend)
+	}
+
 	di.IdentityManager = identity.NewIdentityManager(di.Keystore, di.EventBus)
 	di.SignerFactory = func(id identity.Identity) identity.Signer {
 		return identity.NewSigner(di.Keystore, id)
@@ -727,7 +957,7 @@ func (di *Dependencies) bootstrapIdentityComponents(options node.Options) {
 		identity.NewIdentityCache(options.Directories.Keystore, "remember.json"),
 		di.SignerFactory,
 	)
-
+	return nil
 }
 
 func (di *Dependencies) bootstrapQualityComponents(options node.OptionsQuality) (err error) {
@@ -855,6 +1085,8 @@ func (di *Dependencies) bootstrapNATComponents(options node.Options) error {
 	} else {
 		di.NATPinger = &traversal.NoopPinger{}
 	}
+
+	di.NATBehaviorDetector = behavior.NewDetector(config.GetStringSlice(config.FlagSTUNServers), 30*time.Minute, di.EventBus)
 	return nil
 }
 
@@ -890,7 +1122,10 @@ func (di *Dependencies) handleConnStateChange() error {
 
 	latestState := connectionstate.NotConnected
 	return di.EventBus.SubscribeAsync(connectionstate.AppTopicConnectionState, func(e connectionstate.AppEventConnectionState) {
-		// Here we care only about connected and disconnected events.
+		// Here we care only about connected and disconnected events of the default connection.
+		if e.SessionInfo.ConnectionID != "" {
+			return
+		}
 		if e.State != connectionstate.Connected && e.State != connectionstate.NotConnected {
 			return
 		}
@@ -101,8 +101,15 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 				wgOptions,
 				portPool,
 				di.ServiceFirewall,
+				di.ShaperLimits,
 			)
-			return svc, wireguard_service.GetProposal(loc), nil
+
+			proposal := wireguard_service.GetProposal(loc)
+			if config.GetBool(config.FlagShaperEnabled) {
+				profile := wireguard_service.GetShaperProfile()
+				proposal.SetBandwidthLimit(&market.BandwidthLimit{UplinkKbps: profile.UplinkKbps, DownlinkKbps: profile.DownlinkKbps})
+			}
+			return svc, proposal, nil
 		},
 	)
 }
@@ -119,7 +126,11 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 		}
 
 		transportOptions := serviceOptions.(openvpn_service.Options)
-		proposal := openvpn_discovery.NewServiceProposalWithLocation(loc, transportOptions.Protocol)
+		proposal := openvpn_discovery.NewServiceProposalWithLocation(loc, transportOptions.Protocols())
+		if config.GetBool(config.FlagShaperEnabled) {
+			profile := openvpn_service.GetShaperProfile()
+			proposal.SetBandwidthLimit(&market.BandwidthLimit{UplinkKbps: profile.UplinkKbps, DownlinkKbps: profile.DownlinkKbps})
+		}
 
 		// TODO: Use global port pool once migrated to p2p.
 		var portPool port.ServicePortSupplier
@@ -140,6 +151,7 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			portPool,
 			di.EventBus,
 			di.ServiceFirewall,
+			di.ShaperLimits,
 		)
 		return manager, proposal, nil
 	}
@@ -197,10 +209,12 @@ func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options)
 		di.IdentityRegistry,
 		di.Keystore,
 		di.SettlementHistoryStorage,
+		di.EventBus,
 		pingpong.HermesPromiseSettlerConfig{
 			HermesAddress:        common.HexToAddress(nodeOptions.Hermes.HermesID),
 			Threshold:            nodeOptions.Payments.HermesPromiseSettlingThreshold,
 			MaxWaitForSettlement: nodeOptions.Payments.SettlementTimeout,
+			MaxFeeRatio:          nodeOptions.Payments.SettlementMaxFeeRatio,
 		},
 	)
 	if err := settler.Subscribe(di.EventBus); err != nil {
@@ -228,6 +242,25 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 	)
 	go di.PolicyOracle.Start()
 
+	var localPolicies []market.AccessPolicyRuleSet
+	if path := config.GetString(config.FlagAccessPolicyFile); path != "" {
+		var err error
+		if localPolicies, err = policy.LoadRuleSets(path); err != nil {
+			return err
+		}
+	}
+
+	quotaTracker := service.NewQuotaTracker()
+	if err := quotaTracker.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	acceptedHermeses := nodeOptions.Hermes.HermesIDs()
+	hermesIDs := make([]string, len(acceptedHermeses))
+	for i, id := range acceptedHermeses {
+		hermesIDs[i] = id.Hex()
+	}
+
 	newP2PSessionHandler := func(serviceInstance *service.Instance, channel p2p.Channel) *service.SessionManager {
 		paymentEngineFactory := pingpong.InvoiceFactoryCreator(
 			channel, nodeOptions.Payments.ProviderInvoiceFrequency,
@@ -241,11 +274,12 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			di.EventBus,
 			serviceInstance.Proposal,
 			di.HermesPromiseHandler,
-			common.HexToAddress(nodeOptions.Hermes.HermesID),
+			acceptedHermeses,
 		)
 		return service.NewSessionManager(
 			serviceInstance,
 			di.ServiceSessions,
+			quotaTracker,
 			paymentEngineFactory,
 			di.NATTracker,
 			di.EventBus,
@@ -259,9 +293,11 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		di.DiscoveryFactory,
 		di.EventBus,
 		di.PolicyOracle,
+		localPolicies,
 		di.P2PListener,
 		newP2PSessionHandler,
 		di.SessionConnectivityStatusStorage,
+		hermesIDs,
 	)
 
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessions}
@@ -60,9 +60,15 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 				return errors.Wrap(err, "failed to configure DHT node")
 			}
 			discoveryWorker.AddWorker(dhtNode)
+			di.DHTNode = dhtNode
 
-			proposalRegistry.AddRegistry(dhtdiscovery.NewRegistry())
-			proposalRepository.Add(dhtdiscovery.NewRepository())
+			dhtRepository := dhtdiscovery.NewRepository(dhtNode, options.FetchInterval)
+			if options.FetchEnabled {
+				discoveryWorker.AddWorker(dhtRepository)
+			}
+
+			proposalRegistry.AddRegistry(dhtdiscovery.NewRegistry(dhtNode, 2*options.PingInterval))
+			proposalRepository.Add(dhtRepository)
 
 		default:
 			return errors.Errorf("unknown discovery adapter: %s", discoveryType)
@@ -76,7 +82,7 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 
 	di.ProposalRepository = proposalRepository
 	di.DiscoveryFactory = func() service.Discovery {
-		return discovery.NewService(di.IdentityRegistry, proposalRegistry, options.PingInterval, di.SignerFactory, di.EventBus)
+		return discovery.NewService(di.IdentityRegistry, proposalRegistry, options.PingInterval, di.SignerFactory, di.EventBus, di.NATBehaviorDetector)
 	}
 	return nil
 }
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sources of user configuration changes.
+const (
+	AuditSourceTequilapi = "tequilapi"
+	AuditSourceFile      = "file"
+)
+
+const redactedValue = "********"
+
+// AuditEntry describes a single user configuration change.
+type AuditEntry struct {
+	Time   time.Time   `json:"time"`
+	Actor  string      `json:"actor,omitempty"`
+	Remote string      `json:"remote,omitempty"`
+	Source string      `json:"source"`
+	Key    string      `json:"key"`
+	Old    interface{} `json:"old"`
+	New    interface{} `json:"new"`
+}
+
+// AuditLog appends user configuration changes to a file, one JSON entry per line.
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAuditLog creates audit log stored at the given path.
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Record appends the changes to the audit log, values of secret flags are redacted.
+func (l *AuditLog) Record(source, actor, remote string, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open config audit log")
+	}
+	defer file.Close()
+
+	now := time.Now().UTC()
+	encoder := json.NewEncoder(file)
+	for _, change := range changes {
+		entry := AuditEntry{
+			Time:   now,
+			Actor:  actor,
+			Remote: remote,
+			Source: source,
+			Key:    change.Key,
+			Old:    change.Old,
+			New:    change.New,
+		}
+		if flagSchema, ok := SchemaOf(change.Key); ok && flagSchema.Secret {
+			entry.Old, entry.New = redact(entry.Old), redact(entry.New)
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return errors.Wrap(err, "failed to write config audit log")
+		}
+	}
+	return nil
+}
+
+// Entries returns the latest audit log entries, oldest first. All entries are returned if limit is not positive.
+func (l *AuditLog) Entries(limit int) ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return []AuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open config audit log")
+	}
+	defer file.Close()
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrap(err, "failed to parse config audit log")
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) > limit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read config audit log")
+	}
+	return entries, nil
+}
+
+func redact(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return redactedValue
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog_RecordsChanges(t *testing.T) {
+	logFileName := NewTempFileName(t)
+	defer os.Remove(logFileName)
+	auditLog := NewAuditLog(logFileName)
+
+	err := auditLog.Record(AuditSourceTequilapi, "myst", "127.0.0.1", []Change{
+		{Key: FlagOpenvpnPort.Name, Old: nil, New: 5522},
+		{Key: FlagTequilapiPassword.Name, Old: "old-secret", New: "new-secret"},
+	})
+	assert.NoError(t, err)
+	err = auditLog.Record(AuditSourceFile, "", "", []Change{
+		{Key: FlagOpenvpnPort.Name, Old: 5522, New: nil},
+	})
+	assert.NoError(t, err)
+
+	entries, err := auditLog.Entries(0)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	assert.Equal(t, AuditSourceTequilapi, entries[0].Source)
+	assert.Equal(t, "myst", entries[0].Actor)
+	assert.Equal(t, "127.0.0.1", entries[0].Remote)
+	assert.Equal(t, FlagOpenvpnPort.Name, entries[0].Key)
+	assert.Nil(t, entries[0].Old)
+	assert.Equal(t, 5522.0, entries[0].New)
+
+	assert.Equal(t, redactedValue, entries[1].Old)
+	assert.Equal(t, redactedValue, entries[1].New)
+
+	assert.Equal(t, AuditSourceFile, entries[2].Source)
+	assert.Nil(t, entries[2].New)
+
+	entries, err = auditLog.Entries(1)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, AuditSourceFile, entries[0].Source)
+}
+
+func TestAuditLog_EntriesWithoutFile(t *testing.T) {
+	auditLog := NewAuditLog(os.TempDir() + "/not-existing-config-audit.log")
+
+	entries, err := auditLog.Entries(10)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -20,7 +20,11 @@ package config
 import (
 	"io/ioutil"
 	"math/big"
+	"os"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -45,6 +49,14 @@ type Config struct {
 	user               map[string]interface{}
 	cli                map[string]interface{}
 	eventBus           eventbus.EventBus
+	mu                 sync.RWMutex
+}
+
+// Change describes a changed user configuration value, New is nil if the value was removed.
+type Change struct {
+	Key string
+	Old interface{}
+	New interface{}
 }
 
 // Current global configuration instance.
@@ -72,6 +84,9 @@ func (cfg *Config) EnableEventPublishing(eb eventbus.EventBus) {
 // LoadUserConfig loads and remembers user config location.
 func (cfg *Config) LoadUserConfig(location string) error {
 	log.Debug().Msg("Loading user configuration: " + location)
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
 	cfg.userConfigLocation = location
 	_, err := toml.DecodeFile(cfg.userConfigLocation, &cfg.user)
 	if err != nil {
@@ -88,6 +103,9 @@ func (cfg *Config) LoadUserConfig(location string) error {
 // SaveUserConfig saves user configuration to the file from which it was loaded.
 func (cfg *Config) SaveUserConfig() error {
 	log.Info().Msg("Saving user configuration")
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
 	if !cfg.userConfigLoaded() {
 		return errors.New("user configuration cannot be saved, because it must be loaded first")
 	}
@@ -110,20 +128,28 @@ func (cfg *Config) SaveUserConfig() error {
 
 // GetDefaultConfig returns default configuration.
 func (cfg *Config) GetDefaultConfig() map[string]interface{} {
-	return cfg.defaults
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	return copyMap(cfg.defaults)
 }
 
 // GetUserConfig returns user configuration.
 func (cfg *Config) GetUserConfig() map[string]interface{} {
-	return cfg.user
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	return copyMap(cfg.user)
 }
 
 // GetConfig returns current configuration.
 func (cfg *Config) GetConfig() map[string]interface{} {
-	config := make(map[string]interface{})
-	mergeMaps(cfg.defaults, config, nil)
-	mergeMaps(cfg.user, config, nil)
-	mergeMaps(cfg.cli, config, nil)
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	config := copyMap(cfg.defaults)
+	mergeMaps(copyMap(cfg.user), config, nil)
+	mergeMaps(copyMap(cfg.cli), config, nil)
 	return config
 }
 
@@ -134,10 +160,10 @@ func (cfg *Config) SetDefault(key string, value interface{}) {
 
 // SetUser sets user configuration value for key.
 func (cfg *Config) SetUser(key string, value interface{}) {
+	cfg.set(&cfg.user, key, value)
 	if cfg.eventBus != nil {
 		cfg.eventBus.Publish(AppTopicConfig(key), value)
 	}
-	cfg.set(&cfg.user, key, value)
 }
 
 // SetCLI sets value passed via CLI flag for key.
@@ -155,8 +181,137 @@ func (cfg *Config) RemoveCLI(key string) {
 	cfg.remove(&cfg.cli, key)
 }
 
+// ApplyUserValues sets or removes (if the value is nil) user configuration values
+// and returns the changes. Values should be validated with ValidateUserValues first.
+func (cfg *Config) ApplyUserValues(values map[string]interface{}) []Change {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changes []Change
+	for _, key := range keys {
+		value := values[key]
+		cfg.mu.RLock()
+		old := cfg.searchMap(cfg.user, strings.Split(strings.ToLower(key), "."))
+		cfg.mu.RUnlock()
+		if reflect.DeepEqual(old, value) {
+			continue
+		}
+
+		if value == nil {
+			log.Debug().Msgf("Clearing user config value: %q", key)
+			cfg.RemoveUser(key)
+			if cfg.eventBus != nil {
+				cfg.eventBus.Publish(AppTopicConfig(key), cfg.Get(key))
+			}
+		} else {
+			log.Debug().Msgf("Setting user config value: %q", key)
+			cfg.SetUser(key, value)
+		}
+		changes = append(changes, Change{Key: strings.ToLower(key), Old: old, New: value})
+	}
+	return changes
+}
+
+// ReloadUserConfig reads user configuration file again and applies the changed values.
+// Invalid values in the file are skipped, the current ones are kept instead.
+func (cfg *Config) ReloadUserConfig() ([]Change, error) {
+	cfg.mu.RLock()
+	location := cfg.userConfigLocation
+	current := flattenMap("", cfg.user)
+	cfg.mu.RUnlock()
+	if location == "" {
+		return nil, errors.New("user configuration cannot be reloaded, because it must be loaded first")
+	}
+
+	loaded := make(map[string]interface{})
+	if _, err := toml.DecodeFile(location, &loaded); err != nil {
+		return nil, errors.Wrap(err, "failed to decode configuration file")
+	}
+	values, errs := ValidateUserValues(loaded)
+	for key, err := range errs {
+		log.Warn().Err(err).Msgf("Ignoring invalid user config value of %q", key)
+		delete(values, key)
+	}
+	normalized, _ := ValidateUserValues(current)
+	for key := range current {
+		if _, ok := errs[key]; ok {
+			continue
+		}
+		if _, ok := values[key]; !ok {
+			values[key] = nil
+		}
+	}
+	for key, value := range values {
+		if value != nil && reflect.DeepEqual(normalized[key], value) {
+			delete(values, key)
+		}
+	}
+
+	return cfg.ApplyUserValues(values), nil
+}
+
+// WatchUserConfig reloads user configuration when its file is modified and passes the changes to onReload.
+// Nothing is watched if user configuration was not loaded. Returned function stops watching.
+func (cfg *Config) WatchUserConfig(interval time.Duration, onReload func(changes []Change)) (stop func()) {
+	cfg.mu.RLock()
+	location := cfg.userConfigLocation
+	cfg.mu.RUnlock()
+	if location == "" {
+		return func() {}
+	}
+
+	modified := func() time.Time {
+		info, err := os.Stat(location)
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		lastModified := modified()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			m := modified()
+			if m.IsZero() || m.Equal(lastModified) {
+				continue
+			}
+			lastModified = m
+
+			changes, err := cfg.ReloadUserConfig()
+			if err != nil {
+				log.Warn().Err(err).Msg("Could not reload user configuration")
+				continue
+			}
+			if len(changes) > 0 {
+				log.Info().Msgf("User configuration reloaded with %d changes", len(changes))
+				onReload(changes)
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
 // set sets value to a particular configuration value map.
 func (cfg *Config) set(configMap *map[string]interface{}, key string, value interface{}) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
 	key = strings.ToLower(key)
 	segments := strings.Split(key, ".")
 
@@ -169,6 +324,9 @@ func (cfg *Config) set(configMap *map[string]interface{}, key string, value inte
 
 // remove removes a configured value from a particular configuration map.
 func (cfg *Config) remove(configMap *map[string]interface{}, key string) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
 	key = strings.ToLower(key)
 	segments := strings.Split(key, ".")
 
@@ -181,6 +339,9 @@ func (cfg *Config) remove(configMap *map[string]interface{}, key string) {
 
 // Get returns stored config value as-is.
 func (cfg *Config) Get(key string) interface{} {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
 	segments := strings.Split(strings.ToLower(key), ".")
 	cliValue := cfg.searchMap(cfg.cli, segments)
 	if cliValue != nil {
@@ -376,3 +537,14 @@ func GetFloat64(flag cli.Float64Flag) float64 {
 func AppTopicConfig(configKey string) string {
 	return "config:" + configKey
 }
+
+func copyMap(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for key, value := range src {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = copyMap(nested)
+		}
+		dst[key] = value
+	}
+	return dst
+}
@@ -81,6 +81,64 @@ func TestUserConfig_Save(t *testing.T) {
 	assert.NotContains(t, string(tomlContent), `proto = "tcp"`)
 }
 
+func TestUserConfig_Reload(t *testing.T) {
+	// given
+	configFileName := NewTempFileName(t)
+	defer os.Remove(configFileName)
+	err := ioutil.WriteFile(configFileName, []byte(`
+		[openvpn]
+		port = 31338
+		proto = "tcp"
+	`), 0700)
+	assert.NoError(t, err)
+
+	cfg := NewConfig()
+	err = cfg.LoadUserConfig(configFileName)
+	assert.NoError(t, err)
+
+	// when: file is changed with one valid and one invalid value
+	err = ioutil.WriteFile(configFileName, []byte(`
+		[openvpn]
+		port = 70000
+
+		[shaper]
+		enabled = true
+	`), 0700)
+	assert.NoError(t, err)
+	changes, err := cfg.ReloadUserConfig()
+
+	// then: valid changes are applied and invalid value is kept
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Key: "openvpn.proto", Old: "tcp", New: nil},
+		{Key: "shaper.enabled", Old: nil, New: true},
+	}, changes)
+	assert.Equal(t, 31338, cfg.GetInt("openvpn.port"))
+	assert.Nil(t, cfg.Get("openvpn.proto"))
+	assert.True(t, cfg.GetBool("shaper.enabled"))
+
+	// when: file is reloaded without changes
+	changes, err = cfg.ReloadUserConfig()
+
+	// then
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestUserConfig_ApplyUserValues(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetUser("openvpn.port", 5522)
+
+	changes := cfg.ApplyUserValues(map[string]interface{}{
+		"openvpn.port":  5522,
+		"openvpn.proto": "udp",
+		"shaper":        nil,
+	})
+
+	assert.Equal(t, []Change{{Key: "openvpn.proto", Old: nil, New: "udp"}}, changes)
+	assert.Equal(t, "udp", cfg.GetString("openvpn.proto"))
+}
+
 func NewTempFileName(t *testing.T) string {
 	file, err := ioutil.TempFile("", "*")
 	assert.NoError(t, err)
@@ -29,6 +29,12 @@ var (
 		Usage: "hermes contract address used to register identity",
 		Value: metadata.DefaultNetwork.HermesID,
 	}
+	// FlagHermesAcceptedIDs determines additional hermeses the node accepts or makes payments through
+	FlagHermesAcceptedIDs = cli.StringSliceFlag{
+		Name:  "hermes.accepted-ids",
+		Usage: "additional hermes contract addresses to accept payments through as a provider or to pay through as a consumer, the registration hermes is always used",
+		Value: cli.NewStringSlice(),
+	}
 )
 
 // RegisterFlagsHermes function register network flags to flag list
@@ -36,10 +42,12 @@ func RegisterFlagsHermes(flags *[]cli.Flag) {
 	*flags = append(
 		*flags,
 		&FlagHermesID,
+		&FlagHermesAcceptedIDs,
 	)
 }
 
 // ParseFlagsHermes function fills in hermes options from CLI context
 func ParseFlagsHermes(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagHermesID)
+	Current.ParseStringSliceFlag(ctx, FlagHermesAcceptedIDs)
 }
@@ -71,9 +71,15 @@ var (
 	// FlagPortMapping enables NAT port mapping.
 	FlagPortMapping = cli.BoolFlag{
 		Name:  "nat-port-mapping",
-		Usage: "Enables NAT port mapping",
+		Usage: "Enables NAT port mapping via UPnP, NAT-PMP or PCP",
 		Value: true,
 	}
+	// FlagSTUNServers STUN servers used to detect NAT type.
+	FlagSTUNServers = cli.StringSliceFlag{
+		Name:  "stun-servers",
+		Usage: "STUN servers used to detect NAT type, at least two or one supporting RFC 5780 are needed. Empty list disables detection",
+		Value: cli.NewStringSlice("stun.stunprotocol.org:3478", "stun.l.google.com:19302"),
+	}
 	// FlagIncomingFirewall enables incoming traffic filtering.
 	FlagIncomingFirewall = cli.BoolFlag{
 		Name:  "incoming-firewall",
@@ -96,6 +102,7 @@ func RegisterFlagsNetwork(flags *[]cli.Flag) {
 		&FlagLocalnet,
 		&FlagPortMapping,
 		&FlagNATPunching,
+		&FlagSTUNServers,
 		&FlagAPIAddress,
 		&FlagBrokerAddress,
 		&FlagEtherRPC,
@@ -116,6 +123,7 @@ func ParseFlagsNetwork(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagEtherRPC)
 	Current.ParseBoolFlag(ctx, FlagPortMapping)
 	Current.ParseBoolFlag(ctx, FlagNATPunching)
+	Current.ParseStringSliceFlag(ctx, FlagSTUNServers)
 	Current.ParseBoolFlag(ctx, FlagIncomingFirewall)
 	Current.ParseBoolFlag(ctx, FlagOutgoingFirewall)
 	Current.ParseInt64Flag(ctx, FlagChainID)
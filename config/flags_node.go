@@ -102,12 +102,48 @@ var (
 		Name:  "shaper.enabled",
 		Usage: "Limit service bandwidth",
 	}
+	// FlagShaperBandwidthUplink default uplink bandwidth limit of the services.
+	FlagShaperBandwidthUplink = cli.IntFlag{
+		Name:  "shaper.bandwidth.uplink",
+		Usage: "Default uplink bandwidth limit of the services in Kbps, applied when shaper is enabled",
+		Value: 5000,
+	}
+	// FlagShaperBandwidthDownlink default downlink bandwidth limit of the services.
+	FlagShaperBandwidthDownlink = cli.IntFlag{
+		Name:  "shaper.bandwidth.downlink",
+		Usage: "Default downlink bandwidth limit of the services in Kbps, applied when shaper is enabled",
+		Value: 5000,
+	}
 	// FlagKeystoreLightweight determines the scrypt memory complexity.
 	FlagKeystoreLightweight = cli.BoolFlag{
 		Name:  "keystore.lightweight",
 		Usage: "Determines the scrypt memory complexity. If set to true, will use 4MB blocks instead of the standard 256MB ones",
 		Value: true,
 	}
+	// FlagKeystoreBackend selects where identity keys are kept.
+	FlagKeystoreBackend = cli.StringFlag{
+		Name:  "keystore.backend",
+		Usage: "Backend keeping identity keys. Options: { filesystem, vault (single encrypted file), remote (JSON-RPC signer) }",
+		Value: "filesystem",
+	}
+	// FlagKeystoreVaultFile path of the keystore vault file.
+	FlagKeystoreVaultFile = cli.StringFlag{
+		Name:  "keystore.vault.file",
+		Usage: "Path of the keystore vault file, defaults to vault.json in the keystore directory",
+		Value: "",
+	}
+	// FlagKeystoreRemoteURL URL of the remote signer.
+	FlagKeystoreRemoteURL = cli.StringFlag{
+		Name:  "keystore.remote.url",
+		Usage: "URL of the remote signer JSON-RPC endpoint, used by the remote keystore backend",
+		Value: "",
+	}
+	// FlagKeystoreRemoteTokenFile path of the file holding bearer token of the remote signer.
+	FlagKeystoreRemoteTokenFile = cli.StringFlag{
+		Name:  "keystore.remote.token-file",
+		Usage: "Path of the file holding bearer token sent to the remote signer",
+		Value: "",
+	}
 	// FlagLogHTTP enables HTTP payload logging.
 	FlagLogHTTP = cli.BoolFlag{
 		Name:  "log.http",
@@ -181,6 +217,12 @@ var (
 		Usage: "Enables pprof",
 		Value: false,
 	}
+	// FlagMetricsAddress address of the separate listener serving node metrics.
+	FlagMetricsAddress = cli.StringFlag{
+		Name:  "metrics.address",
+		Usage: "Address (host:port) to serve Prometheus metrics on, in addition to TequilAPI. Disabled when empty",
+		Value: "",
+	}
 	// FlagUIEnable enables built-in web UI for node.
 	FlagUIEnable = cli.BoolFlag{
 		Name:  "ui.enable",
@@ -217,6 +259,35 @@ var (
 		Usage: "Range of P2P listen ports (e.g. 51820:52075), value of 0:0 means disabled",
 		Value: "0:0",
 	}
+	// FlagP2PStreamPort sets the port for p2p TCP/TLS stream transport.
+	FlagP2PStreamPort = cli.IntFlag{
+		Name:  "p2p.stream.port",
+		Usage: "TCP port of the P2P stream transport used by consumers which are not able to use UDP, value of 0 means disabled",
+		Value: 0,
+	}
+	// FlagP2PStreamTLS enables TLS for p2p stream transport.
+	FlagP2PStreamTLS = cli.BoolFlag{
+		Name:  "p2p.stream.tls",
+		Usage: "Wrap P2P stream transport in TLS",
+	}
+	// FlagP2PSignalPort sets the port of the p2p HTTP signaling.
+	FlagP2PSignalPort = cli.IntFlag{
+		Name:  "p2p.signal.port",
+		Usage: "TCP port of the P2P HTTP signaling, which lets consumers exchange P2P config with provider having public IP without NATS broker, value of 0 means disabled. P2P signaling over DHT is served whenever DHT discovery is used",
+		Value: 0,
+	}
+	// FlagP2PRelayPort sets the port of the p2p relay.
+	FlagP2PRelayPort = cli.IntFlag{
+		Name:  "p2p.relay.port",
+		Usage: "UDP port of the P2P relay which forwards traffic of peers failed to punch NAT holes, value of 0 means disabled",
+		Value: 0,
+	}
+	// FlagP2PRelayAddresses sets the relays provider advertises for p2p channels.
+	FlagP2PRelayAddresses = cli.StringSliceFlag{
+		Name:  "p2p.relay.addresses",
+		Usage: `Addresses of P2P relays consumers can use when NAT hole punching fails (e.g. "relay.example.com:4500")`,
+		Value: cli.NewStringSlice(),
+	}
 
 	//FlagConsumer sets to run as consumer only which allows to skip bootstrap for some of the dependencies.
 	FlagConsumer = cli.BoolFlag{
@@ -254,7 +325,13 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagFirewallKillSwitch,
 		&FlagFirewallProtectedNetworks,
 		&FlagShaperEnabled,
+		&FlagShaperBandwidthUplink,
+		&FlagShaperBandwidthDownlink,
 		&FlagKeystoreLightweight,
+		&FlagKeystoreBackend,
+		&FlagKeystoreVaultFile,
+		&FlagKeystoreRemoteURL,
+		&FlagKeystoreRemoteTokenFile,
 		&FlagLogHTTP,
 		&FlagLogLevel,
 		&FlagOpenvpnBinary,
@@ -265,12 +342,18 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagTequilapiUsername,
 		&FlagTequilapiPassword,
 		&FlagPProfEnable,
+		&FlagMetricsAddress,
 		&FlagUIEnable,
 		&FlagUIAddress,
 		&FlagUIPort,
 		&FlagUserMode,
 		&FlagVendorID,
 		&FlagP2PListenPorts,
+		&FlagP2PStreamPort,
+		&FlagP2PStreamTLS,
+		&FlagP2PSignalPort,
+		&FlagP2PRelayPort,
+		&FlagP2PRelayAddresses,
 		&FlagConsumer,
 	)
 
@@ -302,7 +385,13 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseBoolFlag(ctx, FlagFirewallKillSwitch)
 	Current.ParseStringFlag(ctx, FlagFirewallProtectedNetworks)
 	Current.ParseBoolFlag(ctx, FlagShaperEnabled)
+	Current.ParseIntFlag(ctx, FlagShaperBandwidthUplink)
+	Current.ParseIntFlag(ctx, FlagShaperBandwidthDownlink)
 	Current.ParseBoolFlag(ctx, FlagKeystoreLightweight)
+	Current.ParseStringFlag(ctx, FlagKeystoreBackend)
+	Current.ParseStringFlag(ctx, FlagKeystoreVaultFile)
+	Current.ParseStringFlag(ctx, FlagKeystoreRemoteURL)
+	Current.ParseStringFlag(ctx, FlagKeystoreRemoteTokenFile)
 	Current.ParseBoolFlag(ctx, FlagLogHTTP)
 	Current.ParseStringFlag(ctx, FlagLogLevel)
 	Current.ParseStringFlag(ctx, FlagOpenvpnBinary)
@@ -313,12 +402,18 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagTequilapiUsername)
 	Current.ParseStringFlag(ctx, FlagTequilapiPassword)
 	Current.ParseBoolFlag(ctx, FlagPProfEnable)
+	Current.ParseStringFlag(ctx, FlagMetricsAddress)
 	Current.ParseBoolFlag(ctx, FlagUIEnable)
 	Current.ParseStringFlag(ctx, FlagUIAddress)
 	Current.ParseIntFlag(ctx, FlagUIPort)
 	Current.ParseBoolFlag(ctx, FlagUserMode)
 	Current.ParseStringFlag(ctx, FlagVendorID)
 	Current.ParseStringFlag(ctx, FlagP2PListenPorts)
+	Current.ParseIntFlag(ctx, FlagP2PStreamPort)
+	Current.ParseBoolFlag(ctx, FlagP2PStreamTLS)
+	Current.ParseIntFlag(ctx, FlagP2PSignalPort)
+	Current.ParseIntFlag(ctx, FlagP2PRelayPort)
+	Current.ParseStringSliceFlag(ctx, FlagP2PRelayAddresses)
 	Current.ParseBoolFlag(ctx, FlagConsumer)
 
 	ValidateAddressFlags(FlagTequilapiAddress)
@@ -43,6 +43,12 @@ var (
 		Value: 0.1,
 		Usage: "The percentage of balance before we settle promises",
 	}
+	// FlagPaymentsHermesPromiseSettleMaxFeeRatio represents the fraction of settled amount we're willing to pay in fees when settling early.
+	FlagPaymentsHermesPromiseSettleMaxFeeRatio = cli.Float64Flag{
+		Name:  "payments.hermes.promise.max-fee-ratio",
+		Value: 0,
+		Usage: "Settle promises before the balance threshold once transactor and hermes fees are at most this fraction of the amount, 0 disables it",
+	}
 	// FlagPaymentsHermesPromiseSettleTimeout represents the time we wait for confirmation of the promise settlement.
 	FlagPaymentsHermesPromiseSettleTimeout = cli.DurationFlag{
 		Name:  "payments.hermes.promise.timeout",
@@ -109,6 +115,30 @@ var (
 		Usage: "sets the upper limit of session payment value before forcing an invoice. If this value is exceeded before a payment interval is reached, an invoice is sent.",
 		Value: "30000000000000000",
 	}
+	// FlagPaymentsConsumerLimitSession sets the maximum amount consumer spends per session.
+	FlagPaymentsConsumerLimitSession = cli.StringFlag{
+		Name:  "payments.consumer.limit.session",
+		Usage: "Sets the maximum amount of MYST (in wei) spent per session. The connection is stopped once the limit is reached, value of 0 means no limit.",
+		Value: "0",
+	}
+	// FlagPaymentsConsumerLimitDaily sets the maximum amount consumer spends per day.
+	FlagPaymentsConsumerLimitDaily = cli.StringFlag{
+		Name:  "payments.consumer.limit.daily",
+		Usage: "Sets the maximum amount of MYST (in wei) spent per day (UTC). The connection is stopped once the limit is reached, value of 0 means no limit.",
+		Value: "0",
+	}
+	// FlagPaymentsConsumerLimitMonthly sets the maximum amount consumer spends per month.
+	FlagPaymentsConsumerLimitMonthly = cli.StringFlag{
+		Name:  "payments.consumer.limit.monthly",
+		Usage: "Sets the maximum amount of MYST (in wei) spent per month (UTC). The connection is stopped once the limit is reached, value of 0 means no limit.",
+		Value: "0",
+	}
+	// FlagPaymentsConsumerLimitAlertThreshold sets the part of the spending limit which triggers an alert.
+	FlagPaymentsConsumerLimitAlertThreshold = cli.Float64Flag{
+		Name:  "payments.consumer.limit.alert-threshold",
+		Usage: "Sets the part of the spending limit, which being spent triggers an alert. 0.8 means 80%, value of 0 disables alerts",
+		Value: 0.8,
+	}
 )
 
 // RegisterFlagsPayments function register payments flags to flag list.
@@ -118,6 +148,7 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 		&FlagPaymentsMaxHermesFee,
 		&FlagPaymentsBCTimeout,
 		&FlagPaymentsHermesPromiseSettleThreshold,
+		&FlagPaymentsHermesPromiseSettleMaxFeeRatio,
 		&FlagPaymentsHermesPromiseSettleTimeout,
 		&FlagPaymentsMystSCAddress,
 		&FlagPaymentsProviderInvoiceFrequency,
@@ -129,6 +160,10 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 		&FlagPaymentsMaxUnpaidInvoiceValue,
 		&FlagPaymentsWethAddress,
 		&FlagPaymentsDaiAddress,
+		&FlagPaymentsConsumerLimitSession,
+		&FlagPaymentsConsumerLimitDaily,
+		&FlagPaymentsConsumerLimitMonthly,
+		&FlagPaymentsConsumerLimitAlertThreshold,
 	)
 }
 
@@ -137,6 +172,7 @@ func ParseFlagsPayments(ctx *cli.Context) {
 	Current.ParseIntFlag(ctx, FlagPaymentsMaxHermesFee)
 	Current.ParseDurationFlag(ctx, FlagPaymentsBCTimeout)
 	Current.ParseFloat64Flag(ctx, FlagPaymentsHermesPromiseSettleThreshold)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsHermesPromiseSettleMaxFeeRatio)
 	Current.ParseDurationFlag(ctx, FlagPaymentsHermesPromiseSettleTimeout)
 	Current.ParseStringFlag(ctx, FlagPaymentsMystSCAddress)
 	Current.ParseDurationFlag(ctx, FlagPaymentsProviderInvoiceFrequency)
@@ -148,4 +184,8 @@ func ParseFlagsPayments(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagPaymentsMaxUnpaidInvoiceValue)
 	Current.ParseStringFlag(ctx, FlagPaymentsWethAddress)
 	Current.ParseStringFlag(ctx, FlagPaymentsDaiAddress)
+	Current.ParseStringFlag(ctx, FlagPaymentsConsumerLimitSession)
+	Current.ParseStringFlag(ctx, FlagPaymentsConsumerLimitDaily)
+	Current.ParseStringFlag(ctx, FlagPaymentsConsumerLimitMonthly)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsConsumerLimitAlertThreshold)
 }
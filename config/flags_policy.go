@@ -37,6 +37,12 @@ var (
 		Usage: `Proposal fetch interval { "30s", "3m", "1h20m30s" }`,
 		Value: 10 * time.Minute,
 	}
+	// FlagAccessPolicyFile path to the file of locally defined access policies.
+	FlagAccessPolicyFile = cli.StringFlag{
+		Name:  "access-policy.file",
+		Usage: "Path to JSON file with the list of local access policies, which are applied to all services",
+		Value: "",
+	}
 )
 
 // RegisterFlagsPolicy function registers Policy Oracle flags to flag list.
@@ -44,6 +50,7 @@ func RegisterFlagsPolicy(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagAccessPolicyAddress,
 		&FlagAccessPolicyFetchInterval,
+		&FlagAccessPolicyFile,
 	)
 }
 
@@ -51,4 +58,5 @@ func RegisterFlagsPolicy(flags *[]cli.Flag) {
 func ParseFlagsPolicy(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagAccessPolicyAddress)
 	Current.ParseDurationFlag(ctx, FlagAccessPolicyFetchInterval)
+	Current.ParseStringFlag(ctx, FlagAccessPolicyFile)
 }
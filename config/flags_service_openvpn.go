@@ -22,10 +22,10 @@ import (
 )
 
 var (
-	// FlagOpenvpnProtocol protocol for OpenVPN to use.
+	// FlagOpenvpnProtocol protocols for OpenVPN to use.
 	FlagOpenvpnProtocol = cli.StringFlag{
 		Name:  "openvpn.proto",
-		Usage: "OpenVPN protocol to use. Options: { udp, tcp }",
+		Usage: "OpenVPN protocol to use, several protocols are listened on simultaneously. Options: { udp, tcp, \"udp,tcp\" }",
 		Value: "udp",
 	}
 	// FlagOpenvpnPort port for OpenVPN to use.
@@ -61,6 +61,34 @@ var (
 		Name:  "openvpn.access-policies",
 		Usage: "Comma separated list that determines the access policies of the OpenVPN service.",
 	}
+	// FlagOpenVPNBandwidthUplink uplink bandwidth limit of the OpenVPN service.
+	FlagOpenVPNBandwidthUplink = cli.IntFlag{
+		Name:  "openvpn.bandwidth.uplink",
+		Usage: "Uplink bandwidth limit of the OpenVPN service in Kbps, shared by all sessions. If not specified, shaper default is used",
+		Value: 0,
+	}
+	// FlagOpenVPNBandwidthDownlink downlink bandwidth limit of the OpenVPN service.
+	FlagOpenVPNBandwidthDownlink = cli.IntFlag{
+		Name:  "openvpn.bandwidth.downlink",
+		Usage: "Downlink bandwidth limit of the OpenVPN service in Kbps, shared by all sessions. If not specified, shaper default is used",
+		Value: 0,
+	}
+	// FlagOpenVPNQuotaDataGB daily data quota of a single consumer of the OpenVPN service.
+	FlagOpenVPNQuotaDataGB = cli.Float64Flag{
+		Name:  "openvpn.quota.data-gb",
+		Usage: "Amount of data in GiB a single consumer is allowed to transfer per day using the OpenVPN service. If not specified, data is unlimited",
+	}
+	// FlagOpenVPNQuotaTime daily time quota of a single consumer of the OpenVPN service.
+	FlagOpenVPNQuotaTime = cli.DurationFlag{
+		Name:  "openvpn.quota.time",
+		Usage: "Total duration of sessions a single consumer is allowed per day using the OpenVPN service. If not specified, time is unlimited",
+	}
+	// FlagOpenVPNQuotaSessions concurrent sessions quota of a single consumer of the OpenVPN service.
+	FlagOpenVPNQuotaSessions = cli.IntFlag{
+		Name:  "openvpn.quota.sessions",
+		Usage: "Number of concurrent sessions with the provider a single consumer is allowed to have when using the OpenVPN service. If not specified, sessions are unlimited",
+		Value: 0,
+	}
 )
 
 // RegisterFlagsServiceOpenvpn registers OpenVPN CLI flags for parsing them later
@@ -73,6 +101,11 @@ func RegisterFlagsServiceOpenvpn(flags *[]cli.Flag) {
 		&FlagOpenVPNPriceMinute,
 		&FlagOpenVPNPriceGB,
 		&FlagOpenVPNAccessPolicies,
+		&FlagOpenVPNBandwidthUplink,
+		&FlagOpenVPNBandwidthDownlink,
+		&FlagOpenVPNQuotaDataGB,
+		&FlagOpenVPNQuotaTime,
+		&FlagOpenVPNQuotaSessions,
 	)
 }
 
@@ -85,4 +118,9 @@ func ParseFlagsServiceOpenvpn(ctx *cli.Context) {
 	Current.ParseFloat64Flag(ctx, FlagOpenVPNPriceMinute)
 	Current.ParseFloat64Flag(ctx, FlagOpenVPNPriceGB)
 	Current.ParseStringFlag(ctx, FlagOpenVPNAccessPolicies)
+	Current.ParseIntFlag(ctx, FlagOpenVPNBandwidthUplink)
+	Current.ParseIntFlag(ctx, FlagOpenVPNBandwidthDownlink)
+	Current.ParseFloat64Flag(ctx, FlagOpenVPNQuotaDataGB)
+	Current.ParseDurationFlag(ctx, FlagOpenVPNQuotaTime)
+	Current.ParseIntFlag(ctx, FlagOpenVPNQuotaSessions)
 }
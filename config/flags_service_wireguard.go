@@ -49,6 +49,34 @@ var (
 		Name:  "wireguard.access-policies",
 		Usage: "Comma separated list that determines the access policies of the wireguard service.",
 	}
+	// FlagWireguardBandwidthUplink uplink bandwidth limit of every wireguard session.
+	FlagWireguardBandwidthUplink = cli.IntFlag{
+		Name:  "wireguard.bandwidth.uplink",
+		Usage: "Uplink bandwidth limit of every wireguard session in Kbps. If not specified, shaper default is used",
+		Value: 0,
+	}
+	// FlagWireguardBandwidthDownlink downlink bandwidth limit of every wireguard session.
+	FlagWireguardBandwidthDownlink = cli.IntFlag{
+		Name:  "wireguard.bandwidth.downlink",
+		Usage: "Downlink bandwidth limit of every wireguard session in Kbps. If not specified, shaper default is used",
+		Value: 0,
+	}
+	// FlagWireguardQuotaDataGB daily data quota of a single consumer of the wireguard service.
+	FlagWireguardQuotaDataGB = cli.Float64Flag{
+		Name:  "wireguard.quota.data-gb",
+		Usage: "Amount of data in GiB a single consumer is allowed to transfer per day using the wireguard service. If not specified, data is unlimited",
+	}
+	// FlagWireguardQuotaTime daily time quota of a single consumer of the wireguard service.
+	FlagWireguardQuotaTime = cli.DurationFlag{
+		Name:  "wireguard.quota.time",
+		Usage: "Total duration of sessions a single consumer is allowed per day using the wireguard service. If not specified, time is unlimited",
+	}
+	// FlagWireguardQuotaSessions concurrent sessions quota of a single consumer of the wireguard service.
+	FlagWireguardQuotaSessions = cli.IntFlag{
+		Name:  "wireguard.quota.sessions",
+		Usage: "Number of concurrent sessions with the provider a single consumer is allowed to have when using the wireguard service. If not specified, sessions are unlimited",
+		Value: 0,
+	}
 )
 
 // RegisterFlagsServiceWireguard function register Wireguard flags to flag list
@@ -59,6 +87,11 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 		&FlagWireguardPriceMinute,
 		&FlagWireguardPriceGB,
 		&FlagWireguardAccessPolicies,
+		&FlagWireguardBandwidthUplink,
+		&FlagWireguardBandwidthDownlink,
+		&FlagWireguardQuotaDataGB,
+		&FlagWireguardQuotaTime,
+		&FlagWireguardQuotaSessions,
 	)
 }
 
@@ -69,4 +102,9 @@ func ParseFlagsServiceWireguard(ctx *cli.Context) {
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceMinute)
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceGB)
 	Current.ParseStringFlag(ctx, FlagWireguardAccessPolicies)
+	Current.ParseIntFlag(ctx, FlagWireguardBandwidthUplink)
+	Current.ParseIntFlag(ctx, FlagWireguardBandwidthDownlink)
+	Current.ParseFloat64Flag(ctx, FlagWireguardQuotaDataGB)
+	Current.ParseDurationFlag(ctx, FlagWireguardQuotaTime)
+	Current.ParseIntFlag(ctx, FlagWireguardQuotaSessions)
 }
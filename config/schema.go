@@ -0,0 +1,407 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cast"
+	"github.com/urfave/cli/v2"
+)
+
+// Value types of the configuration flags.
+const (
+	FlagTypeBool        = "bool"
+	FlagTypeInt         = "int"
+	FlagTypeInt64       = "int64"
+	FlagTypeUint64      = "uint64"
+	FlagTypeFloat64     = "float64"
+	FlagTypeDuration    = "duration"
+	FlagTypeString      = "string"
+	FlagTypeStringSlice = "string_slice"
+)
+
+// Effects of the changed configuration flag values.
+const (
+	// FlagEffectLive flags are applied as soon as they are changed.
+	FlagEffectLive = "live"
+	// FlagEffectServiceStart flags are applied to services started after the change.
+	FlagEffectServiceStart = "service_start"
+	// FlagEffectRestart flags are applied after the node restarts.
+	FlagEffectRestart = "restart"
+)
+
+// FlagSchema describes the type, allowed values and effect of a configuration flag.
+type FlagSchema struct {
+	Name            string      `json:"name"`
+	Type            string      `json:"type"`
+	Usage           string      `json:"usage"`
+	Default         interface{} `json:"default,omitempty"`
+	Min             *float64    `json:"min,omitempty"`
+	Max             *float64    `json:"max,omitempty"`
+	Options         []string    `json:"options,omitempty"`
+	Secret          bool        `json:"secret,omitempty"`
+	Effect          string      `json:"effect"`
+	RequiresRestart bool        `json:"requires_restart"`
+}
+
+// flagConstraint limits values of a flag on top of its type.
+type flagConstraint struct {
+	min, max *float64
+	options  []string
+	secret   bool
+}
+
+func bounds(min, max float64) flagConstraint {
+	return flagConstraint{min: &min, max: &max}
+}
+
+func atLeast(min float64) flagConstraint {
+	return flagConstraint{min: &min}
+}
+
+func oneOf(options ...string) flagConstraint {
+	return flagConstraint{options: options}
+}
+
+var secret = flagConstraint{secret: true}
+
+var flagConstraints = map[string]flagConstraint{
+	FlagTequilapiPort.Name:                             bounds(1, 65535),
+	FlagUIPort.Name:                                    bounds(1, 65535),
+	FlagOpenvpnPort.Name:                               bounds(0, 65535),
+	FlagDHTPort.Name:                                   bounds(0, 65535),
+	FlagP2PStreamPort.Name:                             bounds(0, 65535),
+	FlagP2PSignalPort.Name:                             bounds(0, 65535),
+	FlagP2PRelayPort.Name:                              bounds(0, 65535),
+	FlagShaperBandwidthUplink.Name:                     atLeast(0),
+	FlagShaperBandwidthDownlink.Name:                   atLeast(0),
+	FlagOpenVPNBandwidthUplink.Name:                    atLeast(0),
+	FlagOpenVPNBandwidthDownlink.Name:                  atLeast(0),
+	FlagWireguardBandwidthUplink.Name:                  atLeast(0),
+	FlagWireguardBandwidthDownlink.Name:                atLeast(0),
+	FlagOpenVPNQuotaDataGB.Name:                        atLeast(0),
+	FlagOpenVPNQuotaSessions.Name:                      atLeast(0),
+	FlagWireguardQuotaDataGB.Name:                      atLeast(0),
+	FlagWireguardQuotaSessions.Name:                    atLeast(0),
+	FlagOpenVPNPriceMinute.Name:                        atLeast(0),
+	FlagOpenVPNPriceGB.Name:                            atLeast(0),
+	FlagWireguardPriceMinute.Name:                      atLeast(0),
+	FlagWireguardPriceGB.Name:                          atLeast(0),
+	FlagNoopPriceMinute.Name:                           atLeast(0),
+	FlagNoopPriceGB.Name:                               atLeast(0),
+	FlagPaymentPricePerMinute.Name:                     atLeast(0),
+	FlagPaymentPricePerGB.Name:                         atLeast(0),
+	FlagPaymentsMaxHermesFee.Name:                      bounds(0, 10000),
+	FlagPaymentsHermesPromiseSettleThreshold.Name:      bounds(0, 1),
+	FlagPaymentsHermesPromiseSettleMaxFeeRatio.Name:    bounds(0, 1),
+	FlagPaymentsConsumerLimitAlertThreshold.Name:       bounds(0, 1),
+	FlagTransactorProviderMaxRegistrationAttempts.Name: atLeast(0),
+	FlagOpenvpnProtocol.Name:                           oneOf("udp", "tcp", "udp,tcp"),
+	FlagKeystoreBackend.Name:                           oneOf("filesystem", "vault", "remote"),
+	FlagDHTProtocol.Name:                               oneOf("udp", "tcp"),
+	FlagLocationType.Name:                              oneOf("oracle", "builtin", "mmdb", "manual"),
+	FlagQualityType.Name:                               oneOf("elastic", "morqa", "none"),
+	FlagDiscoveryType.Name:                             oneOf("api", "broker", "dht"),
+	FlagLogLevel.Name: oneOf(
+		zerolog.TraceLevel.String(),
+		zerolog.DebugLevel.String(),
+		zerolog.InfoLevel.String(),
+		zerolog.WarnLevel.String(),
+		zerolog.ErrorLevel.String(),
+		zerolog.FatalLevel.String(),
+		zerolog.PanicLevel.String(),
+		zerolog.Disabled.String(),
+	),
+	FlagTequilapiPassword.Name:  secret,
+	FlagIdentityPassphrase.Name: secret,
+	FlagMMNAPIKey.Name:          secret,
+}
+
+// liveFlags are applied by their subscribers of AppTopicConfig as soon as they are changed.
+var liveFlags = []string{
+	FlagShaperEnabled.Name,
+	FlagShaperBandwidthUplink.Name,
+	FlagShaperBandwidthDownlink.Name,
+}
+
+var (
+	schemaOnce sync.Once
+	schema     map[string]FlagSchema
+	schemaErr  error
+)
+
+// Schema returns schema of all node and service flags, sorted by name.
+func Schema() ([]FlagSchema, error) {
+	flags, err := schemaFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]FlagSchema, 0, len(flags))
+	for _, flag := range flags {
+		list = append(list, flag)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// SchemaOf returns schema of the flag with the given name.
+func SchemaOf(name string) (FlagSchema, bool) {
+	flags, err := schemaFlags()
+	if err != nil {
+		return FlagSchema{}, false
+	}
+	flag, ok := flags[strings.ToLower(name)]
+	return flag, ok
+}
+
+func schemaFlags() (map[string]FlagSchema, error) {
+	schemaOnce.Do(func() {
+		schema, schemaErr = buildSchema()
+	})
+	return schema, schemaErr
+}
+
+func buildSchema() (map[string]FlagSchema, error) {
+	var nodeFlags []cli.Flag
+	if err := RegisterFlagsNode(&nodeFlags); err != nil {
+		return nil, err
+	}
+	var serviceFlags []cli.Flag
+	RegisterFlagsServiceStart(&serviceFlags)
+	RegisterFlagsServiceOpenvpn(&serviceFlags)
+	RegisterFlagsServiceWireguard(&serviceFlags)
+	RegisterFlagsServiceNoop(&serviceFlags)
+
+	flags := make(map[string]FlagSchema)
+	add := func(list []cli.Flag, effect string) error {
+		for _, flag := range list {
+			flagSchema, err := newFlagSchema(flag)
+			if err != nil {
+				return err
+			}
+			flagSchema.Effect = effect
+			for _, name := range liveFlags {
+				if name == flagSchema.Name {
+					flagSchema.Effect = FlagEffectLive
+				}
+			}
+			flagSchema.RequiresRestart = flagSchema.Effect == FlagEffectRestart
+			flags[strings.ToLower(flagSchema.Name)] = flagSchema
+		}
+		return nil
+	}
+	if err := add(nodeFlags, FlagEffectRestart); err != nil {
+		return nil, err
+	}
+	if err := add(serviceFlags, FlagEffectServiceStart); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+func newFlagSchema(flag cli.Flag) (FlagSchema, error) {
+	var flagSchema FlagSchema
+	switch f := flag.(type) {
+	case *cli.BoolFlag:
+		flagSchema = FlagSchema{Name: f.Name, Type: FlagTypeBool, Usage: f.Usage, Default: f.Value}
+	case *cli.IntFlag:
+		flagSchema = FlagSchema{Name: f.Name, Type: FlagTypeInt, Usage: f.Usage, Default: f.Value}
+	case *cli.Int64Flag:
+		flagSchema = FlagSchema{Name: f.Name, Type: FlagTypeInt64, Usage: f.Usage, Default: f.Value}
+	case *cli.Uint64Flag:
+		flagSchema = FlagSchema{Name: f.Name, Type: FlagTypeUint64, Usage: f.Usage, Default: f.Value}
+	case *cli.Float64Flag:
+		flagSchema = FlagSchema{Name: f.Name, Type: FlagTypeFloat64, Usage: f.Usage, Default: f.Value}
+	case *cli.DurationFlag:
+		flagSchema = FlagSchema{Name: f.Name, Type: FlagTypeDuration, Usage: f.Usage, Default: f.Value.String()}
+	case *cli.StringFlag:
+		flagSchema = FlagSchema{Name: f.Name, Type: FlagTypeString, Usage: f.Usage, Default: f.Value}
+	case *cli.StringSliceFlag:
+		flagSchema = FlagSchema{Name: f.Name, Type: FlagTypeStringSlice, Usage: f.Usage}
+		if f.Value != nil {
+			flagSchema.Default = f.Value.Value()
+		}
+	default:
+		return FlagSchema{}, fmt.Errorf("unsupported flag type %T of %v", flag, flag.Names())
+	}
+
+	constraint := flagConstraints[flagSchema.Name]
+	flagSchema.Min = constraint.min
+	flagSchema.Max = constraint.max
+	flagSchema.Options = constraint.options
+	flagSchema.Secret = constraint.secret
+	if flagSchema.Secret {
+		flagSchema.Default = nil
+	}
+	return flagSchema, nil
+}
+
+// Validate checks the value against the flag schema and returns it converted to the flag type.
+// Durations are returned as strings, so they are readable in the user configuration.
+func (s FlagSchema) Validate(value interface{}) (interface{}, error) {
+	var converted interface{}
+	var err error
+	switch s.Type {
+	case FlagTypeBool:
+		converted, err = cast.ToBoolE(value)
+	case FlagTypeInt:
+		converted, err = toInteger(value, func(v interface{}) (interface{}, error) { return cast.ToIntE(v) })
+	case FlagTypeInt64:
+		converted, err = toInteger(value, func(v interface{}) (interface{}, error) { return cast.ToInt64E(v) })
+	case FlagTypeUint64:
+		converted, err = toInteger(value, func(v interface{}) (interface{}, error) { return cast.ToUint64E(v) })
+	case FlagTypeFloat64:
+		converted, err = cast.ToFloat64E(value)
+	case FlagTypeDuration:
+		var d time.Duration
+		d, err = cast.ToDurationE(value)
+		converted = d.String()
+	case FlagTypeString:
+		converted, err = toString(value)
+	case FlagTypeStringSlice:
+		converted, err = toStringSlice(value)
+	default:
+		err = fmt.Errorf("unknown type %s", s.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %v: %w", s.Type, value, err)
+	}
+
+	if s.Min != nil || s.Max != nil {
+		number, err := cast.ToFloat64E(converted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %v: %w", s.Type, value, err)
+		}
+		if s.Min != nil && number < *s.Min {
+			return nil, fmt.Errorf("value %v is less than %v", value, *s.Min)
+		}
+		if s.Max != nil && number > *s.Max {
+			return nil, fmt.Errorf("value %v is greater than %v", value, *s.Max)
+		}
+	}
+
+	if len(s.Options) > 0 {
+		values, ok := converted.([]string)
+		if !ok {
+			values = []string{fmt.Sprint(converted)}
+		}
+		for _, v := range values {
+			if !s.allows(v) {
+				return nil, fmt.Errorf("value %q is not one of %s", v, strings.Join(s.Options, ", "))
+			}
+		}
+	}
+	return converted, nil
+}
+
+func (s FlagSchema) allows(value string) bool {
+	for _, option := range s.Options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// toInteger converts the value to integer, rejecting fractions which cast would truncate.
+func toInteger(value interface{}, convert func(interface{}) (interface{}, error)) (interface{}, error) {
+	if f, ok := value.(float64); ok && f != float64(int64(f)) {
+		return nil, fmt.Errorf("%v is not an integer", f)
+	}
+	return convert(value)
+}
+
+// toString converts scalar values only, cast would format maps and slices too.
+func toString(value interface{}) (string, error) {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, []string:
+		return "", fmt.Errorf("%T is not a string", value)
+	}
+	return cast.ToStringE(value)
+}
+
+func toStringSlice(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return strings.Split(v, ","), nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		res := make([]string, len(v))
+		for i, item := range v {
+			s, err := toString(item)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = s
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("%T is not a list of strings", value)
+	}
+}
+
+// ValidateUserValues flattens nested values to dotted keys and validates the values of known flags.
+// Values of unknown keys are returned as they are, nil values mean removal and are not validated.
+func ValidateUserValues(values map[string]interface{}) (map[string]interface{}, map[string]error) {
+	flat := flattenMap("", values)
+	errs := make(map[string]error)
+	for key, value := range flat {
+		flagSchema, ok := SchemaOf(key)
+		if !ok || value == nil {
+			continue
+		}
+		converted, err := flagSchema.Validate(value)
+		if err != nil {
+			errs[key] = err
+			delete(flat, key)
+			continue
+		}
+		flat[key] = converted
+	}
+	return flat, errs
+}
+
+func flattenMap(prefix string, values map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	for key, value := range values {
+		key = strings.ToLower(key)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if _, known := SchemaOf(key); !known {
+				for k, v := range flattenMap(key, nested) {
+					flat[k] = v
+				}
+				continue
+			}
+		}
+		flat[key] = value
+	}
+	return flat
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_DescribesFlags(t *testing.T) {
+	flags, err := Schema()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, flags)
+
+	port, ok := SchemaOf(FlagOpenvpnPort.Name)
+	assert.True(t, ok)
+	assert.Equal(t, FlagTypeInt, port.Type)
+	assert.Equal(t, FlagEffectServiceStart, port.Effect)
+	assert.Equal(t, 65535.0, *port.Max)
+
+	shaper, ok := SchemaOf(FlagShaperEnabled.Name)
+	assert.True(t, ok)
+	assert.Equal(t, FlagEffectLive, shaper.Effect)
+	assert.False(t, shaper.RequiresRestart)
+
+	password, ok := SchemaOf(FlagTequilapiPassword.Name)
+	assert.True(t, ok)
+	assert.True(t, password.Secret)
+	assert.True(t, password.RequiresRestart)
+	assert.Nil(t, password.Default)
+}
+
+func TestValidateUserValues(t *testing.T) {
+	values, errs := ValidateUserValues(map[string]interface{}{
+		"openvpn": map[string]interface{}{
+			"port":  5522.0,
+			"proto": "tcp",
+		},
+		FlagPaymentsBCTimeout.Name: "1m",
+		FlagDiscoveryType.Name:     []interface{}{"api", "dht"},
+		FlagTequilapiPassword.Name: nil,
+		"access-policy": map[string]interface{}{
+			"list": "mysterium",
+		},
+	})
+
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]interface{}{
+		FlagOpenvpnPort.Name:       5522,
+		FlagOpenvpnProtocol.Name:   "tcp",
+		FlagPaymentsBCTimeout.Name: "1m0s",
+		FlagDiscoveryType.Name:     []string{"api", "dht"},
+		FlagTequilapiPassword.Name: nil,
+		"access-policy.list":       "mysterium",
+	}, values)
+}
+
+func TestValidateUserValues_ReturnsErrors(t *testing.T) {
+	values, errs := ValidateUserValues(map[string]interface{}{
+		FlagOpenvpnPort.Name:                          70000,
+		FlagTequilapiPort.Name:                        4050.5,
+		FlagOpenvpnProtocol.Name:                      "icmp",
+		FlagPaymentsHermesPromiseSettleThreshold.Name: "a lot",
+		FlagDiscoveryType.Name:                        "api,ftp",
+		FlagUIEnable.Name:                             false,
+	})
+
+	assert.Len(t, errs, 5)
+	assert.Contains(t, errs, FlagOpenvpnPort.Name)
+	assert.Contains(t, errs, FlagTequilapiPort.Name)
+	assert.Contains(t, errs, FlagOpenvpnProtocol.Name)
+	assert.Contains(t, errs, FlagPaymentsHermesPromiseSettleThreshold.Name)
+	assert.Contains(t, errs, FlagDiscoveryType.Name)
+	assert.Equal(t, map[string]interface{}{FlagUIEnable.Name: false}, values)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/rs/zerolog/log"
 )
 
@@ -35,14 +36,17 @@ type publisher interface {
 
 // NewTracker creates instance of Tracker
 func NewTracker(publisher publisher) *Tracker {
-	return &Tracker{publisher: publisher}
+	return &Tracker{
+		publisher: publisher,
+		previous:  make(map[session.ID]connectionstate.Statistics),
+	}
 }
 
-// Tracker keeps track of current speed
+// Tracker keeps track of current speed of every connection session
 type Tracker struct {
 	publisher publisher
 
-	previous connectionstate.Statistics
+	previous map[session.ID]connectionstate.Statistics
 	lock     sync.RWMutex
 }
 
@@ -63,20 +67,23 @@ func (t *Tracker) consumeStatisticsEvent(evt connectionstate.AppEventConnectionS
 		t.lock.Unlock()
 	}()
 
+	sessionID := evt.SessionInfo.SessionID
+	previous := t.previous[sessionID]
+
 	// Skip speed calculation on the very first event.
-	if t.previous.At.IsZero() {
-		t.previous = evt.Stats
+	if previous.At.IsZero() {
+		t.previous[sessionID] = evt.Stats
 		return
 	}
 
-	secondsSince := evt.Stats.At.Sub(t.previous.At).Seconds()
+	secondsSince := evt.Stats.At.Sub(previous.At).Seconds()
 	if secondsSince < consumeCooldown.Seconds() {
 		log.Trace().Msgf("%fs passed since the last consumption, ignoring the event", secondsSince)
 		return
 	}
 
-	byteDownDiff := evt.Stats.BytesReceived - t.previous.BytesReceived
-	byteUpDiff := evt.Stats.BytesSent - t.previous.BytesSent
+	byteDownDiff := evt.Stats.BytesReceived - previous.BytesReceived
+	byteUpDiff := evt.Stats.BytesSent - previous.BytesSent
 
 	t.publisher.Publish(AppTopicConnectionThroughput, AppEventConnectionThroughput{
 		Throughput: Throughput{
@@ -85,7 +92,7 @@ func (t *Tracker) consumeStatisticsEvent(evt connectionstate.AppEventConnectionS
 		},
 		SessionInfo: evt.SessionInfo,
 	})
-	t.previous = evt.Stats
+	t.previous[sessionID] = evt.Stats
 }
 
 // consumeSessionEvent handles the session state changes
@@ -94,6 +101,6 @@ func (t *Tracker) consumeSessionEvent(sessionEvent connectionstate.AppEventConne
 	defer t.lock.Unlock()
 	switch sessionEvent.Status {
 	case connectionstate.SessionEndedStatus, connectionstate.SessionCreatedStatus:
-		t.previous = connectionstate.Statistics{}
+		delete(t.previous, sessionEvent.SessionInfo.SessionID)
 	}
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,42 +36,44 @@ func Test_ThroughputStringOutput(t *testing.T) {
 func Test_ConsumeSessionEvent_ResetsOnConnect(t *testing.T) {
 	tracker := Tracker{
 		publisher: mocks.NewEventBus(),
-		previous: connectionstate.Statistics{
-			At:            time.Now(),
-			BytesReceived: 1,
-			BytesSent:     1,
+		previous: map[session.ID]connectionstate.Statistics{
+			"1": {
+				At:            time.Now(),
+				BytesReceived: 1,
+				BytesSent:     1,
+			},
 		},
 	}
 	tracker.consumeSessionEvent(connectionstate.AppEventConnectionSession{
-		Status: connectionstate.SessionCreatedStatus,
+		Status:      connectionstate.SessionCreatedStatus,
+		SessionInfo: connectionstate.Status{SessionID: "1"},
 	})
 
-	assert.True(t, tracker.previous.At.IsZero())
-	assert.Zero(t, tracker.previous.BytesReceived)
-	assert.Zero(t, tracker.previous.BytesSent)
+	assert.NotContains(t, tracker.previous, session.ID("1"))
 }
 
 func Test_ConsumeSessionEvent_ResetsOnDisconnect(t *testing.T) {
 	tracker := Tracker{
 		publisher: mocks.NewEventBus(),
-		previous: connectionstate.Statistics{
-			At:            time.Now(),
-			BytesReceived: 1,
-			BytesSent:     1,
+		previous: map[session.ID]connectionstate.Statistics{
+			"1": {
+				At:            time.Now(),
+				BytesReceived: 1,
+				BytesSent:     1,
+			},
 		},
 	}
 	tracker.consumeSessionEvent(connectionstate.AppEventConnectionSession{
-		Status: connectionstate.SessionEndedStatus,
+		Status:      connectionstate.SessionEndedStatus,
+		SessionInfo: connectionstate.Status{SessionID: "1"},
 	})
 
-	assert.True(t, tracker.previous.At.IsZero())
-	assert.Zero(t, tracker.previous.BytesReceived)
-	assert.Zero(t, tracker.previous.BytesSent)
+	assert.NotContains(t, tracker.previous, session.ID("1"))
 }
 
 func Test_ConsumeStatisticsEvent_SkipsOnZero(t *testing.T) {
 	publisher := mocks.NewEventBus()
-	tracker := Tracker{publisher: publisher}
+	tracker := NewTracker(publisher)
 	e := connectionstate.AppEventConnectionStatistics{
 		Stats: connectionstate.Statistics{
 			At:            time.Now(),
@@ -79,15 +82,15 @@ func Test_ConsumeStatisticsEvent_SkipsOnZero(t *testing.T) {
 		},
 	}
 	tracker.consumeStatisticsEvent(e)
-	assert.False(t, tracker.previous.At.IsZero())
-	assert.Equal(t, e.Stats.BytesReceived, tracker.previous.BytesReceived)
-	assert.Equal(t, e.Stats.BytesSent, tracker.previous.BytesSent)
+	assert.False(t, tracker.previous[""].At.IsZero())
+	assert.Equal(t, e.Stats.BytesReceived, tracker.previous[""].BytesReceived)
+	assert.Equal(t, e.Stats.BytesSent, tracker.previous[""].BytesSent)
 	assert.Nil(t, publisher.Pop())
 }
 
 func Test_ConsumeStatisticsEvent_Regression_1674_InsaneSpeedReports(t *testing.T) {
 	publisher := mocks.NewEventBus()
-	tracker := Tracker{publisher: publisher}
+	tracker := NewTracker(publisher)
 	tracker.consumeStatisticsEvent(connectionstate.AppEventConnectionStatistics{
 		Stats: connectionstate.Statistics{
 			At:            time.Now(),
@@ -115,3 +118,23 @@ func Test_ConsumeStatisticsEvent_Regression_1674_InsaneSpeedReports(t *testing.T
 	lastEvent := publisher.Pop().(AppEventConnectionThroughput)
 	assert.InDelta(t, 4096, datasize.BitSize(lastEvent.Throughput.Down).Bytes(), 1024)
 }
+
+func Test_ConsumeStatisticsEvent_TracksSessionsSeparately(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	tracker := NewTracker(publisher)
+	statistics := func(sessionID session.ID, at time.Time, bytes uint64) connectionstate.AppEventConnectionStatistics {
+		return connectionstate.AppEventConnectionStatistics{
+			Stats:       connectionstate.Statistics{At: at, BytesSent: bytes, BytesReceived: bytes},
+			SessionInfo: connectionstate.Status{SessionID: sessionID},
+		}
+	}
+
+	start := time.Now()
+	tracker.consumeStatisticsEvent(statistics("1", start, 0))
+	tracker.consumeStatisticsEvent(statistics("2", start, 1000000))
+	tracker.consumeStatisticsEvent(statistics("1", start.Add(time.Second), 1024))
+
+	lastEvent := publisher.Pop().(AppEventConnectionThroughput)
+	assert.Equal(t, session.ID("1"), lastEvent.SessionInfo.SessionID)
+	assert.InDelta(t, 1024, datasize.BitSize(lastEvent.Throughput.Down).Bytes(), 1)
+}
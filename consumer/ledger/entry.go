@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledger
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	// EntryTypeSession marks a session provided or consumed by the identity.
+	EntryTypeSession = "session"
+	// EntryTypeInvoice marks an invoice paid by the identity as a consumer.
+	EntryTypeInvoice = "invoice"
+	// EntryTypePromise marks a hermes promise received by the identity as a provider.
+	EntryTypePromise = "promise"
+	// EntryTypeSettlement marks a settlement of hermes promises.
+	EntryTypeSettlement = "settlement"
+)
+
+// Entry is a single ledger row. Amount is the tokens spent or earned by this entry depending on its direction,
+// Total is the running total of the agreement (for invoices) or the channel (for promises and settlements).
+type Entry struct {
+	ID        string `storm:"id"`
+	Type      string
+	Time      time.Time
+	Identity  identity.Identity
+	Peer      identity.Identity
+	HermesID  string
+	Direction string
+	// Reference is session ID for sessions and invoices, channel ID for promises and transaction hash for settlements.
+	Reference    string
+	Amount       *big.Int
+	Total        *big.Int
+	DataSent     uint64
+	DataReceived uint64
+	Duration     time.Duration
+}
+
+const (
+	// SortByTime sorts entries by their time.
+	SortByTime = "time"
+	// SortByAmount sorts entries by their amount.
+	SortByAmount = "amount"
+	// SortByType sorts entries by their type, then by time.
+	SortByType = "type"
+)
+
+const (
+	// OrderAsc sorts entries in ascending order.
+	OrderAsc = "asc"
+	// OrderDesc sorts entries in descending order.
+	OrderDesc = "desc"
+)
+
+// Filter defines ledger entries to be listed.
+type Filter struct {
+	Identity *identity.Identity
+	TimeFrom *time.Time
+	TimeTo   *time.Time
+	Types    []string
+	SortBy   string
+	Order    string
+}
+
+// NewFilter creates filter which lists all entries, the newest first.
+func NewFilter() *Filter {
+	return &Filter{SortBy: SortByTime, Order: OrderDesc}
+}
+
+// SetIdentity filters entries of the given identity.
+func (f *Filter) SetIdentity(id identity.Identity) *Filter {
+	f.Identity = &id
+	return f
+}
+
+// SetTimeFrom filters entries from given time.
+func (f *Filter) SetTimeFrom(from time.Time) *Filter {
+	from = from.UTC()
+	f.TimeFrom = &from
+	return f
+}
+
+// SetTimeTo filters entries to given time.
+func (f *Filter) SetTimeTo(to time.Time) *Filter {
+	to = to.UTC()
+	f.TimeTo = &to
+	return f
+}
+
+// SetTypes filters entries of given types.
+func (f *Filter) SetTypes(types ...string) *Filter {
+	f.Types = types
+	return f
+}
+
+// SetSort sets the field and the order entries are sorted by.
+func (f *Filter) SetSort(sortBy, order string) *Filter {
+	f.SortBy = sortBy
+	f.Order = order
+	return f
+}
+
+func (f *Filter) hasType(entryType string) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == entryType {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matches(entry Entry) bool {
+	if f.Identity != nil && *f.Identity != entry.Identity {
+		return false
+	}
+	if f.TimeFrom != nil && entry.Time.Before(*f.TimeFrom) {
+		return false
+	}
+	if f.TimeTo != nil && entry.Time.After(*f.TimeTo) {
+		return false
+	}
+	return f.hasType(entry.Type)
+}
+
+func (f *Filter) sort(entries []Entry) {
+	less := func(a, b Entry) bool {
+		return a.Time.Before(b.Time)
+	}
+	switch f.SortBy {
+	case SortByAmount:
+		less = func(a, b Entry) bool {
+			return amount(a).Cmp(amount(b)) < 0
+		}
+	case SortByType:
+		less = func(a, b Entry) bool {
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			return a.Time.Before(b.Time)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if f.Order == OrderDesc {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+}
+
+func amount(entry Entry) *big.Int {
+	if entry.Amount == nil {
+		return new(big.Int)
+	}
+	return entry.Amount
+}
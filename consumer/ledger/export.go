@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledger
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{
+	"id", "type", "time", "identity", "peer", "hermes_id", "direction", "reference",
+	"amount", "total", "bytes_sent", "bytes_received", "duration",
+}
+
+// WriteCSV writes entries as CSV with a header row, durations are in seconds.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		record := []string{
+			entry.ID,
+			entry.Type,
+			entry.Time.Format(time.RFC3339),
+			entry.Identity.Address,
+			entry.Peer.Address,
+			entry.HermesID,
+			entry.Direction,
+			entry.Reference,
+			bigIntString(entry.Amount),
+			bigIntString(entry.Total),
+			strconv.FormatUint(entry.DataSent, 10),
+			strconv.FormatUint(entry.DataReceived, 10),
+			strconv.FormatInt(int64(entry.Duration.Seconds()), 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func bigIntString(value *big.Int) string {
+	if value == nil {
+		return "0"
+	}
+	return value.String()
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledger
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+type entryStorage interface {
+	List(filter *Filter) ([]Entry, error)
+}
+
+type sessionStorage interface {
+	List(filter *session.Filter) ([]session.History, error)
+}
+
+type settlementStorage interface {
+	List(filter pingpong.SettlementHistoryFilter) ([]pingpong.SettlementHistoryEntry, error)
+}
+
+// Ledger joins sessions, paid invoices, received hermes promises and settlements into a single list of entries.
+type Ledger struct {
+	entries     entryStorage
+	sessions    sessionStorage
+	settlements settlementStorage
+}
+
+// NewLedger creates ledger with given dependencies.
+func NewLedger(entries entryStorage, sessions sessionStorage, settlements settlementStorage) *Ledger {
+	return &Ledger{
+		entries:     entries,
+		sessions:    sessions,
+		settlements: settlements,
+	}
+}
+
+// List returns filtered and sorted ledger entries.
+func (l *Ledger) List(filter *Filter) ([]Entry, error) {
+	var result []Entry
+
+	if filter.hasType(EntryTypeSession) {
+		sessions, err := l.listSessions(filter)
+		if err != nil {
+			return nil, fmt.Errorf("could not list sessions: %w", err)
+		}
+		result = append(result, sessions...)
+	}
+
+	if filter.hasType(EntryTypeInvoice) || filter.hasType(EntryTypePromise) {
+		entries, err := l.entries.List(filter)
+		if err != nil {
+			return nil, fmt.Errorf("could not list ledger entries: %w", err)
+		}
+		for _, entry := range entries {
+			if filter.matches(entry) {
+				result = append(result, entry)
+			}
+		}
+	}
+
+	if filter.hasType(EntryTypeSettlement) {
+		settlements, err := l.listSettlements(filter)
+		if err != nil {
+			return nil, fmt.Errorf("could not list settlements: %w", err)
+		}
+		result = append(result, settlements...)
+	}
+
+	if result == nil {
+		result = []Entry{}
+	}
+	filter.sort(result)
+	return result, nil
+}
+
+func (l *Ledger) listSessions(filter *Filter) ([]Entry, error) {
+	sessionFilter := session.NewFilter()
+	if filter.TimeFrom != nil {
+		sessionFilter.SetStartedFrom(*filter.TimeFrom)
+	}
+	if filter.TimeTo != nil {
+		sessionFilter.SetStartedTo(*filter.TimeTo)
+	}
+
+	sessions, err := l.sessions.List(sessionFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Entry, 0, len(sessions))
+	for _, se := range sessions {
+		if se.Tokens == nil {
+			se.Tokens = new(big.Int)
+		}
+		entry := Entry{
+			ID:           string(se.SessionID),
+			Type:         EntryTypeSession,
+			Time:         se.Started,
+			HermesID:     se.HermesID,
+			Direction:    se.Direction,
+			Reference:    string(se.SessionID),
+			Amount:       se.Tokens,
+			Total:        se.Tokens,
+			DataSent:     se.DataSent,
+			DataReceived: se.DataReceived,
+			Duration:     se.GetDuration(),
+		}
+		if se.Direction == session.DirectionConsumed {
+			entry.Identity, entry.Peer = se.ConsumerID, se.ProviderID
+		} else {
+			entry.Identity, entry.Peer = se.ProviderID, se.ConsumerID
+		}
+
+		if filter.matches(entry) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (l *Ledger) listSettlements(filter *Filter) ([]Entry, error) {
+	settlementFilter := pingpong.SettlementHistoryFilter{
+		TimeFrom:   filter.TimeFrom,
+		TimeTo:     filter.TimeTo,
+		ProviderID: filter.Identity,
+	}
+
+	settlements, err := l.settlements.List(settlementFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Entry, 0, len(settlements))
+	for _, settlement := range settlements {
+		entry := Entry{
+			ID:        settlement.TxHash.Hex(),
+			Type:      EntryTypeSettlement,
+			Time:      settlement.Time,
+			Identity:  settlement.ProviderID,
+			HermesID:  settlement.HermesID.Hex(),
+			Direction: session.DirectionProvided,
+			Reference: settlement.TxHash.Hex(),
+			Amount:    settlement.Amount,
+			Total:     settlement.TotalSettled,
+		}
+		if filter.matches(entry) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
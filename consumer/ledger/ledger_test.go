@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledger
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/identity"
+	node_session "github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	consumerID = identity.FromAddress("0x1")
+	providerID = identity.FromAddress("0x2")
+	hermesID   = common.HexToAddress("0x3")
+
+	sessionConsumed = session.History{
+		SessionID:    node_session.ID("session1"),
+		Direction:    session.DirectionConsumed,
+		ConsumerID:   consumerID,
+		ProviderID:   providerID,
+		HermesID:     hermesID.Hex(),
+		DataSent:     10,
+		DataReceived: 20,
+		Tokens:       big.NewInt(300),
+		Started:      time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC),
+		Updated:      time.Date(2020, 7, 1, 10, 2, 0, 0, time.UTC),
+	}
+	invoicePaid = Entry{
+		ID:        "invoice1",
+		Type:      EntryTypeInvoice,
+		Time:      time.Date(2020, 7, 1, 10, 1, 0, 0, time.UTC),
+		Identity:  consumerID,
+		Peer:      providerID,
+		Direction: session.DirectionConsumed,
+		Reference: "session1",
+		Amount:    big.NewInt(300),
+		Total:     big.NewInt(300),
+	}
+	promiseReceived = Entry{
+		ID:        "promise1",
+		Type:      EntryTypePromise,
+		Time:      time.Date(2020, 7, 2, 10, 0, 0, 0, time.UTC),
+		Identity:  providerID,
+		HermesID:  hermesID.Hex(),
+		Direction: session.DirectionProvided,
+		Reference: "0x01",
+		Amount:    big.NewInt(500),
+		Total:     big.NewInt(500),
+	}
+	settlement = pingpong.SettlementHistoryEntry{
+		TxHash:       common.HexToHash("0x4"),
+		ProviderID:   providerID,
+		HermesID:     hermesID,
+		Time:         time.Date(2020, 7, 3, 10, 0, 0, 0, time.UTC),
+		Amount:       big.NewInt(400),
+		TotalSettled: big.NewInt(400),
+	}
+)
+
+func TestLedger_List_JoinsEntriesOfIdentity(t *testing.T) {
+	// given
+	ledger := newTestLedger()
+
+	// when
+	entries, err := ledger.List(NewFilter().SetIdentity(consumerID))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		invoicePaid,
+		{
+			ID:           "session1",
+			Type:         EntryTypeSession,
+			Time:         sessionConsumed.Started,
+			Identity:     consumerID,
+			Peer:         providerID,
+			HermesID:     hermesID.Hex(),
+			Direction:    session.DirectionConsumed,
+			Reference:    "session1",
+			Amount:       big.NewInt(300),
+			Total:        big.NewInt(300),
+			DataSent:     10,
+			DataReceived: 20,
+			Duration:     2 * time.Minute,
+		},
+	}, entries)
+}
+
+func TestLedger_List_FiltersByTimeAndType(t *testing.T) {
+	// given
+	ledger := newTestLedger()
+
+	// when
+	entries, err := ledger.List(NewFilter().
+		SetTimeFrom(time.Date(2020, 7, 2, 0, 0, 0, 0, time.UTC)).
+		SetTypes(EntryTypeSettlement))
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, EntryTypeSettlement, entries[0].Type)
+	assert.Equal(t, providerID, entries[0].Identity)
+	assert.Equal(t, settlement.TxHash.Hex(), entries[0].Reference)
+	assert.Equal(t, big.NewInt(400), entries[0].Amount)
+}
+
+func TestLedger_List_SortsEntries(t *testing.T) {
+	ledger := newTestLedger()
+
+	tests := []struct {
+		sortBy   string
+		order    string
+		expected []string
+	}{
+		{SortByTime, OrderAsc, []string{EntryTypeSession, EntryTypeInvoice, EntryTypePromise, EntryTypeSettlement}},
+		{SortByTime, OrderDesc, []string{EntryTypeSettlement, EntryTypePromise, EntryTypeInvoice, EntryTypeSession}},
+		{SortByAmount, OrderDesc, []string{EntryTypePromise, EntryTypeSettlement, EntryTypeSession, EntryTypeInvoice}},
+		{SortByType, OrderAsc, []string{EntryTypeInvoice, EntryTypePromise, EntryTypeSession, EntryTypeSettlement}},
+	}
+	for _, test := range tests {
+		t.Run(test.sortBy+" "+test.order, func(t *testing.T) {
+			entries, err := ledger.List(NewFilter().SetSort(test.sortBy, test.order))
+			assert.NoError(t, err)
+
+			var types []string
+			for _, entry := range entries {
+				types = append(types, entry.Type)
+			}
+			assert.Equal(t, test.expected, types)
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Entry{invoicePaid})
+
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"id,type,time,identity,peer,hermes_id,direction,reference,amount,total,bytes_sent,bytes_received,duration\n"+
+			"invoice1,invoice,2020-07-01T10:01:00Z,0x1,0x2,,Consumed,session1,300,300,0,0,0\n",
+		buf.String(),
+	)
+}
+
+func newTestLedger() *Ledger {
+	return NewLedger(
+		&mockEntryStorage{entries: []Entry{invoicePaid, promiseReceived}},
+		&mockSessionStorage{sessions: []session.History{sessionConsumed}},
+		&mockSettlementStorage{settlements: []pingpong.SettlementHistoryEntry{settlement}},
+	)
+}
+
+type mockEntryStorage struct {
+	entries []Entry
+}
+
+func (m *mockEntryStorage) List(_ *Filter) ([]Entry, error) {
+	return m.entries, nil
+}
+
+type mockSessionStorage struct {
+	sessions []session.History
+}
+
+func (m *mockSessionStorage) List(_ *session.Filter) ([]session.History, error) {
+	return m.sessions, nil
+}
+
+type mockSettlementStorage struct {
+	settlements []pingpong.SettlementHistoryEntry
+}
+
+func (m *mockSettlementStorage) List(_ pingpong.SettlementHistoryFilter) ([]pingpong.SettlementHistoryEntry, error) {
+	return m.settlements, nil
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gofrs/uuid"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/rs/zerolog/log"
+)
+
+const ledgerStorageBucketName = "ledger"
+
+type timeGetter func() time.Time
+
+// Storage records paid invoices and received hermes promises, which are not kept as history anywhere else.
+type Storage struct {
+	storage    *boltdb.Bolt
+	timeGetter timeGetter
+
+	mu sync.Mutex
+}
+
+// NewStorage creates ledger storage with given dependencies.
+func NewStorage(storage *boltdb.Bolt) *Storage {
+	return &Storage{
+		storage:    storage,
+		timeGetter: time.Now,
+	}
+}
+
+// Subscribe subscribes to relevant events of event bus.
+func (s *Storage) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(pingpong_event.AppTopicInvoicePaid, s.consumeInvoicePaidEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(pingpong_event.AppTopicHermesPromise, s.consumeHermesPromiseEvent)
+}
+
+// List retrieves stored entries.
+func (s *Storage) List(filter *Filter) ([]Entry, error) {
+	where := []q.Matcher{q.In("Type", []string{EntryTypeInvoice, EntryTypePromise})}
+	if filter.Identity != nil {
+		where = append(where, q.Eq("Identity", *filter.Identity))
+	}
+	if filter.TimeFrom != nil {
+		where = append(where, q.Gte("Time", *filter.TimeFrom))
+	}
+	if filter.TimeTo != nil {
+		where = append(where, q.Lte("Time", *filter.TimeTo))
+	}
+
+	var result []Entry
+	err := s.storage.DB().
+		From(ledgerStorageBucketName).
+		Select(where...).
+		OrderBy("Time").
+		Find(&result)
+	if errors.Is(err, storm.ErrNotFound) {
+		return []Entry{}, nil
+	}
+	return result, err
+}
+
+func (s *Storage) consumeInvoicePaidEvent(e pingpong_event.AppEventInvoicePaid) {
+	err := s.record(Entry{
+		Type:      EntryTypeInvoice,
+		Identity:  e.ConsumerID,
+		Peer:      identity.FromAddress(e.Invoice.Provider),
+		Direction: session.DirectionConsumed,
+		Reference: e.SessionID,
+		Total:     e.Invoice.AgreementTotal,
+	})
+	if err != nil {
+		log.Error().Err(err).Msgf("Invoice of session %s was not recorded to ledger", e.SessionID)
+	}
+}
+
+func (s *Storage) consumeHermesPromiseEvent(e pingpong_event.AppEventHermesPromise) {
+	channelID := hexutil.Encode(e.Promise.ChannelID)
+	err := s.record(Entry{
+		Type:      EntryTypePromise,
+		Identity:  e.ProviderID,
+		HermesID:  e.HermesID.Hex(),
+		Direction: session.DirectionProvided,
+		Reference: channelID,
+		Total:     e.Promise.Amount,
+	})
+	if err != nil {
+		log.Error().Err(err).Msgf("Promise of channel %s was not recorded to ledger", channelID)
+	}
+}
+
+// record stores the entry, its amount is the increase of the total since the previous entry of the same reference.
+func (s *Storage) record(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.Total == nil {
+		entry.Total = new(big.Int)
+	}
+
+	var previous Entry
+	err := s.storage.DB().
+		From(ledgerStorageBucketName).
+		Select(q.Eq("Type", entry.Type), q.Eq("Reference", entry.Reference)).
+		OrderBy("Time").
+		Reverse().
+		First(&previous)
+	switch {
+	case errors.Is(err, storm.ErrNotFound):
+		entry.Amount = new(big.Int).Set(entry.Total)
+	case err != nil:
+		return fmt.Errorf("could not get previous ledger entry: %w", err)
+	case previous.Total.Cmp(entry.Total) >= 0:
+		// Same total was already recorded, e.g. invoice was paid again after the reconnect.
+		return nil
+	default:
+		entry.Amount = new(big.Int).Sub(entry.Total, previous.Total)
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	entry.ID = id.String()
+	entry.Time = s.timeGetter().UTC()
+
+	return s.storage.Store(ledgerStorageBucketName, &entry)
+}
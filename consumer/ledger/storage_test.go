@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledger
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorage_RecordsAmountsSincePreviousTotal(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	defer storageCleanup()
+	consumerID := identity.FromAddress("0x1")
+	providerID := identity.FromAddress("0x2")
+	hermesID := common.HexToAddress("0x3")
+
+	// when
+	for _, total := range []int64{100, 250, 250} {
+		storage.consumeInvoicePaidEvent(pingpong_event.AppEventInvoicePaid{
+			ConsumerID: consumerID,
+			SessionID:  "session1",
+			Invoice:    crypto.Invoice{AgreementTotal: big.NewInt(total), Provider: providerID.Address},
+		})
+	}
+	storage.consumeHermesPromiseEvent(pingpong_event.AppEventHermesPromise{
+		Promise:    crypto.Promise{ChannelID: []byte{1}, Amount: big.NewInt(500)},
+		HermesID:   hermesID,
+		ProviderID: providerID,
+	})
+
+	// then
+	invoices, err := storage.List(NewFilter().SetIdentity(consumerID))
+	assert.NoError(t, err)
+	assert.Len(t, invoices, 2)
+	assert.Equal(t, EntryTypeInvoice, invoices[0].Type)
+	assert.Equal(t, providerID, invoices[0].Peer)
+	assert.Equal(t, "session1", invoices[0].Reference)
+	assert.Equal(t, big.NewInt(100), invoices[0].Amount)
+	assert.Equal(t, big.NewInt(150), invoices[1].Amount)
+	assert.Equal(t, big.NewInt(250), invoices[1].Total)
+
+	promises, err := storage.List(NewFilter().SetIdentity(providerID))
+	assert.NoError(t, err)
+	assert.Len(t, promises, 1)
+	assert.Equal(t, EntryTypePromise, promises[0].Type)
+	assert.Equal(t, "0x01", promises[0].Reference)
+	assert.Equal(t, hermesID.Hex(), promises[0].HermesID)
+	assert.Equal(t, big.NewInt(500), promises[0].Amount)
+}
+
+func TestStorage_ListFiltersByTime(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	defer storageCleanup()
+	consumerID := identity.FromAddress("0x1")
+
+	for day, total := range []int64{100, 200, 300} {
+		storage.timeGetter = func() time.Time {
+			return time.Date(2020, 7, day+1, 12, 0, 0, 0, time.UTC)
+		}
+		storage.consumeInvoicePaidEvent(pingpong_event.AppEventInvoicePaid{
+			ConsumerID: consumerID,
+			SessionID:  "session1",
+			Invoice:    crypto.Invoice{AgreementTotal: big.NewInt(total)},
+		})
+	}
+
+	// when
+	entries, err := storage.List(NewFilter().
+		SetTimeFrom(time.Date(2020, 7, 2, 0, 0, 0, 0, time.UTC)).
+		SetTimeTo(time.Date(2020, 7, 2, 23, 59, 59, 0, time.UTC)))
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, big.NewInt(200), entries[0].Total)
+	assert.Equal(t, big.NewInt(100), entries[0].Amount)
+}
+
+func newStorage() (*Storage, func()) {
+	dir, err := ioutil.TempDir("", "ledgerStorageTest")
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := boltdb.NewStorage(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewStorage(db), func() {
+		err := db.Close()
+		if err != nil {
+			panic(err)
+		}
+
+		err = os.RemoveAll(dir)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
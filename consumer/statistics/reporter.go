@@ -46,17 +46,21 @@ type Reporter interface {
 
 // SessionStatisticsReporter sends session stats to remote API server with a fixed sendInterval.
 // Extra one send will be done on session disconnect.
+// Every simultaneous session is reported separately.
 type SessionStatisticsReporter struct {
 	signerFactory  identity.SignerFactory
-	statistics     connectionstate.Statistics
-	statisticsMu   sync.RWMutex
 	remoteReporter Reporter
 
 	sendInterval time.Duration
-	done         chan struct{}
 
-	opLock  sync.Mutex
-	started bool
+	opLock   sync.Mutex
+	sessions map[session.ID]*sessionReport
+}
+
+type sessionReport struct {
+	statistics   connectionstate.Statistics
+	statisticsMu sync.RWMutex
+	done         chan struct{}
 }
 
 // NewSessionStatisticsReporter function creates new session stats sender by given options
@@ -66,7 +70,7 @@ func NewSessionStatisticsReporter(remoteReporter Reporter, signerFactory identit
 		remoteReporter: remoteReporter,
 
 		sendInterval: interval,
-		done:         make(chan struct{}),
+		sessions:     make(map[session.ID]*sessionReport),
 	}
 }
 
@@ -83,25 +87,26 @@ func (sr *SessionStatisticsReporter) start(session connectionstate.Status) {
 	sr.opLock.Lock()
 	defer sr.opLock.Unlock()
 
-	if sr.started {
+	if _, started := sr.sessions[session.SessionID]; started {
 		return
 	}
 
 	signer := sr.signerFactory(session.ConsumerID)
 
-	sr.done = make(chan struct{})
+	report := &sessionReport{done: make(chan struct{})}
+	sr.sessions[session.SessionID] = report
 	go func() {
 		for {
 			select {
-			case <-sr.done:
-				if err := sr.send(session, signer); err != nil {
+			case <-report.done:
+				if err := sr.send(session, report, signer); err != nil {
 					log.Error().Err(err).Msg("Failed to send session stats to the remote service")
 				} else {
 					log.Debug().Msg("Final stats sent")
 				}
 				return
 			case <-time.After(sr.sendInterval):
-				if err := sr.send(session, signer); err != nil {
+				if err := sr.send(session, report, signer); err != nil {
 					log.Error().Err(err).Msg("Failed to send session stats to the remote service")
 				} else {
 					log.Debug().Msg("Stats sent")
@@ -110,28 +115,36 @@ func (sr *SessionStatisticsReporter) start(session connectionstate.Status) {
 		}
 	}()
 
-	sr.started = true
-	log.Debug().Msg("Session statistics reporter started")
+	log.Debug().Msgf("Session statistics reporter started for session %s", session.SessionID)
 }
 
 // stop stops the sending of stats
-func (sr *SessionStatisticsReporter) stop() {
+func (sr *SessionStatisticsReporter) stop(sessionID session.ID) {
 	sr.opLock.Lock()
 	defer sr.opLock.Unlock()
 
-	if !sr.started {
+	report, started := sr.sessions[sessionID]
+	if !started {
 		return
 	}
 
-	close(sr.done)
-	sr.started = false
-	log.Debug().Msg("Session statistics reporter stopping")
+	close(report.done)
+	delete(sr.sessions, sessionID)
+	log.Debug().Msgf("Session statistics reporter stopping for session %s", sessionID)
 }
 
-func (sr *SessionStatisticsReporter) send(session connectionstate.Status, signer identity.Signer) error {
-	sr.statisticsMu.RLock()
-	dataStats := sr.statistics
-	sr.statisticsMu.RUnlock()
+func (sr *SessionStatisticsReporter) started(sessionID session.ID) bool {
+	sr.opLock.Lock()
+	defer sr.opLock.Unlock()
+
+	_, started := sr.sessions[sessionID]
+	return started
+}
+
+func (sr *SessionStatisticsReporter) send(session connectionstate.Status, report *sessionReport, signer identity.Signer) error {
+	report.statisticsMu.RLock()
+	dataStats := report.statistics
+	report.statisticsMu.RUnlock()
 
 	return sr.remoteReporter.SendSessionStats(
 		session.SessionID,
@@ -150,15 +163,21 @@ func (sr *SessionStatisticsReporter) send(session connectionstate.Status, signer
 func (sr *SessionStatisticsReporter) consumeSessionEvent(sessionEvent connectionstate.AppEventConnectionSession) {
 	switch sessionEvent.Status {
 	case connectionstate.SessionEndedStatus:
-		sr.stop()
+		sr.stop(sessionEvent.SessionInfo.SessionID)
 	case connectionstate.SessionCreatedStatus:
-		sr.statistics = connectionstate.Statistics{}
 		sr.start(sessionEvent.SessionInfo)
 	}
 }
 
 func (sr *SessionStatisticsReporter) consumeSessionStatisticsEvent(e connectionstate.AppEventConnectionStatistics) {
-	sr.statisticsMu.Lock()
-	sr.statistics = e.Stats
-	sr.statisticsMu.Unlock()
+	sr.opLock.Lock()
+	report, started := sr.sessions[e.SessionInfo.SessionID]
+	sr.opLock.Unlock()
+	if !started {
+		return
+	}
+
+	report.statisticsMu.Lock()
+	report.statistics = e.Stats
+	report.statisticsMu.Unlock()
 }
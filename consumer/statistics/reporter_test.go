@@ -52,10 +52,10 @@ func TestStatisticsReporterStartsAndStops(t *testing.T) {
 	reporter.consumeSessionEvent(mockSessionEvent)
 
 	reporter.start(mockSessionEvent.SessionInfo)
-	reporter.stop()
+	reporter.stop(mockSessionEvent.SessionInfo.SessionID)
 
 	assert.NoError(t, waitForChannel(mockSender.called, time.Millisecond*200))
-	assert.False(t, reporter.started)
+	assert.False(t, reporter.started(mockSessionEvent.SessionInfo.SessionID))
 }
 
 func TestStatisticsReporterInterval(t *testing.T) {
@@ -67,7 +67,7 @@ func TestStatisticsReporterInterval(t *testing.T) {
 	reporter.start(mockSessionEvent.SessionInfo)
 	assert.NoError(t, waitForChannel(mockSender.called, time.Millisecond*200))
 
-	reporter.stop()
+	reporter.stop(mockSessionEvent.SessionInfo.SessionID)
 }
 
 func TestStatisticsReporterConsumeSessionEvent(t *testing.T) {
@@ -75,11 +75,33 @@ func TestStatisticsReporterConsumeSessionEvent(t *testing.T) {
 	reporter := NewSessionStatisticsReporter(mockSender, mockSignerFactory, time.Nanosecond)
 	reporter.consumeSessionEvent(mockSessionEvent)
 	<-mockSender.called
-	assert.True(t, reporter.started)
+	assert.True(t, reporter.started(mockSessionEvent.SessionInfo.SessionID))
 	copy := mockSessionEvent
 	copy.Status = connectionstate.SessionEndedStatus
 	reporter.consumeSessionEvent(copy)
-	assert.False(t, reporter.started)
+	assert.False(t, reporter.started(mockSessionEvent.SessionInfo.SessionID))
+}
+
+func TestStatisticsReporterReportsSessionsSeparately(t *testing.T) {
+	mockSender := newMockRemoteSender()
+	reporter := NewSessionStatisticsReporter(mockSender, mockSignerFactory, time.Minute)
+
+	other := mockSessionEvent
+	other.SessionInfo.SessionID = "other"
+	reporter.consumeSessionEvent(mockSessionEvent)
+	reporter.consumeSessionEvent(other)
+	assert.True(t, reporter.started(mockSessionEvent.SessionInfo.SessionID))
+	assert.True(t, reporter.started(other.SessionInfo.SessionID))
+
+	ended := other
+	ended.Status = connectionstate.SessionEndedStatus
+	reporter.consumeSessionEvent(ended)
+	assert.NoError(t, waitForChannel(mockSender.called, time.Millisecond*200))
+	assert.True(t, reporter.started(mockSessionEvent.SessionInfo.SessionID))
+	assert.False(t, reporter.started(other.SessionInfo.SessionID))
+
+	reporter.stop(mockSessionEvent.SessionInfo.SessionID)
+	assert.NoError(t, waitForChannel(mockSender.called, time.Millisecond*200))
 }
 
 func waitForChannel(ch chan bool, duration time.Duration) error {
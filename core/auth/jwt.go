@@ -78,20 +78,38 @@ func (jwtAuth *JWTAuthenticator) CreateToken(username string) (JWT, error) {
 
 // ValidateToken validates a JWT token
 func (jwtAuth *JWTAuthenticator) ValidateToken(token string) (bool, error) {
+	if _, err := jwtAuth.parseToken(token); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Username validates a JWT token and returns the username it was issued to
+func (jwtAuth *JWTAuthenticator) Username(token string) (string, error) {
+	claims, err := jwtAuth.parseToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Username, nil
+}
+
+func (jwtAuth *JWTAuthenticator) parseToken(token string) (*jwtClaims, error) {
 	claims := &jwtClaims{}
 
 	tkn, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
 		return jwtAuth.encryptionKey, nil
 	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	if tkn == nil || !tkn.Valid {
-		return false, errors.New("invalid JWT token")
+		return nil, errors.New("invalid JWT token")
 	}
 
-	return true, nil
+	return claims, nil
 }
 
 func (jwtAuth *JWTAuthenticator) getExpirationTime() time.Time {
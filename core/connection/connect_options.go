@@ -22,6 +22,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/session"
@@ -33,6 +34,14 @@ type ConnectParams struct {
 	DisableKillSwitch bool
 	// DNS servers to use
 	DNS DNSOption
+	// filter of proposals to fail over to when connection with provider is lost, failover is disabled when nil
+	Failover *proposal.Filter
+	// destinations routed through the tunnel or around it, all traffic is routed through the tunnel when not set
+	SplitTunnel SplitTunnel
+
+	// ID of additional connection, empty for the default connection.
+	// It is assigned by MultiManager, so any value set by the caller is overwritten.
+	ConnectionID string
 }
 
 // ConnectOptions represents the params we need to ensure a successful connection
@@ -46,4 +55,5 @@ type ConnectOptions struct {
 	ProviderNATConn *net.UDPConn
 	ChannelConn     *net.UDPConn
 	HermesID        common.Address
+	Routes          Routes
 }
@@ -20,13 +20,15 @@ package connection
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 )
 
 type consumerBalanceGetter interface {
-	GetBalance(chainID int64, id identity.Identity) *big.Int
-	ForceBalanceUpdate(chainID int64, id identity.Identity) *big.Int
+	HermesIDs() []common.Address
+	GetHermesBalance(chainID int64, id identity.Identity, hermesID common.Address) *big.Int
+	ForceHermesBalanceUpdate(chainID int64, id identity.Identity, hermesID common.Address) *big.Int
 }
 
 type unlockChecker interface {
@@ -47,19 +49,19 @@ func NewValidator(consumerBalanceGetter consumerBalanceGetter, unlockChecker unl
 	}
 }
 
-// validateBalance checks if consumer has enough money for given proposal.
-func (v *Validator) validateBalance(chainID int64, consumerID identity.Identity, proposal market.ServiceProposal) bool {
+// validateBalance checks if consumer has enough money in the given hermes for given proposal.
+func (v *Validator) validateBalance(chainID int64, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal) bool {
 	if proposal.PaymentMethodType == "" || proposal.PaymentMethod == nil {
 		return true
 	}
 
 	proposalPrice := proposal.PaymentMethod.GetPrice()
-	balance := v.consumerBalanceGetter.GetBalance(chainID, consumerID)
+	balance := v.consumerBalanceGetter.GetHermesBalance(chainID, consumerID, hermesID)
 	if balance.Cmp(proposalPrice.Amount) >= 0 {
 		return true
 	}
 
-	balance = v.consumerBalanceGetter.ForceBalanceUpdate(chainID, consumerID)
+	balance = v.consumerBalanceGetter.ForceHermesBalanceUpdate(chainID, consumerID, hermesID)
 	return balance.Cmp(proposalPrice.Amount) >= 0
 }
 
@@ -68,13 +70,46 @@ func (v *Validator) isUnlocked(consumerID identity.Identity) bool {
 	return v.unlockChecker.IsUnlocked(consumerID.Address)
 }
 
+// SelectHermes picks the hermes to pay for given proposal through.
+// Among the hermeses accepted by provider, the preferred one is tried first and then the ones consumer has a channel with,
+// the first having enough balance is picked. If none has enough balance, the first accepted one is returned.
+// Providers not advertising hermeses are always paid through the preferred one.
+func (v *Validator) SelectHermes(chainID int64, consumerID identity.Identity, preferred common.Address, proposal market.ServiceProposal) (common.Address, error) {
+	if len(proposal.HermesIDs) == 0 {
+		return preferred, nil
+	}
+
+	candidates := []common.Address{preferred}
+	for _, hermesID := range v.consumerBalanceGetter.HermesIDs() {
+		if hermesID != preferred {
+			candidates = append(candidates, hermesID)
+		}
+	}
+
+	var accepted []common.Address
+	for _, hermesID := range candidates {
+		if !proposal.AcceptsHermes(hermesID.Hex()) {
+			continue
+		}
+		if v.validateBalance(chainID, consumerID, hermesID, proposal) {
+			return hermesID, nil
+		}
+		accepted = append(accepted, hermesID)
+	}
+
+	if len(accepted) == 0 {
+		return common.Address{}, ErrHermesNotAccepted
+	}
+	return accepted[0], nil
+}
+
 // Validate checks whether the pre-connection conditions are fulfilled.
-func (v *Validator) Validate(chainID int64, consumerID identity.Identity, proposal market.ServiceProposal) error {
+func (v *Validator) Validate(chainID int64, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal) error {
 	if !v.isUnlocked(consumerID) {
 		return ErrUnlockRequired
 	}
 
-	if !v.validateBalance(chainID, consumerID, proposal) {
+	if !v.validateBalance(chainID, consumerID, hermesID, proposal) {
 		return ErrInsufficientBalance
 	}
 
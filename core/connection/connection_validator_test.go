@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
@@ -146,7 +147,7 @@ func TestValidator_Validate(t *testing.T) {
 				consumerBalanceGetter: tt.fields.consumerBalanceGetter,
 				unlockChecker:         tt.fields.unlockChecker,
 			}
-			err := v.Validate(tt.args.chainID, tt.args.consumerID, tt.args.proposal)
+			err := v.Validate(tt.args.chainID, tt.args.consumerID, common.Address{}, tt.args.proposal)
 			if tt.wantErr != nil {
 				assert.EqualError(t, err, tt.wantErr.Error(), tt.name)
 			} else {
@@ -156,6 +157,70 @@ func TestValidator_Validate(t *testing.T) {
 	}
 }
 
+func TestValidator_SelectHermes(t *testing.T) {
+	var (
+		preferred = common.HexToAddress("0x1")
+		other     = common.HexToAddress("0x2")
+		unknown   = common.HexToAddress("0x3")
+	)
+	newProposal := func(hermesIDs ...common.Address) market.ServiceProposal {
+		proposal := market.ServiceProposal{
+			PaymentMethod: &mockPaymentMethod{price: money.Money{
+				Amount:   big.NewInt(100),
+				Currency: "MYSTT",
+			}},
+			PaymentMethodType: "PER_MINUTE",
+		}
+		for _, id := range hermesIDs {
+			proposal.HermesIDs = append(proposal.HermesIDs, id.Hex())
+		}
+		return proposal
+	}
+	balances := &mockConsumerBalanceGetter{
+		hermesIDs: []common.Address{preferred, other},
+		balances: map[common.Address]*big.Int{
+			preferred: big.NewInt(10),
+			other:     big.NewInt(100),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		proposal market.ServiceProposal
+		want     common.Address
+		wantErr  error
+	}{
+		{
+			name:     "uses preferred hermes if provider does not advertise hermeses",
+			proposal: newProposal(),
+			want:     preferred,
+		},
+		{
+			name:     "prefers hermes with enough balance",
+			proposal: newProposal(preferred, other),
+			want:     other,
+		},
+		{
+			name:     "falls back to first accepted hermes without enough balance",
+			proposal: newProposal(unknown, preferred),
+			want:     preferred,
+		},
+		{
+			name:     "fails if no hermes is accepted",
+			proposal: newProposal(unknown),
+			wantErr:  ErrHermesNotAccepted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Validator{consumerBalanceGetter: balances}
+			got, err := v.SelectHermes(1, identity.FromAddress("0x4"), preferred, tt.proposal)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 type mockUnlockChecker struct {
 	toReturn bool
 }
@@ -165,14 +230,26 @@ func (muc *mockUnlockChecker) IsUnlocked(id string) bool {
 }
 
 type mockConsumerBalanceGetter struct {
+	hermesIDs   []common.Address
+	balances    map[common.Address]*big.Int
 	toReturn    *big.Int
 	forceReturn *big.Int
 }
 
-func (mcbg *mockConsumerBalanceGetter) GetBalance(chainID int64, id identity.Identity) *big.Int {
+func (mcbg *mockConsumerBalanceGetter) HermesIDs() []common.Address {
+	return mcbg.hermesIDs
+}
+
+func (mcbg *mockConsumerBalanceGetter) GetHermesBalance(chainID int64, id identity.Identity, hermesID common.Address) *big.Int {
+	if balance, ok := mcbg.balances[hermesID]; ok {
+		return balance
+	}
 	return mcbg.toReturn
 }
 
-func (mcbg *mockConsumerBalanceGetter) ForceBalanceUpdate(chainID int64, id identity.Identity) *big.Int {
+func (mcbg *mockConsumerBalanceGetter) ForceHermesBalanceUpdate(chainID int64, id identity.Identity, hermesID common.Address) *big.Int {
+	if balance, ok := mcbg.balances[hermesID]; ok {
+		return balance
+	}
 	return mcbg.forceReturn
 }
@@ -35,6 +35,8 @@ const (
 	AppTopicConnectionStatistics = "Statistics"
 	// AppTopicConnectionSession represents the session lifetime changes
 	AppTopicConnectionSession = "Session"
+	// AppTopicConnectionTrace represents the connection debugging records topic
+	AppTopicConnectionTrace = "ConnectionTrace"
 )
 
 // AppEventConnectionState is the struct we'll emit on a AppEventConnectionState topic event
@@ -57,6 +59,8 @@ const (
 	Disconnecting = State("Disconnecting")
 	// Reconnecting means that connection is lost but underlying service is trying to reestablish it
 	Reconnecting = State("Reconnecting")
+	// FailingOver means that connection with provider is lost and another provider is being connected to
+	FailingOver = State("FailingOver")
 	// Unknown means that we could not map the underlying transport state to our state
 	Unknown = State("Unknown")
 	// Canceled means that connection initialization was started, but failed never reaching Connected state
@@ -65,10 +69,16 @@ const (
 	StateIPNotChanged = State("IPNotChanged")
 	// StateConnectionFailed means that underlying connection is failed
 	StateConnectionFailed = State("ConnectionFailed")
+	// StateFailoverFailed means that none of the providers to fail over to could be connected
+	StateFailoverFailed = State("FailoverFailed")
+	// StateSessionQuotaExceeded means that session was closed by provider as consumer exceeded its quota
+	StateSessionQuotaExceeded = State("SessionQuotaExceeded")
 )
 
 // Status holds connection state, session id and proposal of the connection
 type Status struct {
+	// ConnectionID identifies additional connection of the multi connection manager, it is empty for the default connection
+	ConnectionID     string
 	StartedAt        time.Time
 	ConsumerID       identity.Identity
 	ConsumerLocation locationstate.Location
@@ -104,3 +114,28 @@ type AppEventConnectionStatistics struct {
 	Stats       Statistics
 	SessionInfo Status
 }
+
+// TraceKind represents a kind of the connection debugging record
+type TraceKind string
+
+const (
+	// TraceConnect marks the start of a connection attempt
+	TraceConnect = TraceKind("connect")
+	// TraceStage represents a traced stage of the connection establishment
+	TraceStage = TraceKind("stage")
+	// TraceP2P represents a p2p message sent to or received from the provider
+	TraceP2P = TraceKind("p2p")
+	// TraceError represents an error the connection failed with
+	TraceError = TraceKind("error")
+)
+
+// AppEventConnectionTrace represents a single debugging record of the connection
+type AppEventConnectionTrace struct {
+	ConnectionID string
+	SessionID    session.ID
+	Time         time.Time
+	Kind         TraceKind
+	Name         string
+	Details      string
+	Duration     time.Duration
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connectiontrace
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+const (
+	// KindState represents the connection state transition entry
+	KindState = "state"
+	// KindKeepAlive represents the failed p2p keep alive ping entry
+	KindKeepAlive = "keepalive"
+)
+
+// Entry represents a single timestamped record of the connection.
+type Entry struct {
+	Time     time.Time     `json:"time"`
+	Kind     string        `json:"kind"`
+	Name     string        `json:"name"`
+	Details  string        `json:"details,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Recording represents the trace of a single connection attempt and the session established by it.
+type Recording struct {
+	ID           string     `json:"id"`
+	ConnectionID string     `json:"connection_id,omitempty"`
+	SessionID    session.ID `json:"session_id,omitempty"`
+	ConsumerID   string     `json:"consumer_id,omitempty"`
+	ProviderID   string     `json:"provider_id,omitempty"`
+	ServiceType  string     `json:"service_type,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	Entries      []Entry    `json:"entries"`
+	// Dropped is the number of the oldest traffic entries dropped after the entries limit was reached.
+	Dropped int `json:"dropped,omitempty"`
+
+	// established is set once the connection is established, the entries recorded before are always kept.
+	established bool
+	// traffic holds the p2p and keep alive entries recorded after the connection is established.
+	traffic []Entry
+}
+
+// Recorder records connection traces into a ring buffer holding the latest recordings.
+type Recorder struct {
+	capacity   int
+	maxEntries int
+
+	mu         sync.Mutex
+	recordings []*Recording
+	current    map[string]*Recording
+	sessions   map[session.ID]string
+}
+
+// NewRecorder returns a new recorder keeping up to capacity recordings. Entries recorded while the connection
+// is being established are always kept, the traffic recorded afterwards is limited to maxEntries entries.
+func NewRecorder(capacity, maxEntries int) *Recorder {
+	return &Recorder{
+		capacity:   capacity,
+		maxEntries: maxEntries,
+		current:    make(map[string]*Recording),
+		sessions:   make(map[session.ID]string),
+	}
+}
+
+// Subscribe subscribes the recorder to the connection events.
+func (r *Recorder) Subscribe(bus eventbus.Subscriber) error {
+	// Handlers are called synchronously to keep entries in the order they were published.
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionTrace, r.consumeTraceEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionState, r.consumeStateEvent); err != nil {
+		return err
+	}
+	return bus.Subscribe(p2p.AppTopicKeepAliveFailed, r.consumeKeepAliveFailedEvent)
+}
+
+// List returns copies of the recordings without entries, the latest recording goes first.
+func (r *Recorder) List() []Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := make([]Recording, 0, len(r.recordings))
+	for i := len(r.recordings) - 1; i >= 0; i-- {
+		recording := *r.recordings[i]
+		recording.Entries = nil
+		list = append(list, recording)
+	}
+	return list
+}
+
+// Get returns a copy of the recording with given ID.
+func (r *Recorder) Get(id string) (Recording, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, recording := range r.recordings {
+		if recording.ID == id {
+			return copyRecording(recording), true
+		}
+	}
+	return Recording{}, false
+}
+
+// All returns copies of all the recordings with entries, the latest recording goes first.
+func (r *Recorder) All() []Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make([]Recording, 0, len(r.recordings))
+	for i := len(r.recordings) - 1; i >= 0; i-- {
+		all = append(all, copyRecording(r.recordings[i]))
+	}
+	return all
+}
+
+func (r *Recorder) consumeTraceEvent(e connectionstate.AppEventConnectionTrace) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recording, ok := r.current[e.ConnectionID]
+	if !ok || e.Kind == connectionstate.TraceConnect {
+		recording = r.start(e.ConnectionID, e.Time)
+	}
+	if e.SessionID != "" {
+		r.setSession(recording, e.SessionID)
+	}
+	r.append(recording, Entry{
+		Time:     e.Time,
+		Kind:     string(e.Kind),
+		Name:     e.Name,
+		Details:  e.Details,
+		Duration: e.Duration,
+	})
+}
+
+func (r *Recorder) consumeStateEvent(e connectionstate.AppEventConnectionState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info := e.SessionInfo
+	recording, ok := r.current[info.ConnectionID]
+	if !ok {
+		return
+	}
+
+	if info.ConsumerID.Address != "" {
+		recording.ConsumerID = info.ConsumerID.Address
+	}
+	if info.Proposal.ProviderID != "" {
+		recording.ProviderID = info.Proposal.ProviderID
+		recording.ServiceType = info.Proposal.ServiceType
+	}
+	if info.SessionID != "" {
+		r.setSession(recording, info.SessionID)
+	}
+
+	entry := Entry{Time: time.Now(), Kind: KindState, Name: string(e.State)}
+	if info.SessionID != "" {
+		entry.Details = fmt.Sprintf("session %s", info.SessionID)
+	}
+	r.append(recording, entry)
+	if e.State == connectionstate.Connected {
+		recording.established = true
+	}
+}
+
+func (r *Recorder) consumeKeepAliveFailedEvent(e p2p.AppEventKeepAliveFailed) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	connectionID, ok := r.sessions[session.ID(e.SessionID)]
+	if !ok {
+		return
+	}
+	recording, ok := r.current[connectionID]
+	if !ok || recording.SessionID != session.ID(e.SessionID) {
+		return
+	}
+
+	r.append(recording, Entry{
+		Time:    time.Now(),
+		Kind:    KindKeepAlive,
+		Name:    "Keep alive ping failed",
+		Details: fmt.Sprintf("role %s, session closed %t", e.Role, e.Closed),
+	})
+}
+
+func (r *Recorder) start(connectionID string, at time.Time) *Recording {
+	id, err := uuid.NewV4()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not generate connection trace ID")
+	}
+
+	recording := &Recording{
+		ID:           id.String(),
+		ConnectionID: connectionID,
+		StartedAt:    at,
+	}
+	r.recordings = append(r.recordings, recording)
+	if len(r.recordings) > r.capacity {
+		oldest := r.recordings[0]
+		r.recordings = r.recordings[1:]
+		delete(r.sessions, oldest.SessionID)
+		if r.current[oldest.ConnectionID] == oldest {
+			delete(r.current, oldest.ConnectionID)
+		}
+	}
+	r.current[connectionID] = recording
+	return recording
+}
+
+func (r *Recorder) setSession(recording *Recording, sessionID session.ID) {
+	recording.SessionID = sessionID
+	r.sessions[sessionID] = recording.ConnectionID
+}
+
+func (r *Recorder) append(recording *Recording, entry Entry) {
+	if !recording.established || !isTraffic(entry) {
+		recording.Entries = append(recording.Entries, entry)
+		return
+	}
+
+	recording.traffic = append(recording.traffic, entry)
+	if len(recording.traffic) > r.maxEntries {
+		recording.traffic = recording.traffic[1:]
+		recording.Dropped++
+	}
+}
+
+// isTraffic tells if the entry is recorded repeatedly for as long as the connection lasts.
+func isTraffic(entry Entry) bool {
+	return entry.Kind == string(connectionstate.TraceP2P) || entry.Kind == KindKeepAlive
+}
+
+// copyRecording returns a copy of the recording with all its entries ordered by time,
+// since the stages are published only after the connection attempt finishes.
+func copyRecording(recording *Recording) Recording {
+	cp := *recording
+	cp.Entries = make([]Entry, 0, len(recording.Entries)+len(recording.traffic))
+	cp.Entries = append(cp.Entries, recording.Entries...)
+	cp.Entries = append(cp.Entries, recording.traffic...)
+	cp.traffic = nil
+	sort.SliceStable(cp.Entries, func(i, j int) bool {
+		return cp.Entries[i].Time.Before(cp.Entries[j].Time)
+	})
+	return cp
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connectiontrace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+func TestRecorder_RecordsConnection(t *testing.T) {
+	bus := eventbus.New()
+	recorder := NewRecorder(5, 100)
+	require.NoError(t, recorder.Subscribe(bus))
+
+	now := time.Now()
+	bus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+		Time: now, Kind: connectionstate.TraceConnect, Name: "Connect",
+	})
+	bus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
+		State: connectionstate.Connecting,
+		SessionInfo: connectionstate.Status{
+			ConsumerID: identity.FromAddress("0x1"),
+			Proposal:   market.ServiceProposal{ProviderID: "0x2", ServiceType: "wireguard"},
+		},
+	})
+	bus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+		Time: time.Now(), Kind: connectionstate.TraceP2P, Name: p2p.TopicSessionCreate, Details: "sent", Duration: time.Second,
+	})
+	bus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
+		State:       connectionstate.Connected,
+		SessionInfo: connectionstate.Status{SessionID: "session1"},
+	})
+	// stages are published after the connection attempt finishes
+	bus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+		Time: now, Kind: connectionstate.TraceStage, Name: "Consumer whole Connect", Duration: time.Second,
+	})
+	bus.Publish(p2p.AppTopicKeepAliveFailed, p2p.AppEventKeepAliveFailed{SessionID: "session1", Role: "consumer"})
+	bus.Publish(p2p.AppTopicKeepAliveFailed, p2p.AppEventKeepAliveFailed{SessionID: "unknown", Role: "consumer"})
+
+	list := recorder.List()
+	require.Len(t, list, 1)
+	assert.Nil(t, list[0].Entries)
+	assert.Equal(t, "0x1", list[0].ConsumerID)
+	assert.Equal(t, "0x2", list[0].ProviderID)
+	assert.Equal(t, "wireguard", list[0].ServiceType)
+	assert.Equal(t, "session1", string(list[0].SessionID))
+
+	recording, ok := recorder.Get(list[0].ID)
+	require.True(t, ok)
+	var kinds []string
+	for _, entry := range recording.Entries {
+		kinds = append(kinds, entry.Kind+":"+entry.Name)
+	}
+	assert.Equal(t, []string{
+		"connect:Connect",
+		"stage:Consumer whole Connect",
+		"state:Connecting",
+		"p2p:" + p2p.TopicSessionCreate,
+		"state:Connected",
+		"keepalive:Keep alive ping failed",
+	}, kinds)
+
+	_, ok = recorder.Get("unknown")
+	assert.False(t, ok)
+}
+
+func TestRecorder_SeparatesConnections(t *testing.T) {
+	bus := eventbus.New()
+	recorder := NewRecorder(5, 100)
+	require.NoError(t, recorder.Subscribe(bus))
+
+	for _, connectionID := range []string{"", "second"} {
+		bus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+			ConnectionID: connectionID, Kind: connectionstate.TraceConnect, Name: "Connect",
+		})
+	}
+	bus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+		ConnectionID: "", Kind: connectionstate.TraceError, Name: "Connect failed",
+	})
+
+	list := recorder.All()
+	require.Len(t, list, 2)
+	assert.Equal(t, "second", list[0].ConnectionID)
+	assert.Len(t, list[0].Entries, 1)
+	assert.Equal(t, "", list[1].ConnectionID)
+	assert.Len(t, list[1].Entries, 2)
+}
+
+func TestRecorder_KeepsLatestRecordingsAndEntries(t *testing.T) {
+	bus := eventbus.New()
+	recorder := NewRecorder(2, 3)
+	require.NoError(t, recorder.Subscribe(bus))
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+			Time: time.Now(), Kind: connectionstate.TraceConnect, Name: "Connect",
+		})
+	}
+	for i := 0; i < 4; i++ {
+		bus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+			Time: time.Now(), Kind: connectionstate.TraceP2P, Name: p2p.TopicSessionCreate,
+		})
+	}
+	bus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
+		State: connectionstate.Connected,
+	})
+	for i := 0; i < 5; i++ {
+		bus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+			Time: time.Now(), Kind: connectionstate.TraceP2P, Name: p2p.TopicPaymentInvoice,
+		})
+	}
+
+	list := recorder.All()
+	require.Len(t, list, 2)
+	assert.Len(t, list[0].Entries, 9)
+	assert.Equal(t, 2, list[0].Dropped)
+	assert.Equal(t, "Connect", list[0].Entries[0].Name)
+	assert.Equal(t, p2p.TopicSessionCreate, list[0].Entries[4].Name)
+	assert.Equal(t, "Connected", list[0].Entries[5].Name)
+	assert.Equal(t, p2p.TopicPaymentInvoice, list[0].Entries[8].Name)
+	assert.Len(t, list[1].Entries, 1)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"sort"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/scoring"
+	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type qualityFinder interface {
+	ProposalsMetrics() []quality.ConnectMetric
+}
+
+// FailoverPolicy picks proposals to fail over to when connection with the provider is lost.
+type FailoverPolicy interface {
+	// Candidates returns proposals matching the filter from the best to the worst, excluding the given ones.
+	Candidates(filter *proposal.Filter, exclude []market.ServiceProposal) ([]market.ServiceProposal, error)
+}
+
+// NewQualityFailoverPolicy creates failover policy which ranks proposals by their connection quality.
+func NewQualityFailoverPolicy(repository proposal.Repository, quality qualityFinder) *qualityFailoverPolicy {
+	return &qualityFailoverPolicy{
+		repository: repository,
+		quality:    quality,
+	}
+}
+
+type qualityFailoverPolicy struct {
+	repository proposal.Repository
+	quality    qualityFinder
+}
+
+// Candidates returns proposals matching the filter from the best to the worst, excluding the given ones.
+func (p *qualityFailoverPolicy) Candidates(filter *proposal.Filter, exclude []market.ServiceProposal) ([]market.ServiceProposal, error) {
+	proposals, err := p.repository.Proposals(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[market.ProposalID]bool, len(exclude))
+	for _, proposal := range exclude {
+		excluded[proposal.UniqueID()] = true
+	}
+
+	scores := make(map[market.ProposalID]float64)
+	for _, metric := range p.quality.ProposalsMetrics() {
+		scores[market.ProposalID{ProviderID: metric.ProposalID.ProviderID, ServiceType: metric.ProposalID.ServiceType}] = scoring.Quality(metric)
+	}
+
+	candidates := make([]market.ServiceProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		if !excluded[proposal.UniqueID()] {
+			candidates = append(candidates, proposal)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scoreOf(scores, candidates[i]) > scoreOf(scores, candidates[j])
+	})
+	return candidates, nil
+}
+
+func scoreOf(scores map[market.ProposalID]float64, proposal market.ServiceProposal) float64 {
+	if score, ok := scores[proposal.UniqueID()]; ok {
+		return score
+	}
+	// Proposals without any connection metrics are tried after the proven ones.
+	return scoring.UnknownQuality
+}
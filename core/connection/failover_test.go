@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProposalRepository struct {
+	proposals []market.ServiceProposal
+}
+
+func (m *mockProposalRepository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	return nil, nil
+}
+
+func (m *mockProposalRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	return m.proposals, nil
+}
+
+type mockQualityFinder struct {
+	metrics []quality.ConnectMetric
+}
+
+func (m *mockQualityFinder) ProposalsMetrics() []quality.ConnectMetric {
+	return m.metrics
+}
+
+func connectMetric(providerID string, success, fail int, monitoringFailed bool) quality.ConnectMetric {
+	return quality.ConnectMetric{
+		ProposalID:       quality.ProposalID{ProviderID: providerID, ServiceType: "wireguard"},
+		ConnectCount:     quality.ConnectCount{Success: success, Fail: fail},
+		MonitoringFailed: monitoringFailed,
+	}
+}
+
+func TestQualityFailoverPolicy_Candidates(t *testing.T) {
+	proposals := []market.ServiceProposal{
+		{ProviderID: "failed", ServiceType: "wireguard"},
+		{ProviderID: "unknown", ServiceType: "wireguard"},
+		{ProviderID: "current", ServiceType: "wireguard"},
+		{ProviderID: "poor", ServiceType: "wireguard"},
+		{ProviderID: "good", ServiceType: "wireguard"},
+	}
+	policy := NewQualityFailoverPolicy(
+		&mockProposalRepository{proposals: proposals},
+		&mockQualityFinder{metrics: []quality.ConnectMetric{
+			connectMetric("failed", 100, 0, true),
+			connectMetric("current", 100, 0, false),
+			connectMetric("poor", 1, 9, false),
+			connectMetric("good", 9, 1, false),
+		}},
+	)
+
+	candidates, err := policy.Candidates(&proposal.Filter{ServiceType: "wireguard"}, proposals[2:3])
+	assert.NoError(t, err)
+
+	var providers []string
+	for _, candidate := range candidates {
+		providers = append(providers, candidate.ProviderID)
+	}
+	assert.Equal(t, []string{"good", "unknown", "poor", "failed"}, providers)
+}
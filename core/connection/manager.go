@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/location"
 
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
@@ -63,6 +65,8 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	// ErrUnlockRequired indicates that the consumer identity has not been unlocked yet
 	ErrUnlockRequired = errors.New("unlock required")
+	// ErrHermesNotAccepted indicates that provider does not accept payments through any hermes the consumer has a channel with
+	ErrHermesNotAccepted = errors.New("no hermes accepted by provider")
 )
 
 // IPCheckConfig contains common params for connection ip check.
@@ -78,10 +82,16 @@ type KeepAliveConfig struct {
 	MaxSendErrCount int
 }
 
+// FailoverConfig contains provider failover options.
+type FailoverConfig struct {
+	MaxAttempts int
+}
+
 // Config contains common configuration options for connection manager.
 type Config struct {
 	IPCheck   IPCheckConfig
 	KeepAlive KeepAliveConfig
+	Failover  FailoverConfig
 }
 
 // DefaultConfig returns default params.
@@ -96,6 +106,9 @@ func DefaultConfig() Config {
 			SendTimeout:     5 * time.Second,
 			MaxSendErrCount: 5,
 		},
+		Failover: FailoverConfig{
+			MaxAttempts: 3,
+		},
 	}
 }
 
@@ -109,8 +122,15 @@ type PaymentIssuer interface {
 	Stop()
 }
 
+// ResumablePaymentIssuer is a payment issuer which can offer provider to resume the session interrupted by the node restart.
+type ResumablePaymentIssuer interface {
+	PaymentIssuer
+	ResumeOffer() *pb.SessionResume
+}
+
 type validator interface {
-	Validate(chainID int64, consumerID identity.Identity, proposal market.ServiceProposal) error
+	SelectHermes(chainID int64, consumerID identity.Identity, preferred common.Address, proposal market.ServiceProposal) (common.Address, error)
+	Validate(chainID int64, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal) error
 }
 
 // TimeGetter function returns current time
@@ -130,7 +150,9 @@ type connectionManager struct {
 	statsReportInterval  time.Duration
 	validator            validator
 	p2pDialer            p2p.Dialer
+	failoverPolicy       FailoverPolicy
 	timeGetter           TimeGetter
+	resolveHost          HostResolver
 
 	// These are populated by Connect at runtime.
 	ctx                    context.Context
@@ -146,8 +168,10 @@ type connectionManager struct {
 	cancel                 func()
 	channel                p2p.Channel
 
-	discoLock      sync.Mutex
-	connectOptions ConnectOptions
+	discoLock          sync.Mutex
+	failoverLock       sync.Mutex
+	connectOptions     ConnectOptions
+	connectOptionsLock sync.RWMutex
 }
 
 // NewManager creates connection manager with given dependencies
@@ -161,6 +185,7 @@ func NewManager(
 	statsReportInterval time.Duration,
 	validator validator,
 	p2pDialer p2p.Dialer,
+	failoverPolicy FailoverPolicy,
 ) *connectionManager {
 	return &connectionManager{
 		newConnection:        connectionCreator,
@@ -175,7 +200,9 @@ func NewManager(
 		statsReportInterval:  statsReportInterval,
 		validator:            validator,
 		p2pDialer:            p2pDialer,
+		failoverPolicy:       failoverPolicy,
 		timeGetter:           time.Now,
+		resolveHost:          dns.LookupViaSystem,
 	}
 }
 
@@ -183,13 +210,30 @@ func (m *connectionManager) chainID() int64 {
 	return config.GetInt64(config.FlagChainID)
 }
 
-func (m *connectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, params ConnectParams) (err error) {
+func (m *connectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, params ConnectParams) error {
+	if m.Status().State != connectionstate.NotConnected {
+		return ErrAlreadyExists
+	}
+
+	return m.connect(consumerID, hermesID, proposal, params, connectionstate.Connecting)
+}
+
+// connect establishes connection with the provider of the proposal, reporting given state until connected.
+func (m *connectionManager) connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, params ConnectParams, state connectionstate.State) (err error) {
 	var sessionID session.ID
 
+	m.publishTraceEvent(params.ConnectionID, sessionID, connectionstate.TraceConnect, "Connect",
+		fmt.Sprintf("consumer %s, provider %s, service %s, hermes %s, state %s", consumerID.Address, proposal.ProviderID, proposal.ServiceType, hermesID.Hex(), state), 0)
+
 	tracer := trace.NewTracer("Consumer whole Connect")
 	defer func() {
 		traceResult := tracer.Finish(m.eventBus, string(sessionID))
 		log.Debug().Msgf("Consumer connection trace: %s", traceResult)
+
+		m.publishTraceStages(params.ConnectionID, sessionID, tracer)
+		if err != nil {
+			m.publishTraceEvent(params.ConnectionID, sessionID, connectionstate.TraceError, "Connect failed", err.Error(), 0)
+		}
 	}()
 
 	// make sure cache is cleared when connect terminates at any stage as part of disconnect
@@ -199,11 +243,17 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return nil
 	})
 
-	if m.Status().State != connectionstate.NotConnected {
-		return ErrAlreadyExists
+	hermesID, err = m.validator.SelectHermes(m.chainID(), consumerID, hermesID, proposal)
+	if err != nil {
+		return err
+	}
+
+	err = m.validator.Validate(m.chainID(), consumerID, hermesID, proposal)
+	if err != nil {
+		return err
 	}
 
-	err = m.validator.Validate(m.chainID(), consumerID, proposal)
+	routes, err := m.splitTunnelRoutes(params)
 	if err != nil {
 		return err
 	}
@@ -212,17 +262,22 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 	m.ctxLock.Unlock()
 
-	m.statusConnecting(consumerID, hermesID, proposal)
+	m.statusConnecting(consumerID, hermesID, proposal, params.ConnectionID, state)
 	defer func() {
 		if err != nil {
 			log.Err(err).Msg("Connect failed, disconnecting")
-			m.disconnect()
+			if state == connectionstate.FailingOver {
+				// Keep failing over status, next proposal is going to be tried.
+				m.teardown(func() {})
+			} else {
+				m.disconnect()
+			}
 		}
 	}()
 
 	providerID := identity.FromAddress(proposal.ProviderID)
 
-	err = m.createP2PChannel(m.currentCtx(), consumerID, providerID, proposal, tracer)
+	err = m.createP2PChannel(m.currentCtx(), consumerID, providerID, proposal, params.ConnectionID, tracer)
 	if err != nil {
 		return fmt.Errorf("could not create p2p channel during connect: %w", err)
 	}
@@ -237,7 +292,12 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return err
 	}
 
-	sessionDTO, err := m.createP2PSession(m.currentCtx(), connection, m.channel, consumerID, hermesID, proposal, tracer)
+	var resume *pb.SessionResume
+	if resumable, ok := paymentSession.(ResumablePaymentIssuer); ok {
+		resume = resumable.ResumeOffer()
+	}
+
+	sessionDTO, err := m.createP2PSession(m.currentCtx(), connection, m.channel, consumerID, hermesID, proposal, resume, tracer)
 	sessionID = session.ID(sessionDTO.GetID())
 	if err != nil {
 		m.sendSessionStatus(m.channel, consumerID, sessionID, connectivity.StatusSessionEstablishmentFailed, err)
@@ -245,7 +305,8 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 	}
 
 	traceStart := tracer.StartStage("Consumer session creation (start)")
-	go m.keepAliveLoop(m.channel, sessionID)
+	m.handleSessionStatus(m.channel, sessionID)
+	go m.keepAliveLoop(m.currentCtx(), m.channel, sessionID)
 	m.setStatus(func(status *connectionstate.Status) {
 		status.SessionID = sessionID
 	})
@@ -254,7 +315,7 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 	tracer.EndStage(traceStart)
 
 	// Try to establish connection with peer.
-	m.connectOptions = ConnectOptions{
+	connectOptions := ConnectOptions{
 		SessionID:       sessionID,
 		SessionConfig:   sessionDTO.GetConfig(),
 		Params:          params,
@@ -264,8 +325,13 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		ProviderNATConn: m.channel.ServiceConn(),
 		ChannelConn:     m.channel.Conn(),
 		HermesID:        hermesID,
+		Routes:          routes,
 	}
-	err = m.startConnection(m.currentCtx(), connection, m.connectOptions, tracer)
+	m.connectOptionsLock.Lock()
+	m.connectOptions = connectOptions
+	m.connectOptionsLock.Unlock()
+
+	err = m.startConnection(m.currentCtx(), connection, connectOptions, tracer)
 	if err != nil {
 		if err == context.Canceled {
 			return ErrConnectionCancelled
@@ -275,8 +341,10 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		})
 		m.publishStateEvent(connectionstate.StateConnectionFailed)
 
-		log.Info().Err(err).Msg("Cancelling connection initiation: ")
-		m.Cancel()
+		if state != connectionstate.FailingOver {
+			log.Info().Err(err).Msg("Cancelling connection initiation: ")
+			m.Cancel()
+		}
 		return err
 	}
 
@@ -341,6 +409,29 @@ func (m *connectionManager) sendSessionStatus(channel p2p.ChannelSender, consume
 	return nil
 }
 
+// handleSessionStatus handles session connectivity statuses sent by provider.
+func (m *connectionManager) handleSessionStatus(channel p2p.ChannelHandler, sessionID session.ID) {
+	ctx := m.currentCtx()
+	channel.Handle(p2p.TopicSessionStatus, func(c p2p.Context) error {
+		var ss pb.SessionStatus
+		if err := c.Request().UnmarshalProto(&ss); err != nil {
+			return err
+		}
+		log.Debug().Msgf("Received P2P session status message for %q: %s", p2p.TopicSessionStatus, ss.String())
+
+		if ss.GetSessionID() != string(sessionID) {
+			return c.OK()
+		}
+
+		if connectivity.StatusCode(ss.GetCode()) == connectivity.StatusSessionQuotaExceeded {
+			log.Warn().Msgf("Session %s is closed by provider: %s", sessionID, ss.GetMessage())
+			m.publishStateEvent(connectionstate.StateSessionQuotaExceeded)
+			go m.failover(ctx, false)
+		}
+		return c.OK()
+	})
+}
+
 func (m *connectionManager) getPublicIP() string {
 	currentPublicIP, err := m.ipResolver.GetPublicIP()
 	if err != nil {
@@ -403,22 +494,22 @@ func (m *connectionManager) cleanAfterDisconnect() {
 	m.cleanupAfterDisconnect = nil
 }
 
-func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, providerID identity.Identity, proposal market.ServiceProposal, tracer *trace.Tracer) error {
+func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, providerID identity.Identity, proposal market.ServiceProposal, connectionID string, tracer *trace.Tracer) error {
 	trace := tracer.StartStage("Consumer P2P channel creation")
 	defer tracer.EndStage(trace)
 
-	contactDef, err := p2p.ParseContact(proposal.ProviderContacts)
+	signalingDef, err := p2p.ParseSignaling(proposal.ProviderContacts)
 	if err != nil {
 		return fmt.Errorf("provider does not support p2p communication: %w", err)
 	}
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, p2pDialTimeout)
-	defer cancel()
-
 	// TODO register all handlers before channel read/write loops
-	channel, err := m.p2pDialer.Dial(timeoutCtx, consumerID, providerID, proposal.ServiceType, contactDef, tracer)
+	channel, err := m.dialP2P(ctx, consumerID, providerID, proposal.ServiceType, signalingDef, tracer)
 	if err != nil {
-		return fmt.Errorf("p2p dialer failed: %w", err)
+		channel, err = m.dialP2PFallback(ctx, consumerID, providerID, proposal, signalingDef, err, tracer)
+		if err != nil {
+			return err
+		}
 	}
 	m.addCleanupAfterDisconnect(func() error {
 		log.Trace().Msg("Cleaning: closing P2P communication channel")
@@ -427,10 +518,60 @@ func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, pr
 		return channel.Close()
 	})
 
-	m.channel = channel
+	m.channel = newTracedChannel(channel, connectionID, m.eventBus)
 	return nil
 }
 
+func (m *connectionManager) dialP2P(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef p2p.SignalingDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, p2pDialTimeout)
+	defer cancel()
+
+	return m.p2pDialer.Dial(timeoutCtx, consumerID, providerID, serviceType, signalingDef, tracer)
+}
+
+// dialP2PFallback dials provider over the stream transport and then over relays advertised by provider
+// after the channel could not be established by NAT hole punching.
+func (m *connectionManager) dialP2PFallback(ctx context.Context, consumerID, providerID identity.Identity, proposal market.ServiceProposal, signalingDef p2p.SignalingDefinition, dialErr error, tracer *trace.Tracer) (p2p.Channel, error) {
+	err := fmt.Errorf("p2p dialer failed: %w", dialErr)
+
+	if streamDef, streamErr := p2p.ParseStreamContact(proposal.ProviderContacts); streamErr == nil {
+		log.Warn().Err(err).Msgf("Failed to dial provider over UDP, falling back to %s stream transport", streamDef.Address)
+		channel, streamErr := m.dialP2PStream(ctx, consumerID, providerID, proposal.ServiceType, signalingDef, streamDef, tracer)
+		if streamErr == nil {
+			return channel, nil
+		}
+		err = fmt.Errorf("p2p stream dialer failed: %w", streamErr)
+	}
+
+	relayDef, relayErr := p2p.ParseRelayContact(proposal.ProviderContacts)
+	if relayErr != nil {
+		return nil, err
+	}
+	for _, relayAddress := range relayDef.Addresses {
+		log.Warn().Err(err).Msgf("Failed to dial provider, falling back to %s relay", relayAddress)
+		channel, relayErr := m.dialP2PRelay(ctx, consumerID, providerID, proposal.ServiceType, signalingDef, relayAddress, tracer)
+		if relayErr == nil {
+			return channel, nil
+		}
+		err = fmt.Errorf("p2p relay dialer failed: %w", relayErr)
+	}
+	return nil, err
+}
+
+func (m *connectionManager) dialP2PStream(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef p2p.SignalingDefinition, streamDef p2p.StreamContactDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, p2pDialTimeout)
+	defer cancel()
+
+	return m.p2pDialer.DialStream(timeoutCtx, consumerID, providerID, serviceType, signalingDef, streamDef, tracer)
+}
+
+func (m *connectionManager) dialP2PRelay(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef p2p.SignalingDefinition, relayAddress string, tracer *trace.Tracer) (p2p.Channel, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, p2pDialTimeout)
+	defer cancel()
+
+	return m.p2pDialer.DialRelay(timeoutCtx, consumerID, providerID, serviceType, signalingDef, relayAddress, tracer)
+}
+
 func (m *connectionManager) addCleanupAfterDisconnect(fn func() error) {
 	m.cleanupLock.Lock()
 	defer m.cleanupLock.Unlock()
@@ -443,7 +584,7 @@ func (m *connectionManager) addCleanup(fn func() error) {
 	m.cleanup = append(m.cleanup, fn)
 }
 
-func (m *connectionManager) createP2PSession(ctx context.Context, c Connection, p2pChannel p2p.ChannelSender, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, tracer *trace.Tracer) (*pb.SessionResponse, error) {
+func (m *connectionManager) createP2PSession(ctx context.Context, c Connection, p2pChannel p2p.ChannelSender, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, resume *pb.SessionResume, tracer *trace.Tracer) (*pb.SessionResponse, error) {
 	trace := tracer.StartStage("Consumer session creation")
 	defer tracer.EndStage(trace)
 
@@ -468,6 +609,7 @@ func (m *connectionManager) createP2PSession(ctx context.Context, c Connection,
 		},
 		ProposalID: int64(proposal.ID),
 		Config:     config,
+		Resume:     resume,
 	}
 	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionCreate, sessionRequest.String())
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
@@ -553,7 +695,7 @@ func (m *connectionManager) startConnection(ctx context.Context, conn Connection
 		return nil
 	})
 
-	err = m.setupTrafficBlock(connectOptions.Params.DisableKillSwitch)
+	err = m.setupTrafficBlock(connectOptions.Params.DisableKillSwitch, connectOptions.Routes.Exclude)
 	if err != nil {
 		return err
 	}
@@ -572,8 +714,8 @@ func (m *connectionManager) startConnection(ctx context.Context, conn Connection
 		return nil
 	})
 
-	go m.consumeConnectionStates(conn.State())
-	go m.connectionWaiter(conn)
+	go m.consumeConnectionStates(ctx, conn.State())
+	go m.connectionWaiter(ctx, conn)
 
 	// Clear IP cache so session IP check can report that IP has really changed.
 	m.clearIPCache()
@@ -605,15 +747,16 @@ func (m *connectionManager) setStatus(delta func(status *connectionstate.Status)
 	}
 }
 
-func (m *connectionManager) statusConnecting(consumerID identity.Identity, accountantID common.Address, proposal market.ServiceProposal) {
+func (m *connectionManager) statusConnecting(consumerID identity.Identity, accountantID common.Address, proposal market.ServiceProposal, connectionID string, state connectionstate.State) {
 	m.setStatus(func(status *connectionstate.Status) {
 		*status = connectionstate.Status{
+			ConnectionID:     connectionID,
 			StartedAt:        m.timeGetter(),
 			ConsumerID:       consumerID,
 			ConsumerLocation: m.locationResolver.GetOrigin(),
 			HermesID:         accountantID,
 			Proposal:         proposal,
-			State:            connectionstate.Connecting,
+			State:            state,
 		}
 	})
 }
@@ -630,6 +773,12 @@ func (m *connectionManager) statusReconnecting() {
 	})
 }
 
+func (m *connectionManager) statusFailingOver() {
+	m.setStatus(func(status *connectionstate.Status) {
+		status.State = connectionstate.FailingOver
+	})
+}
+
 func (m *connectionManager) statusNotConnected() {
 	m.setStatus(func(status *connectionstate.Status) {
 		status.State = connectionstate.NotConnected
@@ -672,6 +821,11 @@ func (m *connectionManager) CheckChannel(ctx context.Context) error {
 }
 
 func (m *connectionManager) disconnect() {
+	m.teardown(m.statusNotConnected)
+}
+
+// teardown stops current connection, the status is set before cleanups which require the connection to be stopped.
+func (m *connectionManager) teardown(setStatus func()) {
 	m.discoLock.Lock()
 	defer m.discoLock.Unlock()
 
@@ -685,12 +839,12 @@ func (m *connectionManager) disconnect() {
 	m.ctxLock.Unlock()
 
 	m.cleanConnection()
-	m.statusNotConnected()
+	setStatus()
 
 	m.cleanAfterDisconnect()
 }
 
-func (m *connectionManager) connectionWaiter(connection Connection) {
+func (m *connectionManager) connectionWaiter(ctx context.Context, connection Connection) {
 	err := connection.Wait()
 	if err != nil {
 		log.Warn().Err(err).Msg("Connection exited with error")
@@ -698,7 +852,11 @@ func (m *connectionManager) connectionWaiter(connection Connection) {
 		log.Info().Msg("Connection exited")
 	}
 
-	logDisconnectError(m.Disconnect())
+	if ctx.Err() != nil {
+		// Connection was already torn down, it might be replaced by failover.
+		return
+	}
+	m.failover(ctx, false)
 }
 
 func (m *connectionManager) waitForConnectedState(stateChannel <-chan connectionstate.State) error {
@@ -727,13 +885,17 @@ func (m *connectionManager) waitForConnectedState(stateChannel <-chan connection
 	}
 }
 
-func (m *connectionManager) consumeConnectionStates(stateChannel <-chan connectionstate.State) {
+func (m *connectionManager) consumeConnectionStates(ctx context.Context, stateChannel <-chan connectionstate.State) {
 	for state := range stateChannel {
 		m.onStateChanged(state)
 	}
 
 	log.Debug().Msg("State updater stopCalled")
-	logDisconnectError(m.Disconnect())
+	if ctx.Err() != nil {
+		// Connection was already torn down, it might be replaced by failover.
+		return
+	}
+	m.failover(ctx, false)
 }
 
 func (m *connectionManager) onStateChanged(state connectionstate.State) {
@@ -748,17 +910,12 @@ func (m *connectionManager) onStateChanged(state connectionstate.State) {
 	}
 }
 
-func (m *connectionManager) setupTrafficBlock(disableKillSwitch bool) error {
+func (m *connectionManager) setupTrafficBlock(disableKillSwitch bool, excluded []net.IPNet) error {
 	if disableKillSwitch {
 		return nil
 	}
 
-	outboundIP, err := m.ipResolver.GetOutboundIP()
-	if err != nil {
-		return err
-	}
-
-	removeRule, err := firewall.BlockNonTunnelTraffic(firewall.Session, outboundIP)
+	removeRule, err := m.blockNonTunnelTraffic(excluded)
 	if err != nil {
 		return err
 	}
@@ -771,6 +928,47 @@ func (m *connectionManager) setupTrafficBlock(disableKillSwitch bool) error {
 	return nil
 }
 
+// blockNonTunnelTraffic blocks traffic leaving outside the tunnel, except the one to excluded subnets.
+func (m *connectionManager) blockNonTunnelTraffic(excluded []net.IPNet) (firewall.OutgoingRuleRemove, error) {
+	outboundIP, err := m.ipResolver.GetOutboundIP()
+	if err != nil {
+		return nil, err
+	}
+
+	removeBlock, err := firewall.BlockNonTunnelTraffic(firewall.Session, outboundIP)
+	if err != nil {
+		return nil, err
+	}
+
+	removeRules := []firewall.OutgoingRuleRemove{removeBlock}
+	removeAll := func() {
+		for _, removeRule := range removeRules {
+			removeRule()
+		}
+	}
+	for _, subnet := range excluded {
+		removeRule, err := firewall.AllowIPAccess(subnet.String())
+		if err != nil {
+			removeAll()
+			return nil, fmt.Errorf("could not allow access to excluded subnet %s: %w", subnet.String(), err)
+		}
+		removeRules = append(removeRules, removeRule)
+	}
+	return removeAll, nil
+}
+
+// splitTunnelRoutes resolves destinations of the split tunnel into routes.
+func (m *connectionManager) splitTunnelRoutes(params ConnectParams) (Routes, error) {
+	if !params.SplitTunnel.Enabled() {
+		return Routes{}, nil
+	}
+	if len(params.SplitTunnel.Include) > 0 && !params.DisableKillSwitch {
+		return Routes{}, ErrSplitTunnelKillSwitch
+	}
+
+	return params.SplitTunnel.Resolve(m.resolveHost)
+}
+
 func (m *connectionManager) publishStateEvent(state connectionstate.State) {
 	m.eventBus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
 		State:       state,
@@ -778,7 +976,40 @@ func (m *connectionManager) publishStateEvent(state connectionstate.State) {
 	})
 }
 
-func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session.ID) {
+func (m *connectionManager) publishTraceEvent(connectionID string, sessionID session.ID, kind connectionstate.TraceKind, name, details string, duration time.Duration) {
+	m.eventBus.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+		ConnectionID: connectionID,
+		SessionID:    sessionID,
+		Time:         time.Now(),
+		Kind:         kind,
+		Name:         name,
+		Details:      details,
+		Duration:     duration,
+	})
+}
+
+func (m *connectionManager) publishTraceStages(connectionID string, sessionID session.ID, tracer *trace.Tracer) {
+	for _, stage := range tracer.Stages() {
+		event := connectionstate.AppEventConnectionTrace{
+			ConnectionID: connectionID,
+			SessionID:    sessionID,
+			Time:         stage.Start,
+			Kind:         connectionstate.TraceStage,
+			Name:         stage.Key,
+		}
+		if stage.End.IsZero() {
+			event.Details = "did not end"
+		} else {
+			event.Duration = stage.End.Sub(stage.Start)
+		}
+		if stage.Err != nil {
+			event.Details = "failed: " + stage.Err.Error()
+		}
+		m.eventBus.Publish(connectionstate.AppTopicConnectionTrace, event)
+	}
+}
+
+func (m *connectionManager) keepAliveLoop(ctx context.Context, channel p2p.Channel, sessionID session.ID) {
 	// TODO: Remove this check once all provider migrates to p2p.
 	if channel == nil {
 		return
@@ -799,18 +1030,24 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 	var errCount int
 	for {
 		select {
-		case <-m.currentCtx().Done():
-			log.Debug().Msgf("Stopping p2p keepalive: %v", m.currentCtx().Err())
+		case <-ctx.Done():
+			log.Debug().Msgf("Stopping p2p keepalive: %v", ctx.Err())
 			return
 		case <-time.After(m.config.KeepAlive.SendInterval):
-			ctx, cancel := context.WithTimeout(context.Background(), m.config.KeepAlive.SendTimeout)
-			if err := m.sendKeepAlivePing(ctx, channel, sessionID); err != nil {
+			pingCtx, cancel := context.WithTimeout(context.Background(), m.config.KeepAlive.SendTimeout)
+			if err := m.sendKeepAlivePing(pingCtx, channel, sessionID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sessionID)
 				errCount++
-				if errCount == m.config.KeepAlive.MaxSendErrCount {
-					log.Error().Msgf("Max p2p keepalive err count reached, disconnecting. SessionID=%s", sessionID)
-					m.Disconnect()
+				closing := errCount == m.config.KeepAlive.MaxSendErrCount
+				m.eventBus.Publish(p2p.AppTopicKeepAliveFailed, p2p.AppEventKeepAliveFailed{
+					SessionID: string(sessionID),
+					Role:      "consumer",
+					Closed:    closing,
+				})
+				if closing {
+					log.Error().Msgf("Max p2p keepalive err count reached, failing over. SessionID=%s", sessionID)
 					cancel()
+					m.failover(ctx, false)
 					return
 				}
 			} else {
@@ -837,6 +1074,11 @@ func (m *connectionManager) currentCtx() context.Context {
 }
 
 func (m *connectionManager) Reconnect() {
+	if m.failoverEnabled() {
+		m.failover(m.currentCtx(), true)
+		return
+	}
+
 	err := m.Disconnect()
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to disconnect stale session")
@@ -846,12 +1088,88 @@ func (m *connectionManager) Reconnect() {
 	m.cleanupFinishedLock.Lock()
 	defer m.cleanupFinishedLock.Unlock()
 	<-m.cleanupFinished
-	err = m.Connect(m.connectOptions.ConsumerID, m.connectOptions.HermesID, m.connectOptions.Proposal, m.connectOptions.Params)
+	options := m.currentConnectOptions()
+	err = m.Connect(options.ConsumerID, options.HermesID, options.Proposal, options.Params)
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to reconnect")
 	}
 }
 
+func (m *connectionManager) currentConnectOptions() ConnectOptions {
+	m.connectOptionsLock.RLock()
+	defer m.connectOptionsLock.RUnlock()
+
+	return m.connectOptions
+}
+
+func (m *connectionManager) failoverEnabled() bool {
+	return m.failoverPolicy != nil && m.currentConnectOptions().Params.Failover != nil && m.config.Failover.MaxAttempts > 0
+}
+
+// failover replaces the lost connection with the connection to the best proposal matching failover filter,
+// keeping the kill switch up in between. Connection is closed if failover is disabled or all attempts fail.
+// The lost connection is identified by its context, so it is replaced just once, whichever watcher notices it first.
+func (m *connectionManager) failover(lost context.Context, retryCurrent bool) {
+	m.failoverLock.Lock()
+	defer m.failoverLock.Unlock()
+
+	if lost != nil && lost.Err() != nil {
+		// Connection was already torn down or replaced.
+		return
+	}
+	if !m.failoverEnabled() {
+		logDisconnectError(m.Disconnect())
+		return
+	}
+	if m.Status().State == connectionstate.NotConnected {
+		return
+	}
+
+	options := m.currentConnectOptions()
+	if !options.Params.DisableKillSwitch {
+		removeRule, err := m.blockNonTunnelTraffic(options.Routes.Exclude)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to keep traffic blocked, disconnecting")
+			logDisconnectError(m.Disconnect())
+			return
+		}
+		defer removeRule()
+	}
+
+	m.teardown(m.statusFailingOver)
+
+	var candidates []market.ServiceProposal
+	if retryCurrent {
+		candidates = append(candidates, options.Proposal)
+	}
+	others, err := m.failoverPolicy.Candidates(options.Params.Failover, []market.ServiceProposal{options.Proposal})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to find proposals to fail over to")
+	}
+	candidates = append(candidates, others...)
+	if len(candidates) > m.config.Failover.MaxAttempts {
+		candidates = candidates[:m.config.Failover.MaxAttempts]
+	}
+
+	for _, proposal := range candidates {
+		if m.Status().State != connectionstate.FailingOver {
+			log.Info().Msg("Failover interrupted")
+			return
+		}
+
+		log.Info().Msgf("Failing over to provider %s, service %s", proposal.ProviderID, proposal.ServiceType)
+		err := m.connect(options.ConsumerID, options.HermesID, proposal, options.Params, connectionstate.FailingOver)
+		if err == nil {
+			return
+		}
+		log.Warn().Err(err).Msgf("Failed to fail over to provider %s", proposal.ProviderID)
+	}
+
+	log.Error().Msg("Failover failed, no provider could be connected")
+	m.publishStateEvent(connectionstate.StateFailoverFailed)
+	m.statusNotConnected()
+}
+
 func logDisconnectError(err error) {
 	if err != nil && err != ErrNoConnection {
 		log.Error().Err(err).Msg("Disconnect error")
@@ -28,6 +28,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/trace"
@@ -124,6 +125,9 @@ func (tc *testContext) SetupTest() {
 			SendInterval:    100 * time.Millisecond,
 			MaxSendErrCount: 5,
 		},
+		Failover: FailoverConfig{
+			MaxAttempts: 3,
+		},
 	}
 	tc.fakeIPResolver = ip.NewResolverMock("ip")
 	tc.fakeLocationResolver = &mockLocationResolver{}
@@ -132,7 +136,7 @@ func (tc *testContext) SetupTest() {
 	brokerConn := nats.StartConnectionMock()
 	brokerConn.MockResponse("fake-node-1.p2p-config-exchange", []byte("123"))
 
-	tc.mockP2P = &mockP2PDialer{&mockP2PChannel{}}
+	tc.mockP2P = &mockP2PDialer{ch: &mockP2PChannel{}}
 	tc.mockTime = time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC)
 
 	tc.connManager = NewManager(
@@ -152,6 +156,7 @@ func (tc *testContext) SetupTest() {
 		tc.statsReportInterval,
 		&mockValidator{},
 		tc.mockP2P,
+		&mockFailoverPolicy{},
 	)
 	tc.connManager.timeGetter = func() time.Time {
 		return tc.mockTime
@@ -180,6 +185,43 @@ func (tc *testContext) TestOnConnectErrorStatusIsNotConnected() {
 	)
 }
 
+func (tc *testContext) TestConnectFallsBackToStreamTransportWhenDialFails() {
+	tc.mockP2P.dialErr = errors.New("UDP is blocked")
+	proposal := activeProposal
+	proposal.ProviderContacts = market.ContactList{
+		activeProviderContact,
+		{Type: p2p.ContactTypeStreamV1, Definition: p2p.StreamContactDefinition{Address: "1.2.3.4:443", TLS: true}},
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, proposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), connectionstate.Connected, tc.connManager.Status().State)
+	assert.Equal(tc.T(), "1.2.3.4:443", tc.mockP2P.getDialedStreamTo())
+}
+
+func (tc *testContext) TestConnectFailsWhenDialFailsAndProviderHasNoStreamContact() {
+	tc.mockP2P.dialErr = errors.New("UDP is blocked")
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
+	assert.Error(tc.T(), err)
+	assert.Empty(tc.T(), tc.mockP2P.getDialedStreamTo())
+}
+
+func (tc *testContext) TestConnectFallsBackToRelayWhenDialFails() {
+	tc.mockP2P.dialErr = errors.New("NAT hole punching failed")
+	tc.mockP2P.failingRelays = map[string]bool{"1.2.3.4:4500": true}
+	proposal := activeProposal
+	proposal.ProviderContacts = market.ContactList{
+		activeProviderContact,
+		{Type: p2p.ContactTypeRelayV1, Definition: p2p.RelayContactDefinition{Addresses: []string{"1.2.3.4:4500", "5.6.7.8:4500"}}},
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, proposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), connectionstate.Connected, tc.connManager.Status().State)
+	assert.Equal(tc.T(), []string{"1.2.3.4:4500", "5.6.7.8:4500"}, tc.mockP2P.getDialedRelayTo())
+}
+
 func (tc *testContext) TestWhenManagerMadeConnectionStatusReturnsConnectedStateAndSessionId() {
 	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
 	assert.NoError(tc.T(), err)
@@ -471,6 +513,39 @@ func (tc *testContext) Test_SessionEndPublished_OnConnectError() {
 	assert.True(tc.T(), found)
 }
 
+func (tc *testContext) Test_ManagerPublishesTraceEvents() {
+	tc.stubPublisher.Clear()
+
+	tc.fakeConnectionFactory.mockConnection.onStartReturnError = errors.New("fatal connection error")
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{ConnectionID: "conn1"})
+	assert.Error(tc.T(), err)
+
+	var kinds []connectionstate.TraceKind
+	var stages []string
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic != connectionstate.AppTopicConnectionTrace {
+			continue
+		}
+		event := v.Event.(connectionstate.AppEventConnectionTrace)
+		assert.Equal(tc.T(), "conn1", event.ConnectionID)
+		if len(kinds) == 0 || kinds[len(kinds)-1] != event.Kind {
+			kinds = append(kinds, event.Kind)
+		}
+		if event.Kind == connectionstate.TraceStage {
+			stages = append(stages, event.Name)
+		}
+		if event.Kind == connectionstate.TraceError {
+			assert.Equal(tc.T(), establishedSessionID, event.SessionID)
+			assert.Contains(tc.T(), event.Details, "fatal connection error")
+		}
+	}
+
+	assert.Equal(tc.T(), connectionstate.TraceConnect, kinds[0])
+	assert.Contains(tc.T(), kinds, connectionstate.TraceStage)
+	assert.Equal(tc.T(), connectionstate.TraceError, kinds[len(kinds)-1])
+	assert.Contains(tc.T(), stages, "Consumer whole Connect")
+}
+
 func (tc *testContext) Test_ManagerPublishesEvents() {
 	tc.stubPublisher.Clear()
 
@@ -606,6 +681,146 @@ func (tc *testContext) Test_ManagerNotifiesAboutSuccessfulConnection() {
 	)
 }
 
+func (tc *testContext) TestFailsOverToNextProposalWhenKeepAliveFails() {
+	nextProposal := activeProposal
+	nextProposal.ProviderID = "fake-node-2"
+	tc.connManager.failoverPolicy = &mockFailoverPolicy{proposals: []market.ServiceProposal{activeProposal, nextProposal}}
+	tc.connManager.config.KeepAlive.MaxSendErrCount = 1
+	tc.stubPublisher.Clear()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		DisableKillSwitch: true,
+		Failover:          &proposal.Filter{ServiceType: activeServiceType},
+	})
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		status := tc.connManager.Status()
+		return status.State == connectionstate.Connected && status.Proposal.ProviderID == nextProposal.ProviderID
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var failingOver bool
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic == connectionstate.AppTopicConnectionState && v.Event.(connectionstate.AppEventConnectionState).State == connectionstate.FailingOver {
+			failingOver = true
+		}
+	}
+	assert.True(tc.T(), failingOver)
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) TestFailsOverToNextProposalWhenTunnelExits() {
+	nextProposal := activeProposal
+	nextProposal.ProviderID = "fake-node-2"
+	tc.connManager.failoverPolicy = &mockFailoverPolicy{proposals: []market.ServiceProposal{activeProposal, nextProposal}}
+	tc.fakeConnectionFactory.mockConnection.onStopReportStates = []fakeState{}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		DisableKillSwitch: true,
+		Failover:          &proposal.Filter{ServiceType: activeServiceType},
+	})
+	assert.NoError(tc.T(), err)
+
+	tc.fakeConnectionFactory.mockConnection.reportState(exitingState)
+	tc.fakeConnectionFactory.mockConnection.reportState(processExited)
+
+	assert.Eventually(tc.T(), func() bool {
+		status := tc.connManager.Status()
+		return status.State == connectionstate.Connected && status.Proposal.ProviderID == nextProposal.ProviderID
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) TestDisconnectsWhenNoProposalToFailOver() {
+	tc.connManager.config.KeepAlive.MaxSendErrCount = 1
+	tc.stubPublisher.Clear()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		DisableKillSwitch: true,
+		Failover:          &proposal.Filter{ServiceType: activeServiceType},
+	})
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().State == connectionstate.NotConnected
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var failoverFailed bool
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic == connectionstate.AppTopicConnectionState && v.Event.(connectionstate.AppEventConnectionState).State == connectionstate.StateFailoverFailed {
+			failoverFailed = true
+		}
+	}
+	assert.True(tc.T(), failoverFailed)
+}
+
+func (tc *testContext) TestDisconnectsWhenProviderReportsExceededQuota() {
+	tc.stubPublisher.Clear()
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	err = tc.mockP2P.ch.receive(p2p.TopicSessionStatus, p2p.ProtoMessage(&pb.SessionStatus{
+		ConsumerID: consumerID.Address,
+		SessionID:  string(establishedSessionID),
+		Code:       uint32(connectivity.StatusSessionQuotaExceeded),
+		Message:    "daily data quota exceeded",
+	}))
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().State == connectionstate.NotConnected
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var quotaExceeded bool
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic == connectionstate.AppTopicConnectionState && v.Event.(connectionstate.AppEventConnectionState).State == connectionstate.StateSessionQuotaExceeded {
+			quotaExceeded = true
+		}
+	}
+	assert.True(tc.T(), quotaExceeded)
+}
+
+func (tc *testContext) TestIgnoresStatusOfOtherSession() {
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	err = tc.mockP2P.ch.receive(p2p.TopicSessionStatus, p2p.ProtoMessage(&pb.SessionStatus{
+		ConsumerID: consumerID.Address,
+		SessionID:  "other-session",
+		Code:       uint32(connectivity.StatusSessionQuotaExceeded),
+	}))
+	assert.NoError(tc.T(), err)
+
+	waitABit()
+	assert.Equal(tc.T(), connectionstate.Connected, tc.connManager.Status().State)
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) TestConnectResolvesSplitTunnelRoutes() {
+	tc.connManager.resolveHost = func(host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("1.2.3.4")}, nil
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		DisableKillSwitch: true,
+		SplitTunnel:       SplitTunnel{Include: []string{"10.0.0.0/8"}, Exclude: []string{"example.com"}},
+	})
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), Routes{
+		Include: []net.IPNet{{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}},
+		Exclude: []net.IPNet{{IP: net.IP{1, 2, 3, 4}, Mask: net.CIDRMask(32, 32)}},
+	}, tc.connManager.currentConnectOptions().Routes)
+}
+
+func (tc *testContext) TestConnectRejectsIncludedSplitTunnelWithKillSwitch() {
+	err := tc.connManager.Connect(consumerID, hermesID, activeProposal, ConnectParams{
+		SplitTunnel: SplitTunnel{Include: []string{"10.0.0.0/8"}},
+	})
+	assert.Equal(tc.T(), ErrSplitTunnelKillSwitch, err)
+	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
@@ -678,16 +893,61 @@ func (mpm *mockPaymentMethod) GetRate() market.PaymentRate {
 }
 
 type mockP2PDialer struct {
-	ch *mockP2PChannel
+	ch      *mockP2PChannel
+	dialErr error
+
+	lock           sync.Mutex
+	dialedStreamTo string
+	dialedRelayTo  []string
+	failingRelays  map[string]bool
+}
+
+func (m *mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, signalingDef p2p.SignalingDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+	if m.dialErr != nil {
+		return nil, m.dialErr
+	}
+	return m.ch, nil
+}
+
+func (m *mockP2PDialer) DialStream(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, signalingDef p2p.SignalingDefinition, streamDef p2p.StreamContactDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.dialedStreamTo = streamDef.Address
+	return m.ch, nil
 }
 
-func (m mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition, tracer *trace.Tracer) (p2p.Channel, error) {
+func (m *mockP2PDialer) SetSocketProtector(protect p2p.SocketProtector) {}
+
+func (m *mockP2PDialer) DialRelay(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, signalingDef p2p.SignalingDefinition, relayAddress string, tracer *trace.Tracer) (p2p.Channel, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.dialedRelayTo = append(m.dialedRelayTo, relayAddress)
+	if m.failingRelays[relayAddress] {
+		return nil, errors.New("relay is not reachable")
+	}
 	return m.ch, nil
 }
 
+func (m *mockP2PDialer) getDialedRelayTo() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.dialedRelayTo
+}
+
+func (m *mockP2PDialer) getDialedStreamTo() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.dialedStreamTo
+}
+
 type mockP2PChannel struct {
-	status proto.Message
-	lock   sync.Mutex
+	status   proto.Message
+	handlers map[string]p2p.HandlerFunc
+	lock     sync.Mutex
 }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn {
@@ -722,6 +982,23 @@ func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message)
 }
 
 func (m *mockP2PChannel) Handle(topic string, handler p2p.HandlerFunc) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.handlers == nil {
+		m.handlers = make(map[string]p2p.HandlerFunc)
+	}
+	m.handlers[topic] = handler
+}
+
+func (m *mockP2PChannel) receive(topic string, msg *p2p.Message) error {
+	m.lock.Lock()
+	handler, ok := m.handlers[topic]
+	m.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("no handler for topic %q", topic)
+	}
+	return handler(&mockP2PContext{req: msg})
 }
 
 func (m *mockP2PChannel) Tracer() *trace.Tracer {
@@ -738,11 +1015,55 @@ func (m *mockP2PChannel) Close() error {
 	return nil
 }
 
+type mockP2PContext struct {
+	req *p2p.Message
+}
+
+func (m *mockP2PContext) Request() *p2p.Message {
+	return m.req
+}
+
+func (m *mockP2PContext) Error(err error) error {
+	return err
+}
+
+func (m *mockP2PContext) OkWithReply(_ *p2p.Message) error {
+	return nil
+}
+
+func (m *mockP2PContext) OK() error {
+	return nil
+}
+
+type mockFailoverPolicy struct {
+	proposals []market.ServiceProposal
+}
+
+func (m *mockFailoverPolicy) Candidates(_ *proposal.Filter, exclude []market.ServiceProposal) ([]market.ServiceProposal, error) {
+	var candidates []market.ServiceProposal
+	for _, p := range m.proposals {
+		var excluded bool
+		for _, e := range exclude {
+			if p.ProviderID == e.ProviderID {
+				excluded = true
+			}
+		}
+		if !excluded {
+			candidates = append(candidates, p)
+		}
+	}
+	return candidates, nil
+}
+
 type mockValidator struct {
 	errorToReturn error
 }
 
-func (mv *mockValidator) Validate(chainID int64, consumerID identity.Identity, proposal market.ServiceProposal) error {
+func (mv *mockValidator) SelectHermes(chainID int64, consumerID identity.Identity, preferred common.Address, proposal market.ServiceProposal) (common.Address, error) {
+	return preferred, nil
+}
+
+func (mv *mockValidator) Validate(chainID int64, consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal) error {
 	return mv.errorToReturn
 }
 
@@ -0,0 +1,244 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofrs/uuid"
+	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrAdditionalConnectionRoutes indicates that additional connection would take over routes of the default connection.
+var ErrAdditionalConnectionRoutes = errors.New("additional connection must route only included split tunnel destinations")
+
+// ManagerFactory creates manager of a single connection.
+type ManagerFactory func() Manager
+
+// AdditionalConnection holds status and statistics of the connection managed by MultiManager.
+type AdditionalConnection struct {
+	Session    connectionstate.Status
+	Statistics connectionstate.Statistics
+	Throughput bandwidth.Throughput
+	Invoice    crypto.Invoice
+}
+
+type additionalConnection struct {
+	manager    Manager
+	statistics connectionstate.Statistics
+	throughput bandwidth.Throughput
+	invoice    crypto.Invoice
+}
+
+// MultiManager manages additional simultaneous connections, each of them addressed by its own ID.
+// Every connection has a separate manager, so statistics, payments and cleanup are kept apart.
+// The default connection is managed by the single connection Manager and is not listed here.
+//
+// Additional connections route only their included split tunnel destinations, so the routes and
+// the kill switch of the default connection stay the only ones: additional connections never set up
+// a kill switch of their own (see ErrSplitTunnelKillSwitch).
+type MultiManager struct {
+	newManager ManagerFactory
+
+	lock        sync.RWMutex
+	connections map[string]*additionalConnection
+}
+
+// NewMultiManager creates manager of additional connections.
+func NewMultiManager(newManager ManagerFactory) *MultiManager {
+	return &MultiManager{
+		newManager:  newManager,
+		connections: make(map[string]*additionalConnection),
+	}
+}
+
+// Subscribe subscribes to events of the additional connections.
+func (mm *MultiManager) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionState, mm.consumeStateEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connectionstate.AppTopicConnectionStatistics, mm.consumeStatisticsEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(bandwidth.AppTopicConnectionThroughput, mm.consumeThroughputEvent); err != nil {
+		return err
+	}
+	return bus.Subscribe(pingpongEvent.AppTopicInvoicePaid, mm.consumeInvoiceEvent)
+}
+
+// Connect creates new connection alongside the existing ones and returns its ID.
+// Split tunnel of the connection must include destinations and exclude none.
+func (mm *MultiManager) Connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, params ConnectParams) (string, error) {
+	if len(params.SplitTunnel.Include) == 0 || len(params.SplitTunnel.Exclude) > 0 {
+		return "", ErrAdditionalConnectionRoutes
+	}
+	if !params.DisableKillSwitch {
+		return "", ErrSplitTunnelKillSwitch
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+	connectionID := id.String()
+
+	mm.lock.Lock()
+	mm.connections[connectionID] = &additionalConnection{manager: mm.newManager()}
+	manager := mm.connections[connectionID].manager
+	mm.lock.Unlock()
+
+	params.ConnectionID = connectionID
+	if err := manager.Connect(consumerID, hermesID, proposal, params); err != nil {
+		mm.remove(connectionID)
+		return "", err
+	}
+	return connectionID, nil
+}
+
+// Connection returns status and statistics of the connection.
+func (mm *MultiManager) Connection(connectionID string) (AdditionalConnection, error) {
+	mm.lock.RLock()
+	defer mm.lock.RUnlock()
+
+	connection, ok := mm.connections[connectionID]
+	if !ok {
+		return AdditionalConnection{}, ErrNoConnection
+	}
+	return connection.info(), nil
+}
+
+// Connections returns all additional connections ordered by their start time.
+func (mm *MultiManager) Connections() []AdditionalConnection {
+	mm.lock.RLock()
+	defer mm.lock.RUnlock()
+
+	connections := make([]AdditionalConnection, 0, len(mm.connections))
+	for _, connection := range mm.connections {
+		connections = append(connections, connection.info())
+	}
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].Session.StartedAt.Before(connections[j].Session.StartedAt)
+	})
+	return connections
+}
+
+// Disconnect closes the connection.
+func (mm *MultiManager) Disconnect(connectionID string) error {
+	mm.lock.RLock()
+	connection, ok := mm.connections[connectionID]
+	mm.lock.RUnlock()
+	if !ok {
+		return ErrNoConnection
+	}
+
+	return connection.manager.Disconnect()
+}
+
+// DisconnectAll closes all additional connections.
+func (mm *MultiManager) DisconnectAll() {
+	mm.lock.RLock()
+	managers := make([]Manager, 0, len(mm.connections))
+	for _, connection := range mm.connections {
+		managers = append(managers, connection.manager)
+	}
+	mm.lock.RUnlock()
+
+	for _, manager := range managers {
+		logDisconnectError(manager.Disconnect())
+	}
+}
+
+func (mm *MultiManager) remove(connectionID string) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+
+	delete(mm.connections, connectionID)
+}
+
+func (mm *MultiManager) consumeStateEvent(e connectionstate.AppEventConnectionState) {
+	if e.State != connectionstate.NotConnected || e.SessionInfo.ConnectionID == "" {
+		return
+	}
+
+	log.Debug().Msgf("Additional connection %s closed", e.SessionInfo.ConnectionID)
+	mm.remove(e.SessionInfo.ConnectionID)
+}
+
+func (mm *MultiManager) consumeStatisticsEvent(e connectionstate.AppEventConnectionStatistics) {
+	mm.update(e.SessionInfo.ConnectionID, func(connection *additionalConnection) {
+		connection.statistics = e.Stats
+	})
+}
+
+func (mm *MultiManager) consumeThroughputEvent(e bandwidth.AppEventConnectionThroughput) {
+	mm.update(e.SessionInfo.ConnectionID, func(connection *additionalConnection) {
+		connection.throughput = e.Throughput
+	})
+}
+
+func (mm *MultiManager) consumeInvoiceEvent(e pingpongEvent.AppEventInvoicePaid) {
+	// Statuses are taken outside of the lock, managers lock their own state.
+	mm.lock.RLock()
+	managers := make(map[string]Manager, len(mm.connections))
+	for connectionID, connection := range mm.connections {
+		managers[connectionID] = connection.manager
+	}
+	mm.lock.RUnlock()
+
+	for connectionID, manager := range managers {
+		if manager.Status().SessionID == session.ID(e.SessionID) {
+			mm.update(connectionID, func(connection *additionalConnection) {
+				connection.invoice = e.Invoice
+			})
+			return
+		}
+	}
+}
+
+func (mm *MultiManager) update(connectionID string, delta func(connection *additionalConnection)) {
+	if connectionID == "" {
+		return
+	}
+
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+
+	if connection, ok := mm.connections[connectionID]; ok {
+		delta(connection)
+	}
+}
+
+func (c *additionalConnection) info() AdditionalConnection {
+	return AdditionalConnection{
+		Session:    c.manager.Status(),
+		Statistics: c.statistics,
+		Throughput: c.throughput,
+		Invoice:    c.invoice,
+	}
+}
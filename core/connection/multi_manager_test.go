@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeManager struct {
+	bus        eventbus.Publisher
+	connectErr error
+
+	lock   sync.Mutex
+	status connectionstate.Status
+}
+
+func (fm *fakeManager) Connect(consumerID identity.Identity, _ common.Address, proposal market.ServiceProposal, params ConnectParams) error {
+	fm.lock.Lock()
+	defer fm.lock.Unlock()
+
+	if fm.connectErr != nil {
+		return fm.connectErr
+	}
+	fm.status = connectionstate.Status{
+		ConnectionID: params.ConnectionID,
+		State:        connectionstate.Connected,
+		SessionID:    session.ID(params.ConnectionID + "-session"),
+		ConsumerID:   consumerID,
+		Proposal:     proposal,
+		StartedAt:    time.Now(),
+	}
+	return nil
+}
+
+func (fm *fakeManager) Status() connectionstate.Status {
+	fm.lock.Lock()
+	defer fm.lock.Unlock()
+
+	return fm.status
+}
+
+func (fm *fakeManager) Disconnect() error {
+	fm.lock.Lock()
+	fm.status.State = connectionstate.NotConnected
+	status := fm.status
+	fm.lock.Unlock()
+
+	fm.bus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
+		State:       status.State,
+		SessionInfo: status,
+	})
+	return nil
+}
+
+func (fm *fakeManager) CheckChannel(context.Context) error {
+	return nil
+}
+
+func (fm *fakeManager) Reconnect() {}
+
+var additionalParams = ConnectParams{
+	DisableKillSwitch: true,
+	SplitTunnel:       SplitTunnel{Include: []string{"10.0.0.0/8"}},
+}
+
+func TestMultiManager_KeepsConnectionsApart(t *testing.T) {
+	// given
+	bus := eventbus.New()
+	mm := NewMultiManager(func() Manager { return &fakeManager{bus: bus} })
+	assert.NoError(t, mm.Subscribe(bus))
+
+	// when
+	first, err := mm.Connect(identity.FromAddress("0x1"), common.Address{}, market.ServiceProposal{ProviderID: "0x2"}, additionalParams)
+	assert.NoError(t, err)
+	second, err := mm.Connect(identity.FromAddress("0x1"), common.Address{}, market.ServiceProposal{ProviderID: "0x3"}, additionalParams)
+	assert.NoError(t, err)
+
+	firstConnection, err := mm.Connection(first)
+	assert.NoError(t, err)
+	bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+		Stats:       connectionstate.Statistics{BytesReceived: 10},
+		SessionInfo: firstConnection.Session,
+	})
+	// statistics of the default connection are not tracked
+	bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+		Stats: connectionstate.Statistics{BytesReceived: 20},
+	})
+
+	// then
+	assert.NotEqual(t, first, second)
+	assert.Len(t, mm.Connections(), 2)
+
+	firstConnection, err = mm.Connection(first)
+	assert.NoError(t, err)
+	assert.Equal(t, first, firstConnection.Session.ConnectionID)
+	assert.Equal(t, "0x2", firstConnection.Session.Proposal.ProviderID)
+	assert.Equal(t, uint64(10), firstConnection.Statistics.BytesReceived)
+
+	secondConnection, err := mm.Connection(second)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x3", secondConnection.Session.Proposal.ProviderID)
+	assert.Zero(t, secondConnection.Statistics.BytesReceived)
+
+	// when
+	assert.NoError(t, mm.Disconnect(first))
+
+	// then
+	_, err = mm.Connection(first)
+	assert.Equal(t, ErrNoConnection, err)
+	assert.Len(t, mm.Connections(), 1)
+	assert.Equal(t, ErrNoConnection, mm.Disconnect(first))
+}
+
+func TestMultiManager_ForgetsFailedConnection(t *testing.T) {
+	// given
+	bus := eventbus.New()
+	connectErr := errors.New("boom")
+	mm := NewMultiManager(func() Manager { return &fakeManager{bus: bus, connectErr: connectErr} })
+	assert.NoError(t, mm.Subscribe(bus))
+
+	// when
+	id, err := mm.Connect(identity.FromAddress("0x1"), common.Address{}, market.ServiceProposal{}, additionalParams)
+
+	// then
+	assert.Equal(t, connectErr, err)
+	assert.Empty(t, id)
+	assert.Empty(t, mm.Connections())
+}
+
+func TestMultiManager_RequiresIncludeOnlyRoutes(t *testing.T) {
+	// given
+	bus := eventbus.New()
+	mm := NewMultiManager(func() Manager { return &fakeManager{bus: bus} })
+	consumerID := identity.FromAddress("0x1")
+
+	// when
+	_, allTrafficErr := mm.Connect(consumerID, common.Address{}, market.ServiceProposal{}, ConnectParams{DisableKillSwitch: true})
+	_, excludeErr := mm.Connect(consumerID, common.Address{}, market.ServiceProposal{}, ConnectParams{
+		DisableKillSwitch: true,
+		SplitTunnel:       SplitTunnel{Include: []string{"10.0.0.0/8"}, Exclude: []string{"10.1.0.0/16"}},
+	})
+	_, killSwitchErr := mm.Connect(consumerID, common.Address{}, market.ServiceProposal{}, ConnectParams{
+		SplitTunnel: SplitTunnel{Include: []string{"10.0.0.0/8"}},
+	})
+
+	// then
+	assert.Equal(t, ErrAdditionalConnectionRoutes, allTrafficErr)
+	assert.Equal(t, ErrAdditionalConnectionRoutes, excludeErr)
+	assert.Equal(t, ErrSplitTunnelKillSwitch, killSwitchErr)
+	assert.Empty(t, mm.Connections())
+}
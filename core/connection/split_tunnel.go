@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrSplitTunnelKillSwitch indicates that kill switch would block traffic which is routed outside the tunnel.
+var ErrSplitTunnelKillSwitch = errors.New("kill switch must be disabled when only included destinations are routed through the tunnel")
+
+// HostResolver resolves IPv4 addresses of the domain name.
+type HostResolver func(host string) ([]net.IP, error)
+
+// SplitTunnel defines which traffic is routed through the tunnel.
+// Destinations are given as CIDRs, single IPs or domain names.
+// Domain names are resolved once when connecting (see Resolve) and are not tracked afterwards.
+// When Include list is empty, all traffic except Exclude destinations is routed through the tunnel,
+// otherwise only Include destinations are routed through the tunnel.
+type SplitTunnel struct {
+	Include []string
+	Exclude []string
+}
+
+// Routes are split tunnel destinations resolved to subnets.
+type Routes struct {
+	Include []net.IPNet
+	Exclude []net.IPNet
+}
+
+// Enabled checks if any of the split tunnel lists are given.
+func (st SplitTunnel) Enabled() bool {
+	return len(st.Include) > 0 || len(st.Exclude) > 0
+}
+
+// Validate checks if all split tunnel destinations are valid.
+func (st SplitTunnel) Validate() error {
+	for _, destination := range append(append([]string{}, st.Include...), st.Exclude...) {
+		if _, ok := parseSubnet(destination); ok {
+			continue
+		}
+		if !isDomainName(destination) {
+			return fmt.Errorf("invalid split tunnel destination %q: expected IPv4 CIDR, IPv4 address or domain name", destination)
+		}
+	}
+	return nil
+}
+
+// Resolve resolves domain names of split tunnel destinations and returns them as subnets.
+// Domains are resolved once, so the routes follow addresses the domain had at the time of connecting:
+// they are not refreshed when DNS answers change during the connection, not even on failover.
+func (st SplitTunnel) Resolve(resolveHost HostResolver) (Routes, error) {
+	var routes Routes
+	var err error
+	if routes.Include, err = resolveSubnets(st.Include, resolveHost); err != nil {
+		return Routes{}, err
+	}
+	if routes.Exclude, err = resolveSubnets(st.Exclude, resolveHost); err != nil {
+		return Routes{}, err
+	}
+	return routes, nil
+}
+
+func resolveSubnets(destinations []string, resolveHost HostResolver) ([]net.IPNet, error) {
+	var subnets []net.IPNet
+	for _, destination := range destinations {
+		if subnet, ok := parseSubnet(destination); ok {
+			subnets = append(subnets, subnet)
+			continue
+		}
+		if !isDomainName(destination) {
+			return nil, fmt.Errorf("invalid split tunnel destination %q", destination)
+		}
+
+		ips, err := resolveHost(destination)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not resolve split tunnel destination %q", destination)
+		}
+		for _, ip := range ips {
+			if ip = ip.To4(); ip != nil {
+				subnets = append(subnets, net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)})
+			}
+		}
+	}
+	return subnets, nil
+}
+
+func parseSubnet(destination string) (net.IPNet, bool) {
+	if ip := net.ParseIP(destination).To4(); ip != nil {
+		return net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)}, true
+	}
+	if _, subnet, err := net.ParseCIDR(destination); err == nil && subnet.IP.To4() != nil {
+		return *subnet, true
+	}
+	return net.IPNet{}, false
+}
+
+func isDomainName(destination string) bool {
+	name := strings.TrimSuffix(destination, ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+
+	labels := strings.Split(name, ".")
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+
+	// Top level domain is never numeric, so malformed IPs are not taken for domains.
+	tld := labels[len(labels)-1]
+	return strings.Trim(tld, "0123456789") != ""
+}
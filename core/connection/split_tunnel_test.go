@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTunnel_Validate(t *testing.T) {
+	tests := []struct {
+		destination string
+		expectErr   bool
+	}{
+		{destination: "10.0.0.0/8"},
+		{destination: "1.2.3.4"},
+		{destination: "example.com"},
+		{destination: "sub-domain.example.com."},
+		{destination: "localhost"},
+		{destination: "::1", expectErr: true},
+		{destination: "2001:db8::/32", expectErr: true},
+		{destination: "1.2.3", expectErr: true},
+		{destination: "512.512.512.512", expectErr: true},
+		{destination: "-example.com", expectErr: true},
+		{destination: "example..com", expectErr: true},
+		{destination: "http://example.com", expectErr: true},
+		{destination: "", expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.destination, func(t *testing.T) {
+			err := SplitTunnel{Exclude: []string{test.destination}}.Validate()
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSplitTunnel_Resolve(t *testing.T) {
+	resolveHost := func(host string) ([]net.IP, error) {
+		if host != "example.com" {
+			return nil, errors.New("NXDOMAIN")
+		}
+		return []net.IP{net.ParseIP("93.184.216.34"), net.ParseIP("2606:2800:220:1::")}, nil
+	}
+
+	routes, err := SplitTunnel{
+		Include: []string{"10.0.0.0/8", "example.com"},
+		Exclude: []string{"10.1.2.3"},
+	}.Resolve(resolveHost)
+	assert.NoError(t, err)
+	assert.Equal(t, Routes{
+		Include: []net.IPNet{
+			{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+			{IP: net.IP{93, 184, 216, 34}, Mask: net.CIDRMask(32, 32)},
+		},
+		Exclude: []net.IPNet{
+			{IP: net.IP{10, 1, 2, 3}, Mask: net.CIDRMask(32, 32)},
+		},
+	}, routes)
+
+	_, err = SplitTunnel{Exclude: []string{"unknown.com"}}.Resolve(resolveHost)
+	assert.EqualError(t, err, `could not resolve split tunnel destination "unknown.com": NXDOMAIN`)
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+// tracedChannel publishes the topics of the p2p messages exchanged with the provider as connection trace records.
+type tracedChannel struct {
+	p2p.Channel
+
+	connectionID string
+	publisher    eventbus.Publisher
+}
+
+func newTracedChannel(channel p2p.Channel, connectionID string, publisher eventbus.Publisher) *tracedChannel {
+	return &tracedChannel{
+		Channel:      channel,
+		connectionID: connectionID,
+		publisher:    publisher,
+	}
+}
+
+// Send sends message to given topic and records the topic, the round trip duration and the error if any.
+func (c *tracedChannel) Send(ctx context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
+	start := time.Now()
+	reply, err := c.Channel.Send(ctx, topic, msg)
+
+	details := "sent"
+	if err != nil {
+		details = "sent, failed: " + err.Error()
+	}
+	c.publish(start, topic, details, time.Since(start))
+	return reply, err
+}
+
+// Handle registers handler for given topic which records every received message.
+func (c *tracedChannel) Handle(topic string, handler p2p.HandlerFunc) {
+	c.Channel.Handle(topic, func(ctx p2p.Context) error {
+		start := time.Now()
+		err := handler(ctx)
+
+		details := "received"
+		if err != nil {
+			details = "received, handling failed: " + err.Error()
+		}
+		c.publish(start, topic, details, time.Since(start))
+		return err
+	})
+}
+
+func (c *tracedChannel) publish(at time.Time, topic, details string, duration time.Duration) {
+	c.publisher.Publish(connectionstate.AppTopicConnectionTrace, connectionstate.AppEventConnectionTrace{
+		ConnectionID: c.connectionID,
+		Time:         at,
+		Kind:         connectionstate.TraceP2P,
+		Name:         topic,
+		Details:      details,
+		Duration:     duration,
+	})
+}
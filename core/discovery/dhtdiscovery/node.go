@@ -22,8 +22,12 @@ import (
 	"fmt"
 
 	"github.com/libp2p/go-libp2p"
+	libp2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/peer"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	dhtopts "github.com/libp2p/go-libp2p-kad-dht/opts"
+	routedhost "github.com/libp2p/go-libp2p/p2p/host/routed"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/rs/zerolog/log"
 )
@@ -34,6 +38,7 @@ type Node struct {
 	libP2PNode       host.Host
 	libP2PNodeCtx    context.Context
 	libP2PNodeCancel context.CancelFunc
+	libP2PDHT        *dht.IpfsDHT
 
 	bootstrapPeers []*peer.AddrInfo
 }
@@ -83,6 +88,16 @@ func (n *Node) Start() (err error) {
 		return fmt.Errorf("failed to start DHT node: %w", err)
 	}
 
+	// Start Kademlia DHT, which stores signed proposal records of the network.
+	n.libP2PDHT, err = dht.New(
+		n.libP2PNodeCtx,
+		n.libP2PNode,
+		dhtopts.NamespacedValidator(proposalNamespace, &proposalValidator{}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start DHT routing: %w", err)
+	}
+
 	log.Info().Msgf("DHT node started on %s with ID=%s", n.libP2PNode.Addrs(), n.libP2PNode.ID())
 
 	// Start connecting to the bootstrap peer nodes early. They will tell us about the other nodes in the network.
@@ -95,9 +110,47 @@ func (n *Node) Start() (err error) {
 
 // Stop stops DHT node.
 func (n *Node) Stop() {
+	if n.libP2PDHT != nil {
+		if err := n.libP2PDHT.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close DHT routing")
+		}
+	}
 	n.libP2PNodeCancel()
 }
 
+// routing returns DHT routing of the running node.
+func (n *Node) routing() (*dht.IpfsDHT, error) {
+	if n.libP2PDHT == nil {
+		return nil, errNodeNotStarted
+	}
+
+	return n.libP2PDHT, nil
+}
+
+// Host returns libp2p host of the running node, which finds addresses of unknown peers in the DHT.
+func (n *Node) Host() (host.Host, error) {
+	routing, err := n.routing()
+	if err != nil {
+		return nil, err
+	}
+
+	return routedhost.Wrap(n.libP2PNode, routing), nil
+}
+
+// peerKey returns the private key of the running node, which signs the published records.
+func (n *Node) peerKey() (libp2pcrypto.PrivKey, error) {
+	if n.libP2PNode == nil {
+		return nil, errNodeNotStarted
+	}
+
+	key := n.libP2PNode.Peerstore().PrivKey(n.libP2PNode.ID())
+	if key == nil {
+		return nil, fmt.Errorf("no private key of DHT peer %s", n.libP2PNode.ID())
+	}
+
+	return key, nil
+}
+
 func (n *Node) connectToPeer(peerInfo peer.AddrInfo) {
 	if err := n.libP2PNode.Connect(n.libP2PNodeCtx, peerInfo); err != nil {
 		log.Warn().Err(err).Msgf("Failed to contact DHT peer %s", peerInfo.ID)
@@ -0,0 +1,334 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	libp2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/multiformats/go-multihash"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// proposalNamespace is a DHT key namespace under which proposal records are stored.
+const proposalNamespace = "myst-proposals"
+
+// proposalRendezvous is a content key which is provided by every node publishing proposals.
+const proposalRendezvous = "mysterium/proposals/v1"
+
+// maxProposalTTL limits how long published proposals stay valid, so a record can not outlive the fresher ones.
+const maxProposalTTL = time.Hour
+
+var (
+	errNodeNotStarted     = errors.New("DHT node is not started")
+	errRecordEmpty        = errors.New("proposal record is empty")
+	errRecordExpired      = errors.New("proposal record is expired")
+	errRecordNoValidity   = errors.New("no valid proposal records")
+	errRecordTTLTooLong   = errors.New("proposal record expires too late")
+	errRecordPeerMismatch = errors.New("proposal record is not published by the peer of its key")
+)
+
+// proposalRecordKey returns DHT key of the proposals published by given peer.
+func proposalRecordKey(peerID peer.ID) string {
+	return fmt.Sprintf("/%s/%s", proposalNamespace, peer.IDB58Encode(peerID))
+}
+
+// proposalRecordPeer returns the peer whose proposals are stored under given DHT key.
+func proposalRecordPeer(key string) (peer.ID, error) {
+	prefix := fmt.Sprintf("/%s/", proposalNamespace)
+	if !strings.HasPrefix(key, prefix) {
+		return "", fmt.Errorf("invalid proposal record key %q", key)
+	}
+
+	return peer.IDB58Decode(strings.TrimPrefix(key, prefix))
+}
+
+// proposalRendezvousCID returns content key which peers publishing proposals are providing.
+func proposalRendezvousCID() (cid.Cid, error) {
+	hash, err := multihash.Sum([]byte(proposalRendezvous), multihash.SHA2_256, -1)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return cid.NewCidV1(cid.Raw, hash), nil
+}
+
+// proposalPayload is a part of proposal record, which is signed by the provider.
+type proposalPayload struct {
+	Proposal  market.ServiceProposal `json:"proposal"`
+	ExpiresAt time.Time              `json:"expires_at"`
+}
+
+// signedProposal is a single proposal of the provider with it's signature and expiration time.
+type signedProposal struct {
+	Payload   []byte `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// newSignedProposal signs given proposal, which stays valid until given time.
+func newSignedProposal(proposal market.ServiceProposal, expiresAt time.Time, signer identity.Signer) (signedProposal, error) {
+	payload, err := json.Marshal(proposalPayload{Proposal: proposal, ExpiresAt: expiresAt.UTC()})
+	if err != nil {
+		return signedProposal{}, fmt.Errorf("failed to serialize proposal: %w", err)
+	}
+
+	signature, err := signer.Sign(payload)
+	if err != nil {
+		return signedProposal{}, fmt.Errorf("failed to sign proposal: %w", err)
+	}
+
+	return signedProposal{Payload: payload, Signature: signature.Base64()}, nil
+}
+
+// open verifies the signature of provider and returns the signed payload.
+func (sp signedProposal) open(now time.Time) (proposalPayload, error) {
+	var payload proposalPayload
+	if err := json.Unmarshal(sp.Payload, &payload); err != nil {
+		return payload, fmt.Errorf("failed to parse proposal: %w", err)
+	}
+
+	if now.After(payload.ExpiresAt) {
+		return payload, errRecordExpired
+	}
+	if payload.ExpiresAt.After(now.Add(maxProposalTTL)) {
+		return payload, errRecordTTLTooLong
+	}
+
+	verifier := identity.NewVerifierIdentity(identity.FromAddress(payload.Proposal.ProviderID))
+	if !verifier.Verify(sp.Payload, identity.SignatureBase64(sp.Signature)) {
+		return payload, fmt.Errorf("invalid signature of proposal %v", payload.Proposal.UniqueID())
+	}
+
+	return payload, nil
+}
+
+// proposalRecord is a DHT value holding all proposals published by a single peer.
+type proposalRecord struct {
+	Proposals []signedProposal `json:"proposals"`
+	// PeerKey is a public key of the publishing peer, which ID is a part of the record key.
+	PeerKey []byte `json:"peer_key"`
+	// PeerSignature binds the proposals to the record key, so they can not be put under the key of another peer.
+	PeerSignature []byte `json:"peer_signature"`
+	// WithdrawnUntil is set on the record without proposals, which replaces the proposals of a stopped provider.
+	// It is valid until the replaced proposals would expire.
+	WithdrawnUntil *time.Time `json:"withdrawn_until,omitempty"`
+}
+
+// newProposalRecord creates a record of given proposals, signed by the peer publishing it under given key.
+func newProposalRecord(key string, proposals []signedProposal, peerKey libp2pcrypto.PrivKey) (proposalRecord, error) {
+	return proposalRecord{Proposals: proposals}.sign(key, peerKey)
+}
+
+// newWithdrawalRecord creates a record without proposals, which replaces all proposals of the peer until given time.
+func newWithdrawalRecord(key string, withdrawnUntil time.Time, peerKey libp2pcrypto.PrivKey) (proposalRecord, error) {
+	withdrawnUntil = withdrawnUntil.UTC()
+	return proposalRecord{Proposals: []signedProposal{}, WithdrawnUntil: &withdrawnUntil}.sign(key, peerKey)
+}
+
+// sign signs the record by the peer publishing it under given key.
+func (pr proposalRecord) sign(key string, peerKey libp2pcrypto.PrivKey) (proposalRecord, error) {
+	record := pr
+
+	publicKey, err := libp2pcrypto.MarshalPublicKey(peerKey.GetPublic())
+	if err != nil {
+		return record, fmt.Errorf("failed to serialize peer key: %w", err)
+	}
+
+	message, err := record.peerMessage(key)
+	if err != nil {
+		return record, err
+	}
+
+	signature, err := peerKey.Sign(message)
+	if err != nil {
+		return record, fmt.Errorf("failed to sign proposal record: %w", err)
+	}
+
+	record.PeerKey, record.PeerSignature = publicKey, signature
+	return record, nil
+}
+
+// peerMessage returns the message signed by the publishing peer.
+func (pr proposalRecord) peerMessage(key string) ([]byte, error) {
+	message, err := json.Marshal(struct {
+		Key            string           `json:"key"`
+		Proposals      []signedProposal `json:"proposals"`
+		WithdrawnUntil *time.Time       `json:"withdrawn_until,omitempty"`
+	}{Key: key, Proposals: pr.Proposals, WithdrawnUntil: pr.WithdrawnUntil})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize proposal record: %w", err)
+	}
+
+	return message, nil
+}
+
+// verifyPeer checks that the record is published by the peer of given key.
+func (pr proposalRecord) verifyPeer(key string) error {
+	peerID, err := proposalRecordPeer(key)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := libp2pcrypto.UnmarshalPublicKey(pr.PeerKey)
+	if err != nil {
+		return fmt.Errorf("failed to parse peer key: %w", err)
+	}
+	if !peerID.MatchesPublicKey(publicKey) {
+		return errRecordPeerMismatch
+	}
+
+	message, err := pr.peerMessage(key)
+	if err != nil {
+		return err
+	}
+
+	if ok, err := publicKey.Verify(message, pr.PeerSignature); err != nil || !ok {
+		return errRecordPeerMismatch
+	}
+
+	return nil
+}
+
+// validProposals returns the proposals of the record, which are properly signed and not yet expired.
+func (pr proposalRecord) validProposals(now time.Time) ([]proposalPayload, error) {
+	if len(pr.Proposals) == 0 {
+		return nil, errRecordEmpty
+	}
+
+	payloads := make([]proposalPayload, 0, len(pr.Proposals))
+	for _, sp := range pr.Proposals {
+		payload, err := sp.open(now)
+		if errors.Is(err, errRecordExpired) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		payloads = append(payloads, payload)
+	}
+
+	if len(payloads) == 0 {
+		return nil, errRecordNoValidity
+	}
+
+	return payloads, nil
+}
+
+// validWithdrawal checks that the record withdraws the proposals of the peer and is not yet expired.
+func (pr proposalRecord) validWithdrawal(now time.Time) error {
+	if pr.WithdrawnUntil == nil || len(pr.Proposals) > 0 {
+		return errRecordEmpty
+	}
+	if now.After(*pr.WithdrawnUntil) {
+		return errRecordExpired
+	}
+	if pr.WithdrawnUntil.After(now.Add(maxProposalTTL)) {
+		return errRecordTTLTooLong
+	}
+
+	return nil
+}
+
+// latestExpiry returns the latest expiration time of the proposals in the record or of its withdrawal.
+func (pr proposalRecord) latestExpiry(now time.Time) time.Time {
+	var latest time.Time
+	if pr.validWithdrawal(now) == nil {
+		latest = *pr.WithdrawnUntil
+	}
+
+	payloads, _ := pr.validProposals(now)
+	for _, payload := range payloads {
+		if payload.ExpiresAt.After(latest) {
+			latest = payload.ExpiresAt
+		}
+	}
+
+	return latest
+}
+
+func parseProposalRecord(value []byte) (proposalRecord, error) {
+	var record proposalRecord
+	if err := json.Unmarshal(value, &record); err != nil {
+		return record, fmt.Errorf("failed to parse proposal record: %w", err)
+	}
+
+	return record, nil
+}
+
+// proposalValidator checks proposal records before they are stored or accepted from the DHT.
+type proposalValidator struct {
+	now func() time.Time
+}
+
+func (pv *proposalValidator) currentTime() time.Time {
+	if pv.now != nil {
+		return pv.now()
+	}
+
+	return time.Now()
+}
+
+// Validate accepts only records of properly signed and non-expired proposals or their withdrawals,
+// published by the peer of the key.
+func (pv *proposalValidator) Validate(key string, value []byte) error {
+	record, err := parseProposalRecord(value)
+	if err != nil {
+		return err
+	}
+
+	if err := record.verifyPeer(key); err != nil {
+		return err
+	}
+
+	if record.WithdrawnUntil != nil {
+		return record.validWithdrawal(pv.currentTime())
+	}
+
+	_, err = record.validProposals(pv.currentTime())
+	return err
+}
+
+// Select chooses the freshest record, the one which expires the latest.
+func (pv *proposalValidator) Select(key string, values [][]byte) (int, error) {
+	if len(values) == 0 {
+		return 0, errRecordEmpty
+	}
+
+	now := pv.currentTime()
+	best, bestExpiry := 0, time.Time{}
+	for i, value := range values {
+		record, err := parseProposalRecord(value)
+		if err != nil || record.verifyPeer(key) != nil {
+			continue
+		}
+
+		if expiry := record.latestExpiry(now); expiry.After(bestExpiry) {
+			best, bestExpiry = i, expiry
+		}
+	}
+
+	return best, nil
+}
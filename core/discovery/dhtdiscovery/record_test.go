@@ -0,0 +1,185 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	libp2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	providerKey, _  = crypto.HexToECDSA("6f88637b68ee88816e73f663aef709d7009836c98ae91ef31e3dfac7be3a1657")
+	providerID      = crypto.PubkeyToAddress(providerKey.PublicKey).Hex()
+	providerSigner  = &keySigner{}
+	providerService = market.ServiceProposal{ProviderID: providerID, ServiceType: "mock"}
+)
+
+type keySigner struct{}
+
+func (ks *keySigner) Sign(message []byte) (identity.Signature, error) {
+	signature, err := crypto.Sign(crypto.Keccak256(message), providerKey)
+	return identity.SignatureBytes(signature), err
+}
+
+func newPeer(t *testing.T) (string, libp2pcrypto.PrivKey) {
+	privateKey, publicKey, err := libp2pcrypto.GenerateKeyPair(libp2pcrypto.Ed25519, -1)
+	assert.NoError(t, err)
+	peerID, err := peer.IDFromPublicKey(publicKey)
+	assert.NoError(t, err)
+	return proposalRecordKey(peerID), privateKey
+}
+
+func recordValue(t *testing.T, key string, peerKey libp2pcrypto.PrivKey, proposals ...signedProposal) []byte {
+	record, err := newProposalRecord(key, proposals, peerKey)
+	assert.NoError(t, err)
+	value, err := json.Marshal(record)
+	assert.NoError(t, err)
+	return value
+}
+
+func Test_ProposalValidator_Validate(t *testing.T) {
+	now := time.Now()
+	validator := &proposalValidator{now: func() time.Time { return now }}
+	key, peerKey := newPeer(t)
+
+	valid, err := newSignedProposal(providerService, now.Add(time.Minute), providerSigner)
+	assert.NoError(t, err)
+	assert.NoError(t, validator.Validate(key, recordValue(t, key, peerKey, valid)))
+
+	expired, err := newSignedProposal(providerService, now.Add(-time.Minute), providerSigner)
+	assert.NoError(t, err)
+	assert.Equal(t, errRecordNoValidity, validator.Validate(key, recordValue(t, key, peerKey, expired)))
+	assert.NoError(t, validator.Validate(key, recordValue(t, key, peerKey, expired, valid)))
+
+	forged, err := newSignedProposal(market.ServiceProposal{ProviderID: "0x1", ServiceType: "mock"}, now.Add(time.Minute), providerSigner)
+	assert.NoError(t, err)
+	assert.Error(t, validator.Validate(key, recordValue(t, key, peerKey, forged)))
+
+	fake, err := newSignedProposal(providerService, now.Add(time.Minute), &identity.SignerFake{})
+	assert.NoError(t, err)
+	assert.Error(t, validator.Validate(key, recordValue(t, key, peerKey, fake)))
+
+	assert.Equal(t, errRecordEmpty, validator.Validate(key, recordValue(t, key, peerKey)))
+	assert.Error(t, validator.Validate(key, []byte("{")))
+}
+
+func Test_ProposalValidator_ValidateRejectsFarExpiry(t *testing.T) {
+	now := time.Now()
+	validator := &proposalValidator{now: func() time.Time { return now }}
+	key, peerKey := newPeer(t)
+
+	longLived, err := newSignedProposal(providerService, now.Add(maxProposalTTL+time.Minute), providerSigner)
+	assert.NoError(t, err)
+	assert.Equal(t, errRecordTTLTooLong, validator.Validate(key, recordValue(t, key, peerKey, longLived)))
+}
+
+func Test_ProposalValidator_ValidateRejectsRecordOfAnotherPeer(t *testing.T) {
+	now := time.Now()
+	validator := &proposalValidator{now: func() time.Time { return now }}
+	victimKey, _ := newPeer(t)
+	attackerKey, attackerPeerKey := newPeer(t)
+
+	valid, err := newSignedProposal(providerService, now.Add(time.Minute), providerSigner)
+	assert.NoError(t, err)
+
+	// Record signed by the attacker for the victim's key.
+	assert.Equal(t, errRecordPeerMismatch, validator.Validate(victimKey, recordValue(t, victimKey, attackerPeerKey, valid)))
+	// Attacker's own record replayed under the victim's key.
+	assert.Equal(t, errRecordPeerMismatch, validator.Validate(victimKey, recordValue(t, attackerKey, attackerPeerKey, valid)))
+	assert.Error(t, validator.Validate("/myst-proposals/peer", recordValue(t, attackerKey, attackerPeerKey, valid)))
+}
+
+func Test_ProposalValidator_Select(t *testing.T) {
+	now := time.Now()
+	validator := &proposalValidator{now: func() time.Time { return now }}
+	key, peerKey := newPeer(t)
+	_, otherPeerKey := newPeer(t)
+
+	older, err := newSignedProposal(providerService, now.Add(time.Minute), providerSigner)
+	assert.NoError(t, err)
+	newer, err := newSignedProposal(providerService, now.Add(time.Hour), providerSigner)
+	assert.NoError(t, err)
+	foreign, err := newSignedProposal(providerService, now.Add(time.Hour), providerSigner)
+	assert.NoError(t, err)
+
+	index, err := validator.Select(key, [][]byte{
+		recordValue(t, key, peerKey, older),
+		[]byte("{"),
+		recordValue(t, key, otherPeerKey, foreign),
+		recordValue(t, key, peerKey, newer),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, index)
+
+	_, err = validator.Select(key, [][]byte{})
+	assert.Equal(t, errRecordEmpty, err)
+}
+
+func Test_ProposalValidator_ValidateWithdrawal(t *testing.T) {
+	now := time.Now()
+	validator := &proposalValidator{now: func() time.Time { return now }}
+	key, peerKey := newPeer(t)
+	_, otherPeerKey := newPeer(t)
+
+	assert.NoError(t, validator.Validate(key, withdrawalValue(t, key, peerKey, now.Add(time.Minute))))
+	assert.Equal(t, errRecordExpired, validator.Validate(key, withdrawalValue(t, key, peerKey, now.Add(-time.Minute))))
+	assert.Equal(t, errRecordTTLTooLong, validator.Validate(key, withdrawalValue(t, key, peerKey, now.Add(maxProposalTTL+time.Minute))))
+	assert.Equal(t, errRecordPeerMismatch, validator.Validate(key, withdrawalValue(t, key, otherPeerKey, now.Add(time.Minute))))
+}
+
+func Test_ProposalValidator_SelectWithdrawal(t *testing.T) {
+	now := time.Now()
+	validator := &proposalValidator{now: func() time.Time { return now }}
+	key, peerKey := newPeer(t)
+
+	published, err := newSignedProposal(providerService, now.Add(time.Minute), providerSigner)
+	assert.NoError(t, err)
+
+	index, err := validator.Select(key, [][]byte{
+		recordValue(t, key, peerKey, published),
+		withdrawalValue(t, key, peerKey, now.Add(2*time.Minute)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	republished, err := newSignedProposal(providerService, now.Add(3*time.Minute), providerSigner)
+	assert.NoError(t, err)
+
+	index, err = validator.Select(key, [][]byte{
+		withdrawalValue(t, key, peerKey, now.Add(2*time.Minute)),
+		recordValue(t, key, peerKey, republished),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+}
+
+func withdrawalValue(t *testing.T, key string, peerKey libp2pcrypto.PrivKey, withdrawnUntil time.Time) []byte {
+	record, err := newWithdrawalRecord(key, withdrawnUntil, peerKey)
+	assert.NoError(t, err)
+	value, err := json.Marshal(record)
+	assert.NoError(t, err)
+	return value
+}
@@ -18,29 +18,151 @@
 package dhtdiscovery
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
 )
 
+const publishTimeout = 30 * time.Second
+
 type registryDHT struct {
+	node *Node
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	proposals map[market.ProposalID]registeredProposal
 }
 
-// NewRegistry create an instance of Broker registryBroker.
-func NewRegistry() *registryDHT {
-	return &registryDHT{}
+type registeredProposal struct {
+	proposal market.ServiceProposal
+	signer   identity.Signer
+}
+
+// NewRegistry create an instance of DHT registryDHT.
+// Published proposals stay valid in the DHT for the given ttl, unless they are pinged.
+func NewRegistry(node *Node, ttl time.Duration) *registryDHT {
+	if ttl > maxProposalTTL {
+		ttl = maxProposalTTL
+	}
+
+	return &registryDHT{
+		node:      node,
+		ttl:       ttl,
+		proposals: make(map[market.ProposalID]registeredProposal),
+	}
 }
 
 // RegisterProposal registers service proposal to discovery service.
 func (rd *registryDHT) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	return rd.storeProposal(proposal, signer)
 }
 
 // UnregisterProposal unregisters a service proposal when client disconnects.
 func (rd *registryDHT) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	rd.mu.Lock()
+	delete(rd.proposals, proposal.UniqueID())
+	key, value, err := rd.signRecord()
+	rd.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return rd.publish(key, value)
 }
 
 // PingProposal pings service proposal as being alive.
 func (rd *registryDHT) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return rd.storeProposal(proposal, signer)
+}
+
+func (rd *registryDHT) storeProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	rd.mu.Lock()
+	rd.proposals[proposal.UniqueID()] = registeredProposal{proposal: proposal, signer: signer}
+	key, value, err := rd.signRecord()
+	rd.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return rd.publish(key, value)
+}
+
+// signRecord signs all proposals of this node with a fresh expiration time and returns the DHT record of them.
+// Without proposals, the record withdraws the previously published ones until they would expire.
+// Should be called with the lock held, so records signed later expire later.
+func (rd *registryDHT) signRecord() (string, []byte, error) {
+	routing, err := rd.node.routing()
+	if err != nil {
+		return "", nil, err
+	}
+
+	peerKey, err := rd.node.peerKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	expiresAt := time.Now().Add(rd.ttl)
+	proposals := make([]signedProposal, 0, len(rd.proposals))
+	for _, rp := range rd.proposals {
+		sp, err := newSignedProposal(rp.proposal, expiresAt, rp.signer)
+		if err != nil {
+			return "", nil, err
+		}
+
+		proposals = append(proposals, sp)
+	}
+
+	key := proposalRecordKey(routing.PeerID())
+	var record proposalRecord
+	if len(proposals) == 0 {
+		record, err = newWithdrawalRecord(key, expiresAt, peerKey)
+	} else {
+		record, err = newProposalRecord(key, proposals, peerKey)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to serialize proposal record: %w", err)
+	}
+
+	return key, value, nil
+}
+
+// publish puts the signed record into the DHT. It is called without the lock, as the network calls take long.
+func (rd *registryDHT) publish(key string, value []byte) error {
+	routing, err := rd.node.routing()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	rendezvous, err := proposalRendezvousCID()
+	if err != nil {
+		return err
+	}
+
+	// Record is always stored locally first. Network distribution is best effort,
+	// it's retried with the next ping when DHT peers become reachable.
+	// Records published concurrently are ordered by DHT itself, the one expiring later is kept.
+	if err := routing.PutValue(ctx, key, value); err != nil {
+		log.Warn().Err(err).Msg("Failed to distribute proposals in DHT")
+		return nil
+	}
+
+	if err := routing.Provide(ctx, rendezvous, true); err != nil {
+		log.Warn().Err(err).Msg("Failed to announce proposals in DHT")
+	}
+
 	return nil
 }
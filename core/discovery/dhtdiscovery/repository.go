@@ -18,37 +18,82 @@
 package dhtdiscovery
 
 import (
+	"context"
+	"fmt"
 	"sync"
+	"time"
 
+	"github.com/libp2p/go-libp2p-core/peer"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	lookupTimeout = 30 * time.Second
+	lookupLimit   = 1000
 )
 
 // Repository provides proposals from the DHT.
 type Repository struct {
+	node          *Node
+	fetchInterval time.Duration
+
 	stopOnce sync.Once
 	stopChan chan struct{}
+
+	mu        sync.RWMutex
+	proposals map[market.ProposalID]proposalPayload
 }
 
 // NewRepository constructs a new proposal repository (backed by the DHT).
-func NewRepository() *Repository {
+func NewRepository(node *Node, fetchInterval time.Duration) *Repository {
 	return &Repository{
-		stopChan: make(chan struct{}),
+		node:          node,
+		fetchInterval: fetchInterval,
+		stopChan:      make(chan struct{}),
+		proposals:     make(map[market.ProposalID]proposalPayload),
 	}
 }
 
 // Proposal returns a single proposal by its ID.
 func (r *Repository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
-	return nil, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payload, ok := r.proposals[id]
+	if !ok || time.Now().After(payload.ExpiresAt) {
+		return nil, fmt.Errorf("proposal does not exist: %v", id)
+	}
+
+	return &payload.Proposal, nil
 }
 
 // Proposals returns proposals matching the filter.
 func (r *Repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
-	return []market.ServiceProposal{}, nil
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	proposals := make([]market.ServiceProposal, 0)
+	for _, payload := range r.proposals {
+		if now.After(payload.ExpiresAt) {
+			continue
+		}
+
+		if filter == nil || filter.Matches(payload.Proposal) {
+			proposals = append(proposals, payload.Proposal)
+		}
+	}
+
+	return proposals, nil
 }
 
 // Start begins proposals synchronization to storage.
 func (r *Repository) Start() error {
+	go r.fetchLoop()
+
 	return nil
 }
 
@@ -58,3 +103,81 @@ func (r *Repository) Stop() {
 		close(r.stopChan)
 	})
 }
+
+func (r *Repository) fetchLoop() {
+	for {
+		if err := r.fetch(); err != nil {
+			log.Warn().Err(err).Msg("Failed to fetch proposals from DHT")
+		}
+
+		select {
+		case <-r.stopChan:
+			return
+		case <-time.After(r.fetchInterval):
+		}
+	}
+}
+
+// fetch looks up all peers publishing proposals and replaces storage with their valid records.
+func (r *Repository) fetch() error {
+	routing, err := r.node.routing()
+	if err != nil {
+		return err
+	}
+
+	rendezvous, err := proposalRendezvousCID()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+
+	peers := []peer.ID{routing.PeerID()}
+	for peerInfo := range routing.FindProvidersAsync(ctx, rendezvous, lookupLimit) {
+		if peerInfo.ID != routing.PeerID() {
+			peers = append(peers, peerInfo.ID)
+		}
+	}
+
+	now := time.Now()
+	proposals := make(map[market.ProposalID]proposalPayload)
+	for _, peerID := range peers {
+		value, err := routing.GetValue(ctx, proposalRecordKey(peerID), dht.Quorum(1))
+		if err != nil {
+			log.Debug().Err(err).Msgf("Failed to get proposals of DHT peer %s", peerID)
+			continue
+		}
+
+		record, err := parseProposalRecord(value)
+		if err != nil {
+			continue
+		}
+
+		if record.WithdrawnUntil != nil {
+			// Provider has stopped and withdrawn its proposals.
+			continue
+		}
+
+		payloads, err := record.validProposals(now)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Skipping invalid proposals of DHT peer %s", peerID)
+			continue
+		}
+
+		for _, payload := range payloads {
+			if !payload.Proposal.IsSupported() {
+				continue
+			}
+
+			proposals[payload.Proposal.UniqueID()] = payload
+		}
+	}
+
+	r.mu.Lock()
+	r.proposals = proposals
+	r.mu.Unlock()
+
+	log.Debug().Msgf("Fetched %d proposals from %d DHT peers", len(proposals), len(peers))
+	return nil
+}
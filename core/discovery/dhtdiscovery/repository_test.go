@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dhtdiscovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	market.RegisterServiceDefinitionUnserializer(
+		"mock_service",
+		func(rawDefinition *json.RawMessage) (market.ServiceDefinition, error) {
+			return mockServiceDefinition{}, nil
+		},
+	)
+	market.RegisterPaymentMethodUnserializer(
+		"mock_payment",
+		func(rawDefinition *json.RawMessage) (market.PaymentMethod, error) {
+			return mockPaymentMethod{}, nil
+		},
+	)
+	market.RegisterContactUnserializer("mock_contact",
+		func(rawMessage *json.RawMessage) (market.ContactDefinition, error) {
+			return mockContact{}, nil
+		},
+	)
+}
+
+var supportedProposal = market.ServiceProposal{
+	ProviderID:        providerID,
+	ServiceType:       "mock_service",
+	ServiceDefinition: mockServiceDefinition{},
+	PaymentMethodType: "mock_payment",
+	PaymentMethod:     mockPaymentMethod{},
+	ProviderContacts:  []market.Contact{{Type: "mock_contact", Definition: mockContact{}}},
+}
+
+type mockServiceDefinition struct{}
+
+func (msd mockServiceDefinition) GetLocation() market.Location {
+	return market.Location{}
+}
+
+type mockPaymentMethod struct{}
+
+func (method mockPaymentMethod) GetPrice() money.Money {
+	return money.Money{}
+}
+
+func (method mockPaymentMethod) GetType() string {
+	return "mock_payment"
+}
+
+func (method mockPaymentMethod) GetRate() market.PaymentRate {
+	return market.PaymentRate{PerTime: time.Minute}
+}
+
+type mockContact struct{}
+
+func startTestNode(t *testing.T, bootstrapPeers ...string) *Node {
+	node, err := NewNode("/ip4/127.0.0.1/tcp/0", bootstrapPeers)
+	assert.NoError(t, err)
+	assert.NoError(t, node.Start())
+
+	return node
+}
+
+func nodeAddress(node *Node) string {
+	return fmt.Sprintf("%s/p2p/%s", node.libP2PNode.Addrs()[0], node.libP2PNode.ID())
+}
+
+func Test_Repository_FetchesProposalsOfOtherNodes(t *testing.T) {
+	providerNode := startTestNode(t)
+	defer providerNode.Stop()
+
+	consumerNode := startTestNode(t, nodeAddress(providerNode))
+	defer consumerNode.Stop()
+
+	assert.Eventually(t, func() bool {
+		return providerNode.libP2PDHT.RoutingTable().Size() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	registry := NewRegistry(providerNode, time.Minute)
+	assert.NoError(t, registry.RegisterProposal(supportedProposal, providerSigner))
+
+	repository := NewRepository(consumerNode, time.Minute)
+	assert.NoError(t, repository.fetch())
+
+	proposals, err := repository.Proposals(&proposal.Filter{ServiceType: "mock_service"})
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 1)
+	assert.Equal(t, supportedProposal.UniqueID(), proposals[0].UniqueID())
+
+	found, err := repository.Proposal(supportedProposal.UniqueID())
+	assert.NoError(t, err)
+	assert.Equal(t, providerID, found.ProviderID)
+
+	_, err = repository.Proposal(market.ProposalID{ProviderID: "0x1", ServiceType: "mock_service"})
+	assert.Error(t, err)
+}
+
+func Test_Registry_RequiresStartedNode(t *testing.T) {
+	node, err := NewNode("/ip4/127.0.0.1/tcp/0", nil)
+	assert.NoError(t, err)
+
+	registry := NewRegistry(node, time.Minute)
+	assert.Equal(t, errNodeNotStarted, registry.RegisterProposal(supportedProposal, providerSigner))
+
+	repository := NewRepository(node, time.Minute)
+	assert.Equal(t, errNodeNotStarted, repository.fetch())
+}
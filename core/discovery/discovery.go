@@ -27,6 +27,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity/registry"
 	identity_registry "github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/behavior"
 	"github.com/rs/zerolog/log"
 )
 
@@ -46,6 +47,10 @@ const (
 	StatusUndefined
 )
 
+type natBehaviorProvider interface {
+	Behavior() behavior.Behavior
+}
+
 // Discovery structure holds discovery service state
 type Discovery struct {
 	identityRegistry identity_registry.IdentityRegistry
@@ -56,6 +61,7 @@ type Discovery struct {
 	signer           identity.Signer
 	proposal         market.ServiceProposal
 	eventBus         eventbus.EventBus
+	natBehavior      natBehaviorProvider
 
 	statusChan                  chan Status
 	status                      Status
@@ -66,19 +72,22 @@ type Discovery struct {
 	mu sync.RWMutex
 }
 
-// NewService creates new discovery service
+// NewService creates new discovery service.
+// Announced proposals are updated with the current NAT type of the node.
 func NewService(
 	identityRegistry identity_registry.IdentityRegistry,
 	proposalRegistry ProposalRegistry,
 	proposalPingTTL time.Duration,
 	signerCreate identity.SignerFactory,
 	eventBus eventbus.EventBus,
+	natBehavior natBehaviorProvider,
 ) *Discovery {
 	return &Discovery{
 		identityRegistry:            identityRegistry,
 		proposalRegistry:            proposalRegistry,
 		proposalPingTTL:             proposalPingTTL,
 		eventBus:                    eventBus,
+		natBehavior:                 natBehavior,
 		signerCreate:                signerCreate,
 		statusChan:                  make(chan Status),
 		status:                      StatusUndefined,
@@ -183,14 +192,15 @@ func (d *Discovery) registerIdentity() {
 }
 
 func (d *Discovery) registerProposal() {
-	err := d.proposalRegistry.RegisterProposal(d.proposal, d.signer)
+	proposal := d.announcedProposal()
+	err := d.proposalRegistry.RegisterProposal(proposal, d.signer)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to register proposal, retrying after 1 min")
 		time.Sleep(1 * time.Minute)
 		d.changeStatus(RegisterProposal)
 		return
 	}
-	d.eventBus.Publish(AppTopicProposalAnnounce, d.proposal)
+	d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
 	d.changeStatus(PingProposal)
 }
 
@@ -199,16 +209,26 @@ func (d *Discovery) pingProposal() {
 	case <-d.stop:
 		return
 	case <-time.After(d.proposalPingTTL):
-		err := d.proposalRegistry.PingProposal(d.proposal, d.signer)
+		proposal := d.announcedProposal()
+		err := d.proposalRegistry.PingProposal(proposal, d.signer)
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to ping proposal")
 		}
 
-		d.eventBus.Publish(AppTopicProposalAnnounce, d.proposal)
+		d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
 		d.changeStatus(PingProposal)
 	}
 }
 
+// announcedProposal returns proposal with NAT type detected at the moment, it can change along with the network.
+func (d *Discovery) announcedProposal() market.ServiceProposal {
+	proposal := d.proposal
+	if d.natBehavior != nil {
+		proposal.NATType = string(d.natBehavior.Behavior().Type)
+	}
+	return proposal
+}
+
 func (d *Discovery) unregisterProposal() {
 	err := d.proposalRegistry.UnregisterProposal(d.proposal, d.signer)
 	if err != nil {
@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	identityregistry "github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/behavior"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,6 +61,27 @@ func TestStartRegistersProposal(t *testing.T) {
 	assert.Equal(t, PingProposal, actualStatus)
 }
 
+func TestStartRegistersProposalWithNATType(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identityregistry.FakeRegistry{RegistrationStatus: identityregistry.Registered}
+	d.natBehavior = &mockNATBehavior{behavior.Behavior{Type: behavior.NATTypeFullCone}}
+	announced := make(chan market.ServiceProposal, 1)
+	err := d.eventBus.SubscribeAsync(AppTopicProposalAnnounce, func(proposal market.ServiceProposal) {
+		announced <- proposal
+	})
+	assert.NoError(t, err)
+
+	d.Start(providerID, serviceProposal)
+	defer d.Stop()
+
+	select {
+	case proposal := <-announced:
+		assert.Equal(t, "full_cone", proposal.NATType)
+	case <-time.After(2 * time.Second):
+		t.Fatal("proposal was not announced")
+	}
+}
+
 func TestStartRegistersIdentitySuccessfully(t *testing.T) {
 	d := discoveryWithMockedDependencies()
 	d.identityRegistry = &identityregistry.FakeRegistry{RegistrationStatus: identityregistry.Unregistered}
@@ -141,3 +163,11 @@ func (mockedProposalRegistry) UnregisterProposal(proposal market.ServiceProposal
 }
 
 var _ ProposalRegistry = &mockedProposalRegistry{}
+
+type mockNATBehavior struct {
+	behavior behavior.Behavior
+}
+
+func (m *mockNATBehavior) Behavior() behavior.Behavior {
+	return m.behavior
+}
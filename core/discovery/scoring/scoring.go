@@ -0,0 +1,266 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package scoring
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// UnknownQuality is a score given to proposals without any connection metrics or session history.
+const UnknownQuality = 0.5
+
+type qualityFinder interface {
+	ProposalsMetrics() []quality.ConnectMetric
+}
+
+type sessionHistory interface {
+	List(filter *session.Filter) ([]session.History, error)
+}
+
+// Weights are weights of the score components, total score is a weighted average of them.
+type Weights struct {
+	Price    float64
+	Quality  float64
+	History  float64
+	Location float64
+}
+
+// DefaultWeights returns weights of the score components, which favour connection quality.
+func DefaultWeights() Weights {
+	return Weights{
+		Price:    0.3,
+		Quality:  0.4,
+		History:  0.2,
+		Location: 0.1,
+	}
+}
+
+// Score explains how the proposal was ranked, every component is between 0 (the worst) and 1 (the best).
+type Score struct {
+	Total float64
+	// Price is relative to the cheapest and the most expensive proposals ranked together.
+	Price float64
+	// Quality is a share of successful connections measured by quality oracle.
+	Quality float64
+	// History is a share of successful own sessions with the provider.
+	History float64
+	// Location is a match with the preferred location.
+	Location float64
+}
+
+// RankedProposal is a proposal with its score.
+type RankedProposal struct {
+	market.ServiceProposal
+	Score Score
+}
+
+// Preferences are consumer preferences proposals are ranked by.
+type Preferences struct {
+	// Country is a preferred country code of the provider.
+	Country string
+}
+
+// Ranker ranks proposals by their price, quality and consumer's own sessions history.
+type Ranker struct {
+	quality qualityFinder
+	history sessionHistory
+	weights Weights
+}
+
+// NewRanker creates proposals ranker.
+func NewRanker(quality qualityFinder, history sessionHistory, weights Weights) *Ranker {
+	return &Ranker{
+		quality: quality,
+		history: history,
+		weights: weights,
+	}
+}
+
+// Rank scores the given proposals and returns them from the best to the worst.
+func (r *Ranker) Rank(proposals []market.ServiceProposal, preferences Preferences) ([]RankedProposal, error) {
+	history, err := r.sessionHistory()
+	if err != nil {
+		return nil, err
+	}
+	qualities := make(map[market.ProposalID]float64)
+	for _, metric := range r.quality.ProposalsMetrics() {
+		qualities[market.ProposalID{ProviderID: metric.ProposalID.ProviderID, ServiceType: metric.ProposalID.ServiceType}] = Quality(metric)
+	}
+	prices := newPriceRange(proposals)
+
+	ranked := make([]RankedProposal, len(proposals))
+	for i, proposal := range proposals {
+		score := Score{
+			Price:    prices.score(proposal),
+			Quality:  UnknownQuality,
+			History:  history[proposal.UniqueID()].score(),
+			Location: locationScore(proposal, preferences),
+		}
+		if quality, ok := qualities[proposal.UniqueID()]; ok {
+			score.Quality = quality
+		}
+		score.Total = r.total(score)
+		ranked[i] = RankedProposal{ServiceProposal: proposal, Score: score}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score.Total > ranked[j].Score.Total
+	})
+	return ranked, nil
+}
+
+func (r *Ranker) total(score Score) float64 {
+	sum := r.weights.Price + r.weights.Quality + r.weights.History + r.weights.Location
+	if sum == 0 {
+		return 0
+	}
+	return (r.weights.Price*score.Price +
+		r.weights.Quality*score.Quality +
+		r.weights.History*score.History +
+		r.weights.Location*score.Location) / sum
+}
+
+func (r *Ranker) sessionHistory() (map[market.ProposalID]sessionsCount, error) {
+	sessions, err := r.history.List(session.NewFilter().SetDirection(session.DirectionConsumed))
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[market.ProposalID]sessionsCount)
+	for _, se := range sessions {
+		id := market.ProposalID{ProviderID: se.ProviderID.Address, ServiceType: se.ServiceType}
+		count := history[id]
+		count.total++
+		if se.DataReceived+se.DataSent > 0 {
+			count.successful++
+		}
+		history[id] = count
+	}
+	return history, nil
+}
+
+// Quality is a share of successful connections, proposals failing monitoring are scored zero.
+func Quality(metric quality.ConnectMetric) float64 {
+	if metric.MonitoringFailed {
+		return 0
+	}
+
+	total := metric.ConnectCount.Success + metric.ConnectCount.Fail + metric.ConnectCount.Timeout
+	if total == 0 {
+		return UnknownQuality
+	}
+	return float64(metric.ConnectCount.Success) / float64(total)
+}
+
+type sessionsCount struct {
+	total      int
+	successful int
+}
+
+// score is a smoothed share of successful sessions, so a single session does not decide on its own.
+func (c sessionsCount) score() float64 {
+	return float64(c.successful+1) / float64(c.total+2)
+}
+
+func locationScore(proposal market.ServiceProposal, preferences Preferences) float64 {
+	if preferences.Country == "" {
+		return 1
+	}
+	if proposal.ServiceDefinition == nil {
+		return 0
+	}
+
+	if strings.EqualFold(proposal.ServiceDefinition.GetLocation().Country, preferences.Country) {
+		return 1
+	}
+	return 0
+}
+
+type priceRange struct {
+	minute, gib bounds
+}
+
+func newPriceRange(proposals []market.ServiceProposal) priceRange {
+	var prices priceRange
+	for i, proposal := range proposals {
+		minute, gib := pricePerMinute(proposal), pricePerGiB(proposal)
+		if i == 0 {
+			prices.minute = bounds{min: minute, max: minute}
+			prices.gib = bounds{min: gib, max: gib}
+			continue
+		}
+		prices.minute.extend(minute)
+		prices.gib.extend(gib)
+	}
+	return prices
+}
+
+// score averages relative prices per minute and per GiB, the cheapest proposal is scored 1.
+func (r priceRange) score(proposal market.ServiceProposal) float64 {
+	return (r.minute.score(pricePerMinute(proposal)) + r.gib.score(pricePerGiB(proposal))) / 2
+}
+
+type bounds struct {
+	min, max float64
+}
+
+func (b *bounds) extend(price float64) {
+	if price < b.min {
+		b.min = price
+	}
+	if price > b.max {
+		b.max = price
+	}
+}
+
+func (b bounds) score(price float64) float64 {
+	if b.max == b.min {
+		return 1
+	}
+	return 1 - (price-b.min)/(b.max-b.min)
+}
+
+func pricePerMinute(proposal market.ServiceProposal) float64 {
+	if proposal.PaymentMethod == nil || proposal.PaymentMethod.GetRate().PerTime == 0 {
+		return 0
+	}
+	return price(proposal, float64(time.Minute)/float64(proposal.PaymentMethod.GetRate().PerTime))
+}
+
+func pricePerGiB(proposal market.ServiceProposal) float64 {
+	if proposal.PaymentMethod == nil || proposal.PaymentMethod.GetRate().PerByte == 0 {
+		return 0
+	}
+	return price(proposal, float64(datasize.GiB.Bytes())/float64(proposal.PaymentMethod.GetRate().PerByte))
+}
+
+func price(proposal market.ServiceProposal, chunks float64) float64 {
+	amount := proposal.PaymentMethod.GetPrice().Amount
+	if amount == nil {
+		return 0
+	}
+	total, _ := new(big.Float).Mul(big.NewFloat(chunks), new(big.Float).SetInt(amount)).Float64()
+	return total
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package scoring
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPaymentMethod struct {
+	price money.Money
+	rate  market.PaymentRate
+}
+
+func (m mockPaymentMethod) GetPrice() money.Money {
+	return m.price
+}
+
+func (m mockPaymentMethod) GetType() string {
+	return "mock"
+}
+
+func (m mockPaymentMethod) GetRate() market.PaymentRate {
+	return m.rate
+}
+
+type mockServiceDefinition struct {
+	country string
+}
+
+func (m mockServiceDefinition) GetLocation() market.Location {
+	return market.Location{Country: m.country}
+}
+
+type mockQualityFinder struct {
+	metrics []quality.ConnectMetric
+}
+
+func (m *mockQualityFinder) ProposalsMetrics() []quality.ConnectMetric {
+	return m.metrics
+}
+
+type mockSessionHistory struct {
+	sessions []session.History
+}
+
+func (m *mockSessionHistory) List(filter *session.Filter) ([]session.History, error) {
+	return m.sessions, nil
+}
+
+func testProposal(providerID, country string, pricePerGiB int64) market.ServiceProposal {
+	return market.ServiceProposal{
+		ProviderID:        providerID,
+		ServiceType:       "wireguard",
+		ServiceDefinition: mockServiceDefinition{country: country},
+		PaymentMethod: mockPaymentMethod{
+			price: money.NewMoney(big.NewInt(pricePerGiB), money.CurrencyMyst),
+			rate:  market.PaymentRate{PerTime: time.Minute, PerByte: datasize.GiB.Bytes()},
+		},
+	}
+}
+
+func TestRanker_Rank(t *testing.T) {
+	proposals := []market.ServiceProposal{
+		testProposal("expensive", "DE", 300),
+		testProposal("cheap", "US", 100),
+		testProposal("failing", "DE", 100),
+	}
+	ranker := NewRanker(
+		&mockQualityFinder{metrics: []quality.ConnectMetric{
+			{ProposalID: quality.ProposalID{ProviderID: "expensive", ServiceType: "wireguard"}, ConnectCount: quality.ConnectCount{Success: 9, Fail: 1}},
+			{ProposalID: quality.ProposalID{ProviderID: "cheap", ServiceType: "wireguard"}, ConnectCount: quality.ConnectCount{Success: 8, Timeout: 2}},
+			{ProposalID: quality.ProposalID{ProviderID: "failing", ServiceType: "wireguard"}, MonitoringFailed: true},
+		}},
+		&mockSessionHistory{sessions: []session.History{
+			{ProviderID: identity.FromAddress("cheap"), ServiceType: "wireguard", DataReceived: 100},
+			{ProviderID: identity.FromAddress("cheap"), ServiceType: "wireguard", DataReceived: 100},
+			{ProviderID: identity.FromAddress("expensive"), ServiceType: "wireguard"},
+		}},
+		DefaultWeights(),
+	)
+
+	ranked, err := ranker.Rank(proposals, Preferences{Country: "DE"})
+	assert.NoError(t, err)
+
+	assert.Len(t, ranked, 3)
+	assert.Equal(t, "cheap", ranked[0].ProviderID)
+	assert.InDelta(t, 1, ranked[0].Score.Price, 0.001)
+	assert.InDelta(t, 0.8, ranked[0].Score.Quality, 0.001)
+	assert.InDelta(t, 0.75, ranked[0].Score.History, 0.001)
+	assert.InDelta(t, 0, ranked[0].Score.Location, 0.001)
+	assert.InDelta(t, 0.3+0.32+0.15, ranked[0].Score.Total, 0.001)
+
+	assert.Equal(t, "expensive", ranked[1].ProviderID)
+	assert.InDelta(t, 0, ranked[1].Score.Price, 0.001)
+	assert.InDelta(t, 1.0/3, ranked[1].Score.History, 0.001)
+	assert.InDelta(t, 1, ranked[1].Score.Location, 0.001)
+
+	assert.Equal(t, "failing", ranked[2].ProviderID)
+	assert.InDelta(t, 0, ranked[2].Score.Quality, 0.001)
+	assert.InDelta(t, UnknownQuality, ranked[2].Score.History, 0.001)
+}
+
+func TestRanker_Rank_WithoutPreferences(t *testing.T) {
+	ranker := NewRanker(&mockQualityFinder{}, &mockSessionHistory{}, DefaultWeights())
+
+	ranked, err := ranker.Rank([]market.ServiceProposal{testProposal("provider", "", 100)}, Preferences{})
+	assert.NoError(t, err)
+
+	score := ranked[0].Score
+	assert.InDelta(t, 0.3+0.2+0.1+0.1, score.Total, 0.001)
+	assert.Equal(t, Score{Total: score.Total, Price: 1, Quality: UnknownQuality, History: UnknownQuality, Location: 1}, score)
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// writeEventBusStats writes delivery statistics of the event bus topics.
+func writeEventBusStats(w io.Writer, monitor eventbus.Monitor) {
+	stats := monitor.Stats()
+	topics := make([]string, 0, len(stats))
+	for topic := range stats {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	counters := []struct {
+		name  string
+		help  string
+		value func(eventbus.TopicStats) uint64
+	}{
+		{
+			name:  "myst_eventbus_published_total",
+			help:  "Number of events published to the topic.",
+			value: func(s eventbus.TopicStats) uint64 { return s.Published },
+		},
+		{
+			name:  "myst_eventbus_delivered_total",
+			help:  "Number of events delivered to the topic subscribers.",
+			value: func(s eventbus.TopicStats) uint64 { return s.Delivered },
+		},
+		{
+			name:  "myst_eventbus_panics_total",
+			help:  "Number of topic subscriber calls which panicked.",
+			value: func(s eventbus.TopicStats) uint64 { return s.Panics },
+		},
+		{
+			name:  "myst_eventbus_mismatches_total",
+			help:  "Number of events not delivered because of the subscriber signature.",
+			value: func(s eventbus.TopicStats) uint64 { return s.Mismatches },
+		},
+	}
+	for _, counter := range counters {
+		writeHeader(w, counter.name, counter.help, typeCounter)
+		for _, topic := range topics {
+			writeSample(w, counter.name, []string{"topic"}, []string{topic}, float64(counter.value(stats[topic])))
+		}
+	}
+
+	const latency = "myst_eventbus_handler_duration_seconds"
+	writeHeader(w, latency, "Duration of topic subscriber calls.", typeHistogram)
+	for _, topic := range topics {
+		histogram := stats[topic].Latency
+		var cumulative uint64
+		for i, bound := range histogram.Bounds {
+			cumulative += histogram.Counts[i]
+			le := strconv.FormatFloat(bound.Seconds(), 'g', -1, 64)
+			writeSample(w, latency+"_bucket", []string{"topic", "le"}, []string{topic, le}, float64(cumulative))
+		}
+		writeSample(w, latency+"_bucket", []string{"topic", "le"}, []string{topic, "+Inf"}, float64(histogram.Count()))
+		writeSample(w, latency+"_sum", []string{"topic"}, []string{topic}, histogram.Sum.Seconds())
+		writeSample(w, latency+"_count", []string{"topic"}, []string{topic}, float64(histogram.Count()))
+	}
+}
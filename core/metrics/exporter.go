@@ -0,0 +1,292 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"io"
+	"math/big"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/p2p"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+// Exporter collects metrics of the node internals from the event bus and exposes them in Prometheus text format.
+type Exporter struct {
+	monitor eventbus.Monitor
+
+	providerSessions      *vec
+	providerSessionsTotal *vec
+	providerBytes         *vec
+	providerEarned        *vec
+	providerEarnings      *vec
+	hermesPromises        *vec
+	settlements           *vec
+	settled               *vec
+	consumerSessions      *vec
+	consumerSessionsTotal *vec
+	consumerStates        *vec
+	consumerBytes         *vec
+	consumerSpent         *vec
+	keepAliveFailures     *vec
+	natTraversals         *vec
+
+	lock sync.Mutex
+	// Session events carry cumulative values, the last seen ones are kept to count the increments.
+	providerSessionTypes map[string]string
+	providerTransferred  map[string]transferred
+	providerTokens       map[string]*big.Int
+	consumerTransferred  map[string]transferred
+	consumerTokens       map[string]*big.Int
+}
+
+type transferred struct {
+	up, down uint64
+}
+
+// NewExporter creates the exporter of node metrics.
+// Delivery statistics of the event bus are exported too, when the bus provides them.
+func NewExporter(bus eventbus.EventBus) *Exporter {
+	monitor, _ := bus.(eventbus.Monitor)
+	return &Exporter{
+		monitor: monitor,
+
+		providerSessions:      newGauge("myst_provider_sessions", "Number of active provider sessions.", "service_type"),
+		providerSessionsTotal: newCounter("myst_provider_sessions_total", "Number of provider sessions created.", "service_type"),
+		providerBytes:         newCounter("myst_provider_transferred_bytes_total", "Number of bytes transferred by provider sessions.", "direction"),
+		providerEarned:        newCounter("myst_provider_earned_myst_total", "Amount of MYST earned by provider sessions."),
+		providerEarnings:      newGauge("myst_provider_earnings_myst", "Earnings of provider identity.", "identity", "kind"),
+		hermesPromises:        newCounter("myst_provider_hermes_promises_total", "Number of promises received from hermes."),
+		settlements:           newCounter("myst_provider_settlements_total", "Number of settlements by result.", "result"),
+		settled:               newCounter("myst_provider_settled_myst_total", "Amount of MYST sent to beneficiary by settlements."),
+		consumerSessions:      newGauge("myst_consumer_sessions", "Number of active consumer sessions."),
+		consumerSessionsTotal: newCounter("myst_consumer_sessions_total", "Number of consumer sessions created."),
+		consumerStates:        newCounter("myst_consumer_connection_states_total", "Number of consumer connection state changes by state.", "state"),
+		consumerBytes:         newCounter("myst_consumer_transferred_bytes_total", "Number of bytes transferred by consumer sessions.", "direction"),
+		consumerSpent:         newCounter("myst_consumer_spent_myst_total", "Amount of MYST paid for consumer sessions."),
+		keepAliveFailures:     newCounter("myst_p2p_keepalive_failures_total", "Number of failed p2p keep alive pings.", "role", "closed"),
+		natTraversals:         newCounter("myst_nat_traversals_total", "Number of NAT traversal attempts by stage and result.", "stage", "result"),
+
+		providerSessionTypes: make(map[string]string),
+		providerTransferred:  make(map[string]transferred),
+		providerTokens:       make(map[string]*big.Int),
+		consumerTransferred:  make(map[string]transferred),
+		consumerTokens:       make(map[string]*big.Int),
+	}
+}
+
+// Handler returns HTTP handler serving metrics in Prometheus text format.
+func (e *Exporter) Handler() http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, _ *http.Request) {
+		resp.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		e.Write(resp)
+	})
+}
+
+// Write writes all metrics in Prometheus text format.
+func (e *Exporter) Write(w io.Writer) {
+	for _, v := range []*vec{
+		e.providerSessions, e.providerSessionsTotal, e.providerBytes, e.providerEarned, e.providerEarnings,
+		e.hermesPromises, e.settlements, e.settled,
+		e.consumerSessions, e.consumerSessionsTotal, e.consumerStates, e.consumerBytes, e.consumerSpent,
+		e.keepAliveFailures, e.natTraversals,
+	} {
+		v.write(w)
+	}
+
+	writeHeader(w, "myst_p2p_channels", "Number of open p2p channels.", typeGauge)
+	writeSample(w, "myst_p2p_channels", nil, nil, float64(p2p.OpenChannels()))
+	writeHeader(w, "go_goroutines", "Number of goroutines that currently exist.", typeGauge)
+	writeSample(w, "go_goroutines", nil, nil, float64(runtime.NumGoroutine()))
+
+	if e.monitor != nil {
+		writeEventBusStats(w, e.monitor)
+	}
+}
+
+// Subscribe subscribes to relevant events of event bus.
+// Handlers are cheap and called synchronously, so that cumulative values are counted in the order of publishing.
+func (e *Exporter) Subscribe(bus eventbus.Subscriber) error {
+	subscriptions := map[string]interface{}{
+		sessionEvent.AppTopicSession:                 e.consumeServiceSessionEvent,
+		sessionEvent.AppTopicDataTransferred:         e.consumeServiceDataTransferredEvent,
+		sessionEvent.AppTopicTokensEarned:            e.consumeServiceTokensEarnedEvent,
+		pingpongEvent.AppTopicEarningsChanged:        e.consumeEarningsChangedEvent,
+		pingpongEvent.AppTopicHermesPromise:          e.consumeHermesPromiseEvent,
+		pingpongEvent.AppTopicSettlementComplete:     e.consumeSettlementEvent,
+		pingpongEvent.AppTopicInvoicePaid:            e.consumeInvoicePaidEvent,
+		connectionstate.AppTopicConnectionSession:    e.consumeConnectionSessionEvent,
+		connectionstate.AppTopicConnectionState:      e.consumeConnectionStateEvent,
+		connectionstate.AppTopicConnectionStatistics: e.consumeConnectionStatisticsEvent,
+		p2p.AppTopicKeepAliveFailed:                  e.consumeKeepAliveFailedEvent,
+		natEvent.AppTopicTraversal:                   e.consumeNATEvent,
+	}
+	for topic, fn := range subscriptions {
+		if err := bus.Subscribe(topic, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) consumeServiceSessionEvent(ev sessionEvent.AppEventSession) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	sessionID := ev.Session.ID
+	switch ev.Status {
+	case sessionEvent.CreatedStatus:
+		serviceType := ev.Session.Proposal.ServiceType
+		e.providerSessionTypes[sessionID] = serviceType
+		e.providerSessions.add(1, serviceType)
+		e.providerSessionsTotal.add(1, serviceType)
+	case sessionEvent.RemovedStatus:
+		if serviceType, ok := e.providerSessionTypes[sessionID]; ok {
+			e.providerSessions.add(-1, serviceType)
+		}
+		delete(e.providerSessionTypes, sessionID)
+		delete(e.providerTransferred, sessionID)
+		delete(e.providerTokens, sessionID)
+	}
+}
+
+func (e *Exporter) consumeServiceDataTransferredEvent(ev sessionEvent.AppEventDataTransferred) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.providerTransferred[ev.ID] = countTransferred(e.providerBytes, e.providerTransferred[ev.ID], transferred{up: ev.Up, down: ev.Down})
+}
+
+func (e *Exporter) consumeServiceTokensEarnedEvent(ev sessionEvent.AppEventTokensEarned) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.providerTokens[ev.SessionID] = countTokens(e.providerEarned, e.providerTokens[ev.SessionID], ev.Total)
+}
+
+func (e *Exporter) consumeEarningsChangedEvent(ev pingpongEvent.AppEventEarningsChanged) {
+	if ev.Current.LifetimeBalance != nil {
+		e.providerEarnings.set(crypto.BigMystToFloat(ev.Current.LifetimeBalance), ev.Identity.Address, "lifetime")
+	}
+	if ev.Current.UnsettledBalance != nil {
+		e.providerEarnings.set(crypto.BigMystToFloat(ev.Current.UnsettledBalance), ev.Identity.Address, "unsettled")
+	}
+}
+
+func (e *Exporter) consumeHermesPromiseEvent(_ pingpongEvent.AppEventHermesPromise) {
+	e.hermesPromises.add(1)
+}
+
+func (e *Exporter) consumeSettlementEvent(ev pingpongEvent.AppEventSettlementComplete) {
+	if !ev.Successful {
+		e.settlements.add(1, "failure")
+		return
+	}
+
+	e.settlements.add(1, "success")
+	if ev.Amount != nil {
+		e.settled.add(crypto.BigMystToFloat(ev.Amount))
+	}
+}
+
+func (e *Exporter) consumeInvoicePaidEvent(ev pingpongEvent.AppEventInvoicePaid) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.consumerTokens[ev.SessionID] = countTokens(e.consumerSpent, e.consumerTokens[ev.SessionID], ev.Invoice.AgreementTotal)
+}
+
+func (e *Exporter) consumeConnectionSessionEvent(ev connectionstate.AppEventConnectionSession) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	switch ev.Status {
+	case connectionstate.SessionCreatedStatus:
+		e.consumerSessions.add(1)
+		e.consumerSessionsTotal.add(1)
+	case connectionstate.SessionEndedStatus:
+		e.consumerSessions.add(-1)
+		sessionID := string(ev.SessionInfo.SessionID)
+		delete(e.consumerTransferred, sessionID)
+		delete(e.consumerTokens, sessionID)
+	}
+}
+
+func (e *Exporter) consumeConnectionStateEvent(ev connectionstate.AppEventConnectionState) {
+	e.consumerStates.add(1, string(ev.State))
+}
+
+func (e *Exporter) consumeConnectionStatisticsEvent(ev connectionstate.AppEventConnectionStatistics) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	sessionID := string(ev.SessionInfo.SessionID)
+	e.consumerTransferred[sessionID] = countTransferred(
+		e.consumerBytes,
+		e.consumerTransferred[sessionID],
+		transferred{up: ev.Stats.BytesSent, down: ev.Stats.BytesReceived},
+	)
+}
+
+func (e *Exporter) consumeKeepAliveFailedEvent(ev p2p.AppEventKeepAliveFailed) {
+	e.keepAliveFailures.add(1, ev.Role, strconv.FormatBool(ev.Closed))
+}
+
+func (e *Exporter) consumeNATEvent(ev natEvent.Event) {
+	result := "failure"
+	if ev.Successful {
+		result = "success"
+	}
+	e.natTraversals.add(1, ev.Stage, result)
+}
+
+// countTransferred adds the increase of cumulative session traffic to the counter and returns the new last value.
+func countTransferred(counter *vec, last, current transferred) transferred {
+	if current.up > last.up {
+		counter.add(float64(current.up-last.up), "up")
+		last.up = current.up
+	}
+	if current.down > last.down {
+		counter.add(float64(current.down-last.down), "down")
+		last.down = current.down
+	}
+	return last
+}
+
+// countTokens adds the increase of cumulative session amount to the counter and returns the new last value.
+func countTokens(counter *vec, last, current *big.Int) *big.Int {
+	if current == nil {
+		return last
+	}
+	if last == nil {
+		last = new(big.Int)
+	}
+	if current.Cmp(last) > 0 {
+		counter.add(crypto.BigMystToFloat(new(big.Int).Sub(current, last)))
+		return new(big.Int).Set(current)
+	}
+	return last
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/market"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/p2p"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSubscribedExporter(t *testing.T) (*Exporter, eventbus.EventBus) {
+	bus := eventbus.New()
+	exporter := NewExporter(bus)
+	assert.NoError(t, exporter.Subscribe(bus))
+	return exporter, bus
+}
+
+func TestExporter_CountsProviderSessions(t *testing.T) {
+	exporter, bus := newSubscribedExporter(t)
+
+	sessionContext := sessionEvent.SessionContext{ID: "1", Proposal: market.ServiceProposal{ServiceType: "wireguard"}}
+	bus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{Status: sessionEvent.CreatedStatus, Session: sessionContext})
+	assert.Equal(t, 1.0, exporter.providerSessions.get("wireguard"))
+	assert.Equal(t, 1.0, exporter.providerSessionsTotal.get("wireguard"))
+
+	bus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{Status: sessionEvent.RemovedStatus, Session: sessionContext})
+	assert.Equal(t, 0.0, exporter.providerSessions.get("wireguard"))
+	assert.Equal(t, 1.0, exporter.providerSessionsTotal.get("wireguard"))
+}
+
+func TestExporter_CountsIncrementsOfCumulativeValues(t *testing.T) {
+	exporter, bus := newSubscribedExporter(t)
+
+	bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "1", Up: 10, Down: 100})
+	bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "1", Up: 15, Down: 300})
+	bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "2", Up: 5, Down: 50})
+	assert.Equal(t, 20.0, exporter.providerBytes.get("up"))
+	assert.Equal(t, 350.0, exporter.providerBytes.get("down"))
+
+	status := connectionstate.Status{SessionID: "3"}
+	bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+		Stats: connectionstate.Statistics{BytesSent: 7, BytesReceived: 70}, SessionInfo: status,
+	})
+	bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+		Stats: connectionstate.Statistics{BytesSent: 9, BytesReceived: 90}, SessionInfo: status,
+	})
+	assert.Equal(t, 9.0, exporter.consumerBytes.get("up"))
+	assert.Equal(t, 90.0, exporter.consumerBytes.get("down"))
+
+	bus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{SessionID: "1", Total: big.NewInt(5e17)})
+	bus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{SessionID: "1", Total: big.NewInt(15e17)})
+	assert.Equal(t, 1.5, exporter.providerEarned.get())
+}
+
+func TestExporter_CountsOutcomes(t *testing.T) {
+	exporter, bus := newSubscribedExporter(t)
+
+	bus.Publish(pingpongEvent.AppTopicSettlementComplete, pingpongEvent.AppEventSettlementComplete{Successful: true, Amount: big.NewInt(2e18)})
+	bus.Publish(pingpongEvent.AppTopicSettlementComplete, pingpongEvent.AppEventSettlementComplete{Successful: false})
+	assert.Equal(t, 1.0, exporter.settlements.get("success"))
+	assert.Equal(t, 1.0, exporter.settlements.get("failure"))
+	assert.Equal(t, 2.0, exporter.settled.get())
+
+	bus.Publish(p2p.AppTopicKeepAliveFailed, p2p.AppEventKeepAliveFailed{Role: "consumer", Closed: true})
+	assert.Equal(t, 1.0, exporter.keepAliveFailures.get("consumer", "true"))
+
+	bus.Publish(natEvent.AppTopicTraversal, natEvent.Event{Stage: "hole_punching", Successful: false})
+	assert.Equal(t, 1.0, exporter.natTraversals.get("hole_punching", "failure"))
+}
+
+func TestExporter_Handler(t *testing.T) {
+	exporter, bus := newSubscribedExporter(t)
+	bus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{State: connectionstate.Connected})
+
+	resp := httptest.NewRecorder()
+	exporter.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `myst_consumer_connection_states_total{state="Connected"} 1`)
+	assert.Contains(t, resp.Body.String(), "myst_p2p_channels 0")
+	assert.Contains(t, resp.Body.String(), `myst_eventbus_published_total{topic="State"}`)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// vec is a metric family of the single type with values partitioned by labels.
+type vec struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	lock   sync.Mutex
+	values map[string]*sample
+}
+
+type sample struct {
+	labelValues []string
+	value       float64
+}
+
+func newCounter(name, help string, labels ...string) *vec {
+	return newVec(name, help, typeCounter, labels)
+}
+
+func newGauge(name, help string, labels ...string) *vec {
+	return newVec(name, help, typeGauge, labels)
+}
+
+func newVec(name, help, kind string, labels []string) *vec {
+	return &vec{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		values: make(map[string]*sample),
+	}
+}
+
+func (v *vec) add(delta float64, labelValues ...string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.sample(labelValues).value += delta
+}
+
+func (v *vec) set(value float64, labelValues ...string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.sample(labelValues).value = value
+}
+
+func (v *vec) get(labelValues ...string) float64 {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if s, ok := v.values[strings.Join(labelValues, "\xff")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (v *vec) sample(labelValues []string) *sample {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.values[key]
+	if !ok {
+		s = &sample{labelValues: labelValues}
+		v.values[key] = s
+	}
+	return s
+}
+
+func (v *vec) write(w io.Writer) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	writeHeader(w, v.name, v.help, v.kind)
+	if len(v.labels) == 0 && len(v.values) == 0 {
+		writeSample(w, v.name, nil, nil, 0)
+		return
+	}
+
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := v.values[key]
+		writeSample(w, v.name, v.labels, s.labelValues, s.value)
+	}
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+func writeSample(w io.Writer, name string, labels, labelValues []string, value float64) {
+	io.WriteString(w, name)
+	if len(labels) > 0 {
+		escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+		pairs := make([]string, len(labels))
+		for i := range labels {
+			pairs[i] = fmt.Sprintf(`%s="%s"`, labels[i], escaper.Replace(labelValues[i]))
+		}
+		fmt.Fprintf(w, "{%s}", strings.Join(pairs, ","))
+	}
+	fmt.Fprintf(w, " %s\n", strconv.FormatFloat(value, 'g', -1, 64))
+}
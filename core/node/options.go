@@ -19,7 +19,9 @@ package node
 
 import (
 	"path"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/logconfig"
@@ -80,9 +82,29 @@ type Options struct {
 	Consumer bool
 
 	P2PPorts        *port.Range
+	P2PStream       OptionsP2PStream
+	P2PSignal       OptionsP2PSignal
+	P2PRelay        OptionsP2PRelay
 	PilvytisAddress string
 }
 
+// OptionsP2PStream describes p2p stream transport used when UDP is blocked.
+type OptionsP2PStream struct {
+	Port int
+	TLS  bool
+}
+
+// OptionsP2PSignal describes p2p HTTP signaling used instead of NATS broker by consumers.
+type OptionsP2PSignal struct {
+	Port int
+}
+
+// OptionsP2PRelay describes p2p relay used when NAT hole punching fails.
+type OptionsP2PRelay struct {
+	Port      int
+	Addresses []string
+}
+
 // GetOptions retrieves node options from the app configuration.
 func GetOptions() *Options {
 	network := OptionsNetwork{
@@ -120,7 +142,11 @@ func GetOptions() *Options {
 		},
 		FeedbackURL: config.GetString(config.FlagFeedbackURL),
 		Keystore: OptionsKeystore{
-			UseLightweight: config.GetBool(config.FlagKeystoreLightweight),
+			UseLightweight:  config.GetBool(config.FlagKeystoreLightweight),
+			Backend:         config.GetString(config.FlagKeystoreBackend),
+			VaultFile:       config.GetString(config.FlagKeystoreVaultFile),
+			RemoteURL:       config.GetString(config.FlagKeystoreRemoteURL),
+			RemoteTokenFile: config.GetString(config.FlagKeystoreRemoteTokenFile),
 		},
 		LogOptions:     *GetLogOptions(),
 		OptionsNetwork: network,
@@ -150,6 +176,7 @@ func GetOptions() *Options {
 			BCTimeout:                      config.GetDuration(config.FlagPaymentsBCTimeout),
 			HermesPromiseSettlingThreshold: config.GetFloat64(config.FlagPaymentsHermesPromiseSettleThreshold),
 			SettlementTimeout:              config.GetDuration(config.FlagPaymentsHermesPromiseSettleTimeout),
+			SettlementMaxFeeRatio:          config.GetFloat64(config.FlagPaymentsHermesPromiseSettleMaxFeeRatio),
 			MystSCAddress:                  config.GetString(config.FlagPaymentsMystSCAddress),
 			WethAddress:                    config.GetString(config.FlagPaymentsWethAddress),
 			DaiAddress:                     config.GetString(config.FlagPaymentsDaiAddress),
@@ -160,9 +187,14 @@ func GetOptions() *Options {
 			ConsumerDataLeewayMegabytes:    config.GetUInt64(config.FlagPaymentsConsumerDataLeewayMegabytes),
 			ProviderInvoiceFrequency:       config.GetDuration(config.FlagPaymentsProviderInvoiceFrequency),
 			MaxUnpaidInvoiceValue:          config.GetBigInt(config.FlagPaymentsMaxUnpaidInvoiceValue),
+			ConsumerSessionLimit:           config.GetBigInt(config.FlagPaymentsConsumerLimitSession),
+			ConsumerDailyLimit:             config.GetBigInt(config.FlagPaymentsConsumerLimitDaily),
+			ConsumerMonthlyLimit:           config.GetBigInt(config.FlagPaymentsConsumerLimitMonthly),
+			ConsumerLimitAlertThreshold:    config.GetFloat64(config.FlagPaymentsConsumerLimitAlertThreshold),
 		},
 		Hermes: OptionsHermes{
-			HermesID: config.GetString(config.FlagHermesID),
+			HermesID:    config.GetString(config.FlagHermesID),
+			AcceptedIDs: getAcceptedHermesIDs(),
 		},
 		Openvpn: wrapper{nodeOptions: openvpn_core.NodeOptions{
 			BinaryPath: config.GetString(config.FlagOpenvpnBinary),
@@ -170,7 +202,18 @@ func GetOptions() *Options {
 		Firewall: OptionsFirewall{
 			BlockAlways: config.GetBool(config.FlagFirewallKillSwitch),
 		},
-		P2PPorts:        getP2PListenPorts(),
+		P2PPorts: getP2PListenPorts(),
+		P2PStream: OptionsP2PStream{
+			Port: config.GetInt(config.FlagP2PStreamPort),
+			TLS:  config.GetBool(config.FlagP2PStreamTLS),
+		},
+		P2PSignal: OptionsP2PSignal{
+			Port: config.GetInt(config.FlagP2PSignalPort),
+		},
+		P2PRelay: OptionsP2PRelay{
+			Port:      config.GetInt(config.FlagP2PRelayPort),
+			Addresses: config.GetStringSlice(config.FlagP2PRelayAddresses),
+		},
 		Consumer:        config.GetBool(config.FlagConsumer),
 		PilvytisAddress: config.GetString(config.FlagPilvytisAddress),
 	}
@@ -224,6 +267,25 @@ func GetDHTOptions() *OptionsDHT {
 // OptionsKeystore stores the keystore configuration
 type OptionsKeystore struct {
 	UseLightweight bool
+	// Backend keeping identity keys, filesystem keystore is used when empty.
+	Backend   string
+	VaultFile string
+	RemoteURL string
+	// RemoteTokenFile holds the bearer token of the remote signer, so it is not exposed in the command line.
+	RemoteTokenFile string
+}
+
+func getAcceptedHermesIDs() []string {
+	var ids []string
+	for _, id := range config.GetStringSlice(config.FlagHermesAcceptedIDs) {
+		id = strings.TrimSpace(id)
+		if !common.IsHexAddress(id) {
+			log.Warn().Msgf("Ignoring accepted hermes %q, it is not a valid address", id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
 }
 
 func getP2PListenPorts() *port.Range {
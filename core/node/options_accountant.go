@@ -17,7 +17,33 @@
 
 package node
 
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
 // OptionsHermes describes possible parameters for interaction with Hermes
 type OptionsHermes struct {
-	HermesID string
+	HermesID    string
+	AcceptedIDs []string
+}
+
+// HermesIDs returns the registration hermes followed by the additionally accepted ones, without duplicates.
+func (o OptionsHermes) HermesIDs() []common.Address {
+	ids := []common.Address{common.HexToAddress(o.HermesID)}
+	seen := map[common.Address]bool{ids[0]: true}
+	for _, id := range o.AcceptedIDs {
+		id = strings.TrimSpace(id)
+		if !common.IsHexAddress(id) {
+			continue
+		}
+		addr := common.HexToAddress(id)
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		ids = append(ids, addr)
+	}
+	return ids
 }
@@ -28,6 +28,7 @@ type OptionsPayments struct {
 	BCTimeout                      time.Duration
 	HermesPromiseSettlingThreshold float64
 	SettlementTimeout              time.Duration
+	SettlementMaxFeeRatio          float64
 	MystSCAddress                  string
 	WethAddress                    string
 	DaiAddress                     string
@@ -38,4 +39,8 @@ type OptionsPayments struct {
 	ConsumerDataLeewayMegabytes    uint64
 	ProviderInvoiceFrequency       time.Duration
 	MaxUnpaidInvoiceValue          *big.Int
+	ConsumerSessionLimit           *big.Int
+	ConsumerDailyLimit             *big.Int
+	ConsumerMonthlyLimit           *big.Int
+	ConsumerLimitAlertThreshold    float64
 }
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// localPolicySource marks policies defined by the provider itself, instead of fetched from TrustOracle
+const localPolicySource = "local"
+
+// LocalPolicy returns the policy of locally defined rules
+func LocalPolicy(rules market.AccessPolicyRuleSet) market.AccessPolicy {
+	return market.AccessPolicy{ID: rules.ID, Source: localPolicySource}
+}
+
+// LoadRuleSets reads and validates the list of locally defined policy rules from JSON file
+func LoadRuleSets(path string) ([]market.AccessPolicyRuleSet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access policies: %w", err)
+	}
+
+	var ruleSets []market.AccessPolicyRuleSet
+	if err := json.Unmarshal(data, &ruleSets); err != nil {
+		return nil, fmt.Errorf("failed to parse access policies %s: %w", path, err)
+	}
+
+	repository := NewRepository()
+	for _, rules := range ruleSets {
+		if rules.ID == "" {
+			return nil, fmt.Errorf("access policy without ID in %s", path)
+		}
+		repository.SetPolicyRules(LocalPolicy(rules), rules)
+	}
+	if _, _, err := repository.TrafficRules(); err != nil {
+		return nil, fmt.Errorf("invalid access policies %s: %w", path, err)
+	}
+
+	return ruleSets, nil
+}
@@ -121,6 +121,14 @@ func (r *Repository) IsIdentityAllowed(identity identity.Identity) bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
+	for _, item := range r.items {
+		for _, rule := range item.rules.Deny {
+			if rule.Type == market.AccessPolicyTypeIdentity && identity.Address == rule.Value {
+				return false
+			}
+		}
+	}
+
 	isAllowedByDefault := true
 	for _, item := range r.items {
 		for _, rule := range item.rules.Allow {
@@ -142,12 +150,11 @@ func (r *Repository) HasDNSRules() bool {
 	defer r.lock.RUnlock()
 
 	for _, item := range r.items {
-		for _, rule := range item.rules.Allow {
-			if rule.Type == market.AccessPolicyTypeDNSZone {
-				return true
-			}
-			if rule.Type == market.AccessPolicyTypeDNSHostname {
-				return true
+		for _, rules := range [][]market.AccessRule{item.rules.Allow, item.rules.Deny} {
+			for _, rule := range rules {
+				if isDNSRule(rule) {
+					return true
+				}
 			}
 		}
 	}
@@ -160,18 +167,16 @@ func (r *Repository) IsHostAllowed(host string) bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
+	if r.isHostDenied(host) {
+		return false
+	}
+
 	isAllowedByDefault := true
 	for _, item := range r.items {
 		for _, rule := range item.rules.Allow {
-			if rule.Type == market.AccessPolicyTypeDNSZone {
+			if isDNSRule(rule) {
 				isAllowedByDefault = false
-				if strings.HasSuffix(host, rule.Value) {
-					return true
-				}
-			}
-			if rule.Type == market.AccessPolicyTypeDNSHostname {
-				isAllowedByDefault = false
-				if host == rule.Value {
+				if hostMatches(rule, host) {
 					return true
 				}
 			}
@@ -181,6 +186,39 @@ func (r *Repository) IsHostAllowed(host string) bool {
 	return isAllowedByDefault
 }
 
+// IsHostDenied returns flag if given FQDN host is explicitly denied by rules
+func (r *Repository) IsHostDenied(host string) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.isHostDenied(host)
+}
+
+func (r *Repository) isHostDenied(host string) bool {
+	for _, item := range r.items {
+		for _, rule := range item.rules.Deny {
+			if isDNSRule(rule) && hostMatches(rule, host) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isDNSRule(rule market.AccessRule) bool {
+	return rule.Type == market.AccessPolicyTypeDNSZone || rule.Type == market.AccessPolicyTypeDNSHostname
+}
+
+func hostMatches(rule market.AccessRule, host string) bool {
+	switch rule.Type {
+	case market.AccessPolicyTypeDNSZone:
+		return strings.HasSuffix(host, rule.Value)
+	case market.AccessPolicyTypeDNSHostname:
+		return host == rule.Value
+	}
+	return false
+}
+
 func (r *Repository) findItemFor(policy market.AccessPolicy) (*listItem, error) {
 	for i, item := range r.items {
 		if item.policy == policy {
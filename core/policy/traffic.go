@@ -0,0 +1,326 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const minutesPerDay = 24 * 60
+
+// IsTrafficBlocked returns flag if consumer traffic should be blocked unless it's explicitly allowed by rules.
+// CIDR and port allow rules always block the rest of traffic. DNS allow rules block it only when
+// DNS proxy is running, as hosts are allowed by its answers.
+func (r *Repository) IsTrafficBlocked(dnsProxyRunning bool) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, item := range r.items {
+		for _, rule := range item.rules.Allow {
+			switch {
+			case rule.Type == market.AccessPolicyTypeCIDR, rule.Type == market.AccessPolicyTypePort:
+				return true
+			case isDNSRule(rule) && dnsProxyRunning:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsAvailableAt returns flag if service is available at the given time by schedules of all policies
+func (r *Repository) IsAvailableAt(t time.Time) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	available, err := r.availableMinutes()
+	if err != nil {
+		return false
+	}
+
+	t = t.UTC()
+	return available[t.Hour()*60+t.Minute()]
+}
+
+// TrafficQuota returns the traffic limit of a single consumer session in bytes, 0 if unlimited
+func (r *Repository) TrafficQuota() uint64 {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var quota uint64
+	for _, item := range r.items {
+		if item.rules.Quota == nil || item.rules.Quota.TrafficBytes == 0 {
+			continue
+		}
+		if quota == 0 || item.rules.Quota.TrafficBytes < quota {
+			quota = item.rules.Quota.TrafficBytes
+		}
+	}
+	return quota
+}
+
+// TrafficRules returns firewall rules of allowed and denied consumer traffic
+func (r *Repository) TrafficRules() (allow []firewall.TrafficRule, deny []firewall.TrafficRule, err error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, item := range r.items {
+		for _, rule := range item.rules.Allow {
+			rules, err := trafficRules(rule)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid allow rule of policy %s: %w", item.policy.ID, err)
+			}
+			allow = append(allow, rules...)
+		}
+		for _, rule := range item.rules.Deny {
+			rules, err := trafficRules(rule)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid deny rule of policy %s: %w", item.policy.ID, err)
+			}
+			deny = append(deny, rules...)
+		}
+	}
+
+	available, err := r.availableMinutes()
+	if err != nil {
+		return nil, nil, err
+	}
+	deny = append(deny, unavailableTimeRules(available)...)
+
+	return allow, deny, nil
+}
+
+// ApplyTrafficRules enforces traffic rules of the policies for the consumer network.
+func (r *Repository) ApplyTrafficRules(fw firewall.IncomingTrafficFirewall, network net.IPNet, dnsProxyRunning bool) (firewall.IncomingRuleRemove, error) {
+	allow, deny, err := r.TrafficRules()
+	if err != nil {
+		return nil, err
+	}
+
+	var removers []firewall.IncomingRuleRemove
+	removeAll := func() error {
+		var lastErr error
+		for _, remove := range removers {
+			if err := remove(); err != nil {
+				lastErr = err
+			}
+		}
+		return lastErr
+	}
+	addRule := func(remove firewall.IncomingRuleRemove, err error) error {
+		if err != nil {
+			removeAll()
+			return err
+		}
+		removers = append(removers, remove)
+		return nil
+	}
+
+	for _, rule := range deny {
+		if err := addRule(fw.DenyIncomingTraffic(network, rule)); err != nil {
+			return nil, fmt.Errorf("failed to deny traffic: %w", err)
+		}
+	}
+	if r.IsTrafficBlocked(dnsProxyRunning) {
+		for _, rule := range allow {
+			if err := addRule(fw.AllowIncomingTraffic(network, rule)); err != nil {
+				return nil, fmt.Errorf("failed to allow traffic: %w", err)
+			}
+		}
+		if err := addRule(fw.BlockIncomingTraffic(network)); err != nil {
+			return nil, fmt.Errorf("failed to enable traffic blocking: %w", err)
+		}
+	}
+
+	return removeAll, nil
+}
+
+// ApplyTrafficQuota enforces the traffic quota of the policies for the network of a single consumer session.
+func (r *Repository) ApplyTrafficQuota(fw firewall.IncomingTrafficFirewall, network net.IPNet) (firewall.IncomingRuleRemove, error) {
+	quota := r.TrafficQuota()
+	if quota == 0 {
+		return func() error { return nil }, nil
+	}
+	return fw.LimitIncomingTraffic(network, quota)
+}
+
+// trafficRules converts CIDR and port access rules to firewall rules, other rules are skipped.
+func trafficRules(rule market.AccessRule) ([]firewall.TrafficRule, error) {
+	switch rule.Type {
+	case market.AccessPolicyTypeCIDR:
+		network, err := parseNetwork(rule.Value)
+		if err != nil {
+			return nil, err
+		}
+		return []firewall.TrafficRule{{Destination: network}}, nil
+	case market.AccessPolicyTypePort:
+		return parsePorts(rule.Value)
+	}
+	return nil, nil
+}
+
+func parseNetwork(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IPv4 address: %q", value)
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, err
+	}
+	if network.IP.To4() == nil {
+		return nil, fmt.Errorf("invalid IPv4 network: %q", value)
+	}
+	return network, nil
+}
+
+// parsePorts parses port rule value "[protocol/]port[-port]", rule of any protocol matches both TCP and UDP.
+func parsePorts(value string) ([]firewall.TrafficRule, error) {
+	protocols := []string{"tcp", "udp"}
+	ports := value
+	if idx := strings.Index(value, "/"); idx >= 0 {
+		protocol := strings.ToLower(value[:idx])
+		if protocol != "tcp" && protocol != "udp" {
+			return nil, fmt.Errorf("invalid protocol: %q", value)
+		}
+		protocols = []string{protocol}
+		ports = value[idx+1:]
+	}
+
+	bounds := strings.SplitN(ports, "-", 2)
+	from, err := parsePort(bounds[0])
+	if err != nil {
+		return nil, err
+	}
+	to := from
+	if len(bounds) == 2 {
+		if to, err = parsePort(bounds[1]); err != nil {
+			return nil, err
+		}
+		if to < from {
+			return nil, fmt.Errorf("invalid port range: %q", value)
+		}
+	}
+
+	rules := make([]firewall.TrafficRule, len(protocols))
+	for i, protocol := range protocols {
+		rules[i] = firewall.TrafficRule{Protocol: protocol, PortFrom: from, PortTo: to}
+	}
+	return rules, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port: %q", value)
+	}
+	return port, nil
+}
+
+// availableMinutes returns minutes of the day allowed by schedules of all policies. Should be called with the lock held.
+func (r *Repository) availableMinutes() ([minutesPerDay]bool, error) {
+	var available [minutesPerDay]bool
+	for i := range available {
+		available[i] = true
+	}
+
+	for _, item := range r.items {
+		if len(item.rules.Schedule) == 0 {
+			continue
+		}
+
+		var scheduled [minutesPerDay]bool
+		for _, window := range item.rules.Schedule {
+			from, err := parseTimeOfDay(window.From)
+			if err != nil {
+				return available, fmt.Errorf("invalid schedule of policy %s: %w", item.policy.ID, err)
+			}
+			to, err := parseTimeOfDay(window.To)
+			if err != nil {
+				return available, fmt.Errorf("invalid schedule of policy %s: %w", item.policy.ID, err)
+			}
+
+			// Window of the same start and end covers the whole day.
+			for minute := from; ; {
+				scheduled[minute] = true
+				if minute = (minute + 1) % minutesPerDay; minute == to {
+					break
+				}
+			}
+		}
+
+		for i := range available {
+			available[i] = available[i] && scheduled[i]
+		}
+	}
+
+	return available, nil
+}
+
+// unavailableTimeRules converts unavailable minutes of the day to firewall rules, merging ranges across midnight.
+func unavailableTimeRules(available [minutesPerDay]bool) []firewall.TrafficRule {
+	type timeRange struct{ from, to int }
+
+	var ranges []timeRange
+	for minute := 0; minute < minutesPerDay; minute++ {
+		if available[minute] {
+			continue
+		}
+		if len(ranges) > 0 && ranges[len(ranges)-1].to == minute-1 {
+			ranges[len(ranges)-1].to = minute
+		} else {
+			ranges = append(ranges, timeRange{from: minute, to: minute})
+		}
+	}
+
+	if len(ranges) == 1 && ranges[0].from == 0 && ranges[0].to == minutesPerDay-1 {
+		return []firewall.TrafficRule{{}}
+	}
+	if len(ranges) > 1 && ranges[0].from == 0 && ranges[len(ranges)-1].to == minutesPerDay-1 {
+		ranges[0].from = ranges[len(ranges)-1].from
+		ranges = ranges[:len(ranges)-1]
+	}
+
+	rules := make([]firewall.TrafficRule, len(ranges))
+	for i, r := range ranges {
+		rules[i] = firewall.TrafficRule{
+			TimeStart: fmt.Sprintf("%02d:%02d:00", r.from/60, r.from%60),
+			TimeStop:  fmt.Sprintf("%02d:%02d:59", r.to/60, r.to%60),
+		}
+	}
+	return rules
+}
+
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of the day: %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
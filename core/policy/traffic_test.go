@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package policy
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	policyTraffic      = market.AccessPolicy{ID: "traffic", Source: "local"}
+	policyTrafficRules = market.AccessPolicyRuleSet{
+		ID: "traffic",
+		Allow: []market.AccessRule{
+			{Type: market.AccessPolicyTypeCIDR, Value: "10.0.0.0/8"},
+			{Type: market.AccessPolicyTypePort, Value: "tcp/443"},
+		},
+		Deny: []market.AccessRule{
+			{Type: market.AccessPolicyTypeIdentity, Value: "0x2"},
+			{Type: market.AccessPolicyTypeCIDR, Value: "10.0.0.1"},
+			{Type: market.AccessPolicyTypePort, Value: "5000-6000"},
+		},
+		Schedule: []market.AccessTimeWindow{{From: "08:00", To: "18:00"}},
+		Quota:    &market.AccessQuota{TrafficBytes: 1000},
+	}
+)
+
+func Test_Repository_TrafficRules(t *testing.T) {
+	repo := NewRepository()
+	repo.SetPolicyRules(policyTraffic, policyTrafficRules)
+
+	allow, deny, err := repo.TrafficRules()
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]firewall.TrafficRule{
+			{Destination: &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}},
+			{Protocol: "tcp", PortFrom: 443, PortTo: 443},
+		},
+		allow,
+	)
+	assert.Equal(
+		t,
+		[]firewall.TrafficRule{
+			{Destination: &net.IPNet{IP: net.IP{10, 0, 0, 1}, Mask: net.CIDRMask(32, 32)}},
+			{Protocol: "tcp", PortFrom: 5000, PortTo: 6000},
+			{Protocol: "udp", PortFrom: 5000, PortTo: 6000},
+			{TimeStart: "18:00:00", TimeStop: "07:59:59"},
+		},
+		deny,
+	)
+	assert.True(t, repo.IsTrafficBlocked(false))
+	assert.Equal(t, uint64(1000), repo.TrafficQuota())
+	assert.False(t, repo.IsIdentityAllowed(identity.FromAddress("0x2")))
+	assert.True(t, repo.IsIdentityAllowed(identity.FromAddress("0x3")))
+}
+
+func Test_Repository_IsTrafficBlocked(t *testing.T) {
+	repo := NewRepository()
+	assert.False(t, repo.IsTrafficBlocked(true))
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		Allow: []market.AccessRule{{Type: market.AccessPolicyTypeIdentity, Value: "0x1"}},
+	})
+	assert.False(t, repo.IsTrafficBlocked(true))
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		Allow: []market.AccessRule{{Type: market.AccessPolicyTypeDNSHostname, Value: "ipinfo.io"}},
+	})
+	assert.True(t, repo.IsTrafficBlocked(true))
+	assert.False(t, repo.IsTrafficBlocked(false), "traffic is not blocked by DNS rules without DNS proxy")
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		Deny: []market.AccessRule{{Type: market.AccessPolicyTypeCIDR, Value: "10.0.0.1"}},
+	})
+	assert.False(t, repo.IsTrafficBlocked(true))
+}
+
+func Test_Repository_TrafficRulesInvalid(t *testing.T) {
+	for _, value := range []string{"tcp/0", "icmp/1", "2000-1000", "port"} {
+		repo := NewRepository()
+		repo.SetPolicyRules(policyTraffic, market.AccessPolicyRuleSet{
+			Allow: []market.AccessRule{{Type: market.AccessPolicyTypePort, Value: value}},
+		})
+		_, _, err := repo.TrafficRules()
+		assert.Error(t, err, value)
+	}
+
+	for _, value := range []string{"10.0.0.0/33", "::1", "host"} {
+		repo := NewRepository()
+		repo.SetPolicyRules(policyTraffic, market.AccessPolicyRuleSet{
+			Deny: []market.AccessRule{{Type: market.AccessPolicyTypeCIDR, Value: value}},
+		})
+		_, _, err := repo.TrafficRules()
+		assert.Error(t, err, value)
+	}
+}
+
+func Test_Repository_IsAvailableAt(t *testing.T) {
+	repo := NewRepository()
+	assert.True(t, repo.IsAvailableAt(time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC)))
+	_, deny, err := repo.TrafficRules()
+	assert.NoError(t, err)
+	assert.Empty(t, deny)
+
+	repo.SetPolicyRules(policyOne, market.AccessPolicyRuleSet{
+		Schedule: []market.AccessTimeWindow{{From: "22:00", To: "06:00"}, {From: "12:00", To: "13:00"}},
+	})
+	assert.True(t, repo.IsAvailableAt(time.Date(2020, 1, 1, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, repo.IsAvailableAt(time.Date(2020, 1, 1, 3, 0, 0, 0, time.UTC)))
+	assert.True(t, repo.IsAvailableAt(time.Date(2020, 1, 1, 12, 59, 0, 0, time.UTC)))
+	assert.False(t, repo.IsAvailableAt(time.Date(2020, 1, 1, 13, 0, 0, 0, time.UTC)))
+	_, deny, err = repo.TrafficRules()
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]firewall.TrafficRule{
+			{TimeStart: "06:00:00", TimeStop: "11:59:59"},
+			{TimeStart: "13:00:00", TimeStop: "21:59:59"},
+		},
+		deny,
+	)
+
+	// Schedules of all policies should be satisfied.
+	repo.SetPolicyRules(policyTwo, market.AccessPolicyRuleSet{
+		Schedule: []market.AccessTimeWindow{{From: "08:00", To: "12:00"}},
+	})
+	assert.False(t, repo.IsAvailableAt(time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC)))
+	_, deny, err = repo.TrafficRules()
+	assert.NoError(t, err)
+	assert.Equal(t, []firewall.TrafficRule{{}}, deny)
+}
+
+func Test_LoadRuleSets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "policies.json")
+	err = ioutil.WriteFile(path, []byte(`[{
+		"id": "local",
+		"allow": [{"type": "dns_zone", "value": "example.com"}],
+		"deny": [{"type": "port", "value": "udp/53"}],
+		"schedule": [{"from": "08:00", "to": "18:00"}],
+		"quota": {"traffic_bytes": 1000}
+	}]`), 0600)
+	assert.NoError(t, err)
+
+	ruleSets, err := LoadRuleSets(path)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]market.AccessPolicyRuleSet{{
+			ID:       "local",
+			Allow:    []market.AccessRule{{Type: market.AccessPolicyTypeDNSZone, Value: "example.com"}},
+			Deny:     []market.AccessRule{{Type: market.AccessPolicyTypePort, Value: "udp/53"}},
+			Schedule: []market.AccessTimeWindow{{From: "08:00", To: "18:00"}},
+			Quota:    &market.AccessQuota{TrafficBytes: 1000},
+		}},
+		ruleSets,
+	)
+
+	err = ioutil.WriteFile(path, []byte(`[{"id": "local", "schedule": [{"from": "8", "to": "18:00"}]}]`), 0600)
+	assert.NoError(t, err)
+	_, err = LoadRuleSets(path)
+	assert.Error(t, err)
+
+	_, err = LoadRuleSets(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
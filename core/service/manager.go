@@ -67,9 +67,11 @@ func NewManager(
 	discoveryFactory DiscoveryFactory,
 	eventPublisher Publisher,
 	policyOracle *policy.Oracle,
+	localPolicies []market.AccessPolicyRuleSet,
 	p2pListener p2p.Listener,
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager,
 	statusStorage connectivity.StatusStorage,
+	hermesIDs []string,
 ) *Manager {
 	return &Manager{
 		serviceRegistry:  serviceRegistry,
@@ -77,9 +79,11 @@ func NewManager(
 		discoveryFactory: discoveryFactory,
 		eventPublisher:   eventPublisher,
 		policyOracle:     policyOracle,
+		localPolicies:    localPolicies,
 		p2pListener:      p2pListener,
 		sessionManager:   sessionManager,
 		statusStorage:    statusStorage,
+		hermesIDs:        hermesIDs,
 	}
 }
 
@@ -91,10 +95,12 @@ type Manager struct {
 	discoveryFactory DiscoveryFactory
 	eventPublisher   Publisher
 	policyOracle     *policy.Oracle
+	localPolicies    []market.AccessPolicyRuleSet
 
 	p2pListener    p2p.Listener
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager
 	statusStorage  connectivity.StatusStorage
+	hermesIDs      []string
 }
 
 // Start starts an instance of the given service type if knows one in service registry.
@@ -117,8 +123,13 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		}
 		proposal.SetAccessPolicies(&policies)
 	}
+	// Local policies are not advertised in the proposal, as consumers are not able to fetch them.
+	for _, rules := range manager.localPolicies {
+		policyRules.SetPolicyRules(policy.LocalPolicy(rules), rules)
+	}
 
-	proposal.SetProviderContacts(providerID, market.ContactList{manager.p2pListener.GetContact()})
+	proposal.SetProviderContacts(providerID, manager.p2pListener.GetContacts())
+	proposal.SetHermesIDs(manager.hermesIDs)
 
 	id, err = generateID()
 	if err != nil {
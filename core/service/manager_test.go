@@ -57,7 +57,8 @@ func TestManager_StartRemovesServiceFromPoolIfServiceCrashes(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		nil,
+		&mockP2PListener{}, nil, nil, nil,
 	)
 	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
 	assert.Nil(t, err)
@@ -81,7 +82,8 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		nil,
+		&mockP2PListener{}, nil, nil, nil,
 	)
 	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
 	assert.Nil(t, err)
@@ -107,7 +109,8 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 		discoveryFactory,
 		eventBus,
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		nil,
+		&mockP2PListener{}, nil, nil, nil,
 	)
 
 	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
@@ -148,8 +151,8 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 type mockP2PListener struct {
 }
 
-func (m mockP2PListener) GetContact() market.Contact {
-	return market.Contact{}
+func (m mockP2PListener) GetContacts() market.ContactList {
+	return market.ContactList{}
 }
 
 func (m mockP2PListener) Listen(providerID identity.Identity, serviceType string, channelHandler func(ch p2p.Channel)) (func(), error) {
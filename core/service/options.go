@@ -17,5 +17,78 @@
 
 package service
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Options represents any type of options for pluggable service
 type Options interface{}
+
+// QuotaOptions is implemented by service options which limit usage of the service by a single consumer.
+type QuotaOptions interface {
+	ConsumerQuotas() Quotas
+}
+
+// Quotas limits usage of a service by a single consumer identity, zero limit means it is unlimited.
+type Quotas struct {
+	// DataPerDay is the amount of bytes consumer is allowed to transfer per day.
+	DataPerDay uint64
+	// TimePerDay is the total duration of consumer sessions allowed per day.
+	TimePerDay time.Duration
+	// Sessions is the number of concurrent sessions allowed per consumer.
+	Sessions int
+}
+
+// IsUnlimited checks if none of the quotas are set.
+func (q Quotas) IsUnlimited() bool {
+	return q.DataPerDay == 0 && q.TimePerDay == 0 && q.Sessions == 0
+}
+
+type quotasJSON struct {
+	DataPerDay uint64 `json:"data_per_day,omitempty"`
+	TimePerDay string `json:"time_per_day,omitempty"`
+	Sessions   int    `json:"sessions,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface to provide human readable configuration.
+func (q Quotas) MarshalJSON() ([]byte, error) {
+	quotas := quotasJSON{
+		DataPerDay: q.DataPerDay,
+		Sessions:   q.Sessions,
+	}
+	if q.TimePerDay > 0 {
+		quotas.TimePerDay = q.TimePerDay.String()
+	}
+	return json.Marshal(quotas)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
+func (q *Quotas) UnmarshalJSON(data []byte) error {
+	var quotas quotasJSON
+	if err := json.Unmarshal(data, &quotas); err != nil {
+		return err
+	}
+
+	var timePerDay time.Duration
+	if quotas.TimePerDay != "" {
+		var err error
+		if timePerDay, err = time.ParseDuration(quotas.TimePerDay); err != nil {
+			return err
+		}
+	}
+
+	*q = Quotas{
+		DataPerDay: quotas.DataPerDay,
+		TimePerDay: timePerDay,
+		Sessions:   quotas.Sessions,
+	}
+	return nil
+}
+
+func consumerQuotas(options Options) Quotas {
+	if quotaOptions, ok := options.(QuotaOptions); ok {
+		return quotaOptions.ConsumerQuotas()
+	}
+	return Quotas{}
+}
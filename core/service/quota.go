@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrSessionQuotaExceeded is returned when consumer has more concurrent sessions than allowed.
+	ErrSessionQuotaExceeded = errors.New("concurrent sessions quota exceeded")
+	// ErrDataQuotaExceeded is returned when consumer has transferred more data today than allowed.
+	ErrDataQuotaExceeded = errors.New("daily data quota exceeded")
+	// ErrTimeQuotaExceeded is returned when consumer sessions lasted longer today than allowed.
+	ErrTimeQuotaExceeded = errors.New("daily time quota exceeded")
+)
+
+type quotaKey struct {
+	consumerID  identity.Identity
+	serviceType string
+}
+
+type quotaUsage struct {
+	day      time.Time
+	data     uint64
+	duration time.Duration
+}
+
+type quotaSession struct {
+	key         quotaKey
+	startedAt   time.Time
+	transferred uint64
+}
+
+// QuotaTracker tracks daily usage of services by every consumer, so quotas could be applied to all of their sessions.
+type QuotaTracker struct {
+	now func() time.Time
+
+	lock     sync.Mutex
+	sessions map[session.ID]*quotaSession
+	usage    map[quotaKey]*quotaUsage
+}
+
+// NewQuotaTracker returns new instance of quota tracker.
+func NewQuotaTracker() *QuotaTracker {
+	return &QuotaTracker{
+		now:      time.Now,
+		sessions: make(map[session.ID]*quotaSession),
+		usage:    make(map[quotaKey]*quotaUsage),
+	}
+}
+
+// Subscribe subscribes to data transfer events of the provided sessions.
+func (t *QuotaTracker) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(sevent.AppTopicDataTransferred, t.consumeDataTransferredEvent)
+}
+
+func (t *QuotaTracker) track(sess *Session) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.sessions[sess.ID] = &quotaSession{
+		key:       quotaKey{consumerID: sess.ConsumerID, serviceType: sess.Proposal.ServiceType},
+		startedAt: t.now(),
+	}
+}
+
+func (t *QuotaTracker) untrack(sessionID session.ID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	sess, ok := t.sessions[sessionID]
+	if !ok {
+		return
+	}
+	delete(t.sessions, sessionID)
+
+	now := t.now()
+	t.usageOf(sess.key, now).duration += sessionDurationToday(sess, now)
+
+	// Forget the usage of previous days, quotas are applied daily.
+	today := dayOf(now)
+	for key, usage := range t.usage {
+		if usage.day.Before(today) {
+			delete(t.usage, key)
+		}
+	}
+}
+
+func (t *QuotaTracker) check(consumerID identity.Identity, serviceType string, quotas Quotas) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	key := quotaKey{consumerID: consumerID, serviceType: serviceType}
+	usage := t.usageOf(key, now)
+
+	if quotas.DataPerDay > 0 && usage.data >= quotas.DataPerDay {
+		return ErrDataQuotaExceeded
+	}
+
+	if quotas.TimePerDay > 0 {
+		duration := usage.duration
+		for _, sess := range t.sessions {
+			if sess.key == key {
+				duration += sessionDurationToday(sess, now)
+			}
+		}
+		if duration >= quotas.TimePerDay {
+			return ErrTimeQuotaExceeded
+		}
+	}
+
+	return nil
+}
+
+func (t *QuotaTracker) consumeDataTransferredEvent(e sevent.AppEventDataTransferred) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	sess, ok := t.sessions[session.ID(e.ID)]
+	if !ok {
+		return
+	}
+
+	// Statistics of the session are cumulative, only the growth is added to the daily usage.
+	transferred := e.Up + e.Down
+	if transferred <= sess.transferred {
+		return
+	}
+	t.usageOf(sess.key, t.now()).data += transferred - sess.transferred
+	sess.transferred = transferred
+}
+
+func (t *QuotaTracker) usageOf(key quotaKey, now time.Time) *quotaUsage {
+	today := dayOf(now)
+
+	usage, ok := t.usage[key]
+	if !ok || !usage.day.Equal(today) {
+		usage = &quotaUsage{day: today}
+		t.usage[key] = usage
+	}
+	return usage
+}
+
+// sessionDurationToday returns how long the session lasted today.
+func sessionDurationToday(sess *quotaSession, now time.Time) time.Duration {
+	startedAt := sess.startedAt
+	if today := dayOf(now); startedAt.Before(today) {
+		startedAt = today
+	}
+	return now.Sub(startedAt)
+}
+
+func dayOf(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
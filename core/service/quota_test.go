@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	sevent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestQuotaTracker(now *time.Time) *QuotaTracker {
+	tracker := NewQuotaTracker()
+	tracker.now = func() time.Time {
+		return *now
+	}
+	return tracker
+}
+
+func newQuotaSession(id string) *Session {
+	return &Session{
+		ID:         session.ID(id),
+		ConsumerID: consumerID,
+		Proposal:   market.ServiceProposal{ServiceType: "mockservice"},
+	}
+}
+
+func TestQuotaTracker_DataPerDay(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newTestQuotaTracker(&now)
+	quotas := Quotas{DataPerDay: 100}
+
+	tracker.track(newQuotaSession("1"))
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "1", Up: 20, Down: 30})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "unknown", Up: 100, Down: 100})
+	assert.NoError(t, tracker.check(consumerID, "mockservice", quotas))
+
+	tracker.untrack("1")
+	tracker.track(newQuotaSession("2"))
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "2", Up: 10, Down: 10})
+	tracker.consumeDataTransferredEvent(sevent.AppEventDataTransferred{ID: "2", Up: 20, Down: 30})
+	assert.Equal(t, ErrDataQuotaExceeded, tracker.check(consumerID, "mockservice", quotas))
+	assert.NoError(t, tracker.check(identity.FromAddress("0x2"), "mockservice", quotas))
+	assert.NoError(t, tracker.check(consumerID, "otherservice", quotas))
+
+	now = now.Add(24 * time.Hour)
+	assert.NoError(t, tracker.check(consumerID, "mockservice", quotas))
+}
+
+func TestQuotaTracker_TimePerDay(t *testing.T) {
+	now := time.Date(2020, 10, 1, 21, 0, 0, 0, time.UTC)
+	tracker := newTestQuotaTracker(&now)
+	quotas := Quotas{TimePerDay: 90 * time.Minute}
+
+	tracker.track(newQuotaSession("1"))
+	now = now.Add(30 * time.Minute)
+	tracker.untrack("1")
+	assert.NoError(t, tracker.check(consumerID, "mockservice", quotas))
+
+	tracker.track(newQuotaSession("2"))
+	now = now.Add(time.Hour)
+	assert.Equal(t, ErrTimeQuotaExceeded, tracker.check(consumerID, "mockservice", quotas))
+
+	// Only the part of the session lasting today is counted on the next day.
+	now = time.Date(2020, 10, 2, 1, 0, 0, 0, time.UTC)
+	assert.NoError(t, tracker.check(consumerID, "mockservice", quotas))
+	now = now.Add(30 * time.Minute)
+	assert.Equal(t, ErrTimeQuotaExceeded, tracker.check(consumerID, "mockservice", quotas))
+}
+
+func TestQuotaTracker_ForgetsPreviousDays(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newTestQuotaTracker(&now)
+
+	tracker.track(newQuotaSession("1"))
+	tracker.untrack("1")
+	assert.Len(t, tracker.usage, 1)
+
+	now = now.Add(24 * time.Hour)
+	tracker.track(&Session{ID: "2", ConsumerID: identity.FromAddress("0x2")})
+	tracker.untrack("2")
+	assert.Len(t, tracker.usage, 1)
+	assert.Len(t, tracker.sessions, 0)
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"net"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/connectivity"
 	sevent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
@@ -74,6 +76,8 @@ type KeepAliveConfig struct {
 // Config contains common configuration options for session manager.
 type Config struct {
 	KeepAlive KeepAliveConfig
+	// QuotaCheckInterval is how often daily quotas of the running session are checked.
+	QuotaCheckInterval time.Duration
 }
 
 // DefaultConfig returns default params.
@@ -84,6 +88,7 @@ func DefaultConfig() Config {
 			SendTimeout:     5 * time.Second,
 			MaxSendErrCount: 5,
 		},
+		QuotaCheckInterval: 10 * time.Second,
 	}
 }
 
@@ -113,6 +118,21 @@ type PaymentEngine interface {
 	Stop()
 }
 
+// PaymentAgreement describes the agreement between consumer and provider on payments of a session.
+type PaymentAgreement struct {
+	ID    *big.Int
+	Total *big.Int
+}
+
+// ResumablePaymentEngine is a payment engine which can continue the agreement of an interrupted session.
+type ResumablePaymentEngine interface {
+	PaymentEngine
+	// Agreement returns current agreement with the total amount paid by consumer so far.
+	Agreement() PaymentAgreement
+	// Resume continues the given agreement instead of starting a new one, must be called before Start.
+	Resume(agreement PaymentAgreement)
+}
+
 // NATEventGetter lets us access the last known traversal event
 type NATEventGetter interface {
 	LastEvent() *event.Event
@@ -122,6 +142,7 @@ type NATEventGetter interface {
 func NewSessionManager(
 	service *Instance,
 	sessionStorage *SessionPool,
+	quotaTracker *QuotaTracker,
 	paymentEngineFactory PaymentEngineFactory,
 	natEventGetter NATEventGetter,
 	publisher publisher,
@@ -131,6 +152,7 @@ func NewSessionManager(
 	return &SessionManager{
 		service:              service,
 		sessionStorage:       sessionStorage,
+		quotaTracker:         quotaTracker,
 		natEventGetter:       natEventGetter,
 		publisher:            publisher,
 		paymentEngineFactory: paymentEngineFactory,
@@ -144,6 +166,7 @@ func NewSessionManager(
 type SessionManager struct {
 	service              *Instance
 	sessionStorage       *SessionPool
+	quotaTracker         *QuotaTracker
 	paymentEngineFactory PaymentEngineFactory
 	paymentEngineChan    chan crypto.ExchangeMessage
 	natEventGetter       NATEventGetter
@@ -173,6 +196,8 @@ func (manager *SessionManager) Start(request *pb.SessionRequest) (_ pb.SessionRe
 		log.Debug().Msgf("Provider connection trace: %s", traceResult)
 	}()
 
+	manager.closeResumedSession(session)
+
 	if err = manager.startSession(session); err != nil {
 		return pb.SessionResponse{}, err
 	}
@@ -205,6 +230,11 @@ func (manager *SessionManager) startSession(session *Session) error {
 		return err
 	}
 
+	quotas := consumerQuotas(manager.service.Options)
+	if err := manager.checkQuotas(session, quotas); err != nil {
+		return err
+	}
+
 	manager.clearStaleSession(session.ConsumerID, manager.service.Type)
 
 	manager.sessionStorage.Add(session)
@@ -213,7 +243,16 @@ func (manager *SessionManager) startSession(session *Session) error {
 		return nil
 	})
 
+	manager.quotaTracker.track(session)
+	session.addCleanup(func() error {
+		manager.quotaTracker.untrack(session.ID)
+		return nil
+	})
+
 	go manager.keepAliveLoop(session, manager.channel)
+	if quotas.DataPerDay > 0 || quotas.TimePerDay > 0 {
+		go manager.quotaLoop(session, quotas)
+	}
 
 	return nil
 }
@@ -227,9 +266,85 @@ func (manager *SessionManager) validateSession(session *Session) error {
 		return fmt.Errorf("consumer identity is not allowed: %s", session.ConsumerID.Address)
 	}
 
+	if !manager.service.Policies().IsAvailableAt(time.Now()) {
+		return errors.New("service is not available at this time by access policies")
+	}
+
 	return nil
 }
 
+// checkQuotas checks if consumer is still allowed to start the session. Sessions of the same service are not counted
+// against the concurrent sessions quota, as they are replaced by the new session.
+func (manager *SessionManager) checkQuotas(sess *Session, quotas Quotas) error {
+	if quotas.Sessions > 0 {
+		var sessions int
+		for _, s := range manager.sessionStorage.GetAll() {
+			if s.ConsumerID == sess.ConsumerID && s.Proposal.ServiceType != manager.service.Type {
+				sessions++
+			}
+		}
+		if sessions >= quotas.Sessions {
+			return ErrSessionQuotaExceeded
+		}
+	}
+
+	return manager.quotaTracker.check(sess.ConsumerID, manager.service.Type, quotas)
+}
+
+// quotaLoop closes the session once consumer exceeds daily quotas, letting consumer know the reason.
+func (manager *SessionManager) quotaLoop(sess *Session, quotas Quotas) {
+	for {
+		select {
+		case <-sess.Done():
+			return
+		case <-time.After(manager.config.QuotaCheckInterval):
+			err := manager.quotaTracker.check(sess.ConsumerID, manager.service.Type, quotas)
+			if err == nil {
+				continue
+			}
+
+			log.Info().Err(err).Msgf("Closing session %s of consumer %s", sess.ID, sess.ConsumerID.Address)
+			if err := manager.sendSessionStatus(sess, connectivity.StatusSessionQuotaExceeded, err); err != nil {
+				log.Warn().Err(err).Msgf("Could not notify consumer about exceeded quota. SessionID=%s", sess.ID)
+			}
+			sess.Close()
+			return
+		}
+	}
+}
+
+// sendSessionStatus sends session connectivity status to consumer.
+func (manager *SessionManager) sendSessionStatus(sess *Session, code connectivity.StatusCode, errDetails error) error {
+	sessionStatus := &pb.SessionStatus{
+		ConsumerID: sess.ConsumerID.Address,
+		SessionID:  string(sess.ID),
+		Code:       uint32(code),
+		Message:    errDetails.Error(),
+	}
+	log.Debug().Msgf("Sending session status P2P message to %q: %s", p2p.TopicSessionStatus, sessionStatus.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), manager.config.KeepAlive.SendTimeout)
+	defer cancel()
+	_, err := manager.channel.Send(ctx, p2p.TopicSessionStatus, p2p.ProtoMessage(sessionStatus))
+	return err
+}
+
+// closeResumedSession closes the session consumer wants to resume, so its payment agreement is released before
+// the new session starts. Stale sessions are closed in background otherwise.
+func (manager *SessionManager) closeResumedSession(sess *Session) {
+	resume := sess.request.GetResume()
+	if resume == nil {
+		return
+	}
+
+	resumed, found := manager.sessionStorage.Find(session.ID(resume.GetSessionID()))
+	if !found || resumed.ConsumerID != sess.ConsumerID {
+		return
+	}
+	log.Info().Msgf("Closing session %s resumed by session %s", resumed.ID, sess.ID)
+	resumed.Close()
+}
+
 func (manager *SessionManager) clearStaleSession(consumerID identity.Identity, serviceType string) {
 	// Reading stale session before starting the clean up in goroutine.
 	// This is required to make sure we are not cleaning the newly created session.
@@ -271,9 +386,19 @@ func (manager *SessionManager) paymentLoop(session *Session) error {
 		return err
 	}
 
+	resumable, isResumable := engine.(ResumablePaymentEngine)
+	if isResumable {
+		manager.resumeAgreement(session, resumable)
+	}
+
 	// stop the balance tracker once the session is finished
 	session.addCleanup(func() error {
 		engine.Stop()
+		if isResumable {
+			if agreement := resumable.Agreement(); agreement.Total.Sign() > 0 {
+				manager.sessionStorage.KeepAgreement(session, agreement)
+			}
+		}
 		return nil
 	})
 
@@ -293,6 +418,28 @@ func (manager *SessionManager) paymentLoop(session *Session) error {
 	return nil
 }
 
+func (manager *SessionManager) resumeAgreement(sess *Session, engine ResumablePaymentEngine) {
+	resume := sess.request.GetResume()
+	if resume == nil {
+		return
+	}
+
+	agreementID, ok := new(big.Int).SetString(resume.GetAgreementID(), bigIntBase)
+	if !ok {
+		log.Warn().Msgf("Invalid agreement ID %q to resume, starting a new agreement", resume.GetAgreementID())
+		return
+	}
+
+	agreement, ok := manager.sessionStorage.TakeAgreement(session.ID(resume.GetSessionID()), sess, agreementID)
+	if !ok {
+		log.Info().Msgf("Session %s can not be resumed, starting a new agreement", resume.GetSessionID())
+		return
+	}
+
+	log.Info().Msgf("Resuming agreement %v of session %s, already paid %v", agreement.ID, resume.GetSessionID(), agreement.Total)
+	engine.Resume(agreement)
+}
+
 func (manager *SessionManager) providerService(session *Session, channel p2p.Channel) (pb.SessionResponse, error) {
 	trace := session.tracer.StartStage("Provider session create (configure)")
 	defer session.tracer.EndStage(trace)
@@ -346,7 +493,13 @@ func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel)
 			if err := manager.sendKeepAlivePing(channel, sess.ID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sess.ID)
 				errCount++
-				if errCount == manager.config.KeepAlive.MaxSendErrCount {
+				closing := errCount == manager.config.KeepAlive.MaxSendErrCount
+				manager.publisher.Publish(p2p.AppTopicKeepAliveFailed, p2p.AppEventKeepAliveFailed{
+					SessionID: string(sess.ID),
+					Role:      "provider",
+					Closed:    closing,
+				})
+				if closing {
 					log.Error().Msgf("Max p2p keepalive err count reached, closing p2p channel. SessionID=%s", sess.ID)
 					channel.Close()
 					return
@@ -20,7 +20,9 @@ package service
 import (
 	"context"
 	"errors"
+	"math/big"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,6 +35,8 @@ import (
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/connectivity"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/trace"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -78,12 +82,30 @@ func (m mockBalanceTracker) WaitFirstInvoice(time.Duration) error {
 
 type mockP2PChannel struct {
 	tracer *trace.Tracer
+
+	lock sync.Mutex
+	sent map[string]*p2p.Message
 }
 
-func (m *mockP2PChannel) Send(_ context.Context, _ string, _ *p2p.Message) (*p2p.Message, error) {
+func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.sent == nil {
+		m.sent = make(map[string]*p2p.Message)
+	}
+	m.sent[topic] = msg
 	return nil, nil
 }
 
+func (m *mockP2PChannel) sentMessage(topic string) (*p2p.Message, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	msg, ok := m.sent[topic]
+	return msg, ok
+}
+
 func (m *mockP2PChannel) Handle(topic string, handler p2p.HandlerFunc) {
 }
 
@@ -235,6 +257,61 @@ func TestManager_Start_Second_Session_Destroy_Stale_Session(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond, "Waiting for session destroy")
 }
 
+type mockResumablePaymentEngine struct {
+	mockBalanceTracker
+	agreement PaymentAgreement
+	resumed   *PaymentAgreement
+}
+
+func (m *mockResumablePaymentEngine) Agreement() PaymentAgreement {
+	return m.agreement
+}
+
+func (m *mockResumablePaymentEngine) Resume(agreement PaymentAgreement) {
+	m.resumed = &agreement
+}
+
+func TestManager_Start_ResumesAgreementOfInterruptedSession(t *testing.T) {
+	interrupted := &mockResumablePaymentEngine{agreement: PaymentAgreement{ID: big.NewInt(5), Total: big.NewInt(100)}}
+	resuming := &mockResumablePaymentEngine{}
+	engines := []PaymentEngine{interrupted, resuming}
+
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := NewSessionManager(
+		currentService,
+		sessionStore,
+		NewQuotaTracker(),
+		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage) (PaymentEngine, error) {
+			engine := engines[0]
+			engines = engines[1:]
+			return engine, nil
+		},
+		&MockNatEventTracker{},
+		publisher,
+		&mockP2PChannel{tracer: trace.NewTracer("Provider connect")},
+		DefaultConfig(),
+	)
+	sessionRequest := &pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	}
+
+	response, err := manager.Start(sessionRequest)
+	assert.NoError(t, err)
+
+	sessionRequest.Resume = &pb.SessionResume{SessionID: response.ID, AgreementID: "5"}
+	_, err = manager.Start(sessionRequest)
+	assert.NoError(t, err)
+
+	_, found := sessionStore.Find(session.ID(response.ID))
+	assert.False(t, found)
+	assert.Equal(t, &interrupted.agreement, resuming.resumed)
+}
+
 func TestManager_Start_RejectsUnknownProposal(t *testing.T) {
 	publisher := mocks.NewEventBus()
 	sessionStore := NewSessionPool(mocks.NewEventBus())
@@ -272,6 +349,91 @@ func TestManager_Start_RejectsUnknownProposal(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+type mockQuotaOptions struct {
+	quotas Quotas
+}
+
+func (o mockQuotaOptions) ConsumerQuotas() Quotas {
+	return o.quotas
+}
+
+func newQuotaService(quotas Quotas) *Instance {
+	return NewInstance(
+		identity.FromAddress(currentProposal.ProviderID),
+		currentProposal.ServiceType,
+		mockQuotaOptions{quotas: quotas},
+		currentProposal,
+		servicestate.Running,
+		&mockService{},
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+}
+
+func TestManager_Start_RejectsSessionsOverQuota(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(newQuotaService(Quotas{Sessions: 1}), sessionStore, publisher, &mockBalanceTracker{})
+
+	otherProposal := market.ServiceProposal{ServiceType: "otherservice"}
+	otherService := NewInstance(identity.FromAddress(""), otherProposal.ServiceType, struct{}{}, otherProposal, servicestate.Running, &mockService{}, policy.NewRepository(), &mockDiscovery{})
+	otherSession, err := NewSession(otherService, &pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}}, trace.NewTracer(""))
+	assert.NoError(t, err)
+	sessionStore.Add(otherSession)
+
+	_, err = manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.Equal(t, ErrSessionQuotaExceeded, err)
+	assert.Len(t, sessionStore.GetAll(), 1)
+}
+
+func TestManager_Start_ClosesSessionOverQuota(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	channel := &mockP2PChannel{tracer: trace.NewTracer("Provider connect")}
+	config := DefaultConfig()
+	config.QuotaCheckInterval = 10 * time.Millisecond
+	manager := NewSessionManager(
+		newQuotaService(Quotas{TimePerDay: time.Millisecond}),
+		sessionStore,
+		NewQuotaTracker(),
+		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage) (PaymentEngine, error) {
+			return &mockBalanceTracker{}, nil
+		},
+		&MockNatEventTracker{},
+		publisher,
+		channel,
+		config,
+	)
+
+	response, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, found := sessionStore.Find(session.ID(response.ID))
+		return !found
+	}, 2*time.Second, 10*time.Millisecond)
+
+	msg, ok := channel.sentMessage(p2p.TopicSessionStatus)
+	assert.True(t, ok)
+	var status pb.SessionStatus
+	assert.NoError(t, msg.UnmarshalProto(&status))
+	assert.Equal(t, response.ID, status.SessionID)
+	assert.Equal(t, uint32(connectivity.StatusSessionQuotaExceeded), status.Code)
+	assert.Equal(t, ErrTimeQuotaExceeded.Error(), status.Message)
+}
+
 type MockNatEventTracker struct {
 }
 
@@ -337,6 +499,7 @@ func newManager(service *Instance, sessions *SessionPool, publisher publisher, p
 	return NewSessionManager(
 		service,
 		sessions,
+		NewQuotaTracker(),
 		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage) (PaymentEngine, error) {
 			return paymentEngine, nil
 		},
@@ -18,7 +18,9 @@
 package service
 
 import (
+	"math/big"
 	"sync"
+	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session"
@@ -28,18 +30,30 @@ import (
 // NewSessionPool initiates new session storage
 func NewSessionPool(publisher publisher) *SessionPool {
 	sm := &SessionPool{
-		sessions:  make(map[session.ID]*Session),
-		lock:      sync.Mutex{},
-		publisher: publisher,
+		sessions:   make(map[session.ID]*Session),
+		agreements: make(map[session.ID]closedAgreement),
+		lock:       sync.Mutex{},
+		publisher:  publisher,
 	}
 	return sm
 }
 
 // SessionPool maintains all current sessions in memory
 type SessionPool struct {
-	sessions  map[session.ID]*Session
-	lock      sync.Mutex
-	publisher publisher
+	sessions   map[session.ID]*Session
+	agreements map[session.ID]closedAgreement
+	lock       sync.Mutex
+	publisher  publisher
+}
+
+// resumableAgreementTTL is how long payment agreement of a closed session can be resumed by the consumer.
+const resumableAgreementTTL = 30 * time.Minute
+
+type closedAgreement struct {
+	consumerID  identity.Identity
+	serviceType string
+	agreement   PaymentAgreement
+	closedAt    time.Time
 }
 
 // Add puts given session to storage and publishes a creation event.
@@ -121,3 +135,44 @@ func (sp *SessionPool) RemoveForService(serviceID string) {
 		}
 	}
 }
+
+// KeepAgreement remembers payment agreement of the closed session, so consumer could resume it in a new session.
+func (sp *SessionPool) KeepAgreement(instance *Session, agreement PaymentAgreement) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	now := time.Now()
+	for id, closed := range sp.agreements {
+		if now.Sub(closed.closedAt) > resumableAgreementTTL {
+			delete(sp.agreements, id)
+		}
+	}
+
+	sp.agreements[instance.ID] = closedAgreement{
+		consumerID:  instance.ConsumerID,
+		serviceType: instance.Proposal.ServiceType,
+		agreement:   agreement,
+		closedAt:    now,
+	}
+}
+
+// TakeAgreement returns payment agreement of the closed session, if it can be resumed by the given session.
+// Agreement can be taken only once.
+func (sp *SessionPool) TakeAgreement(id session.ID, instance *Session, agreementID *big.Int) (PaymentAgreement, bool) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	closed, found := sp.agreements[id]
+	if !found {
+		return PaymentAgreement{}, false
+	}
+	if closed.consumerID != instance.ConsumerID || closed.serviceType != instance.Proposal.ServiceType {
+		return PaymentAgreement{}, false
+	}
+	if closed.agreement.ID.Cmp(agreementID) != 0 || time.Since(closed.closedAt) > resumableAgreementTTL {
+		return PaymentAgreement{}, false
+	}
+
+	delete(sp.agreements, id)
+	return closed.agreement, true
+}
@@ -19,6 +19,7 @@ package service
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
@@ -193,3 +194,24 @@ func Benchmark_SessionPool_GetAll(b *testing.B) {
 	}
 	benchmarkSessionPoolGetAllResult = r
 }
+
+func TestSessionPool_TakeAgreement_OnlyByTheSameConsumer(t *testing.T) {
+	pool := NewSessionPool(mocks.NewEventBus())
+	closed, _ := NewSession(currentService, &pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}}, trace.NewTracer(""))
+	other, _ := NewSession(currentService, &pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: "0x2"}}, trace.NewTracer(""))
+	resuming, _ := NewSession(currentService, &pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}}, trace.NewTracer(""))
+
+	pool.KeepAgreement(closed, PaymentAgreement{ID: big.NewInt(5), Total: big.NewInt(100)})
+
+	_, ok := pool.TakeAgreement(closed.ID, other, big.NewInt(5))
+	assert.False(t, ok)
+	_, ok = pool.TakeAgreement(closed.ID, resuming, big.NewInt(6))
+	assert.False(t, ok)
+
+	agreement, ok := pool.TakeAgreement(closed.ID, resuming, big.NewInt(5))
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(100), agreement.Total)
+
+	_, ok = pool.TakeAgreement(closed.ID, resuming, big.NewInt(5))
+	assert.False(t, ok)
+}
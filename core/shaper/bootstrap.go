@@ -17,16 +17,52 @@
 
 package shaper
 
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
 // Shaper shapes traffic on a network interface.
 type Shaper interface {
 	// Start applies shaping configuration on the specified interface and then continuously ensures it.
-	Start(interfaceName string) error
+	// Profile is resolved again every time shaping configuration or bandwidth limits change.
+	Start(interfaceName string, profile ProfileFunc) error
 	// Clear clears shaping rules.
 	Clear(interfaceName string)
 }
 
 type eventListener interface {
 	SubscribeAsync(topic string, fn interface{}) error
+	Unsubscribe(topic string, fn interface{}) error
+}
+
+// subscription keeps the handler subscribed to the topics, so exactly it is unsubscribed when shaping is cleared.
+type subscription struct {
+	listener eventListener
+	topics   []string
+	handler  func(interface{})
+}
+
+func subscribe(listener eventListener, topics []string, handler func(interface{})) (*subscription, error) {
+	sub := &subscription{listener: listener, handler: handler}
+	for _, topic := range topics {
+		if err := listener.SubscribeAsync(topic, sub.handler); err != nil {
+			sub.unsubscribe()
+			return nil, fmt.Errorf("could not subscribe to topic %s: %w", topic, err)
+		}
+		sub.topics = append(sub.topics, topic)
+	}
+	return sub, nil
+}
+
+func (s *subscription) unsubscribe() {
+	for _, topic := range s.topics {
+		if err := s.listener.Unsubscribe(topic, s.handler); err != nil {
+			log.Warn().Err(err).Msgf("Could not unsubscribe from topic %s", topic)
+		}
+	}
+	s.topics = nil
 }
 
 // New creates a traffic shaper (linux) or no-op.
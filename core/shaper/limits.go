@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// AppTopicLimits represents the topic to which events about changed bandwidth limits are published.
+const AppTopicLimits = "shaper_limits"
+
+// Profile describes bandwidth limits of a shaped interface.
+// Zero value of the limit means it's not set and is inherited from the broader scope.
+type Profile struct {
+	UplinkKbps   int `json:"uplink_kbps"`
+	DownlinkKbps int `json:"downlink_kbps"`
+}
+
+// Merge returns the profile with the limits of given override applied on top of it.
+func (p Profile) Merge(override Profile) Profile {
+	if override.UplinkKbps > 0 {
+		p.UplinkKbps = override.UplinkKbps
+	}
+	if override.DownlinkKbps > 0 {
+		p.DownlinkKbps = override.DownlinkKbps
+	}
+	return p
+}
+
+// ProfileFunc returns the currently effective profile of a shaped interface.
+type ProfileFunc func() Profile
+
+// DefaultProfile returns node wide bandwidth limits from the configuration.
+func DefaultProfile() Profile {
+	return Profile{
+		UplinkKbps:   config.GetInt(config.FlagShaperBandwidthUplink),
+		DownlinkKbps: config.GetInt(config.FlagShaperBandwidthDownlink),
+	}
+}
+
+// AppEventLimits represents the event of changed bandwidth limits.
+// Either ServiceType or SessionID is set, depending on the scope of the change.
+type AppEventLimits struct {
+	ServiceType string
+	SessionID   string
+	Profile     Profile
+}
+
+// Limits keeps bandwidth limits overridden at runtime per service type and per consumer session.
+type Limits struct {
+	publisher eventbus.Publisher
+
+	mu       sync.RWMutex
+	services map[string]Profile
+	sessions map[string]Profile
+}
+
+// NewLimits creates an empty set of bandwidth limit overrides.
+func NewLimits(publisher eventbus.Publisher) *Limits {
+	return &Limits{
+		publisher: publisher,
+		services:  make(map[string]Profile),
+		sessions:  make(map[string]Profile),
+	}
+}
+
+// SetService overrides bandwidth limits of all sessions of the given service type.
+func (l *Limits) SetService(serviceType string, profile Profile) {
+	l.mu.Lock()
+	l.services[serviceType] = profile
+	l.mu.Unlock()
+
+	l.publisher.Publish(AppTopicLimits, AppEventLimits{ServiceType: serviceType, Profile: profile})
+}
+
+// ClearService removes bandwidth limit overrides of the given service type.
+func (l *Limits) ClearService(serviceType string) {
+	l.mu.Lock()
+	delete(l.services, serviceType)
+	l.mu.Unlock()
+
+	l.publisher.Publish(AppTopicLimits, AppEventLimits{ServiceType: serviceType})
+}
+
+// SetSession overrides bandwidth limits of a single consumer session.
+func (l *Limits) SetSession(sessionID string, profile Profile) {
+	l.mu.Lock()
+	l.sessions[sessionID] = profile
+	l.mu.Unlock()
+
+	l.publisher.Publish(AppTopicLimits, AppEventLimits{SessionID: sessionID, Profile: profile})
+}
+
+// ClearSession removes bandwidth limit overrides of a single consumer session.
+func (l *Limits) ClearSession(sessionID string) {
+	l.mu.Lock()
+	delete(l.sessions, sessionID)
+	l.mu.Unlock()
+
+	l.publisher.Publish(AppTopicLimits, AppEventLimits{SessionID: sessionID})
+}
+
+// RemoveSession forgets bandwidth limit overrides of the ended consumer session.
+// Nothing is published, as the session is not shaped anymore.
+func (l *Limits) RemoveSession(sessionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.sessions, sessionID)
+}
+
+// Services returns bandwidth limit overrides by service type.
+func (l *Limits) Services() map[string]Profile {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return copyProfiles(l.services)
+}
+
+// Sessions returns bandwidth limit overrides by session ID.
+func (l *Limits) Sessions() map[string]Profile {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return copyProfiles(l.sessions)
+}
+
+// Resolve returns the effective profile of the session: service and session overrides applied over the given base.
+func (l *Limits) Resolve(serviceType, sessionID string, base Profile) Profile {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return base.Merge(l.services[serviceType]).Merge(l.sessions[sessionID])
+}
+
+// ProfileFunc returns the function resolving the effective profile of the session at the time of the call.
+func (l *Limits) ProfileFunc(serviceType, sessionID string, base func() Profile) ProfileFunc {
+	return func() Profile {
+		return l.Resolve(serviceType, sessionID, base())
+	}
+}
+
+func copyProfiles(profiles map[string]Profile) map[string]Profile {
+	result := make(map[string]Profile, len(profiles))
+	for key, profile := range profiles {
+		result[key] = profile
+	}
+	return result
+}
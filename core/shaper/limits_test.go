@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Profile_Merge(t *testing.T) {
+	base := Profile{UplinkKbps: 5000, DownlinkKbps: 5000}
+
+	assert.Equal(t, base, base.Merge(Profile{}))
+	assert.Equal(t, Profile{UplinkKbps: 1000, DownlinkKbps: 5000}, base.Merge(Profile{UplinkKbps: 1000}))
+	assert.Equal(t, Profile{UplinkKbps: 1000, DownlinkKbps: 2000}, base.Merge(Profile{UplinkKbps: 1000, DownlinkKbps: 2000}))
+}
+
+func Test_Limits_Resolve(t *testing.T) {
+	bus := mocks.NewEventBus()
+	limits := NewLimits(bus)
+	base := Profile{UplinkKbps: 5000, DownlinkKbps: 5000}
+
+	assert.Equal(t, base, limits.Resolve("wireguard", "session1", base))
+
+	limits.SetService("wireguard", Profile{DownlinkKbps: 3000})
+	assert.Equal(t, AppEventLimits{ServiceType: "wireguard", Profile: Profile{DownlinkKbps: 3000}}, bus.Pop())
+	assert.Equal(t, Profile{UplinkKbps: 5000, DownlinkKbps: 3000}, limits.Resolve("wireguard", "session1", base))
+	assert.Equal(t, base, limits.Resolve("openvpn", "session1", base))
+
+	limits.SetSession("session1", Profile{UplinkKbps: 1000})
+	assert.Equal(t, AppEventLimits{SessionID: "session1", Profile: Profile{UplinkKbps: 1000}}, bus.Pop())
+	assert.Equal(t, Profile{UplinkKbps: 1000, DownlinkKbps: 3000}, limits.Resolve("wireguard", "session1", base))
+	assert.Equal(t, Profile{UplinkKbps: 5000, DownlinkKbps: 3000}, limits.Resolve("wireguard", "session2", base))
+	assert.Equal(t, map[string]Profile{"session1": {UplinkKbps: 1000}}, limits.Sessions())
+
+	limits.ClearService("wireguard")
+	limits.ClearSession("session1")
+	assert.Equal(t, base, limits.Resolve("wireguard", "session1", base))
+	assert.Empty(t, limits.Services())
+	assert.Empty(t, limits.Sessions())
+}
+
+func Test_Limits_ProfileFunc_ResolvesOnEveryCall(t *testing.T) {
+	limits := NewLimits(mocks.NewEventBus())
+	profile := limits.ProfileFunc("wireguard", "session1", func() Profile {
+		return Profile{UplinkKbps: 5000, DownlinkKbps: 5000}
+	})
+
+	assert.Equal(t, Profile{UplinkKbps: 5000, DownlinkKbps: 5000}, profile())
+
+	limits.SetSession("session1", Profile{DownlinkKbps: 100})
+	assert.Equal(t, Profile{UplinkKbps: 5000, DownlinkKbps: 100}, profile())
+}
+
+func Test_Limits_RemoveSession(t *testing.T) {
+	bus := mocks.NewEventBus()
+	limits := NewLimits(bus)
+	base := Profile{UplinkKbps: 5000, DownlinkKbps: 5000}
+
+	limits.SetSession("session1", Profile{UplinkKbps: 1000})
+	bus.Pop()
+
+	limits.RemoveSession("session1")
+	assert.Nil(t, bus.Pop())
+	assert.Empty(t, limits.Sessions())
+	assert.Equal(t, base, limits.Resolve("wireguard", "session1", base))
+}
+
+func Test_Subscription_UnsubscribesExactlyItsHandler(t *testing.T) {
+	bus := eventbus.New()
+	var firstCalls, secondCalls int32
+	first, err := subscribe(bus, []string{AppTopicLimits}, func(interface{}) { atomic.AddInt32(&firstCalls, 1) })
+	assert.NoError(t, err)
+	second, err := subscribe(bus, []string{AppTopicLimits}, func(interface{}) { atomic.AddInt32(&secondCalls, 1) })
+	assert.NoError(t, err)
+
+	first.unsubscribe()
+	bus.Publish(AppTopicLimits, AppEventLimits{})
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&secondCalls) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&firstCalls))
+
+	second.unsubscribe()
+	assert.Error(t, bus.Unsubscribe(AppTopicLimits, second.handler))
+}
@@ -33,7 +33,7 @@ func create(_ eventListener) *noopShaper {
 }
 
 // Start noop
-func (noopShaper) Start(_ string) error {
+func (noopShaper) Start(_ string, _ ProfileFunc) error {
 	if config.GetBool(config.FlagShaperEnabled) {
 		log.Warn().Msgf("Flag %q is only supported under linux", config.FlagShaperEnabled.Name)
 	}
@@ -18,18 +18,21 @@
 package shaper
 
 import (
+	"sync"
+
 	"github.com/mysteriumnetwork/go-wondershaper/wondershaper"
 	"github.com/mysteriumnetwork/node/config"
-	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
-const limitKbps = 5000
-
 type linuxShaper struct {
-	ws          *wondershaper.Shaper
-	listener    eventListener
-	listenTopic string
+	ws           *wondershaper.Shaper
+	listener     eventListener
+	listenTopics []string
+
+	mu           sync.Mutex
+	cleared      bool
+	subscription *subscription
 }
 
 func create(listener eventListener) *linuxShaper {
@@ -37,41 +40,73 @@ func create(listener eventListener) *linuxShaper {
 	ws.Stdout = log.Logger
 	ws.Stderr = log.Logger
 	return &linuxShaper{
-		ws:          ws,
-		listener:    listener,
-		listenTopic: config.AppTopicConfig(config.FlagShaperEnabled.Name),
+		ws:       ws,
+		listener: listener,
+		listenTopics: []string{
+			config.AppTopicConfig(config.FlagShaperEnabled.Name),
+			config.AppTopicConfig(config.FlagShaperBandwidthUplink.Name),
+			config.AppTopicConfig(config.FlagShaperBandwidthDownlink.Name),
+			AppTopicLimits,
+		},
 	}
 }
 
 // Start applies shaping configuration on the specified interface and then continuously ensures it.
-func (s *linuxShaper) Start(interfaceName string) error {
+func (s *linuxShaper) Start(interfaceName string, profile ProfileFunc) error {
 	applyLimits := func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.cleared {
+			return nil
+		}
+
 		s.ws.Clear(interfaceName)
 
 		if config.GetBool(config.FlagShaperEnabled) {
-			err := s.ws.LimitDownlink(interfaceName, limitKbps)
-			if err != nil {
-				log.Error().Err(err).Msg("Could not limit download speed")
-				return err
+			limits := profile()
+			if limits.DownlinkKbps > 0 {
+				err := s.ws.LimitDownlink(interfaceName, limits.DownlinkKbps)
+				if err != nil {
+					log.Error().Err(err).Msg("Could not limit download speed")
+					return err
+				}
 			}
-			err = s.ws.LimitUplink(interfaceName, limitKbps)
-			if err != nil {
-				log.Error().Err(err).Msg("Could not limit upload speed")
-				return err
+			if limits.UplinkKbps > 0 {
+				err := s.ws.LimitUplink(interfaceName, limits.UplinkKbps)
+				if err != nil {
+					log.Error().Err(err).Msg("Could not limit upload speed")
+					return err
+				}
 			}
 		}
 		return nil
 	}
 
-	err := s.listener.SubscribeAsync(s.listenTopic, applyLimits)
+	sub, err := subscribe(s.listener, s.listenTopics, func(interface{}) { applyLimits() })
 	if err != nil {
-		return errors.Wrap(err, "could not subscribe to topic: "+s.listenTopic)
+		return err
+	}
+	s.mu.Lock()
+	if s.cleared {
+		sub.unsubscribe()
+	} else {
+		s.subscription = sub
 	}
+	s.mu.Unlock()
 
 	return applyLimits()
 }
 
 // Clear clears shaping rules.
 func (s *linuxShaper) Clear(interfaceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleared = true
+	if s.subscription != nil {
+		s.subscription.unsubscribe()
+		s.subscription = nil
+	}
 	s.ws.Clear(interfaceName)
 }
@@ -28,7 +28,9 @@ import (
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/session/pingpong"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -39,6 +41,7 @@ const AppTopicState = "State change"
 // State represents the node state at the current moment. It's a read only object, used only to display data.
 type State struct {
 	NATStatus        contract.NATStatusDTO
+	PortMappings     []mapping.Mapping
 	Services         []contract.ServiceInfoDTO
 	Sessions         []session.History
 	Connection       Connection
@@ -54,6 +57,8 @@ type Identity struct {
 	Balance            *big.Int
 	Earnings           *big.Int
 	EarningsTotal      *big.Int
+	HermesBalances     map[common.Address]*big.Int
+	HermesEarnings     map[common.Address]pingpongEvent.Earnings
 }
 
 // Connection represents consumer connection state.
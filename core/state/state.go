@@ -34,7 +34,9 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/nat/behavior"
 	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	nodeSession "github.com/mysteriumnetwork/node/session"
 	sevent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong"
@@ -69,11 +71,13 @@ type channelAddressCalculator interface {
 
 type balanceProvider interface {
 	GetBalance(chainID int64, id identity.Identity) *big.Int
+	GetHermesBalances(chainID int64, id identity.Identity) map[common.Address]*big.Int
 }
 
 type earningsProvider interface {
 	List(chainID int64) []pingpong.HermesChannel
 	GetEarnings(chainID int64, id identity.Identity) pingpongEvent.Earnings
+	GetEarningsPerHermes(chainID int64, id identity.Identity) map[common.Address]pingpongEvent.Earnings
 }
 
 // Keeper keeps track of state through eventual consistency.
@@ -107,6 +111,7 @@ type KeeperDeps struct {
 	BalanceProvider           balanceProvider
 	EarningsProvider          earningsProvider
 	ChainID                   int64
+	HermesID                  common.Address
 }
 
 // NewKeeper returns a new instance of the keeper.
@@ -135,9 +140,10 @@ func NewKeeper(deps KeeperDeps, debounceDuration time.Duration) *Keeper {
 	k.consumeServiceSessionEarningsEvent = debounce(k.updateSessionEarnings, debounceDuration)
 
 	// consumer
-	k.consumeConnectionStatisticsEvent = debounce(k.updateConnectionStats, debounceDuration)
-	k.consumeConnectionThroughputEvent = debounce(k.updateConnectionThroughput, debounceDuration)
-	k.consumeConnectionSpendingEvent = debounce(k.updateConnectionSpending, debounceDuration)
+	// additional connections are filtered before debouncing, so they do not shadow events of the default one
+	k.consumeConnectionStatisticsEvent = k.defaultConnectionOnly(debounce(k.updateConnectionStats, debounceDuration))
+	k.consumeConnectionThroughputEvent = k.defaultConnectionOnly(debounce(k.updateConnectionThroughput, debounceDuration))
+	k.consumeConnectionSpendingEvent = k.defaultConnectionOnly(debounce(k.updateConnectionSpending, debounceDuration))
 	k.announceStateChanges = debounce(k.announceState, debounceDuration)
 
 	return k
@@ -166,6 +172,8 @@ func (k *Keeper) fetchIdentities() []stateEvent.Identity {
 			Balance:            k.deps.BalanceProvider.GetBalance(k.deps.ChainID, id),
 			Earnings:           earnings.UnsettledBalance,
 			EarningsTotal:      earnings.LifetimeBalance,
+			HermesBalances:     k.deps.BalanceProvider.GetHermesBalances(k.deps.ChainID, id),
+			HermesEarnings:     k.deps.EarningsProvider.GetEarningsPerHermes(k.deps.ChainID, id),
 		}
 		identities[idx] = stateIdentity
 	}
@@ -174,43 +182,55 @@ func (k *Keeper) fetchIdentities() []stateEvent.Identity {
 
 // Subscribe subscribes to the event bus.
 func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
-	if err := bus.SubscribeAsync(servicestate.AppTopicServiceStatus, k.consumeServiceStateEvent); err != nil {
+	// Keeper may be subscribed after some events were already published, catch up with them if the bus allows it.
+	subscribe := bus.SubscribeAsync
+	if replayer, ok := bus.(eventbus.Replayer); ok {
+		subscribe = replayer.SubscribeAsyncWithReplay
+	}
+
+	if err := subscribe(servicestate.AppTopicServiceStatus, k.consumeServiceStateEvent); err != nil {
+		return err
+	}
+	if err := subscribe(sevent.AppTopicSession, k.consumeServiceSessionEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(sevent.AppTopicSession, k.consumeServiceSessionEvent); err != nil {
+	if err := subscribe(sevent.AppTopicDataTransferred, k.consumeServiceSessionStatisticsEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(sevent.AppTopicDataTransferred, k.consumeServiceSessionStatisticsEvent); err != nil {
+	if err := subscribe(sevent.AppTopicTokensEarned, k.consumeServiceSessionEarningsEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(sevent.AppTopicTokensEarned, k.consumeServiceSessionEarningsEvent); err != nil {
+	if err := subscribe(natEvent.AppTopicTraversal, k.consumeNATEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(natEvent.AppTopicTraversal, k.consumeNATEvent); err != nil {
+	if err := subscribe(mapping.AppTopicPortMappings, k.consumePortMappingsEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionState, k.consumeConnectionStateEvent); err != nil {
+	if err := subscribe(behavior.AppTopicNATBehavior, k.consumeNATBehaviorEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionStatistics, k.consumeConnectionStatisticsEvent); err != nil {
+	if err := subscribe(connectionstate.AppTopicConnectionState, k.consumeConnectionStateEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(bandwidth.AppTopicConnectionThroughput, k.consumeConnectionThroughputEvent); err != nil {
+	if err := subscribe(connectionstate.AppTopicConnectionStatistics, k.consumeConnectionStatisticsEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(pingpongEvent.AppTopicInvoicePaid, k.consumeConnectionSpendingEvent); err != nil {
+	if err := subscribe(bandwidth.AppTopicConnectionThroughput, k.consumeConnectionThroughputEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(identity.AppTopicIdentityCreated, k.consumeIdentityCreatedEvent); err != nil {
+	if err := subscribe(pingpongEvent.AppTopicInvoicePaid, k.consumeConnectionSpendingEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(registry.AppTopicIdentityRegistration, k.consumeIdentityRegistrationEvent); err != nil {
+	if err := subscribe(identity.AppTopicIdentityCreated, k.consumeIdentityCreatedEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(pingpongEvent.AppTopicBalanceChanged, k.consumeBalanceChangedEvent); err != nil {
+	if err := subscribe(registry.AppTopicIdentityRegistration, k.consumeIdentityRegistrationEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(pingpongEvent.AppTopicEarningsChanged, k.consumeEarningsChangedEvent); err != nil {
+	if err := subscribe(pingpongEvent.AppTopicBalanceChanged, k.consumeBalanceChangedEvent); err != nil {
+		return err
+	}
+	if err := subscribe(pingpongEvent.AppTopicEarningsChanged, k.consumeEarningsChangedEvent); err != nil {
 		return err
 	}
 	return nil
@@ -276,7 +296,8 @@ func (k *Keeper) updateNatStatus(e interface{}) {
 
 	k.deps.NATStatusProvider.ConsumeNATEvent(event)
 	status := k.deps.NATStatusProvider.Status()
-	k.state.NATStatus = contract.NATStatusDTO{Status: status.Status}
+	k.state.NATStatus.Status = status.Status
+	k.state.NATStatus.Error = ""
 	if status.Error != nil {
 		k.state.NATStatus.Error = status.Error.Error()
 	}
@@ -284,6 +305,23 @@ func (k *Keeper) updateNatStatus(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
+func (k *Keeper) consumeNATBehaviorEvent(e behavior.AppEventNATBehavior) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.state.NATStatus.Type = string(e.Behavior.Type)
+	k.state.NATStatus.PortAllocation = string(e.Behavior.PortAllocation)
+	go k.announceStateChanges(nil)
+}
+
+func (k *Keeper) consumePortMappingsEvent(e mapping.AppEventPortMappings) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.state.PortMappings = e.Mappings
+	go k.announceStateChanges(nil)
+}
+
 // consumeServiceSessionEvent consumes the session change events
 func (k *Keeper) consumeServiceSessionEvent(e sevent.AppEventSession) {
 	k.lock.Lock()
@@ -397,6 +435,11 @@ func (k *Keeper) consumeConnectionStateEvent(e interface{}) {
 		return
 	}
 
+	if evt.SessionInfo.ConnectionID != "" {
+		// additional connections are not part of the node state
+		return
+	}
+
 	if evt.State == connectionstate.NotConnected {
 		k.state.Connection = stateEvent.Connection{}
 	}
@@ -406,6 +449,30 @@ func (k *Keeper) consumeConnectionStateEvent(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
+// defaultConnectionOnly passes only the events of the default connection to the given consumer.
+func (k *Keeper) defaultConnectionOnly(f func(interface{})) func(interface{}) {
+	return func(e interface{}) {
+		switch evt := e.(type) {
+		case connectionstate.AppEventConnectionStatistics:
+			if evt.SessionInfo.ConnectionID != "" {
+				return
+			}
+		case bandwidth.AppEventConnectionThroughput:
+			if evt.SessionInfo.ConnectionID != "" {
+				return
+			}
+		case pingpongEvent.AppEventInvoicePaid:
+			k.lock.RLock()
+			sessionID := k.state.Connection.Session.SessionID
+			k.lock.RUnlock()
+			if string(sessionID) != evt.SessionID {
+				return
+			}
+		}
+		f(e)
+	}
+}
+
 func (k *Keeper) updateConnectionStats(e interface{}) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
@@ -468,7 +535,16 @@ func (k *Keeper) consumeBalanceChangedEvent(e interface{}) {
 		log.Warn().Msgf("Couldn't find a matching identity for balance change: %s", evt.Identity.Address)
 		return
 	}
-	id.Balance = evt.Current
+	// maps are replaced rather than updated, as previously announced states may still be read
+	balances := make(map[common.Address]*big.Int, len(id.HermesBalances)+1)
+	for hermesID, balance := range id.HermesBalances {
+		balances[hermesID] = balance
+	}
+	balances[evt.HermesID] = evt.Current
+	id.HermesBalances = balances
+	if evt.HermesID == k.deps.HermesID {
+		id.Balance = evt.Current
+	}
 	go k.announceStateChanges(nil)
 }
 
@@ -496,6 +572,7 @@ func (k *Keeper) consumeEarningsChangedEvent(e interface{}) {
 	}
 	id.Earnings = evt.Current.UnsettledBalance
 	id.EarningsTotal = evt.Current.LifetimeBalance
+	id.HermesEarnings = k.deps.EarningsProvider.GetEarningsPerHermes(k.deps.ChainID, evt.Identity)
 
 	go k.announceStateChanges(nil)
 }
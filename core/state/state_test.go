@@ -36,7 +36,9 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/nat/behavior"
 	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	nodeSession "github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong"
@@ -167,6 +169,65 @@ func Test_ConsumesNATEvents(t *testing.T) {
 	assert.Equal(t, natProvider.statusToReturn.Status, keeper.GetState().NATStatus.Status)
 }
 
+func Test_ConsumesPortMappingsEvents(t *testing.T) {
+	// given
+	expected := []mapping.Mapping{{Protocol: "UDP", Port: 51334, Name: "p2p", Method: "PCP(192.168.1.1)", ExpiresAt: time.Now()}}
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{statusToReturn: mockNATStatus},
+		Publisher:         eventBus,
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+		EarningsProvider:  &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+	assert.Empty(t, keeper.GetState().PortMappings)
+
+	// when
+	eventBus.Publish(mapping.AppTopicPortMappings, mapping.AppEventPortMappings{Mappings: expected})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return len(keeper.GetState().PortMappings) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, expected, keeper.GetState().PortMappings)
+}
+
+func Test_ConsumesNATBehaviorEvents(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{statusToReturn: mockNATStatus},
+		Publisher:         eventBus,
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+		EarningsProvider:  &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+
+	// when
+	eventBus.Publish(behavior.AppTopicNATBehavior, behavior.AppEventNATBehavior{
+		Behavior: behavior.Behavior{Type: behavior.NATTypeSymmetric, PortAllocation: behavior.PortAllocationSequential},
+	})
+	eventBus.Publish(natEvent.AppTopicTraversal, natEvent.Event{Successful: true})
+
+	// then
+	assert.Eventually(t, func() bool {
+		status := keeper.GetState().NATStatus
+		return status.Type != "" && status.Status == mockNATStatus.Status
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, contract.NATStatusDTO{
+		Status:         mockNATStatus.Status,
+		Error:          mockNATStatus.Error.Error(),
+		Type:           "symmetric",
+		PortAllocation: "sequential",
+	}, keeper.GetState().NATStatus)
+}
+
 func Test_ConsumesSessionEvents(t *testing.T) {
 	// given
 	expected := sessionEvent.SessionContext{
@@ -433,6 +494,43 @@ func Test_ConsumesConnectionStatisticsEvents(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func Test_IgnoresAdditionalConnectionEvents(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{statusToReturn: mockNATStatus},
+		Publisher:         eventBus,
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+		EarningsProvider:  &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+
+	// when
+	additional := connectionstate.Status{State: connectionstate.Connected, SessionID: "2", ConnectionID: "additional"}
+	eventBus.Publish(connectionstate.AppTopicConnectionState, connectionstate.AppEventConnectionState{
+		State:       additional.State,
+		SessionInfo: additional,
+	})
+	eventBus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
+		Stats:       connectionstate.Statistics{At: time.Now(), BytesSent: 1},
+		SessionInfo: additional,
+	})
+	eventBus.Publish(pingpongEvent.AppTopicInvoicePaid, pingpongEvent.AppEventInvoicePaid{
+		SessionID: "2",
+		Invoice:   crypto.Invoice{AgreementTotal: big.NewInt(1)},
+	})
+
+	// then
+	time.Sleep(50 * time.Millisecond)
+	connection := keeper.GetState().Connection
+	assert.Equal(t, connectionstate.NotConnected, connection.Session.State)
+	assert.True(t, connection.Statistics.At.IsZero())
+	assert.Nil(t, connection.Invoice.AgreementTotal)
+}
+
 func Test_ConsumesConnectionInvoiceEvents(t *testing.T) {
 	// given
 	expected := crypto.Invoice{
@@ -499,6 +597,50 @@ func Test_ConsumesBalanceChangeEvent(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func Test_ConsumesBalanceChangeEventPerHermes(t *testing.T) {
+	// given
+	hermes1 := common.HexToAddress("0x1")
+	hermes2 := common.HexToAddress("0x2")
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{statusToReturn: mockNATStatus},
+		Publisher:         eventBus,
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider: &mocks.IdentityProvider{
+			Identities: []identity.Identity{
+				{Address: "0x000000000000000000000000000000000000000a"},
+			},
+		},
+		IdentityRegistry:          &mocks.IdentityRegistry{Status: registry.Registered},
+		IdentityChannelCalculator: pingpong.NewChannelAddressCalculator("", "", ""),
+		BalanceProvider: &mockBalanceProvider{
+			Balance:        big.NewInt(1),
+			HermesBalances: map[common.Address]*big.Int{hermes1: big.NewInt(1), hermes2: big.NewInt(2)},
+		},
+		EarningsProvider: &mockEarningsProvider{},
+		HermesID:         hermes1,
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+	assert.Equal(t, map[common.Address]*big.Int{hermes1: big.NewInt(1), hermes2: big.NewInt(2)}, keeper.GetState().Identities[0].HermesBalances)
+
+	// when
+	eventBus.Publish(pingpongEvent.AppTopicBalanceChanged, pingpongEvent.AppEventBalanceChanged{
+		Identity: identity.Identity{Address: "0x000000000000000000000000000000000000000a"},
+		HermesID: hermes2,
+		Previous: big.NewInt(2),
+		Current:  big.NewInt(999),
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return keeper.GetState().Identities[0].HermesBalances[hermes2].Cmp(big.NewInt(999)) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, big.NewInt(1), keeper.GetState().Identities[0].Balance)
+	assert.Equal(t, big.NewInt(1), keeper.GetState().Identities[0].HermesBalances[hermes1])
+}
+
 func Test_ConsumesEarningsChangeEvent(t *testing.T) {
 	// given
 	eventBus := eventbus.New()
@@ -534,6 +676,9 @@ func Test_ConsumesEarningsChangeEvent(t *testing.T) {
 		{ChannelID: "1"},
 		{ChannelID: "2"},
 	}
+	channelsProvider.HermesEarnings = map[common.Address]pingpongEvent.Earnings{
+		common.HexToAddress("0x1"): {LifetimeBalance: big.NewInt(100), UnsettledBalance: big.NewInt(10)},
+	}
 	eventBus.Publish(pingpongEvent.AppTopicEarningsChanged, pingpongEvent.AppEventEarningsChanged{
 		Identity: identity.Identity{Address: "0x000000000000000000000000000000000000000a"},
 		Previous: pingpongEvent.Earnings{},
@@ -546,6 +691,7 @@ func Test_ConsumesEarningsChangeEvent(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 	assert.Len(t, keeper.GetState().ProviderChannels, 2)
 	assert.Equal(t, channelsProvider.Channels, keeper.GetState().ProviderChannels)
+	assert.Equal(t, channelsProvider.HermesEarnings, keeper.GetState().Identities[0].HermesEarnings)
 }
 
 func Test_ConsumesIdentityRegistrationEvent(t *testing.T) {
@@ -667,7 +813,8 @@ func interacted(c interactionCounter, times int) func() bool {
 }
 
 type mockBalanceProvider struct {
-	Balance *big.Int
+	Balance        *big.Int
+	HermesBalances map[common.Address]*big.Int
 }
 
 // GetBalance returns a pre-defined balance.
@@ -675,9 +822,15 @@ func (mbp *mockBalanceProvider) GetBalance(_ int64, _ identity.Identity) *big.In
 	return mbp.Balance
 }
 
+// GetHermesBalances returns pre-defined balances.
+func (mbp *mockBalanceProvider) GetHermesBalances(_ int64, _ identity.Identity) map[common.Address]*big.Int {
+	return mbp.HermesBalances
+}
+
 type mockEarningsProvider struct {
-	Earnings pingpongEvent.Earnings
-	Channels []pingpong.HermesChannel
+	Earnings       pingpongEvent.Earnings
+	HermesEarnings map[common.Address]pingpongEvent.Earnings
+	Channels       []pingpong.HermesChannel
 }
 
 // List retrieves identity's channels with all known hermeses.
@@ -690,6 +843,11 @@ func (mep *mockEarningsProvider) GetEarnings(chainID int64, _ identity.Identity)
 	return mep.Earnings
 }
 
+// GetEarningsPerHermes returns pre-defined settlement states.
+func (mep *mockEarningsProvider) GetEarningsPerHermes(chainID int64, _ identity.Identity) map[common.Address]pingpongEvent.Earnings {
+	return mep.HermesEarnings
+}
+
 func serviceByID(services []contract.ServiceInfoDTO, id string) (se contract.ServiceInfoDTO, found bool) {
 	for i := range services {
 		if services[i].ID == id {
@@ -46,6 +46,13 @@ type whitelistHandler struct {
 }
 
 func (wh *whitelistHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	if wh.isQuestionDenied(req) {
+		resp := &dns.Msg{}
+		resp.SetRcode(req, dns.RcodeNameError)
+		writer.WriteMsg(resp)
+		return
+	}
+
 	resolverWriter := &recordingWriter{writer: writer}
 	wh.resolver.ServeDNS(resolverWriter, req)
 	resp := resolverWriter.responseMsg
@@ -62,6 +69,15 @@ func (wh *whitelistHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
 	writer.WriteMsg(resp)
 }
 
+func (wh *whitelistHandler) isQuestionDenied(req *dns.Msg) bool {
+	for _, question := range req.Question {
+		if wh.policies.IsHostDenied(strings.TrimRight(question.Name, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
 func (wh *whitelistHandler) whitelistByAnswer(response *dns.Msg) error {
 	for _, record := range response.Answer {
 		switch recordValue := record.(type) {
@@ -37,6 +37,14 @@ var (
 		},
 	}
 
+	policyDNSDeny      = market.AccessPolicy{ID: "denied-domain"}
+	policyDNSDenyRules = market.AccessPolicyRuleSet{
+		ID: "denied-domain",
+		Deny: []market.AccessRule{
+			{Type: market.AccessPolicyTypeDNSHostname, Value: "denied.wildcard.com"},
+		},
+	}
+
 	policyDNSHostname      = market.AccessPolicy{ID: "domain"}
 	policyDNSHostnameRules = market.AccessPolicyRuleSet{
 		ID: "domain",
@@ -164,6 +172,42 @@ func Test_WhitelistAnswers(t *testing.T) {
 	}
 }
 
+func Test_WhitelistAnswers_DeniesHost(t *testing.T) {
+	repo := createPolicies()
+	repo.SetPolicyRules(policyDNSDeny, policyDNSDenyRules)
+
+	mockedBlocker := &trafficBlockerMock{
+		allowIPCalls: map[string]int{},
+	}
+	response := &dns.Msg{
+		Answer: []dns.RR{
+			&dns.A{
+				Hdr: dns.RR_Header{Name: "denied.wildcard.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 0},
+				A:   net.ParseIP("0.0.0.5"),
+			},
+		},
+	}
+	writer := &recordingWriter{}
+	handler := WhitelistAnswers(
+		dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+			writer.WriteMsg(response)
+		}),
+		mockedBlocker,
+		repo,
+	)
+
+	req := &dns.Msg{}
+	req.SetQuestion("denied.wildcard.com.", dns.TypeA)
+	handler.ServeDNS(writer, req)
+	assert.Equal(t, map[string]int{}, mockedBlocker.allowIPCalls)
+	assert.Equal(t, dns.RcodeNameError, writer.responseMsg.Rcode)
+
+	req.SetQuestion("cdn.wildcard.com.", dns.TypeA)
+	handler.ServeDNS(writer, req)
+	assert.Equal(t, map[string]int{}, mockedBlocker.allowIPCalls)
+	assert.Equal(t, response, writer.responseMsg)
+}
+
 func createPolicies() *policy.Repository {
 	repo := policy.NewRepository()
 	repo.SetPolicyRules(policyDNSZone, policyDNSZoneRules)
@@ -187,6 +231,18 @@ func (tbn *trafficBlockerMock) AllowURLAccess(rawURLs ...string) (firewall.Incom
 	return nil, nil
 }
 
+func (tbn *trafficBlockerMock) AllowIncomingTraffic(net.IPNet, firewall.TrafficRule) (firewall.IncomingRuleRemove, error) {
+	return nil, nil
+}
+
+func (tbn *trafficBlockerMock) DenyIncomingTraffic(net.IPNet, firewall.TrafficRule) (firewall.IncomingRuleRemove, error) {
+	return nil, nil
+}
+
+func (tbn *trafficBlockerMock) LimitIncomingTraffic(net.IPNet, uint64) (firewall.IncomingRuleRemove, error) {
+	return nil, nil
+}
+
 func (tbn *trafficBlockerMock) AllowIPAccess(ip net.IP) (firewall.IncomingRuleRemove, error) {
 	ipString := ip.String()
 	if _, called := tbn.allowIPCalls[ipString]; !called {
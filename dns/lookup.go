@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// LookupViaSystem resolves IPv4 addresses of the host using system DNS servers.
+func LookupViaSystem(host string) ([]net.IP, error) {
+	handler, err := ResolveViaSystem()
+	if err != nil {
+		return nil, err
+	}
+
+	return Lookup(handler, host)
+}
+
+// Lookup resolves IPv4 addresses of the host by querying given DNS handler.
+func Lookup(handler dns.Handler, host string) ([]net.IP, error) {
+	req := &dns.Msg{}
+	req.SetQuestion(dns.Fqdn(host), dns.TypeA)
+
+	writer := &recordingWriter{}
+	handler.ServeDNS(writer, req)
+
+	resp := writer.responseMsg
+	if resp == nil {
+		return nil, errors.New("no DNS response for " + host)
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("DNS query for %s failed: %s", host, dns.RcodeToString[resp.Rcode])
+	}
+
+	var ips []net.IP
+	for _, record := range resp.Answer {
+		if a, ok := record.(*dns.A); ok {
+			ips = append(ips, a.A)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, errors.New("no IPv4 addresses found for " + host)
+	}
+	return ips, nil
+}
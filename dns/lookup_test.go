@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Lookup(t *testing.T) {
+	handler := dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+		if req.Question[0].Name != "example.com." {
+			resp.SetRcode(req, dns.RcodeNameError)
+			writer.WriteMsg(resp)
+			return
+		}
+
+		resp.Answer = []dns.RR{
+			&dns.CNAME{
+				Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET},
+				Target: "cdn.example.com.",
+			},
+			&dns.A{
+				Hdr: dns.RR_Header{Name: "cdn.example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET},
+				A:   net.ParseIP("1.2.3.4"),
+			},
+			&dns.A{
+				Hdr: dns.RR_Header{Name: "cdn.example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET},
+				A:   net.ParseIP("1.2.3.5"),
+			},
+		}
+		writer.WriteMsg(resp)
+	})
+
+	ips, err := Lookup(handler, "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []net.IP{net.ParseIP("1.2.3.4"), net.ParseIP("1.2.3.5")}, ips)
+
+	_, err = Lookup(handler, "unknown.com")
+	assert.EqualError(t, err, "DNS query for unknown.com failed: NXDOMAIN")
+}
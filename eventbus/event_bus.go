@@ -18,11 +18,18 @@
 package eventbus
 
 import (
-	asaskevichEventBus "github.com/asaskevich/EventBus"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
+// replayBufferSize is the number of the latest events kept per topic for late subscribers.
+const replayBufferSize = 16
+
 // EventBus allows subscribing and publishing data by topic
 type EventBus interface {
 	Publisher
@@ -41,20 +48,166 @@ type Subscriber interface {
 	Unsubscribe(topic string, fn interface{}) error
 }
 
+// Replayer subscribes to events, delivering the latest already published events of the topic first.
+type Replayer interface {
+	SubscribeAsyncWithReplay(topic string, fn interface{}) error
+}
+
+// Monitor provides delivery statistics of the published topics.
+type Monitor interface {
+	Stats() map[string]TopicStats
+}
+
+type topicEntry struct {
+	handlers []*handler
+	replay   []interface{}
+	stats    *topicStats
+}
+
 type simplifiedEventBus struct {
-	bus asaskevichEventBus.Bus
+	lock   sync.Mutex
+	topics map[string]*topicEntry
 }
 
-func (simplifiedBus simplifiedEventBus) Unsubscribe(topic string, fn interface{}) error {
-	return simplifiedBus.bus.Unsubscribe(topic, fn)
+// New returns implementation of EventBus
+func New() EventBus {
+	return &simplifiedEventBus{
+		topics: make(map[string]*topicEntry),
+	}
 }
 
-func (simplifiedBus simplifiedEventBus) Subscribe(topic string, fn interface{}) error {
-	return simplifiedBus.bus.Subscribe(topic, fn)
+// Subscribe subscribes to the topic, handler is called in the publisher goroutine.
+func (bus *simplifiedEventBus) Subscribe(topic string, fn interface{}) error {
+	return bus.subscribe(topic, fn, deliverSync, false)
 }
 
-func (simplifiedBus simplifiedEventBus) SubscribeAsync(topic string, fn interface{}) error {
-	return simplifiedBus.bus.SubscribeAsync(topic, fn, false)
+// SubscribeAsync subscribes to the topic, handler is called in a separate goroutine for every event.
+func (bus *simplifiedEventBus) SubscribeAsync(topic string, fn interface{}) error {
+	return bus.subscribe(topic, fn, deliverAsync, false)
+}
+
+// SubscribeAsyncWithReplay subscribes to the topic and replays the latest events published before the subscription.
+// Handler is called in a separate goroutine, one event at a time in the order of publishing.
+func (bus *simplifiedEventBus) SubscribeAsyncWithReplay(topic string, fn interface{}) error {
+	return bus.subscribe(topic, fn, deliverQueued, true)
+}
+
+func (bus *simplifiedEventBus) subscribe(topic string, fn interface{}, delivery deliveryMode, replay bool) error {
+	h, err := newHandler(fn, delivery)
+	if err != nil {
+		return fmt.Errorf("invalid handler of topic %q: %w", topic, err)
+	}
+
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	entry := bus.topic(topic)
+	entry.handlers = append(entry.handlers, h)
+	if replay {
+		for _, data := range entry.replay {
+			bus.deliver(topic, entry.stats, h, data)
+		}
+	}
+	return nil
+}
+
+// Unsubscribe removes the handler from the topic.
+// Handler is matched by its value first, then by its code when the match is unambiguous (e.g. method values).
+// Subscribers running the same code, e.g. methods of several instances, should keep the func value they
+// subscribed with and unsubscribe with it, so exactly their handler is removed.
+func (bus *simplifiedEventBus) Unsubscribe(topic string, fn interface{}) error {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	entry, ok := bus.topics[topic]
+	if !ok || len(entry.handlers) == 0 {
+		return fmt.Errorf("topic %s doesn't exist", topic)
+	}
+
+	idx, err := findHandler(entry.handlers, reflect.ValueOf(fn))
+	if err != nil {
+		return fmt.Errorf("failed to unsubscribe from topic %s: %w", topic, err)
+	}
+
+	handlers := make([]*handler, 0, len(entry.handlers)-1)
+	handlers = append(handlers, entry.handlers[:idx]...)
+	entry.handlers = append(handlers, entry.handlers[idx+1:]...)
+	return nil
+}
+
+// Publish delivers the event to all subscribers of the topic.
+func (bus *simplifiedEventBus) Publish(topic string, data interface{}) {
+	log.WithLevel(levelFor(topic)).Msgf("Published topic=%q event=%+v", topic, data)
+
+	bus.lock.Lock()
+	entry := bus.topic(topic)
+	entry.stats.published()
+	if len(entry.replay) == replayBufferSize {
+		copy(entry.replay, entry.replay[1:])
+		entry.replay = entry.replay[:replayBufferSize-1]
+	}
+	entry.replay = append(entry.replay, data)
+	handlers := entry.handlers
+	for _, h := range handlers {
+		if h.delivery != deliverSync {
+			bus.deliver(topic, entry.stats, h, data)
+		}
+	}
+	bus.lock.Unlock()
+
+	// Synchronous handlers are called without the lock, so that they are free to publish and subscribe.
+	for _, h := range handlers {
+		if h.delivery == deliverSync {
+			bus.deliver(topic, entry.stats, h, data)
+		}
+	}
+}
+
+// Stats returns delivery statistics of every topic published or subscribed to so far.
+func (bus *simplifiedEventBus) Stats() map[string]TopicStats {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+
+	result := make(map[string]TopicStats, len(bus.topics))
+	for topic, entry := range bus.topics {
+		result[topic] = entry.stats.snapshot()
+	}
+	return result
+}
+
+// topic returns the entry of the topic, creating it if needed. Should be called with the lock held.
+func (bus *simplifiedEventBus) topic(topic string) *topicEntry {
+	entry, ok := bus.topics[topic]
+	if !ok {
+		entry = &topicEntry{stats: newTopicStats()}
+		bus.topics[topic] = entry
+	}
+	return entry
+}
+
+func (bus *simplifiedEventBus) deliver(topic string, stats *topicStats, h *handler, data interface{}) {
+	call := func() {
+		start := time.Now()
+		switch err := h.call(data); err.(type) {
+		case nil:
+			stats.delivered(time.Since(start))
+		case *mismatchError:
+			stats.mismatched()
+			log.Error().Err(err).Msgf("Event of topic %q was not delivered", topic)
+		default:
+			stats.panicked()
+			log.Error().Err(err).Msgf("Subscriber of topic %q failed", topic)
+		}
+	}
+
+	switch h.delivery {
+	case deliverSync:
+		call()
+	case deliverAsync:
+		go call()
+	case deliverQueued:
+		h.queue.push(call)
+	}
 }
 
 var logLevelsByTopic = map[string]zerolog.Level{
@@ -76,14 +229,3 @@ func levelFor(topic string) zerolog.Level {
 	}
 	return zerolog.DebugLevel
 }
-
-func (simplifiedBus simplifiedEventBus) Publish(topic string, data interface{}) {
-	log.WithLevel(levelFor(topic)).Msgf("Published topic=%q event=%+v", topic, data)
-	simplifiedBus.bus.Publish(topic, data)
-}
-
-// New returns implementation of EventBus
-func New() EventBus {
-	bus := asaskevichEventBus.New()
-	return simplifiedEventBus{bus: bus}
-}
@@ -18,7 +18,10 @@
 package eventbus
 
 import (
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,3 +37,145 @@ func Test_simplifiedEventBus_Publish_InvokesSubscribers(t *testing.T) {
 
 	assert.Equal(t, "test data", received)
 }
+
+func Test_simplifiedEventBus_Subscribe_ValidatesHandler(t *testing.T) {
+	eventBus := New()
+
+	assert.Error(t, eventBus.Subscribe("test topic", "not a function"))
+	assert.Error(t, eventBus.Subscribe("test topic", func(a, b string) {}))
+	assert.Error(t, eventBus.Subscribe("test topic", func(data ...string) {}))
+	assert.NoError(t, eventBus.Subscribe("test topic", func() {}))
+	assert.NoError(t, eventBus.Subscribe("test topic", func(data interface{}) {}))
+}
+
+func Test_simplifiedEventBus_Publish_IsolatesFailingSubscribers(t *testing.T) {
+	eventBus := New()
+	var received []string
+	eventBus.Subscribe("test topic", func(data int) {
+		received = append(received, "int")
+	})
+	eventBus.Subscribe("test topic", func(data string) {
+		panic("failing subscriber")
+	})
+	eventBus.Subscribe("test topic", func(data string) {
+		received = append(received, data)
+	})
+	eventBus.Subscribe("test topic", func() {
+		received = append(received, "no args")
+	})
+
+	eventBus.Publish("test topic", "test data")
+
+	assert.Equal(t, []string{"test data", "no args"}, received)
+
+	stats := eventBus.(Monitor).Stats()["test topic"]
+	assert.Equal(t, uint64(1), stats.Published)
+	assert.Equal(t, uint64(2), stats.Delivered)
+	assert.Equal(t, uint64(1), stats.Panics)
+	assert.Equal(t, uint64(1), stats.Mismatches)
+	assert.Equal(t, uint64(2), stats.Latency.Count())
+	assert.Len(t, stats.Latency.Counts, len(stats.Latency.Bounds)+1)
+}
+
+func Test_simplifiedEventBus_Publish_PassesNilAsZeroValue(t *testing.T) {
+	eventBus := New()
+	received := &struct{}{}
+	eventBus.Subscribe("test topic", func(data *struct{}) {
+		received = data
+	})
+
+	eventBus.Publish("test topic", nil)
+
+	assert.Nil(t, received)
+}
+
+func Test_simplifiedEventBus_SubscribeAsync_InvokesSubscribers(t *testing.T) {
+	eventBus := New()
+	received := make(chan string, 1)
+	eventBus.SubscribeAsync("test topic", func(data string) {
+		received <- data
+	})
+
+	eventBus.Publish("test topic", "test data")
+
+	select {
+	case data := <-received:
+		assert.Equal(t, "test data", data)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+}
+
+func Test_simplifiedEventBus_SubscribeAsyncWithReplay_DeliversBufferedEventsInOrder(t *testing.T) {
+	eventBus := New()
+	for i := 0; i < replayBufferSize+2; i++ {
+		eventBus.Publish("test topic", i)
+	}
+
+	var lock sync.Mutex
+	var received []int
+	err := eventBus.(Replayer).SubscribeAsyncWithReplay("test topic", func(data int) {
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, data)
+	})
+	assert.NoError(t, err)
+	eventBus.Publish("test topic", 100)
+
+	expected := make([]int, 0, replayBufferSize+1)
+	for i := 2; i < replayBufferSize+2; i++ {
+		expected = append(expected, i)
+	}
+	expected = append(expected, 100)
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return assert.ObjectsAreEqual(expected, received)
+	}, time.Second, 10*time.Millisecond)
+}
+
+type testSubscriber struct {
+	received []string
+}
+
+func (ts *testSubscriber) consume(data string) {
+	ts.received = append(ts.received, data)
+}
+
+func Test_simplifiedEventBus_Unsubscribe(t *testing.T) {
+	eventBus := New()
+	first, second := &testSubscriber{}, &testSubscriber{}
+	handler := func(data string) {}
+
+	assert.NoError(t, eventBus.Subscribe("test topic", first.consume))
+	assert.NoError(t, eventBus.Subscribe("test topic", handler))
+	assert.NoError(t, eventBus.Unsubscribe("test topic", handler))
+	assert.NoError(t, eventBus.Unsubscribe("test topic", first.consume))
+	assert.Error(t, eventBus.Unsubscribe("test topic", first.consume))
+	assert.Error(t, eventBus.Unsubscribe("unknown topic", handler))
+
+	assert.NoError(t, eventBus.Subscribe("test topic", first.consume))
+	assert.NoError(t, eventBus.Subscribe("test topic", second.consume))
+	assert.True(t, errors.Is(eventBus.Unsubscribe("test topic", first.consume), errHandlerAmbiguous))
+
+	eventBus.Publish("test topic", "test data")
+	assert.Equal(t, []string{"test data"}, first.received)
+	assert.Equal(t, []string{"test data"}, second.received)
+}
+
+func Test_simplifiedEventBus_UnsubscribeExactHandlerOfSeveralInstances(t *testing.T) {
+	eventBus := New()
+	first, second := &testSubscriber{}, &testSubscriber{}
+	consumeFirst, consumeSecond := first.consume, second.consume
+
+	assert.NoError(t, eventBus.Subscribe("test topic", consumeFirst))
+	assert.NoError(t, eventBus.Subscribe("test topic", consumeSecond))
+	assert.NoError(t, eventBus.Unsubscribe("test topic", consumeFirst))
+
+	eventBus.Publish("test topic", "test data")
+	assert.Empty(t, first.received)
+	assert.Equal(t, []string{"test data"}, second.received)
+
+	assert.NoError(t, eventBus.Unsubscribe("test topic", consumeSecond))
+	assert.Error(t, eventBus.Unsubscribe("test topic", consumeSecond))
+}
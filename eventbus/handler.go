@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type deliveryMode int
+
+const (
+	deliverSync deliveryMode = iota
+	deliverAsync
+	deliverQueued
+)
+
+var (
+	errHandlerNotFound  = errors.New("handler is not subscribed")
+	errHandlerAmbiguous = errors.New("handler matches several subscribers")
+)
+
+// mismatchError is returned when the event can not be passed to the handler.
+type mismatchError struct {
+	eventType   reflect.Type
+	handlerType reflect.Type
+}
+
+func (e *mismatchError) Error() string {
+	return fmt.Sprintf("event of type %v can not be passed to handler %v", e.eventType, e.handlerType)
+}
+
+type handler struct {
+	fn       reflect.Value
+	argType  reflect.Type
+	delivery deliveryMode
+	queue    *queue
+}
+
+// newHandler validates that fn is a function accepting at most one argument.
+func newHandler(fn interface{}, delivery deliveryMode) (*handler, error) {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return nil, fmt.Errorf("%T is not a function", fn)
+	}
+
+	fnType := value.Type()
+	if fnType.NumIn() > 1 || fnType.IsVariadic() {
+		return nil, fmt.Errorf("function %v should accept at most one argument", fnType)
+	}
+
+	h := &handler{fn: value, delivery: delivery}
+	if fnType.NumIn() == 1 {
+		h.argType = fnType.In(0)
+	}
+	if delivery == deliverQueued {
+		h.queue = &queue{}
+	}
+	return h, nil
+}
+
+// call passes the event to the handler, recovering from the handler panic.
+func (h *handler) call(data interface{}) (err error) {
+	args, err := h.args(data)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler %v panicked: %v", h.fn.Type(), r)
+		}
+	}()
+	h.fn.Call(args)
+	return nil
+}
+
+func (h *handler) args(data interface{}) ([]reflect.Value, error) {
+	if h.argType == nil {
+		return nil, nil
+	}
+	if data == nil {
+		return []reflect.Value{reflect.Zero(h.argType)}, nil
+	}
+
+	value := reflect.ValueOf(data)
+	if !value.Type().AssignableTo(h.argType) {
+		return nil, &mismatchError{eventType: value.Type(), handlerType: h.fn.Type()}
+	}
+	return []reflect.Value{value}, nil
+}
+
+// findHandler returns the index of the handler equal to fn.
+// The same func value is always matched exactly, even when several subscribers run the same code.
+// Method values and closures are distinct on every evaluation, so they are matched by their code,
+// as long as only one subscriber runs the same code.
+func findHandler(handlers []*handler, fn reflect.Value) (int, error) {
+	if fn.Kind() != reflect.Func {
+		return -1, errHandlerNotFound
+	}
+
+	for idx, h := range handlers {
+		if h.fn == fn {
+			return idx, nil
+		}
+	}
+
+	found := -1
+	for idx, h := range handlers {
+		if h.fn.Type() == fn.Type() && h.fn.Pointer() == fn.Pointer() {
+			if found >= 0 {
+				return -1, errHandlerAmbiguous
+			}
+			found = idx
+		}
+	}
+	if found < 0 {
+		return -1, errHandlerNotFound
+	}
+	return found, nil
+}
+
+// queue runs the pushed calls one by one in the order of pushing.
+type queue struct {
+	lock    sync.Mutex
+	calls   []func()
+	running bool
+}
+
+func (q *queue) push(call func()) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.calls = append(q.calls, call)
+	if !q.running {
+		q.running = true
+		go q.run()
+	}
+}
+
+func (q *queue) run() {
+	for {
+		q.lock.Lock()
+		if len(q.calls) == 0 {
+			q.running = false
+			q.lock.Unlock()
+			return
+		}
+		call := q.calls[0]
+		q.calls[0] = nil
+		q.calls = q.calls[1:]
+		q.lock.Unlock()
+
+		call()
+	}
+}
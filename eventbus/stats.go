@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// latencyBounds are the upper bounds of the handler latency histogram buckets.
+var latencyBounds = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// TopicStats represents delivery statistics of a single topic.
+type TopicStats struct {
+	// Published is the number of events published to the topic.
+	Published uint64
+	// Delivered is the number of handler calls completed successfully.
+	Delivered uint64
+	// Panics is the number of handler calls which panicked.
+	Panics uint64
+	// Mismatches is the number of events not delivered because of the handler signature.
+	Mismatches uint64
+	// Latency is the histogram of successful handler call durations.
+	Latency Histogram
+}
+
+// Histogram represents the distribution of durations.
+type Histogram struct {
+	// Bounds are the inclusive upper bounds of the buckets.
+	Bounds []time.Duration
+	// Counts holds the number of observations per bucket, the last bucket counts the ones above all bounds.
+	Counts []uint64
+	// Sum is the total of all observed durations.
+	Sum time.Duration
+}
+
+// Count returns the total number of observations.
+func (h Histogram) Count() uint64 {
+	var count uint64
+	for _, c := range h.Counts {
+		count += c
+	}
+	return count
+}
+
+type topicStats struct {
+	lock  sync.Mutex
+	stats TopicStats
+}
+
+func newTopicStats() *topicStats {
+	return &topicStats{
+		stats: TopicStats{
+			Latency: Histogram{Counts: make([]uint64, len(latencyBounds)+1)},
+		},
+	}
+}
+
+func (ts *topicStats) published() {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	ts.stats.Published++
+}
+
+func (ts *topicStats) delivered(latency time.Duration) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	ts.stats.Delivered++
+	ts.stats.Latency.Sum += latency
+
+	bucket := len(latencyBounds)
+	for i, bound := range latencyBounds {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	ts.stats.Latency.Counts[bucket]++
+}
+
+func (ts *topicStats) panicked() {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	ts.stats.Panics++
+}
+
+func (ts *topicStats) mismatched() {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	ts.stats.Mismatches++
+}
+
+func (ts *topicStats) snapshot() TopicStats {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	result := ts.stats
+	result.Latency.Bounds = append([]time.Duration(nil), latencyBounds...)
+	result.Latency.Counts = append([]uint64(nil), ts.stats.Latency.Counts...)
+	return result
+}
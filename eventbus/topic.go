@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Topic binds the topic name to the type of its events.
+// Subscribing through the topic rejects handlers of a wrong signature at the subscription time,
+// instead of failing at the event delivery.
+type Topic struct {
+	name      string
+	eventType reflect.Type
+}
+
+// NewTopic describes the topic which events are of the same type as the given sample event.
+func NewTopic(name string, sample interface{}) Topic {
+	return Topic{name: name, eventType: reflect.TypeOf(sample)}
+}
+
+// Name returns the name of the topic.
+func (t Topic) Name() string {
+	return t.name
+}
+
+// Subscribe validates the handler and subscribes it to the topic.
+func (t Topic) Subscribe(bus Subscriber, fn interface{}) error {
+	if err := t.validate(fn); err != nil {
+		return err
+	}
+	return bus.Subscribe(t.name, fn)
+}
+
+// SubscribeAsync validates the handler and subscribes it to the topic asynchronously.
+func (t Topic) SubscribeAsync(bus Subscriber, fn interface{}) error {
+	if err := t.validate(fn); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(t.name, fn)
+}
+
+// Publish validates the event and publishes it to the topic.
+func (t Topic) Publish(bus Publisher, data interface{}) error {
+	if dataType := reflect.TypeOf(data); dataType != t.eventType {
+		return fmt.Errorf("topic %q accepts events of type %v, got %v", t.name, t.eventType, dataType)
+	}
+
+	bus.Publish(t.name, data)
+	return nil
+}
+
+func (t Topic) validate(fn interface{}) error {
+	h, err := newHandler(fn, deliverSync)
+	if err != nil {
+		return fmt.Errorf("invalid handler of topic %q: %w", t.name, err)
+	}
+
+	if h.argType != nil && t.eventType != nil && !t.eventType.AssignableTo(h.argType) {
+		return fmt.Errorf("invalid handler of topic %q: %w", t.name, &mismatchError{eventType: t.eventType, handlerType: h.fn.Type()})
+	}
+	return nil
+}
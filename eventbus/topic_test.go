@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEvent struct {
+	Value string
+}
+
+func Test_Topic_Subscribe_ValidatesHandlerType(t *testing.T) {
+	eventBus := New()
+	topic := NewTopic("test topic", testEvent{})
+
+	var received testEvent
+	assert.NoError(t, topic.Subscribe(eventBus, func(e testEvent) {
+		received = e
+	}))
+	assert.NoError(t, topic.SubscribeAsync(eventBus, func(e interface{}) {}))
+	assert.NoError(t, topic.Subscribe(eventBus, func() {}))
+	assert.Error(t, topic.Subscribe(eventBus, func(e *testEvent) {}))
+	assert.Error(t, topic.SubscribeAsync(eventBus, func(e string) {}))
+
+	assert.NoError(t, topic.Publish(eventBus, testEvent{Value: "test"}))
+	assert.Equal(t, testEvent{Value: "test"}, received)
+
+	assert.Error(t, topic.Publish(eventBus, "test"))
+	assert.Equal(t, "test topic", topic.Name())
+}
@@ -18,7 +18,13 @@
 package feedback
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/mysteriumnetwork/feedback/client"
+	"github.com/mysteriumnetwork/node/core/connection/connectiontrace"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -28,6 +34,7 @@ import (
 type Reporter struct {
 	logCollector     logCollector
 	identityProvider identityProvider
+	connectionTraces connectionTraceProvider
 	feedbackAPI      *client.FeedbackAPI
 }
 
@@ -35,6 +42,7 @@ type Reporter struct {
 func NewReporter(
 	logCollector logCollector,
 	identityProvider identityProvider,
+	connectionTraces connectionTraceProvider,
 	feedbackURL string,
 ) (*Reporter, error) {
 	log.Info().Msg("Using feedback API at: " + feedbackURL)
@@ -45,12 +53,17 @@ func NewReporter(
 	return &Reporter{
 		logCollector:     logCollector,
 		identityProvider: identityProvider,
+		connectionTraces: connectionTraces,
 		feedbackAPI:      api,
 	}, nil
 }
 
 type logCollector interface {
-	Archive() (filepath string, err error)
+	Archive(extraFilepaths ...string) (filepath string, err error)
+}
+
+type connectionTraceProvider interface {
+	All() []connectiontrace.Recording
 }
 
 type identityProvider interface {
@@ -63,11 +76,22 @@ type UserReport struct {
 	Description string `json:"description"`
 }
 
-// NewIssue sends node logs, Identity and UserReport to the feedback service
+// NewIssue sends node logs, connection traces, Identity and UserReport to the feedback service
 func (r *Reporter) NewIssue(report UserReport) (result *client.CreateGithubIssueResult, err error) {
 	userID := r.currentIdentity()
 
-	archiveFilepath, err := r.logCollector.Archive()
+	tracesDir, err := ioutil.TempDir("", "connection-traces")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create connection traces directory")
+	}
+	defer os.RemoveAll(tracesDir)
+
+	tracesFilepath, err := r.writeConnectionTraces(tracesDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not write connection traces")
+	}
+
+	archiveFilepath, err := r.logCollector.Archive(tracesFilepath)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create log archive")
 	}
@@ -84,6 +108,16 @@ func (r *Reporter) NewIssue(report UserReport) (result *client.CreateGithubIssue
 	return result, nil
 }
 
+func (r *Reporter) writeConnectionTraces(dir string) (string, error) {
+	traces, err := json.MarshalIndent(r.connectionTraces.All(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	tracesFilepath := filepath.Join(dir, "connection-traces.json")
+	return tracesFilepath, ioutil.WriteFile(tracesFilepath, traces, 0600)
+}
+
 func (r *Reporter) currentIdentity() (identity string) {
 	identities := r.identityProvider.GetIdentities()
 	if len(identities) > 0 {
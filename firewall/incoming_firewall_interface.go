@@ -18,6 +18,7 @@
 package firewall
 
 import (
+	"fmt"
 	"net"
 )
 
@@ -28,7 +29,26 @@ type IncomingTrafficFirewall interface {
 	BlockIncomingTraffic(network net.IPNet) (IncomingRuleRemove, error)
 	AllowURLAccess(rawURLs ...string) (IncomingRuleRemove, error)
 	AllowIPAccess(ip net.IP) (IncomingRuleRemove, error)
+	AllowIncomingTraffic(network net.IPNet, rule TrafficRule) (IncomingRuleRemove, error)
+	DenyIncomingTraffic(network net.IPNet, rule TrafficRule) (IncomingRuleRemove, error)
+	LimitIncomingTraffic(network net.IPNet, bytes uint64) (IncomingRuleRemove, error)
 }
 
 // IncomingRuleRemove type defines function for removal of created rule.
 type IncomingRuleRemove func() error
+
+// TrafficRule matches the traffic by its destination and time, empty fields match any traffic.
+type TrafficRule struct {
+	Destination *net.IPNet
+	// Protocol is required to match ports ("tcp", "udp").
+	Protocol string
+	PortFrom int
+	PortTo   int
+	// TimeStart and TimeStop limit the rule to the time of the day in UTC ("08:00:00"), range wraps around midnight.
+	TimeStart string
+	TimeStop  string
+}
+
+func (r TrafficRule) String() string {
+	return fmt.Sprintf("destination=%v protocol=%q ports=%d-%d time=%s-%s", r.Destination, r.Protocol, r.PortFrom, r.PortTo, r.TimeStart, r.TimeStop)
+}
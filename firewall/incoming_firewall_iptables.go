@@ -20,6 +20,7 @@ package firewall
 import (
 	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 
 const (
 	incomingFirewallChain = "MYST_PROVIDER_FIREWALL"
+	incomingPolicyChain   = "MYST_PROVIDER_POLICY"
 	incomingFirewallIpset = "myst-provider-dst-whitelist"
 )
 
@@ -51,7 +53,10 @@ func (ibi *incomingFirewallIptables) Setup() error {
 	if _, err := ipset.Exec(op); err != nil {
 		return err
 	}
-	return ibi.setupFirewallChain()
+	if err := ibi.setupFirewallChain(); err != nil {
+		return err
+	}
+	return ibi.setupPolicyChain()
 }
 
 func (ibi *incomingFirewallIptables) Teardown() {
@@ -115,6 +120,80 @@ func (ibi *incomingFirewallIptables) AllowIPAccess(ip net.IP) (IncomingRuleRemov
 	}, nil
 }
 
+// AllowIncomingTraffic accepts matching traffic of the network, which is otherwise blocked by BlockIncomingTraffic.
+func (ibi *incomingFirewallIptables) AllowIncomingTraffic(network net.IPNet, rule TrafficRule) (IncomingRuleRemove, error) {
+	spec := append([]string{"-s", network.String()}, trafficRuleSpec(rule)...)
+	return addIncomingRule(iptables.InsertAt(incomingFirewallChain, 1).RuleSpec(append(spec, "-j", "ACCEPT")...))
+}
+
+// DenyIncomingTraffic rejects matching traffic of the network, regardless of it being blocked.
+func (ibi *incomingFirewallIptables) DenyIncomingTraffic(network net.IPNet, rule TrafficRule) (IncomingRuleRemove, error) {
+	spec := append([]string{"-s", network.String()}, trafficRuleSpec(rule)...)
+	return addIncomingRule(iptables.AppendTo(incomingPolicyChain).RuleSpec(append(spec, "-j", "REJECT")...))
+}
+
+// LimitIncomingTraffic rejects the traffic of the network in each direction, after the given number of bytes passes.
+func (ibi *incomingFirewallIptables) LimitIncomingTraffic(network net.IPNet, bytes uint64) (IncomingRuleRemove, error) {
+	quota := strconv.FormatUint(bytes, 10)
+	uplinkRemove, err := addIncomingRule(
+		iptables.AppendTo(incomingPolicyChain).RuleSpec("-s", network.String(), "-m", "quota", "!", "--quota", quota, "-j", "REJECT"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	downlinkRemove, err := addIncomingRule(
+		iptables.AppendTo(incomingPolicyChain).RuleSpec("-d", network.String(), "-m", "quota", "!", "--quota", quota, "-j", "REJECT"),
+	)
+	if err != nil {
+		uplinkRemove()
+		return nil, err
+	}
+
+	return func() error {
+		uplinkRemove()
+		return downlinkRemove()
+	}, nil
+}
+
+func addIncomingRule(rule iptables.Rule) (IncomingRuleRemove, error) {
+	remover, err := iptables.AddRuleWithRemoval(rule)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		remover()
+		return nil
+	}, nil
+}
+
+func trafficRuleSpec(rule TrafficRule) []string {
+	var spec []string
+	if rule.Destination != nil {
+		spec = append(spec, "-d", rule.Destination.String())
+	}
+	if rule.Protocol != "" {
+		spec = append(spec, "-p", rule.Protocol)
+		if rule.PortFrom > 0 {
+			ports := strconv.Itoa(rule.PortFrom)
+			if rule.PortTo > rule.PortFrom {
+				ports += ":" + strconv.Itoa(rule.PortTo)
+			}
+			spec = append(spec, "--dport", ports)
+		}
+	}
+	if rule.TimeStart != "" || rule.TimeStop != "" {
+		spec = append(spec, "-m", "time")
+		if rule.TimeStart != "" {
+			spec = append(spec, "--timestart", rule.TimeStart)
+		}
+		if rule.TimeStop != "" {
+			spec = append(spec, "--timestop", rule.TimeStop)
+		}
+	}
+	return spec
+}
+
 func (ibi *incomingFirewallIptables) checkIpsetVersion() error {
 	output, err := ipset.Exec(ipset.OpVersion())
 	if err != nil {
@@ -145,6 +224,17 @@ func (ibi *incomingFirewallIptables) setupFirewallChain() error {
 	return nil
 }
 
+func (ibi *incomingFirewallIptables) setupPolicyChain() error {
+	// Add chain
+	if _, err := iptables.Exec("-N", incomingPolicyChain); err != nil {
+		return err
+	}
+
+	// Insert rule - all forwarded packets are checked against policy rules before any other rule
+	_, err := iptables.Exec("-I", "FORWARD", "1", "-j", incomingPolicyChain)
+	return err
+}
+
 func (ibi *incomingFirewallIptables) cleanupStaleRules() error {
 	// List rules
 	rules, err := iptables.Exec("-S", "FORWARD")
@@ -153,7 +243,7 @@ func (ibi *incomingFirewallIptables) cleanupStaleRules() error {
 	}
 	for _, rule := range rules {
 		// detect if any references exist in FORWARD chain like -j MYST_PROVIDER_FIREWALL
-		if strings.HasSuffix(rule, incomingFirewallChain) {
+		if strings.HasSuffix(rule, incomingFirewallChain) || strings.HasSuffix(rule, incomingPolicyChain) {
 			deleteRule := strings.Replace(rule, "-A", "-D", 1)
 			deleteRuleArgs := strings.Split(deleteRule, " ")
 			if _, err := iptables.Exec(deleteRuleArgs...); err != nil {
@@ -162,21 +252,25 @@ func (ibi *incomingFirewallIptables) cleanupStaleRules() error {
 		}
 	}
 
-	// List chain rules
-	if _, err := iptables.Exec("-L", incomingFirewallChain); err != nil {
-		// error means no such chain - log error just in case and bail out
-		log.Info().Err(err).Msg("[setup] Got error while listing kill switch chain rules. Probably nothing to worry about")
-		return nil
-	}
+	for _, chain := range []string{incomingFirewallChain, incomingPolicyChain} {
+		// List chain rules
+		if _, err := iptables.Exec("-L", chain); err != nil {
+			// error means no such chain - log error just in case and continue
+			log.Info().Err(err).Msgf("[setup] Got error while listing %s chain rules. Probably nothing to worry about", chain)
+			continue
+		}
 
-	// Remove chain rules
-	if _, err := iptables.Exec("-F", incomingFirewallChain); err != nil {
-		return err
-	}
+		// Remove chain rules
+		if _, err := iptables.Exec("-F", chain); err != nil {
+			return err
+		}
 
-	// Remove chain
-	_, err = iptables.Exec("-X", incomingFirewallChain)
-	return err
+		// Remove chain
+		if _, err := iptables.Exec("-X", chain); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 var _ IncomingTrafficFirewall = &incomingFirewallIptables{}
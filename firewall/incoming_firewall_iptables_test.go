@@ -55,6 +55,8 @@ func Test_incomingFirewallIptables_Setup(t *testing.T) {
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-N MYST_PROVIDER_FIREWALL"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_FIREWALL -m set --match-set myst-provider-dst-whitelist dst -j ACCEPT"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_FIREWALL -j REJECT"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-N MYST_PROVIDER_POLICY"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-I FORWARD 1 -j MYST_PROVIDER_POLICY"))
 }
 
 func Test_incomingFirewallIptables_Teardown(t *testing.T) {
@@ -94,6 +96,7 @@ func Test_incomingFirewallIptables_TeardownIfPreviousCleanupFailed(t *testing.T)
 					"-P FORWARD ACCEPT",
 					// leftover - DNS firewall is still enabled
 					"-A FORWARD -s 10.8.0.1/24 -j MYST_PROVIDER_FIREWALL",
+					"-A FORWARD -j MYST_PROVIDER_POLICY",
 				},
 			},
 			// DNS fw chain still exists
@@ -113,8 +116,11 @@ func Test_incomingFirewallIptables_TeardownIfPreviousCleanupFailed(t *testing.T)
 	fw.Teardown()
 	assert.True(t, mockedIpset.VerifyCalledWithArgs("destroy myst-provider-dst-whitelist"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D FORWARD -s 10.8.0.1/24 -j MYST_PROVIDER_FIREWALL"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D FORWARD -j MYST_PROVIDER_POLICY"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-F MYST_PROVIDER_FIREWALL"))
 	assert.True(t, mockedIptables.VerifyCalledWithArgs("-X MYST_PROVIDER_FIREWALL"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-F MYST_PROVIDER_POLICY"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-X MYST_PROVIDER_POLICY"))
 }
 
 func Test_incomingFirewallIptables_BlockIncomingTraffic(t *testing.T) {
@@ -150,3 +156,60 @@ func Test_incomingFirewallIptables_AllowIPAccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, mockedIpset.VerifyCalledWithArgs("del myst-provider-dst-whitelist 1.2.3.4"))
 }
+
+func Test_incomingFirewallIptables_AllowIncomingTraffic(t *testing.T) {
+	mockedIptables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedIptables.Exec
+
+	fw := &incomingFirewallIptables{}
+
+	_, network, _ := net.ParseCIDR("10.8.0.1/24")
+	_, destination, _ := net.ParseCIDR("1.2.3.0/24")
+	removeRule, err := fw.AllowIncomingTraffic(*network, TrafficRule{Destination: destination, Protocol: "tcp", PortFrom: 80, PortTo: 90})
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-I MYST_PROVIDER_FIREWALL 1 -s 10.8.0.0/24 -d 1.2.3.0/24 -p tcp --dport 80:90 -j ACCEPT"))
+
+	err = removeRule()
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D MYST_PROVIDER_FIREWALL -s 10.8.0.0/24 -d 1.2.3.0/24 -p tcp --dport 80:90 -j ACCEPT"))
+}
+
+func Test_incomingFirewallIptables_DenyIncomingTraffic(t *testing.T) {
+	mockedIptables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedIptables.Exec
+
+	fw := &incomingFirewallIptables{}
+
+	_, network, _ := net.ParseCIDR("10.8.0.1/24")
+	removeRule, err := fw.DenyIncomingTraffic(*network, TrafficRule{Protocol: "udp", PortFrom: 53, TimeStart: "18:00:00", TimeStop: "07:59:59"})
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_POLICY -s 10.8.0.0/24 -p udp --dport 53 -m time --timestart 18:00:00 --timestop 07:59:59 -j REJECT"))
+
+	err = removeRule()
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D MYST_PROVIDER_POLICY -s 10.8.0.0/24 -p udp --dport 53 -m time --timestart 18:00:00 --timestop 07:59:59 -j REJECT"))
+}
+
+func Test_incomingFirewallIptables_LimitIncomingTraffic(t *testing.T) {
+	mockedIptables := iptablesExecMock{
+		mocks: map[string]iptablesExecResult{},
+	}
+	iptables.Exec = mockedIptables.Exec
+
+	fw := &incomingFirewallIptables{}
+
+	_, network, _ := net.ParseCIDR("10.8.0.1/24")
+	removeRule, err := fw.LimitIncomingTraffic(*network, 1000)
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_POLICY -s 10.8.0.0/24 -m quota ! --quota 1000 -j REJECT"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-A MYST_PROVIDER_POLICY -d 10.8.0.0/24 -m quota ! --quota 1000 -j REJECT"))
+
+	err = removeRule()
+	assert.NoError(t, err)
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D MYST_PROVIDER_POLICY -s 10.8.0.0/24 -m quota ! --quota 1000 -j REJECT"))
+	assert.True(t, mockedIptables.VerifyCalledWithArgs("-D MYST_PROVIDER_POLICY -d 10.8.0.0/24 -m quota ! --quota 1000 -j REJECT"))
+}
@@ -69,4 +69,31 @@ func (ifn *incomingFirewallNoop) AllowIPAccess(ip net.IP) (IncomingRuleRemove, e
 	}, nil
 }
 
+// AllowIncomingTraffic logs traffic for which access was requested.
+func (ifn *incomingFirewallNoop) AllowIncomingTraffic(network net.IPNet, rule TrafficRule) (IncomingRuleRemove, error) {
+	log.Info().Msgf("Allow traffic of %s: %s", network.String(), rule)
+	return func() error {
+		log.Info().Msgf("Rule for traffic of %s: %s removed", network.String(), rule)
+		return nil
+	}, nil
+}
+
+// DenyIncomingTraffic logs traffic for which denial was requested.
+func (ifn *incomingFirewallNoop) DenyIncomingTraffic(network net.IPNet, rule TrafficRule) (IncomingRuleRemove, error) {
+	log.Info().Msgf("Deny traffic of %s: %s", network.String(), rule)
+	return func() error {
+		log.Info().Msgf("Rule for traffic of %s: %s removed", network.String(), rule)
+		return nil
+	}, nil
+}
+
+// LimitIncomingTraffic logs traffic for which quota was requested.
+func (ifn *incomingFirewallNoop) LimitIncomingTraffic(network net.IPNet, bytes uint64) (IncomingRuleRemove, error) {
+	log.Info().Msgf("Limit traffic of %s to %d bytes", network.String(), bytes)
+	return func() error {
+		log.Info().Msgf("Traffic limit of %s removed", network.String())
+		return nil
+	}, nil
+}
+
 var _ IncomingTrafficFirewall = &incomingFirewallNoop{}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Keystore backends selectable by configuration.
+const (
+	KeystoreBackendFilesystem = "filesystem"
+	KeystoreBackendVault      = "vault"
+	KeystoreBackendRemote     = "remote"
+)
+
+// KeystoreBackend keeps private keys of identities: lists and creates accounts, unlocks them,
+// signs hashes and encrypts data with keys derived from them.
+// Signers created by SignerFactory and Manager.Unlock work with any of the backends.
+type KeystoreBackend interface {
+	Accounts() []accounts.Account
+	NewAccount(passphrase string) (accounts.Account, error)
+	Find(a accounts.Account) (accounts.Account, error)
+	Unlock(a accounts.Account, passphrase string) error
+	Lock(addr common.Address) error
+	SignHash(a accounts.Account, hash []byte) ([]byte, error)
+	Encrypt(addr common.Address, plaintext []byte) ([]byte, error)
+	Decrypt(addr common.Address, encrypted []byte) ([]byte, error)
+}
+
+func findAccount(list []accounts.Account, a accounts.Account) (accounts.Account, error) {
+	for _, account := range list {
+		if account.Address == a.Address {
+			return account, nil
+		}
+	}
+	return accounts.Account{}, accounts.ErrUnknownAccount
+}
+
+// encryptWithKey encrypts the plaintext with AES-GCM key derived from the private key.
+func encryptWithKey(privateKey *ecdsa.PrivateKey, plaintext []byte) ([]byte, error) {
+	gcm, err := derivedCipher(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptWithKey decrypts the message encrypted by encryptWithKey.
+func decryptWithKey(privateKey *ecdsa.PrivateKey, encrypted []byte) ([]byte, error) {
+	gcm, err := derivedCipher(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(encrypted) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, encrypted := encrypted[:nonceSize], encrypted[nonceSize:]
+	return gcm.Open(nil, nonce, encrypted, nil)
+}
+
+func derivedCipher(privateKey *ecdsa.PrivateKey) (cipher.AEAD, error) {
+	keyDerived, err := deriveKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := aes.NewCipher(keyDerived)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(c)
+}
+
+func deriveKey(privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	hashFunc := sha512.New
+	hkdfDerived := hkdf.New(hashFunc, privateKey.D.Bytes(), nil, nil)
+	key := make([]byte, 32)
+	_, err := io.ReadFull(hkdfDerived, key)
+	return key, err
+}
@@ -18,14 +18,8 @@
 package identity
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/ecdsa"
-	"crypto/rand"
-	"crypto/sha512"
-	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"sync"
 	"time"
@@ -34,7 +28,6 @@ import (
 	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
-	"golang.org/x/crypto/hkdf"
 )
 
 type ethKeystore interface {
@@ -152,28 +145,7 @@ func (ks *Keystore) Encrypt(addr common.Address, plaintext []byte) ([]byte, erro
 	if !found {
 		return nil, ethKs.ErrLocked
 	}
-
-	keyDerived, err := key.deriveKey()
-	if err != nil {
-		return nil, err
-	}
-
-	c, err := aes.NewCipher(keyDerived)
-	if err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(c)
-	if err != nil {
-		return nil, err
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
-	}
-
-	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+	return encryptWithKey(key.PrivateKey, plaintext)
 }
 
 // Decrypt takes a derived key for the given address and decrypts the encrypted message.
@@ -185,29 +157,7 @@ func (ks *Keystore) Decrypt(addr common.Address, encrypted []byte) ([]byte, erro
 	if !found {
 		return nil, ethKs.ErrLocked
 	}
-
-	keyDerived, err := key.deriveKey()
-	if err != nil {
-		return nil, err
-	}
-
-	c, err := aes.NewCipher(keyDerived)
-	if err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(c)
-	if err != nil {
-		return nil, err
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(encrypted) < nonceSize {
-		return nil, errors.New("ciphertext too short")
-	}
-
-	nonce, encrypted := encrypted[:nonceSize], encrypted[nonceSize:]
-	return gcm.Open(nil, nonce, encrypted, nil)
+	return decryptWithKey(key.PrivateKey, encrypted)
 }
 
 // SignHash calculates a ECDSA signature for the given hash. The produced
@@ -238,14 +188,6 @@ type unlocked struct {
 	abort chan struct{}
 }
 
-func (u *unlocked) deriveKey() ([]byte, error) {
-	hashFunc := sha512.New
-	hkdfDerived := hkdf.New(hashFunc, u.Key.PrivateKey.D.Bytes(), nil, nil)
-	key := make([]byte, 32)
-	_, err := io.ReadFull(hkdfDerived, key)
-	return key, err
-}
-
 func loadStoredKey(addr common.Address, filename, auth string) (*ethKs.Key, error) {
 	// Load the key from the keystore and decrypt its contents
 	keyjson, err := ioutil.ReadFile(filename)
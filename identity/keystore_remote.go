@@ -0,0 +1,223 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/rs/zerolog/log"
+)
+
+// Methods of the remote signer JSON-RPC protocol.
+const (
+	remoteMethodAccounts   = "keystore_accounts"
+	remoteMethodNewAccount = "keystore_newAccount"
+	remoteMethodUnlock     = "keystore_unlock"
+	remoteMethodLock       = "keystore_lock"
+	remoteMethodSignHash   = "keystore_signHash"
+	remoteMethodEncrypt    = "keystore_encrypt"
+	remoteMethodDecrypt    = "keystore_decrypt"
+)
+
+// Error codes of the remote signer JSON-RPC protocol, mapped back to the keystore errors.
+const (
+	remoteErrorInternal       = -32000
+	remoteErrorLocked         = -32001
+	remoteErrorUnknownAccount = -32002
+	remoteErrorInvalidRequest = -32600
+	remoteErrorMethodNotFound = -32601
+)
+
+// remoteParams holds parameters of any remote signer method, unused ones are omitted.
+type remoteParams struct {
+	Address    *common.Address `json:"address,omitempty"`
+	Passphrase string          `json:"passphrase,omitempty"`
+	Hash       hexutil.Bytes   `json:"hash,omitempty"`
+	Data       hexutil.Bytes   `json:"data,omitempty"`
+}
+
+type remoteRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  *remoteParams `json:"params,omitempty"`
+}
+
+type remoteResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *remoteError    `json:"error,omitempty"`
+}
+
+type remoteError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *remoteError) Error() string {
+	return fmt.Sprintf("remote signer error %d: %s", e.Code, e.Message)
+}
+
+func (e *remoteError) toKeystoreError() error {
+	switch e.Code {
+	case remoteErrorLocked:
+		return ethKs.ErrLocked
+	case remoteErrorUnknownAccount:
+		return accounts.ErrUnknownAccount
+	default:
+		return e
+	}
+}
+
+// NewKeystoreRemote creates keystore, which keeps keys in an external signer and calls it over JSON-RPC (HTTP POST).
+// Private keys never leave the signer: the node asks it to sign hashes and to encrypt or decrypt data.
+// Token is sent as a bearer token of the requests, when given.
+func NewKeystoreRemote(url, token string, timeout time.Duration) *RemoteKeystore {
+	return &RemoteKeystore{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// RemoteKeystore handles accounts kept by the remote signer.
+type RemoteKeystore struct {
+	url    string
+	token  string
+	client *http.Client
+	lastID uint64
+}
+
+// Accounts returns accounts of the remote signer.
+func (rs *RemoteKeystore) Accounts() []accounts.Account {
+	var addresses []common.Address
+	if err := rs.call(remoteMethodAccounts, nil, &addresses); err != nil {
+		log.Error().Err(err).Msgf("Could not list accounts of remote signer %s", rs.url)
+		return nil
+	}
+
+	list := make([]accounts.Account, len(addresses))
+	for i, address := range addresses {
+		list[i] = rs.account(address)
+	}
+	return list
+}
+
+// Find finds the account in the remote signer.
+func (rs *RemoteKeystore) Find(a accounts.Account) (accounts.Account, error) {
+	return findAccount(rs.Accounts(), a)
+}
+
+// NewAccount asks the remote signer to generate a new key.
+func (rs *RemoteKeystore) NewAccount(passphrase string) (accounts.Account, error) {
+	var address common.Address
+	if err := rs.call(remoteMethodNewAccount, &remoteParams{Passphrase: passphrase}, &address); err != nil {
+		return accounts.Account{}, err
+	}
+	return rs.account(address), nil
+}
+
+// Unlock asks the remote signer to unlock the account, the signer decides how the passphrase is checked.
+func (rs *RemoteKeystore) Unlock(a accounts.Account, passphrase string) error {
+	return rs.call(remoteMethodUnlock, &remoteParams{Address: &a.Address, Passphrase: passphrase}, nil)
+}
+
+// Lock asks the remote signer to lock the account.
+func (rs *RemoteKeystore) Lock(addr common.Address) error {
+	return rs.call(remoteMethodLock, &remoteParams{Address: &addr}, nil)
+}
+
+// SignHash asks the remote signer for ECDSA signature of the given hash in the [R || S || V] format where V is 0 or 1.
+func (rs *RemoteKeystore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	err := rs.call(remoteMethodSignHash, &remoteParams{Address: &a.Address, Hash: hash}, &signature)
+	return signature, err
+}
+
+// Encrypt asks the remote signer to encrypt the plaintext with the key derived for the given address.
+func (rs *RemoteKeystore) Encrypt(addr common.Address, plaintext []byte) ([]byte, error) {
+	var encrypted hexutil.Bytes
+	err := rs.call(remoteMethodEncrypt, &remoteParams{Address: &addr, Data: plaintext}, &encrypted)
+	return encrypted, err
+}
+
+// Decrypt asks the remote signer to decrypt the message with the key derived for the given address.
+func (rs *RemoteKeystore) Decrypt(addr common.Address, encrypted []byte) ([]byte, error) {
+	var plaintext hexutil.Bytes
+	err := rs.call(remoteMethodDecrypt, &remoteParams{Address: &addr, Data: encrypted}, &plaintext)
+	return plaintext, err
+}
+
+func (rs *RemoteKeystore) account(address common.Address) accounts.Account {
+	return accounts.Account{
+		Address: address,
+		URL:     accounts.URL{Scheme: KeystoreBackendRemote, Path: rs.url},
+	}
+}
+
+func (rs *RemoteKeystore) call(method string, params *remoteParams, result interface{}) error {
+	body, err := json.Marshal(remoteRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&rs.lastID, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, rs.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if rs.token != "" {
+		req.Header.Set("Authorization", "Bearer "+rs.token)
+	}
+
+	resp, err := rs.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not call remote signer: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("remote signer responded with status %d", resp.StatusCode)
+	}
+
+	var response remoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("could not parse remote signer response: %w", err)
+	}
+	if response.Error != nil {
+		return response.Error.toKeystoreError()
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
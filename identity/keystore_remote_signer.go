@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// NewRemoteSigner serves the keystore over the JSON-RPC protocol spoken by RemoteKeystore.
+// It is a local stand-in for external signers, e.g. for tests or for keeping keys on a separate host.
+// Requests must carry the bearer token, when it is given.
+func NewRemoteSigner(backend KeystoreBackend, token string) http.Handler {
+	return &remoteSigner{backend: backend, token: token}
+}
+
+type remoteSigner struct {
+	backend KeystoreBackend
+	token   string
+}
+
+func (s *remoteSigner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.token)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req remoteRequest
+	response := remoteResponse{JSONRPC: "2.0"}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error = &remoteError{Code: remoteErrorInvalidRequest, Message: err.Error()}
+	} else {
+		response.ID = req.ID
+		result, err := s.handle(req.Method, req.Params)
+		if err != nil {
+			response.Error = toRemoteError(err)
+		} else if response.Result, err = json.Marshal(result); err != nil {
+			response.Error = toRemoteError(err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+var errRemoteAddressMissing = &remoteError{Code: remoteErrorInvalidRequest, Message: "address is required"}
+
+func (s *remoteSigner) handle(method string, params *remoteParams) (interface{}, error) {
+	if params == nil {
+		params = &remoteParams{}
+	}
+
+	switch method {
+	case remoteMethodAccounts:
+		list := s.backend.Accounts()
+		addresses := make([]string, len(list))
+		for i, account := range list {
+			addresses[i] = account.Address.Hex()
+		}
+		return addresses, nil
+	case remoteMethodNewAccount:
+		account, err := s.backend.NewAccount(params.Passphrase)
+		return account.Address, err
+	}
+
+	if params.Address == nil {
+		return nil, errRemoteAddressMissing
+	}
+	account := accounts.Account{Address: *params.Address}
+
+	switch method {
+	case remoteMethodUnlock:
+		account, err := s.backend.Find(account)
+		if err != nil {
+			return nil, err
+		}
+		return nil, s.backend.Unlock(account, params.Passphrase)
+	case remoteMethodLock:
+		return nil, s.backend.Lock(account.Address)
+	case remoteMethodSignHash:
+		signature, err := s.backend.SignHash(account, params.Hash)
+		return hexutil.Bytes(signature), err
+	case remoteMethodEncrypt:
+		encrypted, err := s.backend.Encrypt(account.Address, params.Data)
+		return hexutil.Bytes(encrypted), err
+	case remoteMethodDecrypt:
+		plaintext, err := s.backend.Decrypt(account.Address, params.Data)
+		return hexutil.Bytes(plaintext), err
+	default:
+		return nil, &remoteError{Code: remoteErrorMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+func toRemoteError(err error) *remoteError {
+	var rpcErr *remoteError
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.Is(err, ethKs.ErrLocked):
+		return &remoteError{Code: remoteErrorLocked, Message: err.Error()}
+	case errors.Is(err, accounts.ErrUnknownAccount):
+		return &remoteError{Code: remoteErrorUnknownAccount, Message: err.Error()}
+	default:
+		return &remoteError{Code: remoteErrorInternal, Message: err.Error()}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RemoteKeystore(t *testing.T) {
+	vault, cleanup := newTestVault(t)
+	defer cleanup()
+	signer := httptest.NewServer(NewRemoteSigner(vault, "token"))
+	defer signer.Close()
+
+	remote := NewKeystoreRemote(signer.URL, "token", time.Second)
+	manager := NewIdentityManager(remote, eventbus.New())
+
+	id, err := manager.CreateNewIdentity("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []Identity{id}, manager.GetIdentities())
+
+	_, err = NewSigner(remote, id).Sign([]byte(secretMessage))
+	assert.Equal(t, ethKs.ErrLocked, err)
+
+	assert.Error(t, manager.Unlock(1, id.Address, "wrong"))
+	assert.NoError(t, manager.Unlock(1, id.Address, "secret"))
+
+	signature, err := NewSigner(remote, id).Sign([]byte(secretMessage))
+	assert.NoError(t, err)
+	assert.True(t, NewVerifierIdentity(id).Verify([]byte(secretMessage), signature))
+
+	encrypted, err := remote.Encrypt(identityToAccount(id).Address, []byte(secretMessage))
+	assert.NoError(t, err)
+	decrypted, err := vault.Decrypt(identityToAccount(id).Address, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, secretMessage, string(decrypted))
+}
+
+func Test_RemoteKeystore_RequiresToken(t *testing.T) {
+	vault, cleanup := newTestVault(t)
+	defer cleanup()
+	signer := httptest.NewServer(NewRemoteSigner(vault, "token"))
+	defer signer.Close()
+
+	remote := NewKeystoreRemote(signer.URL, "other", time.Second)
+
+	_, err := remote.NewAccount("secret")
+	assert.Error(t, err)
+	assert.Empty(t, vault.Accounts())
+}
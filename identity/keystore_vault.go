@@ -0,0 +1,355 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	vaultVersion = 1
+	vaultScryptR = 8
+)
+
+// ErrVaultPassphrase is returned when the vault can not be opened with the given passphrase.
+var ErrVaultPassphrase = errors.New("could not decrypt vault: wrong passphrase or corrupted file")
+
+// NewKeystoreVault creates keystore, which keeps all keys in a single file encrypted with a passphrase.
+// The file key is derived with scrypt and keys are sealed with ChaCha20-Poly1305, the way age
+// passphrase recipients do. Identities of the vault share its passphrase, which is set by the first NewAccount.
+func NewKeystoreVault(path string, scryptN, scryptP int) *VaultKeystore {
+	return &VaultKeystore{
+		path:     path,
+		scryptN:  scryptN,
+		scryptP:  scryptP,
+		unlocked: make(map[common.Address]*ecdsa.PrivateKey),
+	}
+}
+
+// VaultKeystore handles accounts kept in the encrypted vault file.
+type VaultKeystore struct {
+	path    string
+	scryptN int
+	scryptP int
+
+	fileMu   sync.Mutex
+	unlocked map[common.Address]*ecdsa.PrivateKey
+	mu       sync.RWMutex
+}
+
+type vaultFile struct {
+	Version int `json:"version"`
+	// Addresses are kept in clear, so accounts can be listed while the vault is locked.
+	// They are authenticated as additional data of the ciphertext.
+	Addresses  []common.Address `json:"addresses"`
+	ScryptN    int              `json:"scrypt_n"`
+	ScryptR    int              `json:"scrypt_r"`
+	ScryptP    int              `json:"scrypt_p"`
+	Salt       []byte           `json:"salt"`
+	Nonce      []byte           `json:"nonce"`
+	Ciphertext []byte           `json:"ciphertext"`
+}
+
+// Accounts returns accounts of the vault.
+func (vs *VaultKeystore) Accounts() []accounts.Account {
+	vs.fileMu.Lock()
+	defer vs.fileMu.Unlock()
+
+	file, err := vs.read()
+	if err != nil {
+		log.Error().Err(err).Msgf("Could not read keystore vault %s", vs.path)
+		return nil
+	}
+	if file == nil {
+		return nil
+	}
+
+	list := make([]accounts.Account, len(file.Addresses))
+	for i, address := range file.Addresses {
+		list[i] = vs.account(address)
+	}
+	return list
+}
+
+// Find finds the account in the vault.
+func (vs *VaultKeystore) Find(a accounts.Account) (accounts.Account, error) {
+	return findAccount(vs.Accounts(), a)
+}
+
+// NewAccount generates a new key and stores it into the vault.
+// The vault is created with the passphrase if it does not exist yet.
+func (vs *VaultKeystore) NewAccount(passphrase string) (accounts.Account, error) {
+	vs.fileMu.Lock()
+	defer vs.fileMu.Unlock()
+
+	file, err := vs.read()
+	if err != nil {
+		return accounts.Account{}, err
+	}
+
+	var addresses []common.Address
+	keys := make(map[common.Address]*ecdsa.PrivateKey)
+	if file != nil {
+		if keys, err = file.open(passphrase); err != nil {
+			return accounts.Account{}, err
+		}
+		addresses = file.Addresses
+	}
+	defer zeroKeys(keys)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	keys[address] = key
+
+	if err := vs.write(append(addresses, address), keys, passphrase); err != nil {
+		return accounts.Account{}, err
+	}
+	return vs.account(address), nil
+}
+
+// Unlock opens the vault with the passphrase and keeps the key of the account in memory.
+func (vs *VaultKeystore) Unlock(a accounts.Account, passphrase string) error {
+	vs.fileMu.Lock()
+	file, err := vs.read()
+	vs.fileMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return accounts.ErrUnknownAccount
+	}
+
+	keys, err := file.open(passphrase)
+	if err != nil {
+		return err
+	}
+	key, ok := keys[a.Address]
+	if !ok {
+		zeroKeys(keys)
+		return accounts.ErrUnknownAccount
+	}
+	delete(keys, a.Address)
+	zeroKeys(keys)
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if previous, found := vs.unlocked[a.Address]; found {
+		zeroKey(previous)
+	}
+	vs.unlocked[a.Address] = key
+	return nil
+}
+
+// Lock removes the private key with the given address from memory.
+func (vs *VaultKeystore) Lock(addr common.Address) error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if key, found := vs.unlocked[addr]; found {
+		zeroKey(key)
+		delete(vs.unlocked, addr)
+	}
+	return nil
+}
+
+// SignHash calculates a ECDSA signature for the given hash. The produced
+// signature is in the [R || S || V] format where V is 0 or 1.
+func (vs *VaultKeystore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	key, found := vs.unlocked[a.Address]
+	if !found {
+		return nil, ethKs.ErrLocked
+	}
+	return crypto.Sign(hash, key)
+}
+
+// Encrypt takes a derived key for the given address and encrypts the plaintext.
+func (vs *VaultKeystore) Encrypt(addr common.Address, plaintext []byte) ([]byte, error) {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	key, found := vs.unlocked[addr]
+	if !found {
+		return nil, ethKs.ErrLocked
+	}
+	return encryptWithKey(key, plaintext)
+}
+
+// Decrypt takes a derived key for the given address and decrypts the encrypted message.
+func (vs *VaultKeystore) Decrypt(addr common.Address, encrypted []byte) ([]byte, error) {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	key, found := vs.unlocked[addr]
+	if !found {
+		return nil, ethKs.ErrLocked
+	}
+	return decryptWithKey(key, encrypted)
+}
+
+func (vs *VaultKeystore) account(address common.Address) accounts.Account {
+	return accounts.Account{
+		Address: address,
+		URL:     accounts.URL{Scheme: KeystoreBackendVault, Path: vs.path},
+	}
+}
+
+// read reads the vault file, nil is returned if the vault is not created yet.
+func (vs *VaultKeystore) read() (*vaultFile, error) {
+	data, err := ioutil.ReadFile(vs.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("could not parse keystore vault: %w", err)
+	}
+	if file.Version != vaultVersion {
+		return nil, fmt.Errorf("unsupported keystore vault version %d", file.Version)
+	}
+	return &file, nil
+}
+
+// write seals the keys with the passphrase and replaces the vault file, accounts are listed in the given order.
+func (vs *VaultKeystore) write(addresses []common.Address, keys map[common.Address]*ecdsa.PrivateKey, passphrase string) error {
+	file := vaultFile{
+		Version:   vaultVersion,
+		Addresses: addresses,
+		ScryptN:   vs.scryptN,
+		ScryptR:   vaultScryptR,
+		ScryptP:   vs.scryptP,
+		Salt:      make([]byte, 16),
+	}
+	if _, err := io.ReadFull(rand.Reader, file.Salt); err != nil {
+		return err
+	}
+
+	plaintext := make(map[common.Address][]byte, len(keys))
+	for _, address := range addresses {
+		plaintext[address] = crypto.FromECDSA(keys[address])
+	}
+	payload, err := json.Marshal(plaintext)
+	if err != nil {
+		return err
+	}
+
+	aead, err := file.cipher(passphrase)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, file.Nonce); err != nil {
+		return err
+	}
+	additionalData, err := json.Marshal(file.Addresses)
+	if err != nil {
+		return err
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, payload, additionalData)
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(vs.path), 0700); err != nil {
+		return err
+	}
+	tmpPath := vs.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, vs.path)
+}
+
+// open decrypts keys of the vault.
+func (file *vaultFile) open(passphrase string) (map[common.Address]*ecdsa.PrivateKey, error) {
+	aead, err := file.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	additionalData, err := json.Marshal(file.Addresses)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := aead.Open(nil, file.Nonce, file.Ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrVaultPassphrase
+	}
+
+	var plaintext map[common.Address][]byte
+	if err := json.Unmarshal(payload, &plaintext); err != nil {
+		return nil, fmt.Errorf("could not parse keystore vault keys: %w", err)
+	}
+
+	keys := make(map[common.Address]*ecdsa.PrivateKey, len(plaintext))
+	for address, keyBytes := range plaintext {
+		key, err := crypto.ToECDSA(keyBytes)
+		if err != nil {
+			zeroKeys(keys)
+			return nil, fmt.Errorf("invalid key of %s in keystore vault: %w", address.Hex(), err)
+		}
+		if crypto.PubkeyToAddress(key.PublicKey) != address {
+			zeroKeys(keys)
+			return nil, fmt.Errorf("key content mismatch in keystore vault: want account %x", address)
+		}
+		keys[address] = key
+	}
+	return keys, nil
+}
+
+func (file *vaultFile) cipher(passphrase string) (cipher.AEAD, error) {
+	fileKey, err := scrypt.Key([]byte(passphrase), file.Salt, file.ScryptN, file.ScryptR, file.ScryptP, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(fileKey)
+}
+
+func zeroKeys(keys map[common.Address]*ecdsa.PrivateKey) {
+	for _, key := range keys {
+		zeroKey(key)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethKs "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVault(t *testing.T) (*VaultKeystore, func()) {
+	dir, err := ioutil.TempDir("", "keystoreVaultTest")
+	assert.NoError(t, err)
+
+	vault := NewKeystoreVault(filepath.Join(dir, "vault.json"), ethKs.LightScryptN, ethKs.LightScryptP)
+	return vault, func() { os.RemoveAll(dir) }
+}
+
+func Test_VaultKeystore(t *testing.T) {
+	vault, cleanup := newTestVault(t)
+	defer cleanup()
+
+	assert.Empty(t, vault.Accounts())
+
+	first, err := vault.NewAccount("secret")
+	assert.NoError(t, err)
+	_, err = vault.NewAccount("wrong")
+	assert.Equal(t, ErrVaultPassphrase, err)
+	second, err := vault.NewAccount("secret")
+	assert.NoError(t, err)
+
+	list := vault.Accounts()
+	assert.Len(t, list, 2)
+	assert.Equal(t, first.Address, list[0].Address)
+	assert.Equal(t, second.Address, list[1].Address)
+
+	t.Run("Fails to sign or encrypt while locked", func(t *testing.T) {
+		_, err := vault.SignHash(first, messageHash([]byte(secretMessage)))
+		assert.Equal(t, ethKs.ErrLocked, err)
+		_, err = vault.Encrypt(first.Address, []byte(secretMessage))
+		assert.Equal(t, ethKs.ErrLocked, err)
+	})
+
+	t.Run("Fails to unlock with wrong passphrase", func(t *testing.T) {
+		assert.Equal(t, ErrVaultPassphrase, vault.Unlock(first, "wrong"))
+	})
+
+	t.Run("Unlocks only the requested account", func(t *testing.T) {
+		assert.NoError(t, vault.Unlock(first, "secret"))
+
+		encrypted, err := vault.Encrypt(first.Address, []byte(secretMessage))
+		assert.NoError(t, err)
+		decrypted, err := vault.Decrypt(first.Address, encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, secretMessage, string(decrypted))
+
+		_, err = vault.SignHash(second, messageHash([]byte(secretMessage)))
+		assert.Equal(t, ethKs.ErrLocked, err)
+	})
+
+	t.Run("Locks the account", func(t *testing.T) {
+		assert.NoError(t, vault.Lock(first.Address))
+		_, err := vault.SignHash(first, messageHash([]byte(secretMessage)))
+		assert.Equal(t, ethKs.ErrLocked, err)
+	})
+}
+
+func Test_VaultKeystore_SignsThroughManager(t *testing.T) {
+	vault, cleanup := newTestVault(t)
+	defer cleanup()
+
+	manager := NewIdentityManager(vault, eventbus.New())
+	id, err := manager.CreateNewIdentity("secret")
+	assert.NoError(t, err)
+	assert.NoError(t, manager.Unlock(1, id.Address, "secret"))
+
+	signature, err := NewSigner(vault, id).Sign([]byte(secretMessage))
+	assert.NoError(t, err)
+	assert.True(t, NewVerifierIdentity(id).Verify([]byte(secretMessage), signature))
+}
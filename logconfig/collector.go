@@ -36,8 +36,8 @@ func NewCollector(options *LogOptions) *Collector {
 	return &Collector{options: options}
 }
 
-// Archive creates ZIP archive containing all node log files.
-func (c *Collector) Archive() (outputFilepath string, err error) {
+// Archive creates ZIP archive containing all node log files and given extra files.
+func (c *Collector) Archive(extraFilepaths ...string) (outputFilepath string, err error) {
 	if c.options.Filepath == "" {
 		return "", errors.New("file logging is disabled, can't retrieve logs")
 	}
@@ -45,6 +45,7 @@ func (c *Collector) Archive() (outputFilepath string, err error) {
 	if err != nil {
 		return "", err
 	}
+	filepaths = append(filepaths, extraFilepaths...)
 
 	zip := archiver.NewZip()
 	zip.OverwriteExisting = true
@@ -18,6 +18,7 @@
 package logconfig
 
 import (
+	"archive/zip"
 	"io/ioutil"
 	"os"
 	"path"
@@ -79,6 +80,41 @@ func TestCollector_Archive(t *testing.T) {
 	assert.NotEmpty(zipFilename)
 }
 
+func TestCollector_Archive_IncludesExtraFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	// given
+	baseName := "mysterium-test.log"
+	fn := NewTempFileName(t, baseName)
+	defer os.Remove(fn)
+
+	extra := NewTempFileName(t, "connection-traces.json")
+	defer os.Remove(extra)
+
+	opts := LogOptions{
+		LogLevel: zerolog.DebugLevel,
+		Filepath: path.Join(path.Dir(fn), baseName),
+	}
+	collector := NewCollector(&opts)
+
+	// when
+	zipFilename, err := collector.Archive(extra)
+	defer os.Remove(zipFilename)
+
+	// then
+	assert.NoError(err)
+	archive, err := zip.OpenReader(zipFilename)
+	assert.NoError(err)
+	defer archive.Close()
+
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.Contains(names, path.Base(fn))
+	assert.Contains(names, path.Base(extra))
+}
+
 func NewTempFileName(t *testing.T, pattern string) string {
 	file, err := ioutil.TempFile("", pattern)
 	assert.NoError(t, err)
@@ -24,6 +24,10 @@ const (
 	AccessPolicyTypeDNSHostname = "dns_hostname"
 	// AccessPolicyTypeDNSZone Explicitly allow just specific DNS zone ("example.com" matches "example.com" and all of its subdomains)
 	AccessPolicyTypeDNSZone = "dns_zone"
+	// AccessPolicyTypeCIDR Explicitly match traffic to specific destination network ("10.0.0.0/8")
+	AccessPolicyTypeCIDR = "cidr"
+	// AccessPolicyTypePort Explicitly match traffic to specific destination port of optional protocol ("tcp/443", "udp/5000-6000", "53")
+	AccessPolicyTypePort = "port"
 )
 
 // AccessPolicy represents the access controls for proposal
@@ -38,6 +42,11 @@ type AccessPolicyRuleSet struct {
 	Title       string       `json:"title"`
 	Description string       `json:"description"`
 	Allow       []AccessRule `json:"allow"`
+	Deny        []AccessRule `json:"deny,omitempty"`
+	// Schedule limits the time of the day when the service is available, it's always available if empty.
+	Schedule []AccessTimeWindow `json:"schedule,omitempty"`
+	// Quota limits resources used by a single consumer session.
+	Quota *AccessQuota `json:"quota,omitempty"`
 }
 
 // AccessRule represents rule specifying whether connection should be allowed
@@ -45,3 +54,16 @@ type AccessRule struct {
 	Type  string `json:"type"`
 	Value string `json:"value"`
 }
+
+// AccessTimeWindow represents the time of the day in UTC ("08:00" - "18:00").
+// Window wraps around midnight if it ends before it starts ("22:00" - "06:00").
+type AccessTimeWindow struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// AccessQuota represents limits of resources used by a single consumer session
+type AccessQuota struct {
+	// TrafficBytes limits the traffic of the session in each direction.
+	TrafficBytes uint64 `json:"traffic_bytes,omitempty"`
+}
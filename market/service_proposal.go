@@ -19,6 +19,7 @@ package market
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/mysteriumnetwork/node/identity"
 )
@@ -57,6 +58,21 @@ type ServiceProposal struct {
 
 	// AccessPolicies represents the access controls for proposal
 	AccessPolicies *[]AccessPolicy `json:"access_policies,omitempty"`
+
+	// BandwidthLimit represents the bandwidth cap applied to every consumer session
+	BandwidthLimit *BandwidthLimit `json:"bandwidth_limit,omitempty"`
+
+	// NATType represents NAT type of the provider, empty if unknown
+	NATType string `json:"nat_type,omitempty"`
+
+	// HermesIDs lists hermeses the provider accepts payments through, empty if not advertised by the provider
+	HermesIDs []string `json:"hermes_ids,omitempty"`
+}
+
+// BandwidthLimit describes bandwidth cap of a consumer session, zero value means unlimited
+type BandwidthLimit struct {
+	UplinkKbps   int `json:"uplink_kbps"`
+	DownlinkKbps int `json:"downlink_kbps"`
 }
 
 // UniqueID returns unique proposal composite ID
@@ -79,6 +95,9 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 		PaymentMethod     *json.RawMessage `json:"payment_method"`
 		ProviderContacts  *json.RawMessage `json:"provider_contacts"`
 		AccessPolicies    *[]AccessPolicy  `json:"access_policies,omitempty"`
+		BandwidthLimit    *BandwidthLimit  `json:"bandwidth_limit,omitempty"`
+		NATType           string           `json:"nat_type,omitempty"`
+		HermesIDs         []string         `json:"hermes_ids,omitempty"`
 	}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
 		return err
@@ -106,6 +125,9 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 	proposal.ProviderContacts = unserializeContacts(jsonData.ProviderContacts)
 
 	proposal.AccessPolicies = jsonData.AccessPolicies
+	proposal.BandwidthLimit = jsonData.BandwidthLimit
+	proposal.NATType = jsonData.NATType
+	proposal.HermesIDs = jsonData.HermesIDs
 	return nil
 }
 
@@ -123,6 +145,30 @@ func (proposal *ServiceProposal) SetAccessPolicies(ap *[]AccessPolicy) {
 	proposal.AccessPolicies = ap
 }
 
+// SetBandwidthLimit updates service proposal with the bandwidth cap of consumer sessions
+func (proposal *ServiceProposal) SetBandwidthLimit(limit *BandwidthLimit) {
+	proposal.BandwidthLimit = limit
+}
+
+// SetHermesIDs updates service proposal with hermeses the provider accepts payments through
+func (proposal *ServiceProposal) SetHermesIDs(hermesIDs []string) {
+	proposal.HermesIDs = hermesIDs
+}
+
+// AcceptsHermes returns true if provider accepts payments through the given hermes.
+// Proposals not listing any hermes are assumed to accept any of them, as older providers did not advertise it.
+func (proposal *ServiceProposal) AcceptsHermes(hermesID string) bool {
+	if len(proposal.HermesIDs) == 0 {
+		return true
+	}
+	for _, id := range proposal.HermesIDs {
+		if strings.EqualFold(id, hermesID) {
+			return true
+		}
+	}
+	return false
+}
+
 // SetPaymentMethod updates payment method in the proposal.
 func (proposal *ServiceProposal) SetPaymentMethod(pm PaymentMethod) {
 	if pm != nil {
@@ -257,3 +257,55 @@ func Test_ServiceProposal_UnserializeAccessPolicy(t *testing.T) {
 	assert.Equal(t, expected, actual)
 	assert.True(t, actual.IsSupported())
 }
+
+func Test_ServiceProposal_UnserializeBandwidthLimit(t *testing.T) {
+	jsonData := []byte(`{
+		"id": 1,
+		"format": "format/X",
+		"service_type": "mock_service",
+		"service_definition": null,
+		"payment_method_type": "mock_payment",
+		"payment_method": {},
+		"provider_id": "node",
+		"provider_contacts": [
+			{ "type" : "mock_contact" , "definition" : {}}
+		],
+		"bandwidth_limit": {
+			"uplink_kbps": 1000,
+			"downlink_kbps": 5000
+		}
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, &BandwidthLimit{UplinkKbps: 1000, DownlinkKbps: 5000}, actual.BandwidthLimit)
+}
+
+func Test_ServiceProposal_UnserializeHermesIDs(t *testing.T) {
+	jsonData := []byte(`{
+		"id": 1,
+		"format": "format/X",
+		"service_type": "mock_service",
+		"service_definition": null,
+		"payment_method_type": "mock_payment",
+		"payment_method": {},
+		"provider_id": "node",
+		"provider_contacts": [
+			{ "type" : "mock_contact" , "definition" : {}}
+		],
+		"hermes_ids": ["0x00000000000000000000000000000000000000aa", "0x00000000000000000000000000000000000000bb"]
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0x00000000000000000000000000000000000000aa", "0x00000000000000000000000000000000000000bb"}, actual.HermesIDs)
+	assert.True(t, actual.AcceptsHermes("0x00000000000000000000000000000000000000AA"))
+	assert.False(t, actual.AcceptsHermes("0x00000000000000000000000000000000000000cc"))
+}
+
+func Test_ServiceProposal_AcceptsAnyHermesIfNotAdvertised(t *testing.T) {
+	proposal := ServiceProposal{}
+	assert.True(t, proposal.AcceptsHermes("0x00000000000000000000000000000000000000cc"))
+}
@@ -51,6 +51,7 @@ import (
 	"github.com/mysteriumnetwork/node/logconfig"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 )
 
@@ -66,13 +67,14 @@ type MobileNode struct {
 	ipResolver                   ip.Resolver
 	eventBus                     eventbus.EventBus
 	connectionRegistry           *connection.Registry
+	p2pDialer                    p2p.Dialer
 	proposalsManager             *proposalsManager
 	hermes                       common.Address
 	feedbackReporter             *feedback.Reporter
 	transactor                   *registry.Transactor
 	identityRegistry             registry.IdentityRegistry
 	identityChannelCalculator    *pingpong.ChannelAddressCalculator
-	consumerBalanceTracker       *pingpong.ConsumerBalanceTracker
+	consumerBalanceTracker       *pingpong.ConsumerBalances
 	registryAddress              string
 	channelImplementationAddress string
 	chainID                      int64
@@ -237,6 +239,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		ipResolver:                   di.IPResolver,
 		eventBus:                     di.EventBus,
 		connectionRegistry:           di.ConnectionRegistry,
+		p2pDialer:                    di.P2PDialer,
 		hermes:                       common.HexToAddress(nodeOptions.Hermes.HermesID),
 		feedbackReporter:             di.Reporter,
 		transactor:                   di.Transactor,
@@ -336,8 +339,12 @@ type BalanceChangeCallback interface {
 }
 
 // RegisterBalanceChangeCallback registers callback which is called on identity balance change.
+// Only the balance of the registration hermes channel is reported, since it is the one used for connections.
 func (mb *MobileNode) RegisterBalanceChangeCallback(cb BalanceChangeCallback) {
 	_ = mb.eventBus.SubscribeAsync(event.AppTopicBalanceChanged, func(e event.AppEventBalanceChanged) {
+		if e.HermesID != mb.hermes {
+			return
+		}
 		balance := crypto.BigMystToFloat(e.Current)
 		cb.OnChange(e.Identity.Address, balance)
 	})
@@ -574,6 +581,8 @@ func (mb *MobileNode) WaitUntilDies() error {
 // OverrideWireguardConnection overrides default wireguard connection implementation to more mobile adapted one
 func (mb *MobileNode) OverrideWireguardConnection(wgTunnelSetup WireguardTunnelSetup) {
 	wireguard.Bootstrap()
+	// Exclude p2p stream sockets from VPN tunnel, they would be routed through it otherwise.
+	mb.p2pDialer.SetSocketProtector(wgTunnelSetup.Protect)
 	factory := func() (connection.Connection, error) {
 		opts := wireGuardOptions{
 			statsUpdateInterval: 1 * time.Second,
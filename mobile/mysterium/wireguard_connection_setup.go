@@ -131,6 +131,11 @@ func (c *wireguardConnection) Start(ctx context.Context, options connection.Conn
 		options.ProviderNATConn.Close()
 		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
 		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+
+		// Channel over p2p streams is bridged to the local UDP conn, stream sockets are protected by p2p dialer.
+		if remoteIP := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).IP; remoteIP.IsLoopback() {
+			config.Provider.Endpoint.IP = remoteIP
+		}
 	}
 
 	if err := c.device.Start(c.privateKey, config, options.ChannelConn); err != nil {
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package behavior
+
+// AppTopicNATBehavior represents the topic detected NAT behavior is published to.
+const AppTopicNATBehavior = "NAT behavior"
+
+// AppEventNATBehavior is published after each NAT behavior detection.
+type AppEventNATBehavior struct {
+	Behavior Behavior
+	Error    error
+}
+
+// NATType describes how NAT maps internal endpoints and which incoming packets it lets through.
+type NATType string
+
+const (
+	// NATTypeNone means that node is directly reachable by its public IP.
+	NATTypeNone NATType = "none"
+	// NATTypeFullCone maps internal endpoint to the same external one and accepts packets from any host.
+	NATTypeFullCone NATType = "full_cone"
+	// NATTypeRestrictedCone accepts packets only from hosts internal endpoint has sent packets to.
+	NATTypeRestrictedCone NATType = "restricted_cone"
+	// NATTypePortRestrictedCone accepts packets only from host endpoints internal endpoint has sent packets to.
+	NATTypePortRestrictedCone NATType = "port_restricted_cone"
+	// NATTypeSymmetric maps internal endpoint to different external ones for each destination.
+	NATTypeSymmetric NATType = "symmetric"
+)
+
+// PortAllocation describes how NAT picks external ports of new mappings.
+type PortAllocation string
+
+const (
+	// PortAllocationPreserving keeps external port equal to the internal one.
+	PortAllocationPreserving PortAllocation = "preserving"
+	// PortAllocationSequential allocates external ports close to the previously allocated ones.
+	PortAllocationSequential PortAllocation = "sequential"
+	// PortAllocationRandom allocates unpredictable external ports.
+	PortAllocationRandom PortAllocation = "random"
+)
+
+// Behavior represents NAT behavior of the node, empty fields mean that it is unknown.
+type Behavior struct {
+	Type           NATType
+	PortAllocation PortAllocation
+}
+
+// Known tells whether NAT type was detected.
+func (b Behavior) Known() bool {
+	return b.Type != ""
+}
+
+// Predictable tells whether peers can guess external port of the mapping used to reach them.
+func (b Behavior) Predictable() bool {
+	if !b.Known() {
+		return false
+	}
+	return b.Type != NATTypeSymmetric || b.PortAllocation == PortAllocationPreserving
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package behavior
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/rs/zerolog/log"
+)
+
+// maxSequentialPortDelta is the largest gap between external ports of consecutive mappings
+// which are still considered to be allocated sequentially.
+const maxSequentialPortDelta = 16
+
+// Detector classifies NAT of the node by probing STUN servers and keeps the last detected behavior.
+type Detector struct {
+	servers   []string
+	interval  time.Duration
+	publisher eventbus.Publisher
+
+	mu       sync.RWMutex
+	behavior Behavior
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewDetector creates NAT behavior detector which uses given STUN servers and repeats detection every interval.
+// Servers supporting RFC 5780 are required to detect NAT filtering behavior.
+func NewDetector(servers []string, interval time.Duration, publisher eventbus.Publisher) *Detector {
+	var nonEmpty []string
+	for _, s := range servers {
+		if s != "" {
+			nonEmpty = append(nonEmpty, s)
+		}
+	}
+	return &Detector{
+		servers:   nonEmpty,
+		interval:  interval,
+		publisher: publisher,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start starts periodic NAT behavior detection in the background.
+func (d *Detector) Start() {
+	if len(d.servers) == 0 {
+		log.Info().Msg("No STUN servers configured, NAT behavior detection disabled")
+		return
+	}
+
+	go func() {
+		for {
+			d.detectAndPublish()
+
+			select {
+			case <-d.stop:
+				return
+			case <-time.After(d.interval):
+			}
+		}
+	}()
+}
+
+// Stop stops periodic NAT behavior detection.
+func (d *Detector) Stop() {
+	d.once.Do(func() {
+		close(d.stop)
+	})
+}
+
+// Behavior returns the last detected NAT behavior.
+func (d *Detector) Behavior() Behavior {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.behavior
+}
+
+func (d *Detector) detectAndPublish() {
+	behavior, err := d.Detect()
+	if err != nil {
+		log.Warn().Err(err).Msg("NAT behavior detection failed")
+	} else {
+		log.Info().Msgf("Detected NAT type: %s, port allocation: %s", behavior.Type, behavior.PortAllocation)
+	}
+
+	d.mu.Lock()
+	d.behavior = behavior
+	d.mu.Unlock()
+
+	d.publisher.Publish(AppTopicNATBehavior, AppEventNATBehavior{Behavior: behavior, Error: err})
+}
+
+// Detect classifies NAT following RFC 3489 and RFC 5780 tests.
+// Mapping behavior is detected by comparing external endpoints of the same socket seen by two servers,
+// filtering behavior by asking server to respond from the alternate IP and port.
+func (d *Detector) Detect() (Behavior, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return Behavior{}, fmt.Errorf("could not open UDP socket: %w", err)
+	}
+	defer conn.Close()
+	local := conn.LocalAddr().(*net.UDPAddr)
+
+	server, res, err := d.bindAny(conn, d.servers)
+	if err != nil {
+		return Behavior{}, err
+	}
+	if res.mapped.Port == local.Port && isLocalIP(res.mapped.IP) {
+		return Behavior{Type: NATTypeNone, PortAllocation: PortAllocationPreserving}, nil
+	}
+
+	// Second mapping is requested from other server endpoint to find out if it depends on destination.
+	var otherRes *bindingResponse
+	if res.other != nil {
+		otherRes, err = bind(conn, res.other, false, false)
+	}
+	if otherRes == nil {
+		_, otherRes, err = d.bindAny(conn, d.serversExcept(server))
+	}
+	if err != nil {
+		return Behavior{}, fmt.Errorf("could not detect NAT mapping behavior: %w", err)
+	}
+	symmetric := !otherRes.mapped.IP.Equal(res.mapped.IP) || otherRes.mapped.Port != res.mapped.Port
+
+	behavior := Behavior{Type: NATTypeSymmetric}
+	allocated := []int{res.mapped.Port}
+	if symmetric {
+		allocated = append(allocated, otherRes.mapped.Port)
+	}
+	behavior.PortAllocation = d.detectPortAllocation(server, local.Port, allocated)
+	if symmetric {
+		return behavior, nil
+	}
+
+	if res.other == nil {
+		log.Debug().Msgf("STUN server %s can't change response source, assuming port restricted cone NAT", server)
+		behavior.Type = NATTypePortRestrictedCone
+		return behavior, nil
+	}
+	switch {
+	case d.responds(conn, server, true, true):
+		behavior.Type = NATTypeFullCone
+	case d.responds(conn, server, false, true):
+		behavior.Type = NATTypeRestrictedCone
+	default:
+		behavior.Type = NATTypePortRestrictedCone
+	}
+	return behavior, nil
+}
+
+// detectPortAllocation maps one more socket and compares its external port with previously allocated ones.
+func (d *Detector) detectPortAllocation(server *net.UDPAddr, localPort int, allocated []int) PortAllocation {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+
+	res, err := bind(conn, server, false, false)
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not detect NAT port allocation")
+		return ""
+	}
+
+	if allocated[0] == localPort && res.mapped.Port == conn.LocalAddr().(*net.UDPAddr).Port {
+		return PortAllocationPreserving
+	}
+	allocated = append(allocated, res.mapped.Port)
+	for i := 1; i < len(allocated); i++ {
+		delta := allocated[i] - allocated[i-1]
+		if delta <= 0 || delta > maxSequentialPortDelta {
+			return PortAllocationRandom
+		}
+	}
+	return PortAllocationSequential
+}
+
+// responds tells whether response to the request for source change passes through NAT.
+func (d *Detector) responds(conn *net.UDPConn, server *net.UDPAddr, changeIP, changePort bool) bool {
+	_, err := bind(conn, server, changeIP, changePort)
+	return err == nil
+}
+
+// bindAny sends binding request to servers one by one until one of them responds.
+func (d *Detector) bindAny(conn *net.UDPConn, servers []string) (*net.UDPAddr, *bindingResponse, error) {
+	err := errors.New("no STUN servers available")
+	for _, s := range servers {
+		var server *net.UDPAddr
+		server, err = net.ResolveUDPAddr("udp4", s)
+		if err != nil {
+			continue
+		}
+		var res *bindingResponse
+		res, err = bind(conn, server, false, false)
+		if err != nil {
+			err = fmt.Errorf("STUN server %s: %w", s, err)
+			continue
+		}
+		return server, res, nil
+	}
+	return nil, nil, err
+}
+
+func (d *Detector) serversExcept(server *net.UDPAddr) []string {
+	var servers []string
+	for _, s := range d.servers {
+		if addr, err := net.ResolveUDPAddr("udp4", s); err == nil && addr.String() == server.String() {
+			continue
+		}
+		servers = append(servers, s)
+	}
+	return servers
+}
+
+func isLocalIP(ip net.IP) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
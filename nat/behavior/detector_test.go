@@ -0,0 +1,300 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package behavior
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Detect(t *testing.T) {
+	defer shortenSTUNTimeouts()()
+
+	tests := []struct {
+		name     string
+		nat      *fakeNAT
+		filter   func(changeIP, changePort bool) bool
+		expected Behavior
+	}{
+		{
+			name:     "no NAT",
+			nat:      nil,
+			expected: Behavior{Type: NATTypeNone, PortAllocation: PortAllocationPreserving},
+		},
+		{
+			name:     "full cone",
+			nat:      &fakeNAT{preserving: true},
+			filter:   func(changeIP, changePort bool) bool { return true },
+			expected: Behavior{Type: NATTypeFullCone, PortAllocation: PortAllocationPreserving},
+		},
+		{
+			name:     "restricted cone",
+			nat:      &fakeNAT{step: 1},
+			filter:   func(changeIP, changePort bool) bool { return !changeIP },
+			expected: Behavior{Type: NATTypeRestrictedCone, PortAllocation: PortAllocationSequential},
+		},
+		{
+			name:     "port restricted cone",
+			nat:      &fakeNAT{step: 1},
+			expected: Behavior{Type: NATTypePortRestrictedCone, PortAllocation: PortAllocationSequential},
+		},
+		{
+			name:     "symmetric",
+			nat:      &fakeNAT{symmetric: true, step: 2},
+			filter:   func(changeIP, changePort bool) bool { return true },
+			expected: Behavior{Type: NATTypeSymmetric, PortAllocation: PortAllocationSequential},
+		},
+		{
+			name:     "symmetric with random ports",
+			nat:      &fakeNAT{symmetric: true, step: 1000},
+			expected: Behavior{Type: NATTypeSymmetric, PortAllocation: PortAllocationRandom},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeSTUNServer(t, test.nat, true, test.filter)
+			defer server.close()
+
+			behavior, err := NewDetector([]string{server.addr()}, time.Hour, mocks.NewEventBus()).Detect()
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, behavior)
+		})
+	}
+}
+
+func TestDetector_Detect_UsesSecondServerWithoutAlternateAddress(t *testing.T) {
+	defer shortenSTUNTimeouts()()
+
+	nat := &fakeNAT{symmetric: true, step: 1}
+	server1 := newFakeSTUNServer(t, nat, false, nil)
+	defer server1.close()
+	server2 := newFakeSTUNServer(t, nat, false, nil)
+	defer server2.close()
+
+	behavior, err := NewDetector([]string{server1.addr(), server2.addr()}, time.Hour, mocks.NewEventBus()).Detect()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Behavior{Type: NATTypeSymmetric, PortAllocation: PortAllocationSequential}, behavior)
+}
+
+func TestDetector_Detect_AssumesPortRestrictedConeWithoutFilteringTests(t *testing.T) {
+	defer shortenSTUNTimeouts()()
+
+	nat := &fakeNAT{preserving: true}
+	server1 := newFakeSTUNServer(t, nat, false, nil)
+	defer server1.close()
+	server2 := newFakeSTUNServer(t, nat, false, nil)
+	defer server2.close()
+
+	behavior, err := NewDetector([]string{server1.addr(), server2.addr()}, time.Hour, mocks.NewEventBus()).Detect()
+
+	assert.NoError(t, err)
+	assert.Equal(t, Behavior{Type: NATTypePortRestrictedCone, PortAllocation: PortAllocationPreserving}, behavior)
+}
+
+func TestDetector_Detect_FailsWithSingleBasicServer(t *testing.T) {
+	defer shortenSTUNTimeouts()()
+
+	server := newFakeSTUNServer(t, &fakeNAT{preserving: true}, false, nil)
+	defer server.close()
+
+	_, err := NewDetector([]string{server.addr()}, time.Hour, mocks.NewEventBus()).Detect()
+
+	assert.Error(t, err)
+}
+
+func TestDetector_Start_PublishesBehavior(t *testing.T) {
+	defer shortenSTUNTimeouts()()
+
+	server := newFakeSTUNServer(t, &fakeNAT{preserving: true}, true, func(changeIP, changePort bool) bool { return true })
+	defer server.close()
+	publisher := mocks.NewEventBus()
+	detector := NewDetector([]string{server.addr()}, time.Hour, publisher)
+
+	detector.Start()
+	defer detector.Stop()
+
+	expected := Behavior{Type: NATTypeFullCone, PortAllocation: PortAllocationPreserving}
+	assert.Eventually(t, func() bool {
+		return detector.Behavior() == expected
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return publisher.Pop() == AppEventNATBehavior{Behavior: expected}
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func shortenSTUNTimeouts() func() {
+	timeouts := stunTimeouts
+	stunTimeouts = []time.Duration{50 * time.Millisecond}
+	return func() { stunTimeouts = timeouts }
+}
+
+// fakeNAT maps internal endpoints to the external ones, nil fakeNAT keeps endpoints as they are.
+type fakeNAT struct {
+	symmetric  bool
+	preserving bool
+	step       int
+
+	mu       sync.Mutex
+	lastPort int
+	mappings map[string]int
+}
+
+func (n *fakeNAT) mapped(from, to net.Addr) *net.UDPAddr {
+	src := from.(*net.UDPAddr)
+	if n == nil {
+		return src
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := src.String()
+	if n.symmetric {
+		key += "->" + to.String()
+	}
+	if n.mappings == nil {
+		n.mappings = make(map[string]int)
+		n.lastPort = 40000
+	}
+	port, ok := n.mappings[key]
+	if !ok {
+		if n.preserving && !n.used(src.Port) {
+			port = src.Port
+		} else {
+			n.lastPort += n.step
+			port = n.lastPort
+		}
+		n.mappings[key] = port
+	}
+	return &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: port}
+}
+
+func (n *fakeNAT) used(port int) bool {
+	for _, p := range n.mappings {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeSTUNServer answers binding requests from the primary and the alternate endpoints.
+type fakeSTUNServer struct {
+	t         *testing.T
+	primary   *net.UDPConn
+	alternate *net.UDPConn
+	nat       *fakeNAT
+	withOther bool
+	filter    func(changeIP, changePort bool) bool
+}
+
+func newFakeSTUNServer(t *testing.T, nat *fakeNAT, withOther bool, filter func(changeIP, changePort bool) bool) *fakeSTUNServer {
+	primary, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	alternate, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+
+	s := &fakeSTUNServer{t: t, primary: primary, alternate: alternate, nat: nat, withOther: withOther, filter: filter}
+	go s.serve(primary, alternate)
+	go s.serve(alternate, primary)
+	return s
+}
+
+func (s *fakeSTUNServer) addr() string {
+	return s.primary.LocalAddr().String()
+}
+
+func (s *fakeSTUNServer) close() {
+	s.primary.Close()
+	s.alternate.Close()
+}
+
+func (s *fakeSTUNServer) serve(conn, other *net.UDPConn) {
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		req := buf[:n]
+		if len(req) < stunHeaderLen || binary.BigEndian.Uint16(req[0:]) != stunBindingRequest {
+			continue
+		}
+		var flags uint32
+		if len(req) >= stunHeaderLen+8 && binary.BigEndian.Uint16(req[stunHeaderLen:]) == stunAttrChangeRequest {
+			flags = binary.BigEndian.Uint32(req[stunHeaderLen+4:])
+		}
+
+		var otherAddr *net.UDPAddr
+		if s.withOther {
+			otherAddr = other.LocalAddr().(*net.UDPAddr)
+		}
+		res := newTestBindingResponse(req[8:20], s.nat.mapped(from, conn.LocalAddr()), otherAddr)
+
+		src := conn
+		if flags != 0 {
+			if s.filter == nil || !s.filter(flags&stunChangeIP != 0, flags&stunChangePort != 0) {
+				continue
+			}
+			src = other
+		}
+		if _, err := src.WriteToUDP(res, from); err != nil {
+			s.t.Logf("Could not respond: %v", err)
+		}
+	}
+}
+
+func newTestBindingResponse(txID []byte, mapped, other *net.UDPAddr) []byte {
+	msg := make([]byte, stunHeaderLen)
+	binary.BigEndian.PutUint16(msg[0:], stunBindingResponse)
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	copy(msg[8:], txID)
+
+	msg = append(msg, addressAttribute(stunAttrXorMappedAddress, mapped, true)...)
+	if other != nil {
+		msg = append(msg, addressAttribute(stunAttrOtherAddress, other, false)...)
+	}
+	binary.BigEndian.PutUint16(msg[2:], uint16(len(msg)-stunHeaderLen))
+	return msg
+}
+
+func addressAttribute(attrType uint16, addr *net.UDPAddr, xor bool) []byte {
+	attr := make([]byte, 12)
+	binary.BigEndian.PutUint16(attr[0:], attrType)
+	binary.BigEndian.PutUint16(attr[2:], 8)
+	attr[5] = stunFamilyIPv4
+	port := uint16(addr.Port)
+	ip := binary.BigEndian.Uint32(addr.IP.To4())
+	if xor {
+		port ^= stunMagicCookie >> 16
+		ip ^= stunMagicCookie
+	}
+	binary.BigEndian.PutUint16(attr[6:], port)
+	binary.BigEndian.PutUint32(attr[8:], ip)
+	return attr
+}
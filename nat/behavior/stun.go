@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package behavior
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Minimal STUN (RFC 5389) binding client with RFC 5780 CHANGE-REQUEST support.
+const (
+	stunHeaderLen   = 20
+	stunMagicCookie = 0x2112A442
+
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+
+	stunAttrMappedAddress    = 0x0001
+	stunAttrChangeRequest    = 0x0003
+	stunAttrChangedAddress   = 0x0005
+	stunAttrXorMappedAddress = 0x0020
+	stunAttrOtherAddress     = 0x802c
+
+	stunChangeIP   = 0x04
+	stunChangePort = 0x02
+
+	stunFamilyIPv4 = 0x01
+)
+
+// stunTimeouts are the read timeouts of request retransmissions.
+var stunTimeouts = []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second}
+
+var errNoBindingResponse = errors.New("no STUN binding response")
+
+type bindingResponse struct {
+	// mapped is the external endpoint request was sent from as seen by the server.
+	mapped *net.UDPAddr
+	// other is the alternate server endpoint, nil if server can't change response source.
+	other *net.UDPAddr
+}
+
+// bind sends binding request to the server and waits for the response.
+// Response may arrive from other endpoint of the server if source change was requested.
+func bind(conn *net.UDPConn, server *net.UDPAddr, changeIP, changePort bool) (*bindingResponse, error) {
+	var flags uint32
+	if changeIP {
+		flags |= stunChangeIP
+	}
+	if changePort {
+		flags |= stunChangePort
+	}
+
+	txID := make([]byte, 12)
+	if _, err := rand.Read(txID); err != nil {
+		return nil, err
+	}
+	req := newBindingRequest(txID, flags)
+
+	buf := make([]byte, 1500)
+	for _, timeout := range stunTimeouts {
+		if _, err := conn.WriteToUDP(req, server); err != nil {
+			return nil, fmt.Errorf("could not send STUN binding request: %w", err)
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			res, ok, err := parseBindingResponse(buf[:n], txID)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return res, nil
+			}
+		}
+	}
+	return nil, errNoBindingResponse
+}
+
+func newBindingRequest(txID []byte, changeFlags uint32) []byte {
+	var attrs []byte
+	if changeFlags != 0 {
+		attrs = make([]byte, 8)
+		binary.BigEndian.PutUint16(attrs[0:], stunAttrChangeRequest)
+		binary.BigEndian.PutUint16(attrs[2:], 4)
+		binary.BigEndian.PutUint32(attrs[4:], changeFlags)
+	}
+
+	msg := make([]byte, stunHeaderLen, stunHeaderLen+len(attrs))
+	binary.BigEndian.PutUint16(msg[0:], stunBindingRequest)
+	binary.BigEndian.PutUint16(msg[2:], uint16(len(attrs)))
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	copy(msg[8:], txID)
+	return append(msg, attrs...)
+}
+
+// parseBindingResponse parses binding success response.
+// It returns false if message is not a response to the request with given transaction ID.
+func parseBindingResponse(msg, txID []byte) (*bindingResponse, bool, error) {
+	if len(msg) < stunHeaderLen || binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie || !bytes.Equal(msg[8:20], txID) {
+		return nil, false, nil
+	}
+	if msgType := binary.BigEndian.Uint16(msg[0:]); msgType != stunBindingResponse {
+		return nil, false, fmt.Errorf("unexpected STUN message type 0x%04x", msgType)
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:]))
+	if len(msg) < stunHeaderLen+length {
+		return nil, false, errors.New("truncated STUN message")
+	}
+
+	var res bindingResponse
+	var mapped, xorMapped *net.UDPAddr
+	attrs := msg[stunHeaderLen : stunHeaderLen+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:]))
+		if len(attrs) < 4+attrLen {
+			return nil, false, errors.New("truncated STUN attribute")
+		}
+		value := attrs[4 : 4+attrLen]
+
+		switch attrType {
+		case stunAttrMappedAddress:
+			mapped = parseAddress(value, false)
+		case stunAttrXorMappedAddress:
+			xorMapped = parseAddress(value, true)
+		case stunAttrOtherAddress, stunAttrChangedAddress:
+			res.other = parseAddress(value, false)
+		}
+
+		// Attributes are padded to a multiple of 4 bytes.
+		padded := (attrLen + 3) &^ 3
+		if len(attrs) < 4+padded {
+			break
+		}
+		attrs = attrs[4+padded:]
+	}
+
+	res.mapped = xorMapped
+	if res.mapped == nil {
+		res.mapped = mapped
+	}
+	if res.mapped == nil {
+		return nil, false, errors.New("STUN response has no mapped address")
+	}
+	return &res, true, nil
+}
+
+// parseAddress parses IPv4 address attribute value, it returns nil for other families.
+func parseAddress(value []byte, xor bool) *net.UDPAddr {
+	if len(value) < 8 || value[1] != stunFamilyIPv4 {
+		return nil
+	}
+	port := binary.BigEndian.Uint16(value[2:])
+	ip := binary.BigEndian.Uint32(value[4:])
+	if xor {
+		port ^= stunMagicCookie >> 16
+		ip ^= stunMagicCookie
+	}
+
+	addr := &net.UDPAddr{IP: make(net.IP, 4), Port: int(port)}
+	binary.BigEndian.PutUint32(addr.IP, ip)
+	return addr
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	portmap "github.com/ethereum/go-ethereum/p2p/nat"
+)
+
+const anyMechanism = "UPnP, NAT-PMP or PCP"
+
+// Any returns a port mapper that uses the first discovered mechanism of UPnP, NAT-PMP or PCP.
+// Discovery is performed lazily, on the first use of the mapper.
+func Any() portmap.Interface {
+	return &autodisc{
+		discover: func() portmap.Interface {
+			return discoverFirst(portmap.UPnP(), portmap.PMP(nil), PCP(nil))
+		},
+	}
+}
+
+// discoverFirst returns the first of the given mechanisms which is able to tell the router's external IP.
+func discoverFirst(mechanisms ...portmap.Interface) portmap.Interface {
+	found := make(chan portmap.Interface, len(mechanisms))
+	for _, m := range mechanisms {
+		go func(m portmap.Interface) {
+			if _, err := m.ExternalIP(); err != nil {
+				found <- nil
+				return
+			}
+			found <- m
+		}(m)
+	}
+
+	for range mechanisms {
+		if m := <-found; m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// autodisc is a port mapping mechanism that is still being discovered,
+// calls to it wait until the discovery is done and are passed to the discovered mechanism.
+type autodisc struct {
+	once     sync.Once
+	discover func() portmap.Interface
+
+	mu    sync.Mutex
+	found portmap.Interface
+}
+
+func (n *autodisc) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error {
+	found, err := n.wait()
+	if err != nil {
+		return err
+	}
+	return found.AddMapping(protocol, extport, intport, name, lifetime)
+}
+
+func (n *autodisc) DeleteMapping(protocol string, extport, intport int) error {
+	found, err := n.wait()
+	if err != nil {
+		return err
+	}
+	return found.DeleteMapping(protocol, extport, intport)
+}
+
+func (n *autodisc) ExternalIP() (net.IP, error) {
+	found, err := n.wait()
+	if err != nil {
+		return nil, err
+	}
+	return found.ExternalIP()
+}
+
+func (n *autodisc) String() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.found == nil {
+		return anyMechanism
+	}
+	return n.found.String()
+}
+
+func (n *autodisc) wait() (portmap.Interface, error) {
+	n.once.Do(func() {
+		found := n.discover()
+
+		n.mu.Lock()
+		n.found = found
+		n.mu.Unlock()
+	})
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.found == nil {
+		return nil, fmt.Errorf("no %s router discovered", anyMechanism)
+	}
+	return n.found, nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	portmap "github.com/ethereum/go-ethereum/p2p/nat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAny_UsesFirstDiscoveredMechanism(t *testing.T) {
+	pcp := &mockMechanism{name: "PCP", ip: net.ParseIP("1.2.3.4")}
+	mapper := &autodisc{discover: func() portmap.Interface {
+		return discoverFirst(&mockMechanism{name: "UPnP", err: errors.New("not found")}, pcp)
+	}}
+
+	assert.Equal(t, anyMechanism, mapper.String())
+	assert.NoError(t, mapper.AddMapping("UDP", 1, 1, "Test", time.Minute))
+	assert.Equal(t, "PCP", mapper.String())
+	assert.True(t, pcp.mapped)
+}
+
+func TestAny_FailsWhenNothingDiscovered(t *testing.T) {
+	mapper := &autodisc{discover: func() portmap.Interface {
+		return discoverFirst(&mockMechanism{name: "UPnP", err: errors.New("not found")})
+	}}
+
+	_, err := mapper.ExternalIP()
+
+	assert.EqualError(t, err, "no UPnP, NAT-PMP or PCP router discovered")
+}
+
+type mockMechanism struct {
+	name   string
+	ip     net.IP
+	err    error
+	mapped bool
+}
+
+func (m *mockMechanism) AddMapping(string, int, int, string, time.Duration) error {
+	m.mapped = true
+	return m.err
+}
+
+func (m *mockMechanism) DeleteMapping(string, int, int) error {
+	return m.err
+}
+
+func (m *mockMechanism) ExternalIP() (net.IP, error) {
+	return m.ip, m.err
+}
+
+func (m *mockMechanism) String() string {
+	return m.name
+}
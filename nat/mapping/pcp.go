@@ -0,0 +1,219 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	portmap "github.com/ethereum/go-ethereum/p2p/nat"
+	"github.com/jackpal/gateway"
+)
+
+const (
+	pcpPort    = 5351
+	pcpVersion = 2
+	pcpOpMap   = 1
+
+	pcpHeaderLen  = 24
+	pcpMapLen     = 36
+	pcpResultOK   = 0
+	pcpResponseOp = 0x80
+
+	// pcpProbePort is a discard port used for the probe mapping revealing external IP address.
+	pcpProbePort = 9
+)
+
+// pcpTimeouts are the response timeouts of consecutive request retransmissions.
+var pcpTimeouts = []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second}
+
+var errPCPNoResponse = errors.New("no PCP response")
+
+// PCP returns a port mapper that uses Port Control Protocol (RFC 6887). The provided gateway
+// address should be the IP of the router. If the given gateway address is nil, default gateway is used.
+func PCP(gw net.IP) portmap.Interface {
+	return &pcp{
+		gw:     gw,
+		port:   pcpPort,
+		nonces: make(map[string][]byte),
+	}
+}
+
+type pcp struct {
+	gw   net.IP
+	port int
+
+	mu     sync.Mutex
+	nonces map[string][]byte
+}
+
+func (n *pcp) String() string {
+	return fmt.Sprintf("PCP(%v)", n.gw)
+}
+
+// ExternalIP returns external address of the router. PCP has no dedicated request for it,
+// so it is learned from the short lived probe mapping which is deleted right away.
+func (n *pcp) ExternalIP() (net.IP, error) {
+	ip, err := n.request("udp", pcpProbePort, pcpProbePort, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.DeleteMapping("udp", pcpProbePort, pcpProbePort); err != nil {
+		return nil, err
+	}
+	if ip == nil {
+		return nil, errors.New("PCP server did not assign external IPv4 address")
+	}
+	return ip, nil
+}
+
+func (n *pcp) AddMapping(protocol string, extport, intport int, _ string, lifetime time.Duration) error {
+	if lifetime <= 0 {
+		return errors.New("lifetime must not be <= 0")
+	}
+	_, err := n.request(protocol, extport, intport, lifetime)
+	return err
+}
+
+func (n *pcp) DeleteMapping(protocol string, extport, intport int) error {
+	_, err := n.request(protocol, extport, intport, 0)
+
+	n.mu.Lock()
+	delete(n.nonces, nonceKey(protocol, intport))
+	n.mu.Unlock()
+	return err
+}
+
+func (n *pcp) request(protocol string, extport, intport int, lifetime time.Duration) (net.IP, error) {
+	var protocolNum byte
+	switch strings.ToLower(protocol) {
+	case "udp":
+		protocolNum = 17
+	case "tcp":
+		protocolNum = 6
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", protocol)
+	}
+
+	gw, err := n.gateway()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: gw, Port: n.port})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	nonce, err := n.nonce(protocol, intport)
+	if err != nil {
+		return nil, err
+	}
+
+	req := make([]byte, pcpHeaderLen+pcpMapLen)
+	req[0] = pcpVersion
+	req[1] = pcpOpMap
+	binary.BigEndian.PutUint32(req[4:8], uint32(lifetime/time.Second))
+	copy(req[8:24], conn.LocalAddr().(*net.UDPAddr).IP.To16())
+	copy(req[24:36], nonce)
+	req[36] = protocolNum
+	binary.BigEndian.PutUint16(req[40:42], uint16(intport))
+	binary.BigEndian.PutUint16(req[42:44], uint16(extport))
+	copy(req[44:60], net.IPv4zero.To16())
+
+	res := make([]byte, 1100) // maximum PCP message size
+	for _, timeout := range pcpTimeouts {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+		size, err := conn.Read(res)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+			return nil, err
+		}
+		if extIP, ok, err := parsePCPMapResponse(res[:size], nonce); ok {
+			return extIP, err
+		}
+	}
+	return nil, errPCPNoResponse
+}
+
+// parsePCPMapResponse parses MAP response and returns assigned external IP, ok is false for responses to some other request.
+func parsePCPMapResponse(res, nonce []byte) (extIP net.IP, ok bool, err error) {
+	if len(res) < pcpHeaderLen+pcpMapLen || res[0] != pcpVersion || res[1] != pcpResponseOp|pcpOpMap {
+		return nil, false, nil
+	}
+	if string(res[24:36]) != string(nonce) {
+		return nil, false, nil
+	}
+	if code := res[3]; code != pcpResultOK {
+		return nil, true, fmt.Errorf("PCP request failed with result code %d", code)
+	}
+
+	extIP = net.IP(append([]byte(nil), res[44:60]...)).To4()
+	if extIP.IsUnspecified() {
+		extIP = nil
+	}
+	return extIP, true, nil
+}
+
+func (n *pcp) gateway() (net.IP, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.gw == nil {
+		gw, err := gateway.DiscoverGateway()
+		if err != nil {
+			return nil, err
+		}
+		n.gw = gw
+	}
+	return n.gw, nil
+}
+
+// nonce returns nonce of the mapping, the same nonce has to be used for renewing or deleting it.
+func (n *pcp) nonce(protocol string, intport int) ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := nonceKey(protocol, intport)
+	if nonce, ok := n.nonces[key]; ok {
+		return nonce, nil
+	}
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	n.nonces[key] = nonce
+	return nonce, nil
+}
+
+func nonceKey(protocol string, intport int) string {
+	return fmt.Sprintf("%s/%d", strings.ToLower(protocol), intport)
+}
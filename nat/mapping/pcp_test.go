@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPCP_AddMapping(t *testing.T) {
+	server := newFakePCPServer(t, 0)
+	defer server.close()
+	client := server.client()
+
+	assert.NoError(t, client.AddMapping("UDP", 51334, 51335, "Test", 20*time.Minute))
+	req := server.lastRequest()
+	assert.Equal(t, byte(pcpVersion), req[0])
+	assert.Equal(t, byte(pcpOpMap), req[1])
+	assert.Equal(t, uint32(1200), binary.BigEndian.Uint32(req[4:8]))
+	assert.Equal(t, net.ParseIP("127.0.0.1").To16(), net.IP(req[8:24]))
+	assert.Equal(t, byte(17), req[36])
+	assert.Equal(t, uint16(51335), binary.BigEndian.Uint16(req[40:42]))
+	assert.Equal(t, uint16(51334), binary.BigEndian.Uint16(req[42:44]))
+	nonce := append([]byte(nil), req[24:36]...)
+
+	assert.NoError(t, client.AddMapping("UDP", 51334, 51335, "Test", 20*time.Minute))
+	assert.Equal(t, nonce, server.lastRequest()[24:36], "renewal must use the same nonce")
+
+	assert.NoError(t, client.DeleteMapping("UDP", 51334, 51335))
+	req = server.lastRequest()
+	assert.Equal(t, nonce, req[24:36], "deletion must use the same nonce")
+	assert.Equal(t, uint32(0), binary.BigEndian.Uint32(req[4:8]))
+}
+
+func TestPCP_AddMapping_RejectsPermanentLease(t *testing.T) {
+	server := newFakePCPServer(t, 0)
+	defer server.close()
+
+	assert.Error(t, server.client().AddMapping("UDP", 51334, 51334, "Test", 0))
+}
+
+func TestPCP_AddMapping_ReturnsErrorOfFailedRequest(t *testing.T) {
+	// NOT_AUTHORIZED result code.
+	server := newFakePCPServer(t, 2)
+	defer server.close()
+
+	err := server.client().AddMapping("TCP", 51334, 51334, "Test", time.Minute)
+
+	assert.EqualError(t, err, "PCP request failed with result code 2")
+}
+
+func TestPCP_ExternalIP(t *testing.T) {
+	server := newFakePCPServer(t, 0)
+	defer server.close()
+
+	ip, err := server.client().ExternalIP()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip.String())
+	req := server.lastRequest()
+	assert.Equal(t, uint32(0), binary.BigEndian.Uint32(req[4:8]), "probe mapping must be deleted")
+}
+
+type fakePCPServer struct {
+	conn       *net.UDPConn
+	resultCode byte
+
+	mu      sync.Mutex
+	request []byte
+}
+
+func newFakePCPServer(t *testing.T, resultCode byte) *fakePCPServer {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	assert.NoError(t, err)
+
+	server := &fakePCPServer{conn: conn, resultCode: resultCode}
+	go server.serve()
+	return server
+}
+
+func (s *fakePCPServer) client() *pcp {
+	client := PCP(net.ParseIP("127.0.0.1")).(*pcp)
+	client.port = s.conn.LocalAddr().(*net.UDPAddr).Port
+	return client
+}
+
+func (s *fakePCPServer) serve() {
+	buf := make([]byte, 1100)
+	for {
+		size, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		req := append([]byte(nil), buf[:size]...)
+
+		s.mu.Lock()
+		s.request = req
+		s.mu.Unlock()
+
+		res := make([]byte, pcpHeaderLen+pcpMapLen)
+		res[0] = pcpVersion
+		res[1] = pcpResponseOp | req[1]
+		res[3] = s.resultCode
+		copy(res[4:8], req[4:8])
+		copy(res[24:44], req[24:44])
+		copy(res[44:60], net.ParseIP("1.2.3.4").To16())
+		s.conn.WriteToUDP(res, addr)
+	}
+}
+
+func (s *fakePCPServer) lastRequest() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.request
+}
+
+func (s *fakePCPServer) close() {
+	s.conn.Close()
+}
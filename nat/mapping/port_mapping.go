@@ -34,9 +34,10 @@ const StageName = "port_mapping"
 // DefaultConfig returns default port mapping config.
 func DefaultConfig() *Config {
 	return &Config{
-		MapInterface:      portmap.Any(),
+		MapInterface:      Any(),
 		MapLifetime:       20 * time.Minute,
 		MapUpdateInterval: 15 * time.Minute,
+		MapRetryInterval:  time.Minute,
 	}
 }
 
@@ -45,9 +46,11 @@ type Config struct {
 	MapInterface      portmap.Interface
 	MapLifetime       time.Duration
 	MapUpdateInterval time.Duration
+	// MapRetryInterval is used instead of update interval after failed lease renewal, so lease is not lost.
+	MapRetryInterval time.Duration
 }
 
-// PortMapper tries to map port using router's uPnP or NAT-PMP depending on given config map interface.
+// PortMapper tries to map port using router's UPnP, NAT-PMP or PCP depending on given config map interface.
 type PortMapper interface {
 	// Map maps port for given protocol. It returns release func which
 	// must be called when port no longer needed and ok which is true if
@@ -60,12 +63,14 @@ func NewPortMapper(config *Config, publisher eventbus.Publisher) PortMapper {
 	return &portMapper{
 		config:    config,
 		publisher: publisher,
+		registry:  newRegistry(publisher),
 	}
 }
 
 type portMapper struct {
 	config    *Config
 	publisher eventbus.Publisher
+	registry  *registry
 }
 
 func (p *portMapper) Map(protocol string, port int, name string) (release func(), ok bool) {
@@ -85,27 +90,61 @@ func (p *portMapper) Map(protocol string, port int, name string) (release func()
 		return nil, false
 	}
 
+	mapping := Mapping{
+		Protocol: protocol,
+		Port:     port,
+		Name:     name,
+		Method:   p.config.MapInterface.String(),
+	}
+
 	// If only permanent lease is supported we don't need to update it in intervals.
 	if permanent {
-		return func() { p.deleteMapping(protocol, port, port) }, true
+		p.registry.put(mapping)
+		return func() {
+			p.deleteMapping(protocol, port, port)
+			p.registry.remove(protocol, port)
+		}, true
 	}
 
+	mapping.ExpiresAt = time.Now().Add(p.config.MapLifetime)
+	p.registry.put(mapping)
+
 	stopUpdate := make(chan struct{})
+	updateStopped := make(chan struct{})
 	go func() {
+		defer close(updateStopped)
+
+		interval := p.config.MapUpdateInterval
 		for {
 			select {
 			case <-stopUpdate:
 				return
-			case <-time.After(p.config.MapUpdateInterval):
-				_, err := p.addMapping(protocol, port, port, name)
+			case <-time.After(interval):
+				permanent, err := p.addMapping(protocol, port, port, name)
 				p.notify(err)
+				interval = p.config.MapUpdateInterval
+				if err != nil {
+					mapping.Error = err.Error()
+					if p.config.MapRetryInterval > 0 {
+						interval = p.config.MapRetryInterval
+					}
+				} else {
+					mapping.Error = ""
+					mapping.ExpiresAt = time.Now().Add(p.config.MapLifetime)
+					if permanent {
+						mapping.ExpiresAt = time.Time{}
+					}
+				}
+				p.registry.put(mapping)
 			}
 		}
 	}()
 
 	return func() {
-		p.deleteMapping(protocol, port, port)
 		close(stopUpdate)
+		<-updateStopped
+		p.deleteMapping(protocol, port, port)
+		p.registry.remove(protocol, port)
 	}, true
 }
 
@@ -125,6 +125,54 @@ func TestMap_uPnP_routerIPPublic(t *testing.T) {
 	}
 }
 
+func TestMap_RegistersActiveMappings(t *testing.T) {
+	router := &mockRouter{uPnPEnabled: true}
+	config := &Config{
+		MapInterface:      router,
+		MapUpdateInterval: time.Hour,
+		MapLifetime:       time.Minute,
+	}
+	bus := mocks.NewEventBus()
+	portMapper := NewPortMapper(config, bus).(*portMapper)
+
+	release, ok := portMapper.Map("UDP", 51334, "Test")
+	assert.True(t, ok)
+
+	mappings := portMapper.registry.list()
+	assert.Len(t, mappings, 1)
+	assert.Equal(t, "UDP", mappings[0].Protocol)
+	assert.Equal(t, 51334, mappings[0].Port)
+	assert.Equal(t, "Test", mappings[0].Name)
+	assert.WithinDuration(t, time.Now().Add(config.MapLifetime), mappings[0].ExpiresAt, time.Second)
+	assert.Equal(t, AppEventPortMappings{Mappings: mappings}, bus.Pop())
+
+	release()
+	assert.Empty(t, portMapper.registry.list())
+	assert.Equal(t, AppEventPortMappings{Mappings: []Mapping{}}, bus.Pop())
+}
+
+func TestMap_ReportsFailedRenewal(t *testing.T) {
+	router := &mockRouter{uPnPEnabled: true}
+	config := &Config{
+		MapInterface:      router,
+		MapUpdateInterval: 5 * time.Millisecond,
+		MapRetryInterval:  time.Hour,
+		MapLifetime:       time.Minute,
+	}
+	portMapper := NewPortMapper(config, mocks.NewEventBus()).(*portMapper)
+
+	release, ok := portMapper.Map("UDP", 51334, "Test")
+	assert.True(t, ok)
+	defer release()
+
+	router.setEnabled(false)
+	assert.Eventually(t, func() bool {
+		mappings := portMapper.registry.list()
+		return len(mappings) == 1 && mappings[0].Error != ""
+	}, time.Second, 5*time.Millisecond)
+	assert.False(t, portMapper.registry.list()[0].ExpiresAt.IsZero())
+}
+
 type mapping struct {
 	protocol         string
 	extport, intport int
@@ -161,6 +209,13 @@ func (m *mockRouter) AddMapping(protocol string, extport, intport int, name stri
 	return nil
 }
 
+func (m *mockRouter) setEnabled(enabled bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.uPnPEnabled = enabled
+}
+
 func (m *mockRouter) addedMapping() mapping {
 	m.Lock()
 	defer m.Unlock()
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// AppTopicPortMappings represents the topic active port mappings are published to.
+const AppTopicPortMappings = "Port mappings"
+
+// AppEventPortMappings is published whenever active port mappings change.
+type AppEventPortMappings struct {
+	Mappings []Mapping
+}
+
+// Mapping represents port forwarded by the router.
+type Mapping struct {
+	Protocol string
+	Port     int
+	Name     string
+	// Method is the mechanism used to forward the port, e.g. UPnP, NAT-PMP or PCP.
+	Method string
+	// ExpiresAt is the end of the current lease, zero for permanent lease.
+	ExpiresAt time.Time
+	// Error of the last lease renewal, router drops the mapping once the lease expires.
+	Error string
+}
+
+// registry keeps active port mappings and announces their changes.
+type registry struct {
+	publisher eventbus.Publisher
+
+	mu       sync.Mutex
+	mappings map[string]Mapping
+}
+
+func newRegistry(publisher eventbus.Publisher) *registry {
+	return &registry{
+		publisher: publisher,
+		mappings:  make(map[string]Mapping),
+	}
+}
+
+func (r *registry) put(m Mapping) {
+	r.mu.Lock()
+	r.mappings[mappingKey(m.Protocol, m.Port)] = m
+	mappings := r.listLocked()
+	r.mu.Unlock()
+
+	r.publisher.Publish(AppTopicPortMappings, AppEventPortMappings{Mappings: mappings})
+}
+
+func (r *registry) remove(protocol string, port int) {
+	r.mu.Lock()
+	delete(r.mappings, mappingKey(protocol, port))
+	mappings := r.listLocked()
+	r.mu.Unlock()
+
+	r.publisher.Publish(AppTopicPortMappings, AppEventPortMappings{Mappings: mappings})
+}
+
+func (r *registry) list() []Mapping {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.listLocked()
+}
+
+func (r *registry) listLocked() []Mapping {
+	mappings := make([]Mapping, 0, len(r.mappings))
+	for _, m := range r.mappings {
+		mappings = append(mappings, m)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].Port != mappings[j].Port {
+			return mappings[i].Port < mappings[j].Port
+		}
+		return mappings[i].Protocol < mappings[j].Protocol
+	})
+	return mappings
+}
+
+func mappingKey(protocol string, port int) string {
+	return fmt.Sprintf("%s/%d", protocol, port)
+}
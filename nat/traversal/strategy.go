@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"github.com/mysteriumnetwork/node/nat/behavior"
+)
+
+// Strategy describes how both peers punch holes in their NATs.
+// Provider and consumer select the same strategy as they exchange their NAT behaviors.
+type Strategy struct {
+	// Hopeless is set when hole punching can't succeed and should be skipped.
+	Hopeless bool
+	// Ports is a number of offered port pairs worth pinging.
+	Ports int
+	// FullTTL tells provider to ping with full TTL instead of raising it from the low initial value.
+	FullTTL bool
+}
+
+// NewStrategy selects hole punching strategy by NAT behaviors of both peers.
+// Offered ports are pinged until required number of connections is established.
+// Unknown behaviors fall back to pinging all offered ports with provider TTL raised gradually.
+func NewStrategy(provider, consumer behavior.Behavior, offeredPorts, requiredConns int) Strategy {
+	strategy := Strategy{Ports: offeredPorts}
+	if !provider.Known() || !consumer.Known() {
+		return strategy
+	}
+
+	// Peers can't guess each other's external ports when both NATs allocate them per destination,
+	// neither when port restricted NAT only lets in packets from the endpoint it has sent packets to.
+	if !provider.Predictable() && !consumer.Predictable() {
+		return Strategy{Hopeless: true}
+	}
+	if unreachable(provider, consumer) || unreachable(consumer, provider) {
+		return Strategy{Hopeless: true}
+	}
+
+	// Birthday-style pinging of many ports is only needed to hit unpredictable external ports,
+	// otherwise a spare port pair for each of the required conns is enough.
+	if provider.Predictable() && consumer.Predictable() && 2*requiredConns < offeredPorts {
+		strategy.Ports = 2 * requiredConns
+	}
+
+	// Low TTL keeps provider pings from reaching consumer NAT which might block the endpoint
+	// in return, this can't happen if consumer NAT lets any packets in.
+	strategy.FullTTL = consumer.Type == behavior.NATTypeNone || consumer.Type == behavior.NATTypeFullCone
+	return strategy
+}
+
+func unreachable(b, peer behavior.Behavior) bool {
+	return b.Type == behavior.NATTypeSymmetric && b.PortAllocation == behavior.PortAllocationRandom &&
+		peer.Type == behavior.NATTypePortRestrictedCone
+}
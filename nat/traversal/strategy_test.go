@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/nat/behavior"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStrategy(t *testing.T) {
+	var (
+		unknown        = behavior.Behavior{}
+		none           = behavior.Behavior{Type: behavior.NATTypeNone, PortAllocation: behavior.PortAllocationPreserving}
+		fullCone       = behavior.Behavior{Type: behavior.NATTypeFullCone, PortAllocation: behavior.PortAllocationRandom}
+		portRestricted = behavior.Behavior{Type: behavior.NATTypePortRestrictedCone, PortAllocation: behavior.PortAllocationSequential}
+		symmetric      = behavior.Behavior{Type: behavior.NATTypeSymmetric, PortAllocation: behavior.PortAllocationSequential}
+		symmetricRand  = behavior.Behavior{Type: behavior.NATTypeSymmetric, PortAllocation: behavior.PortAllocationRandom}
+		symmetricPres  = behavior.Behavior{Type: behavior.NATTypeSymmetric, PortAllocation: behavior.PortAllocationPreserving}
+	)
+
+	tests := []struct {
+		name     string
+		provider behavior.Behavior
+		consumer behavior.Behavior
+		expected Strategy
+	}{
+		{name: "unknown provider", provider: unknown, consumer: portRestricted, expected: Strategy{Ports: 20}},
+		{name: "unknown consumer", provider: symmetric, consumer: unknown, expected: Strategy{Ports: 20}},
+		{name: "both cones", provider: portRestricted, consumer: portRestricted, expected: Strategy{Ports: 4}},
+		{name: "consumer without NAT", provider: portRestricted, consumer: none, expected: Strategy{Ports: 4, FullTTL: true}},
+		{name: "consumer full cone", provider: symmetric, consumer: fullCone, expected: Strategy{Ports: 20, FullTTL: true}},
+		{name: "port preserving symmetric", provider: symmetricPres, consumer: portRestricted, expected: Strategy{Ports: 4}},
+		{name: "symmetric provider", provider: symmetric, consumer: portRestricted, expected: Strategy{Ports: 20}},
+		{name: "both symmetric", provider: symmetric, consumer: symmetricRand, expected: Strategy{Hopeless: true}},
+		{name: "random symmetric provider", provider: symmetricRand, consumer: portRestricted, expected: Strategy{Hopeless: true}},
+		{name: "random symmetric consumer", provider: portRestricted, consumer: symmetricRand, expected: Strategy{Hopeless: true}},
+		{name: "one symmetric side", provider: symmetricPres, consumer: symmetricRand, expected: Strategy{Ports: 20}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewStrategy(test.provider, test.consumer, 20, 2))
+		})
+	}
+}
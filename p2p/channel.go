@@ -27,12 +27,12 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mysteriumnetwork/node/trace"
 	"github.com/rs/zerolog/log"
 	kcp "github.com/xtaci/kcp-go/v5"
-	"golang.org/x/crypto/nacl/box"
 )
 
 var (
@@ -171,6 +171,9 @@ type channel struct {
 	// upnpPortsRelease should be called to close mapped upnp ports when channel is closed.
 	upnpPortsRelease []func()
 
+	// transportRelease should be called to close stream transport bridges or remove relay firewall rules when channel is closed.
+	transportRelease []func()
+
 	// stop is used to stop all running goroutines.
 	stop chan struct{}
 
@@ -231,6 +234,7 @@ func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey Publi
 		remoteAlive:      make(chan struct{}, 1),
 	}
 
+	atomic.AddInt64(&openChannels, 1)
 	return &c, nil
 }
 
@@ -438,10 +442,14 @@ func (c *channel) Close() error {
 
 	var closeErr error
 	c.once.Do(func() {
+		atomic.AddInt64(&openChannels, -1)
 		close(c.stop)
 		for _, release := range c.upnpPortsRelease {
 			release()
 		}
+		for _, release := range c.transportRelease {
+			release()
+		}
 
 		if err := c.tr.remoteConn.Close(); err != nil {
 			closeErr = fmt.Errorf("could not close remote conn: %w", err)
@@ -554,6 +562,13 @@ func (c *channel) setUpnpPortsRelease(release []func()) {
 	c.upnpPortsRelease = release
 }
 
+func (c *channel) setTransportRelease(release []func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.transportRelease = release
+}
+
 func (c *channel) checkIfChannelAlive() {
 	select {
 	case <-c.stop:
@@ -604,9 +619,8 @@ func listenUDPSession(proxyAddr net.Addr, privateKey PrivateKey, peerPubKey Publ
 
 func newBlockCrypt(privateKey PrivateKey, peerPublicKey PublicKey) (kcp.BlockCrypt, error) {
 	// Compute shared key. Nonce for each message will be added inside kcp salsa block crypt.
-	var sharedKey [32]byte
-	box.Precompute(&sharedKey, (*[32]byte)(&peerPublicKey), (*[32]byte)(&privateKey))
-	blockCrypt, err := kcp.NewSalsa20BlockCrypt(sharedKey[:])
+	key := sharedKey(privateKey, peerPublicKey)
+	blockCrypt, err := kcp.NewSalsa20BlockCrypt(key[:])
 	if err != nil {
 		return nil, fmt.Errorf("could not create Sasla20 block crypt: %w", err)
 	}
@@ -27,6 +27,7 @@ import (
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/behavior"
 	"github.com/mysteriumnetwork/node/pb"
 
 	"google.golang.org/protobuf/proto"
@@ -35,9 +36,13 @@ import (
 const (
 	pingMaxPorts       = 20
 	requiredConnCount  = 2
-	consumerInitialTTL = 128
+	maxTTL             = 128
+	consumerInitialTTL = maxTTL
 )
 
+// ErrHolePunchingHopeless is returned when NAT behaviors of the peers make hole punching impossible.
+var ErrHolePunchingHopeless = errors.New("NAT hole punching is not possible between peer NATs")
+
 type brokerConnector interface {
 	Connect(serverURIs ...*url.URL) (nats.Connection, error)
 }
@@ -50,6 +55,10 @@ type natProviderPinger interface {
 	PingConsumerPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
 }
 
+type natBehaviorProvider interface {
+	Behavior() behavior.Behavior
+}
+
 func configExchangeSubject(providerID identity.Identity, serviceType string) string {
 	return fmt.Sprintf("%s.%s.p2p-config-exchange", providerID.Address, serviceType)
 }
@@ -104,6 +113,43 @@ func unpackSignedMsg(verifier identity.Verifier, b []byte) (*pb.P2PSignedMsg, er
 	return &signedMsg, nil
 }
 
+// exchangePublicKey returns public key of the peer, which sent signed config exchange message.
+func exchangePublicKey(b []byte) (PublicKey, error) {
+	var signedMsg pb.P2PSignedMsg
+	if err := proto.Unmarshal(b, &signedMsg); err != nil {
+		return PublicKey{}, err
+	}
+	var exchangeMsg pb.P2PConfigExchangeMsg
+	if err := proto.Unmarshal(signedMsg.Data, &exchangeMsg); err != nil {
+		return PublicKey{}, err
+	}
+	return DecodePublicKey(exchangeMsg.PublicKey)
+}
+
+// packChannelHandlersReady returns message, which tells consumer that provider channel handlers are ready.
+func packChannelHandlersReady() ([]byte, error) {
+	handlersReadyMsg := pb.P2PChannelHandlersReady{Value: "HANDLERS READY"}
+
+	message, err := proto.Marshal(&handlersReadyMsg)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal exchange msg: %w", err)
+	}
+	return message, nil
+}
+
+// unpackChannelHandlersReady checks the message telling that provider channel handlers are ready.
+func unpackChannelHandlersReady(b []byte) error {
+	var handlersReady pb.P2PChannelHandlersReady
+	if err := proto.Unmarshal(b, &handlersReady); err != nil {
+		return fmt.Errorf("failed to unmarshal handlers ready message: %w", err)
+	}
+	if handlersReady.Value != "HANDLERS READY" {
+		return errors.New("incorrect handlers ready message value")
+	}
+
+	return nil
+}
+
 // encryptConnConfigMsg encrypts proto message and returns bytes.
 func encryptConnConfigMsg(msg *pb.P2PConnectConfig, privateKey PrivateKey, peerPubKey PublicKey) ([]byte, error) {
 	protoBytes, err := proto.Marshal(msg)
@@ -130,6 +176,13 @@ func decryptConnConfigMsg(ciphertext []byte, privateKey PrivateKey, peerPubKey P
 	return &peerProtoConnectConfig, nil
 }
 
+func peerNATBehavior(config *pb.P2PConnectConfig) behavior.Behavior {
+	return behavior.Behavior{
+		Type:           behavior.NATType(config.NatType),
+		PortAllocation: behavior.PortAllocation(config.PortAllocation),
+	}
+}
+
 func int32ToIntSlice(arr []int32) []int {
 	var res []int
 	for _, v := range arr {
@@ -33,6 +33,14 @@ var (
 const (
 	// ContactTypeV1 is p2p contact type.
 	ContactTypeV1 = "nats/p2p/v1"
+	// ContactTypeStreamV1 is p2p stream transport contact type.
+	ContactTypeStreamV1 = "stream/p2p/v1"
+	// ContactTypeRelayV1 is p2p relay contact type.
+	ContactTypeRelayV1 = "relay/p2p/v1"
+	// ContactTypeHTTPV1 is p2p HTTP signaling contact type.
+	ContactTypeHTTPV1 = "http/p2p/v1"
+	// ContactTypeDHTV1 is p2p DHT signaling contact type.
+	ContactTypeDHTV1 = "dht/p2p/v1"
 )
 
 // ContactDefinition represents p2p contact which contains NATS broker addresses for connection.
@@ -40,6 +48,40 @@ type ContactDefinition struct {
 	BrokerAddresses []string `json:"broker_addresses"`
 }
 
+// StreamContactDefinition represents p2p stream transport contact which contains address of provider's stream server.
+// Stream transport tunnels p2p channel and service traffic over TCP or TLS when UDP is blocked.
+type StreamContactDefinition struct {
+	Address string `json:"address"`
+	TLS     bool   `json:"tls"`
+}
+
+// RelayContactDefinition represents p2p relay contact which contains addresses of relays provider agrees to use.
+// Relays forward p2p channel and service traffic when NAT hole punching fails.
+type RelayContactDefinition struct {
+	Addresses []string `json:"addresses"`
+}
+
+// HTTPContactDefinition represents p2p HTTP signaling contact which contains address of provider's signal server.
+// Consumers exchange p2p config with provider directly over HTTP, without NATS broker.
+type HTTPContactDefinition struct {
+	Address string `json:"address"`
+}
+
+// DHTContactDefinition represents p2p DHT signaling contact which contains ID and addresses of provider's DHT peer.
+// Consumers exchange p2p config with provider over streams of their DHT nodes, without NATS broker.
+type DHTContactDefinition struct {
+	PeerID    string   `json:"peer_id"`
+	Addresses []string `json:"addresses"`
+}
+
+// SignalingDefinition describes how consumer reaches provider to exchange p2p config.
+// Direct HTTP signaling is preferred when provider offers it, then DHT signaling, NATS broker is used otherwise.
+type SignalingDefinition struct {
+	Broker *ContactDefinition
+	HTTP   *HTTPContactDefinition
+	DHT    *DHTContactDefinition
+}
+
 // ParseContact tries to parse p2p contact from given contacts list.
 func ParseContact(contacts market.ContactList) (ContactDefinition, error) {
 	for _, c := range contacts {
@@ -54,6 +96,94 @@ func ParseContact(contacts market.ContactList) (ContactDefinition, error) {
 	return ContactDefinition{}, ErrContactNotFound
 }
 
+// ParseStreamContact tries to parse p2p stream transport contact from given contacts list.
+func ParseStreamContact(contacts market.ContactList) (StreamContactDefinition, error) {
+	for _, c := range contacts {
+		if c.Type == ContactTypeStreamV1 {
+			def, ok := c.Definition.(StreamContactDefinition)
+			if !ok {
+				return StreamContactDefinition{}, fmt.Errorf("invalid p2p stream contact definition: %#v", c.Definition)
+			}
+			return def, nil
+		}
+	}
+	return StreamContactDefinition{}, ErrContactNotFound
+}
+
+// ParseRelayContact tries to parse p2p relay contact from given contacts list.
+func ParseRelayContact(contacts market.ContactList) (RelayContactDefinition, error) {
+	for _, c := range contacts {
+		if c.Type == ContactTypeRelayV1 {
+			def, ok := c.Definition.(RelayContactDefinition)
+			if !ok {
+				return RelayContactDefinition{}, fmt.Errorf("invalid p2p relay contact definition: %#v", c.Definition)
+			}
+			return def, nil
+		}
+	}
+	return RelayContactDefinition{}, ErrContactNotFound
+}
+
+// ParseHTTPContact tries to parse p2p HTTP signaling contact from given contacts list.
+func ParseHTTPContact(contacts market.ContactList) (HTTPContactDefinition, error) {
+	for _, c := range contacts {
+		if c.Type == ContactTypeHTTPV1 {
+			def, ok := c.Definition.(HTTPContactDefinition)
+			if !ok {
+				return HTTPContactDefinition{}, fmt.Errorf("invalid p2p HTTP contact definition: %#v", c.Definition)
+			}
+			return def, nil
+		}
+	}
+	return HTTPContactDefinition{}, ErrContactNotFound
+}
+
+// ParseDHTContact tries to parse p2p DHT signaling contact from given contacts list.
+func ParseDHTContact(contacts market.ContactList) (DHTContactDefinition, error) {
+	for _, c := range contacts {
+		if c.Type == ContactTypeDHTV1 {
+			def, ok := c.Definition.(DHTContactDefinition)
+			if !ok {
+				return DHTContactDefinition{}, fmt.Errorf("invalid p2p DHT contact definition: %#v", c.Definition)
+			}
+			return def, nil
+		}
+	}
+	return DHTContactDefinition{}, ErrContactNotFound
+}
+
+// ParseSignaling tries to parse all p2p signaling contacts from given contacts list.
+// ErrContactNotFound is returned if provider offers none of them.
+func ParseSignaling(contacts market.ContactList) (SignalingDefinition, error) {
+	var signaling SignalingDefinition
+
+	brokerDef, err := ParseContact(contacts)
+	if err == nil {
+		signaling.Broker = &brokerDef
+	} else if !errors.Is(err, ErrContactNotFound) {
+		return SignalingDefinition{}, err
+	}
+
+	httpDef, err := ParseHTTPContact(contacts)
+	if err == nil {
+		signaling.HTTP = &httpDef
+	} else if !errors.Is(err, ErrContactNotFound) {
+		return SignalingDefinition{}, err
+	}
+
+	dhtDef, err := ParseDHTContact(contacts)
+	if err == nil {
+		signaling.DHT = &dhtDef
+	} else if !errors.Is(err, ErrContactNotFound) {
+		return SignalingDefinition{}, err
+	}
+
+	if signaling.Broker == nil && signaling.HTTP == nil && signaling.DHT == nil {
+		return SignalingDefinition{}, ErrContactNotFound
+	}
+	return signaling, nil
+}
+
 // RegisterContactUnserializer registers global proposal contact unserializer.
 func RegisterContactUnserializer() {
 	market.RegisterContactUnserializer(
@@ -64,4 +194,36 @@ func RegisterContactUnserializer() {
 			return contact, err
 		},
 	)
+	market.RegisterContactUnserializer(
+		ContactTypeStreamV1,
+		func(rawDefinition *json.RawMessage) (market.ContactDefinition, error) {
+			var contact StreamContactDefinition
+			err := json.Unmarshal(*rawDefinition, &contact)
+			return contact, err
+		},
+	)
+	market.RegisterContactUnserializer(
+		ContactTypeRelayV1,
+		func(rawDefinition *json.RawMessage) (market.ContactDefinition, error) {
+			var contact RelayContactDefinition
+			err := json.Unmarshal(*rawDefinition, &contact)
+			return contact, err
+		},
+	)
+	market.RegisterContactUnserializer(
+		ContactTypeHTTPV1,
+		func(rawDefinition *json.RawMessage) (market.ContactDefinition, error) {
+			var contact HTTPContactDefinition
+			err := json.Unmarshal(*rawDefinition, &contact)
+			return contact, err
+		},
+	)
+	market.RegisterContactUnserializer(
+		ContactTypeDHTV1,
+		func(rawDefinition *json.RawMessage) (market.ContactDefinition, error) {
+			var contact DHTContactDefinition
+			err := json.Unmarshal(*rawDefinition, &contact)
+			return contact, err
+		},
+	)
 }
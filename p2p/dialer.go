@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"sync"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/pb"
 
 	"github.com/rs/zerolog/log"
@@ -43,13 +45,40 @@ const maxBrokerConnectAttempts = 25
 
 // Dialer knows how to exchange p2p keys and encrypted configuration and creates ready to use p2p channels.
 type Dialer interface {
-	// Dial exchanges p2p configuration via broker, performs NAT pinging if needed
+	// Dial exchanges p2p configuration via signaling, performs NAT pinging if needed
 	// and create p2p channel which is ready for communication.
-	Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef ContactDefinition, tracer *trace.Tracer) (Channel, error)
+	Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef SignalingDefinition, tracer *trace.Tracer) (Channel, error)
+
+	// DialStream exchanges p2p configuration via signaling and creates p2p channel tunneled
+	// over TCP or TLS streams of provider's stream server. It is used when UDP is blocked.
+	DialStream(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef SignalingDefinition, streamDef StreamContactDefinition, tracer *trace.Tracer) (Channel, error)
+
+	// DialRelay exchanges p2p configuration via signaling and creates p2p channel which datagrams
+	// are forwarded by the given relay. It is used when NAT hole punching fails.
+	DialRelay(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef SignalingDefinition, relayAddress string, tracer *trace.Tracer) (Channel, error)
+
+	// SetSocketProtector sets the hook protecting sockets of p2p streams, it should be set before dialing.
+	SetSocketProtector(protect SocketProtector)
 }
 
+// signalingConn carries config exchange messages between consumer and provider.
+type signalingConn interface {
+	// exchange sends signed initial exchange and returns signed reply of provider.
+	exchange(ctx context.Context, msg []byte) ([]byte, error)
+	// ack sends signed exchange ack and returns when provider is ready to be dialed.
+	ack(ctx context.Context, msg []byte) error
+	// handlersReady returns channel, which is closed when provider channel handlers are ready.
+	handlersReady() <-chan struct{}
+	Close()
+}
+
+// SocketProtector excludes the socket from the VPN tunnel (e.g. on mobile), so p2p traffic is not routed through it.
+type SocketProtector func(socket int) error
+
 // NewDialer creates new p2p communication dialer which is used on consumer side.
-func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, consumerPinger natConsumerPinger, portPool port.ServicePortSupplier) Dialer {
+// NAT behaviors of both peers are used to select hole punching strategy.
+// Signal host is optional, it lets consumer exchange config with provider over the DHT.
+func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, consumerPinger natConsumerPinger, portPool port.ServicePortSupplier, natBehavior natBehaviorProvider, signalHost SignalHost) Dialer {
 	return &dialer{
 		broker:         broker,
 		ipResolver:     ipResolver,
@@ -57,6 +86,8 @@ func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifier i
 		verifier:       verifier,
 		portPool:       portPool,
 		consumerPinger: consumerPinger,
+		natBehavior:    natBehavior,
+		signalHost:     signalHost,
 	}
 }
 
@@ -68,51 +99,84 @@ type dialer struct {
 	signer         identity.SignerFactory
 	verifier       identity.Verifier
 	ipResolver     ip.Resolver
+	natBehavior    natBehaviorProvider
+	signalHost     SignalHost
+	protectSocket  SocketProtector
 }
 
-// Dial exchanges p2p configuration via broker, performs NAT pinging if needed
+// SetSocketProtector sets the hook protecting sockets of p2p streams, it should be set before dialing.
+func (m *dialer) SetSocketProtector(protect SocketProtector) {
+	m.protectSocket = protect
+}
+
+// Dial exchanges p2p configuration via signaling, performs NAT pinging if needed
 // and create p2p channel which is ready for communication.
-func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef ContactDefinition, tracer *trace.Tracer) (Channel, error) {
-	config := &p2pConnectConfig{tracer: tracer}
+func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef SignalingDefinition, tracer *trace.Tracer) (Channel, error) {
+	return m.dial(ctx, consumerID, providerID, serviceType, signalingDef, nil, "", tracer)
+}
 
-	// Send initial exchange with signed consumer public key.
-	brokerConn, err := m.connect(contactDef, tracer)
-	if err != nil {
-		return nil, fmt.Errorf("could not open broker conn: %w", err)
-	}
-	defer brokerConn.Close()
+// DialStream exchanges p2p configuration via signaling and creates p2p channel tunneled
+// over TCP or TLS streams of provider's stream server.
+func (m *dialer) DialStream(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef SignalingDefinition, streamDef StreamContactDefinition, tracer *trace.Tracer) (Channel, error) {
+	return m.dial(ctx, consumerID, providerID, serviceType, signalingDef, &streamDef, "", tracer)
+}
 
-	peerReady := make(chan struct{})
-	var once sync.Once
-	_, err = brokerConn.Subscribe(channelHandlersReadySubject(providerID, serviceType), func(msg *nats_lib.Msg) {
-		defer once.Do(func() { close(peerReady) })
-		if err := m.channelHandlersReady(msg); err != nil {
-			log.Err(err).Msg("Channel handlers ready handler setup failed")
-			return
-		}
-	})
+// DialRelay exchanges p2p configuration via signaling and creates p2p channel which datagrams
+// are forwarded by the given relay.
+func (m *dialer) DialRelay(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef SignalingDefinition, relayAddress string, tracer *trace.Tracer) (Channel, error) {
+	return m.dial(ctx, consumerID, providerID, serviceType, signalingDef, nil, relayAddress, tracer)
+}
 
-	config, err = m.startConfigExchange(config, ctx, brokerConn, providerID, serviceType, consumerID)
+func (m *dialer) dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, signalingDef SignalingDefinition, streamDef *StreamContactDefinition, relayAddress string, tracer *trace.Tracer) (Channel, error) {
+	// Send initial exchange with signed consumer public key.
+	signaling, config, err := m.exchangeConfig(ctx, signalingDef, providerID, serviceType, consumerID, tracer)
 	if err != nil {
 		return nil, fmt.Errorf("could not exchange config: %w", err)
 	}
-
+	defer signaling.Close()
+
+	// Provider offers only ports of the required conns if it can be reached directly.
+	direct := len(config.peerPorts) == requiredConnCount
+	if streamDef != nil {
+		config.transport = transportStream
+	} else if relayAddress != "" {
+		config.transport = transportRelay
+		config.relayAddress = relayAddress
+		if config.relayToken, err = newRelayToken(); err != nil {
+			return nil, fmt.Errorf("could not generate relay token: %w", err)
+		}
+	} else if !direct {
+		strategy := traversal.NewStrategy(config.peerNATBehavior, m.natBehavior.Behavior(), len(config.peerPorts), requiredConnCount)
+		if strategy.Hopeless {
+			return nil, fmt.Errorf("could not dial p2p channel: %w", ErrHolePunchingHopeless)
+		}
+		// Provider pings as many ports as consumer acquires.
+		config.peerPorts = config.peerPorts[:strategy.Ports]
+	}
 	config.publicIP, config.localPorts, err = m.prepareLocalPorts(config)
 	if err != nil {
 		return nil, fmt.Errorf("could not prepare ports: %w", err)
 	}
 
 	// Finally send consumer encrypted and signed connect config in ack message.
-	err = m.ackConfigExchange(config, ctx, brokerConn, providerID, serviceType, consumerID)
+	err = m.ackConfigExchange(config, ctx, signaling, providerID, consumerID)
 	if err != nil {
 		return nil, fmt.Errorf("could not ack config: %w", err)
 	}
 
-	dial := m.dialPinger
-	if len(config.peerPorts) == requiredConnCount {
-		dial = m.dialDirect
+	var conn1, conn2 *net.UDPConn
+	var transportRelease []func()
+	if streamDef != nil {
+		conn1, conn2, transportRelease, err = m.dialStreams(ctx, config, *streamDef)
+	} else if relayAddress != "" {
+		conn1, conn2, transportRelease, err = m.dialRelay(ctx, config)
+	} else {
+		dial := m.dialPinger
+		if direct {
+			dial = m.dialDirect
+		}
+		conn1, conn2, err = dial(ctx, providerID, config)
 	}
-	conn1, conn2, err := dial(ctx, providerID, config)
 	if err != nil {
 		return nil, fmt.Errorf("could not dial p2p channel: %w", err)
 	}
@@ -120,9 +184,12 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	// Wait until provider confirms that channel handlers are ready.
 	traceAck := config.tracer.StartStage("Consumer P2P dial ack")
 	select {
-	case <-peerReady:
+	case <-signaling.handlersReady():
 		log.Debug().Msg("Received handlers ready message from provider")
 	case <-ctx.Done():
+		for _, release := range transportRelease {
+			release()
+		}
 		return nil, errors.New("timeout while performing configuration exchange")
 	}
 
@@ -132,12 +199,127 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	}
 	channel.setTracer(tracer)
 	channel.setServiceConn(conn2)
+	channel.setTransportRelease(transportRelease)
 	channel.launchReadSendLoops()
 	config.tracer.EndStage(traceAck)
 
 	return channel, nil
 }
 
+// signalingOption is a signaling offered by provider, which consumer can exchange config over.
+type signalingOption struct {
+	name    string
+	stage   string
+	connect func() (signalingConn, error)
+}
+
+// exchangeConfig sends initial exchange over the signaling offered by provider. Direct HTTP signaling
+// is tried first, then DHT signaling, NATS broker is used if provider does not offer them or could not be reached.
+func (m *dialer) exchangeConfig(ctx context.Context, signalingDef SignalingDefinition, providerID identity.Identity, serviceType string, consumerID identity.Identity, tracer *trace.Tracer) (signalingConn, *p2pConnectConfig, error) {
+	var options []signalingOption
+	if signalingDef.HTTP != nil {
+		options = append(options, signalingOption{
+			name:  signalingDef.HTTP.Address,
+			stage: "Consumer P2P exchange (http)",
+			connect: func() (signalingConn, error) {
+				return m.connectHTTP(*signalingDef.HTTP, providerID, serviceType)
+			},
+		})
+	}
+	if signalingDef.DHT != nil && m.signalHost != nil {
+		options = append(options, signalingOption{
+			name:  "DHT peer " + signalingDef.DHT.PeerID,
+			stage: "Consumer P2P exchange (dht)",
+			connect: func() (signalingConn, error) {
+				return m.connectDHT(*signalingDef.DHT, providerID, serviceType)
+			},
+		})
+	}
+	if signalingDef.Broker != nil {
+		options = append(options, signalingOption{
+			name:  "broker",
+			stage: "Consumer P2P exchange",
+			connect: func() (signalingConn, error) {
+				signaling, err := m.connectBroker(*signalingDef.Broker, providerID, serviceType, tracer)
+				if err != nil {
+					return nil, fmt.Errorf("could not open broker conn: %w", err)
+				}
+				return signaling, nil
+			},
+		})
+	}
+
+	err := ErrContactNotFound
+	for i, option := range options {
+		var signaling signalingConn
+		signaling, err = option.connect()
+		if err == nil {
+			var config *p2pConnectConfig
+			trace := tracer.StartStage(option.stage)
+			config, err = m.startConfigExchange(&p2pConnectConfig{tracer: tracer}, ctx, signaling, providerID, consumerID)
+			tracer.EndStage(trace)
+			if err == nil {
+				return signaling, config, nil
+			}
+			signaling.Close()
+		}
+		if i < len(options)-1 {
+			log.Warn().Err(err).Msgf("Failed to exchange config over %s, falling back to %s", option.name, options[i+1].name)
+		}
+	}
+
+	return nil, nil, err
+}
+
+func (m *dialer) connectHTTP(contactDef HTTPContactDefinition, providerID identity.Identity, serviceType string) (signalingConn, error) {
+	signalURL, err := url.Parse(contactDef.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signal address %s: %w", contactDef.Address, err)
+	}
+	removeAllowedIPRule, err := firewall.AllowIPAccess(signalURL.Hostname())
+	if err != nil {
+		return nil, fmt.Errorf("could not add signal IP firewall rule: %w", err)
+	}
+
+	return newHTTPSignaling(contactDef, providerID, serviceType, m.protectSocket, removeAllowedIPRule), nil
+}
+
+func (m *dialer) connectDHT(contactDef DHTContactDefinition, providerID identity.Identity, serviceType string) (signalingConn, error) {
+	dhtHost, err := m.signalHost.Host()
+	if err != nil {
+		return nil, fmt.Errorf("could not get DHT node: %w", err)
+	}
+
+	return newDHTSignaling(dhtHost, contactDef, providerID, serviceType)
+}
+
+func (m *dialer) connectBroker(contactDef ContactDefinition, providerID identity.Identity, serviceType string, tracer *trace.Tracer) (signalingConn, error) {
+	brokerConn, err := m.connect(contactDef, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	signaling := &brokerSignaling{
+		conn:        brokerConn,
+		providerID:  providerID,
+		serviceType: serviceType,
+		ready:       make(chan struct{}),
+	}
+	var once sync.Once
+	_, err = brokerConn.Subscribe(channelHandlersReadySubject(providerID, serviceType), func(msg *nats_lib.Msg) {
+		defer once.Do(func() { close(signaling.ready) })
+		if err := unpackChannelHandlersReady(msg.Data); err != nil {
+			log.Err(err).Msg("Channel handlers ready handler setup failed")
+			return
+		}
+	})
+	if err != nil {
+		brokerConn.Close()
+		return nil, fmt.Errorf("could not subscribe to channel handlers ready topic: %w", err)
+	}
+	return signaling, nil
+}
+
 func (m *dialer) connect(contactDef ContactDefinition, tracer *trace.Tracer) (conn nats.Connection, err error) {
 	trace := tracer.StartStage("Consumer P2P connect")
 	defer tracer.EndStage(trace)
@@ -160,10 +342,7 @@ func (m *dialer) connect(contactDef ContactDefinition, tracer *trace.Tracer) (co
 	return conn, err
 }
 
-func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Context, brokerConn nats.Connection, providerID identity.Identity, serviceType string, consumerID identity.Identity) (*p2pConnectConfig, error) {
-	trace := config.tracer.StartStage("Consumer P2P exchange")
-	defer config.tracer.EndStage(trace)
-
+func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Context, signaling signalingConn, providerID identity.Identity, consumerID identity.Identity) (*p2pConnectConfig, error) {
 	pubKey, privateKey, err := GenerateKey()
 	if err != nil {
 		return nil, fmt.Errorf("could not generate consumer p2p keys: %w", err)
@@ -177,7 +356,7 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 	if err != nil {
 		return nil, fmt.Errorf("could not pack signed message: %v", err)
 	}
-	exchangeMsgBrokerReply, err := m.sendSignedMsg(ctx, configExchangeSubject(providerID, serviceType), packedMsg, brokerConn)
+	exchangeMsgBrokerReply, err := signaling.exchange(ctx, packedMsg)
 	if err != nil {
 		return nil, fmt.Errorf("could not send signed message: %w", err)
 	}
@@ -206,16 +385,23 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 	config.peerPubKey = peerPubKey
 	config.peerPublicIP = peerConnConfig.PublicIP
 	config.peerPorts = int32ToIntSlice(peerConnConfig.Ports)
+	config.peerNATBehavior = peerNATBehavior(peerConnConfig)
 	return config, nil
 }
 
-func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context, brokerConn nats.Connection, providerID identity.Identity, serviceType string, consumerID identity.Identity) error {
+func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context, signaling signalingConn, providerID identity.Identity, consumerID identity.Identity) error {
 	trace := config.tracer.StartStage("Consumer P2P exchange ack")
 	defer config.tracer.EndStage(trace)
 
+	natBehavior := m.natBehavior.Behavior()
 	connConfig := &pb.P2PConnectConfig{
-		PublicIP: config.publicIP,
-		Ports:    intToInt32Slice(config.localPorts),
+		PublicIP:       config.publicIP,
+		Ports:          intToInt32Slice(config.localPorts),
+		Transport:      config.transport,
+		NatType:        string(natBehavior.Type),
+		PortAllocation: string(natBehavior.PortAllocation),
+		RelayAddress:   config.relayAddress,
+		RelayToken:     config.relayToken,
 	}
 	connConfigCiphertext, err := encryptConnConfigMsg(connConfig, config.privateKey, config.peerPubKey)
 	if err != nil {
@@ -235,8 +421,7 @@ func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context
 	//  until provider receives consumer config ( IP, ports ) and starts pinging Consumer first.
 	// This is why we use broker Request method to be sure that Provider processed our given configuration.
 	// To improve speed here investigate options to reduce broker communication round trip.
-	_, err = m.sendSignedMsg(ctx, configExchangeACKSubject(providerID, serviceType), packedMsg, brokerConn)
-
+	err = signaling.ack(ctx, packedMsg)
 	if err != nil {
 		return fmt.Errorf("could not send signed msg: %v", err)
 	}
@@ -254,7 +439,12 @@ func (m *dialer) prepareLocalPorts(config *p2pConnectConfig) (string, []int, err
 		return "", nil, fmt.Errorf("could not get public IP: %v", err)
 	}
 
-	localPorts, err := acquireLocalPorts(m.portPool, len(config.peerPorts))
+	// Only ports of the required conns are bridged to streams or bound to relay, no pinging is done.
+	portsCount := len(config.peerPorts)
+	if config.transport == transportStream || config.transport == transportRelay {
+		portsCount = requiredConnCount
+	}
+	localPorts, err := acquireLocalPorts(m.portPool, portsCount)
 	if err != nil {
 		return publicIP, nil, fmt.Errorf("could not acquire local ports: %v", err)
 	}
@@ -293,27 +483,90 @@ func (m *dialer) dialPinger(ctx context.Context, providerID identity.Identity, c
 	log.Debug().Msgf("Pinging provider %s with IP %s using ports %v:%v", providerID.Address, config.peerIP(), config.localPorts, config.peerPorts)
 	conns, err := m.consumerPinger.PingProviderPeer(ctx, config.peerIP(), config.localPorts, config.peerPorts, consumerInitialTTL, requiredConnCount)
 	if err != nil {
+		config.tracer.FailStage(trace, err)
 		return nil, nil, fmt.Errorf("could not ping peer: %w", err)
 	}
 	return conns[0], conns[1], nil
 }
 
-func (m *dialer) sendSignedMsg(ctx context.Context, subject string, msg []byte, brokerConn nats.Connection) ([]byte, error) {
-	reply, err := brokerConn.RequestWithContext(ctx, subject, msg)
+func (m *dialer) dialStreams(ctx context.Context, config *p2pConnectConfig, streamDef StreamContactDefinition) (*net.UDPConn, *net.UDPConn, []func(), error) {
+	trace := config.tracer.StartStage("Consumer P2P dial (stream)")
+	defer config.tracer.EndStage(trace)
+
+	host, _, err := net.SplitHostPort(streamDef.Address)
 	if err != nil {
-		return nil, fmt.Errorf("could not send broker request to subject %s: %v", subject, err)
+		return nil, nil, nil, fmt.Errorf("invalid stream address %s: %w", streamDef.Address, err)
 	}
-	return reply.Data, nil
+	removeAllowedIPRule, err := firewall.AllowIPAccess(host)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not add stream IP firewall rule: %w", err)
+	}
+
+	log.Debug().Msgf("Dialing provider streams %s (TLS: %v)", streamDef.Address, streamDef.TLS)
+	streams, err := dialStreams(ctx, streamDef, config.publicKey, config.privateKey, config.peerPubKey, m.protectSocket)
+	if err != nil {
+		removeAllowedIPRule()
+		return nil, nil, nil, err
+	}
+	conn1, conn2, release, err := bridgeStreams(streams, config.localPorts)
+	if err != nil {
+		removeAllowedIPRule()
+		return nil, nil, nil, err
+	}
+	return conn1, conn2, append(release, removeAllowedIPRule), nil
 }
 
-func (m *dialer) channelHandlersReady(msg *nats_lib.Msg) error {
-	var handlersReady pb.P2PChannelHandlersReady
-	if err := proto.Unmarshal(msg.Data, &handlersReady); err != nil {
-		return fmt.Errorf("failed to unmarshal handlers ready message: %w", err)
+func (m *dialer) dialRelay(ctx context.Context, config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, []func(), error) {
+	trace := config.tracer.StartStage("Consumer P2P dial (relay)")
+	defer config.tracer.EndStage(trace)
+
+	host, _, err := net.SplitHostPort(config.relayAddress)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid relay address %s: %w", config.relayAddress, err)
 	}
-	if handlersReady.Value != "HANDLERS READY" {
-		return errors.New("incorrect handlers ready message value")
+	removeAllowedIPRule, err := firewall.AllowIPAccess(host)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not add relay IP firewall rule: %w", err)
 	}
 
-	return nil
+	log.Debug().Msgf("Binding p2p conns to relay %s", config.relayAddress)
+	conn1, conn2, err := bindRelay(ctx, config.relayAddress, config.relayToken, config.localPorts)
+	if err != nil {
+		removeAllowedIPRule()
+		return nil, nil, nil, err
+	}
+	return conn1, conn2, []func(){removeAllowedIPRule}, nil
+}
+
+// brokerSignaling sends config exchange messages to provider over NATS broker.
+type brokerSignaling struct {
+	conn        nats.Connection
+	providerID  identity.Identity
+	serviceType string
+	ready       chan struct{}
+}
+
+func (s *brokerSignaling) exchange(ctx context.Context, msg []byte) ([]byte, error) {
+	return s.sendSignedMsg(ctx, configExchangeSubject(s.providerID, s.serviceType), msg)
+}
+
+func (s *brokerSignaling) ack(ctx context.Context, msg []byte) error {
+	_, err := s.sendSignedMsg(ctx, configExchangeACKSubject(s.providerID, s.serviceType), msg)
+	return err
+}
+
+func (s *brokerSignaling) handlersReady() <-chan struct{} {
+	return s.ready
+}
+
+func (s *brokerSignaling) Close() {
+	s.conn.Close()
+}
+
+func (s *brokerSignaling) sendSignedMsg(ctx context.Context, subject string, msg []byte) ([]byte, error) {
+	reply, err := s.conn.RequestWithContext(ctx, subject, msg)
+	if err != nil {
+		return nil, fmt.Errorf("could not send broker request to subject %s: %v", subject, err)
+	}
+	return reply.Data, nil
 }
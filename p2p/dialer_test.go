@@ -19,15 +19,20 @@ package p2p
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"net/url"
 	"testing"
 	"time"
 
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/behavior"
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/trace"
@@ -86,7 +91,7 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 			portPool := port.NewPool()
 
 			// Provider starts listening.
-			channelListener := NewListener(brokerConn, signerFactory, verifier, test.ipResolver, test.natProviderPinger, portPool, test.portMapper)
+			channelListener := NewListener(brokerConn, signerFactory, verifier, test.ipResolver, test.natProviderPinger, portPool, test.portMapper, nil, nil, nil, &mockNATBehavior{})
 			_, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
 				ch.Handle("test", func(c Context) error {
 					return c.OkWithReply(&Message{Data: []byte("pong")})
@@ -95,10 +100,10 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Consumer starts dialing provider.
-			channelDialer := NewDialer(mockBroker, signerFactory, verifier, test.ipResolver, test.natConsumerPinger, portPool)
+			channelDialer := NewDialer(mockBroker, signerFactory, verifier, test.ipResolver, test.natConsumerPinger, portPool, &mockNATBehavior{}, nil)
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
-			consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", ContactDefinition{BrokerAddresses: []string{"broker"}}, trace.NewTracer("Dial"))
+			consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", SignalingDefinition{Broker: &ContactDefinition{BrokerAddresses: []string{"broker"}}}, trace.NewTracer("Dial"))
 			assert.NoError(t, err)
 			defer consumerChannel.Close()
 
@@ -109,6 +114,286 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 	}
 }
 
+func TestDialer_DialStream_Communication_With_Provider(t *testing.T) {
+	for _, useTLS := range []bool{false, true} {
+		t.Run(fmt.Sprintf("TLS: %v", useTLS), func(t *testing.T) {
+			providerID := identity.FromAddress("0x1")
+			signerFactory := func(id identity.Identity) identity.Signer {
+				return &identity.SignerFake{}
+			}
+			verifier := &identity.VerifierFake{}
+			brokerConn := nats.StartConnectionMock()
+			defer brokerConn.Close()
+			mockBroker := &mockBroker{conn: brokerConn}
+			portPool := port.NewPool()
+			ipResolver := ip.NewResolverMock("127.0.0.1")
+
+			ports, err := acquirePorts(1)
+			assert.NoError(t, err)
+			streamServer := NewStreamServer(ports[0], useTLS)
+			assert.NoError(t, streamServer.Start())
+			defer streamServer.Stop()
+
+			// Provider starts listening.
+			channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, &mockProviderNATPinger{}, portPool, &mockPortMapper{}, streamServer, nil, nil, &mockNATBehavior{})
+			_, err = channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+				ch.Handle("test", func(c Context) error {
+					return c.OkWithReply(&Message{Data: []byte("pong")})
+				})
+			})
+			assert.NoError(t, err)
+
+			streamDef, err := ParseStreamContact(channelListener.GetContacts())
+			assert.NoError(t, err)
+			assert.Equal(t, StreamContactDefinition{Address: fmt.Sprintf("127.0.0.1:%d", ports[0]), TLS: useTLS}, streamDef)
+
+			// Consumer dials provider streams.
+			channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, &mockConsumerNATPinger{}, portPool, &mockNATBehavior{}, nil)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			consumerChannel, err := channelDialer.DialStream(ctx, identity.FromAddress("0x2"), providerID, "wireguard", SignalingDefinition{Broker: &ContactDefinition{BrokerAddresses: []string{"broker"}}}, streamDef, trace.NewTracer("Dial"))
+			assert.NoError(t, err)
+			defer consumerChannel.Close()
+
+			assert.True(t, consumerChannel.ServiceConn().RemoteAddr().(*net.UDPAddr).IP.IsLoopback())
+
+			res, err := consumerChannel.Send(context.Background(), "test", &Message{Data: []byte("ping")})
+			assert.NoError(t, err)
+			assert.Equal(t, "pong", string(res.Data))
+		})
+	}
+}
+
+func TestDialer_Dial_Over_HTTP_Signaling(t *testing.T) {
+	ports, err := acquirePorts(2)
+	assert.NoError(t, err)
+	unreachable := HTTPContactDefinition{Address: fmt.Sprintf("http://127.0.0.1:%d", ports[1])}
+
+	tests := []struct {
+		name      string
+		signaling func(httpDef HTTPContactDefinition) SignalingDefinition
+	}{
+		{
+			name: "Provider offers HTTP signaling only",
+			signaling: func(httpDef HTTPContactDefinition) SignalingDefinition {
+				return SignalingDefinition{HTTP: &httpDef}
+			},
+		},
+		{
+			name: "Provider HTTP signaling is unreachable",
+			signaling: func(HTTPContactDefinition) SignalingDefinition {
+				return SignalingDefinition{HTTP: &unreachable, Broker: &ContactDefinition{BrokerAddresses: []string{"broker"}}}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			providerID := identity.FromAddress("0x1")
+			signerFactory := func(id identity.Identity) identity.Signer {
+				return &identity.SignerFake{}
+			}
+			verifier := &identity.VerifierFake{}
+			brokerConn := nats.StartConnectionMock()
+			defer brokerConn.Close()
+			mockBroker := &mockBroker{conn: brokerConn}
+			portPool := port.NewPool()
+			ipResolver := ip.NewResolverMock("127.0.0.1")
+
+			signalServer := NewSignalServer(ports[0], nil)
+			assert.NoError(t, signalServer.Start())
+			defer signalServer.Stop()
+
+			// Provider starts listening.
+			channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, &mockProviderNATPinger{}, portPool, &mockPortMapper{}, nil, signalServer, nil, &mockNATBehavior{})
+			stop, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+				ch.Handle("test", func(c Context) error {
+					return c.OkWithReply(&Message{Data: []byte("pong")})
+				})
+			})
+			assert.NoError(t, err)
+			defer stop()
+
+			httpDef, err := ParseHTTPContact(channelListener.GetContacts())
+			assert.NoError(t, err)
+			assert.Equal(t, HTTPContactDefinition{Address: fmt.Sprintf("http://127.0.0.1:%d", ports[0])}, httpDef)
+
+			// Consumer dials provider.
+			channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, &mockConsumerNATPinger{}, portPool, &mockNATBehavior{}, nil)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", test.signaling(httpDef), trace.NewTracer("Dial"))
+			assert.NoError(t, err)
+			defer consumerChannel.Close()
+
+			res, err := consumerChannel.Send(context.Background(), "test", &Message{Data: []byte("ping")})
+			assert.NoError(t, err)
+			assert.Equal(t, "pong", string(res.Data))
+		})
+	}
+}
+
+func TestDialer_Dial_Over_DHT_Signaling(t *testing.T) {
+	providerID := identity.FromAddress("0x1")
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}
+	verifier := &identity.VerifierFake{}
+	brokerConn := nats.StartConnectionMock()
+	defer brokerConn.Close()
+	mockBroker := &mockBroker{conn: brokerConn}
+	portPool := port.NewPool()
+	ipResolver := ip.NewResolverMock("127.0.0.1")
+
+	providerHost := newMockSignalHost(t)
+	defer providerHost.host.Close()
+	consumerHost := newMockSignalHost(t)
+	defer consumerHost.host.Close()
+
+	signalServer := NewSignalServer(0, providerHost)
+	assert.NoError(t, signalServer.Start())
+	defer signalServer.Stop()
+
+	// Provider starts listening.
+	channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, &mockProviderNATPinger{}, portPool, &mockPortMapper{}, nil, signalServer, nil, &mockNATBehavior{})
+	stop, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+		ch.Handle("test", func(c Context) error {
+			return c.OkWithReply(&Message{Data: []byte("pong")})
+		})
+	})
+	assert.NoError(t, err)
+	defer stop()
+
+	_, err = ParseHTTPContact(channelListener.GetContacts())
+	assert.Equal(t, ErrContactNotFound, err)
+	dhtDef, err := ParseDHTContact(channelListener.GetContacts())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, dhtDef.Addresses)
+
+	// Consumer dials provider over DHT signaling only.
+	channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, &mockConsumerNATPinger{}, portPool, &mockNATBehavior{}, consumerHost)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", SignalingDefinition{DHT: &dhtDef}, trace.NewTracer("Dial"))
+	assert.NoError(t, err)
+	defer consumerChannel.Close()
+
+	res, err := consumerChannel.Send(context.Background(), "test", &Message{Data: []byte("ping")})
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(res.Data))
+}
+
+func TestDialer_DialRelay_Communication_With_Provider(t *testing.T) {
+	providerID := identity.FromAddress("0x1")
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}
+	verifier := &identity.VerifierFake{}
+	brokerConn := nats.StartConnectionMock()
+	defer brokerConn.Close()
+	mockBroker := &mockBroker{conn: brokerConn}
+	portPool := port.NewPool()
+	ipResolver := ip.NewResolverMock("127.0.0.1")
+
+	ports, err := acquirePorts(1)
+	assert.NoError(t, err)
+	relayServer := NewRelayServer(ports[0])
+	assert.NoError(t, relayServer.Start())
+	defer relayServer.Stop()
+	relayAddress := fmt.Sprintf("127.0.0.1:%d", ports[0])
+
+	// Provider starts listening.
+	channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, &mockProviderNATPinger{}, portPool, &mockPortMapper{}, nil, nil, []string{relayAddress}, &mockNATBehavior{})
+	_, err = channelListener.Listen(providerID, "wireguard", func(ch Channel) {
+		ch.Handle("test", func(c Context) error {
+			return c.OkWithReply(&Message{Data: []byte("pong")})
+		})
+	})
+	assert.NoError(t, err)
+
+	relayDef, err := ParseRelayContact(channelListener.GetContacts())
+	assert.NoError(t, err)
+	assert.Equal(t, RelayContactDefinition{Addresses: []string{relayAddress}}, relayDef)
+
+	// Consumer dials provider through the relay.
+	channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, &mockConsumerNATPinger{}, portPool, &mockNATBehavior{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	consumerChannel, err := channelDialer.DialRelay(ctx, identity.FromAddress("0x2"), providerID, "wireguard", SignalingDefinition{Broker: &ContactDefinition{BrokerAddresses: []string{"broker"}}}, relayAddress, trace.NewTracer("Dial"))
+	assert.NoError(t, err)
+	defer consumerChannel.Close()
+
+	assert.Equal(t, relayAddress, consumerChannel.ServiceConn().RemoteAddr().String())
+
+	res, err := consumerChannel.Send(context.Background(), "test", &Message{Data: []byte("ping")})
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(res.Data))
+}
+
+func TestDialer_Exchange_Selects_Traversal_Strategy_By_NAT_Behaviors(t *testing.T) {
+	portRestricted := behavior.Behavior{Type: behavior.NATTypePortRestrictedCone, PortAllocation: behavior.PortAllocationPreserving}
+	fullCone := behavior.Behavior{Type: behavior.NATTypeFullCone, PortAllocation: behavior.PortAllocationPreserving}
+
+	providerID := identity.FromAddress("0x1")
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}
+	verifier := &identity.VerifierFake{}
+	brokerConn := nats.StartConnectionMock()
+	defer brokerConn.Close()
+	mockBroker := &mockBroker{conn: brokerConn}
+	portPool := port.NewPool()
+	ipResolver := ip.NewResolverMockMultiple("127.0.0.1", "1.1.1.1")
+	providerPinger, consumerPinger := natTestPingers(t)
+
+	channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, providerPinger, portPool, &mockPortMapper{}, nil, nil, nil, &mockNATBehavior{portRestricted})
+	_, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {})
+	assert.NoError(t, err)
+
+	channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool, &mockNATBehavior{fullCone}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	consumerChannel, err := channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", SignalingDefinition{Broker: &ContactDefinition{BrokerAddresses: []string{"broker"}}}, trace.NewTracer("Dial"))
+	assert.NoError(t, err)
+	defer consumerChannel.Close()
+
+	consumerPings := consumerPinger.(*mockConsumerNATPinger)
+	assert.Len(t, consumerPings.localPorts, 2*requiredConnCount)
+	assert.Len(t, consumerPings.remotePorts, 2*requiredConnCount)
+	providerPings := providerPinger.(*mockProviderNATPinger)
+	assert.Equal(t, consumerPings.localPorts, providerPings.remotePorts)
+	assert.Equal(t, consumerPings.remotePorts, providerPings.localPorts)
+	assert.Equal(t, maxTTL, providerPings.initialTTL)
+}
+
+func TestDialer_Exchange_Skips_Hopeless_Hole_Punching(t *testing.T) {
+	symmetric := behavior.Behavior{Type: behavior.NATTypeSymmetric, PortAllocation: behavior.PortAllocationRandom}
+
+	providerID := identity.FromAddress("0x1")
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}
+	verifier := &identity.VerifierFake{}
+	brokerConn := nats.StartConnectionMock()
+	defer brokerConn.Close()
+	mockBroker := &mockBroker{conn: brokerConn}
+	portPool := port.NewPool()
+	ipResolver := ip.NewResolverMockMultiple("127.0.0.1", "1.1.1.1")
+	consumerPinger := &mockConsumerNATPinger{}
+
+	channelListener := NewListener(brokerConn, signerFactory, verifier, ipResolver, &mockProviderNATPinger{}, portPool, &mockPortMapper{}, nil, nil, nil, &mockNATBehavior{symmetric})
+	_, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {})
+	assert.NoError(t, err)
+
+	channelDialer := NewDialer(mockBroker, signerFactory, verifier, ipResolver, consumerPinger, portPool, &mockNATBehavior{symmetric}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = channelDialer.Dial(ctx, identity.FromAddress("0x2"), providerID, "wireguard", SignalingDefinition{Broker: &ContactDefinition{BrokerAddresses: []string{"broker"}}}, trace.NewTracer("Dial"))
+
+	assert.True(t, errors.Is(err, ErrHolePunchingHopeless))
+	assert.Nil(t, consumerPinger.localPorts)
+}
+
 func natTestPingers(t *testing.T) (providerPinger natProviderPinger, consumerPinger natConsumerPinger) {
 	ports, err := acquirePorts(2)
 	assert.NoError(t, err)
@@ -125,20 +410,49 @@ func natTestPingers(t *testing.T) (providerPinger natProviderPinger, consumerPin
 
 type mockConsumerNATPinger struct {
 	conns []*net.UDPConn
+
+	localPorts, remotePorts []int
 }
 
 func (m *mockConsumerNATPinger) PingProviderPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
+	m.localPorts, m.remotePorts = localPorts, remotePorts
 	return m.conns, nil
 }
 
 type mockProviderNATPinger struct {
 	conns []*net.UDPConn
+
+	localPorts, remotePorts []int
+	initialTTL              int
 }
 
 func (m *mockProviderNATPinger) PingConsumerPeer(ctx context.Context, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error) {
+	m.localPorts, m.remotePorts, m.initialTTL = localPorts, remotePorts, initialTTL
 	return m.conns, nil
 }
 
+type mockNATBehavior struct {
+	behavior behavior.Behavior
+}
+
+func (m *mockNATBehavior) Behavior() behavior.Behavior {
+	return m.behavior
+}
+
+type mockSignalHost struct {
+	host host.Host
+}
+
+func newMockSignalHost(t *testing.T) *mockSignalHost {
+	h, err := libp2p.New(context.Background(), libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	assert.NoError(t, err)
+	return &mockSignalHost{host: h}
+}
+
+func (m *mockSignalHost) Host() (host.Host, error) {
+	return m.host, nil
+}
+
 type mockBroker struct {
 	conn nats.Connection
 }
@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import "sync/atomic"
+
+// AppTopicKeepAliveFailed represents the topic to which failed p2p keep alive pings are published.
+const AppTopicKeepAliveFailed = "p2p_keepalive_failed"
+
+// AppEventKeepAliveFailed represents the failed p2p keep alive ping.
+type AppEventKeepAliveFailed struct {
+	SessionID string
+	// Role is the side which sent the ping: "provider" or "consumer".
+	Role string
+	// Closed is set when the max number of failures is reached and the session is closed.
+	Closed bool
+}
+
+// openChannels is the number of created p2p channels which are not closed yet.
+var openChannels int64
+
+// OpenChannels returns the number of created p2p channels which are not closed yet.
+func OpenChannels() int64 {
+	return atomic.LoadInt64(&openChannels)
+}
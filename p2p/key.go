@@ -74,6 +74,13 @@ func (k *PrivateKey) Decrypt(publicKey PublicKey, ciphertext []byte) ([]byte, er
 	return decrypted, nil
 }
 
+// sharedKey computes the key shared by both peers of the channel.
+func sharedKey(privateKey PrivateKey, peerPublicKey PublicKey) [keySize]byte {
+	var key [keySize]byte
+	box.Precompute(&key, (*[32]byte)(&peerPublicKey), (*[32]byte)(&privateKey))
+	return key
+}
+
 // GenerateKey generates p2p public and private key pairs.
 func GenerateKey() (PublicKey, PrivateKey, error) {
 	publicKey := [keySize]byte{}
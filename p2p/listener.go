@@ -19,6 +19,7 @@ package p2p
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
@@ -29,6 +30,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/behavior"
 	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/pb"
@@ -45,13 +47,17 @@ type Listener interface {
 	// to channelHandlers
 	Listen(providerID identity.Identity, serviceType string, channelHandler func(ch Channel)) (func(), error)
 
-	// GetContact returns contract which is later can be added to proposal contacts definition so consumer can
+	// GetContacts returns contacts which are later added to proposal contacts definition so consumer can
 	// know how to connect to this p2p listener.
-	GetContact() market.Contact
+	GetContacts() market.ContactList
 }
 
 // NewListener creates new p2p communication listener which is used on provider side.
-func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, providerPinger natProviderPinger, portPool port.ServicePortSupplier, portMapper mapping.PortMapper) Listener {
+// Stream server is optional, it lets consumers with blocked UDP to connect over TCP or TLS.
+// Signal server is optional, it lets consumers exchange config directly over HTTP or DHT without NATS broker.
+// Relays are optional too, consumers fall back to them when NAT hole punching fails.
+// NAT behaviors of both peers are used to select hole punching strategy.
+func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, providerPinger natProviderPinger, portPool port.ServicePortSupplier, portMapper mapping.PortMapper, streamServer *StreamServer, signalServer *SignalServer, relays []string, natBehavior natBehaviorProvider) Listener {
 	return &listener{
 		brokerConn:     brokerConn,
 		pendingConfigs: map[PublicKey]p2pConnectConfig{},
@@ -61,6 +67,10 @@ func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, veri
 		portPool:       portPool,
 		providerPinger: providerPinger,
 		portMapper:     portMapper,
+		streamServer:   streamServer,
+		signalServer:   signalServer,
+		relays:         relays,
+		natBehavior:    natBehavior,
 	}
 }
 
@@ -73,6 +83,10 @@ type listener struct {
 	verifier       identity.Verifier
 	ipResolver     ip.Resolver
 	portMapper     mapping.PortMapper
+	streamServer   *StreamServer
+	signalServer   *SignalServer
+	relays         []string
+	natBehavior    natBehaviorProvider
 
 	// Keys holds pendingConfigs temporary configs for provider side since it
 	// need to handle key exchange in two steps.
@@ -90,6 +104,17 @@ type p2pConnectConfig struct {
 	peerPubKey       PublicKey
 	tracer           *trace.Tracer
 	upnpPortsRelease []func()
+	transport        string
+	peerNATBehavior  behavior.Behavior
+	relayAddress     string
+	relayToken       []byte
+}
+
+// releasePorts releases port mappings of the connection, which is not going to be established.
+func (c *p2pConnectConfig) releasePorts() {
+	for _, release := range c.upnpPortsRelease {
+		release()
+	}
 }
 
 func (c *p2pConnectConfig) peerIP() string {
@@ -100,10 +125,41 @@ func (c *p2pConnectConfig) peerIP() string {
 	return c.peerPublicIP
 }
 
-func (m *listener) GetContact() market.Contact {
-	return market.Contact{
-		Type:       ContactTypeV1,
-		Definition: ContactDefinition{BrokerAddresses: m.brokerConn.Servers()}}
+func (m *listener) GetContacts() market.ContactList {
+	contacts := market.ContactList{
+		{
+			Type:       ContactTypeV1,
+			Definition: ContactDefinition{BrokerAddresses: m.brokerConn.Servers()},
+		},
+	}
+	if len(m.relays) > 0 {
+		contacts = append(contacts, market.Contact{
+			Type:       ContactTypeRelayV1,
+			Definition: RelayContactDefinition{Addresses: m.relays},
+		})
+	}
+	if m.signalServer != nil {
+		if contact, ok := m.signalServer.dhtContact(); ok {
+			contacts = append(contacts, contact)
+		}
+	}
+	httpSignal := m.signalServer != nil && m.signalServer.servesHTTP()
+	if m.streamServer == nil && !httpSignal {
+		return contacts
+	}
+
+	publicIP, err := m.ipResolver.GetPublicIP()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not get public IP, p2p stream and HTTP contacts are not provided")
+		return contacts
+	}
+	if httpSignal {
+		contacts = append(contacts, m.signalServer.httpContact(publicIP))
+	}
+	if m.streamServer != nil {
+		contacts = append(contacts, m.streamServer.contact(publicIP))
+	}
+	return contacts
 }
 
 // Listen listens for incoming peer connections to establish new p2p channels. Establishes p2p channel and passes it
@@ -114,94 +170,38 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get outbound IP: %w", err)
 	}
 
+	exchange := func(msg []byte) ([]byte, error) {
+		return m.providerStartConfigExchange(providerID, msg, outboundIP)
+	}
+	ack := func(msg []byte, reply func() error, ready func(peerPubKey PublicKey) error) error {
+		return m.providerAck(msg, channelHandlers, reply, ready)
+	}
+
 	configSub, err := m.brokerConn.Subscribe(configExchangeSubject(providerID, serviceType), func(msg *nats_lib.Msg) {
-		if err := m.providerStartConfigExchange(providerID, msg, outboundIP); err != nil {
+		reply, err := exchange(msg.Data)
+		if err != nil {
 			log.Err(err).Msg("Could not handle initial exchange")
 			return
 		}
+		if err := m.brokerConn.Publish(msg.Reply, reply); err != nil {
+			log.Err(err).Msg("Could not publish message via broker")
+		}
 	})
 	if err != nil {
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange topic: %w", err)
 	}
 
 	ackSub, err := m.brokerConn.Subscribe(configExchangeACKSubject(providerID, serviceType), func(msg *nats_lib.Msg) {
-		config, err := m.providerAckConfigExchange(msg)
-		if err != nil {
-			log.Err(err).Msg("Could not handle exchange ack")
-			return
+		reply := func() error {
+			return m.brokerConn.Publish(msg.Reply, []byte("OK"))
 		}
-
-		trace := config.tracer.StartStage("Provider P2P exchange ack")
-		// Send ack in separate goroutine and start pinging.
-		// It is important that provider starts sending pings first otherwise
-		// providers router can think that consumer is sending DDoS packets.
-		go func(reply string) {
-			// race condition still happens when consumer starts to ping until provider did not manage to complete required number of pings
-			// this might be provider / consumer performance dependent
-			// make sleep time dependent on pinger interval and wait for 2 ping iterations
-			// TODO: either reintroduce eventual increase of TTL on consumer or maintain some sane delay
-			dur := traversal.DefaultPingConfig().Interval.Milliseconds() * int64(len(config.localPorts)) / 2
-			log.Debug().Msgf("Delaying pings from consumer for %v ms", dur)
-			time.Sleep(time.Duration(dur) * time.Millisecond)
-
-			if err := m.brokerConn.Publish(reply, []byte("OK")); err != nil {
-				log.Err(err).Msg("Could not publish exchange ack")
-			}
-			config.tracer.EndStage(trace)
-		}(msg.Reply)
-
-		var conn1, conn2 *net.UDPConn
-		if len(config.peerPorts) == requiredConnCount {
-			traceDial := config.tracer.StartStage("Provider P2P dial (upnp)")
-			log.Debug().Msg("Skipping consumer ping")
-			conn1, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
-			if err != nil {
-				log.Err(err).Msg("Could not create UDP conn for p2p channel")
-				return
-			}
-			conn2, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
-			if err != nil {
-				log.Err(err).Msg("Could not create UDP conn for service")
-				return
-			}
-			config.tracer.EndStage(traceDial)
-		} else {
-			traceDial := config.tracer.StartStage("Provider P2P dial (pinger)")
-			log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v initial ttl: %v",
-				config.peerIP(), config.localPorts, config.peerPorts, providerInitialTTL)
-			conns, err := m.providerPinger.PingConsumerPeer(context.Background(), config.peerIP(), config.localPorts, config.peerPorts, providerInitialTTL, requiredConnCount)
-			if err != nil {
-				log.Err(err).Msg("Could not ping peer")
-				return
-			}
-			conn1 = conns[0]
-			conn2 = conns[1]
-			config.tracer.EndStage(traceDial)
+		ready := func(PublicKey) error {
+			return m.providerChannelHandlersReady(providerID, serviceType)
 		}
-
-		traceAck := config.tracer.StartStage("Provider P2P dial ack")
-		channel, err := newChannel(conn1, config.privateKey, config.peerPubKey)
-		if err != nil {
-			log.Err(err).Msg("Could not create channel")
-			return
-		}
-		channel.setTracer(config.tracer)
-		channel.setServiceConn(conn2)
-		channel.setUpnpPortsRelease(config.upnpPortsRelease)
-
-		channelHandlers(channel)
-
-		channel.launchReadSendLoops()
-
-		// Send handlers ready to consumer.
-		if err := m.providerChannelHandlersReady(providerID, serviceType); err != nil {
-			log.Err(err).Msg("Could not handle channel handlers ready")
-			channel.Close()
-			return
+		if err := ack(msg.Data, reply, ready); err != nil {
+			log.Err(err).Msg("Could not handle exchange ack")
 		}
-		config.tracer.EndStage(traceAck)
 	})
-
 	if err != nil {
 		if err := configSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
@@ -209,7 +209,13 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange acknowledge topic: %w", err)
 	}
 
+	unregisterSignal := func() {}
+	if m.signalServer != nil {
+		unregisterSignal = m.signalServer.register(providerID, serviceType, signalHandlers{exchange: exchange, ack: ack})
+	}
+
 	return func() {
+		unregisterSignal()
 		if err := configSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
 		}
@@ -219,7 +225,139 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 	}, nil
 }
 
-func (m *listener) providerStartConfigExchange(signerID identity.Identity, msg *nats_lib.Msg, outboundIP string) error {
+// providerAck accepts exchange ack of the consumer and establishes p2p channel in background.
+// Reply lets consumer start dialing, ready tells it that channel handlers are ready.
+func (m *listener) providerAck(msg []byte, channelHandlers func(ch Channel), reply func() error, ready func(peerPubKey PublicKey) error) error {
+	config, err := m.providerAckConfigExchange(msg)
+	if err != nil {
+		return err
+	}
+
+	streamed := config.transport == transportStream
+	relayed := config.transport == transportRelay
+	if streamed {
+		if m.streamServer == nil {
+			config.releasePorts()
+			return errors.New("consumer requested p2p stream transport which is not enabled")
+		}
+		// Streams must be expected before consumer receives ack and starts dialing them.
+		m.streamServer.expect(config.privateKey, config.peerPubKey)
+	}
+	if relayed && !m.isRelay(config.relayAddress) {
+		config.releasePorts()
+		return fmt.Errorf("consumer requested p2p relay %s which is not advertised", config.relayAddress)
+	}
+
+	trace := config.tracer.StartStage("Provider P2P exchange ack")
+	// Send ack in separate goroutine and start pinging.
+	// It is important that provider starts sending pings first otherwise
+	// providers router can think that consumer is sending DDoS packets.
+	go func() {
+		// race condition still happens when consumer starts to ping until provider did not manage to complete required number of pings
+		// this might be provider / consumer performance dependent
+		// make sleep time dependent on pinger interval and wait for 2 ping iterations
+		// TODO: either reintroduce eventual increase of TTL on consumer or maintain some sane delay
+		if !streamed && !relayed {
+			dur := traversal.DefaultPingConfig().Interval.Milliseconds() * int64(len(config.peerPorts)) / 2
+			log.Debug().Msgf("Delaying pings from consumer for %v ms", dur)
+			time.Sleep(time.Duration(dur) * time.Millisecond)
+		}
+
+		if err := reply(); err != nil {
+			log.Err(err).Msg("Could not publish exchange ack")
+		}
+		config.tracer.EndStage(trace)
+	}()
+
+	go m.providerDial(config, channelHandlers, ready)
+	return nil
+}
+
+// providerDial establishes p2p channel over the transport chosen by consumer and passes it to channelHandlers.
+func (m *listener) providerDial(config *p2pConnectConfig, channelHandlers func(ch Channel), ready func(peerPubKey PublicKey) error) {
+	var err error
+	var conn1, conn2 *net.UDPConn
+	var transportRelease []func()
+	if config.transport == transportStream {
+		traceDial := config.tracer.StartStage("Provider P2P dial (stream)")
+		conn1, conn2, transportRelease, err = m.acceptStreams(config)
+		if err != nil {
+			log.Err(err).Msg("Could not accept p2p streams")
+			config.releasePorts()
+			return
+		}
+		config.tracer.EndStage(traceDial)
+	} else if config.transport == transportRelay {
+		traceDial := config.tracer.StartStage("Provider P2P dial (relay)")
+		conn1, conn2, err = m.bindRelay(config)
+		if err != nil {
+			log.Err(err).Msg("Could not bind p2p conns to relay")
+			return
+		}
+		config.tracer.EndStage(traceDial)
+	} else if len(config.localPorts) == requiredConnCount {
+		traceDial := config.tracer.StartStage("Provider P2P dial (upnp)")
+		log.Debug().Msg("Skipping consumer ping")
+		conn1, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
+		if err != nil {
+			log.Err(err).Msg("Could not create UDP conn for p2p channel")
+			return
+		}
+		conn2, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
+		if err != nil {
+			log.Err(err).Msg("Could not create UDP conn for service")
+			return
+		}
+		config.tracer.EndStage(traceDial)
+	} else {
+		traceDial := config.tracer.StartStage("Provider P2P dial (pinger)")
+		if len(config.peerPorts) > len(config.localPorts) {
+			log.Error().Msgf("Consumer offered %d ports, only %d are available", len(config.peerPorts), len(config.localPorts))
+			return
+		}
+		// Consumer acquires only ports worth pinging according to the strategy.
+		localPorts := config.localPorts[:len(config.peerPorts)]
+		ttl := providerInitialTTL
+		if traversal.NewStrategy(m.natBehavior.Behavior(), config.peerNATBehavior, len(config.localPorts), requiredConnCount).FullTTL {
+			ttl = maxTTL
+		}
+		log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v initial ttl: %v",
+			config.peerIP(), localPorts, config.peerPorts, ttl)
+		conns, err := m.providerPinger.PingConsumerPeer(context.Background(), config.peerIP(), localPorts, config.peerPorts, ttl, requiredConnCount)
+		if err != nil {
+			log.Err(err).Msg("Could not ping peer")
+			return
+		}
+		conn1 = conns[0]
+		conn2 = conns[1]
+		config.tracer.EndStage(traceDial)
+	}
+
+	traceAck := config.tracer.StartStage("Provider P2P dial ack")
+	channel, err := newChannel(conn1, config.privateKey, config.peerPubKey)
+	if err != nil {
+		log.Err(err).Msg("Could not create channel")
+		return
+	}
+	channel.setTracer(config.tracer)
+	channel.setServiceConn(conn2)
+	channel.setUpnpPortsRelease(config.upnpPortsRelease)
+	channel.setTransportRelease(transportRelease)
+
+	channelHandlers(channel)
+
+	channel.launchReadSendLoops()
+
+	// Send handlers ready to consumer.
+	if err := ready(config.peerPubKey); err != nil {
+		log.Err(err).Msg("Could not handle channel handlers ready")
+		channel.Close()
+		return
+	}
+	config.tracer.EndStage(traceAck)
+}
+
+func (m *listener) providerStartConfigExchange(signerID identity.Identity, msg []byte, outboundIP string) ([]byte, error) {
 	tracer := trace.NewTracer("Provider whole Connect")
 
 	trace := tracer.StartStage("Provider P2P exchange")
@@ -227,27 +365,27 @@ func (m *listener) providerStartConfigExchange(signerID identity.Identity, msg *
 
 	pubKey, privateKey, err := GenerateKey()
 	if err != nil {
-		return fmt.Errorf("could not generate provider p2p keys: %w", err)
+		return nil, fmt.Errorf("could not generate provider p2p keys: %w", err)
 	}
 
 	// Get initial peer exchange with it's public key.
-	signedMsg, err := unpackSignedMsg(m.verifier, msg.Data)
+	signedMsg, err := unpackSignedMsg(m.verifier, msg)
 	if err != nil {
-		return fmt.Errorf("could not unpack signed msg: %w", err)
+		return nil, fmt.Errorf("could not unpack signed msg: %w", err)
 	}
 	var peerExchangeMsg pb.P2PConfigExchangeMsg
 	if err := proto.Unmarshal(signedMsg.Data, &peerExchangeMsg); err != nil {
-		return err
+		return nil, err
 	}
 	peerPubKey, err := DecodePublicKey(peerExchangeMsg.PublicKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	log.Debug().Msgf("Received consumer public key %s", peerPubKey.Hex())
 
 	publicIP, localPorts, portsRelease, err := m.prepareLocalPorts(outboundIP, tracer)
 	if err != nil {
-		return fmt.Errorf("could not prepare ports: %w", err)
+		return nil, fmt.Errorf("could not prepare ports: %w", err)
 	}
 
 	m.setPendingConfig(p2pConnectConfig{
@@ -262,13 +400,16 @@ func (m *listener) providerStartConfigExchange(signerID identity.Identity, msg *
 		peerPorts:        nil,
 	})
 
+	natBehavior := m.natBehavior.Behavior()
 	config := pb.P2PConnectConfig{
-		PublicIP: publicIP,
-		Ports:    intToInt32Slice(localPorts),
+		PublicIP:       publicIP,
+		Ports:          intToInt32Slice(localPorts),
+		NatType:        string(natBehavior.Type),
+		PortAllocation: string(natBehavior.PortAllocation),
 	}
 	configCiphertext, err := encryptConnConfigMsg(&config, privateKey, peerPubKey)
 	if err != nil {
-		return fmt.Errorf("could not encrypt config msg: %v", err)
+		return nil, fmt.Errorf("could not encrypt config msg: %v", err)
 	}
 	exchangeMsg := pb.P2PConfigExchangeMsg{
 		PublicKey:        pubKey.Hex(),
@@ -277,13 +418,9 @@ func (m *listener) providerStartConfigExchange(signerID identity.Identity, msg *
 	log.Debug().Msgf("Sending reply with public key %s and encrypted config to consumer", exchangeMsg.PublicKey)
 	packedMsg, err := packSignedMsg(m.signer, signerID, &exchangeMsg)
 	if err != nil {
-		return fmt.Errorf("could not pack signed message: %v", err)
-	}
-	err = m.brokerConn.Publish(msg.Reply, packedMsg)
-	if err != nil {
-		return fmt.Errorf("could not publish message via broker: %v", err)
+		return nil, fmt.Errorf("could not pack signed message: %v", err)
 	}
-	return nil
+	return packedMsg, nil
 }
 
 // prepareLocalPorts acquires ports for p2p connections. It tries to acquire only
@@ -342,8 +479,8 @@ func (m *listener) prepareLocalPorts(outboundIP string, tracer *trace.Tracer) (s
 	return publicIP, localPorts, nil, nil
 }
 
-func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConfig, error) {
-	signedMsg, err := unpackSignedMsg(m.verifier, msg.Data)
+func (m *listener) providerAckConfigExchange(msg []byte) (*p2pConnectConfig, error) {
+	signedMsg, err := unpackSignedMsg(m.verifier, msg)
 	if err != nil {
 		return nil, fmt.Errorf("could not unpack signed msg: %w", err)
 	}
@@ -379,15 +516,47 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 		publicIP:         config.publicIP,
 		tracer:           config.tracer,
 		upnpPortsRelease: config.upnpPortsRelease,
+		transport:        peerConfig.Transport,
+		peerNATBehavior:  peerNATBehavior(peerConfig),
+		relayAddress:     peerConfig.RelayAddress,
+		relayToken:       peerConfig.RelayToken,
 	}, nil
 }
 
-func (m *listener) providerChannelHandlersReady(providerID identity.Identity, serviceType string) error {
-	handlersReadyMsg := pb.P2PChannelHandlersReady{Value: "HANDLERS READY"}
+// acceptStreams waits for consumer streams and bridges them to the UDP conns of acquired local ports.
+func (m *listener) acceptStreams(config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, []func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), streamAcceptTimeout)
+	defer cancel()
 
-	message, err := proto.Marshal(&handlersReadyMsg)
+	streams, err := m.streamServer.accept(ctx, config.peerPubKey)
 	if err != nil {
-		return fmt.Errorf("could not marshal exchange msg: %w", err)
+		return nil, nil, nil, err
+	}
+	return bridgeStreams(streams, config.localPorts)
+}
+
+// bindRelay binds UDP conns of acquired local ports to the relay chosen by consumer.
+func (m *listener) bindRelay(config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), relayBindTimeout)
+	defer cancel()
+
+	log.Debug().Msgf("Binding p2p conns to relay %s", config.relayAddress)
+	return bindRelay(ctx, config.relayAddress, config.relayToken, config.localPorts)
+}
+
+func (m *listener) isRelay(address string) bool {
+	for _, relay := range m.relays {
+		if relay == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *listener) providerChannelHandlersReady(providerID identity.Identity, serviceType string) error {
+	message, err := packChannelHandlersReady()
+	if err != nil {
+		return err
 	}
 
 	log.Debug().Msgf("Sending handlers ready message")
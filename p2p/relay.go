@@ -0,0 +1,292 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// transportRelay is a transport of p2p connect config, which forwards UDP traffic through the relay.
+	transportRelay = "relay"
+
+	relayTokenSize    = 16
+	relayKeySize      = relayTokenSize + 1
+	relayBindInterval = 200 * time.Millisecond
+	relayBindTimeout  = time.Minute
+	relayIdleTimeout  = 5 * time.Minute
+	relayBufferSize   = 1 << 16
+)
+
+var (
+	relayBindMagic  = []byte("MRB1")
+	relayReadyMagic = []byte("MRR1")
+)
+
+// RelayServer forwards p2p channel and service datagrams between peers which can't reach each other directly.
+// Peers bind their conns by the same key and relay pairs them, datagrams are encrypted by peers so relay can't read them.
+type RelayServer struct {
+	port int
+
+	mu      sync.Mutex
+	conn    *net.UDPConn
+	pending map[string]relayEndpoint
+	pairs   map[string]*relayPair
+	stop    chan struct{}
+}
+
+type relayEndpoint struct {
+	addr  *net.UDPAddr
+	since time.Time
+}
+
+type relayPair struct {
+	key      string
+	peers    [2]*net.UDPAddr
+	lastSeen time.Time
+}
+
+// NewRelayServer creates relay server listening on given UDP port.
+func NewRelayServer(port int) *RelayServer {
+	return &RelayServer{
+		port:    port,
+		pending: make(map[string]relayEndpoint),
+		pairs:   make(map[string]*relayPair),
+	}
+}
+
+// Start starts relaying datagrams.
+func (s *RelayServer) Start() error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: s.port})
+	if err != nil {
+		return fmt.Errorf("could not listen relay port %d: %w", s.port, err)
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	s.stop = make(chan struct{})
+	s.mu.Unlock()
+
+	log.Info().Msgf("Relaying p2p datagrams on port %d", s.port)
+	go s.serve(conn)
+	go s.expireLoop(s.stop)
+	return nil
+}
+
+// Stop stops relaying datagrams.
+func (s *RelayServer) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+		close(s.stop)
+	}
+}
+
+func (s *RelayServer) serve(conn *net.UDPConn) {
+	buf := make([]byte, relayBufferSize)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			log.Debug().Err(err).Msg("Stopped relaying p2p datagrams")
+			return
+		}
+
+		datagram := buf[:n]
+		if key, ok := parseRelayMsg(datagram, relayBindMagic); ok {
+			s.bind(conn, addr, key)
+			continue
+		}
+		if peer := s.peer(addr); peer != nil {
+			if _, err := conn.WriteToUDP(datagram, peer); err != nil {
+				log.Debug().Err(err).Msgf("Could not relay datagram to %s", peer)
+			}
+		}
+	}
+}
+
+// bind pairs endpoint with the other one bound by the same key and tells both that relay is ready.
+func (s *RelayServer) bind(conn *net.UDPConn, addr *net.UDPAddr, key []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ready := append(append([]byte{}, relayReadyMagic...), key...)
+	if pair, ok := s.pairs[addr.String()]; ok {
+		if pair.key == string(key) {
+			// Peer has not received ready message yet.
+			conn.WriteToUDP(ready, addr)
+			return
+		}
+		s.removePair(pair)
+	}
+
+	pending, ok := s.pending[string(key)]
+	if !ok || pending.addr.String() == addr.String() {
+		s.pending[string(key)] = relayEndpoint{addr: addr, since: time.Now()}
+		return
+	}
+
+	delete(s.pending, string(key))
+	pair := &relayPair{key: string(key), peers: [2]*net.UDPAddr{pending.addr, addr}, lastSeen: time.Now()}
+	s.pairs[pending.addr.String()] = pair
+	s.pairs[addr.String()] = pair
+	for _, peer := range pair.peers {
+		conn.WriteToUDP(ready, peer)
+	}
+}
+
+// peer returns the endpoint paired with the given one.
+func (s *RelayServer) peer(addr *net.UDPAddr) *net.UDPAddr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pair, ok := s.pairs[addr.String()]
+	if !ok {
+		return nil
+	}
+	pair.lastSeen = time.Now()
+	if pair.peers[0].String() == addr.String() {
+		return pair.peers[1]
+	}
+	return pair.peers[0]
+}
+
+func (s *RelayServer) expireLoop(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(relayBindTimeout):
+			s.expire(time.Now())
+		}
+	}
+}
+
+// expire removes idle pairs and endpoints which were not paired in time.
+func (s *RelayServer) expire(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, pending := range s.pending {
+		if now.Sub(pending.since) > relayBindTimeout {
+			delete(s.pending, key)
+		}
+	}
+	for _, pair := range s.pairs {
+		if now.Sub(pair.lastSeen) > relayIdleTimeout {
+			s.removePair(pair)
+		}
+	}
+}
+
+func (s *RelayServer) removePair(pair *relayPair) {
+	for _, peer := range pair.peers {
+		if s.pairs[peer.String()] == pair {
+			delete(s.pairs, peer.String())
+		}
+	}
+}
+
+// bindRelay binds UDP conns of the local ports to the relay and waits until peer binds its conns too.
+// Returned conns look the same as the ones created by NAT hole punching.
+func bindRelay(ctx context.Context, address string, token []byte, localPorts []int) (*net.UDPConn, *net.UDPConn, error) {
+	if len(token) != relayTokenSize {
+		return nil, nil, errors.New("invalid relay token size")
+	}
+	if len(localPorts) < requiredConnCount {
+		return nil, nil, fmt.Errorf("not enough local ports to bind to relay: %d", len(localPorts))
+	}
+
+	relayAddr, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not resolve relay address %s: %w", address, err)
+	}
+
+	var conns []*net.UDPConn
+	for i := 0; i < requiredConnCount; i++ {
+		conn, err := net.DialUDP("udp4", &net.UDPAddr{Port: localPorts[i]}, relayAddr)
+		if err != nil {
+			err = fmt.Errorf("could not create UDP conn for relay: %w", err)
+		} else if err = bindRelayConn(ctx, conn, append(append([]byte{}, token...), byte(i))); err != nil {
+			conn.Close()
+		}
+		if err != nil {
+			for _, conn := range conns {
+				conn.Close()
+			}
+			return nil, nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return conns[0], conns[1], nil
+}
+
+func bindRelayConn(ctx context.Context, conn *net.UDPConn, key []byte) error {
+	bind := append(append([]byte{}, relayBindMagic...), key...)
+	buf := make([]byte, relayBufferSize)
+	for {
+		if _, err := conn.Write(bind); err != nil {
+			return fmt.Errorf("could not send relay bind: %w", err)
+		}
+
+		conn.SetReadDeadline(time.Now().Add(relayBindInterval))
+		n, err := conn.Read(buf)
+		conn.SetReadDeadline(time.Time{})
+		if err == nil {
+			if readyKey, ok := parseRelayMsg(buf[:n], relayReadyMagic); ok && bytes.Equal(readyKey, key) {
+				return nil
+			}
+			continue
+		}
+		if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+			return fmt.Errorf("could not read relay ready: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("peer was not bound to relay %s: %w", conn.RemoteAddr(), ctx.Err())
+		default:
+		}
+	}
+}
+
+func newRelayToken() ([]byte, error) {
+	token := make([]byte, relayTokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func parseRelayMsg(msg, magic []byte) ([]byte, bool) {
+	if len(msg) != len(magic)+relayKeySize || !bytes.HasPrefix(msg, magic) {
+		return nil, false
+	}
+	return msg[len(magic):], true
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayServer_ForwardsDatagramsBetweenBoundPeers(t *testing.T) {
+	ports, err := acquirePorts(5)
+	assert.NoError(t, err)
+	relayServer := NewRelayServer(ports[0])
+	assert.NoError(t, relayServer.Start())
+	defer relayServer.Stop()
+	relayAddress := fmt.Sprintf("127.0.0.1:%d", ports[0])
+
+	token, err := newRelayToken()
+	assert.NoError(t, err)
+
+	type bound struct {
+		conn1, conn2 *net.UDPConn
+		err          error
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	providerBound := make(chan bound)
+	go func() {
+		conn1, conn2, err := bindRelay(ctx, relayAddress, token, ports[1:3])
+		providerBound <- bound{conn1, conn2, err}
+	}()
+	consumerConn1, consumerConn2, err := bindRelay(ctx, relayAddress, token, ports[3:5])
+	assert.NoError(t, err)
+	defer consumerConn1.Close()
+	defer consumerConn2.Close()
+	provider := <-providerBound
+	assert.NoError(t, provider.err)
+	defer provider.conn1.Close()
+	defer provider.conn2.Close()
+
+	assertRelayed(t, consumerConn1, provider.conn1, "channel")
+	assertRelayed(t, provider.conn2, consumerConn2, "service")
+}
+
+func TestRelayServer_Bind_FailsWithoutPeer(t *testing.T) {
+	ports, err := acquirePorts(3)
+	assert.NoError(t, err)
+	relayServer := NewRelayServer(ports[0])
+	assert.NoError(t, relayServer.Start())
+	defer relayServer.Stop()
+
+	token, err := newRelayToken()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_, _, err = bindRelay(ctx, fmt.Sprintf("127.0.0.1:%d", ports[0]), token, ports[1:3])
+	assert.Error(t, err)
+}
+
+func TestRelayServer_Expire(t *testing.T) {
+	relayServer := NewRelayServer(0)
+	now := time.Now()
+	peer1 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1001}
+	peer2 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1002}
+	idle := &relayPair{key: "idle", peers: [2]*net.UDPAddr{peer1, peer2}, lastSeen: now.Add(-relayIdleTimeout - time.Second)}
+	relayServer.pairs[peer1.String()] = idle
+	relayServer.pairs[peer2.String()] = idle
+	relayServer.pending["stale"] = relayEndpoint{addr: peer1, since: now.Add(-relayBindTimeout - time.Second)}
+	relayServer.pending["fresh"] = relayEndpoint{addr: peer2, since: now}
+
+	relayServer.expire(now)
+
+	assert.Empty(t, relayServer.pairs)
+	assert.Len(t, relayServer.pending, 1)
+	assert.Contains(t, relayServer.pending, "fresh")
+}
+
+func assertRelayed(t *testing.T, from, to *net.UDPConn, payload string) {
+	_, err := from.Write([]byte(payload))
+	assert.NoError(t, err)
+
+	buf := make([]byte, 64)
+	to.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := to.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, string(buf[:n]))
+}
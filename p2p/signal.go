@@ -0,0 +1,381 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	signalPathPrefix      = "/p2p/"
+	signalMaxMsgSize      = 64 << 10
+	signalHeaderTimeout   = 10 * time.Second
+	signalReadyTimeout    = 30 * time.Second
+	signalMessageExchange = "exchange"
+	signalMessageAck      = "ack"
+	signalMessageReady    = "ready"
+	// signalStatusGoneAway tells that consumer has gone before the reply was ready.
+	signalStatusGoneAway = 0
+)
+
+// SignalServer serves p2p config exchange over HTTP, so consumers can reach provider
+// having public IP without NATS broker. Signed messages are the same as over the broker,
+// only the consumer waits for channel handlers ready with a long polling request.
+// The same exchange is served over streams of the DHT node, when node has one.
+type SignalServer struct {
+	port       int
+	signalHost SignalHost
+
+	server   *http.Server
+	dhtHost  host.Host
+	mu       sync.Mutex
+	services map[string]signalHandlers
+	ready    map[PublicKey]chan struct{}
+}
+
+// signalHandlers handle config exchange messages of the provider service.
+type signalHandlers struct {
+	exchange func(msg []byte) ([]byte, error)
+	ack      func(msg []byte, reply func() error, ready func(peerPubKey PublicKey) error) error
+}
+
+// NewSignalServer creates signal server listening on given TCP port, value of 0 disables HTTP signaling.
+// Signal host is optional, it serves signaling between the DHT peers.
+func NewSignalServer(port int, signalHost SignalHost) *SignalServer {
+	return &SignalServer{
+		port:       port,
+		signalHost: signalHost,
+		services:   make(map[string]signalHandlers),
+		ready:      make(map[PublicKey]chan struct{}),
+	}
+}
+
+// Start starts serving config exchange requests.
+func (s *SignalServer) Start() error {
+	if s.signalHost != nil {
+		if err := s.startDHT(); err != nil {
+			return err
+		}
+	}
+	if s.port == 0 {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp4", ":"+strconv.Itoa(s.port))
+	if err != nil {
+		return fmt.Errorf("could not listen signal port %d: %w", s.port, err)
+	}
+
+	server := &http.Server{
+		Handler:           http.HandlerFunc(s.handle),
+		ReadHeaderTimeout: signalHeaderTimeout,
+	}
+	s.mu.Lock()
+	s.server = server
+	s.mu.Unlock()
+
+	log.Info().Msgf("Serving p2p signaling on port %d", s.port)
+	go func() {
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			log.Warn().Err(err).Msg("Stopped serving p2p signaling")
+		}
+	}()
+	return nil
+}
+
+// Stop stops serving config exchange requests.
+func (s *SignalServer) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		s.server.Close()
+		s.server = nil
+	}
+	if s.dhtHost != nil {
+		s.dhtHost.RemoveStreamHandler(signalProtocolID)
+		s.dhtHost = nil
+	}
+}
+
+// servesHTTP tells whether config exchange is served over HTTP.
+func (s *SignalServer) servesHTTP() bool {
+	return s.port > 0
+}
+
+func (s *SignalServer) httpContact(publicIP string) market.Contact {
+	return market.Contact{
+		Type: ContactTypeHTTPV1,
+		Definition: HTTPContactDefinition{
+			Address: "http://" + net.JoinHostPort(publicIP, strconv.Itoa(s.port)),
+		},
+	}
+}
+
+// register starts passing config exchange messages of the provider service to the handlers.
+func (s *SignalServer) register(providerID identity.Identity, serviceType string, handlers signalHandlers) func() {
+	key := signalServiceKey(providerID.Address, serviceType)
+
+	s.mu.Lock()
+	s.services[key] = handlers
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.services, key)
+	}
+}
+
+func (s *SignalServer) service(providerAddress, serviceType string) (signalHandlers, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handlers, ok := s.services[signalServiceKey(providerAddress, serviceType)]
+	return handlers, ok
+}
+
+func (s *SignalServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, signalPathPrefix), "/")
+	if !strings.HasPrefix(r.URL.Path, signalPathPrefix) || len(parts) != 3 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	msg, err := ioutil.ReadAll(io.LimitReader(r.Body, signalMaxMsgSize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	reply, status := s.serve(r.Context(), parts[0], parts[1], parts[2], msg)
+	if status == signalStatusGoneAway {
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(reply)
+}
+
+// serve handles config exchange message of the provider service and returns the reply with HTTP status code.
+// Status code is signalStatusGoneAway when consumer has gone before the reply was ready.
+func (s *SignalServer) serve(ctx context.Context, providerAddress, serviceType, message string, msg []byte) ([]byte, int) {
+	handlers, ok := s.service(providerAddress, serviceType)
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+
+	switch message {
+	case signalMessageExchange:
+		reply, err := handlers.exchange(msg)
+		if err != nil {
+			log.Err(err).Msg("Could not handle initial exchange")
+			return nil, http.StatusBadRequest
+		}
+		return reply, http.StatusOK
+	case signalMessageAck:
+		// Consumer is replied only when provider is ready to be dialed, same as over the broker.
+		replied := make(chan struct{})
+		err := handlers.ack(msg, func() error {
+			close(replied)
+			return nil
+		}, s.markReady)
+		if err != nil {
+			log.Err(err).Msg("Could not handle exchange ack")
+			return nil, http.StatusBadRequest
+		}
+		select {
+		case <-replied:
+			return []byte("OK"), http.StatusOK
+		case <-ctx.Done():
+			return nil, signalStatusGoneAway
+		}
+	case signalMessageReady:
+		return s.waitReady(ctx, msg)
+	default:
+		return nil, http.StatusNotFound
+	}
+}
+
+// waitReady replies to the consumer, which sent the exchange ack, once its channel handlers are ready.
+// The message is not verified, the reply tells nothing but that the channel is ready.
+func (s *SignalServer) waitReady(ctx context.Context, msg []byte) ([]byte, int) {
+	peerPubKey, err := exchangePublicKey(msg)
+	if err != nil {
+		return nil, http.StatusBadRequest
+	}
+
+	ready := s.readyChan(peerPubKey)
+	defer s.deleteReady(peerPubKey, ready)
+
+	select {
+	case <-ready:
+		message, err := packChannelHandlersReady()
+		if err != nil {
+			return nil, http.StatusInternalServerError
+		}
+		return message, http.StatusOK
+	case <-time.After(signalReadyTimeout):
+		return nil, http.StatusGatewayTimeout
+	case <-ctx.Done():
+		return nil, signalStatusGoneAway
+	}
+}
+
+// markReady marks channel handlers of the peer as ready, the mark expires if peer does not ask for it.
+func (s *SignalServer) markReady(peerPubKey PublicKey) error {
+	ready := s.readyChan(peerPubKey)
+
+	s.mu.Lock()
+	select {
+	case <-ready:
+	default:
+		close(ready)
+	}
+	s.mu.Unlock()
+
+	time.AfterFunc(signalReadyTimeout, func() {
+		s.deleteReady(peerPubKey, ready)
+	})
+	return nil
+}
+
+func (s *SignalServer) readyChan(peerPubKey PublicKey) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ready, ok := s.ready[peerPubKey]
+	if !ok {
+		ready = make(chan struct{})
+		s.ready[peerPubKey] = ready
+	}
+	return ready
+}
+
+func (s *SignalServer) deleteReady(peerPubKey PublicKey, ready chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ready[peerPubKey] == ready {
+		delete(s.ready, peerPubKey)
+	}
+}
+
+func signalServiceKey(providerAddress, serviceType string) string {
+	return strings.ToLower(providerAddress) + "/" + serviceType
+}
+
+// httpSignaling sends config exchange messages directly to provider's signal server.
+type httpSignaling struct {
+	client  *http.Client
+	url     string
+	ready   chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	release func()
+}
+
+func newHTTPSignaling(contactDef HTTPContactDefinition, providerID identity.Identity, serviceType string, protect SocketProtector, release func()) *httpSignaling {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &httpSignaling{
+		client: &http.Client{
+			Transport: &http.Transport{DialContext: protectedDialer(protect).DialContext},
+		},
+		url:     strings.TrimSuffix(contactDef.Address, "/") + signalPathPrefix + providerID.Address + "/" + serviceType + "/",
+		ready:   make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		release: release,
+	}
+}
+
+func (s *httpSignaling) exchange(ctx context.Context, msg []byte) ([]byte, error) {
+	return s.post(ctx, signalMessageExchange, msg)
+}
+
+func (s *httpSignaling) ack(ctx context.Context, msg []byte) error {
+	if _, err := s.post(ctx, signalMessageAck, msg); err != nil {
+		return err
+	}
+
+	go s.waitReady(msg)
+	return nil
+}
+
+func (s *httpSignaling) handlersReady() <-chan struct{} {
+	return s.ready
+}
+
+func (s *httpSignaling) Close() {
+	s.cancel()
+	s.client.CloseIdleConnections()
+	s.release()
+}
+
+func (s *httpSignaling) waitReady(msg []byte) {
+	reply, err := s.post(s.ctx, signalMessageReady, msg)
+	if err != nil {
+		log.Err(err).Msg("Could not wait for channel handlers ready")
+		return
+	}
+	if err := unpackChannelHandlersReady(reply); err != nil {
+		log.Err(err).Msg("Channel handlers ready handler setup failed")
+		return
+	}
+	close(s.ready)
+}
+
+func (s *httpSignaling) post(ctx context.Context, message string, msg []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+message, bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not send signaling request %s: %w", message, err)
+	}
+	defer resp.Body.Close()
+
+	reply, err := ioutil.ReadAll(io.LimitReader(resp.Body, signalMaxMsgSize))
+	if err != nil {
+		return nil, fmt.Errorf("could not read signaling reply %s: %w", message, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signaling request %s failed with status %d", message, resp.StatusCode)
+	}
+	return reply, nil
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	signalProtocolID    = protocol.ID("/mysterium/p2p-signal/1.0.0")
+	signalMaxHeaderSize = 256
+	signalStreamTimeout = 2 * signalReadyTimeout
+)
+
+// SignalHost provides libp2p host of the DHT node, which carries p2p config exchange between DHT peers
+// without NATS broker. Host finds addresses of the peers, which are not known yet, in the DHT.
+type SignalHost interface {
+	Host() (host.Host, error)
+}
+
+// startDHT starts serving config exchange over the streams of the DHT node.
+func (s *SignalServer) startDHT() error {
+	dhtHost, err := s.signalHost.Host()
+	if err != nil {
+		return fmt.Errorf("could not serve p2p signaling over DHT: %w", err)
+	}
+	dhtHost.SetStreamHandler(signalProtocolID, s.handleStream)
+
+	s.mu.Lock()
+	s.dhtHost = dhtHost
+	s.mu.Unlock()
+
+	log.Info().Msgf("Serving p2p signaling over DHT node %s", dhtHost.ID())
+	return nil
+}
+
+func (s *SignalServer) dhtContact() (market.Contact, bool) {
+	s.mu.Lock()
+	dhtHost := s.dhtHost
+	s.mu.Unlock()
+
+	if dhtHost == nil {
+		return market.Contact{}, false
+	}
+
+	addresses := make([]string, 0)
+	for _, addr := range dhtHost.Addrs() {
+		addresses = append(addresses, addr.String())
+	}
+	return market.Contact{
+		Type: ContactTypeDHTV1,
+		Definition: DHTContactDefinition{
+			PeerID:    libp2ppeer.IDB58Encode(dhtHost.ID()),
+			Addresses: addresses,
+		},
+	}, true
+}
+
+// handleStream serves a single config exchange message. Consumer writes the path of the message
+// and its body, provider replies with HTTP status code and the reply body.
+func (s *SignalServer) handleStream(stream network.Stream) {
+	defer stream.Close()
+
+	stream.SetReadDeadline(time.Now().Add(signalHeaderTimeout))
+	path, msg, err := readSignalFrame(stream, signalMaxMsgSize)
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not read p2p signaling stream")
+		stream.Reset()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), signalStreamTimeout)
+	defer cancel()
+
+	status, reply := http.StatusNotFound, []byte(nil)
+	if parts := strings.Split(path, "/"); len(parts) == 3 {
+		reply, status = s.serve(ctx, parts[0], parts[1], parts[2], msg)
+	}
+	if status == signalStatusGoneAway {
+		stream.Reset()
+		return
+	}
+
+	stream.SetWriteDeadline(time.Now().Add(signalHeaderTimeout))
+	if err := writeSignalFrame(stream, strconv.Itoa(status), reply); err != nil {
+		log.Debug().Err(err).Msg("Could not reply to p2p signaling stream")
+		stream.Reset()
+	}
+}
+
+func writeSignalFrame(w io.Writer, header string, body []byte) error {
+	if _, err := io.WriteString(w, header+"\n"); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+func readSignalFrame(r io.Reader, maxBodySize int64) (string, []byte, error) {
+	reader := bufio.NewReader(io.LimitReader(r, signalMaxHeaderSize+maxBodySize))
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return "", nil, fmt.Errorf("could not read signaling header: %w", err)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not read signaling body: %w", err)
+	}
+	return strings.TrimSuffix(header, "\n"), body, nil
+}
+
+// dhtSignaling sends config exchange messages to provider over the streams of the DHT node.
+type dhtSignaling struct {
+	host   host.Host
+	peerID libp2ppeer.ID
+	path   string
+	ready  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newDHTSignaling(dhtHost host.Host, contactDef DHTContactDefinition, providerID identity.Identity, serviceType string) (*dhtSignaling, error) {
+	peerID, err := libp2ppeer.IDB58Decode(contactDef.PeerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signal peer %s: %w", contactDef.PeerID, err)
+	}
+	if peerID == dhtHost.ID() {
+		return nil, errors.New("signal peer is the node itself")
+	}
+
+	addrs := make([]multiaddr.Multiaddr, 0, len(contactDef.Addresses))
+	for _, address := range contactDef.Addresses {
+		addr, err := multiaddr.NewMultiaddr(address)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Skipping invalid address of signal peer %s", contactDef.PeerID)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	dhtHost.Peerstore().AddAddrs(peerID, addrs, peerstore.TempAddrTTL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &dhtSignaling{
+		host:   dhtHost,
+		peerID: peerID,
+		path:   providerID.Address + "/" + serviceType + "/",
+		ready:  make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+func (s *dhtSignaling) exchange(ctx context.Context, msg []byte) ([]byte, error) {
+	return s.send(ctx, signalMessageExchange, msg)
+}
+
+func (s *dhtSignaling) ack(ctx context.Context, msg []byte) error {
+	if _, err := s.send(ctx, signalMessageAck, msg); err != nil {
+		return err
+	}
+
+	go s.waitReady(msg)
+	return nil
+}
+
+func (s *dhtSignaling) handlersReady() <-chan struct{} {
+	return s.ready
+}
+
+func (s *dhtSignaling) Close() {
+	s.cancel()
+}
+
+func (s *dhtSignaling) waitReady(msg []byte) {
+	reply, err := s.send(s.ctx, signalMessageReady, msg)
+	if err != nil {
+		log.Err(err).Msg("Could not wait for channel handlers ready")
+		return
+	}
+	if err := unpackChannelHandlersReady(reply); err != nil {
+		log.Err(err).Msg("Channel handlers ready handler setup failed")
+		return
+	}
+	close(s.ready)
+}
+
+func (s *dhtSignaling) send(ctx context.Context, message string, msg []byte) ([]byte, error) {
+	stream, err := s.host.NewStream(ctx, s.peerID, signalProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("could not open signaling stream %s: %w", message, err)
+	}
+	defer stream.Close()
+
+	// Stream is reset, so pending reads return as soon as the request is cancelled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.Reset()
+		case <-done:
+		}
+	}()
+
+	if err := writeSignalFrame(stream, s.path+message, msg); err != nil {
+		stream.Reset()
+		return nil, fmt.Errorf("could not send signaling request %s: %w", message, err)
+	}
+	// Closing stream for writing tells provider that the whole message was sent.
+	if err := stream.Close(); err != nil {
+		stream.Reset()
+		return nil, fmt.Errorf("could not send signaling request %s: %w", message, err)
+	}
+
+	header, reply, err := readSignalFrame(stream, signalMaxMsgSize)
+	if err != nil {
+		return nil, fmt.Errorf("could not read signaling reply %s: %w", message, err)
+	}
+	if status, err := strconv.Atoi(header); err != nil || status != http.StatusOK {
+		return nil, fmt.Errorf("signaling request %s failed with status %s", message, header)
+	}
+	return reply, nil
+}
@@ -0,0 +1,525 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// transportStream is a transport of p2p connect config, which tunnels UDP traffic over TCP or TLS streams.
+	transportStream = "stream"
+
+	streamHeaderSize      = keySize + 1
+	streamNonceSize       = 32
+	streamHeaderTimeout   = 10 * time.Second
+	streamAcceptTimeout   = 30 * time.Second
+	streamFrameHeaderSize = 2
+)
+
+// StreamServer accepts TCP or TLS streams, which tunnel p2p channel and service traffic
+// of the consumers whose networks block UDP.
+type StreamServer struct {
+	port   int
+	useTLS bool
+
+	listener net.Listener
+	mu       sync.Mutex
+	expected map[PublicKey]*expectedStreams
+}
+
+type expectedStreams struct {
+	sharedKey [keySize]byte
+	conns     [requiredConnCount]net.Conn
+	count     int
+	ready     chan struct{}
+}
+
+// NewStreamServer creates stream server listening on given TCP port, streams are wrapped into TLS if useTLS is set.
+func NewStreamServer(port int, useTLS bool) *StreamServer {
+	return &StreamServer{
+		port:     port,
+		useTLS:   useTLS,
+		expected: make(map[PublicKey]*expectedStreams),
+	}
+}
+
+// Start starts accepting streams.
+func (s *StreamServer) Start() error {
+	listener, err := net.Listen("tcp4", ":"+strconv.Itoa(s.port))
+	if err != nil {
+		return fmt.Errorf("could not listen stream port %d: %w", s.port, err)
+	}
+
+	if s.useTLS {
+		// Certificate is not verified by consumers, peers are authenticated by p2p keys.
+		// TLS only makes the streams look like any other TLS traffic.
+		cert, err := selfSignedCertificate()
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("could not create stream TLS certificate: %w", err)
+		}
+		listener = tls.NewListener(listener, &tls.Config{Certificates: []tls.Certificate{cert}})
+	}
+
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	log.Info().Msgf("Accepting p2p streams on port %d (TLS: %v)", s.port, s.useTLS)
+	go s.serve(listener)
+	return nil
+}
+
+// Stop stops accepting streams.
+func (s *StreamServer) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+	}
+}
+
+func (s *StreamServer) contact(publicIP string) market.Contact {
+	return market.Contact{
+		Type: ContactTypeStreamV1,
+		Definition: StreamContactDefinition{
+			Address: net.JoinHostPort(publicIP, strconv.Itoa(s.port)),
+			TLS:     s.useTLS,
+		},
+	}
+}
+
+func (s *StreamServer) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Debug().Err(err).Msg("Stopped accepting p2p streams")
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle reads stream header, authenticates the stream and passes it to the expecting peer.
+// Peer public key in the header is not a secret, so the stream is bound only after the peer proves
+// it knows the key shared by the channel, by answering the random nonce of the server.
+func (s *StreamServer) handle(conn net.Conn) {
+	header := make([]byte, streamHeaderSize)
+	conn.SetDeadline(time.Now().Add(streamHeaderTimeout))
+	if _, err := io.ReadFull(conn, header); err != nil {
+		log.Warn().Err(err).Msgf("Could not read p2p stream header from %s", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
+	var peerPubKey PublicKey
+	copy(peerPubKey[:], header[:keySize])
+	index := int(header[keySize])
+
+	expected, ok := s.expectedSlot(peerPubKey, index)
+	if !ok {
+		log.Warn().Msgf("Unexpected p2p stream from %s", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
+	if err := authenticateStream(conn, expected.sharedKey, index); err != nil {
+		log.Warn().Err(err).Msgf("Could not authenticate p2p stream from %s", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.expected[peerPubKey] != expected || expected.conns[index] != nil {
+		log.Warn().Msgf("Unexpected p2p stream from %s", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
+	expected.conns[index] = conn
+	expected.count++
+	if expected.count == requiredConnCount {
+		close(expected.ready)
+	}
+}
+
+// expectedSlot returns the streams expected from the peer, if the stream of given index is not opened yet.
+func (s *StreamServer) expectedSlot(peerPubKey PublicKey, index int) (*expectedStreams, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expected, ok := s.expected[peerPubKey]
+	if !ok || index >= requiredConnCount || expected.conns[index] != nil {
+		return nil, false
+	}
+	return expected, true
+}
+
+// expect marks that peer is going to open its streams, authenticated by the key shared with it.
+func (s *StreamServer) expect(privateKey PrivateKey, peerPubKey PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expected[peerPubKey] = &expectedStreams{
+		sharedKey: sharedKey(privateKey, peerPubKey),
+		ready:     make(chan struct{}),
+	}
+}
+
+// accept waits until expected peer opens all of its streams.
+func (s *StreamServer) accept(ctx context.Context, peerPubKey PublicKey) ([]net.Conn, error) {
+	s.mu.Lock()
+	expected, ok := s.expected[peerPubKey]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("streams are not expected from peer %s", peerPubKey.Hex())
+	}
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.expected, peerPubKey)
+		s.mu.Unlock()
+	}()
+
+	select {
+	case <-expected.ready:
+		return expected.conns[:], nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for _, conn := range expected.conns {
+			if conn != nil {
+				conn.Close()
+			}
+		}
+		s.mu.Unlock()
+		return nil, fmt.Errorf("peer streams were not opened: %w", ctx.Err())
+	}
+}
+
+// dialStreams opens streams to provider's stream server, introduces them by the peer public key
+// and proves the knowledge of the key shared with the provider.
+func dialStreams(ctx context.Context, contact StreamContactDefinition, publicKey PublicKey, privateKey PrivateKey, peerPubKey PublicKey, protect SocketProtector) ([]net.Conn, error) {
+	var conns []net.Conn
+	closeAll := func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+
+	key := sharedKey(privateKey, peerPubKey)
+	for i := 0; i < requiredConnCount; i++ {
+		conn, err := dialStream(ctx, contact, protect)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		conns = append(conns, conn)
+
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		header := append(publicKey[:], byte(i))
+		if _, err := conn.Write(header); err != nil {
+			closeAll()
+			return nil, fmt.Errorf("could not write stream header: %w", err)
+		}
+		if err := answerStreamChallenge(conn, key, i); err != nil {
+			closeAll()
+			return nil, err
+		}
+		conn.SetDeadline(time.Time{})
+	}
+	return conns, nil
+}
+
+// authenticateStream sends random nonce to the peer and checks that it is answered with the shared key.
+func authenticateStream(conn net.Conn, key [keySize]byte, index int) error {
+	nonce := make([]byte, streamNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("could not generate stream nonce: %w", err)
+	}
+	if _, err := conn.Write(nonce); err != nil {
+		return fmt.Errorf("could not write stream nonce: %w", err)
+	}
+
+	answer := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return fmt.Errorf("could not read stream nonce answer: %w", err)
+	}
+	if !hmac.Equal(answer, streamMAC(key, nonce, index)) {
+		return errors.New("invalid stream nonce answer")
+	}
+	return nil
+}
+
+// answerStreamChallenge reads the nonce of the server and answers it with the shared key.
+func answerStreamChallenge(conn net.Conn, key [keySize]byte, index int) error {
+	nonce := make([]byte, streamNonceSize)
+	if _, err := io.ReadFull(conn, nonce); err != nil {
+		return fmt.Errorf("could not read stream nonce: %w", err)
+	}
+	if _, err := conn.Write(streamMAC(key, nonce, index)); err != nil {
+		return fmt.Errorf("could not write stream nonce answer: %w", err)
+	}
+	return nil
+}
+
+// streamMAC authenticates the nonce of the stream with given index.
+func streamMAC(key [keySize]byte, nonce []byte, index int) []byte {
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write([]byte("myst p2p stream"))
+	mac.Write(nonce)
+	mac.Write([]byte{byte(index)})
+	return mac.Sum(nil)
+}
+
+// protectedDialer creates dialer, which protects sockets with the given hook if it is set.
+func protectedDialer(protect SocketProtector) *net.Dialer {
+	var dialer net.Dialer
+	if protect != nil {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			var protectErr error
+			if err := c.Control(func(fd uintptr) {
+				protectErr = protect(int(fd))
+			}); err != nil {
+				return err
+			}
+			if protectErr != nil {
+				return fmt.Errorf("could not protect socket: %w", protectErr)
+			}
+			return nil
+		}
+	}
+	return &dialer
+}
+
+func dialStream(ctx context.Context, contact StreamContactDefinition, protect SocketProtector) (net.Conn, error) {
+	dialer := protectedDialer(protect)
+	conn, err := dialer.DialContext(ctx, "tcp4", contact.Address)
+	if err != nil {
+		return nil, fmt.Errorf("could not dial stream %s: %w", contact.Address, err)
+	}
+	if !contact.TLS {
+		return conn, nil
+	}
+
+	host, _, err := net.SplitHostPort(contact.Address)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// Provider uses self signed certificate, peers are authenticated by p2p keys.
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if deadline, ok := ctx.Deadline(); ok {
+		tlsConn.SetDeadline(deadline)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		tlsConn.Close()
+		return nil, fmt.Errorf("could not establish TLS with %s: %w", contact.Address, err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// bridgeStreams bridges streams to UDP conns of the local ports, closes all the streams on failure.
+func bridgeStreams(streams []net.Conn, localPorts []int) (*net.UDPConn, *net.UDPConn, []func(), error) {
+	var conns []*net.UDPConn
+	var release []func()
+	for i, stream := range streams {
+		conn, closeBridge, err := bridgeStream(stream, localPorts[i])
+		if err != nil {
+			for _, closeBridge := range release {
+				closeBridge()
+			}
+			for _, stream := range streams[i:] {
+				stream.Close()
+			}
+			return nil, nil, nil, err
+		}
+		conns = append(conns, conn)
+		release = append(release, closeBridge)
+	}
+	return conns[0], conns[1], release, nil
+}
+
+// bridgeStream relays datagrams between stream and UDP conn bound to given local port.
+// Returned conn looks the same as the ones created by NAT hole punching,
+// so channel and services can use it without knowing about the stream.
+func bridgeStream(stream net.Conn, localPort int) (*net.UDPConn, func(), error) {
+	relay, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create stream relay conn: %w", err)
+	}
+	conn, err := net.DialUDP("udp4", &net.UDPAddr{Port: localPort}, relay.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		relay.Close()
+		return nil, nil, fmt.Errorf("could not create UDP conn for stream: %w", err)
+	}
+
+	b := &streamBridge{
+		stream:    stream,
+		relay:     relay,
+		localAddr: &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: localPort},
+	}
+	go b.streamToRelay()
+	go b.relayToStream()
+	return conn, b.close, nil
+}
+
+type streamBridge struct {
+	stream net.Conn
+	relay  *net.UDPConn
+
+	mu        sync.Mutex
+	localAddr *net.UDPAddr
+	closeOnce sync.Once
+}
+
+func (b *streamBridge) streamToRelay() {
+	defer b.close()
+
+	reader := bufio.NewReader(b.stream)
+	buf := make([]byte, 1<<16)
+	for {
+		datagram, err := readFrame(reader, buf)
+		if err != nil {
+			log.Debug().Err(err).Msg("Stopped reading p2p stream")
+			return
+		}
+		if _, err := b.relay.WriteToUDP(datagram, b.peer()); err != nil {
+			log.Debug().Err(err).Msg("Stopped writing p2p stream relay")
+			return
+		}
+	}
+}
+
+func (b *streamBridge) relayToStream() {
+	defer b.close()
+
+	buf := make([]byte, 1<<16)
+	for {
+		n, addr, err := b.relay.ReadFromUDP(buf)
+		if err != nil {
+			log.Debug().Err(err).Msg("Stopped reading p2p stream relay")
+			return
+		}
+		b.setPeer(addr)
+		if err := writeFrame(b.stream, buf[:n]); err != nil {
+			log.Debug().Err(err).Msg("Stopped writing p2p stream")
+			return
+		}
+	}
+}
+
+// peer returns address of local UDP conn, it changes when conn is reopened by channel or service.
+func (b *streamBridge) peer() *net.UDPAddr {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.localAddr
+}
+
+func (b *streamBridge) setPeer(addr *net.UDPAddr) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.localAddr = addr
+}
+
+func (b *streamBridge) close() {
+	b.closeOnce.Do(func() {
+		b.stream.Close()
+		b.relay.Close()
+	})
+}
+
+func writeFrame(w io.Writer, data []byte) error {
+	frame := make([]byte, streamFrameHeaderSize+len(data))
+	binary.BigEndian.PutUint16(frame, uint16(len(data)))
+	copy(frame[streamFrameHeaderSize:], data)
+	_, err := w.Write(frame)
+	return err
+}
+
+func readFrame(r io.Reader, buf []byte) ([]byte, error) {
+	header := buf[:streamFrameHeaderSize]
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	size := int(binary.BigEndian.Uint16(header))
+	if size > len(buf) {
+		return nil, errors.New("stream frame is too large")
+	}
+	if _, err := io.ReadFull(r, buf[:size]); err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
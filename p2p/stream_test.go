@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamServer_AcceptsAuthenticatedStreams(t *testing.T) {
+	ports, err := acquirePorts(1)
+	assert.NoError(t, err)
+	streamServer := NewStreamServer(ports[0], false)
+	assert.NoError(t, streamServer.Start())
+	defer streamServer.Stop()
+
+	providerPubKey, providerPrivKey, err := GenerateKey()
+	assert.NoError(t, err)
+	consumerPubKey, consumerPrivKey, err := GenerateKey()
+	assert.NoError(t, err)
+	streamServer.expect(providerPrivKey, consumerPubKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	contact := StreamContactDefinition{Address: fmt.Sprintf("127.0.0.1:%d", ports[0])}
+	streams, err := dialStreams(ctx, contact, consumerPubKey, consumerPrivKey, providerPubKey, nil)
+	assert.NoError(t, err)
+	defer func() {
+		for _, stream := range streams {
+			stream.Close()
+		}
+	}()
+
+	accepted, err := streamServer.accept(ctx, consumerPubKey)
+	assert.NoError(t, err)
+	assert.Len(t, accepted, requiredConnCount)
+}
+
+func TestStreamServer_RejectsStreamsWithoutSharedKey(t *testing.T) {
+	ports, err := acquirePorts(1)
+	assert.NoError(t, err)
+	streamServer := NewStreamServer(ports[0], false)
+	assert.NoError(t, streamServer.Start())
+	defer streamServer.Stop()
+
+	providerPubKey, providerPrivKey, err := GenerateKey()
+	assert.NoError(t, err)
+	consumerPubKey, _, err := GenerateKey()
+	assert.NoError(t, err)
+	_, attackerPrivKey, err := GenerateKey()
+	assert.NoError(t, err)
+	streamServer.expect(providerPrivKey, consumerPubKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	contact := StreamContactDefinition{Address: fmt.Sprintf("127.0.0.1:%d", ports[0])}
+	// Attacker knows the consumer public key, but not the key shared with provider.
+	streams, _ := dialStreams(ctx, contact, consumerPubKey, attackerPrivKey, providerPubKey, nil)
+	for _, stream := range streams {
+		stream.Close()
+	}
+
+	_, err = streamServer.accept(ctx, consumerPubKey)
+	assert.Error(t, err)
+}
@@ -181,6 +181,15 @@ type VPNConfig struct {
 	RemoteProtocol  string `json:"protocol"`
 	TLSPresharedKey string `json:"TLSPresharedKey"`
 	CACertificate   string `json:"CACertificate"`
+
+	// FallbackRemotes are other listeners of the same server tried in order when connection via the primary one fails
+	FallbackRemotes []VPNRemote `json:"fallback_remotes,omitempty"`
+}
+
+// VPNRemote describes additional server listener reachable on the same remote IP
+type VPNRemote struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
 }
 
 func newAuthMiddleware(sessionID session.ID, signer identity.Signer) management.Middleware {
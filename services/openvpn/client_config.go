@@ -55,6 +55,28 @@ func (c *ClientConfig) SetProtocol(protocol string) {
 	}
 }
 
+// AddRemote adds fallback server listener which is tried when connection via the previous ones fails
+func (c *ClientConfig) AddRemote(serverIP string, serverPort int, protocol string) {
+	if protocol == "tcp" {
+		protocol = "tcp-client"
+	}
+	c.SetParam("remote", serverIP, strconv.Itoa(serverPort), protocol)
+}
+
+// SetRoutes routes traffic through the tunnel: only included subnets when given (all traffic otherwise),
+// excluded subnets are routed around the tunnel
+func (c *ClientConfig) SetRoutes(routes connection.Routes) {
+	if len(routes.Include) == 0 {
+		c.SetParam("redirect-gateway", "def1", "bypass-dhcp")
+	}
+	for _, subnet := range routes.Include {
+		c.SetParam("route", subnet.IP.String(), net.IP(subnet.Mask).String())
+	}
+	for _, subnet := range routes.Exclude {
+		c.SetParam("route", subnet.IP.String(), net.IP(subnet.Mask).String(), "net_gateway")
+	}
+}
+
 func defaultClientConfig(runtimeDir string, scriptSearchPath string) *ClientConfig {
 	clientConfig := ClientConfig{GenericConfig: config.NewConfig(runtimeDir, scriptSearchPath), VpnConfig: nil}
 
@@ -70,7 +92,6 @@ func defaultClientConfig(runtimeDir string, scriptSearchPath string) *ClientConf
 
 	clientConfig.SetParam("reneg-sec", "0")
 	clientConfig.SetParam("resolv-retry", "infinite")
-	clientConfig.SetParam("redirect-gateway", "def1", "bypass-dhcp")
 
 	return &clientConfig
 }
@@ -90,29 +111,51 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, scriptDir string, runtimeDi
 		return nil, err
 	}
 
-	clientFileConfig := newClientConfig(runtimeDir, scriptDir)
-	dnsIPs, err := options.Params.DNS.ResolveIPs(vpnConfig.DNSIPs)
-	if err != nil {
-		return nil, err
-	}
-	for _, ip := range dnsIPs {
-		clientFileConfig.SetParam("dhcp-option", "DNS", ip)
-	}
-
+	remoteIP := vpnConfig.RemoteIP
+	routes := options.Routes
 	var remotePort, localPort int
-	if options.ProviderNATConn != nil && vpnConfig.RemoteIP != "127.0.0.1" {
+	useNATConn := options.ProviderNATConn != nil && vpnConfig.RemoteIP != "127.0.0.1"
+	if useNATConn && vpnConfig.RemoteProtocol == "tcp" {
+		// Punched UDP connection is useless for TCP listener, it is connected directly.
+		options.ProviderNATConn.Close()
+		useNATConn = false
+	}
+	if useNATConn {
 		options.ProviderNATConn.Close()
 		remotePort = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
 		localPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
+
+		// Stream transport relays packets via the local socket, so the stream to the provider itself must bypass the tunnel.
+		if streamIP := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).IP; streamIP.IsLoopback() {
+			routes.Exclude = append(routes.Exclude, net.IPNet{IP: net.ParseIP(vpnConfig.RemoteIP), Mask: net.CIDRMask(32, 32)})
+			remoteIP = streamIP.String()
+		}
 	} else {
 		remotePort = vpnConfig.RemotePort
 		localPort = vpnConfig.LocalPort
 	}
 
+	clientFileConfig := newClientConfig(runtimeDir, scriptDir)
+	clientFileConfig.SetRoutes(routes)
+	dnsIPs, err := options.Params.DNS.ResolveIPs(vpnConfig.DNSIPs)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range dnsIPs {
+		clientFileConfig.SetParam("dhcp-option", "DNS", ip)
+	}
+
 	clientFileConfig.VpnConfig = &vpnConfig
 	clientFileConfig.SetReconnectRetry(2)
-	clientFileConfig.SetClientMode(vpnConfig.RemoteIP, remotePort, localPort)
+	clientFileConfig.SetClientMode(remoteIP, remotePort, localPort)
 	clientFileConfig.SetProtocol(vpnConfig.RemoteProtocol)
+	for _, remote := range vpnConfig.FallbackRemotes {
+		clientFileConfig.AddRemote(vpnConfig.RemoteIP, remote.Port, remote.Protocol)
+	}
+	if len(vpnConfig.FallbackRemotes) > 0 {
+		// Don't wait for the primary listener too long, UDP one never refuses the connection.
+		clientFileConfig.SetParam("server-poll-timeout", "10")
+	}
 	clientFileConfig.SetTLSCACertificate(vpnConfig.CACertificate)
 	clientFileConfig.SetTLSCrypt(vpnConfig.TLSPresharedKey)
 
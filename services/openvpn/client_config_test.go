@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package openvpn
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/config"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientConfig_SetRoutes(t *testing.T) {
+	_, included, _ := net.ParseCIDR("10.0.0.0/8")
+	_, excluded, _ := net.ParseCIDR("1.2.3.4/32")
+
+	tests := []struct {
+		name     string
+		routes   connection.Routes
+		expected []string
+	}{
+		{
+			name:     "Redirects all traffic by default",
+			routes:   connection.Routes{},
+			expected: []string{"--redirect-gateway", "def1", "bypass-dhcp"},
+		},
+		{
+			name:   "Routes excluded subnets around the tunnel",
+			routes: connection.Routes{Exclude: []net.IPNet{*excluded}},
+			expected: []string{
+				"--redirect-gateway", "def1", "bypass-dhcp",
+				"--route", "1.2.3.4", "255.255.255.255", "net_gateway",
+			},
+		},
+		{
+			name:   "Routes only included subnets through the tunnel",
+			routes: connection.Routes{Include: []net.IPNet{*included}, Exclude: []net.IPNet{*excluded}},
+			expected: []string{
+				"--route", "10.0.0.0", "255.0.0.0",
+				"--route", "1.2.3.4", "255.255.255.255", "net_gateway",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientConfig := &ClientConfig{GenericConfig: config.NewConfig("", "")}
+			clientConfig.SetRoutes(test.routes)
+
+			args, err := clientConfig.ToArguments()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, args)
+		})
+	}
+}
+
+func TestClientConfig_AddRemote(t *testing.T) {
+	clientConfig := &ClientConfig{GenericConfig: config.NewConfig("", "")}
+	clientConfig.AddRemote("1.2.3.4", 10999, "tcp")
+	clientConfig.AddRemote("1.2.3.4", 11000, "udp")
+
+	args, err := clientConfig.ToArguments()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"--remote", "1.2.3.4", "10999", "tcp-client",
+		"--remote", "1.2.3.4", "11000", "udp",
+	}, args)
+}
@@ -43,6 +43,7 @@ func NewDefaultValidator() *ConfigValidator {
 		validators: []ValidateConfig{
 			validProtocol,
 			validPort,
+			validFallbackRemotes,
 			validIPFormat,
 			validTLSPresharedKey,
 			validCACertificate,
@@ -77,6 +78,18 @@ func validPort(config VPNConfig) error {
 	return nil
 }
 
+func validFallbackRemotes(config VPNConfig) error {
+	for _, remote := range config.FallbackRemotes {
+		if err := validProtocol(VPNConfig{RemoteProtocol: remote.Protocol}); err != nil {
+			return err
+		}
+		if err := validPort(VPNConfig{RemotePort: remote.Port}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validIPFormat(config VPNConfig) error {
 	parsed := net.ParseIP(config.RemoteIP)
 	if parsed == nil {
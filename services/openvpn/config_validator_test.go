@@ -88,6 +88,12 @@ func TestPortOutOfRangeIsNotAllowed(t *testing.T) {
 	assert.Error(t, validPort(vpnConfig))
 }
 
+func TestFallbackRemotesAreValidated(t *testing.T) {
+	assert.NoError(t, validFallbackRemotes(VPNConfig{FallbackRemotes: []VPNRemote{{Port: 10999, Protocol: "tcp"}}}))
+	assert.Error(t, validFallbackRemotes(VPNConfig{FallbackRemotes: []VPNRemote{{Port: 10999, Protocol: "fake_protocol"}}}))
+	assert.Error(t, validFallbackRemotes(VPNConfig{FallbackRemotes: []VPNRemote{{Port: -1, Protocol: "tcp"}}}))
+}
+
 func TestTLSPresharedKeyIsValid(t *testing.T) {
 	vpnConfig := VPNConfig{TLSPresharedKey: tlsTestKey}
 	assert.NoError(t, validTLSPresharedKey(vpnConfig))
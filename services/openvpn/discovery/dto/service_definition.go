@@ -31,8 +31,11 @@ type ServiceDefinition struct {
 	// Available per session bandwidth
 	SessionBandwidth Bandwidth `json:"session_bandwidth,omitempty"`
 
-	// Transport protocol used by service
+	// Primary transport protocol used by service
 	Protocol string `json:"protocol,omitempty"`
+
+	// All transport protocols service listens on, in the order consumers should try them
+	Protocols []string `json:"protocols,omitempty"`
 }
 
 // GetLocation returns geographic location of service definition provider
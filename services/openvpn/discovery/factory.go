@@ -28,7 +28,7 @@ import (
 // NewServiceProposalWithLocation creates service proposal description for openvpn service
 func NewServiceProposalWithLocation(
 	loc locationstate.Location,
-	protocol string,
+	protocols []string,
 ) market.ServiceProposal {
 	serviceLocation := market.Location{
 		Continent: loc.Continent,
@@ -39,13 +39,18 @@ func NewServiceProposalWithLocation(
 		NodeType:  loc.NodeType,
 	}
 
+	definition := dto.ServiceDefinition{
+		Location:          serviceLocation,
+		LocationOriginate: serviceLocation,
+		SessionBandwidth:  dto.Bandwidth(10 * datasize.MiB),
+	}
+	if len(protocols) > 0 {
+		definition.Protocol = protocols[0]
+		definition.Protocols = protocols
+	}
+
 	return market.ServiceProposal{
-		ServiceType: openvpn.ServiceType,
-		ServiceDefinition: dto.ServiceDefinition{
-			Location:          serviceLocation,
-			LocationOriginate: serviceLocation,
-			SessionBandwidth:  dto.Bandwidth(10 * datasize.MiB),
-			Protocol:          protocol,
-		},
+		ServiceType:       openvpn.ServiceType,
+		ServiceDefinition: definition,
 	}
 }
@@ -35,11 +35,11 @@ var (
 		ISP:       "Telia Lietuva, AB",
 		NodeType:  "residential",
 	}
-	protocol = "tcp"
+	protocols = []string{"tcp", "udp"}
 )
 
 func Test_NewServiceProposalWithLocation(t *testing.T) {
-	proposal := NewServiceProposalWithLocation(locationLTTelia, protocol)
+	proposal := NewServiceProposalWithLocation(locationLTTelia, protocols)
 
 	assert.Exactly(
 		t,
@@ -50,6 +50,7 @@ func Test_NewServiceProposalWithLocation(t *testing.T) {
 				LocationOriginate: proposal.ServiceDefinition.GetLocation(),
 				SessionBandwidth:  83886080,
 				Protocol:          "tcp",
+				Protocols:         []string{"tcp", "udp"},
 			},
 		},
 		proposal,
@@ -20,12 +20,17 @@ package service
 import (
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server"
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/server/credentials"
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/rs/zerolog/log"
 )
 
+// AuthCheck is an additional check of client credentials, e.g. invite tokens or per consumer limits.
+// Client is admitted only when all checks pass, returned error explains the rejection.
+type AuthCheck func(session *service.Session, username, password string) error
+
 // authHandler authorizes incoming clients by registering a callback to check auth primitives.
 // username - provider's sessionId
 // password - consumer's identity signature
@@ -34,30 +39,30 @@ type authHandler struct {
 
 	clientMap         *clientMap
 	identityExtractor identity.Extractor
+	checks            []AuthCheck
 }
 
 // newAuthHandler return authHandler instance
-func newAuthHandler(clientMap *clientMap, extractor identity.Extractor) *authHandler {
+func newAuthHandler(clientMap *clientMap, extractor identity.Extractor, checks ...AuthCheck) *authHandler {
 	ah := new(authHandler)
 	ah.Middleware = credentials.NewMiddleware(ah.validate)
 	ah.Middleware.ClientsSubscribe(ah.handleClientEvent)
 	ah.clientMap = clientMap
 	ah.identityExtractor = extractor
+	ah.checks = checks
 	return ah
 }
 
 func (ah *authHandler) handleClientEvent(event server.ClientEvent) {
-	switch event.EventType {
-	case server.Connect:
-		ah.clientMap.Add(event.ClientID, session.ID(event.Env["username"]))
-	case server.Disconnect:
+	if event.EventType == server.Disconnect {
 		ah.clientMap.Remove(event.ClientID)
 	}
 }
 
 // handleAuthorisation provides glue code for openvpn management interface to validate incoming client login request,
-// it expects session id as username, and session signature signed by client as password
-func (ah *authHandler) validate(_ int, username, password string) (bool, error) {
+// it expects session id as username, and session signature signed by client as password.
+// Authorized client is admitted to the client map.
+func (ah *authHandler) validate(clientID int, username, password string) (bool, error) {
 	sessionID := session.ID(username)
 	currentSession, currentSessionFound := ah.clientMap.GetSession(sessionID)
 	if !currentSessionFound {
@@ -76,5 +81,13 @@ func (ah *authHandler) validate(_ int, username, password string) (bool, error)
 		return false, nil
 	}
 
+	for _, check := range ah.checks {
+		if err := check(currentSession, username, password); err != nil {
+			log.Warn().Err(err).Msgf("Client of session %s rejected", sessionID)
+			return false, nil
+		}
+	}
+
+	ah.clientMap.Add(clientID, sessionID)
 	return true, nil
 }
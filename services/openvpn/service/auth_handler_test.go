@@ -18,6 +18,7 @@
 package service
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/management"
@@ -85,6 +86,66 @@ func TestValidateReturnsTrueWhenSessionExistsAndSignatureIsValidAndClientIDMatch
 	assert.True(t, authenticated)
 }
 
+func TestValidateRunsAuthChecksOfValidClient(t *testing.T) {
+	var checkedSession *service.Session
+	var checkedPassword string
+	allow := func(session *service.Session, _, password string) error {
+		checkedSession, checkedPassword = session, password
+		return nil
+	}
+	validator := createAuthHandlerWithSession(identityExisting, sessionExisting, allow).validate
+
+	authenticated, err := validator(1, sessionExistingString, "signature")
+
+	assert.NoError(t, err)
+	assert.True(t, authenticated)
+	assert.Equal(t, sessionExisting, checkedSession)
+	assert.Equal(t, "signature", checkedPassword)
+}
+
+func TestValidateReturnsFalseWhenAuthCheckFails(t *testing.T) {
+	allow := func(*service.Session, string, string) error { return nil }
+	deny := func(*service.Session, string, string) error { return errors.New("invite token expired") }
+	validator := createAuthHandlerWithSession(identityExisting, sessionExisting, allow, deny).validate
+
+	authenticated, err := validator(1, sessionExistingString, "not important")
+
+	assert.NoError(t, err)
+	assert.False(t, authenticated)
+}
+
+func TestValidateSkipsAuthChecksOfInvalidClient(t *testing.T) {
+	checked := false
+	check := func(*service.Session, string, string) error {
+		checked = true
+		return nil
+	}
+	validator := createAuthHandlerWithSession(identity.FromAddress("wrongsignature"), sessionExisting, check).validate
+
+	authenticated, err := validator(1, sessionExistingString, "not important")
+
+	assert.NoError(t, err)
+	assert.False(t, authenticated)
+	assert.False(t, checked)
+}
+
+func TestClientIsNotAdmittedWhenAuthCheckFails(t *testing.T) {
+	deny := func(*service.Session, string, string) error { return errors.New("consumer limit reached") }
+	mockMangement := &management.MockConnection{CommandResult: "SUCCESS"}
+	middleware := createAuthHandlerWithSession(identityExisting, sessionExisting, deny)
+	middleware.Start(mockMangement)
+
+	feedLinesToMiddleware(middleware, []string{
+		">CLIENT:CONNECT,1,4",
+		">CLIENT:ENV,username=" + sessionExistingString,
+		">CLIENT:ENV,password=passwd1",
+		">CLIENT:ENV,END",
+	})
+
+	assert.Contains(t, mockMangement.LastLine, "client-deny 1 4")
+	assert.Empty(t, middleware.clientMap.GetSessionClients(sessionExisting.ID))
+}
+
 func TestSecondClientIsNotDisconnectedWhenFirstClientDisconnects(t *testing.T) {
 	var firstClientConnected = []string{
 		">CLIENT:CONNECT,1,4",
@@ -32,7 +32,7 @@ func createAuthHandler(identityToExtract identity.Identity) *authHandler {
 	return newAuthHandler(NewClientMap(mockSessions), mockExtractor)
 }
 
-func createAuthHandlerWithSession(identityToExtract identity.Identity, sessionInstance *service.Session) *authHandler {
+func createAuthHandlerWithSession(identityToExtract identity.Identity, sessionInstance *service.Session, checks ...AuthCheck) *authHandler {
 	mockExtractor := &mockIdentityExtractor{
 		identityToExtract,
 		nil,
@@ -41,7 +41,7 @@ func createAuthHandlerWithSession(identityToExtract identity.Identity, sessionIn
 		sessionInstance,
 		true,
 	}
-	return newAuthHandler(NewClientMap(mockSessions), mockExtractor)
+	return newAuthHandler(NewClientMap(mockSessions), mockExtractor, checks...)
 }
 
 // mockIdentityExtractor mocked identity extractor
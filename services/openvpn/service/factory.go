@@ -24,13 +24,14 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/rs/zerolog/log"
 )
 
-// NewManager creates new instance of Openvpn service
+// NewManager creates new instance of Openvpn service, clients are admitted only when all given auth checks pass
 func NewManager(nodeOptions node.Options,
 	serviceOptions Options,
 	country string,
@@ -41,6 +42,8 @@ func NewManager(nodeOptions node.Options,
 	portPool port.ServicePortSupplier,
 	bus eventbus.EventBus,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	shaperLimits *shaper.Limits,
+	authChecks ...AuthCheck,
 ) *Manager {
 	return &Manager{
 		nodeOptions:     nodeOptions,
@@ -50,10 +53,11 @@ func NewManager(nodeOptions node.Options,
 		ports:           portPool,
 		bus:             bus,
 		trafficFirewall: trafficFirewall,
+		shaperLimits:    shaperLimits,
 		country:         country,
 		ipResolver:      ipResolver,
-
-		openvpnClients: NewClientMap(sessionMap),
+		sessionMap:      sessionMap,
+		authChecks:      authChecks,
 	}
 }
 
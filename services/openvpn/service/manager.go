@@ -18,6 +18,7 @@
 package service
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -63,11 +64,10 @@ type Manager struct {
 	dnsProxy        *dns.Proxy
 	bus             eventbus.EventBus
 	trafficFirewall firewall.IncomingTrafficFirewall
-	vpnNetwork      net.IPNet
-	vpnServerPort   int
-	openvpnProcess  openvpn.Process
-	openvpnClients  *clientMap
-	openvpnAuth     *authHandler
+	shaperLimits    *shaper.Limits
+	listeners       []*listener
+	sessionMap      SessionMap
+	authChecks      []AuthCheck
 	ipResolver      ip.Resolver
 	serviceOptions  Options
 	nodeOptions     node.Options
@@ -79,45 +79,87 @@ type Manager struct {
 	tlsPrimitives *tls.Primitives
 }
 
+// listener is an OpenVPN process listening on a single transport protocol with its own subnet and clients.
+type listener struct {
+	protocol string
+	port     int
+	network  net.IPNet
+	process  openvpn.Process
+	clients  *clientMap
+	auth     *authHandler
+}
+
 // Serve starts service - does block
 func (m *Manager) Serve(instance *service.Instance) (err error) {
-	m.vpnNetwork = net.IPNet{
+	protocols := m.serviceOptions.Protocols()
+	if len(protocols) == 0 {
+		return errors.New("no OpenVPN protocol configured")
+	}
+
+	vpnNetwork := net.IPNet{
 		IP:   net.ParseIP(m.serviceOptions.Subnet),
 		Mask: net.IPMask(net.ParseIP(m.serviceOptions.Netmask).To4()),
 	}
+	if vpnNetwork.IP.To4() == nil {
+		return fmt.Errorf("invalid OpenVPN subnet: %s", m.serviceOptions.Subnet)
+	}
+	listeners := make([]*listener, len(protocols))
+	for i, protocol := range protocols {
+		if protocol != "udp" && protocol != "tcp" {
+			return fmt.Errorf("unsupported OpenVPN protocol: %s", protocol)
+		}
+		// Every listener runs a separate process, so each of them gets the next subnet of configured size.
+		listeners[i] = &listener{
+			protocol: protocol,
+			network:  nthNetwork(vpnNetwork, i),
+			clients:  NewClientMap(m.sessionMap),
+		}
+	}
+	defer func() {
+		for _, ln := range listeners {
+			if ln.process != nil {
+				ln.process.Stop()
+			}
+		}
+	}()
 
 	var dnsPort = 11153
 	dnsHandler, err := dns.ResolveViaSystem()
 	if err == nil {
 		if instance.Policies().HasDNSRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
-			removeRule, err := m.trafficFirewall.BlockIncomingTraffic(m.vpnNetwork)
-			if err != nil {
-				return fmt.Errorf("failed to enable traffic blocking: %w", err)
-			}
-			defer func() {
-				if err := removeRule(); err != nil {
-					log.Warn().Err(err).Msg("failed to disable traffic blocking")
-				}
-			}()
 		}
 
 		m.dnsProxy = dns.NewProxy("", dnsPort, dnsHandler)
 		if err := m.dnsProxy.Run(); err != nil {
 			log.Warn().Err(err).Msg("Provider DNS will not be available")
 		} else {
+			// Consumer does not know which listener it will be connected to, so all of them share the DNS IP.
 			m.dnsOK = true
-			m.dnsIP = netutil.FirstIP(m.vpnNetwork)
+			m.dnsIP = netutil.FirstIP(listeners[0].network)
 		}
 	} else {
 		log.Warn().Err(err).Msg("Provider DNS will not be available")
 	}
 
+	for _, ln := range listeners {
+		// All OpenVPN sessions of a listener share its subnet, so quotas of a single consumer can not be applied.
+		removeRules, err := instance.Policies().ApplyTrafficRules(m.trafficFirewall, ln.network, m.dnsOK)
+		if err != nil {
+			return fmt.Errorf("failed to apply access policy rules: %w", err)
+		}
+		defer func() {
+			if err := removeRules(); err != nil {
+				log.Warn().Err(err).Msg("failed to disable traffic blocking")
+			}
+		}()
+	}
+
+	// Listeners of different protocols do not conflict, so they share the port number.
 	servicePort, err := m.ports.Acquire()
 	if err != nil {
 		return fmt.Errorf("failed to acquire an unused port: %w", err)
 	}
-	m.vpnServerPort = servicePort.Num()
 
 	m.outboundIP, err = m.ipResolver.GetOutboundIP()
 	if err != nil {
@@ -129,45 +171,57 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 		return
 	}
 
-	if err := firewall.AddInboundRule(m.serviceOptions.Protocol, m.vpnServerPort); err != nil {
-		return fmt.Errorf("failed to add firewall rule: %w", err)
-	}
-	defer func() {
-		if err := firewall.RemoveInboundRule(m.serviceOptions.Protocol, m.vpnServerPort); err != nil {
-			log.Error().Err(err).Msg("Failed to delete firewall rule for OpenVPN")
+	for _, ln := range listeners {
+		ln.port = servicePort.Num()
+		if err := firewall.AddInboundRule(ln.protocol, ln.port); err != nil {
+			return fmt.Errorf("failed to add firewall rule: %w", err)
 		}
-	}()
+		defer func(ln *listener) {
+			if err := firewall.RemoveInboundRule(ln.protocol, ln.port); err != nil {
+				log.Error().Err(err).Msg("Failed to delete firewall rule for OpenVPN")
+			}
+		}(ln)
 
-	log.Info().Msgf("Starting OpenVPN server on port: %d", m.vpnServerPort)
-	if err := m.startServer(); err != nil {
-		return fmt.Errorf("failed to start Openvpn server: %w", err)
-	}
+		log.Info().Msgf("Starting OpenVPN server on port: %d/%s", ln.port, ln.protocol)
+		if err := m.startServer(ln); err != nil {
+			return fmt.Errorf("failed to start Openvpn server: %w", err)
+		}
 
-	if _, err := m.natService.Setup(nat.Options{
-		VPNNetwork:        m.vpnNetwork,
-		ProviderExtIP:     net.ParseIP(m.outboundIP),
-		EnableDNSRedirect: m.dnsOK,
-		DNSIP:             m.dnsIP,
-		DNSPort:           dnsPort,
-	}); err != nil {
-		return fmt.Errorf("failed to setup NAT/firewall rules: %w", err)
-	}
+		if _, err := m.natService.Setup(nat.Options{
+			VPNNetwork:        ln.network,
+			ProviderExtIP:     net.ParseIP(m.outboundIP),
+			EnableDNSRedirect: m.dnsOK,
+			DNSIP:             m.dnsIP,
+			DNSPort:           dnsPort,
+		}); err != nil {
+			return fmt.Errorf("failed to setup NAT/firewall rules: %w", err)
+		}
 
-	s := shaper.New(m.bus)
-	err = s.Start(m.openvpnProcess.DeviceName())
-	if err != nil {
-		log.Error().Err(err).Msg("Could not start traffic shaper")
+		s := shaper.New(m.bus)
+		// All OpenVPN sessions of a listener share its tunnel device, so only service wide limits can be applied.
+		err = s.Start(ln.process.DeviceName(), m.shaperLimits.ProfileFunc(openvpn_service.ServiceType, "", GetShaperProfile))
+		if err != nil {
+			log.Error().Err(err).Msg("Could not start traffic shaper")
+		}
+		defer s.Clear(ln.process.DeviceName())
 	}
-	defer s.Clear(m.openvpnProcess.DeviceName())
+	m.listeners = listeners
 
 	log.Info().Msg("OpenVPN server waiting")
-	return m.openvpnProcess.Wait()
+	exited := make(chan error, len(listeners))
+	for _, ln := range listeners {
+		go func(process openvpn.Process) {
+			exited <- process.Wait()
+		}(ln.process)
+	}
+	// Service is not usable without any of the advertised listeners, so the rest are stopped as well.
+	return <-exited
 }
 
 // Stop stops service
 func (m *Manager) Stop() error {
-	if m.openvpnProcess != nil {
-		m.openvpnProcess.Stop()
+	for _, ln := range m.listeners {
+		ln.process.Stop()
 	}
 
 	if m.dnsProxy != nil {
@@ -181,7 +235,8 @@ func (m *Manager) Stop() error {
 
 // ProvideConfig takes session creation config from end consumer and provides the service configuration to the end consumer
 func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn) (*service.ConfigParams, error) {
-	if m.vpnServerPort == 0 {
+	listeners := m.listeners
+	if len(listeners) == 0 {
 		return nil, errors.New("service port not initialized")
 	}
 
@@ -190,29 +245,41 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, fmt.Errorf("could not get public IP: %w", err)
 	}
 
+	primary := listeners[0]
 	serverIP := vpnServerIP(m.outboundIP, publicIP, m.nodeOptions.OptionsNetwork.Localnet)
 	vpnConfig := &openvpn_service.VPNConfig{
 		RemoteIP:        serverIP,
-		RemotePort:      m.vpnServerPort,
-		RemoteProtocol:  m.serviceOptions.Protocol,
+		RemotePort:      primary.port,
+		RemoteProtocol:  primary.protocol,
 		TLSPresharedKey: m.tlsPrimitives.PresharedKey.ToPEMFormat(),
 		CACertificate:   m.tlsPrimitives.CertificateAuthority.ToPEMFormat(),
 	}
+	for _, ln := range listeners[1:] {
+		vpnConfig.FallbackRemotes = append(vpnConfig.FallbackRemotes, openvpn_service.VPNRemote{
+			Port:     ln.port,
+			Protocol: ln.protocol,
+		})
+	}
 	if m.dnsOK {
 		vpnConfig.DNSIPs = m.dnsIP.String()
 	}
 
-	if err := proxyOpenVPN(conn, m.vpnServerPort); err != nil {
-		return nil, fmt.Errorf("could not proxy connection to OpenVPN server: %w", err)
+	// Punched UDP connection is relayed only to UDP listener, consumer connects to TCP one directly.
+	if primary.protocol == "udp" {
+		if err := proxyOpenVPN(conn, primary.port); err != nil {
+			return nil, fmt.Errorf("could not proxy connection to OpenVPN server: %w", err)
+		}
 	}
 
 	destroy := func() {
 		log.Info().Msgf("Cleaning up session %s", sessionID)
 
-		sessionClients := m.openvpnClients.GetSessionClients(session.ID(sessionID))
-		for clientID := range sessionClients {
-			if err := m.openvpnAuth.ClientKill(clientID); err != nil {
-				log.Error().Err(err).Msgf("Cleaning up session %s failed. Error disconnecting Openvpn client %d", sessionID, clientID)
+		for _, ln := range listeners {
+			sessionClients := ln.clients.GetSessionClients(session.ID(sessionID))
+			for clientID := range sessionClients {
+				if err := ln.auth.ClientKill(clientID); err != nil {
+					log.Error().Err(err).Msgf("Cleaning up session %s failed. Error disconnecting Openvpn client %d", sessionID, clientID)
+				}
 			}
 		}
 	}
@@ -220,16 +287,16 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	return &service.ConfigParams{SessionServiceConfig: vpnConfig, SessionDestroyCallback: destroy}, nil
 }
 
-func (m *Manager) startServer() error {
+func (m *Manager) startServer(ln *listener) error {
 	vpnServerConfig := NewServerConfig(
 		m.nodeOptions.Directories.Runtime,
 		m.nodeOptions.Directories.Script,
-		m.serviceOptions.Subnet,
-		m.serviceOptions.Netmask,
+		ln.network.IP.String(),
+		net.IP(ln.network.Mask).String(),
 		m.tlsPrimitives,
 		m.nodeOptions.BindAddress,
-		m.vpnServerPort,
-		m.serviceOptions.Protocol,
+		ln.port,
+		ln.protocol,
 	)
 
 	openvpnFilterDeny := stringutil.Split(config.GetString(config.FlagFirewallProtectedNetworks), ',')
@@ -239,12 +306,12 @@ func (m *Manager) startServer() error {
 	}
 
 	stateChannel := make(chan openvpn.State, 10)
-	m.openvpnAuth = newAuthHandler(m.openvpnClients, identity.NewExtractor())
-	m.openvpnProcess = openvpn.CreateNewProcess(
+	ln.auth = newAuthHandler(ln.clients, identity.NewExtractor(), m.authChecks...)
+	ln.process = openvpn.CreateNewProcess(
 		m.nodeOptions.Openvpn.BinaryPath(),
 		vpnServerConfig.GenericConfig,
 		filter.NewMiddleware(openvpnFilterAllow, openvpnFilterDeny),
-		m.openvpnAuth,
+		ln.auth,
 		state.NewMiddleware(func(state openvpn.State) {
 			stateChannel <- state
 			//this is the last state - close channel (according to best practices of go - channel writer controls channel)
@@ -252,9 +319,9 @@ func (m *Manager) startServer() error {
 				close(stateChannel)
 			}
 		}),
-		newStatsPublisher(m.openvpnClients, m.bus, 1),
+		newStatsPublisher(ln.clients, m.bus, 1),
 	)
-	if err := m.openvpnProcess.Start(); err != nil {
+	if err := ln.process.Start(); err != nil {
 		return err
 	}
 
@@ -274,13 +341,23 @@ func (m *Manager) startServer() error {
 		for state := range stateChannel {
 			switch state {
 			case openvpn.ProcessStarted:
-				log.Info().Msg("OpenVPN service booting up")
+				log.Info().Msgf("OpenVPN %s service booting up", ln.protocol)
 			case openvpn.ProcessExited:
-				log.Info().Msg("OpenVPN service exited")
+				log.Info().Msgf("OpenVPN %s service exited", ln.protocol)
 			}
 		}
 	}()
 
-	log.Info().Msg("OpenVPN service started successfully")
+	log.Info().Msgf("OpenVPN %s service started successfully", ln.protocol)
 	return nil
 }
+
+// nthNetwork returns n-th network of the same size following the given one.
+func nthNetwork(network net.IPNet, n int) net.IPNet {
+	ones, bits := network.Mask.Size()
+	ip := binary.BigEndian.Uint32(network.IP.To4()) + uint32(n)<<uint(bits-ones)
+
+	next := net.IPNet{IP: make(net.IP, net.IPv4len), Mask: network.Mask}
+	binary.BigEndian.PutUint32(next.IP, ip)
+	return next
+}
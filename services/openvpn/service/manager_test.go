@@ -18,6 +18,7 @@
 package service
 
 import (
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,3 +29,13 @@ func TestManager_StopNotPanic(t *testing.T) {
 	err := m.Stop()
 	assert.NoError(t, err)
 }
+
+func Test_nthNetwork(t *testing.T) {
+	network := net.IPNet{IP: net.ParseIP("10.8.0.0"), Mask: net.CIDRMask(24, 32)}
+	smallNetwork := net.IPNet{IP: net.ParseIP("10.8.0.0"), Mask: net.CIDRMask(26, 32)}
+
+	first, second, next := nthNetwork(network, 0), nthNetwork(network, 1), nthNetwork(smallNetwork, 1)
+	assert.Equal(t, "10.8.0.0/24", first.String())
+	assert.Equal(t, "10.8.1.0/24", second.String())
+	assert.Equal(t, "10.8.0.64/26", next.String())
+}
@@ -19,18 +19,39 @@ package service
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/utils/stringutil"
 	"github.com/rs/zerolog/log"
 )
 
 // Options describes options which are required to start Openvpn service
 type Options struct {
+	// Protocol is a comma separated list of protocols to listen on, the first one is the primary protocol
 	Protocol string `json:"protocol"`
 	Port     int    `json:"port"`
 	Subnet   string `json:"subnet"`
 	Netmask  string `json:"netmask"`
+
+	Quotas service.Quotas `json:"quotas"`
+}
+
+// Protocols returns transport protocols to listen on, the primary protocol first.
+func (o Options) Protocols() []string {
+	var protocols []string
+	seen := make(map[string]bool)
+	for _, protocol := range stringutil.Split(o.Protocol, ',') {
+		protocol = strings.TrimSpace(protocol)
+		if protocol != "" && !seen[protocol] {
+			seen[protocol] = true
+			protocols = append(protocols, protocol)
+		}
+	}
+	return protocols
 }
 
 // GetOptions returns effective OpenVPN service options from application configuration.
@@ -40,9 +61,32 @@ func GetOptions() Options {
 		Port:     config.GetInt(config.FlagOpenvpnPort),
 		Subnet:   config.GetString(config.FlagOpenvpnSubnet),
 		Netmask:  config.GetString(config.FlagOpenvpnNetmask),
+		Quotas:   GetQuotas(),
 	}
 }
 
+// GetQuotas returns effective quotas of OpenVPN service consumers from application configuration.
+func GetQuotas() service.Quotas {
+	return service.Quotas{
+		DataPerDay: uint64(config.GetFloat64(config.FlagOpenVPNQuotaDataGB) * float64(datasize.GiB.Bytes())),
+		TimePerDay: config.GetDuration(config.FlagOpenVPNQuotaTime),
+		Sessions:   config.GetInt(config.FlagOpenVPNQuotaSessions),
+	}
+}
+
+// ConsumerQuotas returns quotas applied to every consumer of the service.
+func (o Options) ConsumerQuotas() service.Quotas {
+	return o.Quotas
+}
+
+// GetShaperProfile returns effective bandwidth limits of OpenVPN service from application configuration.
+func GetShaperProfile() shaper.Profile {
+	return shaper.DefaultProfile().Merge(shaper.Profile{
+		UplinkKbps:   config.GetInt(config.FlagOpenVPNBandwidthUplink),
+		DownlinkKbps: config.GetInt(config.FlagOpenVPNBandwidthDownlink),
+	})
+}
+
 // ParseJSONOptions function fills in OpenVPN options from JSON request, falling back to configured options for
 // missing values
 func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
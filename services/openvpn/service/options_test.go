@@ -73,3 +73,9 @@ func configureDefaults() {
 func emptyContext() *cli.Context {
 	return cli.NewContext(nil, flag.NewFlagSet("", flag.ContinueOnError), nil)
 }
+
+func Test_Options_Protocols(t *testing.T) {
+	assert.Equal(t, []string{"udp"}, Options{Protocol: "udp"}.Protocols())
+	assert.Equal(t, []string{"tcp", "udp"}, Options{Protocol: "tcp, udp,tcp"}.Protocols())
+	assert.Nil(t, Options{}.Protocols())
+}
@@ -121,6 +121,12 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		options.ProviderNATConn.Close()
 		config.LocalPort = options.ProviderNATConn.LocalAddr().(*net.UDPAddr).Port
 		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
+
+		// Stream transport relays packets via the local socket, so the stream to the provider itself must bypass the tunnel.
+		if remoteIP := options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).IP; remoteIP.IsLoopback() {
+			options.Routes.Exclude = append(options.Routes.Exclude, net.IPNet{IP: config.Provider.Endpoint.IP, Mask: net.CIDRMask(32, 32)})
+			config.Provider.Endpoint.IP = remoteIP
+		}
 	}
 
 	dnsIPs, err := options.Params.DNS.ResolveIPs(config.Consumer.DNSIPs)
@@ -130,12 +136,14 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 
 	log.Info().Msg("Starting new connection")
 	conn, err := c.startConn(wgcfg.DeviceConfig{
-		IfaceName:    "", // Interface name will be generated by connection endpoint.
-		Subnet:       config.Consumer.IPAddress,
-		PrivateKey:   c.privateKey,
-		ListenPort:   config.LocalPort,
-		DNS:          dnsIPs,
-		DNSScriptDir: c.opts.DNSScriptDir,
+		IfaceName:     "", // Interface name will be generated by connection endpoint.
+		Subnet:        config.Consumer.IPAddress,
+		PrivateKey:    c.privateKey,
+		ListenPort:    config.LocalPort,
+		DNS:           dnsIPs,
+		DNSScriptDir:  c.opts.DNSScriptDir,
+		IncludeRoutes: options.Routes.Include,
+		ExcludeRoutes: options.Routes.Exclude,
 		Peer: wgcfg.Peer{
 			Endpoint:               &config.Provider.Endpoint,
 			PublicKey:              config.Provider.PublicKey,
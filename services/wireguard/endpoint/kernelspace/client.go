@@ -66,7 +66,7 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	}
 
 	if config.Peer.Endpoint != nil {
-		if err := configureRoutes(config.IfaceName, config.Peer.Endpoint.IP); err != nil {
+		if err := netutil.ConfigureRoutes(config.IfaceName, config.Peer.Endpoint.IP, config.IncludeRoutes, config.ExcludeRoutes); err != nil {
 			return err
 		}
 	}
@@ -174,13 +174,6 @@ func (c *client) Close() (err error) {
 	return nil
 }
 
-func configureRoutes(iface string, ip net.IP) error {
-	if err := netutil.ExcludeRoute(ip); err != nil {
-		return err
-	}
-	return netutil.AddDefaultRoute(iface)
-}
-
 func stringToKey(key string) (wgtypes.Key, error) {
 	k, err := base64.StdEncoding.DecodeString(key)
 	if err != nil {
@@ -18,8 +18,6 @@
 package remoteclient
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"os/user"
 	"sync"
@@ -50,15 +48,13 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 		return fmt.Errorf("could not get current OS user: %w", err)
 	}
 
-	jsonCfg, err := json.Marshal(config)
+	supervisor, err := supervisorclient.Connect()
 	if err != nil {
-		return fmt.Errorf("could not marshal device config to JSON: %w", err)
+		return fmt.Errorf("could not connect to supervisor: %w", err)
 	}
+	defer supervisor.Close()
 
-	// Convert config to base64 to prevent nasty parsing issues on supervisor.
-	jsonb64 := base64.StdEncoding.EncodeToString(jsonCfg)
-
-	actualIface, err := supervisorclient.Command("wg-up", "-uid", currentUser.Uid, "-config", jsonb64)
+	actualIface, err := supervisor.WgUp(currentUser.Uid, config)
 	if err != nil {
 		return fmt.Errorf("failed to create wg interface: %w", err)
 	}
@@ -67,24 +63,30 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 }
 
 func (c *client) DestroyDevice(iface string) error {
-	_, err := supervisorclient.Command("wg-down", "-iface", iface)
+	supervisor, err := supervisorclient.Connect()
 	if err != nil {
+		return fmt.Errorf("could not connect to supervisor: %w", err)
+	}
+	defer supervisor.Close()
+
+	if err := supervisor.WgDown(iface); err != nil {
 		return fmt.Errorf("failed to destroy wg interface: %w", err)
 	}
 	return nil
 }
 
 func (c *client) PeerStats(iface string) (*wgcfg.Stats, error) {
-	statsJSON, err := supervisorclient.Command("wg-stats", "-iface", iface)
+	supervisor, err := supervisorclient.Connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get wg stats: %w", err)
+		return nil, fmt.Errorf("could not connect to supervisor: %w", err)
 	}
+	defer supervisor.Close()
 
-	stats := wgcfg.Stats{}
-	if err := json.Unmarshal([]byte(statsJSON), &stats); err != nil {
-		return nil, fmt.Errorf("could not unmarshal stats: %w", err)
+	stats, err := supervisor.WgStats(iface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wg stats: %w", err)
 	}
-	return &stats, nil
+	return stats, nil
 }
 
 func (c *client) Close() (err error) {
@@ -56,13 +56,10 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	c.devAPI.Up()
 
 	// For consumer mode we need to exclude provider's IP from VPN tunnel
-	// and add default routes to forward all traffic via VPN tunnel.
+	// and add routes to forward traffic via VPN tunnel.
 	if config.Peer.Endpoint != nil {
-		if err := netutil.ExcludeRoute(config.Peer.Endpoint.IP); err != nil {
-			return fmt.Errorf("could not exclude route %s: %w", config.Peer.Endpoint.IP.String(), err)
-		}
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
-			return fmt.Errorf("could not add default route for %s: %w", config.IfaceName, err)
+		if err := netutil.ConfigureRoutes(config.IfaceName, config.Peer.Endpoint.IP, config.IncludeRoutes, config.ExcludeRoutes); err != nil {
+			return err
 		}
 	}
 
@@ -24,6 +24,8 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/rs/zerolog/log"
 )
@@ -32,6 +34,7 @@ import (
 type Options struct {
 	Ports  *port.Range
 	Subnet net.IPNet
+	Quotas service.Quotas
 }
 
 // DefaultOptions is a wireguard service configuration that will be used if no options provided.
@@ -64,9 +67,32 @@ func GetOptions() Options {
 	return Options{
 		Ports:  portRange,
 		Subnet: *ipnet,
+		Quotas: GetQuotas(),
 	}
 }
 
+// GetQuotas returns effective quotas of Wireguard service consumers from application configuration.
+func GetQuotas() service.Quotas {
+	return service.Quotas{
+		DataPerDay: uint64(config.GetFloat64(config.FlagWireguardQuotaDataGB) * float64(datasize.GiB.Bytes())),
+		TimePerDay: config.GetDuration(config.FlagWireguardQuotaTime),
+		Sessions:   config.GetInt(config.FlagWireguardQuotaSessions),
+	}
+}
+
+// ConsumerQuotas returns quotas applied to every consumer of the service.
+func (o Options) ConsumerQuotas() service.Quotas {
+	return o.Quotas
+}
+
+// GetShaperProfile returns effective bandwidth limits of Wireguard sessions from application configuration.
+func GetShaperProfile() shaper.Profile {
+	return shaper.DefaultProfile().Merge(shaper.Profile{
+		UplinkKbps:   config.GetInt(config.FlagWireguardBandwidthUplink),
+		DownlinkKbps: config.GetInt(config.FlagWireguardBandwidthDownlink),
+	})
+}
+
 // ParseJSONOptions function fills in Wireguard options from JSON request
 func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 	var requestOptions = GetOptions()
@@ -75,6 +101,7 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 	}
 
 	opts := DefaultOptions
+	opts.Quotas = requestOptions.Quotas
 	err := json.Unmarshal(*request, &opts)
 	return opts, err
 }
@@ -82,19 +109,22 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (o Options) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Ports  string `json:"ports"`
-		Subnet string `json:"subnet"`
+		Ports  string         `json:"ports"`
+		Subnet string         `json:"subnet"`
+		Quotas service.Quotas `json:"quotas"`
 	}{
 		Ports:  o.Ports.String(),
 		Subnet: o.Subnet.String(),
+		Quotas: o.Quotas,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
-		Ports  string `json:"ports"`
-		Subnet string `json:"subnet"`
+		Ports  string          `json:"ports"`
+		Subnet string          `json:"subnet"`
+		Quotas *service.Quotas `json:"quotas"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -115,6 +145,9 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		}
 		o.Subnet = *ipnet
 	}
+	if options.Quotas != nil {
+		o.Quotas = *options.Quotas
+	}
 
 	return nil
 }
@@ -22,9 +22,11 @@ import (
 	"flag"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli/v2"
 )
@@ -69,3 +71,16 @@ func configureDefaults() {
 func emptyContext() *cli.Context {
 	return cli.NewContext(nil, flag.NewFlagSet("", flag.ContinueOnError), nil)
 }
+
+func Test_ParseJSONOptions_Quotas(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"quotas": {"data_per_day": 1073741824, "time_per_day": "2h", "sessions": 2}}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, service.Quotas{
+		DataPerDay: 1073741824,
+		TimePerDay: 2 * time.Hour,
+		Sessions:   2,
+	}, options.(service.QuotaOptions).ConsumerQuotas())
+}
@@ -60,6 +60,7 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	shaperLimits *shaper.Limits,
 ) *Manager {
 	resourcesAllocator := resources.NewAllocator(portSupplier, options.Subnet)
 
@@ -71,6 +72,7 @@ func NewManager(
 		natEventGetter:     natEventGetter,
 		eventBus:           eventBus,
 		trafficFirewall:    trafficFirewall,
+		shaperLimits:       shaperLimits,
 
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return endpoint.NewConnectionEndpoint(resourcesAllocator)
@@ -91,6 +93,7 @@ type Manager struct {
 	natEventGetter  NATEventGetter
 	eventBus        eventbus.EventBus
 	trafficFirewall firewall.IncomingTrafficFirewall
+	shaperLimits    *shaper.Limits
 
 	dnsOK    bool
 	dnsPort  int
@@ -140,19 +143,29 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	}
 
 	var dnsIP net.IP
-	var releaseTrafficFirewall firewall.IncomingRuleRemove
 	if m.dnsOK {
-		if m.serviceInstance.Policies().HasDNSRules() {
-			releaseTrafficFirewall, err = m.trafficFirewall.BlockIncomingTraffic(providerConfig.Subnet)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to enable traffic blocking")
-			}
-		}
-
 		dnsIP = netutil.FirstIP(config.Consumer.IPAddress)
 		config.Consumer.DNSIPs = dnsIP.String()
 	}
 
+	policies := m.serviceInstance.Policies()
+	releaseTrafficRules, err := policies.ApplyTrafficRules(m.trafficFirewall, providerConfig.Subnet, m.dnsOK)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply access policy rules")
+	}
+	// Every session has its own subnet, so the quota is applied to the single consumer.
+	releaseTrafficQuota, err := policies.ApplyTrafficQuota(m.trafficFirewall, providerConfig.Subnet)
+	if err != nil {
+		releaseTrafficRules()
+		return nil, errors.Wrap(err, "failed to apply access policy quota")
+	}
+	releaseTrafficFirewall := func() error {
+		if err := releaseTrafficQuota(); err != nil {
+			return err
+		}
+		return releaseTrafficRules()
+	}
+
 	natRules, err := m.natService.Setup(nat.Options{
 		VPNNetwork:        config.Consumer.IPAddress,
 		DNSIP:             dnsIP,
@@ -169,7 +182,7 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 
 	ifaceName := conn.InterfaceName()
 	s := shaper.New(m.eventBus)
-	err = s.Start(ifaceName)
+	err = s.Start(ifaceName, m.shaperLimits.ProfileFunc(wg.ServiceType, sessionID, GetShaperProfile))
 	if err != nil {
 		log.Error().Err(err).Msg("Could not start traffic shaper")
 	}
@@ -183,11 +196,10 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		statsPublisher.stop()
 
 		s.Clear(ifaceName)
+		m.shaperLimits.RemoveSession(sessionID)
 
-		if releaseTrafficFirewall != nil {
-			if err := releaseTrafficFirewall(); err != nil {
-				log.Warn().Err(err).Msg("failed to disable traffic blocking")
-			}
+		if err := releaseTrafficFirewall(); err != nil {
+			log.Warn().Err(err).Msg("failed to disable traffic blocking")
 		}
 
 		log.Trace().Msg("Deleting nat rules")
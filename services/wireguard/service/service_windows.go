@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/nat"
@@ -46,6 +47,7 @@ func NewManager(
 	options Options,
 	portSupplier port.ServicePortSupplier,
 	trafficFirewall firewall.IncomingTrafficFirewall,
+	shaperLimits *shaper.Limits,
 ) *Manager {
 	return &Manager{}
 }
@@ -45,6 +45,9 @@ type DeviceConfig struct {
 	DNS        []string  `json:"dns"`
 	// Used only for unix.
 	DNSScriptDir string `json:"dns_script_dir"`
+	// Used only for consumer, all traffic is routed through the tunnel when no subnets are included.
+	IncludeRoutes []net.IPNet `json:"include_routes"`
+	ExcludeRoutes []net.IPNet `json:"exclude_routes"`
 
 	Peer Peer `json:"peer"`
 }
@@ -59,13 +62,15 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 	}
 
 	type deviceConfig struct {
-		IfaceName    string   `json:"iface_name"`
-		Subnet       string   `json:"subnet"`
-		PrivateKey   string   `json:"private_key"`
-		ListenPort   int      `json:"listen_port"`
-		DNS          []string `json:"dns"`
-		DNSScriptDir string   `json:"dns_script_dir"`
-		Peer         peer     `json:"peer"`
+		IfaceName     string   `json:"iface_name"`
+		Subnet        string   `json:"subnet"`
+		PrivateKey    string   `json:"private_key"`
+		ListenPort    int      `json:"listen_port"`
+		DNS           []string `json:"dns"`
+		DNSScriptDir  string   `json:"dns_script_dir"`
+		IncludeRoutes []string `json:"include_routes,omitempty"`
+		ExcludeRoutes []string `json:"exclude_routes,omitempty"`
+		Peer          peer     `json:"peer"`
 	}
 
 	var peerEndpoint string
@@ -74,12 +79,14 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(&deviceConfig{
-		IfaceName:    dc.IfaceName,
-		Subnet:       dc.Subnet.String(),
-		PrivateKey:   dc.PrivateKey,
-		ListenPort:   dc.ListenPort,
-		DNS:          dc.DNS,
-		DNSScriptDir: dc.DNSScriptDir,
+		IfaceName:     dc.IfaceName,
+		Subnet:        dc.Subnet.String(),
+		PrivateKey:    dc.PrivateKey,
+		ListenPort:    dc.ListenPort,
+		DNS:           dc.DNS,
+		DNSScriptDir:  dc.DNSScriptDir,
+		IncludeRoutes: subnetsToStrings(dc.IncludeRoutes),
+		ExcludeRoutes: subnetsToStrings(dc.ExcludeRoutes),
 		Peer: peer{
 			PublicKey:              dc.Peer.PublicKey,
 			Endpoint:               peerEndpoint,
@@ -99,13 +106,15 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	}
 
 	type deviceConfig struct {
-		IfaceName    string   `json:"iface_name"`
-		Subnet       string   `json:"subnet"`
-		PrivateKey   string   `json:"private_key"`
-		ListenPort   int      `json:"listen_port"`
-		DNS          []string `json:"dns"`
-		DNSScriptDir string   `json:"dns_script_dir"`
-		Peer         peer     `json:"peer"`
+		IfaceName     string   `json:"iface_name"`
+		Subnet        string   `json:"subnet"`
+		PrivateKey    string   `json:"private_key"`
+		ListenPort    int      `json:"listen_port"`
+		DNS           []string `json:"dns"`
+		DNSScriptDir  string   `json:"dns_script_dir"`
+		IncludeRoutes []string `json:"include_routes,omitempty"`
+		ExcludeRoutes []string `json:"exclude_routes,omitempty"`
+		Peer          peer     `json:"peer"`
 	}
 
 	cfg := deviceConfig{}
@@ -134,6 +143,12 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	dc.ListenPort = cfg.ListenPort
 	dc.DNS = cfg.DNS
 	dc.DNSScriptDir = cfg.DNSScriptDir
+	if dc.IncludeRoutes, err = stringsToSubnets(cfg.IncludeRoutes); err != nil {
+		return fmt.Errorf("could not parse include routes: %w", err)
+	}
+	if dc.ExcludeRoutes, err = stringsToSubnets(cfg.ExcludeRoutes); err != nil {
+		return fmt.Errorf("could not parse exclude routes: %w", err)
+	}
 	dc.Peer = Peer{
 		PublicKey:              cfg.Peer.PublicKey,
 		Endpoint:               peerEndpoint,
@@ -144,6 +159,26 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func subnetsToStrings(subnets []net.IPNet) []string {
+	var res []string
+	for _, subnet := range subnets {
+		res = append(res, subnet.String())
+	}
+	return res
+}
+
+func stringsToSubnets(cidrs []string) ([]net.IPNet, error) {
+	var res []net.IPNet
+	for _, cidr := range cidrs {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *subnet)
+	}
+	return res, nil
+}
+
 // Encode encodes device config into string representation which is used for
 // userspace and kernel space wireguard configuration.
 func (dc *DeviceConfig) Encode() string {
@@ -151,6 +151,16 @@ func TestDeviceConfig_UnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "Test unmarshal split tunnel routes",
+			config: `{"iface_name":"myst0","subnet":"10.0.182.2/24","include_routes":["10.0.0.0/8"],"exclude_routes":["1.2.3.4/32"],"peer":{}}`,
+			expected: DeviceConfig{
+				IfaceName:     "myst0",
+				Subnet:        net.IPNet{IP: net.ParseIP("10.0.182.2"), Mask: net.IPv4Mask(255, 255, 255, 0)},
+				IncludeRoutes: []net.IPNet{{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}},
+				ExcludeRoutes: []net.IPNet{{IP: net.IP{1, 2, 3, 4}, Mask: net.CIDRMask(32, 32)}},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -35,4 +35,7 @@ const (
 
 	// StatusConnectionFailed indicates unknown session connection error.
 	StatusConnectionFailed StatusCode = 2003
+
+	// StatusSessionQuotaExceeded indicates that session was closed by provider as consumer exceeded its quota.
+	StatusSessionQuotaExceeded StatusCode = 2004
 )
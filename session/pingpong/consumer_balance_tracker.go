@@ -136,6 +136,7 @@ func (cbt *ConsumerBalanceTracker) publishChangeEvent(id identity.Identity, befo
 
 	cbt.bus.Publish(event.AppTopicBalanceChanged, event.AppEventBalanceChanged{
 		Identity: id,
+		HermesID: cbt.hermesAddress,
 		Previous: before,
 		Current:  after,
 	})
@@ -163,6 +164,10 @@ func (cbt *ConsumerBalanceTracker) handleUnlockEvent(data identity.AppEventIdent
 }
 
 func (cbt *ConsumerBalanceTracker) handleGrandTotalChanged(ev event.AppEventGrandTotalChanged) {
+	if ev.HermesID != cbt.hermesAddress {
+		return
+	}
+
 	if _, ok := cbt.getBalance(ev.ChainID, ev.ConsumerID); !ok {
 		cbt.ForceBalanceUpdate(ev.ChainID, ev.ConsumerID)
 		return
@@ -314,6 +319,11 @@ func (cbt *ConsumerBalanceTracker) handleRegistrationEvent(event registry.AppEve
 }
 
 func (cbt *ConsumerBalanceTracker) alignWithTransactor(chainID int64, id identity.Identity) {
+	// transactor only knows the bounty of the hermes identity is being registered with
+	if cbt.transactorRegistrationStatusProvider == nil {
+		return
+	}
+
 	balance, ok := cbt.getBalance(chainID, id)
 	if ok {
 		// do not override existing values with transactor data
@@ -98,6 +98,7 @@ func TestConsumerBalanceTracker_Fresh_Registration(t *testing.T) {
 	var promised = big.NewInt(100)
 	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
 		ChainID:    1,
+		HermesID:   hermesID,
 		ConsumerID: id1,
 		Current:    promised,
 	})
@@ -216,6 +217,7 @@ func TestConsumerBalanceTracker_Handles_GrandTotalChanges(t *testing.T) {
 	var diff = big.NewInt(10)
 	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
 		ChainID:    1,
+		HermesID:   hermesID,
 		ConsumerID: id1,
 		Current:    new(big.Int).Add(grandTotalPromised, diff),
 	})
@@ -229,6 +231,7 @@ func TestConsumerBalanceTracker_Handles_GrandTotalChanges(t *testing.T) {
 	var diff2 = big.NewInt(20)
 	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
 		ChainID:    1,
+		HermesID:   hermesID,
 		ConsumerID: id1,
 		Current:    new(big.Int).Add(grandTotalPromised, diff2),
 	})
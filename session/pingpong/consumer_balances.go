@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// ConsumerBalances keeps track of consumer balances in every hermes the consumer has a channel with.
+// The first hermes is the one identities get registered with, its balance is reported as the consumer balance.
+type ConsumerBalances struct {
+	hermesIDs []common.Address
+	trackers  map[common.Address]*ConsumerBalanceTracker
+}
+
+// NewConsumerBalances creates a new instance from balance trackers of the given hermeses.
+func NewConsumerBalances(hermesIDs []common.Address, trackers map[common.Address]*ConsumerBalanceTracker) *ConsumerBalances {
+	return &ConsumerBalances{
+		hermesIDs: hermesIDs,
+		trackers:  trackers,
+	}
+}
+
+// Subscribe subscribes every balance tracker to relevant events.
+func (cb *ConsumerBalances) Subscribe(bus eventbus.Subscriber) error {
+	for _, hermesID := range cb.hermesIDs {
+		if err := cb.trackers[hermesID].Subscribe(bus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HermesIDs returns hermeses the consumer balances are tracked in.
+func (cb *ConsumerBalances) HermesIDs() []common.Address {
+	return cb.hermesIDs
+}
+
+// GetBalance gets the current balance for given identity in the registration hermes.
+func (cb *ConsumerBalances) GetBalance(chainID int64, id identity.Identity) *big.Int {
+	return cb.GetHermesBalance(chainID, id, cb.hermesIDs[0])
+}
+
+// ForceBalanceUpdate forces a balance update in every hermes and returns the updated balance in the registration hermes.
+func (cb *ConsumerBalances) ForceBalanceUpdate(chainID int64, id identity.Identity) *big.Int {
+	for _, hermesID := range cb.hermesIDs[1:] {
+		cb.trackers[hermesID].ForceBalanceUpdate(chainID, id)
+	}
+	return cb.ForceHermesBalanceUpdate(chainID, id, cb.hermesIDs[0])
+}
+
+// GetHermesBalance gets the current balance for given identity in the given hermes.
+func (cb *ConsumerBalances) GetHermesBalance(chainID int64, id identity.Identity, hermesID common.Address) *big.Int {
+	tracker, ok := cb.trackers[hermesID]
+	if !ok {
+		return new(big.Int)
+	}
+	return tracker.GetBalance(chainID, id)
+}
+
+// ForceHermesBalanceUpdate forces a balance update in the given hermes and returns the updated balance.
+func (cb *ConsumerBalances) ForceHermesBalanceUpdate(chainID int64, id identity.Identity, hermesID common.Address) *big.Int {
+	tracker, ok := cb.trackers[hermesID]
+	if !ok {
+		return new(big.Int)
+	}
+	return tracker.ForceBalanceUpdate(chainID, id)
+}
+
+// GetHermesBalances gets the current balances for given identity in every hermes.
+func (cb *ConsumerBalances) GetHermesBalances(chainID int64, id identity.Identity) map[common.Address]*big.Int {
+	balances := make(map[common.Address]*big.Int, len(cb.hermesIDs))
+	for _, hermesID := range cb.hermesIDs {
+		balances[hermesID] = cb.trackers[hermesID].GetBalance(chainID, id)
+	}
+	return balances
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerBalances_TracksBalancesPerHermes(t *testing.T) {
+	id := identity.FromAddress("0x000000001")
+	hermes1 := common.HexToAddress("0x000000acc")
+	hermes2 := common.HexToAddress("0x000000bcc")
+	bus := eventbus.New()
+	bc := mockConsumerBalanceChecker{
+		channelToReturn: client.ConsumerChannel{
+			Balance: initialBalance,
+			Settled: big.NewInt(0),
+		},
+	}
+	calc := mockChannelAddressCalculator{}
+	newTracker := func(hermesID common.Address) *ConsumerBalanceTracker {
+		return NewConsumerBalanceTracker(bus, mockMystSCaddress, hermesID, &bc, &calc, &mockConsumerTotalsStorage{res: big.NewInt(0)}, &mockconsumerInfoGetter{}, nil, &mockRegistrationStatusProvider{})
+	}
+	balances := NewConsumerBalances([]common.Address{hermes1, hermes2}, map[common.Address]*ConsumerBalanceTracker{
+		hermes1: newTracker(hermes1),
+		hermes2: newTracker(hermes2),
+	})
+
+	err := balances.Subscribe(bus)
+	assert.NoError(t, err)
+	bus.Publish(identity.AppTopicIdentityUnlock, identity.AppEventIdentityUnlock{
+		ChainID: 1,
+		ID:      id,
+	})
+	assert.Eventually(t, func() bool {
+		return balances.GetHermesBalance(1, id, hermes1).Cmp(initialBalance) == 0 &&
+			balances.GetHermesBalance(1, id, hermes2).Cmp(initialBalance) == 0
+	}, defaultWaitTime, defaultWaitInterval)
+
+	var promised = big.NewInt(100)
+	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
+		ChainID:    1,
+		HermesID:   hermes2,
+		ConsumerID: id,
+		Current:    promised,
+	})
+	assert.Eventually(t, func() bool {
+		return balances.GetHermesBalance(1, id, hermes2).Cmp(new(big.Int).Sub(initialBalance, promised)) == 0
+	}, defaultWaitTime, defaultWaitInterval)
+
+	assert.Equal(t, initialBalance, balances.GetBalance(1, id))
+	assert.Equal(t, map[common.Address]*big.Int{
+		hermes1: initialBalance,
+		hermes2: new(big.Int).Sub(initialBalance, promised),
+	}, balances.GetHermesBalances(1, id))
+	assert.Equal(t, big.NewInt(0), balances.GetHermesBalance(1, id, common.HexToAddress("0x000000ccc")))
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+const consumerPaymentStateBucket = "consumer-payment-states"
+
+// ConsumerPaymentState represents the payment state of a consumer session, persisted after every paid invoice.
+// It survives node restarts, so the interrupted session can be resumed with the provider.
+type ConsumerPaymentState struct {
+	AgreementID     string `storm:"id"`
+	SessionID       string
+	ChainID         int64
+	ConsumerID      string
+	ProviderID      string
+	HermesID        string
+	ServiceType     string
+	LastInvoice     crypto.Invoice
+	ExchangeMessage crypto.ExchangeMessage
+	UpdatedAt       time.Time
+}
+
+// ConsumerPaymentStateFilter defines all flags for filtering in consumer payment state storage.
+type ConsumerPaymentStateFilter struct {
+	ChainID     int64
+	ConsumerID  string
+	ProviderID  string
+	HermesID    string
+	ServiceType string
+}
+
+// ConsumerPaymentStateStorage stores payment states of the unfinished consumer sessions.
+type ConsumerPaymentStateStorage struct {
+	bolt *boltdb.Bolt
+}
+
+// NewConsumerPaymentStateStorage returns a new instance of the ConsumerPaymentStateStorage.
+func NewConsumerPaymentStateStorage(bolt *boltdb.Bolt) *ConsumerPaymentStateStorage {
+	return &ConsumerPaymentStateStorage{
+		bolt: bolt,
+	}
+}
+
+// Store stores the given payment state, replacing the previous state of the same agreement.
+func (s *ConsumerPaymentStateStorage) Store(state ConsumerPaymentState) error {
+	return s.bolt.DB().From(consumerPaymentStateBucket).Save(&state)
+}
+
+// Delete removes the payment state of the given agreement.
+func (s *ConsumerPaymentStateStorage) Delete(agreementID string) error {
+	err := s.bolt.DB().From(consumerPaymentStateBucket).DeleteStruct(&ConsumerPaymentState{AgreementID: agreementID})
+	if errors.Is(err, storm.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Latest returns the most recently updated payment state matching the given filter.
+func (s *ConsumerPaymentStateStorage) Latest(filter ConsumerPaymentStateFilter) (ConsumerPaymentState, error) {
+	var state ConsumerPaymentState
+	err := s.bolt.DB().
+		From(consumerPaymentStateBucket).
+		Select(
+			q.Eq("ChainID", filter.ChainID),
+			q.Eq("ConsumerID", filter.ConsumerID),
+			q.Eq("ProviderID", filter.ProviderID),
+			q.Eq("HermesID", filter.HermesID),
+			q.Eq("ServiceType", filter.ServiceType),
+		).
+		OrderBy("UpdatedAt").
+		Reverse().
+		First(&state)
+	if errors.Is(err, storm.ErrNotFound) {
+		return state, ErrNotFound
+	}
+	return state, err
+}
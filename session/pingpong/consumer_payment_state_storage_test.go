@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerPaymentStateStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consumerPaymentStateTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	storage := NewConsumerPaymentStateStorage(bolt)
+
+	filter := ConsumerPaymentStateFilter{
+		ChainID:     1,
+		ConsumerID:  "0x1",
+		ProviderID:  "0x2",
+		HermesID:    "0x3",
+		ServiceType: "wireguard",
+	}
+	older := ConsumerPaymentState{
+		AgreementID: "1",
+		SessionID:   "session-1",
+		ChainID:     filter.ChainID,
+		ConsumerID:  filter.ConsumerID,
+		ProviderID:  filter.ProviderID,
+		HermesID:    filter.HermesID,
+		ServiceType: filter.ServiceType,
+		LastInvoice: crypto.Invoice{AgreementID: big.NewInt(1), AgreementTotal: big.NewInt(10)},
+		UpdatedAt:   time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+	}
+	newer := older
+	newer.AgreementID = "2"
+	newer.SessionID = "session-2"
+	newer.LastInvoice = crypto.Invoice{AgreementID: big.NewInt(2), AgreementTotal: big.NewInt(20)}
+	newer.UpdatedAt = older.UpdatedAt.Add(time.Hour)
+	otherProvider := newer
+	otherProvider.AgreementID = "3"
+	otherProvider.ProviderID = "0x4"
+	otherProvider.UpdatedAt = newer.UpdatedAt.Add(time.Hour)
+
+	t.Run("Returns not found if no states exist", func(t *testing.T) {
+		_, err := storage.Latest(filter)
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("Returns the latest matching state", func(t *testing.T) {
+		assert.NoError(t, storage.Store(older))
+		assert.NoError(t, storage.Store(newer))
+		assert.NoError(t, storage.Store(otherProvider))
+
+		state, err := storage.Latest(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, "session-2", state.SessionID)
+		assert.Equal(t, big.NewInt(20), state.LastInvoice.AgreementTotal)
+	})
+
+	t.Run("Replaces the state of the same agreement", func(t *testing.T) {
+		newer.LastInvoice.AgreementTotal = big.NewInt(30)
+		assert.NoError(t, storage.Store(newer))
+
+		state, err := storage.Latest(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(30), state.LastInvoice.AgreementTotal)
+	})
+
+	t.Run("Deletes the state", func(t *testing.T) {
+		assert.NoError(t, storage.Delete("2"))
+		assert.NoError(t, storage.Delete("2"))
+
+		state, err := storage.Latest(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, "session-1", state.SessionID)
+	})
+}
@@ -36,6 +36,8 @@ const (
 	AppTopicInvoicePaid = "invoice_paid"
 	// AppTopicSettlementRequest forces the settlement of promises for given provider/hermes.
 	AppTopicSettlementRequest = "settlement_request"
+	// AppTopicSettlementComplete represents the topic to which the results of settlements are published.
+	AppTopicSettlementComplete = "settlement_complete"
 )
 
 // AppEventSettlementRequest represents the payload that is sent on the AppTopicSettlementRequest topic.
@@ -45,6 +47,15 @@ type AppEventSettlementRequest struct {
 	ChainID    int64
 }
 
+// AppEventSettlementComplete represents the payload that is sent on the AppTopicSettlementComplete topic.
+// Amount is set only for the successful settlement.
+type AppEventSettlementComplete struct {
+	HermesID   common.Address
+	ProviderID identity.Identity
+	Successful bool
+	Amount     *big.Int
+}
+
 // AppEventHermesPromise represents the payload that is sent on the AppTopicHermesPromise.
 type AppEventHermesPromise struct {
 	Promise    crypto.Promise
@@ -55,6 +66,7 @@ type AppEventHermesPromise struct {
 // AppEventBalanceChanged represents a balance change event
 type AppEventBalanceChanged struct {
 	Identity identity.Identity
+	HermesID common.Address
 	Previous *big.Int
 	Current  *big.Int
 }
@@ -89,3 +101,28 @@ type AppEventGrandTotalChanged struct {
 	HermesID   common.Address
 	ConsumerID identity.Identity
 }
+
+// AppTopicSpendingLimit represents a topic to which consumer spending limit alerts are published.
+const AppTopicSpendingLimit = "consumer_spending_limit"
+
+// SpendingPeriod represents a period the consumer spending is limited for.
+type SpendingPeriod string
+
+const (
+	// SpendingPeriodSession limits spending of a single session.
+	SpendingPeriodSession SpendingPeriod = "session"
+	// SpendingPeriodDaily limits spending of the current day.
+	SpendingPeriodDaily SpendingPeriod = "daily"
+	// SpendingPeriodMonthly limits spending of the current month.
+	SpendingPeriodMonthly SpendingPeriod = "monthly"
+)
+
+// AppEventSpendingLimit is published once spending of the period approaches the limit and once the limit is reached.
+type AppEventSpendingLimit struct {
+	ConsumerID identity.Identity
+	SessionID  string
+	Period     SpendingPeriod
+	Limit      *big.Int
+	Spent      *big.Int
+	Reached    bool
+}
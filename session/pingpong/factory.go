@@ -18,6 +18,7 @@
 package pingpong
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -53,6 +54,9 @@ const (
 	DefaultHermesFailureCount uint64 = 10
 )
 
+// ErrHermesNotAccepted is returned when consumer pays through a hermes the provider does not accept.
+var ErrHermesNotAccepted = errors.New("hermes is not accepted by the provider")
+
 var gb = big.NewInt(1024 * 1024 * 1024)
 var accuracy = big.NewInt(500000000000000)
 
@@ -119,9 +123,13 @@ func InvoiceFactoryCreator(
 	eventBus eventbus.EventBus,
 	proposal market.ServiceProposal,
 	promiseHandler promiseHandler,
-	providersHermes common.Address,
+	acceptedHermeses []common.Address,
 ) func(identity.Identity, identity.Identity, int64, common.Address, string, chan crypto.ExchangeMessage) (service.PaymentEngine, error) {
 	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage) (service.PaymentEngine, error) {
+		if !isHermesAccepted(acceptedHermeses, hermesID) {
+			return nil, fmt.Errorf("could not create payment engine for hermes %v: %w", hermesID.Hex(), ErrHermesNotAccepted)
+		}
+
 		timeTracker := session.NewTracker(mbtime.Now)
 		deps := InvoiceTrackerDeps{
 			Proposal:                   proposal,
@@ -135,7 +143,7 @@ func InvoiceFactoryCreator(
 			ExchangeMessageWaitTimeout: promiseTimeout,
 			ProviderID:                 providerID,
 			ConsumersHermesID:          hermesID,
-			ProvidersHermesID:          providersHermes,
+			ProvidersHermesID:          hermesID,
 			Registry:                   registryAddress,
 			MaxHermesFailureCount:      maxHermesFailureCount,
 			MaxAllowedHermesFee:        maxAllowedHermesFee,
@@ -152,15 +160,26 @@ func InvoiceFactoryCreator(
 	}
 }
 
+func isHermesAccepted(accepted []common.Address, hermesID common.Address) bool {
+	for _, id := range accepted {
+		if id == hermesID {
+			return true
+		}
+	}
+	return false
+}
+
 // ExchangeFactoryFunc returns a exchange factory.
 func ExchangeFactoryFunc(
 	keystore hashSigner,
 	signer identity.SignerFactory,
 	totalStorage consumerTotalsStorage,
+	paymentStateStorage consumerPaymentStateStorage,
 	channelImplementation string,
 	registryAddress string,
 	eventBus eventbus.EventBus,
-	dataLeewayMegabytes uint64) func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal) (connection.PaymentIssuer, error) {
+	dataLeewayMegabytes uint64,
+	spendingLimiter spendingLimiter) func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal) (connection.PaymentIssuer, error) {
 	return func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal market.ServiceProposal) (connection.PaymentIssuer, error) {
 		invoices, err := invoiceReceiver(channel)
 		if err != nil {
@@ -171,6 +190,7 @@ func ExchangeFactoryFunc(
 			InvoiceChan:               invoices,
 			PeerExchangeMessageSender: NewExchangeSender(channel),
 			ConsumerTotalsStorage:     totalStorage,
+			PaymentStateStorage:       paymentStateStorage,
 			TimeTracker:               &timeTracker,
 			Ks:                        keystore,
 			Identity:                  consumer,
@@ -181,6 +201,7 @@ func ExchangeFactoryFunc(
 			HermesAddress:             hermes,
 			DataLeeway:                datasize.MiB * datasize.BitSize(dataLeewayMegabytes),
 			ChainID:                   config.GetInt64(config.FlagChainID),
+			SpendingLimiter:           spendingLimiter,
 		}
 		return NewInvoicePayer(deps), nil
 	}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoiceFactoryCreator_RejectsNotAcceptedHermes(t *testing.T) {
+	accepted := common.HexToAddress("0x1")
+	create := InvoiceFactoryCreator(
+		nil, time.Minute, time.Minute, nil, "", "", 1, 1, nil, nil, mocks.NewEventBus(),
+		market.ServiceProposal{}, nil, []common.Address{accepted},
+	)
+
+	_, err := create(identity.FromAddress("0x2"), identity.FromAddress("0x3"), 1, common.HexToAddress("0x4"), "session", make(chan crypto.ExchangeMessage))
+	assert.True(t, errors.Is(err, ErrHermesNotAccepted))
+
+	engine, err := create(identity.FromAddress("0x2"), identity.FromAddress("0x3"), 1, accepted, "session", make(chan crypto.ExchangeMessage))
+	assert.NoError(t, err)
+	assert.Equal(t, accepted, engine.(*InvoiceTracker).deps.ProvidersHermesID)
+}
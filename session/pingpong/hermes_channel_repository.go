@@ -130,6 +130,23 @@ func (hcr *HermesChannelRepository) GetEarnings(chainID int64, id identity.Ident
 	return hcr.sumChannels(chainID, id)
 }
 
+// GetEarningsPerHermes returns earnings for given identity in every hermes it has a channel with.
+func (hcr *HermesChannelRepository) GetEarningsPerHermes(chainID int64, id identity.Identity) map[common.Address]event.Earnings {
+	hcr.lock.RLock()
+	defer hcr.lock.RUnlock()
+
+	earnings := make(map[common.Address]event.Earnings)
+	for _, channel := range hcr.channels[chainID] {
+		if channel.Identity == id {
+			earnings[channel.HermesID] = event.Earnings{
+				LifetimeBalance:  channel.LifetimeBalance(),
+				UnsettledBalance: channel.UnsettledBalance(),
+			}
+		}
+	}
+	return earnings
+}
+
 func (hcr *HermesChannelRepository) sumChannels(chainID int64, id identity.Identity) event.Earnings {
 	var lifetimeBalance = new(big.Int)
 	var unsettledBalance = new(big.Int)
@@ -206,3 +206,35 @@ func TestHermesChannelRepository_Fetch_publishesEarningChanges(t *testing.T) {
 		return true
 	}, 2*time.Second, 10*time.Millisecond)
 }
+
+func TestHermesChannelRepository_GetEarningsPerHermes(t *testing.T) {
+	// given
+	id := identity.FromAddress("0x0000000000000000000000000000000000000001")
+	hermes1 := common.HexToAddress("0x00000000000000000000000000000000000000002")
+	hermes2 := common.HexToAddress("0x00000000000000000000000000000000000000003")
+	promiseProvider := &mockHermesPromiseStorage{
+		toReturn: HermesPromise{Promise: crypto.Promise{Amount: big.NewInt(7000000)}},
+	}
+	channelStatusProvider := &mockProviderChannelStatusProvider{
+		channelToReturn: client.ProviderChannel{
+			Settled: big.NewInt(1000000),
+			Stake:   big.NewInt(1000000000000),
+		},
+	}
+	repo := NewHermesChannelRepository(promiseProvider, channelStatusProvider, mocks.NewEventBus())
+
+	// when
+	channel1, err := repo.Fetch(1, id, hermes1)
+	assert.NoError(t, err)
+	promiseProvider.toReturn = HermesPromise{Promise: crypto.Promise{Amount: big.NewInt(3000000)}}
+	channel2, err := repo.Fetch(1, id, hermes2)
+	assert.NoError(t, err)
+
+	// then
+	assert.Equal(t, map[common.Address]event.Earnings{
+		hermes1: {LifetimeBalance: channel1.LifetimeBalance(), UnsettledBalance: channel1.UnsettledBalance()},
+		hermes2: {LifetimeBalance: channel2.LifetimeBalance(), UnsettledBalance: channel2.UnsettledBalance()},
+	}, repo.GetEarningsPerHermes(1, id))
+	assert.Equal(t, big.NewInt(8000000), repo.GetEarnings(1, id).UnsettledBalance)
+	assert.Empty(t, repo.GetEarningsPerHermes(2, id))
+}
@@ -57,6 +57,7 @@ type registrationStatusProvider interface {
 }
 
 type transactor interface {
+	FetchSettleFees(chainID int64) (registry.FeesResponse, error)
 	SettleAndRebalance(hermesID, providerID string, promise crypto.Promise) error
 	SettleWithBeneficiary(id, beneficiary, hermesID string, promise crypto.Promise) error
 	SettleIntoStake(hermesID, providerID string, promise crypto.Promise) error
@@ -80,6 +81,7 @@ type HermesPromiseSettler interface {
 	SettleWithBeneficiary(chainID int64, providerID identity.Identity, beneficiary, hermesID common.Address) error
 	SettleIntoStake(chainID int64, providerID identity.Identity, hermesID common.Address) error
 	GetHermesFee(chainID int64, hermesID common.Address) (uint16, error)
+	Forecast(chainID int64, providerID identity.Identity, hermesID common.Address) (SettlementForecast, error)
 }
 
 // hermesPromiseSettler is responsible for settling the hermes promises.
@@ -92,6 +94,8 @@ type hermesPromiseSettler struct {
 	transactor                 transactor
 	channelProvider            hermesChannelProvider
 	settlementHistoryStorage   settlementHistoryStorage
+	publisher                  eventbus.Publisher
+	optimizer                  *settlementOptimizer
 
 	// TODO: Consider adding chain ID to this as well.
 	currentState map[identity.Identity]settlementState
@@ -105,10 +109,13 @@ type HermesPromiseSettlerConfig struct {
 	HermesAddress        common.Address
 	Threshold            float64
 	MaxWaitForSettlement time.Duration
+	// MaxFeeRatio allows settling before the threshold is reached, once transactor and hermes fees
+	// are at most this fraction of the settled amount. Zero disables early settlements.
+	MaxFeeRatio float64
 }
 
 // NewHermesPromiseSettler creates a new instance of hermes promise settler.
-func NewHermesPromiseSettler(transactor transactor, channelProvider hermesChannelProvider, providerChannelStatusProvider providerChannelStatusProvider, registrationStatusProvider registrationStatusProvider, ks ks, settlementHistoryStorage settlementHistoryStorage, config HermesPromiseSettlerConfig) *hermesPromiseSettler {
+func NewHermesPromiseSettler(transactor transactor, channelProvider hermesChannelProvider, providerChannelStatusProvider providerChannelStatusProvider, registrationStatusProvider registrationStatusProvider, ks ks, settlementHistoryStorage settlementHistoryStorage, publisher eventbus.Publisher, config HermesPromiseSettlerConfig) *hermesPromiseSettler {
 	return &hermesPromiseSettler{
 		bc:                         providerChannelStatusProvider,
 		ks:                         ks,
@@ -117,6 +124,8 @@ func NewHermesPromiseSettler(transactor transactor, channelProvider hermesChanne
 		currentState:               make(map[identity.Identity]settlementState),
 		channelProvider:            channelProvider,
 		settlementHistoryStorage:   settlementHistoryStorage,
+		publisher:                  publisher,
+		optimizer:                  newSettlementOptimizer(transactor, providerChannelStatusProvider, config.MaxFeeRatio),
 
 		// defaulting to a queue of 5, in case we have a few active identities.
 		settleQueue: make(chan receivedPromise, 5),
@@ -258,6 +267,7 @@ func (aps *hermesPromiseSettler) handleHermesPromiseReceived(apep event.AppEvent
 		return
 	}
 	log.Info().Msgf("Hermes %q promise state updated for provider %q", apep.HermesID.Hex(), id)
+	aps.optimizer.record(channel)
 
 	if s.needsSettling(aps.config.Threshold, channel) {
 		// TODO: when do we settle into stake? Do we ever auto settle into stake now?
@@ -268,6 +278,9 @@ func (aps *hermesPromiseSettler) handleHermesPromiseReceived(apep event.AppEvent
 		// 	}()
 		// } else
 		aps.initiateSettling(channel)
+	} else if aps.optimizer.needsSettling(apep.Promise.ChainID, s, channel) {
+		log.Info().Msgf("Settlement fees are low enough, settling early for provider %q", id)
+		aps.initiateSettling(channel)
 	}
 }
 
@@ -383,6 +396,17 @@ func (aps *hermesPromiseSettler) SettleWithBeneficiary(chainID int64, providerID
 	)
 }
 
+// Forecast projects earnings of the provider in the hermes channel and the next settlement.
+func (aps *hermesPromiseSettler) Forecast(chainID int64, providerID identity.Identity, hermesID common.Address) (SettlementForecast, error) {
+	channel, found := aps.channelProvider.Get(chainID, providerID, hermesID)
+	if !found {
+		return SettlementForecast{}, ErrNothingToSettle
+	}
+
+	aps.optimizer.record(channel)
+	return aps.optimizer.forecast(chainID, channel, aps.config.Threshold)
+}
+
 // ErrSettleTimeout indicates that the settlement has timed out
 var ErrSettleTimeout = errors.New("settle timeout")
 
@@ -450,9 +474,11 @@ func (aps *hermesPromiseSettler) settle(
 				log.Error().Err(err).Msg("Could not store settlement history")
 			}
 
+			aps.publishSettlementComplete(provider, hermesID, info.AmountSentToBeneficiary)
 			return
 		case <-time.After(aps.config.MaxWaitForSettlement):
 			log.Info().Msgf("Settle timeout for %v", provider)
+			aps.publishSettlementComplete(provider, hermesID, nil)
 
 			// send a signal to waiter that the settlement has timed out
 			errCh <- ErrSettleTimeout
@@ -464,12 +490,23 @@ func (aps *hermesPromiseSettler) settle(
 	if err != nil {
 		cancel()
 		log.Error().Err(err).Msgf("Could not settle promise for %v", provider)
+		aps.publishSettlementComplete(provider, hermesID, nil)
 		return err
 	}
 
 	return <-errCh
 }
 
+// publishSettlementComplete publishes the result of settlement, nil amount means the settlement failed.
+func (aps *hermesPromiseSettler) publishSettlementComplete(provider identity.Identity, hermesID common.Address, amount *big.Int) {
+	aps.publisher.Publish(event.AppTopicSettlementComplete, event.AppEventSettlementComplete{
+		HermesID:   hermesID,
+		ProviderID: provider,
+		Successful: amount != nil,
+		Amount:     amount,
+	})
+}
+
 func (aps *hermesPromiseSettler) isSettling(id identity.Identity) bool {
 	aps.lock.RLock()
 	defer aps.lock.RUnlock()
@@ -515,7 +552,8 @@ type settlementState struct {
 	registered       bool
 }
 
-func (ss settlementState) needsSettling(threshold float64, channel HermesChannel) bool {
+// canSettle returns false if the provider can't settle or has too little to settle.
+func (ss settlementState) canSettle(channel HermesChannel) bool {
 	if !ss.registered {
 		return false
 	}
@@ -524,11 +562,12 @@ func (ss settlementState) needsSettling(threshold float64, channel HermesChannel
 		return false
 	}
 
-	if channel.channel.Stake.Cmp(big.NewInt(0)) == 0 {
-		// if starting with zero stake, only settle one myst or more.
-		if channel.UnsettledBalance().Cmp(big.NewInt(0).SetUint64(crypto.Myst)) == -1 {
-			return false
-		}
+	return channel.UnsettledBalance().Cmp(minimumSettlement(channel)) >= 0
+}
+
+func (ss settlementState) needsSettling(threshold float64, channel HermesChannel) bool {
+	if !ss.canSettle(channel) {
+		return false
 	}
 
 	floated := new(big.Float).SetInt(channel.availableBalance())
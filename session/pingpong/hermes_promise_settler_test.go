@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/bindings"
 	"github.com/mysteriumnetwork/payments/client"
@@ -44,7 +45,7 @@ func TestPromiseSettler_loadInitialState(t *testing.T) {
 	}
 	ks := identity.NewMockKeystore()
 
-	settler := NewHermesPromiseSettler(&mockTransactor{}, &mockHermesChannelProvider{}, &mockProviderChannelStatusProvider{}, mrsp, ks, &settlementHistoryStorageMock{}, cfg)
+	settler := NewHermesPromiseSettler(&mockTransactor{}, &mockHermesChannelProvider{}, &mockProviderChannelStatusProvider{}, mrsp, ks, &settlementHistoryStorageMock{}, mocks.NewEventBus(), cfg)
 	settler.currentState[mockID] = settlementState{}
 
 	// check if existing gets skipped
@@ -103,7 +104,7 @@ func TestPromiseSettler_handleRegistrationEvent(t *testing.T) {
 		},
 	}
 	ks := identity.NewMockKeystore()
-	settler := NewHermesPromiseSettler(&mockTransactor{}, &mockHermesChannelProvider{}, &mockProviderChannelStatusProvider{}, mrsp, ks, &settlementHistoryStorageMock{}, cfg)
+	settler := NewHermesPromiseSettler(&mockTransactor{}, &mockHermesChannelProvider{}, &mockProviderChannelStatusProvider{}, mrsp, ks, &settlementHistoryStorageMock{}, mocks.NewEventBus(), cfg)
 
 	statusesWithNoChangeExpected := []registry.RegistrationStatus{registry.Unregistered, registry.InProgress, registry.RegistrationError}
 	for _, v := range statusesWithNoChangeExpected {
@@ -138,7 +139,7 @@ func TestPromiseSettler_handleHermesPromiseReceived(t *testing.T) {
 		},
 	}
 	ks := identity.NewMockKeystore()
-	settler := NewHermesPromiseSettler(&mockTransactor{}, channelProvider, channelStatusProvider, mrsp, ks, &settlementHistoryStorageMock{}, cfg)
+	settler := NewHermesPromiseSettler(&mockTransactor{}, channelProvider, channelStatusProvider, mrsp, ks, &settlementHistoryStorageMock{}, mocks.NewEventBus(), cfg)
 
 	// no receive on unknown provider
 	channelProvider.channelToReturn = NewHermesChannel("1", mockID, hermesID, mockProviderChannel, HermesPromise{})
@@ -220,7 +221,7 @@ func TestPromiseSettler_handleNodeStart(t *testing.T) {
 		},
 	}
 
-	settler := NewHermesPromiseSettler(&mockTransactor{}, &mockHermesChannelProvider{}, &mockProviderChannelStatusProvider{}, mrsp, ks, &settlementHistoryStorageMock{}, cfg)
+	settler := NewHermesPromiseSettler(&mockTransactor{}, &mockHermesChannelProvider{}, &mockProviderChannelStatusProvider{}, mrsp, ks, &settlementHistoryStorageMock{}, mocks.NewEventBus(), cfg)
 
 	settler.handleNodeStart()
 
@@ -356,7 +357,7 @@ type mockTransactor struct {
 	statusError    error
 }
 
-func (mt *mockTransactor) FetchSettleFees() (registry.FeesResponse, error) {
+func (mt *mockTransactor) FetchSettleFees(_ int64) (registry.FeesResponse, error) {
 	return mt.feesToReturn, mt.feesError
 }
 
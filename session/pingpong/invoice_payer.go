@@ -30,6 +30,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -61,11 +62,22 @@ type consumerTotalsStorage interface {
 	Get(chainID int64, id identity.Identity, hermesID common.Address) (*big.Int, error)
 }
 
+type consumerPaymentStateStorage interface {
+	Store(state ConsumerPaymentState) error
+	Delete(agreementID string) error
+	Latest(filter ConsumerPaymentStateFilter) (ConsumerPaymentState, error)
+}
+
 type timeTracker interface {
 	StartTracking()
 	Elapsed() time.Duration
 }
 
+type spendingLimiter interface {
+	Check(consumerID identity.Identity, sessionID string, sessionTotal, amount *big.Int) error
+	Record(consumerID identity.Identity, sessionID string, sessionTotal, amount *big.Int) error
+}
+
 type channelAddressCalculator interface {
 	GetChannelAddress(id identity.Identity) (common.Address, error)
 }
@@ -81,6 +93,14 @@ type InvoicePayer struct {
 
 	dataTransferred     DataTransferred
 	dataTransferredLock sync.Mutex
+	// consumeStatistics is kept to unsubscribe exactly this payer, as several sessions might be paid at once.
+	consumeStatistics func(e connectionstate.AppEventConnectionStatistics)
+
+	// resumedTotal is the amount already paid in the interrupted session, which agreement was continued by provider.
+	resumedTotal     *big.Int
+	resumable        *ConsumerPaymentState
+	storedAgreement  string
+	paymentStateLock sync.Mutex
 }
 
 type hashSigner interface {
@@ -92,6 +112,7 @@ type InvoicePayerDeps struct {
 	InvoiceChan               chan crypto.Invoice
 	PeerExchangeMessageSender PeerExchangeMessageSender
 	ConsumerTotalsStorage     consumerTotalsStorage
+	PaymentStateStorage       consumerPaymentStateStorage
 	TimeTracker               timeTracker
 	Ks                        hashSigner
 	Identity, Peer            identity.Identity
@@ -102,11 +123,13 @@ type InvoicePayerDeps struct {
 	HermesAddress             common.Address
 	DataLeeway                datasize.BitSize
 	ChainID                   int64
+	// SpendingLimiter is optional, it stops paying invoices once consumer spending limits are reached.
+	SpendingLimiter spendingLimiter
 }
 
 // NewInvoicePayer returns a new instance of exchange message tracker.
 func NewInvoicePayer(ipd InvoicePayerDeps) *InvoicePayer {
-	return &InvoicePayer{
+	ip := &InvoicePayer{
 		stop: make(chan struct{}),
 		deps: ipd,
 		lastInvoice: crypto.Invoice{
@@ -115,6 +138,8 @@ func NewInvoicePayer(ipd InvoicePayerDeps) *InvoicePayer {
 			TransactorFee:  new(big.Int),
 		},
 	}
+	ip.consumeStatistics = ip.consumeDataTransferredEvent
+	return ip
 }
 
 // ErrInvoiceMissmatch represents an error that occurs when invoices do not match.
@@ -131,7 +156,7 @@ func (ip *InvoicePayer) Start() error {
 
 	ip.deps.TimeTracker.StartTracking()
 
-	err = ip.deps.EventBus.Subscribe(connectionstate.AppTopicConnectionStatistics, ip.consumeDataTransferredEvent)
+	err = ip.deps.EventBus.Subscribe(connectionstate.AppTopicConnectionStatistics, ip.consumeStatistics)
 	if err != nil {
 		return errors.Wrap(err, "could not subscribe to data transfer events")
 	}
@@ -142,6 +167,8 @@ func (ip *InvoicePayer) Start() error {
 			return nil
 		case invoice := <-ip.deps.InvoiceChan:
 			log.Debug().Msgf("Invoice received: %v", invoice)
+			ip.takeOverResumed(invoice)
+
 			err := ip.isInvoiceOK(invoice)
 			if err != nil {
 				return errors.Wrap(err, "invoice not valid")
@@ -185,6 +212,9 @@ func (ip *InvoicePayer) isInvoiceOK(invoice crypto.Invoice) error {
 	estimatedTolerance := estimateInvoiceTolerance(ip.deps.TimeTracker.Elapsed(), transferred)
 
 	upperBound, _ := new(big.Float).Mul(new(big.Float).SetInt(shouldBe), big.NewFloat(estimatedTolerance)).Int(nil)
+	if ip.resumedTotal != nil {
+		upperBound.Add(upperBound, ip.resumedTotal)
+	}
 
 	log.Debug().Msgf("Estimated tolerance %.4v, upper bound %v", estimatedTolerance, upperBound)
 
@@ -255,6 +285,12 @@ func (ip *InvoicePayer) issueExchangeMessage(invoice crypto.Invoice) error {
 		return errors.Wrap(err, "could not calculate amount to promise")
 	}
 
+	if ip.deps.SpendingLimiter != nil {
+		if err := ip.deps.SpendingLimiter.Check(ip.deps.Identity, ip.getSessionID(), invoice.AgreementTotal, diff); err != nil {
+			return fmt.Errorf("could not pay invoice: %w", err)
+		}
+	}
+
 	msg, err := crypto.CreateExchangeMessage(ip.chainID(), invoice, amountToPromise, ip.channelAddress.Address, ip.deps.HermesAddress.Hex(), ip.deps.Ks, common.HexToAddress(ip.deps.Identity.Address))
 	if err != nil {
 		return errors.Wrap(err, "could not create exchange message")
@@ -267,25 +303,41 @@ func (ip *InvoicePayer) issueExchangeMessage(invoice crypto.Invoice) error {
 
 	ip.deps.EventBus.Publish(event.AppTopicInvoicePaid, event.AppEventInvoicePaid{
 		ConsumerID: ip.deps.Identity,
-		SessionID:  ip.deps.SessionID,
+		SessionID:  ip.getSessionID(),
 		Invoice:    invoice,
 	})
+	ip.storePaymentState(invoice, *msg)
 
 	// TODO: we'd probably want to check if we have enough balance here
-	err = ip.incrementGrandTotalPromised(*diff)
-	return errors.Wrap(err, "could not increment grand total")
+	if err := ip.incrementGrandTotalPromised(*diff); err != nil {
+		return errors.Wrap(err, "could not increment grand total")
+	}
+
+	// Spending is recorded only once the promise is stored, as failed invoices are paid again with the same amount.
+	if ip.deps.SpendingLimiter != nil {
+		if err := ip.deps.SpendingLimiter.Record(ip.deps.Identity, ip.getSessionID(), invoice.AgreementTotal, diff); err != nil {
+			log.Error().Err(err).Msg("Failed to record spending")
+		}
+	}
+	return nil
 }
 
 // Stop stops the message tracker.
 func (ip *InvoicePayer) Stop() {
 	ip.once.Do(func() {
 		log.Debug().Msg("Stopping...")
-		_ = ip.deps.EventBus.Unsubscribe(connectionstate.AppTopicConnectionStatistics, ip.consumeDataTransferredEvent)
+		_ = ip.deps.EventBus.Unsubscribe(connectionstate.AppTopicConnectionStatistics, ip.consumeStatistics)
+		ip.deletePaymentState()
 		close(ip.stop)
 	})
 }
 
 func (ip *InvoicePayer) consumeDataTransferredEvent(e connectionstate.AppEventConnectionStatistics) {
+	// Statistics of other simultaneous sessions must not be paid by this session.
+	if string(e.SessionInfo.SessionID) != ip.getSessionID() {
+		return
+	}
+
 	// From a server perspective, bytes up are the actual bytes the client downloaded(aka the bytes we pushed to the consumer)
 	// To lessen the confusion, I suggest having the bytes reversed on the session instance.
 	// This way, the session will show that it downloaded the bytes in a manner that is easier to comprehend.
@@ -321,5 +373,112 @@ func (ip *InvoicePayer) getDataTransferred() DataTransferred {
 
 // SetSessionID updates invoice payer dependencies to set session ID once session established.
 func (ip *InvoicePayer) SetSessionID(sessionID string) {
+	ip.dataTransferredLock.Lock()
+	defer ip.dataTransferredLock.Unlock()
+
 	ip.deps.SessionID = sessionID
 }
+
+func (ip *InvoicePayer) getSessionID() string {
+	ip.dataTransferredLock.Lock()
+	defer ip.dataTransferredLock.Unlock()
+
+	return ip.deps.SessionID
+}
+
+// ResumeOffer looks up the payment state of the session with the same provider, which was interrupted by the node restart.
+// Returned offer is sent to provider, which might continue the agreement of that session. Returns nil if nothing to resume.
+func (ip *InvoicePayer) ResumeOffer() *pb.SessionResume {
+	state, err := ip.deps.PaymentStateStorage.Latest(ip.paymentStateFilter())
+	if err != nil {
+		if err != ErrNotFound {
+			log.Warn().Err(err).Msg("Could not get payment state of the interrupted session")
+		}
+		return nil
+	}
+	if state.SessionID == "" {
+		ip.dropPaymentState(state.AgreementID)
+		return nil
+	}
+
+	ip.paymentStateLock.Lock()
+	defer ip.paymentStateLock.Unlock()
+
+	ip.resumable = &state
+	return &pb.SessionResume{
+		SessionID:   state.SessionID,
+		AgreementID: state.AgreementID,
+	}
+}
+
+// takeOverResumed checks the first invoice whether provider continued the agreement of the interrupted session.
+func (ip *InvoicePayer) takeOverResumed(invoice crypto.Invoice) {
+	ip.paymentStateLock.Lock()
+	resumable := ip.resumable
+	ip.resumable = nil
+	ip.paymentStateLock.Unlock()
+
+	if resumable == nil {
+		return
+	}
+	if invoice.AgreementID == nil || invoice.AgreementID.String() != resumable.AgreementID {
+		log.Info().Msgf("Provider started a new agreement, session %s is not resumed", resumable.SessionID)
+		ip.dropPaymentState(resumable.AgreementID)
+		return
+	}
+
+	log.Info().Msgf("Resuming session %s, already paid %v", resumable.SessionID, resumable.LastInvoice.AgreementTotal)
+	ip.lastInvoice = resumable.LastInvoice
+	ip.resumedTotal = resumable.LastInvoice.AgreementTotal
+}
+
+func (ip *InvoicePayer) storePaymentState(invoice crypto.Invoice, msg crypto.ExchangeMessage) {
+	state := ConsumerPaymentState{
+		AgreementID:     invoice.AgreementID.String(),
+		SessionID:       ip.getSessionID(),
+		ChainID:         ip.chainID(),
+		ConsumerID:      ip.deps.Identity.Address,
+		ProviderID:      ip.deps.Peer.Address,
+		HermesID:        ip.deps.HermesAddress.Hex(),
+		ServiceType:     ip.deps.Proposal.ServiceType,
+		LastInvoice:     invoice,
+		ExchangeMessage: msg,
+		UpdatedAt:       time.Now().UTC(),
+	}
+	if err := ip.deps.PaymentStateStorage.Store(state); err != nil {
+		log.Warn().Err(err).Msg("Could not store payment state")
+		return
+	}
+
+	ip.paymentStateLock.Lock()
+	defer ip.paymentStateLock.Unlock()
+
+	ip.storedAgreement = state.AgreementID
+}
+
+// deletePaymentState forgets payment state once the session is finished, it is not resumable anymore.
+func (ip *InvoicePayer) deletePaymentState() {
+	ip.paymentStateLock.Lock()
+	agreementID := ip.storedAgreement
+	ip.paymentStateLock.Unlock()
+
+	if agreementID != "" {
+		ip.dropPaymentState(agreementID)
+	}
+}
+
+func (ip *InvoicePayer) dropPaymentState(agreementID string) {
+	if err := ip.deps.PaymentStateStorage.Delete(agreementID); err != nil {
+		log.Warn().Err(err).Msgf("Could not delete payment state of agreement %s", agreementID)
+	}
+}
+
+func (ip *InvoicePayer) paymentStateFilter() ConsumerPaymentStateFilter {
+	return ConsumerPaymentStateFilter{
+		ChainID:     ip.chainID(),
+		ConsumerID:  ip.deps.Identity.Address,
+		ProviderID:  ip.deps.Peer.Address,
+		HermesID:    ip.deps.HermesAddress.Hex(),
+		ServiceType: ip.deps.Proposal.ServiceType,
+	}
+}
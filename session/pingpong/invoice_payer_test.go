@@ -18,6 +18,7 @@
 package pingpong
 
 import (
+	stdErr "errors"
 	"io/ioutil"
 	"math/big"
 	"os"
@@ -76,6 +77,7 @@ func Test_InvoicePayer_Start_Stop(t *testing.T) {
 		InvoiceChan:               invoiceChan,
 		PeerExchangeMessageSender: mockSender,
 		ConsumerTotalsStorage:     totalsStorage,
+		PaymentStateStorage:       NewConsumerPaymentStateStorage(bolt),
 		TimeTracker:               &tracker,
 		Ks:                        ks,
 		ChannelAddressCalculator:  NewChannelAddressCalculator(acc.Address.Hex(), acc.Address.Hex(), acc.Address.Hex()),
@@ -128,6 +130,7 @@ func Test_InvoicePayer_SendsMessage(t *testing.T) {
 		InvoiceChan:               invoiceChan,
 		PeerExchangeMessageSender: mockSender,
 		ConsumerTotalsStorage:     totalsStorage,
+		PaymentStateStorage:       NewConsumerPaymentStateStorage(bolt),
 		TimeTracker:               &tracker,
 		EventBus:                  mocks.NewEventBus(),
 		ChainID:                   1,
@@ -202,6 +205,7 @@ func Test_InvoicePayer_SendsMessage_OnFreeService(t *testing.T) {
 		InvoiceChan:               invoiceChan,
 		PeerExchangeMessageSender: mockSender,
 		ConsumerTotalsStorage:     totalsStorage,
+		PaymentStateStorage:       NewConsumerPaymentStateStorage(bolt),
 		TimeTracker:               &tracker,
 		EventBus:                  mocks.NewEventBus(),
 		Ks:                        ks,
@@ -265,6 +269,7 @@ func Test_InvoicePayer_BubblesErrors(t *testing.T) {
 		EventBus:                  mocks.NewEventBus(),
 		PeerExchangeMessageSender: mockSender,
 		ConsumerTotalsStorage:     totalsStorage,
+		PaymentStateStorage:       NewConsumerPaymentStateStorage(bolt),
 		TimeTracker:               &tracker,
 		Ks:                        ks,
 		ChannelAddressCalculator:  NewChannelAddressCalculator(acc.Address.Hex(), acc.Address.Hex(), acc.Address.Hex()),
@@ -591,11 +596,12 @@ func TestInvoicePayer_issueExchangeMessage_publishesEvents(t *testing.T) {
 				res: big.NewInt(0),
 				bus: mp,
 			},
-			Ks:       ks,
-			EventBus: mp,
-			Identity: identity.FromAddress(acc.Address.Hex()),
-			Peer:     peerID,
-			ChainID:  1,
+			PaymentStateStorage: &mockConsumerPaymentStateStorage{},
+			Ks:                  ks,
+			EventBus:            mp,
+			Identity:            identity.FromAddress(acc.Address.Hex()),
+			Peer:                peerID,
+			ChainID:             1,
 		},
 	}
 	emt.lastInvoice = crypto.Invoice{
@@ -755,6 +761,7 @@ func TestInvoicePayer_issueExchangeMessage(t *testing.T) {
 				deps: InvoicePayerDeps{
 					PeerExchangeMessageSender: tt.fields.peerExchangeMessageSender,
 					ConsumerTotalsStorage:     tt.fields.consumerTotalsStorage,
+					PaymentStateStorage:       &mockConsumerPaymentStateStorage{},
 					Peer:                      tt.fields.peer,
 					Ks:                        tt.fields.keystore,
 					Identity:                  tt.fields.identity,
@@ -779,6 +786,30 @@ func TestInvoicePayer_issueExchangeMessage(t *testing.T) {
 	}
 }
 
+type mockConsumerPaymentStateStorage struct {
+	states map[string]ConsumerPaymentState
+}
+
+func (m *mockConsumerPaymentStateStorage) Store(state ConsumerPaymentState) error {
+	if m.states == nil {
+		m.states = make(map[string]ConsumerPaymentState)
+	}
+	m.states[state.AgreementID] = state
+	return nil
+}
+
+func (m *mockConsumerPaymentStateStorage) Delete(agreementID string) error {
+	delete(m.states, agreementID)
+	return nil
+}
+
+func (m *mockConsumerPaymentStateStorage) Latest(filter ConsumerPaymentStateFilter) (ConsumerPaymentState, error) {
+	for _, state := range m.states {
+		return state, nil
+	}
+	return ConsumerPaymentState{}, ErrNotFound
+}
+
 type mockConsumerTotalsStorage struct {
 	res     *big.Int
 	resLock sync.Mutex
@@ -968,3 +999,173 @@ func Test_estimateInvoiceTolerance(t *testing.T) {
 		})
 	}
 }
+
+func TestInvoicePayer_ResumesInterruptedAgreement(t *testing.T) {
+	provider := identity.FromAddress("0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C")
+	storage := &mockConsumerPaymentStateStorage{}
+	assert.NoError(t, storage.Store(ConsumerPaymentState{
+		AgreementID: "7",
+		SessionID:   "interrupted",
+		ProviderID:  provider.Address,
+		LastInvoice: crypto.Invoice{
+			AgreementID:    big.NewInt(7),
+			AgreementTotal: big.NewInt(100),
+		},
+	}))
+	ip := NewInvoicePayer(InvoicePayerDeps{
+		PaymentStateStorage: storage,
+		Peer:                provider,
+	})
+
+	offer := ip.ResumeOffer()
+	assert.Equal(t, "interrupted", offer.GetSessionID())
+	assert.Equal(t, "7", offer.GetAgreementID())
+
+	ip.takeOverResumed(crypto.Invoice{AgreementID: big.NewInt(7), AgreementTotal: big.NewInt(110)})
+	assert.Equal(t, big.NewInt(100), ip.lastInvoice.AgreementTotal)
+	assert.Equal(t, big.NewInt(100), ip.resumedTotal)
+	assert.Len(t, storage.states, 1)
+}
+
+func TestInvoicePayer_ForgetsInterruptedAgreementWhenProviderStartsNewOne(t *testing.T) {
+	storage := &mockConsumerPaymentStateStorage{}
+	assert.NoError(t, storage.Store(ConsumerPaymentState{
+		AgreementID: "7",
+		SessionID:   "interrupted",
+		LastInvoice: crypto.Invoice{
+			AgreementID:    big.NewInt(7),
+			AgreementTotal: big.NewInt(100),
+		},
+	}))
+	ip := NewInvoicePayer(InvoicePayerDeps{
+		PaymentStateStorage: storage,
+	})
+
+	assert.NotNil(t, ip.ResumeOffer())
+	ip.takeOverResumed(crypto.Invoice{AgreementID: big.NewInt(8), AgreementTotal: big.NewInt(1)})
+
+	assert.Equal(t, big.NewInt(0), ip.lastInvoice.AgreementTotal)
+	assert.Nil(t, ip.resumedTotal)
+	assert.Empty(t, storage.states)
+}
+
+func TestInvoicePayer_StoresPaymentStateUntilStopped(t *testing.T) {
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(acc, ""))
+
+	storage := &mockConsumerPaymentStateStorage{}
+	ip := NewInvoicePayer(InvoicePayerDeps{
+		PeerExchangeMessageSender: &MockPeerExchangeMessageSender{
+			chanToWriteTo: make(chan crypto.ExchangeMessage, 10),
+		},
+		ConsumerTotalsStorage: &mockConsumerTotalsStorage{res: big.NewInt(0)},
+		PaymentStateStorage:   storage,
+		Ks:                    ks,
+		EventBus:              mocks.NewEventBus(),
+		Identity:              identity.FromAddress(acc.Address.Hex()),
+		Peer:                  identity.FromAddress("0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C"),
+		SessionID:             "session",
+		ChainID:               1,
+	})
+
+	err = ip.issueExchangeMessage(crypto.Invoice{
+		AgreementTotal: big.NewInt(15),
+		AgreementID:    big.NewInt(3),
+		Hashlock:       "0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C",
+		TransactorFee:  new(big.Int),
+	})
+	assert.NoError(t, err)
+
+	state, ok := storage.states["3"]
+	assert.True(t, ok)
+	assert.Equal(t, "session", state.SessionID)
+	assert.Equal(t, big.NewInt(15), state.LastInvoice.AgreementTotal)
+	assert.Equal(t, big.NewInt(15), state.ExchangeMessage.AgreementTotal)
+
+	ip.Stop()
+	assert.Empty(t, storage.states)
+}
+
+type mockSpendingLimiter struct {
+	err   error
+	spent []*big.Int
+}
+
+func (m *mockSpendingLimiter) Check(consumerID identity.Identity, sessionID string, sessionTotal, amount *big.Int) error {
+	return m.err
+}
+
+func (m *mockSpendingLimiter) Record(consumerID identity.Identity, sessionID string, sessionTotal, amount *big.Int) error {
+	m.spent = append(m.spent, amount)
+	return nil
+}
+
+func TestInvoicePayer_DoesNotPayOverSpendingLimit(t *testing.T) {
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(acc, ""))
+
+	exchangeMessages := make(chan crypto.ExchangeMessage, 10)
+	limiter := &mockSpendingLimiter{}
+	ip := NewInvoicePayer(InvoicePayerDeps{
+		PeerExchangeMessageSender: &MockPeerExchangeMessageSender{chanToWriteTo: exchangeMessages},
+		ConsumerTotalsStorage:     &mockConsumerTotalsStorage{res: big.NewInt(0)},
+		PaymentStateStorage:       &mockConsumerPaymentStateStorage{},
+		Ks:                        ks,
+		EventBus:                  mocks.NewEventBus(),
+		Identity:                  identity.FromAddress(acc.Address.Hex()),
+		Peer:                      identity.FromAddress("0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C"),
+		SessionID:                 "session",
+		ChainID:                   1,
+		SpendingLimiter:           limiter,
+	})
+	invoice := crypto.Invoice{
+		AgreementTotal: big.NewInt(15),
+		AgreementID:    big.NewInt(3),
+		Hashlock:       "0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C",
+		TransactorFee:  new(big.Int),
+	}
+
+	assert.NoError(t, ip.issueExchangeMessage(invoice))
+	assert.Equal(t, []*big.Int{big.NewInt(15)}, limiter.spent)
+	assert.Len(t, exchangeMessages, 1)
+
+	limiter.err = ErrSpendingLimitReached
+	err = ip.issueExchangeMessage(invoice)
+	assert.True(t, stdErr.Is(err, ErrSpendingLimitReached))
+	assert.Len(t, exchangeMessages, 1)
+	assert.Len(t, limiter.spent, 1)
+}
+
+func TestInvoicePayer_DoesNotRecordSpendingOfFailedPromise(t *testing.T) {
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	assert.NoError(t, err)
+
+	limiter := &mockSpendingLimiter{}
+	ip := NewInvoicePayer(InvoicePayerDeps{
+		PeerExchangeMessageSender: &MockPeerExchangeMessageSender{chanToWriteTo: make(chan crypto.ExchangeMessage, 10)},
+		ConsumerTotalsStorage:     &mockConsumerTotalsStorage{res: big.NewInt(0)},
+		PaymentStateStorage:       &mockConsumerPaymentStateStorage{},
+		Ks:                        ks,
+		EventBus:                  mocks.NewEventBus(),
+		Identity:                  identity.FromAddress(acc.Address.Hex()),
+		Peer:                      identity.FromAddress("0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C"),
+		SessionID:                 "session",
+		ChainID:                   1,
+		SpendingLimiter:           limiter,
+	})
+	invoice := crypto.Invoice{
+		AgreementTotal: big.NewInt(15),
+		AgreementID:    big.NewInt(3),
+		Hashlock:       "0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C",
+		TransactorFee:  new(big.Int),
+	}
+
+	// Account is locked, so the promise can not be signed.
+	assert.Error(t, ip.issueExchangeMessage(invoice))
+	assert.Empty(t, limiter.spent)
+}
@@ -32,6 +32,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
@@ -118,13 +119,17 @@ type InvoiceTracker struct {
 	once                           sync.Once
 	rnd                            *rand.Rand
 	agreementID                    *big.Int
-	firstInvoicePaid               bool
-	invoicesSent                   map[string]sentInvoice
-	invoiceLock                    sync.Mutex
-	deps                           InvoiceTrackerDeps
+	// agreementBase is the amount paid in the interrupted session, which agreement is resumed.
+	agreementBase    *big.Int
+	firstInvoicePaid bool
+	invoicesSent     map[string]sentInvoice
+	invoiceLock      sync.Mutex
+	deps             InvoiceTrackerDeps
 
 	dataTransferred     DataTransferred
 	dataTransferredLock sync.Mutex
+	// consumeDataTransferred is kept to unsubscribe exactly this tracker, as several sessions might be provided at once.
+	consumeDataTransferred func(e sessionEvent.AppEventDataTransferred)
 
 	criticalInvoiceErrors chan error
 	lastInvoiceSent       time.Duration
@@ -163,7 +168,7 @@ type InvoiceTrackerDeps struct {
 // NewInvoiceTracker creates a new instance of invoice tracker.
 func NewInvoiceTracker(
 	itd InvoiceTrackerDeps) *InvoiceTracker {
-	return &InvoiceTracker{
+	it := &InvoiceTracker{
 		lastExchangeMessage: crypto.ExchangeMessage{
 			Promise: crypto.Promise{
 				Amount: new(big.Int),
@@ -182,7 +187,10 @@ func NewInvoiceTracker(
 		criticalInvoiceErrors:          make(chan error),
 		invoiceChannel:                 make(chan bool),
 		invoiceDebounceRate:            time.Second * 5,
+		agreementBase:                  new(big.Int),
 	}
+	it.consumeDataTransferred = it.consumeDataTransferredEvent
+	return it
 }
 
 func calculateMaxNotReceivedExchangeMessageCount(chargeLeeway, chargePeriod time.Duration) uint64 {
@@ -276,7 +284,7 @@ func (it *InvoiceTracker) Start() error {
 	log.Debug().Msg("Starting...")
 	it.deps.TimeTracker.StartTracking()
 
-	if err := it.deps.EventBus.SubscribeAsync(sessionEvent.AppTopicDataTransferred, it.consumeDataTransferredEvent); err != nil {
+	if err := it.deps.EventBus.SubscribeAsync(sessionEvent.AppTopicDataTransferred, it.consumeDataTransferred); err != nil {
 		return err
 	}
 
@@ -290,7 +298,9 @@ func (it *InvoiceTracker) Start() error {
 		return ErrHermesFeeTooLarge
 	}
 
-	it.generateAgreementID()
+	if it.agreementID == nil {
+		it.generateAgreementID()
+	}
 
 	emErrors := make(chan error)
 	go func() {
@@ -449,6 +459,7 @@ func (it *InvoiceTracker) sendInvoice(isCritical bool) error {
 	}
 
 	shouldBe := CalculatePaymentAmount(it.deps.TimeTracker.Elapsed(), it.getDataTransferred(), it.deps.Proposal.PaymentMethod)
+	shouldBe = new(big.Int).Add(shouldBe, it.agreementBase)
 
 	lastEm := it.getLastExchangeMessage()
 	if lastEm.AgreementTotal.Cmp(big.NewInt(0)) == 0 && shouldBe.Cmp(big.NewInt(0)) == 1 {
@@ -605,11 +616,33 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 	return nil
 }
 
+// Agreement returns current agreement with the total amount paid by consumer so far.
+func (it *InvoiceTracker) Agreement() service.PaymentAgreement {
+	return service.PaymentAgreement{
+		ID:    it.agreementID,
+		Total: it.getLastExchangeMessage().AgreementTotal,
+	}
+}
+
+// Resume continues the given agreement of the interrupted session instead of starting a new one.
+// Consumer is charged only for the usage of this session on top of the already paid total. Must be called before Start.
+func (it *InvoiceTracker) Resume(agreement service.PaymentAgreement) {
+	it.agreementID = agreement.ID
+	it.agreementBase = agreement.Total
+
+	lastEm := it.getLastExchangeMessage()
+	lastEm.AgreementID = agreement.ID
+	lastEm.AgreementTotal = agreement.Total
+	it.saveLastExchangeMessage(lastEm)
+}
+
 // Stop stops the invoice tracker.
 func (it *InvoiceTracker) Stop() {
 	it.once.Do(func() {
 		log.Debug().Msg("Stopping...")
-		_ = it.deps.EventBus.Unsubscribe(sessionEvent.AppTopicDataTransferred, it.consumeDataTransferredEvent)
+		if err := it.deps.EventBus.Unsubscribe(sessionEvent.AppTopicDataTransferred, it.consumeDataTransferred); err != nil {
+			log.Warn().Err(err).Msg("Failed to unsubscribe from data transfer events")
+		}
 		close(it.stop)
 	})
 }
@@ -20,6 +20,7 @@ package noop
 import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
 )
 
 // NoopHermesPromiseSettler doesn't do much.
@@ -45,3 +46,8 @@ func (n *NoopHermesPromiseSettler) SettleWithBeneficiary(chainID int64, _ identi
 func (n *NoopHermesPromiseSettler) GetHermesFee(chainID int64, _ common.Address) (uint16, error) {
 	return 0, nil
 }
+
+// Forecast returns an empty forecast.
+func (n *NoopHermesPromiseSettler) Forecast(chainID int64, providerID identity.Identity, hermesID common.Address) (pingpong.SettlementForecast, error) {
+	return pingpong.SettlementForecast{}, nil
+}
@@ -0,0 +1,311 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// hermesFeeDenominator converts hermes fee in percentiles to fraction, 1500 means 15%.
+	hermesFeeDenominator = 10000
+	// feeRatioDenominator converts fee ratios to integers, so amounts are calculated precisely.
+	feeRatioDenominator = 1000000
+	hermesFeeValidity   = time.Hour
+
+	earningsWindow     = 24 * time.Hour
+	earningsMaxSamples = 1000
+)
+
+type settleFeesProvider interface {
+	FetchSettleFees(chainID int64) (registry.FeesResponse, error)
+}
+
+type hermesFeeProvider interface {
+	GetHermesFee(chainID int64, hermesAddress common.Address) (uint16, error)
+}
+
+// SettlementForecast projects provider earnings in a hermes channel and its next settlement.
+type SettlementForecast struct {
+	ProviderID       identity.Identity
+	HermesID         common.Address
+	UnsettledBalance *big.Int
+	// EarningsPerHour is the growth rate of unsettled balance, zero until it is observed.
+	EarningsPerHour  *big.Int
+	EarningsPerDay   *big.Int
+	EarningsPerMonth *big.Int
+	TransactorFee    *big.Int
+	HermesFee        uint16
+	// SettleAt is the unsettled balance at which the next settlement is expected.
+	SettleAt *big.Int
+	// SettlementFees is the sum of transactor and hermes fees paid for settling SettleAt.
+	SettlementFees *big.Int
+	// NextSettlement is nil if the settlement time can't be estimated, e.g. nothing is earned.
+	NextSettlement *time.Time
+}
+
+type earningsKey struct {
+	provider identity.Identity
+	hermesID common.Address
+}
+
+type earningsSample struct {
+	time     time.Time
+	lifetime *big.Int
+}
+
+type hermesFeeKey struct {
+	chainID  int64
+	hermesID common.Address
+}
+
+type cachedHermesFee struct {
+	fee        uint16
+	validUntil time.Time
+}
+
+// settlementOptimizer picks settlements which are cheap compared to the settled amount
+// and forecasts the next settlement from the unsettled balance growth rate.
+type settlementOptimizer struct {
+	transactor  settleFeesProvider
+	bc          hermesFeeProvider
+	maxFeeRatio float64
+	now         func() time.Time
+
+	lock           sync.Mutex
+	samples        map[earningsKey][]earningsSample
+	transactorFees map[int64]registry.FeesResponse
+	hermesFees     map[hermesFeeKey]cachedHermesFee
+}
+
+func newSettlementOptimizer(transactor settleFeesProvider, bc hermesFeeProvider, maxFeeRatio float64) *settlementOptimizer {
+	return &settlementOptimizer{
+		transactor:     transactor,
+		bc:             bc,
+		maxFeeRatio:    maxFeeRatio,
+		now:            time.Now,
+		samples:        make(map[earningsKey][]earningsSample),
+		transactorFees: make(map[int64]registry.FeesResponse),
+		hermesFees:     make(map[hermesFeeKey]cachedHermesFee),
+	}
+}
+
+// record samples the channel earnings. Lifetime balance is sampled instead of the unsettled one,
+// since it grows at the same rate but is not reset by settlements.
+func (so *settlementOptimizer) record(channel HermesChannel) {
+	so.lock.Lock()
+	defer so.lock.Unlock()
+
+	key := earningsKey{provider: channel.Identity, hermesID: channel.HermesID}
+	now := so.now()
+	lifetime := channel.LifetimeBalance()
+
+	samples := so.samples[key]
+	if len(samples) > 0 && samples[len(samples)-1].lifetime.Cmp(lifetime) > 0 {
+		// promises were reset, earlier samples are meaningless now
+		samples = nil
+	}
+	samples = append(samples, earningsSample{time: now, lifetime: new(big.Int).Set(lifetime)})
+
+	first := 0
+	for first < len(samples)-1 && (now.Sub(samples[first].time) > earningsWindow || len(samples)-first > earningsMaxSamples) {
+		first++
+	}
+	so.samples[key] = samples[first:]
+}
+
+// earningsRate returns the unsettled balance growth in wei per second, nil if it is not known yet.
+func (so *settlementOptimizer) earningsRate(provider identity.Identity, hermesID common.Address) *big.Float {
+	so.lock.Lock()
+	defer so.lock.Unlock()
+
+	samples := so.samples[earningsKey{provider: provider, hermesID: hermesID}]
+	if len(samples) < 2 {
+		return nil
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	elapsed := so.now().Sub(first.time).Seconds()
+	if elapsed <= 0 {
+		return nil
+	}
+	earned := new(big.Float).SetInt(new(big.Int).Sub(last.lifetime, first.lifetime))
+	return earned.Quo(earned, big.NewFloat(elapsed))
+}
+
+// fees returns the transactor settlement fee and the hermes fee, caching them while they are valid.
+func (so *settlementOptimizer) fees(chainID int64, hermesID common.Address) (*big.Int, uint16, error) {
+	so.lock.Lock()
+	defer so.lock.Unlock()
+
+	now := so.now()
+	transactorFees, ok := so.transactorFees[chainID]
+	if !ok || transactorFees.Fee == nil || now.After(transactorFees.ValidUntil) {
+		fees, err := so.transactor.FetchSettleFees(chainID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not fetch settlement fees: %w", err)
+		}
+		if fees.Fee == nil {
+			return nil, 0, fmt.Errorf("transactor returned no settlement fee")
+		}
+		so.transactorFees[chainID] = fees
+		transactorFees = fees
+	}
+
+	key := hermesFeeKey{chainID: chainID, hermesID: hermesID}
+	hermesFee, ok := so.hermesFees[key]
+	if !ok || now.After(hermesFee.validUntil) {
+		fee, err := so.bc.GetHermesFee(chainID, hermesID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not fetch hermes fee: %w", err)
+		}
+		hermesFee = cachedHermesFee{fee: fee, validUntil: now.Add(hermesFeeValidity)}
+		so.hermesFees[key] = hermesFee
+	}
+
+	return transactorFees.Fee, hermesFee.fee, nil
+}
+
+// settlementFees calculates transactor and hermes fees paid for settling the amount.
+func settlementFees(amount, transactorFee *big.Int, hermesFee uint16) *big.Int {
+	fees := new(big.Int).Mul(amount, big.NewInt(int64(hermesFee)))
+	fees.Quo(fees, big.NewInt(hermesFeeDenominator))
+	return fees.Add(fees, transactorFee)
+}
+
+// cheapestSettlement returns the smallest amount which can be settled paying at most the max fee ratio,
+// nil if the hermes fee alone exceeds it.
+func (so *settlementOptimizer) cheapestSettlement(transactorFee *big.Int, hermesFee uint16) *big.Int {
+	maxFee := int64(math.Round(so.maxFeeRatio * feeRatioDenominator))
+	ratio := maxFee - int64(hermesFee)*(feeRatioDenominator/hermesFeeDenominator)
+	if maxFee <= 0 || ratio <= 0 {
+		return nil
+	}
+
+	// transactor fee + amount * hermes fee <= amount * max fee ratio, rounding the amount up
+	amount := new(big.Int).Mul(transactorFee, big.NewInt(feeRatioDenominator))
+	amount.Add(amount, big.NewInt(ratio-1))
+	return amount.Quo(amount, big.NewInt(ratio))
+}
+
+// needsSettling returns true if the unsettled balance of the channel can be settled paying at most the max fee ratio.
+func (so *settlementOptimizer) needsSettling(chainID int64, state settlementState, channel HermesChannel) bool {
+	if so.maxFeeRatio <= 0 || !state.canSettle(channel) {
+		return false
+	}
+
+	transactorFee, hermesFee, err := so.fees(chainID, channel.HermesID)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not check settlement fees for provider %v", channel.Identity)
+		return false
+	}
+
+	amount := so.cheapestSettlement(transactorFee, hermesFee)
+	return amount != nil && channel.UnsettledBalance().Cmp(amount) >= 0
+}
+
+// forecast projects the channel earnings and the next settlement, triggered either by fees or by the balance threshold.
+func (so *settlementOptimizer) forecast(chainID int64, channel HermesChannel, threshold float64) (SettlementForecast, error) {
+	transactorFee, hermesFee, err := so.fees(chainID, channel.HermesID)
+	if err != nil {
+		return SettlementForecast{}, err
+	}
+
+	settleAt := thresholdSettlement(threshold, channel)
+	if amount := so.cheapestSettlement(transactorFee, hermesFee); amount != nil && amount.Cmp(settleAt) < 0 {
+		settleAt = amount
+	}
+	if minimum := minimumSettlement(channel); settleAt.Cmp(minimum) < 0 {
+		settleAt = minimum
+	}
+
+	forecast := SettlementForecast{
+		ProviderID:       channel.Identity,
+		HermesID:         channel.HermesID,
+		UnsettledBalance: channel.UnsettledBalance(),
+		EarningsPerHour:  new(big.Int),
+		EarningsPerDay:   new(big.Int),
+		EarningsPerMonth: new(big.Int),
+		TransactorFee:    transactorFee,
+		HermesFee:        hermesFee,
+		SettleAt:         settleAt,
+		SettlementFees:   settlementFees(settleAt, transactorFee, hermesFee),
+	}
+
+	now := so.now()
+	if forecast.UnsettledBalance.Cmp(settleAt) >= 0 {
+		forecast.NextSettlement = &now
+	}
+
+	rate := so.earningsRate(channel.Identity, channel.HermesID)
+	if rate == nil || rate.Sign() <= 0 {
+		return forecast, nil
+	}
+	forecast.EarningsPerHour = projectEarnings(rate, time.Hour)
+	forecast.EarningsPerDay = projectEarnings(rate, 24*time.Hour)
+	forecast.EarningsPerMonth = projectEarnings(rate, 30*24*time.Hour)
+
+	if forecast.NextSettlement == nil {
+		missing := new(big.Float).SetInt(new(big.Int).Sub(settleAt, forecast.UnsettledBalance))
+		seconds, _ := missing.Quo(missing, rate).Float64()
+		next := now.Add(time.Duration(seconds * float64(time.Second)))
+		forecast.NextSettlement = &next
+	}
+	return forecast, nil
+}
+
+func projectEarnings(rate *big.Float, period time.Duration) *big.Int {
+	earnings, _ := new(big.Float).Mul(rate, big.NewFloat(period.Seconds())).Int(nil)
+	return earnings
+}
+
+// thresholdSettlement returns the unsettled balance at which the channel balance threshold is crossed.
+func thresholdSettlement(threshold float64, channel HermesChannel) *big.Int {
+	available := new(big.Float).SetInt(channel.availableBalance())
+	thresholdAmount, _ := new(big.Float).Mul(big.NewFloat(threshold), available).Int(nil)
+
+	stake := new(big.Int)
+	if channel.channel.Stake != nil {
+		stake = channel.channel.Stake
+	}
+	// balance is the stake reduced by unsettled balance, it has to drop to the threshold too
+	balanceAmount := safeSub(stake, thresholdAmount)
+	if balanceAmount.Cmp(thresholdAmount) > 0 {
+		return balanceAmount
+	}
+	return thresholdAmount
+}
+
+// minimumSettlement returns the smallest unsettled balance worth settling.
+func minimumSettlement(channel HermesChannel) *big.Int {
+	if channel.channel.Stake == nil || channel.channel.Stake.Sign() == 0 {
+		// if starting with zero stake, only settle one myst or more.
+		return new(big.Int).SetUint64(crypto.Myst)
+	}
+	return new(big.Int)
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func optimizerTestChannel(stake, lifetime *big.Int) HermesChannel {
+	return NewHermesChannel("1", mockID, hermesID, client.ProviderChannel{Stake: stake, Settled: big.NewInt(0)}, HermesPromise{Promise: crypto.Promise{Amount: lifetime}})
+}
+
+func newTestOptimizer(now *time.Time) (*settlementOptimizer, *mockTransactor, *mockProviderChannelStatusProvider) {
+	transactor := &mockTransactor{feesToReturn: registry.FeesResponse{Fee: big.NewInt(1e16), ValidUntil: now.Add(time.Hour)}}
+	bc := &mockProviderChannelStatusProvider{feeToReturn: 250}
+	optimizer := newSettlementOptimizer(transactor, bc, 0.05)
+	optimizer.now = func() time.Time { return *now }
+	return optimizer, transactor, bc
+}
+
+func TestSettlementOptimizer_needsSettling(t *testing.T) {
+	now := time.Now()
+	optimizer, transactor, _ := newTestOptimizer(&now)
+	state := settlementState{registered: true}
+	stake := big.NewInt(0).Mul(big.NewInt(10), big.NewInt(1e18))
+
+	// fees are 0.01 + 2.5% of the amount, so at most 5% is paid for 0.4 or more
+	assert.False(t, optimizer.needsSettling(0, state, optimizerTestChannel(stake, big.NewInt(4e17-1))))
+	assert.True(t, optimizer.needsSettling(0, state, optimizerTestChannel(stake, big.NewInt(5e17))))
+	assert.False(t, optimizer.needsSettling(0, settlementState{registered: true, settleInProgress: true}, optimizerTestChannel(stake, big.NewInt(5e17))))
+
+	// zero stake channels settle one myst or more only
+	assert.False(t, optimizer.needsSettling(0, state, optimizerTestChannel(big.NewInt(0), big.NewInt(5e17))))
+	assert.True(t, optimizer.needsSettling(0, state, optimizerTestChannel(big.NewInt(0), big.NewInt(1e18))))
+
+	// fees are cached while valid
+	transactor.feesToReturn = registry.FeesResponse{Fee: big.NewInt(1e18), ValidUntil: now.Add(time.Hour)}
+	assert.True(t, optimizer.needsSettling(0, state, optimizerTestChannel(stake, big.NewInt(5e17))))
+	now = now.Add(2 * time.Hour)
+	assert.False(t, optimizer.needsSettling(0, state, optimizerTestChannel(stake, big.NewInt(5e17))))
+}
+
+func TestSettlementOptimizer_needsSettling_HermesFeeExceedsRatio(t *testing.T) {
+	now := time.Now()
+	optimizer, _, bc := newTestOptimizer(&now)
+	bc.feeToReturn = 600
+
+	assert.False(t, optimizer.needsSettling(0, settlementState{registered: true}, optimizerTestChannel(big.NewInt(1e18), big.NewInt(1e18))))
+}
+
+func TestSettlementOptimizer_forecast(t *testing.T) {
+	now := time.Now()
+	optimizer, _, _ := newTestOptimizer(&now)
+	stake := big.NewInt(0).Mul(big.NewInt(10), big.NewInt(1e18))
+
+	forecast, err := optimizer.forecast(0, optimizerTestChannel(stake, big.NewInt(1e16)), 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(4e17), forecast.SettleAt)
+	assert.Equal(t, big.NewInt(0), forecast.EarningsPerHour)
+	assert.Nil(t, forecast.NextSettlement)
+
+	optimizer.record(optimizerTestChannel(stake, big.NewInt(1e16)))
+	now = now.Add(time.Hour)
+	channel := optimizerTestChannel(stake, big.NewInt(37e16))
+	optimizer.record(channel)
+
+	forecast, err = optimizer.forecast(0, channel, 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(37e16), forecast.UnsettledBalance)
+	assert.Equal(t, big.NewInt(36e16), forecast.EarningsPerHour)
+	assert.Equal(t, big.NewInt(24*36e16), forecast.EarningsPerDay)
+	assert.Equal(t, big.NewInt(1e16), forecast.TransactorFee)
+	assert.Equal(t, uint16(250), forecast.HermesFee)
+	assert.Equal(t, big.NewInt(2e16), forecast.SettlementFees)
+	assert.NotNil(t, forecast.NextSettlement)
+	assert.Equal(t, now.Add(5*time.Minute), *forecast.NextSettlement)
+}
+
+func TestSettlementOptimizer_forecastWithoutFeeSettlement(t *testing.T) {
+	now := time.Now()
+	optimizer, _, _ := newTestOptimizer(&now)
+	optimizer.maxFeeRatio = 0
+
+	forecast, err := optimizer.forecast(0, optimizerTestChannel(big.NewInt(1e18), big.NewInt(95e16)), 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5e17), forecast.SettleAt)
+	assert.NotNil(t, forecast.NextSettlement)
+	assert.Equal(t, now, *forecast.NextSettlement)
+}
+
+func TestPromiseSettler_handleHermesPromiseReceived_SettlesWhenFeesAreLow(t *testing.T) {
+	transactor := &mockTransactor{feesToReturn: registry.FeesResponse{Fee: big.NewInt(1e16), ValidUntil: time.Now().Add(time.Hour)}}
+	channelProvider := &mockHermesChannelProvider{}
+	mrsp := &mockRegistrationStatusProvider{
+		identities: map[string]mockRegistrationStatus{
+			mockChainIdentity: {status: registry.Registered},
+		},
+	}
+	config := cfg
+	config.MaxFeeRatio = 0.05
+	settler := NewHermesPromiseSettler(transactor, channelProvider, &mockProviderChannelStatusProvider{}, mrsp, identity.NewMockKeystore(), &settlementHistoryStorageMock{}, mocks.NewEventBus(), config)
+	settler.currentState[mockID] = settlementState{registered: true}
+
+	// far from threshold, but fees are only 2% of the amount
+	channelProvider.channelToReturn = optimizerTestChannel(big.NewInt(0).Mul(big.NewInt(10), big.NewInt(1e18)), big.NewInt(5e17))
+	settler.handleHermesPromiseReceived(event.AppEventHermesPromise{
+		HermesID:   hermesID,
+		ProviderID: mockID,
+	})
+
+	p := <-settler.settleQueue
+	assert.Equal(t, mockID, p.provider)
+
+	// fees would be 20% of the amount
+	channelProvider.channelToReturn = optimizerTestChannel(big.NewInt(0).Mul(big.NewInt(10), big.NewInt(1e18)), big.NewInt(5e16))
+	settler.handleHermesPromiseReceived(event.AppEventHermesPromise{
+		HermesID:   hermesID,
+		ProviderID: mockID,
+	})
+	assertNoReceive(t, settler.settleQueue)
+}
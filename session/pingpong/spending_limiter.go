@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const consumerSpendingsBucketName = "consumer_spendings"
+
+// ErrSpendingLimitReached represents an error when paying the invoice would exceed the consumer spending limit.
+var ErrSpendingLimitReached = errors.New("spending limit reached")
+
+// SpendingLimits represents the maximum amounts consumer agrees to spend, nil or zero amount means no limit.
+// Days and months are counted in UTC.
+type SpendingLimits struct {
+	Session *big.Int
+	Daily   *big.Int
+	Monthly *big.Int
+	// AlertThreshold is a part of the limit, which being spent triggers an alert. Zero disables alerts.
+	AlertThreshold float64
+}
+
+func (sl SpendingLimits) limit(period event.SpendingPeriod) *big.Int {
+	switch period {
+	case event.SpendingPeriodSession:
+		return sl.Session
+	case event.SpendingPeriodDaily:
+		return sl.Daily
+	case event.SpendingPeriodMonthly:
+		return sl.Monthly
+	}
+	return nil
+}
+
+// SpendingLimiter keeps track of the amounts spent by consumer and enforces spending limits before promises are signed.
+type SpendingLimiter struct {
+	bolt persistentStorage
+	bus  eventbus.Publisher
+	now  func() time.Time
+
+	lock    sync.Mutex
+	limits  SpendingLimits
+	alerted map[string]struct{}
+}
+
+// NewSpendingLimiter creates a new instance of consumer spending limiter.
+func NewSpendingLimiter(bolt persistentStorage, bus eventbus.Publisher, limits SpendingLimits) *SpendingLimiter {
+	return &SpendingLimiter{
+		bolt:    bolt,
+		bus:     bus,
+		now:     time.Now,
+		limits:  limits,
+		alerted: make(map[string]struct{}),
+	}
+}
+
+// Limits returns current spending limits.
+func (sl *SpendingLimiter) Limits() SpendingLimits {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	return sl.limits
+}
+
+// SetLimits updates spending limits, they are applied to the next paid invoice.
+func (sl *SpendingLimiter) SetLimits(limits SpendingLimits) {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	sl.limits = limits
+	sl.alerted = make(map[string]struct{})
+}
+
+// Spent returns amounts spent during the current day and month.
+func (sl *SpendingLimiter) Spent() (daily *big.Int, monthly *big.Int, err error) {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	now := sl.now().UTC()
+	if daily, err = sl.get(dayKey(now)); err != nil {
+		return nil, nil, err
+	}
+	if monthly, err = sl.get(monthKey(now)); err != nil {
+		return nil, nil, err
+	}
+	return daily, monthly, nil
+}
+
+// Check checks whether the amount can be paid without exceeding spending limits.
+// Session total is the total amount of the session including the given amount.
+func (sl *SpendingLimiter) Check(consumerID identity.Identity, sessionID string, sessionTotal, amount *big.Int) error {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	spendings, err := sl.spendings(sessionID, sessionTotal, amount)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range spendings {
+		limit := sl.limits.limit(s.period)
+		if !limited(limit) || s.spent.Cmp(limit) <= 0 {
+			continue
+		}
+		sl.publish(consumerID, sessionID, s.period, limit, s.spent, true)
+		return fmt.Errorf("%w: %s limit %v would be exceeded by %v", ErrSpendingLimitReached, s.period, limit, s.spent)
+	}
+	return nil
+}
+
+// Record records the amount as spent, it should be called once the promise of the amount is issued.
+// Session total is the total amount of the session including the given amount.
+func (sl *SpendingLimiter) Record(consumerID identity.Identity, sessionID string, sessionTotal, amount *big.Int) error {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	spendings, err := sl.spendings(sessionID, sessionTotal, amount)
+	if err != nil {
+		return err
+	}
+
+	if err := sl.bolt.SetValue(consumerSpendingsBucketName, spendings[1].window, spendings[1].spent); err != nil {
+		return errors.Wrap(err, "could not store daily spendings")
+	}
+	if err := sl.bolt.SetValue(consumerSpendingsBucketName, spendings[2].window, spendings[2].spent); err != nil {
+		return errors.Wrap(err, "could not store monthly spendings")
+	}
+
+	for _, s := range spendings {
+		limit := sl.limits.limit(s.period)
+		if !limited(limit) || !sl.nearLimit(s.spent, limit) {
+			continue
+		}
+		alertKey := string(s.period) + s.window
+		if _, ok := sl.alerted[alertKey]; ok {
+			continue
+		}
+		sl.alerted[alertKey] = struct{}{}
+		log.Warn().Msgf("Spending %v is close to %s limit %v", s.spent, s.period, limit)
+		sl.publish(consumerID, sessionID, s.period, limit, s.spent, false)
+	}
+	return nil
+}
+
+type spending struct {
+	period event.SpendingPeriod
+	window string
+	spent  *big.Int
+}
+
+// spendings returns session, daily and monthly spendings including the given amount.
+// Should be called with the lock held.
+func (sl *SpendingLimiter) spendings(sessionID string, sessionTotal, amount *big.Int) ([]spending, error) {
+	now := sl.now().UTC()
+	spentDaily, err := sl.get(dayKey(now))
+	if err != nil {
+		return nil, err
+	}
+	spentMonthly, err := sl.get(monthKey(now))
+	if err != nil {
+		return nil, err
+	}
+
+	return []spending{
+		{event.SpendingPeriodSession, sessionID, sessionTotal},
+		{event.SpendingPeriodDaily, dayKey(now), new(big.Int).Add(spentDaily, amount)},
+		{event.SpendingPeriodMonthly, monthKey(now), new(big.Int).Add(spentMonthly, amount)},
+	}, nil
+}
+
+// nearLimit checks whether the alert threshold of the limit is reached, zero threshold disables alerts.
+func (sl *SpendingLimiter) nearLimit(spent, limit *big.Int) bool {
+	if sl.limits.AlertThreshold <= 0 {
+		return false
+	}
+	threshold, _ := new(big.Float).Mul(new(big.Float).SetInt(limit), big.NewFloat(sl.limits.AlertThreshold)).Int(nil)
+	return spent.Cmp(threshold) >= 0
+}
+
+func (sl *SpendingLimiter) publish(consumerID identity.Identity, sessionID string, period event.SpendingPeriod, limit, spent *big.Int, reached bool) {
+	go sl.bus.Publish(event.AppTopicSpendingLimit, event.AppEventSpendingLimit{
+		ConsumerID: consumerID,
+		SessionID:  sessionID,
+		Period:     period,
+		Limit:      new(big.Int).Set(limit),
+		Spent:      new(big.Int).Set(spent),
+		Reached:    reached,
+	})
+}
+
+func (sl *SpendingLimiter) get(key string) (*big.Int, error) {
+	var res = new(big.Int)
+	err := sl.bolt.GetValue(consumerSpendingsBucketName, key, &res)
+	if err != nil {
+		if err.Error() == errBoltNotFound {
+			return new(big.Int), nil
+		}
+		return nil, errors.Wrap(err, "could not get spendings")
+	}
+	return res, nil
+}
+
+func limited(limit *big.Int) bool {
+	return limit != nil && limit.Sign() > 0
+}
+
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func monthKey(t time.Time) string {
+	return t.Format("2006-01")
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendingLimiter_EnforcesLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spendingLimiterTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	consumerID := identity.FromAddress("0x1")
+	limiter := NewSpendingLimiter(bolt, mocks.NewEventBus(), SpendingLimits{
+		Session: big.NewInt(100),
+		Daily:   big.NewInt(150),
+		Monthly: big.NewInt(250),
+	})
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	assert.NoError(t, spend(limiter, consumerID, "session1", big.NewInt(100), big.NewInt(100)))
+	err = spend(limiter, consumerID, "session1", big.NewInt(101), big.NewInt(1))
+	assert.True(t, errors.Is(err, ErrSpendingLimitReached))
+
+	assert.NoError(t, spend(limiter, consumerID, "session2", big.NewInt(50), big.NewInt(50)))
+	err = spend(limiter, consumerID, "session2", big.NewInt(51), big.NewInt(1))
+	assert.True(t, errors.Is(err, ErrSpendingLimitReached), "daily limit must be enforced")
+
+	now = now.Add(24 * time.Hour)
+	assert.NoError(t, spend(limiter, consumerID, "session3", big.NewInt(100), big.NewInt(100)))
+	err = spend(limiter, consumerID, "session4", big.NewInt(1), big.NewInt(1))
+	assert.True(t, errors.Is(err, ErrSpendingLimitReached), "monthly limit must be enforced")
+
+	daily, monthly, err := limiter.Spent()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), daily)
+	assert.Equal(t, big.NewInt(250), monthly)
+
+	now = now.AddDate(0, 1, 0)
+	assert.NoError(t, spend(limiter, consumerID, "session5", big.NewInt(1), big.NewInt(1)))
+}
+
+func TestSpendingLimiter_CheckDoesNotRecordSpending(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spendingLimiterTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	consumerID := identity.FromAddress("0x1")
+	limiter := NewSpendingLimiter(bolt, mocks.NewEventBus(), SpendingLimits{Daily: big.NewInt(100)})
+
+	assert.NoError(t, limiter.Check(consumerID, "session1", big.NewInt(60), big.NewInt(60)))
+	assert.NoError(t, limiter.Check(consumerID, "session1", big.NewInt(60), big.NewInt(60)))
+
+	daily, _, err := limiter.Spent()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), daily)
+}
+
+func TestSpendingLimiter_ZeroAlertThresholdDisablesAlerts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spendingLimiterTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	bus := mocks.NewEventBus()
+	limiter := NewSpendingLimiter(bolt, bus, SpendingLimits{Daily: big.NewInt(100)})
+
+	assert.NoError(t, spend(limiter, identity.FromAddress("0x1"), "session1", big.NewInt(90), big.NewInt(90)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, bus.Pop())
+}
+
+func TestSpendingLimiter_PublishesAlerts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spendingLimiterTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	bus := mocks.NewEventBus()
+	consumerID := identity.FromAddress("0x1")
+	limiter := NewSpendingLimiter(bolt, bus, SpendingLimits{
+		Daily:          big.NewInt(100),
+		AlertThreshold: 0.8,
+	})
+
+	assert.NoError(t, spend(limiter, consumerID, "session1", big.NewInt(50), big.NewInt(50)))
+	assert.NoError(t, spend(limiter, consumerID, "session1", big.NewInt(80), big.NewInt(30)))
+	assert.Eventually(t, func() bool {
+		e, ok := bus.Pop().(event.AppEventSpendingLimit)
+		return ok && e.Period == event.SpendingPeriodDaily && !e.Reached && e.Spent.Cmp(big.NewInt(80)) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// Alert is published only once a day.
+	assert.NoError(t, spend(limiter, consumerID, "session1", big.NewInt(90), big.NewInt(10)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, bus.Pop())
+
+	assert.Error(t, spend(limiter, consumerID, "session1", big.NewInt(101), big.NewInt(11)))
+	assert.Eventually(t, func() bool {
+		e, ok := bus.Pop().(event.AppEventSpendingLimit)
+		return ok && e.Period == event.SpendingPeriodDaily && e.Reached
+	}, time.Second, 10*time.Millisecond)
+}
+
+func spend(limiter *SpendingLimiter, consumerID identity.Identity, sessionID string, sessionTotal, amount *big.Int) error {
+	if err := limiter.Check(consumerID, sessionID, sessionTotal, amount); err != nil {
+		return err
+	}
+	return limiter.Record(consumerID, sessionID, sessionTotal, amount)
+}
@@ -19,40 +19,289 @@ package client
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
 	"strings"
 
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"github.com/mysteriumnetwork/node/supervisor/protocol"
 	"github.com/rs/zerolog/log"
 )
 
-// Command executes supervisor command.
-func Command(args ...string) (result string, err error) {
-	cmdLine := strings.Join(args, " ")
-	log.Trace().Msgf("Supervisor command invoked: %q", cmdLine)
+// Client talks to the supervisor daemon using the latest protocol version supported by both sides.
+type Client struct {
+	conn     io.ReadWriteCloser
+	scanner  *bufio.Scanner
+	version  string
+	protocol int
+	lastID   uint64
+}
+
+// Connect connects to the supervisor daemon and negotiates protocol version.
+func Connect() (*Client, error) {
 	conn, err := connect()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	c := newClient(conn)
+	if err := c.negotiate(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not negotiate supervisor protocol: %w", err)
+	}
+	return c, nil
+}
+
+func newClient(conn io.ReadWriteCloser) *Client {
+	return &Client{
+		conn:    conn,
+		scanner: bufio.NewScanner(conn),
+	}
+}
+
+// Close closes connection to the daemon.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Version returns version of the daemon.
+func (c *Client) Version() string {
+	return c.version
+}
+
+// Protocol returns negotiated protocol version.
+func (c *Client) Protocol() int {
+	return c.protocol
+}
+
+// Ping checks if daemon is responding.
+func (c *Client) Ping() error {
+	return c.call(protocol.OpPing, nil, nil)
+}
+
+// Kill stops the node process.
+func (c *Client) Kill() error {
+	return c.call(protocol.OpKill, nil, nil)
+}
+
+// WgUp creates WireGuard interface owned by the given user, returns the name of the created interface.
+func (c *Client) WgUp(uid string, config wgcfg.DeviceConfig) (string, error) {
+	var result protocol.InterfaceParams
+	err := c.call(protocol.OpWgUp, protocol.WgUpParams{UID: uid, Config: config}, &result)
+	return result.Iface, err
+}
+
+// WgDown destroys WireGuard interface.
+func (c *Client) WgDown(iface string) error {
+	return c.call(protocol.OpWgDown, protocol.InterfaceParams{Iface: iface}, nil)
+}
+
+// WgStats returns statistics of WireGuard interface.
+func (c *Client) WgStats(iface string) (*wgcfg.Stats, error) {
+	var stats wgcfg.Stats
+	if err := c.call(protocol.OpWgStats, protocol.InterfaceParams{Iface: iface}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Interfaces returns names of the interfaces managed by the daemon.
+func (c *Client) Interfaces() ([]string, error) {
+	var result protocol.InterfacesResult
+	err := c.call(protocol.OpInterfaces, nil, &result)
+	return result.Interfaces, err
+}
+
+// RouteAdd routes subnet through the given interface.
+func (c *Client) RouteAdd(subnet net.IPNet, iface string) error {
+	return c.call(protocol.OpRouteAdd, protocol.RouteParams{Subnet: subnet.String(), Iface: iface}, nil)
+}
+
+// RouteExclude routes subnet around the tunnels via default gateway.
+func (c *Client) RouteExclude(subnet net.IPNet) error {
+	return c.call(protocol.OpRouteAdd, protocol.RouteParams{Subnet: subnet.String(), Exclude: true}, nil)
+}
+
+// RouteDelete deletes route of the subnet.
+func (c *Client) RouteDelete(subnet net.IPNet) error {
+	return c.call(protocol.OpRouteDelete, protocol.RouteParams{Subnet: subnet.String()}, nil)
+}
+
+// DNSSet configures DNS servers of the interface.
+func (c *Client) DNSSet(iface string, servers []string, scriptDir string) error {
+	return c.call(protocol.OpDNSSet, protocol.DNSParams{Iface: iface, Servers: servers, ScriptDir: scriptDir}, nil)
+}
+
+// DNSClean removes DNS configuration of the interface.
+func (c *Client) DNSClean(iface string) error {
+	return c.call(protocol.OpDNSClean, protocol.DNSParams{Iface: iface}, nil)
+}
+
+// FirewallBlock blocks all outgoing traffic except the tunnel traffic leaving via outbound IP, returns ID of the rule.
+func (c *Client) FirewallBlock(outboundIP string) (string, error) {
+	var result protocol.FirewallResult
+	err := c.call(protocol.OpFirewallBlock, protocol.FirewallParams{OutboundIP: outboundIP}, &result)
+	return result.Rule, err
+}
+
+// FirewallAllow excepts IP from the blocked outgoing traffic, returns ID of the rule.
+func (c *Client) FirewallAllow(ip string) (string, error) {
+	var result protocol.FirewallResult
+	err := c.call(protocol.OpFirewallAllow, protocol.FirewallParams{IP: ip}, &result)
+	return result.Rule, err
+}
+
+// FirewallRemove removes firewall rule.
+func (c *Client) FirewallRemove(rule string) error {
+	return c.call(protocol.OpFirewallRemove, protocol.FirewallParams{Rule: rule}, nil)
+}
+
+// negotiate asks for protocol v2 in the form understood by v1 daemons, which answer it with their version only.
+func (c *Client) negotiate() error {
+	line, err := c.roundTrip(protocol.OpVersion + " " + strconv.Itoa(protocol.Version2))
+	if err != nil {
+		return err
+	}
+
+	if !isResponse(line) {
+		c.protocol = protocol.Version1
+		c.version, err = parseV1Reply(line)
+		return err
+	}
+
+	var result protocol.VersionResult
+	if err := parseResponse(line, 0, &result); err != nil {
+		return err
+	}
+	c.protocol = protocol.Version2
+	c.version = result.Version
+	return nil
+}
+
+func (c *Client) call(op string, params interface{}, result interface{}) error {
+	if c.protocol < protocol.Version2 {
+		return c.callV1(op, params, result)
+	}
+
+	c.lastID++
+	req := protocol.Request{ID: c.lastID, Op: op}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("could not marshal %s params: %w", op, err)
+		}
+		req.Params = data
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("could not marshal %s request: %w", op, err)
+	}
+
+	line, err := c.roundTrip(string(data))
+	if err != nil {
+		return err
+	}
+	return parseResponse(line, req.ID, result)
+}
+
+// callV1 translates operations known by v1 daemons to text commands.
+func (c *Client) callV1(op string, params interface{}, result interface{}) error {
+	var args []string
+	switch p := params.(type) {
+	case nil:
+		if op != protocol.OpPing && op != protocol.OpKill {
+			return protocol.NewError(protocol.ErrorUnsupported, "operation %q is not supported by supervisor %s", op, c.version)
+		}
+		args = []string{op}
+	case protocol.WgUpParams:
+		config, err := json.Marshal(p.Config)
+		if err != nil {
+			return fmt.Errorf("could not marshal device config to JSON: %w", err)
+		}
+		// Config is converted to base64 to prevent nasty parsing issues on supervisor.
+		args = []string{op, "-uid", p.UID, "-config", base64.StdEncoding.EncodeToString(config)}
+	case protocol.InterfaceParams:
+		args = []string{op, "-iface", p.Iface}
+	default:
+		return protocol.NewError(protocol.ErrorUnsupported, "operation %q is not supported by supervisor %s", op, c.version)
 	}
-	defer conn.Close()
 
-	_, err = fmt.Fprintln(conn, cmdLine)
+	line, err := c.roundTrip(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	reply, err := parseV1Reply(line)
 	if err != nil {
+		return err
+	}
+
+	switch r := result.(type) {
+	case nil:
+		return nil
+	case *protocol.InterfaceParams:
+		r.Iface = reply
+		return nil
+	default:
+		if err := json.Unmarshal([]byte(reply), result); err != nil {
+			return fmt.Errorf("could not unmarshal %s result: %w", op, err)
+		}
+		return nil
+	}
+}
+
+func (c *Client) roundTrip(line string) (string, error) {
+	log.Trace().Msgf("Supervisor command invoked: %q", line)
+	if _, err := fmt.Fprintln(c.conn, line); err != nil {
 		return "", err
 	}
 
-	scanner := bufio.NewScanner(conn)
-	scanner.Scan()
-	line := string(scanner.Bytes())
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.ErrUnexpectedEOF
+	}
+	return c.scanner.Text(), nil
+}
+
+func isResponse(line string) bool {
+	return bytes.HasPrefix(bytes.TrimSpace([]byte(line)), []byte("{"))
+}
+
+func parseResponse(line string, id uint64, result interface{}) error {
+	var resp protocol.Response
+	if err := json.Unmarshal([]byte(line), &resp); err != nil {
+		return fmt.Errorf("could not parse supervisor response: %w", err)
+	}
+	if resp.ID != id {
+		return fmt.Errorf("unexpected supervisor response to request %d, expected %d", resp.ID, id)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("could not parse supervisor result: %w", err)
+	}
+	return nil
+}
+
+func parseV1Reply(line string) (string, error) {
 	parts := strings.SplitN(line, ": ", 2)
-	status := parts[0]
-	if status == "ok" {
+	if parts[0] == "ok" {
 		if len(parts) > 1 {
-			result = parts[1]
+			return parts[1], nil
 		}
-		return result, nil
+		return "", nil
 	}
 
-	message := strings.TrimPrefix(line, "error: ")
-	return "", errors.New(message)
+	return "", errors.New(strings.TrimPrefix(line, "error: "))
 }
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"github.com/mysteriumnetwork/node/supervisor/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDaemon answers the received lines with the scripted replies.
+func fakeDaemon(replies map[string]string) (c *Client, stop func()) {
+	clientConn, daemonConn := net.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(daemonConn)
+		for scanner.Scan() {
+			reply, ok := replies[scanner.Text()]
+			if !ok {
+				reply = "error: unexpected " + scanner.Text()
+			}
+			fmt.Fprintln(daemonConn, reply)
+		}
+	}()
+	return newClient(clientConn), func() {
+		clientConn.Close()
+		daemonConn.Close()
+	}
+}
+
+func TestClient_NegotiatesV2(t *testing.T) {
+	c, stop := fakeDaemon(map[string]string{
+		"version 2":                  `{"id":0,"result":{"version":"0.40.0","protocol":2}}`,
+		`{"id":1,"op":"interfaces"}`: `{"id":1,"result":{"interfaces":["myst0","myst1"]}}`,
+		`{"id":2,"op":"wg-down","params":{"iface":"wg9"}}`: `{"id":2,"error":{"code":"not-found","message":"interface not found: wg9"}}`,
+	})
+	defer stop()
+
+	assert.NoError(t, c.negotiate())
+	assert.Equal(t, protocol.Version2, c.Protocol())
+	assert.Equal(t, "0.40.0", c.Version())
+
+	interfaces, err := c.Interfaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"myst0", "myst1"}, interfaces)
+
+	err = c.WgDown("wg9")
+	var protocolErr *protocol.Error
+	assert.True(t, errors.As(err, &protocolErr))
+	assert.Equal(t, protocol.ErrorNotFound, protocolErr.Code)
+}
+
+func TestClient_FallsBackToV1(t *testing.T) {
+	c, stop := fakeDaemon(map[string]string{
+		"version 2":           "ok: 0.39.0",
+		"wg-down -iface wg0":  "ok",
+		"wg-stats -iface wg0": `ok: {"bytes_sent":10,"bytes_received":20,"last_handshake":"0001-01-01T00:00:00Z"}`,
+	})
+	defer stop()
+
+	assert.NoError(t, c.negotiate())
+	assert.Equal(t, protocol.Version1, c.Protocol())
+	assert.Equal(t, "0.39.0", c.Version())
+
+	assert.NoError(t, c.WgDown("wg0"))
+
+	stats, err := c.WgStats("wg0")
+	assert.NoError(t, err)
+	assert.Equal(t, &wgcfg.Stats{BytesSent: 10, BytesReceived: 20}, stats)
+
+	_, err = c.Interfaces()
+	var protocolErr *protocol.Error
+	assert.True(t, errors.As(err, &protocolErr))
+	assert.Equal(t, protocol.ErrorUnsupported, protocolErr.Code)
+}
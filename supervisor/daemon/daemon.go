@@ -19,6 +19,7 @@ package daemon
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -35,17 +36,22 @@ import (
 
 	"github.com/mysteriumnetwork/node/supervisor/daemon/transport"
 	"github.com/mysteriumnetwork/node/supervisor/daemon/wireguard"
+	"github.com/mysteriumnetwork/node/supervisor/protocol"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 // Daemon - supervisor process.
 type Daemon struct {
 	monitor *wireguard.Monitor
+	network *network
 }
 
 // New creates a new daemon.
 func New() Daemon {
-	return Daemon{monitor: wireguard.NewMonitor()}
+	return Daemon{
+		monitor: wireguard.NewMonitor(),
+		network: newNetwork(),
+	}
 }
 
 // Start supervisor daemon. Blocks.
@@ -62,17 +68,29 @@ func (d *Daemon) Start(options transport.Options) error {
 }
 
 // dialog talks to the client via established connection.
+// Lines holding JSON object are protocol v2 requests, protocol v1 text commands otherwise.
 func (d *Daemon) dialog(conn io.ReadWriter) {
 	scan := bufio.NewScanner(conn)
 	answer := responder{conn}
 	for scan.Scan() {
 		line := scan.Bytes()
 		log.Debug().Msgf("> %s", line)
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("{")) {
+			if done := d.handleRequest(line, &answer); done {
+				return
+			}
+			continue
+		}
+
 		cmd := strings.Split(string(line), " ")
 		op := strings.ToLower(cmd[0])
 		switch op {
 		case commandVersion:
-			answer.ok(metadata.VersionAsString())
+			if len(cmd) > 1 && negotiatesV2(cmd[1]) {
+				answer.reply(protocol.Response{Result: marshalResult(d.version())})
+			} else {
+				answer.ok(metadata.VersionAsString())
+			}
 		case commandBye:
 			answer.ok("bye")
 			return
@@ -152,9 +170,12 @@ func (d *Daemon) wgDown(args ...string) (err error) {
 		return errors.New("-iface is required")
 	}
 
-	err = d.monitor.Down(*interfaceName)
-	if err != nil {
-		return fmt.Errorf("failed to down wg interface %s: %w", *interfaceName, err)
+	return d.interfaceDown(*interfaceName)
+}
+
+func (d *Daemon) interfaceDown(interfaceName string) error {
+	if err := d.monitor.Down(interfaceName); err != nil {
+		return fmt.Errorf("failed to down wg interface %s: %w", interfaceName, err)
 	}
 
 	netutil.ClearStaleRoutes()
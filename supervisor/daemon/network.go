@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/services/wireguard/connection/dns"
+	"github.com/mysteriumnetwork/node/supervisor/protocol"
+	"github.com/mysteriumnetwork/node/utils/netutil"
+)
+
+// network changes network configuration of the host on behalf of the node and keeps track of the changes.
+type network struct {
+	mu       sync.Mutex
+	dns      map[string]dns.Manager
+	firewall firewall.OutgoingTrafficFirewall
+	rules    map[string]firewall.OutgoingRuleRemove
+	lastRule int
+}
+
+func newNetwork() *network {
+	return &network{
+		dns:   make(map[string]dns.Manager),
+		rules: make(map[string]firewall.OutgoingRuleRemove),
+	}
+}
+
+func (n *network) routeAdd(raw json.RawMessage) (interface{}, error) {
+	params, subnet, err := decodeRouteParams(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Exclude {
+		return nil, netutil.ExcludeSubnet(subnet)
+	}
+	if params.Iface == "" {
+		return nil, protocol.NewError(protocol.ErrorInvalidParams, "iface is required for not excluded route")
+	}
+	return nil, netutil.AddRoute(subnet, params.Iface)
+}
+
+func (n *network) routeDelete(raw json.RawMessage) (interface{}, error) {
+	_, subnet, err := decodeRouteParams(raw)
+	if err != nil {
+		return nil, err
+	}
+	return nil, netutil.DeleteRoute(subnet)
+}
+
+func (n *network) dnsSet(raw json.RawMessage) (interface{}, error) {
+	var params protocol.DNSParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.Iface == "" {
+		return nil, protocol.NewError(protocol.ErrorInvalidParams, "iface is required")
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	manager, ok := n.dns[params.Iface]
+	if ok {
+		if err := manager.Clean(); err != nil {
+			return nil, err
+		}
+	} else {
+		manager = dns.NewManager()
+		n.dns[params.Iface] = manager
+	}
+
+	return nil, manager.Set(dns.Config{
+		ScriptDir: params.ScriptDir,
+		IfaceName: params.Iface,
+		DNS:       params.Servers,
+	})
+}
+
+func (n *network) dnsClean(raw json.RawMessage) (interface{}, error) {
+	var params protocol.DNSParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	manager, ok := n.dns[params.Iface]
+	if !ok {
+		return nil, protocol.NewError(protocol.ErrorNotFound, "DNS of interface %q is not configured", params.Iface)
+	}
+	delete(n.dns, params.Iface)
+	return nil, manager.Clean()
+}
+
+func (n *network) firewallBlock(raw json.RawMessage) (interface{}, error) {
+	var params protocol.FirewallParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	return n.addRule(func(fw firewall.OutgoingTrafficFirewall) (firewall.OutgoingRuleRemove, error) {
+		return fw.BlockOutgoingTraffic(firewall.Session, params.OutboundIP)
+	})
+}
+
+func (n *network) firewallAllow(raw json.RawMessage) (interface{}, error) {
+	var params protocol.FirewallParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if net.ParseIP(params.IP) == nil {
+		return nil, protocol.NewError(protocol.ErrorInvalidParams, "invalid ip %q", params.IP)
+	}
+
+	return n.addRule(func(fw firewall.OutgoingTrafficFirewall) (firewall.OutgoingRuleRemove, error) {
+		return fw.AllowIPAccess(params.IP)
+	})
+}
+
+func (n *network) firewallRemove(raw json.RawMessage) (interface{}, error) {
+	var params protocol.FirewallParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	remove, ok := n.rules[params.Rule]
+	if !ok {
+		return nil, protocol.NewError(protocol.ErrorNotFound, "firewall rule %q not found", params.Rule)
+	}
+	delete(n.rules, params.Rule)
+	remove()
+	return nil, nil
+}
+
+func (n *network) addRule(add func(fw firewall.OutgoingTrafficFirewall) (firewall.OutgoingRuleRemove, error)) (interface{}, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// Firewall is set up on the first use, as most of the nodes never enable the kill switch.
+	if n.firewall == nil {
+		fw := firewall.NewOutgoingTrafficFirewall(true)
+		if err := fw.Setup(); err != nil {
+			return nil, err
+		}
+		n.firewall = fw
+	}
+
+	remove, err := add(n.firewall)
+	if err != nil {
+		return nil, err
+	}
+
+	n.lastRule++
+	rule := strconv.Itoa(n.lastRule)
+	n.rules[rule] = remove
+	return protocol.FirewallResult{Rule: rule}, nil
+}
+
+func decodeRouteParams(raw json.RawMessage) (protocol.RouteParams, net.IPNet, error) {
+	var params protocol.RouteParams
+	if err := decodeParams(raw, &params); err != nil {
+		return params, net.IPNet{}, err
+	}
+
+	_, subnet, err := net.ParseCIDR(params.Subnet)
+	if err != nil {
+		return params, net.IPNet{}, protocol.NewError(protocol.ErrorInvalidParams, "invalid subnet %q: %v", params.Subnet, err)
+	}
+	return params, *subnet, nil
+}
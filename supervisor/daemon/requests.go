@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/supervisor/daemon/wireguard"
+	"github.com/mysteriumnetwork/node/supervisor/protocol"
+	"github.com/rs/zerolog/log"
+)
+
+type operation func(params json.RawMessage) (interface{}, error)
+
+// handleRequest handles protocol v2 request, returns true when client ends the dialog.
+func (d *Daemon) handleRequest(line []byte, answer *responder) (done bool) {
+	var req protocol.Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		answer.reply(protocol.Response{Error: protocol.NewError(protocol.ErrorInvalidRequest, "could not parse request: %v", err)})
+		return false
+	}
+
+	resp := protocol.Response{ID: req.ID}
+	result, err := d.execute(req.Op, req.Params)
+	if err != nil {
+		log.Err(err).Msgf("%s failed", req.Op)
+		resp.Error = toProtocolError(err)
+	} else if result != nil {
+		resp.Result = marshalResult(result)
+	}
+	answer.reply(resp)
+
+	return req.Op == protocol.OpBye
+}
+
+func (d *Daemon) execute(op string, params json.RawMessage) (interface{}, error) {
+	operations := map[string]operation{
+		protocol.OpVersion: func(json.RawMessage) (interface{}, error) {
+			return d.version(), nil
+		},
+		protocol.OpPing: func(json.RawMessage) (interface{}, error) {
+			return "pong", nil
+		},
+		protocol.OpBye: func(json.RawMessage) (interface{}, error) {
+			return "bye", nil
+		},
+		protocol.OpKill: func(json.RawMessage) (interface{}, error) {
+			return nil, d.killMyst()
+		},
+		protocol.OpWgUp:           d.opWgUp,
+		protocol.OpWgDown:         d.opWgDown,
+		protocol.OpWgStats:        d.opWgStats,
+		protocol.OpInterfaces:     d.opInterfaces,
+		protocol.OpRouteAdd:       d.network.routeAdd,
+		protocol.OpRouteDelete:    d.network.routeDelete,
+		protocol.OpDNSSet:         d.network.dnsSet,
+		protocol.OpDNSClean:       d.network.dnsClean,
+		protocol.OpFirewallBlock:  d.network.firewallBlock,
+		protocol.OpFirewallAllow:  d.network.firewallAllow,
+		protocol.OpFirewallRemove: d.network.firewallRemove,
+	}
+
+	execute, ok := operations[op]
+	if !ok {
+		return nil, protocol.NewError(protocol.ErrorUnknownOperation, "unknown operation %q", op)
+	}
+	return execute(params)
+}
+
+func (d *Daemon) version() protocol.VersionResult {
+	return protocol.VersionResult{
+		Version:  metadata.VersionAsString(),
+		Protocol: protocol.Version2,
+	}
+}
+
+func (d *Daemon) opWgUp(raw json.RawMessage) (interface{}, error) {
+	var params protocol.WgUpParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.UID == "" {
+		return nil, protocol.NewError(protocol.ErrorInvalidParams, "uid is required")
+	}
+
+	iface, err := d.monitor.Up(params.Config, params.UID)
+	if err != nil {
+		return nil, err
+	}
+	return protocol.InterfaceParams{Iface: iface}, nil
+}
+
+func (d *Daemon) opWgDown(raw json.RawMessage) (interface{}, error) {
+	var params protocol.InterfaceParams
+	if err := decodeInterfaceParams(raw, &params); err != nil {
+		return nil, err
+	}
+	return nil, d.interfaceDown(params.Iface)
+}
+
+func (d *Daemon) opWgStats(raw json.RawMessage) (interface{}, error) {
+	var params protocol.InterfaceParams
+	if err := decodeInterfaceParams(raw, &params); err != nil {
+		return nil, err
+	}
+	return d.monitor.Stats(params.Iface)
+}
+
+func (d *Daemon) opInterfaces(json.RawMessage) (interface{}, error) {
+	return protocol.InterfacesResult{Interfaces: d.monitor.Interfaces()}, nil
+}
+
+func decodeParams(raw json.RawMessage, params interface{}) error {
+	if len(raw) == 0 {
+		return protocol.NewError(protocol.ErrorInvalidParams, "params are required")
+	}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return protocol.NewError(protocol.ErrorInvalidParams, "could not parse params: %v", err)
+	}
+	return nil
+}
+
+func decodeInterfaceParams(raw json.RawMessage, params *protocol.InterfaceParams) error {
+	if err := decodeParams(raw, params); err != nil {
+		return err
+	}
+	if params.Iface == "" {
+		return protocol.NewError(protocol.ErrorInvalidParams, "iface is required")
+	}
+	return nil
+}
+
+func toProtocolError(err error) *protocol.Error {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) {
+		return protocolErr
+	}
+	if errors.Is(err, wireguard.ErrInterfaceNotFound) {
+		return protocol.NewError(protocol.ErrorNotFound, "%v", err)
+	}
+	return protocol.NewError(protocol.ErrorFailed, "%v", err)
+}
+
+// negotiatesV2 checks if version command argument asks for protocol v2 or later.
+func negotiatesV2(arg string) bool {
+	version, err := strconv.Atoi(arg)
+	return err == nil && version >= protocol.Version2
+}
+
+func marshalResult(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Err(err).Msgf("Could not marshal %T", v)
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package daemon
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemon_Dialog(t *testing.T) {
+	d := New()
+	clientConn, daemonConn := net.Pipe()
+	defer clientConn.Close()
+	go func() {
+		d.dialog(daemonConn)
+		daemonConn.Close()
+	}()
+
+	scanner := bufio.NewScanner(clientConn)
+	ask := func(line string) string {
+		fmt.Fprintln(clientConn, line)
+		scanner.Scan()
+		return scanner.Text()
+	}
+
+	// Protocol v1 clients keep working.
+	assert.Equal(t, "ok: "+metadata.VersionAsString(), ask("version"))
+	assert.Equal(t, "ok: pong", ask("ping"))
+
+	// Protocol v2 is negotiated on version.
+	assert.Equal(t,
+		fmt.Sprintf(`{"id":0,"result":{"version":"%s","protocol":2}}`, metadata.VersionAsString()),
+		ask("version 2"),
+	)
+	assert.Equal(t, `{"id":1,"result":"pong"}`, ask(`{"id":1,"op":"ping"}`))
+	assert.Equal(t, `{"id":2,"result":{"interfaces":[]}}`, ask(`{"id":2,"op":"interfaces"}`))
+	assert.Equal(t,
+		`{"id":3,"error":{"code":"unknown-operation","message":"unknown operation \"reboot\""}}`,
+		ask(`{"id":3,"op":"reboot"}`),
+	)
+	assert.Equal(t,
+		`{"id":4,"error":{"code":"invalid-params","message":"iface is required"}}`,
+		ask(`{"id":4,"op":"wg-stats","params":{}}`),
+	)
+	assert.Equal(t,
+		`{"id":5,"error":{"code":"not-found","message":"failed to down wg interface wg9: interface not found: wg9"}}`,
+		ask(`{"id":5,"op":"wg-down","params":{"iface":"wg9"}}`),
+	)
+	assert.Equal(t,
+		`{"id":6,"error":{"code":"invalid-params","message":"invalid subnet \"10.0.0.1\": invalid CIDR address: 10.0.0.1"}}`,
+		ask(`{"id":6,"op":"route-add","params":{"subnet":"10.0.0.1"}}`),
+	)
+	assert.Equal(t,
+		`{"id":7,"error":{"code":"not-found","message":"firewall rule \"1\" not found"}}`,
+		ask(`{"id":7,"op":"firewall-remove","params":{"rule":"1"}}`),
+	)
+	assert.Equal(t,
+		`{"id":0,"error":{"code":"invalid-request","message":"could not parse request: unexpected end of JSON input"}}`,
+		ask(`{"id":8`),
+	)
+	assert.Equal(t, `{"id":9,"result":"bye"}`, ask(`{"id":9,"op":"bye"}`))
+}
@@ -18,10 +18,13 @@
 package daemon
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/mysteriumnetwork/node/supervisor/protocol"
+
 	"github.com/rs/zerolog/log"
 )
 
@@ -43,6 +46,15 @@ func (r *responder) err(result ...error) {
 	r.message(strings.Join(args, ": "))
 }
 
+func (r *responder) reply(resp protocol.Response) {
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		log.Err(err).Msgf("Could not marshal response to request %d", resp.ID)
+		return
+	}
+	r.message(string(msg))
+}
+
 func (r *responder) message(msg string) {
 	log.Debug().Msgf("< %s", msg)
 	if _, err := fmt.Fprintln(r, msg); err != nil {
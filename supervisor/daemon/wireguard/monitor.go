@@ -18,7 +18,9 @@
 package wireguard
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/userspace"
@@ -26,6 +28,9 @@ import (
 	"github.com/mysteriumnetwork/node/supervisor/daemon/wireguard/wginterface"
 )
 
+// ErrInterfaceNotFound is returned when interface is not managed by the monitor.
+var ErrInterfaceNotFound = errors.New("interface not found")
+
 // Monitor creates/deletes the WireGuard interfaces and keeps track of them.
 type Monitor struct {
 	interfaces map[string]*wginterface.WgInterface
@@ -62,7 +67,7 @@ func (m *Monitor) Down(interfaceName string) error {
 
 	iface, ok := m.interfaces[interfaceName]
 	if !ok {
-		return fmt.Errorf("interface %s not found", interfaceName)
+		return fmt.Errorf("%w: %s", ErrInterfaceNotFound, interfaceName)
 	}
 
 	iface.Down()
@@ -77,7 +82,7 @@ func (m *Monitor) Stats(interfaceName string) (*wgcfg.Stats, error) {
 
 	iface, ok := m.interfaces[interfaceName]
 	if !ok {
-		return nil, fmt.Errorf("interface %s not found", interfaceName)
+		return nil, fmt.Errorf("%w: %s", ErrInterfaceNotFound, interfaceName)
 	}
 
 	deviceState, err := userspace.ParseUserspaceDevice(iface.Device.IpcGetOperation)
@@ -90,3 +95,16 @@ func (m *Monitor) Stats(interfaceName string) (*wgcfg.Stats, error) {
 	}
 	return stats, nil
 }
+
+// Interfaces returns names of the managed interfaces.
+func (m *Monitor) Interfaces() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.interfaces))
+	for name := range m.interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -129,11 +129,8 @@ func configureNetwork(cfg wgcfg.DeviceConfig, dnsManager dns.Manager) error {
 	}
 
 	if cfg.Peer.Endpoint != nil {
-		if err := netutil.ExcludeRoute(cfg.Peer.Endpoint.IP); err != nil {
-			return fmt.Errorf("could not exclude route %s: %w", cfg.Peer.Endpoint.IP.String(), err)
-		}
-		if err := netutil.AddDefaultRoute(cfg.IfaceName); err != nil {
-			return fmt.Errorf("could not add default route for %s: %w", cfg.IfaceName, err)
+		if err := netutil.ConfigureRoutes(cfg.IfaceName, cfg.Peer.Endpoint.IP, cfg.IncludeRoutes, cfg.ExcludeRoutes); err != nil {
+			return err
 		}
 	}
 
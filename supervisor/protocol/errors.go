@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package protocol
+
+import "fmt"
+
+// ErrorCode classifies errors returned by the daemon.
+type ErrorCode string
+
+const (
+	// ErrorUnknownOperation is returned when daemon does not know the operation.
+	ErrorUnknownOperation ErrorCode = "unknown-operation"
+	// ErrorInvalidRequest is returned when request could not be parsed.
+	ErrorInvalidRequest ErrorCode = "invalid-request"
+	// ErrorInvalidParams is returned when parameters of the operation are missing or malformed.
+	ErrorInvalidParams ErrorCode = "invalid-params"
+	// ErrorNotFound is returned when the object of the operation does not exist.
+	ErrorNotFound ErrorCode = "not-found"
+	// ErrorFailed is returned when the operation failed.
+	ErrorFailed ErrorCode = "failed"
+	// ErrorUnsupported is returned when the operation is not supported by the negotiated protocol version.
+	ErrorUnsupported ErrorCode = "unsupported"
+)
+
+// Error is an error returned by the daemon.
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// NewError creates error of the given code.
+func NewError(code ErrorCode, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Error implements error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
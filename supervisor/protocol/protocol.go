@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package protocol describes the versioned protocol spoken between the node and the supervisor daemon.
+//
+// Protocol v1 is a line of space separated text command answered with "ok: <result>" or "error: <message>".
+// Protocol v2 is a line of JSON encoded Request answered with a line of JSON encoded Response carrying the same ID.
+// Client negotiates the protocol by sending v1 "version 2" command: daemons speaking v2 answer it with a Response
+// holding VersionResult, while v1 daemons answer with a plain text "ok: <version>".
+package protocol
+
+import (
+	"encoding/json"
+
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+)
+
+// Protocol versions.
+const (
+	Version1 = 1
+	Version2 = 2
+)
+
+// Operations supported by the daemon.
+const (
+	OpVersion        = "version"
+	OpPing           = "ping"
+	OpKill           = "kill"
+	OpBye            = "bye"
+	OpWgUp           = "wg-up"
+	OpWgDown         = "wg-down"
+	OpWgStats        = "wg-stats"
+	OpInterfaces     = "interfaces"
+	OpRouteAdd       = "route-add"
+	OpRouteDelete    = "route-delete"
+	OpDNSSet         = "dns-set"
+	OpDNSClean       = "dns-clean"
+	OpFirewallBlock  = "firewall-block"
+	OpFirewallAllow  = "firewall-allow"
+	OpFirewallRemove = "firewall-remove"
+)
+
+// Request is a protocol v2 request.
+type Request struct {
+	ID     uint64          `json:"id"`
+	Op     string          `json:"op"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a protocol v2 response to the request with the same ID.
+type Response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// VersionResult is a result of the version operation.
+type VersionResult struct {
+	Version  string `json:"version"`
+	Protocol int    `json:"protocol"`
+}
+
+// WgUpParams are parameters of the wg-up operation.
+type WgUpParams struct {
+	// UID is the user ID, decimal number on POSIX systems and security identifier (SID) on Windows.
+	UID    string             `json:"uid"`
+	Config wgcfg.DeviceConfig `json:"config"`
+}
+
+// InterfaceParams are parameters of the operations on a single interface.
+type InterfaceParams struct {
+	Iface string `json:"iface"`
+}
+
+// InterfacesResult is a result of the interfaces operation.
+type InterfacesResult struct {
+	Interfaces []string `json:"interfaces"`
+}
+
+// RouteParams are parameters of the route operations. Subnet is routed through the interface,
+// or around all tunnels via default gateway when excluded.
+type RouteParams struct {
+	Subnet  string `json:"subnet"`
+	Iface   string `json:"iface,omitempty"`
+	Exclude bool   `json:"exclude,omitempty"`
+}
+
+// DNSParams are parameters of the DNS operations.
+type DNSParams struct {
+	Iface   string   `json:"iface"`
+	Servers []string `json:"servers,omitempty"`
+	// ScriptDir is a directory of the scripts updating DNS configuration, used only on unix.
+	ScriptDir string `json:"script_dir,omitempty"`
+}
+
+// FirewallParams are parameters of the kill switch operations.
+type FirewallParams struct {
+	// OutboundIP is the IP left to be used by the tunnel when all other traffic is blocked.
+	OutboundIP string `json:"outbound_ip,omitempty"`
+	// IP is the address excepted from the blocked traffic.
+	IP string `json:"ip,omitempty"`
+	// Rule is the ID of the rule to remove.
+	Rule string `json:"rule,omitempty"`
+}
+
+// FirewallResult is a result of the kill switch operations adding a rule.
+type FirewallResult struct {
+	Rule string `json:"rule"`
+}
@@ -19,6 +19,7 @@ package client
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"net/http"
 	"net/url"
@@ -313,6 +314,17 @@ func (client *Client) ProposalsByType(serviceType string) ([]contract.ProposalDT
 	return client.proposals(queryParams)
 }
 
+// ProposalsByScore returns proposals for the given service type from the best to the worst
+func (client *Client) ProposalsByScore(serviceType, preferredCountry string) ([]contract.ProposalDTO, error) {
+	queryParams := url.Values{}
+	queryParams.Add("service_type", serviceType)
+	queryParams.Add("sort", "score")
+	if preferredCountry != "" {
+		queryParams.Add("preferred_country", preferredCountry)
+	}
+	return client.proposals(queryParams)
+}
+
 // Proposals returns all available proposals for services
 func (client *Client) Proposals() ([]contract.ProposalDTO, error) {
 	return client.proposals(url.Values{})
@@ -398,6 +410,29 @@ func (client *Client) Sessions() (sessions contract.SessionListResponse, err err
 	return sessions, err
 }
 
+// Ledger returns page of ledger entries filtered by the given query
+func (client *Client) Ledger(query url.Values) (entries contract.LedgerListResponse, err error) {
+	response, err := client.http.Get("ledger", query)
+	if err != nil {
+		return entries, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &entries)
+	return entries, err
+}
+
+// LedgerExport returns all ledger entries filtered by the given query, formatted as CSV or JSON
+func (client *Client) LedgerExport(query url.Values) ([]byte, error) {
+	response, err := client.http.Get("ledger/export", query)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return ioutil.ReadAll(response.Body)
+}
+
 // SessionsByServiceType returns sessions from history filtered by type
 func (client *Client) SessionsByServiceType(serviceType string) (contract.SessionListResponse, error) {
 	sessions, err := client.Sessions()
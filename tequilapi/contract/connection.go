@@ -37,6 +37,7 @@ func NewConnectionInfoDTO(session connectionstate.Status) ConnectionInfoDTO {
 		Status:     string(session.State),
 		ConsumerID: session.ConsumerID.Address,
 		SessionID:  string(session.SessionID),
+		ID:         session.ConnectionID,
 	}
 	if session.HermesID != emptyAddress {
 		response.HermesID = session.HermesID.Hex()
@@ -52,6 +53,10 @@ func NewConnectionInfoDTO(session connectionstate.Status) ConnectionInfoDTO {
 // ConnectionInfoDTO holds partial consumer connection details.
 // swagger:model ConnectionInfoDTO
 type ConnectionInfoDTO struct {
+	// ID of additional connection, empty for the default one
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	ID string `json:"id,omitempty"`
+
 	// example: Connected
 	Status string `json:"status"`
 
@@ -87,6 +92,12 @@ type ConnectionDTO struct {
 	Statistics *ConnectionStatisticsDTO `json:"statistics,omitempty"`
 }
 
+// ConnectionListDTO holds additional consumer connections.
+// swagger:model ConnectionListDTO
+type ConnectionListDTO struct {
+	Connections []ConnectionDTO `json:"connections"`
+}
+
 // NewConnectionStatisticsDTO maps to API connection stats.
 func NewConnectionStatisticsDTO(session connectionstate.Status, statistics connectionstate.Statistics, throughput bandwidth.Throughput, invoice crypto.Invoice) ConnectionStatisticsDTO {
 	agreementTotal := new(big.Int)
@@ -165,6 +176,13 @@ func (cr ConnectionCreateRequest) Validate() *validation.FieldErrorMap {
 	if len(cr.ProviderID) == 0 {
 		errs.ForField("provider_id").AddError("required", "Field is required")
 	}
+	if splitTunnel := cr.ConnectOptions.SplitTunnel; splitTunnel != nil {
+		if err := splitTunnel.ToSplitTunnel().Validate(); err != nil {
+			errs.ForField("split_tunnel").AddError("invalid", err.Error())
+		} else if len(splitTunnel.Include) > 0 && !cr.ConnectOptions.DisableKillSwitch {
+			errs.ForField("split_tunnel").AddError("invalid", connection.ErrSplitTunnelKillSwitch.Error())
+		}
+	}
 	return errs
 }
 
@@ -180,4 +198,55 @@ type ConnectOptions struct {
 	// default: auto
 	// example: auto, provider, system, "1.1.1.1,8.8.8.8"
 	DNS connection.DNSOption `json:"dns"`
+	// filter of proposals to fail over to when connection with provider is lost, failover is disabled when not set
+	// required: false
+	Failover *ConnectFailoverOptions `json:"failover,omitempty"`
+	// destinations routed through the tunnel or around it, all traffic is routed through the tunnel when not set
+	// required: false
+	SplitTunnel *SplitTunnelOptions `json:"split_tunnel,omitempty"`
+}
+
+// SplitTunnelOptions holds destinations given as IPv4 CIDRs, IPv4 addresses or domain names.
+// Domain names are resolved to their IPv4 addresses once, using system DNS servers, when connecting.
+// Routes are not updated later, so addresses the domain moves to (e.g. behind CDNs) are not covered
+// until the next connection.
+// swagger:model SplitTunnelOptionsDTO
+type SplitTunnelOptions struct {
+	// destinations routed through the tunnel, all other traffic is routed around it (requires disabled kill switch)
+	// required: false
+	// example: ["10.0.0.0/8", "example.com"]
+	Include []string `json:"include,omitempty"`
+	// destinations routed around the tunnel
+	// required: false
+	// example: ["192.168.1.0/24", "1.2.3.4", "example.org"]
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// ToSplitTunnel converts options to the split tunnel of connection.
+func (o *SplitTunnelOptions) ToSplitTunnel() connection.SplitTunnel {
+	if o == nil {
+		return connection.SplitTunnel{}
+	}
+	return connection.SplitTunnel{Include: o.Include, Exclude: o.Exclude}
+}
+
+// ConnectFailoverOptions holds filter of proposals to fail over to, proposals of the connected service type are used
+// swagger:model ConnectFailoverOptionsDTO
+type ConnectFailoverOptions struct {
+	// location type of the proposals
+	// required: false
+	// example: residential
+	LocationType string `json:"location_type,omitempty"`
+	// access policy id of the proposals
+	// required: false
+	AccessPolicyID string `json:"access_policy_id,omitempty"`
+	// access policy source of the proposals
+	// required: false
+	AccessPolicySource string `json:"access_policy_source,omitempty"`
+	// upper bound of the price per minute
+	// required: false
+	UpperTimePriceBound *big.Int `json:"upper_time_price_bound,omitempty"`
+	// upper bound of the price per GiB
+	// required: false
+	UpperGBPriceBound *big.Int `json:"upper_gb_price_bound,omitempty"`
 }
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectiontrace"
+)
+
+// NewConnectionTraceListResponse maps to API connection trace list.
+func NewConnectionTraceListResponse(recordings []connectiontrace.Recording) ConnectionTraceListResponse {
+	items := make([]ConnectionTraceDTO, len(recordings))
+	for i, recording := range recordings {
+		items[i] = NewConnectionTraceDTO(recording)
+	}
+	return ConnectionTraceListResponse{Items: items}
+}
+
+// ConnectionTraceListResponse defines connection trace list representable as json.
+// swagger:model ConnectionTraceListResponse
+type ConnectionTraceListResponse struct {
+	Items []ConnectionTraceDTO `json:"items"`
+}
+
+// NewConnectionTraceDTO maps to API connection trace.
+func NewConnectionTraceDTO(recording connectiontrace.Recording) ConnectionTraceDTO {
+	return ConnectionTraceDTO{
+		ID:           recording.ID,
+		ConnectionID: recording.ConnectionID,
+		SessionID:    string(recording.SessionID),
+		ConsumerID:   recording.ConsumerID,
+		ProviderID:   recording.ProviderID,
+		ServiceType:  recording.ServiceType,
+		StartedAt:    recording.StartedAt.Format(time.RFC3339),
+	}
+}
+
+// ConnectionTraceDTO represents the recorded connection trace without its entries.
+// swagger:model ConnectionTraceDTO
+type ConnectionTraceDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	ID string `json:"id"`
+
+	// empty for the default connection
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	ConnectionID string `json:"connection_id,omitempty"`
+
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id,omitempty"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id,omitempty"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id,omitempty"`
+
+	// example: wireguard
+	ServiceType string `json:"service_type,omitempty"`
+
+	// example: 2019-06-06T11:04:43Z
+	StartedAt string `json:"started_at"`
+}
@@ -19,8 +19,11 @@ package contract
 
 import (
 	"math/big"
+	"sort"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
+	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/validation"
 )
 
@@ -46,6 +49,59 @@ type IdentityDTO struct {
 	Earnings           *big.Int `json:"earnings"`
 	EarningsTotal      *big.Int `json:"earnings_total"`
 	Stake              *big.Int `json:"stake"`
+	// balances and earnings in every hermes identity has a channel with
+	Hermeses []IdentityHermesDTO `json:"hermeses"`
+}
+
+// IdentityHermesDTO holds identity balance and earnings in a single hermes.
+// swagger:model IdentityHermesDTO
+type IdentityHermesDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID      string   `json:"hermes_id"`
+	Balance       *big.Int `json:"balance"`
+	Earnings      *big.Int `json:"earnings"`
+	EarningsTotal *big.Int `json:"earnings_total"`
+}
+
+// NewIdentityHermesDTOs maps identity balances and earnings of every hermes, ordered by hermes ID.
+func NewIdentityHermesDTOs(balances map[common.Address]*big.Int, earnings map[common.Address]pingpong_event.Earnings) []IdentityHermesDTO {
+	hermeses := make(map[common.Address]*IdentityHermesDTO)
+	get := func(hermesID common.Address) *IdentityHermesDTO {
+		if dto, ok := hermeses[hermesID]; ok {
+			return dto
+		}
+		dto := &IdentityHermesDTO{
+			HermesID:      hermesID.Hex(),
+			Balance:       new(big.Int),
+			Earnings:      new(big.Int),
+			EarningsTotal: new(big.Int),
+		}
+		hermeses[hermesID] = dto
+		return dto
+	}
+	for hermesID, balance := range balances {
+		if balance != nil {
+			get(hermesID).Balance = balance
+		}
+	}
+	for hermesID, e := range earnings {
+		dto := get(hermesID)
+		if e.UnsettledBalance != nil {
+			dto.Earnings = e.UnsettledBalance
+		}
+		if e.LifetimeBalance != nil {
+			dto.EarningsTotal = e.LifetimeBalance
+		}
+	}
+
+	result := make([]IdentityHermesDTO, 0, len(hermeses))
+	for _, dto := range hermeses {
+		result = append(result, *dto)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].HermesID < result[j].HermesID
+	})
+	return result
 }
 
 // NewIdentityDTO maps to API identity.
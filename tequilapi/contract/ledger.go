@@ -0,0 +1,290 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/mysteriumnetwork/node/consumer/ledger"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+const (
+	// LedgerFormatJSON exports ledger entries as JSON array.
+	LedgerFormatJSON = "json"
+	// LedgerFormatCSV exports ledger entries as CSV with a header row.
+	LedgerFormatCSV = "csv"
+)
+
+// NewLedgerQuery creates ledger query with default values.
+func NewLedgerQuery() LedgerQuery {
+	return LedgerQuery{
+		SortBy: ledger.SortByTime,
+		Order:  ledger.OrderDesc,
+	}
+}
+
+// LedgerQuery allows to filter and sort requested ledger entries.
+type LedgerQuery struct {
+	// Filter the entries from this date. Formatted in RFC3339 e.g. 2020-07-01.
+	// in: query
+	DateFrom *strfmt.Date `json:"date_from"`
+
+	// Filter the entries until this date. Formatted in RFC3339 e.g. 2020-07-30.
+	// in: query
+	DateTo *strfmt.Date `json:"date_to"`
+
+	// Identity to filter the entries by.
+	// in: query
+	Identity *string `json:"identity"`
+
+	// Comma separated entry types to filter the entries by. Possible values are "session", "invoice", "promise", "settlement".
+	// in: query
+	Types []string `json:"types"`
+
+	// Field to sort the entries by. Possible values are "time", "amount", "type".
+	// in: query
+	// default: time
+	SortBy string `json:"sort_by"`
+
+	// Sort order. Possible values are "asc", "desc".
+	// in: query
+	// default: desc
+	Order string `json:"order"`
+}
+
+// Bind creates and validates query from API request.
+func (q *LedgerQuery) Bind(request *http.Request) *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+
+	qs := request.URL.Query()
+	if qStr := qs.Get("date_from"); qStr != "" {
+		if qVal, err := parseDate(qStr); err != nil {
+			errs.ForField("date_from").Add(err)
+		} else {
+			q.DateFrom = qVal
+		}
+	}
+	if qStr := qs.Get("date_to"); qStr != "" {
+		if qVal, err := parseDate(qStr); err != nil {
+			errs.ForField("date_to").Add(err)
+		} else {
+			q.DateTo = qVal
+		}
+	}
+	if qStr := qs.Get("identity"); qStr != "" {
+		q.Identity = &qStr
+	}
+	if qStr := qs.Get("types"); qStr != "" {
+		q.Types = strings.Split(qStr, ",")
+		for _, entryType := range q.Types {
+			switch entryType {
+			case ledger.EntryTypeSession, ledger.EntryTypeInvoice, ledger.EntryTypePromise, ledger.EntryTypeSettlement:
+			default:
+				errs.ForField("types").AddError("invalid", "Unknown entry type: "+entryType)
+			}
+		}
+	}
+	if qStr := qs.Get("sort_by"); qStr != "" {
+		switch qStr {
+		case ledger.SortByTime, ledger.SortByAmount, ledger.SortByType:
+			q.SortBy = qStr
+		default:
+			errs.ForField("sort_by").AddError("invalid", "Unknown sort field: "+qStr)
+		}
+	}
+	if qStr := qs.Get("order"); qStr != "" {
+		switch qStr {
+		case ledger.OrderAsc, ledger.OrderDesc:
+			q.Order = qStr
+		default:
+			errs.ForField("order").AddError("invalid", "Unknown sort order: "+qStr)
+		}
+	}
+
+	return errs
+}
+
+// ToFilter converts API query to ledger filter.
+func (q *LedgerQuery) ToFilter() *ledger.Filter {
+	filter := ledger.NewFilter().SetSort(q.SortBy, q.Order).SetTypes(q.Types...)
+	if q.DateFrom != nil {
+		filter.SetTimeFrom(time.Time(*q.DateFrom).Truncate(24 * time.Hour))
+	}
+	if q.DateTo != nil {
+		filter.SetTimeTo(time.Time(*q.DateTo).Truncate(24 * time.Hour).Add(23 * time.Hour).Add(59 * time.Minute).Add(59 * time.Second))
+	}
+	if q.Identity != nil {
+		filter.SetIdentity(identity.FromAddress(*q.Identity))
+	}
+	return filter
+}
+
+// NewLedgerListQuery creates ledger list query with default values.
+func NewLedgerListQuery() LedgerListQuery {
+	return LedgerListQuery{
+		PaginationQuery: NewPaginationQuery(),
+		LedgerQuery:     NewLedgerQuery(),
+	}
+}
+
+// LedgerListQuery allows to filter, sort and page requested ledger entries.
+// swagger:parameters ledgerList
+type LedgerListQuery struct {
+	PaginationQuery
+	LedgerQuery
+}
+
+// Bind creates and validates query from API request.
+func (q *LedgerListQuery) Bind(request *http.Request) *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	errs.Set(q.PaginationQuery.Bind(request))
+	errs.Set(q.LedgerQuery.Bind(request))
+
+	return errs
+}
+
+// NewLedgerExportQuery creates ledger export query with default values.
+func NewLedgerExportQuery() LedgerExportQuery {
+	return LedgerExportQuery{
+		LedgerQuery: NewLedgerQuery(),
+		Format:      LedgerFormatCSV,
+	}
+}
+
+// LedgerExportQuery allows to filter, sort and choose format of exported ledger entries.
+// swagger:parameters ledgerExport
+type LedgerExportQuery struct {
+	LedgerQuery
+
+	// Format of the export. Possible values are "csv", "json".
+	// in: query
+	// default: csv
+	Format string `json:"format"`
+}
+
+// Bind creates and validates query from API request.
+func (q *LedgerExportQuery) Bind(request *http.Request) *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	errs.Set(q.LedgerQuery.Bind(request))
+
+	if qStr := request.URL.Query().Get("format"); qStr != "" {
+		switch qStr {
+		case LedgerFormatCSV, LedgerFormatJSON:
+			q.Format = qStr
+		default:
+			errs.ForField("format").AddError("invalid", "Unknown export format: "+qStr)
+		}
+	}
+
+	return errs
+}
+
+// NewLedgerListResponse maps to API ledger list.
+func NewLedgerListResponse(entries []ledger.Entry, paginator *utils.Paginator) LedgerListResponse {
+	return LedgerListResponse{
+		Items:       NewLedgerEntryDTOs(entries),
+		PageableDTO: NewPageableDTO(paginator),
+	}
+}
+
+// LedgerListResponse defines ledger entry list representable as json.
+// swagger:model LedgerListResponse
+type LedgerListResponse struct {
+	Items []LedgerEntryDTO `json:"items"`
+	PageableDTO
+}
+
+// NewLedgerEntryDTOs maps to API ledger entries.
+func NewLedgerEntryDTOs(entries []ledger.Entry) []LedgerEntryDTO {
+	dtoArray := make([]LedgerEntryDTO, len(entries))
+	for i, entry := range entries {
+		dtoArray[i] = NewLedgerEntryDTO(entry)
+	}
+	return dtoArray
+}
+
+// NewLedgerEntryDTO maps to API ledger entry.
+func NewLedgerEntryDTO(entry ledger.Entry) LedgerEntryDTO {
+	return LedgerEntryDTO{
+		ID:            entry.ID,
+		Type:          entry.Type,
+		Time:          entry.Time.Format(time.RFC3339),
+		Identity:      entry.Identity.Address,
+		Peer:          entry.Peer.Address,
+		HermesID:      entry.HermesID,
+		Direction:     entry.Direction,
+		Reference:     entry.Reference,
+		Amount:        entry.Amount,
+		Total:         entry.Total,
+		BytesSent:     entry.DataSent,
+		BytesReceived: entry.DataReceived,
+		Duration:      uint64(entry.Duration.Seconds()),
+	}
+}
+
+// LedgerEntryDTO represents the ledger entry object.
+// swagger:model LedgerEntryDTO
+type LedgerEntryDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	ID string `json:"id"`
+
+	// example: session
+	Type string `json:"type"`
+
+	// example: 2019-06-06T11:04:43Z
+	Time string `json:"time"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	Identity string `json:"identity"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	Peer string `json:"peer,omitempty"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID string `json:"hermes_id,omitempty"`
+
+	// example: Consumed
+	Direction string `json:"direction"`
+
+	// session ID, channel ID or transaction hash depending on the entry type
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	Reference string `json:"reference"`
+
+	// example: 500000
+	Amount *big.Int `json:"amount"`
+
+	// example: 500000
+	Total *big.Int `json:"total"`
+
+	// example: 1024
+	BytesSent uint64 `json:"bytes_sent"`
+
+	// example: 1024
+	BytesReceived uint64 `json:"bytes_received"`
+
+	// duration in seconds
+	// example: 120
+	Duration uint64 `json:"duration"`
+}
@@ -17,9 +17,71 @@
 
 package contract
 
-// NATStatusDTO gives information about NAT traversal success or failure
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/nat/mapping"
+)
+
+// NATStatusDTO gives information about NAT traversal success or failure and detected NAT type
 // swagger:model NATStatusDTO
 type NATStatusDTO struct {
 	Status string `json:"status"`
 	Error  string `json:"error"`
+
+	// NAT type, empty if not detected
+	// example: port_restricted_cone
+	Type string `json:"type,omitempty"`
+
+	// how NAT allocates external ports, empty if not detected
+	// example: preserving
+	PortAllocation string `json:"port_allocation,omitempty"`
+}
+
+// NewPortMappingsDTO maps to API port mappings.
+func NewPortMappingsDTO(mappings []mapping.Mapping) PortMappingsDTO {
+	dto := PortMappingsDTO{Mappings: make([]PortMappingDTO, len(mappings))}
+	for i, m := range mappings {
+		dto.Mappings[i] = PortMappingDTO{
+			Protocol: m.Protocol,
+			Port:     m.Port,
+			Name:     m.Name,
+			Method:   m.Method,
+			Error:    m.Error,
+		}
+		if !m.ExpiresAt.IsZero() {
+			dto.Mappings[i].ExpiresAt = m.ExpiresAt.Format(time.RFC3339)
+		}
+	}
+	return dto
+}
+
+// PortMappingsDTO lists ports forwarded by the router.
+// swagger:model PortMappingsDTO
+type PortMappingsDTO struct {
+	Mappings []PortMappingDTO `json:"mappings"`
+}
+
+// PortMappingDTO represents port forwarded by the router.
+// swagger:model PortMappingDTO
+type PortMappingDTO struct {
+	// example: UDP
+	Protocol string `json:"protocol"`
+
+	// example: 51334
+	Port int `json:"port"`
+
+	// example: Myst node p2p port mapping
+	Name string `json:"name"`
+
+	// mechanism used to forward the port
+	// example: UPnP(IGDv1-IP1)
+	Method string `json:"method"`
+
+	// end of the current lease, empty for permanent lease
+	// example: 2020-11-04T16:52:08Z
+	ExpiresAt string `json:"expires_at,omitempty"`
+
+	// error of the last lease renewal
+	Error string `json:"error,omitempty"`
 }
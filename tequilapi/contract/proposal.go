@@ -20,6 +20,7 @@ package contract
 import (
 	"fmt"
 
+	"github.com/mysteriumnetwork/node/core/discovery/scoring"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
@@ -34,9 +35,25 @@ func NewProposalDTO(p market.ServiceProposal) ProposalDTO {
 		ServiceDefinition: NewServiceDefinitionDTO(p.ServiceDefinition),
 		AccessPolicies:    p.AccessPolicies,
 		PaymentMethod:     NewPaymentMethodDTO(p.PaymentMethod),
+		BandwidthLimit:    p.BandwidthLimit,
+		NATType:           p.NATType,
+		HermesIDs:         p.HermesIDs,
 	}
 }
 
+// NewRankedProposalDTO maps to API service proposal with its score.
+func NewRankedProposalDTO(p scoring.RankedProposal) ProposalDTO {
+	dto := NewProposalDTO(p.ServiceProposal)
+	dto.Score = &ProposalScoreDTO{
+		Total:    p.Score.Total,
+		Price:    p.Score.Price,
+		Quality:  p.Score.Quality,
+		History:  p.Score.History,
+		Location: p.Score.Location,
+	}
+	return dto
+}
+
 // NewPaymentMethodDTO maps to API payment method.
 func NewPaymentMethodDTO(m market.PaymentMethod) PaymentMethodDTO {
 	if m == nil {
@@ -107,6 +124,40 @@ type ProposalDTO struct {
 
 	// PaymentMethod
 	PaymentMethod PaymentMethodDTO `json:"payment_method"`
+
+	// BandwidthLimit applied by provider to every consumer session
+	BandwidthLimit *market.BandwidthLimit `json:"bandwidth_limit,omitempty"`
+
+	// NAT type of the provider, empty if unknown
+	// example: port_restricted_cone
+	NATType string `json:"nat_type,omitempty"`
+
+	// Hermeses the provider accepts payments through, empty if not advertised
+	// example: ["0x0000000000000000000000000000000000000001"]
+	HermesIDs []string `json:"hermes_ids,omitempty"`
+
+	// Score of the proposal, returned only if proposals are sorted by score
+	Score *ProposalScoreDTO `json:"score,omitempty"`
+}
+
+// ProposalScoreDTO explains the proposal score, every component is between 0 (the worst) and 1 (the best).
+// swagger:model ProposalScoreDTO
+type ProposalScoreDTO struct {
+	// weighted average of the components
+	// example: 0.82
+	Total float64 `json:"total"`
+	// price relative to the other proposals
+	// example: 0.9
+	Price float64 `json:"price"`
+	// share of successful connections measured by quality oracle
+	// example: 0.95
+	Quality float64 `json:"quality"`
+	// share of successful own sessions with the provider
+	// example: 0.5
+	History float64 `json:"history"`
+	// match with the preferred location
+	// example: 1
+	Location float64 `json:"location"`
 }
 
 func (p ProposalDTO) String() string {
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// NewShaperLimitsDTO maps to API bandwidth limits.
+func NewShaperLimitsDTO(defaults shaper.Profile, services, sessions map[string]shaper.Profile) ShaperLimitsDTO {
+	dto := ShaperLimitsDTO{
+		Default:  NewShaperProfileDTO(defaults),
+		Services: make(map[string]ShaperProfileDTO, len(services)),
+		Sessions: make(map[string]ShaperProfileDTO, len(sessions)),
+	}
+	for serviceType, profile := range services {
+		dto.Services[serviceType] = NewShaperProfileDTO(profile)
+	}
+	for sessionID, profile := range sessions {
+		dto.Sessions[sessionID] = NewShaperProfileDTO(profile)
+	}
+	return dto
+}
+
+// NewShaperProfileDTO maps to API bandwidth profile.
+func NewShaperProfileDTO(profile shaper.Profile) ShaperProfileDTO {
+	return ShaperProfileDTO{
+		UplinkKbps:   profile.UplinkKbps,
+		DownlinkKbps: profile.DownlinkKbps,
+	}
+}
+
+// ShaperLimitsDTO holds bandwidth limits of the node.
+// swagger:model ShaperLimitsDTO
+type ShaperLimitsDTO struct {
+	// node wide limits from configuration
+	Default ShaperProfileDTO `json:"default"`
+	// limits overridden per service type
+	Services map[string]ShaperProfileDTO `json:"services"`
+	// limits overridden per consumer session
+	Sessions map[string]ShaperProfileDTO `json:"sessions"`
+}
+
+// ShaperProfileDTO holds bandwidth limits, zero value means the limit is inherited.
+// swagger:model ShaperProfileDTO
+type ShaperProfileDTO struct {
+	// example: 5000
+	UplinkKbps int `json:"uplink_kbps"`
+	// example: 5000
+	DownlinkKbps int `json:"downlink_kbps"`
+}
+
+// Validate validates fields in request
+func (p ShaperProfileDTO) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if p.UplinkKbps < 0 {
+		errs.ForField("uplink_kbps").AddError("invalid", "Limit can not be negative")
+	}
+	if p.DownlinkKbps < 0 {
+		errs.ForField("downlink_kbps").AddError("invalid", "Limit can not be negative")
+	}
+	return errs
+}
+
+// ToProfile maps to bandwidth profile.
+func (p ShaperProfileDTO) ToProfile() shaper.Profile {
+	return shaper.Profile{
+		UplinkKbps:   p.UplinkKbps,
+		DownlinkKbps: p.DownlinkKbps,
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// NewSpendingLimitsDTO maps to API consumer spending limits.
+func NewSpendingLimitsDTO(limits pingpong.SpendingLimits, spentDaily, spentMonthly *big.Int) SpendingLimitsDTO {
+	return SpendingLimitsDTO{
+		SpendingLimitsRequest: SpendingLimitsRequest{
+			Session:        orZero(limits.Session),
+			Daily:          orZero(limits.Daily),
+			Monthly:        orZero(limits.Monthly),
+			AlertThreshold: limits.AlertThreshold,
+		},
+		SpentDaily:   spentDaily,
+		SpentMonthly: spentMonthly,
+	}
+}
+
+// SpendingLimitsDTO holds consumer spending limits and the amounts spent during the current day and month.
+// swagger:model SpendingLimitsDTO
+type SpendingLimitsDTO struct {
+	SpendingLimitsRequest
+	// amount spent during the current day (UTC)
+	// example: 500000000000000000
+	SpentDaily *big.Int `json:"spent_daily"`
+	// amount spent during the current month (UTC)
+	// example: 2000000000000000000
+	SpentMonthly *big.Int `json:"spent_monthly"`
+}
+
+// SpendingLimitsRequest holds consumer spending limits in MYST wei, zero value means no limit.
+// swagger:model SpendingLimitsRequest
+type SpendingLimitsRequest struct {
+	// example: 1000000000000000000
+	Session *big.Int `json:"session"`
+	// example: 3000000000000000000
+	Daily *big.Int `json:"daily"`
+	// example: 50000000000000000000
+	Monthly *big.Int `json:"monthly"`
+	// part of the limit, which being spent triggers an alert, 0 disables alerts
+	// example: 0.8
+	AlertThreshold float64 `json:"alert_threshold"`
+}
+
+// Validate validates fields in request
+func (r SpendingLimitsRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	for field, limit := range map[string]*big.Int{"session": r.Session, "daily": r.Daily, "monthly": r.Monthly} {
+		if limit != nil && limit.Sign() < 0 {
+			errs.ForField(field).AddError("invalid", "Limit can not be negative")
+		}
+	}
+	if r.AlertThreshold < 0 || r.AlertThreshold > 1 {
+		errs.ForField("alert_threshold").AddError("invalid", "Alert threshold must be between 0 and 1")
+	}
+	return errs
+}
+
+// ToLimits maps to consumer spending limits.
+func (r SpendingLimitsRequest) ToLimits() pingpong.SpendingLimits {
+	return pingpong.SpendingLimits{
+		Session:        orZero(r.Session),
+		Daily:          orZero(r.Daily),
+		Monthly:        orZero(r.Monthly),
+		AlertThreshold: r.AlertThreshold,
+	}
+}
+
+// NewSpendingLimitAlertDTO maps to API consumer spending limit alert.
+func NewSpendingLimitAlertDTO(e event.AppEventSpendingLimit) SpendingLimitAlertDTO {
+	return SpendingLimitAlertDTO{
+		ConsumerID: e.ConsumerID.Address,
+		SessionID:  e.SessionID,
+		Period:     string(e.Period),
+		Limit:      e.Limit,
+		Spent:      e.Spent,
+		Reached:    e.Reached,
+	}
+}
+
+// SpendingLimitAlertDTO notifies that consumer spending approaches the limit or the limit is reached.
+// swagger:model SpendingLimitAlertDTO
+type SpendingLimitAlertDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+	// example: daily
+	Period string `json:"period"`
+	// example: 3000000000000000000
+	Limit *big.Int `json:"limit"`
+	// example: 2400000000000000000
+	Spent *big.Int `json:"spent"`
+	// true if connection was stopped, because the limit is reached
+	// example: false
+	Reached bool `json:"reached"`
+}
+
+func orZero(amount *big.Int) *big.Int {
+	if amount == nil {
+		return new(big.Int)
+	}
+	return amount
+}
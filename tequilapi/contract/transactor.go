@@ -181,6 +181,68 @@ type SettlementDTO struct {
 	SettledAt string `json:"settled_at"`
 }
 
+// NewSettlementForecastDTO maps to API settlement forecast.
+func NewSettlementForecastDTO(forecast pingpong.SettlementForecast) SettlementForecastDTO {
+	dto := SettlementForecastDTO{
+		ProviderID:       forecast.ProviderID.Address,
+		HermesID:         forecast.HermesID.Hex(),
+		UnsettledBalance: forecast.UnsettledBalance,
+		EarningsPerHour:  forecast.EarningsPerHour,
+		EarningsPerDay:   forecast.EarningsPerDay,
+		EarningsPerMonth: forecast.EarningsPerMonth,
+		TransactorFee:    forecast.TransactorFee,
+		HermesFee:        forecast.HermesFee,
+		SettleAt:         forecast.SettleAt,
+		SettlementFees:   forecast.SettlementFees,
+	}
+	if forecast.NextSettlement != nil {
+		dto.NextSettlementAt = forecast.NextSettlement.UTC().Format(time.RFC3339)
+	}
+	return dto
+}
+
+// SettlementForecastDTO represents projected earnings and the next settlement of a provider.
+// swagger:model SettlementForecastDTO
+type SettlementForecastDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID string `json:"hermes_id"`
+
+	// example: 500000
+	UnsettledBalance *big.Int `json:"unsettled_balance"`
+
+	// Growth of unsettled balance observed during the last day, zero until it is known.
+	// example: 1000
+	EarningsPerHour *big.Int `json:"earnings_per_hour"`
+
+	// example: 24000
+	EarningsPerDay *big.Int `json:"earnings_per_day"`
+
+	// example: 720000
+	EarningsPerMonth *big.Int `json:"earnings_per_month"`
+
+	// example: 100
+	TransactorFee *big.Int `json:"transactor_fee"`
+
+	// Hermes fee in percentiles, 1500 means 15%.
+	// example: 250
+	HermesFee uint16 `json:"hermes_fee"`
+
+	// Unsettled balance at which the next settlement is expected.
+	// example: 2000000
+	SettleAt *big.Int `json:"settle_at"`
+
+	// Transactor and hermes fees of the next settlement.
+	// example: 50100
+	SettlementFees *big.Int `json:"settlement_fees"`
+
+	// Empty if the settlement time can't be estimated yet.
+	// example: 2019-06-06T11:04:43Z
+	NextSettlementAt string `json:"next_settlement_at,omitempty"`
+}
+
 // SettleRequest represents the request to settle hermes promises
 // swagger:model SettleRequestDTO
 type SettleRequest struct {
@@ -19,12 +19,17 @@ package endpoints
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 	"github.com/rs/zerolog/log"
 )
 
@@ -33,22 +38,48 @@ type configProvider interface {
 	GetDefaultConfig() map[string]interface{}
 	GetUserConfig() map[string]interface{}
 	SetUser(key string, value interface{})
-	RemoveUser(key string)
+	ApplyUserValues(values map[string]interface{}) []config.Change
 	SaveUserConfig() error
 }
 
+type configAuditLog interface {
+	Record(source, actor, remote string, changes []config.Change) error
+	Entries(limit int) ([]config.AuditEntry, error)
+}
+
+type tokenUsername interface {
+	Username(token string) (string, error)
+}
+
+const (
+	configAuditDefaultLimit = 100
+	configAuditUnknownActor = "unauthenticated"
+)
+
 // swagger:model configPayload
 type configPayload struct {
 	// example: {"data":{"access-policy":{"list":"mysterium"},"openvpn":{"port":5522}}}
 	Data map[string]interface{} `json:"data"`
 }
 
+// swagger:model configSchemaDTO
+type configSchemaDTO struct {
+	Flags []config.FlagSchema `json:"flags"`
+}
+
+// swagger:model configAuditDTO
+type configAuditDTO struct {
+	Entries []config.AuditEntry `json:"entries"`
+}
+
 type configAPI struct {
-	config configProvider
+	config   configProvider
+	auditLog configAuditLog
+	tokens   tokenUsername
 }
 
-func newConfigAPI(config configProvider) *configAPI {
-	return &configAPI{config: config}
+func newConfigAPI(config configProvider, auditLog configAuditLog, tokens tokenUsername) *configAPI {
+	return &configAPI{config: config, auditLog: auditLog, tokens: tokens}
 }
 
 // GetConfig returns current configuration
@@ -112,7 +143,7 @@ func (api *configAPI) GetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 // swagger:operation POST /config/user Configuration serUserConfig
 // ---
 // summary: Sets and returns user configuration
-// description: For keys present in the payload, it will set or remove the user config values (if the key is null). Changes are persisted to the config file.
+// description: For keys present in the payload, it will set or remove the user config values (if the key is null). Values of known flags are validated before any of them is set. Changes are persisted to the config file and recorded in the audit log.
 // parameters:
 //   - in: body
 //     name: body
@@ -124,6 +155,14 @@ func (api *configAPI) GetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 //     description: User configuration
 //     schema:
 //       "$ref": "#/definitions/configPayload"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
@@ -137,21 +176,126 @@ func (api *configAPI) SetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 	}
 	for k, v := range req.Data {
 		if isNil(v) {
-			log.Debug().Msgf("Clearing user config value: %q", v)
-			api.config.RemoveUser(k)
-		} else {
-			log.Debug().Msgf("Setting user config value: %q = %q", k, v)
-			api.config.SetUser(k, v)
+			req.Data[k] = nil
+		}
+	}
+
+	values, errs := config.ValidateUserValues(req.Data)
+	if len(errs) > 0 {
+		errorMap := validation.NewErrorMap()
+		for key, err := range errs {
+			errorMap.ForField(key).AddError("invalid", err.Error())
 		}
+		utils.SendValidationErrorMessage(writer, errorMap)
+		return
 	}
+
+	changes := api.config.ApplyUserValues(values)
 	err = api.config.SaveUserConfig()
 	if err != nil {
 		utils.SendError(writer, err, http.StatusInternalServerError)
 		return
 	}
+	if err := api.auditLog.Record(config.AuditSourceTequilapi, api.actor(httpReq), remoteAddress(httpReq), changes); err != nil {
+		log.Error().Err(err).Msg("Failed to record user config changes")
+	}
 	api.GetUserConfig(writer, nil, nil)
 }
 
+// GetConfigSchema returns configuration schema
+// swagger:operation GET /config/schema Configuration getConfigSchema
+// ---
+// summary: Returns configuration schema
+// description: Returns type, allowed values, default and effect of every configuration flag. Defaults of secret flags are omitted.
+// responses:
+//   200:
+//     description: Configuration schema
+//     schema:
+//       "$ref": "#/definitions/configSchemaDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *configAPI) GetConfigSchema(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
+	flags, err := config.Schema()
+	if err != nil {
+		utils.SendError(writer, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(configSchemaDTO{Flags: flags}, writer)
+}
+
+// GetConfigAudit returns user configuration changes
+// swagger:operation GET /config/audit Configuration getConfigAudit
+// ---
+// summary: Returns user configuration changes
+// description: Returns the latest user configuration changes made via Tequilapi or the config file, oldest first. Values of secret flags are redacted.
+// parameters:
+//   - in: query
+//     name: limit
+//     description: Maximum number of entries to return, 100 by default
+//     type: integer
+// responses:
+//   200:
+//     description: User configuration changes
+//     schema:
+//       "$ref": "#/definitions/configAuditDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (api *configAPI) GetConfigAudit(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
+	limit := configAuditDefaultLimit
+	if limitParam := httpReq.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			utils.SendError(writer, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := api.auditLog.Entries(limit)
+	if err != nil {
+		utils.SendError(writer, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(configAuditDTO{Entries: entries}, writer)
+}
+
+// actor returns the username of a valid token passed by the UI, the same way the UI reverse proxy accepts it.
+func (api *configAPI) actor(httpReq *http.Request) string {
+	var token string
+	if fields := strings.Fields(httpReq.Header.Get("Authorization")); len(fields) == 2 && strings.EqualFold(fields[0], "bearer") {
+		token = fields[1]
+	} else if cookie, err := httpReq.Cookie(auth.JWTCookieName); err == nil {
+		token = cookie.Value
+	}
+	if token == "" || api.tokens == nil {
+		return configAuditUnknownActor
+	}
+
+	username, err := api.tokens.Username(token)
+	if err != nil || username == "" {
+		return configAuditUnknownActor
+	}
+	return username
+}
+
+// remoteAddress returns the address of the peer which sent the request.
+// Forwarding headers are ignored, since any client is able to set them.
+func remoteAddress(httpReq *http.Request) string {
+	host, _, err := net.SplitHostPort(httpReq.RemoteAddr)
+	if err != nil {
+		return httpReq.RemoteAddr
+	}
+	return host
+}
+
 func isNil(val interface{}) bool {
 	if val == nil {
 		return true
@@ -166,10 +310,14 @@ func isNil(val interface{}) bool {
 // AddRoutesForConfig registers /config endpoints in Tequilapi
 func AddRoutesForConfig(
 	router *httprouter.Router,
+	auditLog configAuditLog,
+	tokens tokenUsername,
 ) {
-	api := newConfigAPI(config.Current)
+	api := newConfigAPI(config.Current, auditLog, tokens)
 	router.GET("/config", api.GetConfig)
 	router.GET("/config/default", api.GetDefaultConfig)
 	router.GET("/config/user", api.GetUserConfig)
 	router.POST("/config/user", api.SetUserConfig)
+	router.GET("/config/schema", api.GetConfigSchema)
+	router.GET("/config/audit", api.GetConfigAudit)
 }
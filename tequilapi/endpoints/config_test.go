@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTokenUsername struct{}
+
+func (m *mockTokenUsername) Username(token string) (string, error) {
+	if token != "valid-token" {
+		return "", errors.New("invalid JWT token")
+	}
+	return "myst", nil
+}
+
+func configTestRouter(t *testing.T) (*httprouter.Router, *config.Config, *config.AuditLog, func()) {
+	dir, err := ioutil.TempDir("", "config-api")
+	assert.NoError(t, err)
+	configFileName := filepath.Join(dir, "config.toml")
+	assert.NoError(t, ioutil.WriteFile(configFileName, nil, 0700))
+	cfg := config.NewConfig()
+	assert.NoError(t, cfg.LoadUserConfig(configFileName))
+	auditLog := config.NewAuditLog(filepath.Join(dir, "config-audit.log"))
+
+	api := newConfigAPI(cfg, auditLog, &mockTokenUsername{})
+	router := httprouter.New()
+	router.POST("/config/user", api.SetUserConfig)
+	router.GET("/config/audit", api.GetConfigAudit)
+	return router, cfg, auditLog, func() { os.RemoveAll(dir) }
+}
+
+func Test_SetUserConfig_RecordsChanges(t *testing.T) {
+	router, cfg, auditLog, cleanup := configTestRouter(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/config/user", strings.NewReader(`{"data": {"openvpn": {"port": 5522}, "tequilapi.auth.password": "secret"}}`))
+	req.Header.Set("Authorization", "Bearer valid-token")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 5522, cfg.GetInt("openvpn.port"))
+
+	entries, err := auditLog.Entries(0)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "openvpn.port", entries[0].Key)
+	assert.Equal(t, 5522.0, entries[0].New)
+	assert.Equal(t, "myst", entries[0].Actor)
+	assert.Equal(t, "192.0.2.1", entries[0].Remote)
+	assert.Equal(t, config.AuditSourceTequilapi, entries[0].Source)
+	assert.Equal(t, "tequilapi.auth.password", entries[1].Key)
+	assert.NotEqual(t, "secret", entries[1].New)
+
+	req = httptest.NewRequest(http.MethodGet, "/config/audit?limit=1", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"key":"tequilapi.auth.password"`)
+	assert.NotContains(t, resp.Body.String(), `"key":"openvpn.port"`)
+}
+
+func Test_SetUserConfig_ValidatesValues(t *testing.T) {
+	router, cfg, auditLog, cleanup := configTestRouter(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/config/user", strings.NewReader(`{"data": {"openvpn.port": 70000, "ui.enable": false}}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"openvpn.port"`)
+	assert.Nil(t, cfg.Get("openvpn.port"))
+	assert.Nil(t, cfg.Get("ui.enable"))
+
+	entries, err := auditLog.Entries(0)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -20,6 +20,7 @@ package endpoints
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"net/http"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -125,30 +126,54 @@ func (ce *ConnectionEndpoint) Status(resp http.ResponseWriter, _ *http.Request,
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
 func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	cr, ok := parseConnectRequest(resp, req, ce.identityRegistry, ce.proposalRepository)
+	if !ok {
+		return
+	}
+
+	err := ce.manager.Connect(cr.consumerID, cr.hermesID, cr.proposal, cr.params)
+	if err != nil {
+		sendConnectError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusCreated)
+	ce.Status(resp, req, params)
+}
+
+type connectRequest struct {
+	consumerID identity.Identity
+	hermesID   common.Address
+	proposal   market.ServiceProposal
+	params     connection.ConnectParams
+}
+
+// parseConnectRequest validates connection request and resolves the requested proposal.
+// Errors are written to the response, in which case false is returned.
+func parseConnectRequest(resp http.ResponseWriter, req *http.Request, identityRegistry identityRegistry, proposalRepository proposal.Repository) (*connectRequest, bool) {
 	cr, err := toConnectionRequest(req)
 	if err != nil {
 		utils.SendError(resp, err, http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	if errorMap := cr.Validate(); errorMap.HasErrors() {
 		utils.SendValidationErrorMessage(resp, errorMap)
-		return
+		return nil, false
 	}
 
 	// TODO Validate for account existence
 	consumerID := identity.FromAddress(cr.ConsumerID)
-	status, err := ce.identityRegistry.GetRegistrationStatus(config.GetInt64(config.FlagChainID), consumerID)
+	status, err := identityRegistry.GetRegistrationStatus(config.GetInt64(config.FlagChainID), consumerID)
 	if err != nil {
 		log.Error().Err(err).Stack().Msg("could not check registration status")
 		utils.SendError(resp, err, http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 	switch status {
 	case registry.Unregistered, registry.RegistrationError:
 		log.Warn().Msgf("identity %q is not registered, aborting...", cr.ConsumerID)
 		utils.SendError(resp, fmt.Errorf("identity %q is not registered. Please register the identity first", cr.ConsumerID), http.StatusExpectationFailed)
-		return
+		return nil, false
 	case registry.InProgress:
 		log.Info().Msgf("identity %q registration is in progress, continuing...", cr.ConsumerID)
 	default:
@@ -156,35 +181,37 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 	}
 
 	// TODO Pass proposal ID directly in request
-	proposal, err := ce.proposalRepository.Proposal(market.ProposalID{
+	proposal, err := proposalRepository.Proposal(market.ProposalID{
 		ProviderID:  cr.ProviderID,
 		ServiceType: cr.ServiceType,
 	})
 	if err != nil {
 		utils.SendError(resp, err, http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 	if proposal == nil {
 		utils.SendError(resp, errors.New("provider has no service proposals"), http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
-	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.HermesID), *proposal, getConnectOptions(cr))
+	return &connectRequest{
+		consumerID: consumerID,
+		hermesID:   common.HexToAddress(cr.HermesID),
+		proposal:   *proposal,
+		params:     getConnectOptions(cr, proposal.ServiceType),
+	}, true
+}
 
-	if err != nil {
-		switch err {
-		case connection.ErrAlreadyExists:
-			utils.SendError(resp, err, http.StatusConflict)
-		case connection.ErrConnectionCancelled:
-			utils.SendError(resp, err, statusConnectCancelled)
-		default:
-			log.Error().Err(err).Msg("")
-			utils.SendError(resp, err, http.StatusInternalServerError)
-		}
-		return
+func sendConnectError(resp http.ResponseWriter, err error) {
+	switch err {
+	case connection.ErrAlreadyExists:
+		utils.SendError(resp, err, http.StatusConflict)
+	case connection.ErrConnectionCancelled:
+		utils.SendError(resp, err, statusConnectCancelled)
+	default:
+		log.Error().Err(err).Msg("")
+		utils.SendError(resp, err, http.StatusInternalServerError)
 	}
-	resp.WriteHeader(http.StatusCreated)
-	ce.Status(resp, req, params)
 }
 
 // Kill stops connection
@@ -263,7 +290,7 @@ func toConnectionRequest(req *http.Request) (*contract.ConnectionCreateRequest,
 	return &connectionRequest, nil
 }
 
-func getConnectOptions(cr *contract.ConnectionCreateRequest) connection.ConnectParams {
+func getConnectOptions(cr *contract.ConnectionCreateRequest, serviceType string) connection.ConnectParams {
 	dns := connection.DNSOptionAuto
 	if cr.ConnectOptions.DNS != "" {
 		dns = cr.ConnectOptions.DNS
@@ -272,5 +299,30 @@ func getConnectOptions(cr *contract.ConnectionCreateRequest) connection.ConnectP
 	return connection.ConnectParams{
 		DisableKillSwitch: cr.ConnectOptions.DisableKillSwitch,
 		DNS:               dns,
+		Failover:          getFailoverFilter(cr.ConnectOptions.Failover, serviceType),
+		SplitTunnel:       cr.ConnectOptions.SplitTunnel.ToSplitTunnel(),
+	}
+}
+
+func getFailoverFilter(failover *contract.ConnectFailoverOptions, serviceType string) *proposal.Filter {
+	if failover == nil {
+		return nil
+	}
+
+	filter := &proposal.Filter{
+		ServiceType:        serviceType,
+		LocationType:       failover.LocationType,
+		AccessPolicyID:     failover.AccessPolicyID,
+		AccessPolicySource: failover.AccessPolicySource,
+		ExcludeUnsupported: true,
+	}
+	if failover.UpperTimePriceBound != nil {
+		filter.LowerTimePriceBound = new(big.Int)
+		filter.UpperTimePriceBound = failover.UpperTimePriceBound
+	}
+	if failover.UpperGBPriceBound != nil {
+		filter.LowerGBPriceBound = new(big.Int)
+		filter.UpperGBPriceBound = failover.UpperGBPriceBound
 	}
+	return filter
 }
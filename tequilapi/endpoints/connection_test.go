@@ -50,6 +50,7 @@ type mockConnectionManager struct {
 	requestedProvider    identity.Identity
 	requestedHermesID    common.Address
 	requestedServiceType string
+	requestedParams      connection.ConnectParams
 }
 
 func (cm *mockConnectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, options connection.ConnectParams) error {
@@ -57,6 +58,7 @@ func (cm *mockConnectionManager) Connect(consumerID identity.Identity, hermesID
 	cm.requestedHermesID = hermesID
 	cm.requestedProvider = identity.FromAddress(proposal.ProviderID)
 	cm.requestedServiceType = proposal.ServiceType
+	cm.requestedParams = options
 	return cm.onConnectReturn
 }
 
@@ -262,6 +264,80 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	)
 }
 
+func TestPutWithSplitTunnelPassesItToConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, mockIdentityRegistryInstance)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"connect_options": {
+					"kill_switch": true,
+					"split_tunnel": {
+						"include": ["10.0.0.0/8", "example.com"],
+						"exclude": ["10.1.2.3"]
+					}
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(
+		t,
+		connection.SplitTunnel{Include: []string{"10.0.0.0/8", "example.com"}, Exclude: []string{"10.1.2.3"}},
+		fakeManager.requestedParams.SplitTunnel,
+	)
+}
+
+func TestPutWithInvalidSplitTunnelReturns422Error(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      string
+		errorMessage string
+	}{
+		{
+			name:         "invalid destination",
+			options:      `{"split_tunnel": {"exclude": ["http://example.com"]}}`,
+			errorMessage: `invalid split tunnel destination \"http://example.com\": expected IPv4 CIDR, IPv4 address or domain name`,
+		},
+		{
+			name:         "included destinations with kill switch",
+			options:      `{"split_tunnel": {"include": ["10.0.0.0/8"]}}`,
+			errorMessage: connection.ErrSplitTunnelKillSwitch.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			connEndpoint := NewConnectionEndpoint(&mockConnectionManager{}, nil, &mockProposalRepository{}, mockIdentityRegistryInstance)
+			req := httptest.NewRequest(
+				http.MethodPut,
+				"/irrelevant",
+				strings.NewReader(`{"consumer_id": "my-identity", "provider_id": "required-node", "connect_options": `+test.options+`}`),
+			)
+			resp := httptest.NewRecorder()
+
+			connEndpoint.Create(resp, req, httprouter.Params{})
+
+			assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+			assert.JSONEq(
+				t,
+				`{
+					"message" : "validation_error",
+					"errors" : {
+						"split_tunnel" : [ { "code" : "invalid" , "message" : "`+test.errorMessage+`" } ]
+					}
+				}`, resp.Body.String())
+		})
+	}
+}
+
 func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/connection/connectiontrace"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type connectionTraceProvider interface {
+	List() []connectiontrace.Recording
+	Get(id string) (connectiontrace.Recording, bool)
+}
+
+type connectionTraceEndpoint struct {
+	traces connectionTraceProvider
+}
+
+// NewConnectionTraceEndpoint creates and returns connection trace endpoint.
+func NewConnectionTraceEndpoint(traces connectionTraceProvider) *connectionTraceEndpoint {
+	return &connectionTraceEndpoint{
+		traces: traces,
+	}
+}
+
+// swagger:operation GET /connection/traces Connection connectionTraceList
+// ---
+// summary: Returns recorded connection traces
+// description: Returns the latest recorded connection traces without their entries, the latest trace goes first
+// responses:
+//   200:
+//     description: List of connection traces
+//     schema:
+//       "$ref": "#/definitions/ConnectionTraceListResponse"
+func (endpoint *connectionTraceEndpoint) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewConnectionTraceListResponse(endpoint.traces.List()), resp)
+}
+
+// swagger:operation GET /connection/traces/{id} Connection connectionTraceDownload
+// ---
+// summary: Downloads recorded connection trace
+// description: Downloads connection trace with its stages, p2p messages, state transitions and errors as JSON file
+// produces:
+// - application/json
+// parameters:
+//   - in: path
+//     name: id
+//     description: ID of the connection trace
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Connection trace file
+//   404:
+//     description: Connection trace not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *connectionTraceEndpoint) Download(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	id := params.ByName("id")
+	recording, ok := endpoint.traces.Get(id)
+	if !ok {
+		utils.SendError(resp, errors.New("connection trace not found"), http.StatusNotFound)
+		return
+	}
+
+	resp.Header().Set("Content-Disposition", "attachment; filename=connection-trace-"+recording.ID+".json")
+	utils.WriteAsJSON(recording, resp)
+}
+
+// AddRoutesForConnectionTraces attaches connection trace endpoints to router.
+func AddRoutesForConnectionTraces(router *httprouter.Router, traces connectionTraceProvider) {
+	connectionTraceEndpoint := NewConnectionTraceEndpoint(traces)
+	router.GET("/connection/traces", connectionTraceEndpoint.List)
+	router.GET("/connection/traces/:id", connectionTraceEndpoint.Download)
+}
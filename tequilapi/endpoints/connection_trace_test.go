@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectiontrace"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+var connectionTraceMock = connectiontrace.Recording{
+	ID:          "trace1",
+	SessionID:   "session1",
+	ConsumerID:  "0x1",
+	ProviderID:  "0x2",
+	ServiceType: "wireguard",
+	StartedAt:   time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC),
+	Entries: []connectiontrace.Entry{
+		{Time: time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC), Kind: "connect", Name: "Connect"},
+		{Time: time.Date(2020, 7, 1, 10, 0, 1, 0, time.UTC), Kind: "p2p", Name: "p2p-session-create", Details: "sent", Duration: time.Second},
+	},
+	Dropped: 1,
+}
+
+func Test_ConnectionTraceEndpoint_List(t *testing.T) {
+	// given
+	req, _ := http.NewRequest(http.MethodGet, "/connection/traces", nil)
+	resp := httptest.NewRecorder()
+
+	// when
+	NewConnectionTraceEndpoint(&connectionTraceProviderMock{connectionTraceMock}).List(resp, req, nil)
+
+	// then
+	assert.Equal(t, http.StatusOK, resp.Code)
+	parsedResponse := contract.ConnectionTraceListResponse{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsedResponse))
+	assert.Equal(t, contract.ConnectionTraceListResponse{
+		Items: []contract.ConnectionTraceDTO{{
+			ID:          "trace1",
+			SessionID:   "session1",
+			ConsumerID:  "0x1",
+			ProviderID:  "0x2",
+			ServiceType: "wireguard",
+			StartedAt:   "2020-07-01T10:00:00Z",
+		}},
+	}, parsedResponse)
+}
+
+func Test_ConnectionTraceEndpoint_Download(t *testing.T) {
+	endpoint := NewConnectionTraceEndpoint(&connectionTraceProviderMock{connectionTraceMock})
+
+	t.Run("returns trace file", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/connection/traces/trace1", nil)
+		resp := httptest.NewRecorder()
+
+		endpoint.Download(resp, req, httprouter.Params{{Key: "id", Value: "trace1"}})
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "attachment; filename=connection-trace-trace1.json", resp.Header().Get("Content-Disposition"))
+		parsedResponse := connectiontrace.Recording{}
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsedResponse))
+		assert.Equal(t, connectionTraceMock, parsedResponse)
+	})
+
+	t.Run("returns not found", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/connection/traces/unknown", nil)
+		resp := httptest.NewRecorder()
+
+		endpoint.Download(resp, req, httprouter.Params{{Key: "id", Value: "unknown"}})
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+type connectionTraceProviderMock struct {
+	recording connectiontrace.Recording
+}
+
+func (m *connectionTraceProviderMock) List() []connectiontrace.Recording {
+	recording := m.recording
+	recording.Entries = nil
+	return []connectiontrace.Recording{recording}
+}
+
+func (m *connectionTraceProviderMock) Get(id string) (connectiontrace.Recording, bool) {
+	if id != m.recording.ID {
+		return connectiontrace.Recording{}, false
+	}
+	return m.recording, true
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type multiConnectionManager interface {
+	Connect(consumerID identity.Identity, hermesID common.Address, proposal market.ServiceProposal, params connection.ConnectParams) (string, error)
+	Connection(connectionID string) (connection.AdditionalConnection, error)
+	Connections() []connection.AdditionalConnection
+	Disconnect(connectionID string) error
+}
+
+// MultiConnectionEndpoint struct represents /connections resource and it's subresources.
+// Additional connections live alongside the default one served by /connection.
+type MultiConnectionEndpoint struct {
+	manager            multiConnectionManager
+	proposalRepository proposal.Repository
+	identityRegistry   identityRegistry
+}
+
+// NewMultiConnectionEndpoint creates and returns additional connections endpoint
+func NewMultiConnectionEndpoint(manager multiConnectionManager, proposalRepository proposal.Repository, identityRegistry identityRegistry) *MultiConnectionEndpoint {
+	return &MultiConnectionEndpoint{
+		manager:            manager,
+		proposalRepository: proposalRepository,
+		identityRegistry:   identityRegistry,
+	}
+}
+
+// List returns additional connections
+// swagger:operation GET /connections Connection connectionList
+// ---
+// summary: Returns additional connections
+// description: Returns status and statistics of connections opened alongside the default one
+// responses:
+//   200:
+//     description: List of additional connections
+//     schema:
+//       "$ref": "#/definitions/ConnectionListDTO"
+func (mce *MultiConnectionEndpoint) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	connections := mce.manager.Connections()
+
+	response := contract.ConnectionListDTO{Connections: make([]contract.ConnectionDTO, len(connections))}
+	for i, conn := range connections {
+		response.Connections[i] = contract.NewConnectionDTO(conn.Session, conn.Statistics, conn.Throughput, conn.Invoice)
+	}
+	utils.WriteAsJSON(response, resp)
+}
+
+// Create starts additional connection
+// swagger:operation PUT /connections Connection connectionCreateAdditional
+// ---
+// summary: Starts additional connection
+// description: Consumer opens connection to provider alongside the existing connections, the connection must route only included split tunnel destinations and have kill switch disabled
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (consumer_id, provider_id, service_type) required for creating new connection
+//     schema:
+//       $ref: "#/definitions/ConnectionCreateRequestDTO"
+// responses:
+//   201:
+//     description: Connection started
+//     schema:
+//       "$ref": "#/definitions/ConnectionInfoDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   499:
+//     description: Connection was cancelled
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (mce *MultiConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	cr, ok := parseConnectRequest(resp, req, mce.identityRegistry, mce.proposalRepository)
+	if !ok {
+		return
+	}
+
+	id, err := mce.manager.Connect(cr.consumerID, cr.hermesID, cr.proposal, cr.params)
+	if err != nil {
+		switch err {
+		case connection.ErrAdditionalConnectionRoutes, connection.ErrSplitTunnelKillSwitch:
+			utils.SendError(resp, err, http.StatusBadRequest)
+		default:
+			sendConnectError(resp, err)
+		}
+		return
+	}
+
+	conn, err := mce.manager.Connection(id)
+	if err != nil {
+		// connection might be closed right after it was established
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusCreated)
+	utils.WriteAsJSON(contract.NewConnectionInfoDTO(conn.Session), resp)
+}
+
+// Status returns status of additional connection
+// swagger:operation GET /connections/{id} Connection connectionStatusAdditional
+// ---
+// summary: Returns additional connection status
+// description: Returns status of additional connection
+// parameters:
+//   - in: path
+//     name: id
+//     description: ID of the connection
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Status
+//     schema:
+//       "$ref": "#/definitions/ConnectionInfoDTO"
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (mce *MultiConnectionEndpoint) Status(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	conn, err := mce.manager.Connection(params.ByName("id"))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+	utils.WriteAsJSON(contract.NewConnectionInfoDTO(conn.Session), resp)
+}
+
+// Kill stops additional connection
+// swagger:operation DELETE /connections/{id} Connection connectionCancelAdditional
+// ---
+// summary: Stops additional connection
+// description: Stops additional connection, the default connection is not affected
+// parameters:
+//   - in: path
+//     name: id
+//     description: ID of the connection
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Connection Stopped
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (mce *MultiConnectionEndpoint) Kill(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	err := mce.manager.Disconnect(params.ByName("id"))
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			utils.SendError(resp, err, http.StatusNotFound)
+		default:
+			utils.SendError(resp, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// GetStatistics returns statistics of additional connection
+// swagger:operation GET /connections/{id}/statistics Connection connectionStatisticsAdditional
+// ---
+// summary: Returns additional connection statistics
+// description: Returns statistics of additional connection
+// parameters:
+//   - in: path
+//     name: id
+//     description: ID of the connection
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Connection statistics
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatisticsDTO"
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (mce *MultiConnectionEndpoint) GetStatistics(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	conn, err := mce.manager.Connection(params.ByName("id"))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+	utils.WriteAsJSON(contract.NewConnectionStatisticsDTO(conn.Session, conn.Statistics, conn.Throughput, conn.Invoice), resp)
+}
+
+// AddRoutesForMultiConnection adds additional connections routes to given router
+func AddRoutesForMultiConnection(router *httprouter.Router, manager multiConnectionManager, proposalRepository proposal.Repository, identityRegistry identityRegistry) {
+	endpoint := NewMultiConnectionEndpoint(manager, proposalRepository, identityRegistry)
+	router.GET("/connections", endpoint.List)
+	router.PUT("/connections", endpoint.Create)
+	router.GET("/connections/:id", endpoint.Status)
+	router.DELETE("/connections/:id", endpoint.Kill)
+	router.GET("/connections/:id/statistics", endpoint.GetStatistics)
+}
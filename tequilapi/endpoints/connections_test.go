@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockMultiConnectionManager struct {
+	connections map[string]connection.AdditionalConnection
+}
+
+func (m *mockMultiConnectionManager) Connect(_ identity.Identity, _ common.Address, _ market.ServiceProposal, _ connection.ConnectParams) (string, error) {
+	m.connections["new"] = connection.AdditionalConnection{
+		Session: connectionstate.Status{ConnectionID: "new", State: connectionstate.Connecting},
+	}
+	return "new", nil
+}
+
+func (m *mockMultiConnectionManager) Connection(connectionID string) (connection.AdditionalConnection, error) {
+	conn, ok := m.connections[connectionID]
+	if !ok {
+		return connection.AdditionalConnection{}, connection.ErrNoConnection
+	}
+	return conn, nil
+}
+
+func (m *mockMultiConnectionManager) Connections() []connection.AdditionalConnection {
+	var connections []connection.AdditionalConnection
+	for _, conn := range m.connections {
+		connections = append(connections, conn)
+	}
+	return connections
+}
+
+func (m *mockMultiConnectionManager) Disconnect(connectionID string) error {
+	if _, ok := m.connections[connectionID]; !ok {
+		return connection.ErrNoConnection
+	}
+	delete(m.connections, connectionID)
+	return nil
+}
+
+func TestAddRoutesForMultiConnectionAddsRoutes(t *testing.T) {
+	router := httprouter.New()
+	manager := &mockMultiConnectionManager{
+		connections: map[string]connection.AdditionalConnection{
+			"1": {
+				Session:    connectionstate.Status{ConnectionID: "1", State: connectionstate.Connected, SessionID: "session1"},
+				Statistics: connectionstate.Statistics{BytesSent: 1, BytesReceived: 2},
+			},
+		},
+	}
+	AddRoutesForMultiConnection(router, manager, mockRepositoryWithProposal("node1", "noop"), mockIdentityRegistryInstance)
+
+	tests := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedJSON   string
+	}{
+		{
+			http.MethodGet, "/connections/1", "",
+			http.StatusOK, `{"id": "1", "status": "Connected", "session_id": "session1"}`,
+		},
+		{
+			http.MethodGet, "/connections/1/statistics", "",
+			http.StatusOK, `{
+				"bytes_sent": 1,
+				"bytes_received": 2,
+				"throughput_received": 0,
+				"throughput_sent": 0,
+				"duration": 0,
+				"tokens_spent": 0
+			}`,
+		},
+		{
+			http.MethodGet, "/connections/2", "",
+			http.StatusNotFound, `{"message": "no connection exists"}`,
+		},
+		{
+			http.MethodDelete, "/connections/1", "",
+			http.StatusAccepted, "",
+		},
+		{
+			http.MethodGet, "/connections", "",
+			http.StatusOK, `{"connections": []}`,
+		},
+		{
+			http.MethodPut, "/connections", `{"consumer_id": "me", "provider_id": "node1", "hermes_id":"hermes", "service_type": "noop"}`,
+			http.StatusCreated, `{"id": "new", "status": "Connecting"}`,
+		},
+	}
+
+	for _, test := range tests {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, test.expectedStatus, resp.Code, test.path)
+		if test.expectedJSON != "" {
+			assert.JSONEq(t, test.expectedJSON, resp.Body.String(), test.path)
+		} else {
+			assert.Equal(t, "", resp.Body.String())
+		}
+	}
+}
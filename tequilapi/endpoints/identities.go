@@ -39,10 +39,12 @@ import (
 
 type balanceProvider interface {
 	ForceBalanceUpdate(chainID int64, id identity.Identity) *big.Int
+	GetHermesBalances(chainID int64, id identity.Identity) map[common.Address]*big.Int
 }
 
 type earningsProvider interface {
 	GetEarnings(chainID int64, id identity.Identity) pingpong_event.Earnings
+	GetEarningsPerHermes(chainID int64, id identity.Identity) map[common.Address]pingpong_event.Earnings
 }
 
 type providerChannel interface {
@@ -296,8 +298,9 @@ func (endpoint *identitiesAPI) Get(resp http.ResponseWriter, _ *http.Request, pa
 		stake = data.Stake
 	}
 
-	balance := endpoint.balanceProvider.ForceBalanceUpdate(config.GetInt64(config.FlagChainID), id)
-	settlement := endpoint.earningsProvider.GetEarnings(config.GetInt64(config.FlagChainID), id)
+	chainID := config.GetInt64(config.FlagChainID)
+	balance := endpoint.balanceProvider.ForceBalanceUpdate(chainID, id)
+	settlement := endpoint.earningsProvider.GetEarnings(chainID, id)
 	status := contract.IdentityDTO{
 		Address:            address,
 		RegistrationStatus: regStatus.String(),
@@ -306,6 +309,10 @@ func (endpoint *identitiesAPI) Get(resp http.ResponseWriter, _ *http.Request, pa
 		Earnings:           settlement.UnsettledBalance,
 		EarningsTotal:      settlement.LifetimeBalance,
 		Stake:              stake,
+		Hermeses: contract.NewIdentityHermesDTOs(
+			endpoint.balanceProvider.GetHermesBalances(chainID, id),
+			endpoint.earningsProvider.GetEarningsPerHermes(chainID, id),
+		),
 	}
 	utils.WriteAsJSON(status, resp)
 }
@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/ledger"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/rs/zerolog/log"
+	"github.com/vcraescu/go-paginator/adapter"
+)
+
+type ledgerLister interface {
+	List(filter *ledger.Filter) ([]ledger.Entry, error)
+}
+
+type ledgerEndpoint struct {
+	ledger ledgerLister
+}
+
+// NewLedgerEndpoint creates and returns ledger endpoint.
+func NewLedgerEndpoint(ledger ledgerLister) *ledgerEndpoint {
+	return &ledgerEndpoint{
+		ledger: ledger,
+	}
+}
+
+// swagger:operation GET /ledger Ledger ledgerList
+// ---
+// summary: Returns ledger entries
+// description: Returns sessions, paid invoices, received hermes promises and settlements filtered and sorted by given query
+// responses:
+//   200:
+//     description: List of ledger entries
+//     schema:
+//       "$ref": "#/definitions/LedgerListResponse"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *ledgerEndpoint) List(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	query := contract.NewLedgerListQuery()
+	if errors := query.Bind(request); errors.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errors)
+		return
+	}
+
+	entriesAll, err := endpoint.ledger.List(query.ToFilter())
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	var entries []ledger.Entry
+	p := utils.NewPaginator(adapter.NewSliceAdapter(entriesAll), query.PageSize, query.Page)
+	if err := p.Results(&entries); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewLedgerListResponse(entries, p), resp)
+}
+
+// swagger:operation GET /ledger/export Ledger ledgerExport
+// ---
+// summary: Exports ledger entries
+// description: Exports all ledger entries filtered and sorted by given query as CSV or JSON file
+// produces:
+// - text/csv
+// - application/json
+// responses:
+//   200:
+//     description: Ledger entries file
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *ledgerEndpoint) Export(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	query := contract.NewLedgerExportQuery()
+	if errors := query.Bind(request); errors.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errors)
+		return
+	}
+
+	entries, err := endpoint.ledger.List(query.ToFilter())
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set("Content-Disposition", "attachment; filename=ledger."+query.Format)
+	if query.Format == contract.LedgerFormatJSON {
+		utils.WriteAsJSON(contract.NewLedgerEntryDTOs(entries), resp)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "text/csv")
+	if err := ledger.WriteCSV(resp, entries); err != nil {
+		log.Error().Err(err).Msg("Failed to write ledger CSV")
+	}
+}
+
+// AddRoutesForLedger attaches ledger endpoints to router.
+func AddRoutesForLedger(router *httprouter.Router, ledger ledgerLister) {
+	ledgerEndpoint := NewLedgerEndpoint(ledger)
+	router.GET("/ledger", ledgerEndpoint.List)
+	router.GET("/ledger/export", ledgerEndpoint.Export)
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/ledger"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+var ledgerEntryMock = ledger.Entry{
+	ID:        "invoice1",
+	Type:      ledger.EntryTypeInvoice,
+	Time:      time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC),
+	Identity:  identity.FromAddress("0x1"),
+	Peer:      identity.FromAddress("0x2"),
+	Direction: "Consumed",
+	Reference: "session1",
+	Amount:    big.NewInt(100),
+	Total:     big.NewInt(300),
+}
+
+func Test_LedgerEndpoint_List(t *testing.T) {
+	// given
+	lm := &ledgerMock{entries: []ledger.Entry{ledgerEntryMock}}
+	req, _ := http.NewRequest(http.MethodGet, "/irrelevant?date_from=2020-07-01&date_to=2020-07-02&identity=0x1&types=invoice,settlement&sort_by=amount&order=asc", nil)
+	resp := httptest.NewRecorder()
+
+	// when
+	NewLedgerEndpoint(lm).List(resp, req, nil)
+
+	// then
+	assert.Equal(t, http.StatusOK, resp.Code)
+	parsedResponse := contract.LedgerListResponse{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsedResponse))
+	assert.Equal(
+		t,
+		contract.LedgerListResponse{
+			Items: []contract.LedgerEntryDTO{contract.NewLedgerEntryDTO(ledgerEntryMock)},
+			PageableDTO: contract.PageableDTO{
+				Page:       1,
+				PageSize:   50,
+				TotalItems: 1,
+				TotalPages: 1,
+			},
+		},
+		parsedResponse,
+	)
+	assert.Equal(
+		t,
+		ledger.NewFilter().
+			SetTimeFrom(time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)).
+			SetTimeTo(time.Date(2020, 7, 2, 23, 59, 59, 0, time.UTC)).
+			SetIdentity(identity.FromAddress("0x1")).
+			SetTypes(ledger.EntryTypeInvoice, ledger.EntryTypeSettlement).
+			SetSort(ledger.SortByAmount, ledger.OrderAsc),
+		lm.calledWithFilter,
+	)
+}
+
+func Test_LedgerEndpoint_ListValidatesQuery(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/irrelevant?types=unknown&sort_by=unknown&order=unknown", nil)
+	resp := httptest.NewRecorder()
+
+	NewLedgerEndpoint(&ledgerMock{}).List(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"types": [{"code": "invalid", "message": "Unknown entry type: unknown"}],
+				"sort_by": [{"code": "invalid", "message": "Unknown sort field: unknown"}],
+				"order": [{"code": "invalid", "message": "Unknown sort order: unknown"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func Test_LedgerEndpoint_Export(t *testing.T) {
+	lm := &ledgerMock{entries: []ledger.Entry{ledgerEntryMock}}
+
+	t.Run("CSV", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/irrelevant", nil)
+		resp := httptest.NewRecorder()
+
+		NewLedgerEndpoint(lm).Export(resp, req, nil)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "text/csv", resp.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=ledger.csv", resp.Header().Get("Content-Disposition"))
+		assert.Equal(
+			t,
+			"id,type,time,identity,peer,hermes_id,direction,reference,amount,total,bytes_sent,bytes_received,duration\n"+
+				"invoice1,invoice,2020-07-01T10:00:00Z,0x1,0x2,,Consumed,session1,100,300,0,0,0\n",
+			resp.Body.String(),
+		)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/irrelevant?format=json", nil)
+		resp := httptest.NewRecorder()
+
+		NewLedgerEndpoint(lm).Export(resp, req, nil)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "attachment; filename=ledger.json", resp.Header().Get("Content-Disposition"))
+		var parsedResponse []contract.LedgerEntryDTO
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsedResponse))
+		assert.Equal(t, []contract.LedgerEntryDTO{contract.NewLedgerEntryDTO(ledgerEntryMock)}, parsedResponse)
+	})
+}
+
+type ledgerMock struct {
+	entries          []ledger.Entry
+	calledWithFilter *ledger.Filter
+}
+
+func (m *ledgerMock) List(filter *ledger.Filter) ([]ledger.Entry, error) {
+	m.calledWithFilter = filter
+	return m.entries, nil
+}
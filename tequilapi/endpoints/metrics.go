@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type metricsEndpoint struct {
+	handler http.Handler
+}
+
+// Metrics returns metrics of node internals
+// swagger:operation GET /metrics Metrics metrics
+// ---
+// summary: Returns node metrics
+// description: Returns sessions, transferred bytes, earnings, settlements, p2p channels, keep alive failures
+//   and NAT traversal outcomes in Prometheus text exposition format
+// produces:
+//   - text/plain
+// responses:
+//   200:
+//     description: Node metrics
+func (me *metricsEndpoint) Metrics(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	me.handler.ServeHTTP(resp, req)
+}
+
+// AddRoutesForMetrics attaches metrics endpoint to router
+func AddRoutesForMetrics(router *httprouter.Router, handler http.Handler) {
+	me := &metricsEndpoint{handler: handler}
+	router.GET("/metrics", me.Metrics)
+}
@@ -21,6 +21,7 @@ import (
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
@@ -50,9 +51,24 @@ func (ne *NATEndpoint) NATStatus(resp http.ResponseWriter, _ *http.Request, _ ht
 	utils.WriteAsJSON(ne.stateProvider.GetState().NATStatus, resp)
 }
 
+// PortMappings lists ports forwarded by the router
+// swagger:operation GET /nat/mappings NAT PortMappingsDTO
+// ---
+// summary: Lists port mappings
+// description: Lists ports which are forwarded by the router via UPnP, NAT-PMP or PCP, along with their lease expiry
+// responses:
+//   200:
+//     description: Active port mappings
+//     schema:
+//       "$ref": "#/definitions/PortMappingsDTO"
+func (ne *NATEndpoint) PortMappings(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewPortMappingsDTO(ne.stateProvider.GetState().PortMappings), resp)
+}
+
 // AddRoutesForNAT adds nat routes to given router
 func AddRoutesForNAT(router *httprouter.Router, stateProvider stateProvider) {
 	natEndpoint := NewNATEndpoint(stateProvider)
 
 	router.GET("/nat/status", natEndpoint.NATStatus)
+	router.GET("/nat/mappings", natEndpoint.PortMappings)
 }
@@ -22,9 +22,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
@@ -51,3 +53,50 @@ func Test_NATStatus_ReturnsStatusSuccessful_WithSuccessfulEvent(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.Code)
 	assert.JSONEq(t, string(expectedJSON), resp.Body.String())
 }
+
+func Test_NATStatus_ReturnsDetectedNATType(t *testing.T) {
+	provider := &mockStateProvider{stateToReturn: stateEvent.State{
+		NATStatus: contract.NATStatusDTO{
+			Status:         "successful",
+			Type:           "restricted_cone",
+			PortAllocation: "sequential",
+		},
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/nat/status", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForNAT(router, provider)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"status": "successful", "error": "", "type": "restricted_cone", "port_allocation": "sequential"}`, resp.Body.String())
+}
+
+func Test_PortMappings_ListsActiveMappings(t *testing.T) {
+	expiresAt := time.Date(2020, 11, 4, 16, 52, 8, 0, time.UTC)
+	provider := &mockStateProvider{stateToReturn: stateEvent.State{
+		PortMappings: []mapping.Mapping{
+			{Protocol: "UDP", Port: 51334, Name: "p2p", Method: "NAT-PMP(192.168.1.1)", ExpiresAt: expiresAt},
+			{Protocol: "UDP", Port: 51335, Name: "p2p", Method: "UPnP(IGDv1-IP1)", Error: "renewal failed"},
+		},
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/nat/mappings", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForNAT(router, provider)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"mappings": [
+			{"protocol": "UDP", "port": 51334, "name": "p2p", "method": "NAT-PMP(192.168.1.1)", "expires_at": "2020-11-04T16:52:08Z"},
+			{"protocol": "UDP", "port": 51335, "name": "p2p", "method": "UPnP(IGDv1-IP1)", "error": "renewal failed"}
+		]
+	}`, resp.Body.String())
+}
@@ -18,32 +18,45 @@
 package endpoints
 
 import (
+	"fmt"
 	"math/big"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/scoring"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 	"github.com/pkg/errors"
 )
 
+// sortByScore sorts proposals from the best to the worst by their score.
+const sortByScore = "score"
+
 // QualityFinder allows to fetch proposal quality data
 type QualityFinder interface {
 	ProposalsMetrics() []quality.ConnectMetric
 }
 
+// ProposalRanker allows to rank proposals by their score
+type ProposalRanker interface {
+	Rank(proposals []market.ServiceProposal, preferences scoring.Preferences) ([]scoring.RankedProposal, error)
+}
+
 type proposalsEndpoint struct {
 	proposalRepository proposal.Repository
 	qualityProvider    QualityFinder
+	ranker             ProposalRanker
 }
 
 // NewProposalsEndpoint creates and returns proposal creation endpoint
-func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvider QualityFinder) *proposalsEndpoint {
+func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvider QualityFinder, ranker ProposalRanker) *proposalsEndpoint {
 	return &proposalsEndpoint{
 		proposalRepository: proposalRepository,
 		qualityProvider:    qualityProvider,
+		ranker:             ranker,
 	}
 }
 
@@ -72,16 +85,34 @@ func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvide
 //     name: fetch_metrics
 //     description: if set to true, fetches the connection success metrics for nodes. False by default.
 //     type: boolean
+//   - in: query
+//     name: sort
+//     description: if set to "score", proposals are sorted from the best to the worst and their score components are returned
+//     type: string
+//   - in: query
+//     name: preferred_country
+//     description: country code of the provider preferred when proposals are sorted by score
+//     type: string
 // responses:
 //   200:
 //     description: List of proposals
 //     schema:
 //       "$ref": "#/definitions/ListProposalsResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
 //   500:
 //     description: Internal server error
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
 func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	sortBy := req.URL.Query().Get("sort")
+	if sortBy != "" && sortBy != sortByScore {
+		utils.SendError(resp, fmt.Errorf("unknown sort %q", sortBy), http.StatusBadRequest)
+		return
+	}
+
 	upperTimePriceBound, err := parsePriceBound(req, "upper_time_price_bound")
 	if err != nil {
 		utils.SendError(resp, err, http.StatusBadRequest)
@@ -123,8 +154,19 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 	}
 
 	proposalsRes := contract.ListProposalsResponse{Proposals: []contract.ProposalDTO{}}
-	for _, p := range proposals {
-		proposalsRes.Proposals = append(proposalsRes.Proposals, contract.NewProposalDTO(p))
+	if sortBy == sortByScore {
+		ranked, err := pe.ranker.Rank(proposals, scoring.Preferences{Country: req.URL.Query().Get("preferred_country")})
+		if err != nil {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+		for _, p := range ranked {
+			proposalsRes.Proposals = append(proposalsRes.Proposals, contract.NewRankedProposalDTO(p))
+		}
+	} else {
+		for _, p := range proposals {
+			proposalsRes.Proposals = append(proposalsRes.Proposals, contract.NewProposalDTO(p))
+		}
 	}
 
 	fetchConnectCounts := req.URL.Query().Get("fetch_metrics")
@@ -163,8 +205,8 @@ func parsePriceBound(req *http.Request, key string) (*big.Int, error) {
 }
 
 // AddRoutesForProposals attaches proposals endpoints to router
-func AddRoutesForProposals(router *httprouter.Router, proposalRepository proposal.Repository, qualityProvider QualityFinder) {
-	pe := NewProposalsEndpoint(proposalRepository, qualityProvider)
+func AddRoutesForProposals(router *httprouter.Router, proposalRepository proposal.Repository, qualityProvider QualityFinder, ranker ProposalRanker) {
+	pe := NewProposalsEndpoint(proposalRepository, qualityProvider, ranker)
 	router.GET("/proposals", pe.List)
 	router.GET("/proposals/quality", pe.Quality)
 }
@@ -18,6 +18,7 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"net/http"
@@ -26,9 +27,11 @@ import (
 	"testing"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/discovery/scoring"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -81,7 +84,7 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -149,7 +152,7 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -206,7 +209,7 @@ func TestProposalsEndpointList(t *testing.T) {
 	assert.Nil(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -278,7 +281,7 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -345,6 +348,66 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointListSortedByScore(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: serviceProposals,
+	}
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/irrelevant?sort=score&preferred_country=LT",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	ranker := &mockProposalRanker{}
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, ranker).List
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, scoring.Preferences{Country: "LT"}, ranker.recordedPreferences)
+
+	var res contract.ListProposalsResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Len(t, res.Proposals, 2)
+	assert.Equal(t, "other_provider", res.Proposals[0].ProviderID)
+	assert.Equal(t, &contract.ProposalScoreDTO{Total: 0.9, Price: 1, Quality: 0.8, History: 0.5, Location: 1}, res.Proposals[0].Score)
+	assert.Equal(t, "0xProviderId", res.Proposals[1].ProviderID)
+}
+
+func TestProposalsEndpointListRejectsUnknownSort(t *testing.T) {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/irrelevant?sort=price",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(&mockProposalRepository{}, &mockQualityProvider{}, &mockProposalRanker{}).List
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+type mockProposalRanker struct {
+	recordedPreferences scoring.Preferences
+}
+
+// Rank returns proposals in the reverse order, the first one with a higher score.
+func (m *mockProposalRanker) Rank(proposals []market.ServiceProposal, preferences scoring.Preferences) ([]scoring.RankedProposal, error) {
+	m.recordedPreferences = preferences
+	ranked := make([]scoring.RankedProposal, 0, len(proposals))
+	for i := len(proposals) - 1; i >= 0; i-- {
+		score := scoring.Score{Total: 0.4}
+		if len(ranked) == 0 {
+			score = scoring.Score{Total: 0.9, Price: 1, Quality: 0.8, History: 0.5, Location: 1}
+		}
+		ranked = append(ranked, scoring.RankedProposal{ServiceProposal: proposals[i], Score: score})
+	}
+	return ranked, nil
+}
+
 type mockQualityProvider struct{}
 
 func (m *mockQualityProvider) ProposalsMetrics() []quality.ConnectMetric {
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type shaperLimits interface {
+	Services() map[string]shaper.Profile
+	Sessions() map[string]shaper.Profile
+	SetService(serviceType string, profile shaper.Profile)
+	ClearService(serviceType string)
+	SetSession(sessionID string, profile shaper.Profile)
+	ClearSession(sessionID string)
+}
+
+type shaperEndpoint struct {
+	limits         shaperLimits
+	defaultProfile func() shaper.Profile
+}
+
+// Limits returns bandwidth limits of the node
+// swagger:operation GET /shaper/limits Shaper shaperLimits
+// ---
+// summary: Returns bandwidth limits
+// description: Returns node wide bandwidth limits and the limits overridden per service type and per session
+// responses:
+//   200:
+//     description: Bandwidth limits
+//     schema:
+//       "$ref": "#/definitions/ShaperLimitsDTO"
+func (se *shaperEndpoint) Limits(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewShaperLimitsDTO(se.defaultProfile(), se.limits.Services(), se.limits.Sessions()), resp)
+}
+
+// SetServiceLimits overrides bandwidth limits of the service type
+// swagger:operation PUT /shaper/limits/services/{type} Shaper shaperSetServiceLimits
+// ---
+// summary: Overrides bandwidth limits of the service type
+// description: Limits are applied immediately to all running sessions of the service type
+// parameters:
+//   - name: type
+//     in: path
+//     description: service type
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     description: bandwidth limits
+//     schema:
+//       $ref: "#/definitions/ShaperProfileDTO"
+// responses:
+//   200:
+//     description: Bandwidth limits
+//     schema:
+//       "$ref": "#/definitions/ShaperLimitsDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (se *shaperEndpoint) SetServiceLimits(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	profile, ok := parseShaperProfile(resp, req)
+	if !ok {
+		return
+	}
+
+	se.limits.SetService(params.ByName("type"), profile)
+	se.Limits(resp, req, params)
+}
+
+// ClearServiceLimits removes bandwidth limits override of the service type
+// swagger:operation DELETE /shaper/limits/services/{type} Shaper shaperClearServiceLimits
+// ---
+// summary: Removes bandwidth limits override of the service type
+// parameters:
+//   - name: type
+//     in: path
+//     description: service type
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Bandwidth limits
+//     schema:
+//       "$ref": "#/definitions/ShaperLimitsDTO"
+func (se *shaperEndpoint) ClearServiceLimits(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	se.limits.ClearService(params.ByName("type"))
+	se.Limits(resp, req, params)
+}
+
+// SetSessionLimits overrides bandwidth limits of the session
+// swagger:operation PUT /shaper/limits/sessions/{id} Shaper shaperSetSessionLimits
+// ---
+// summary: Overrides bandwidth limits of the consumer session
+// description: Limits are applied immediately. Sessions sharing a single tunnel (e.g. openvpn) use only service limits.
+// parameters:
+//   - name: id
+//     in: path
+//     description: session ID
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     description: bandwidth limits
+//     schema:
+//       $ref: "#/definitions/ShaperProfileDTO"
+// responses:
+//   200:
+//     description: Bandwidth limits
+//     schema:
+//       "$ref": "#/definitions/ShaperLimitsDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (se *shaperEndpoint) SetSessionLimits(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	profile, ok := parseShaperProfile(resp, req)
+	if !ok {
+		return
+	}
+
+	se.limits.SetSession(params.ByName("id"), profile)
+	se.Limits(resp, req, params)
+}
+
+// ClearSessionLimits removes bandwidth limits override of the session
+// swagger:operation DELETE /shaper/limits/sessions/{id} Shaper shaperClearSessionLimits
+// ---
+// summary: Removes bandwidth limits override of the consumer session
+// parameters:
+//   - name: id
+//     in: path
+//     description: session ID
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Bandwidth limits
+//     schema:
+//       "$ref": "#/definitions/ShaperLimitsDTO"
+func (se *shaperEndpoint) ClearSessionLimits(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	se.limits.ClearSession(params.ByName("id"))
+	se.Limits(resp, req, params)
+}
+
+func parseShaperProfile(resp http.ResponseWriter, req *http.Request) (shaper.Profile, bool) {
+	var dto contract.ShaperProfileDTO
+	if err := json.NewDecoder(req.Body).Decode(&dto); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return shaper.Profile{}, false
+	}
+
+	if errorMap := dto.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return shaper.Profile{}, false
+	}
+
+	return dto.ToProfile(), true
+}
+
+// AddRoutesForShaper attaches bandwidth shaper endpoints to router.
+func AddRoutesForShaper(router *httprouter.Router, limits shaperLimits) {
+	se := &shaperEndpoint{
+		limits:         limits,
+		defaultProfile: shaper.DefaultProfile,
+	}
+	router.GET("/shaper/limits", se.Limits)
+	router.PUT("/shaper/limits/services/:type", se.SetServiceLimits)
+	router.DELETE("/shaper/limits/services/:type", se.ClearServiceLimits)
+	router.PUT("/shaper/limits/sessions/:id", se.SetSessionLimits)
+	router.DELETE("/shaper/limits/sessions/:id", se.ClearSessionLimits)
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func shaperTestRouter(limits *shaper.Limits) *httprouter.Router {
+	router := httprouter.New()
+	se := &shaperEndpoint{
+		limits: limits,
+		defaultProfile: func() shaper.Profile {
+			return shaper.Profile{UplinkKbps: 5000, DownlinkKbps: 5000}
+		},
+	}
+	router.GET("/shaper/limits", se.Limits)
+	router.PUT("/shaper/limits/services/:type", se.SetServiceLimits)
+	router.DELETE("/shaper/limits/services/:type", se.ClearServiceLimits)
+	router.PUT("/shaper/limits/sessions/:id", se.SetSessionLimits)
+	router.DELETE("/shaper/limits/sessions/:id", se.ClearSessionLimits)
+	return router
+}
+
+func Test_Shaper_SetServiceAndSessionLimits(t *testing.T) {
+	limits := shaper.NewLimits(mocks.NewEventBus())
+	router := shaperTestRouter(limits)
+
+	req := httptest.NewRequest(http.MethodPut, "/shaper/limits/services/wireguard", strings.NewReader(`{"downlink_kbps": 1000}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/shaper/limits/sessions/session1", strings.NewReader(`{"uplink_kbps": 200, "downlink_kbps": 300}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"default": {"uplink_kbps": 5000, "downlink_kbps": 5000},
+			"services": {"wireguard": {"uplink_kbps": 0, "downlink_kbps": 1000}},
+			"sessions": {"session1": {"uplink_kbps": 200, "downlink_kbps": 300}}
+		}`,
+		resp.Body.String(),
+	)
+
+	req = httptest.NewRequest(http.MethodDelete, "/shaper/limits/services/wireguard", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, limits.Services())
+	assert.Len(t, limits.Sessions(), 1)
+}
+
+func Test_Shaper_SetLimits_ValidatesProfile(t *testing.T) {
+	limits := shaper.NewLimits(mocks.NewEventBus())
+	router := shaperTestRouter(limits)
+
+	req := httptest.NewRequest(http.MethodPut, "/shaper/limits/sessions/session1", strings.NewReader(`{"uplink_kbps": -1}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"uplink_kbps": [{"code": "invalid", "message": "Limit can not be negative"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+
+	req = httptest.NewRequest(http.MethodPut, "/shaper/limits/sessions/session1", strings.NewReader(`{`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Empty(t, limits.Sessions())
+}
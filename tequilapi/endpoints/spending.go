@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type spendingLimiter interface {
+	Limits() pingpong.SpendingLimits
+	SetLimits(limits pingpong.SpendingLimits)
+	Spent() (daily *big.Int, monthly *big.Int, err error)
+}
+
+type spendingEndpoint struct {
+	limiter spendingLimiter
+	config  configProvider
+}
+
+// Limits returns consumer spending limits
+// swagger:operation GET /spending-limits Spending spendingLimits
+// ---
+// summary: Returns consumer spending limits
+// description: Returns consumer spending limits and the amounts spent during the current day and month
+// responses:
+//   200:
+//     description: Spending limits
+//     schema:
+//       "$ref": "#/definitions/SpendingLimitsDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (se *spendingEndpoint) Limits(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	daily, monthly, err := se.limiter.Spent()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(contract.NewSpendingLimitsDTO(se.limiter.Limits(), daily, monthly), resp)
+}
+
+// SetLimits sets consumer spending limits
+// swagger:operation PUT /spending-limits Spending spendingSetLimits
+// ---
+// summary: Sets consumer spending limits
+// description: Limits are applied to the next invoice paid and persisted to the user configuration
+// parameters:
+//   - in: body
+//     name: body
+//     description: spending limits
+//     schema:
+//       $ref: "#/definitions/SpendingLimitsRequest"
+// responses:
+//   200:
+//     description: Spending limits
+//     schema:
+//       "$ref": "#/definitions/SpendingLimitsDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (se *spendingEndpoint) SetLimits(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	var dto contract.SpendingLimitsRequest
+	if err := json.NewDecoder(req.Body).Decode(&dto); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errorMap := dto.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	limits := dto.ToLimits()
+	se.limiter.SetLimits(limits)
+
+	se.config.SetUser(config.FlagPaymentsConsumerLimitSession.Name, limits.Session.String())
+	se.config.SetUser(config.FlagPaymentsConsumerLimitDaily.Name, limits.Daily.String())
+	se.config.SetUser(config.FlagPaymentsConsumerLimitMonthly.Name, limits.Monthly.String())
+	se.config.SetUser(config.FlagPaymentsConsumerLimitAlertThreshold.Name, limits.AlertThreshold)
+	if err := se.config.SaveUserConfig(); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	se.Limits(resp, req, params)
+}
+
+// AddRoutesForSpendingLimits attaches consumer spending limits endpoints to router.
+func AddRoutesForSpendingLimits(router *httprouter.Router, limiter spendingLimiter) {
+	se := &spendingEndpoint{
+		limiter: limiter,
+		config:  config.Current,
+	}
+	router.GET("/spending-limits", se.Limits)
+	router.PUT("/spending-limits", se.SetLimits)
+}
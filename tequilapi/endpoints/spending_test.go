@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSpendingLimiter struct {
+	limits pingpong.SpendingLimits
+}
+
+func (m *mockSpendingLimiter) Limits() pingpong.SpendingLimits {
+	return m.limits
+}
+
+func (m *mockSpendingLimiter) SetLimits(limits pingpong.SpendingLimits) {
+	m.limits = limits
+}
+
+func (m *mockSpendingLimiter) Spent() (*big.Int, *big.Int, error) {
+	return big.NewInt(10), big.NewInt(20), nil
+}
+
+type mockUserConfig struct {
+	configProvider
+	user  map[string]interface{}
+	saved bool
+}
+
+func (m *mockUserConfig) SetUser(key string, value interface{}) {
+	m.user[key] = value
+}
+
+func (m *mockUserConfig) SaveUserConfig() error {
+	m.saved = true
+	return nil
+}
+
+func spendingTestRouter(limiter spendingLimiter, config configProvider) *httprouter.Router {
+	router := httprouter.New()
+	se := &spendingEndpoint{limiter: limiter, config: config}
+	router.GET("/spending-limits", se.Limits)
+	router.PUT("/spending-limits", se.SetLimits)
+	return router
+}
+
+func Test_SpendingLimits_SetsAndPersistsLimits(t *testing.T) {
+	limiter := &mockSpendingLimiter{}
+	config := &mockUserConfig{user: map[string]interface{}{}}
+	router := spendingTestRouter(limiter, config)
+
+	req := httptest.NewRequest(http.MethodPut, "/spending-limits", strings.NewReader(`{"daily": 3000, "alert_threshold": 0.9}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{"session": 0, "daily": 3000, "monthly": 0, "alert_threshold": 0.9, "spent_daily": 10, "spent_monthly": 20}`,
+		resp.Body.String(),
+	)
+
+	assert.Equal(t, big.NewInt(3000), limiter.limits.Daily)
+	assert.True(t, config.saved)
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"payments.consumer.limit.session":         "0",
+			"payments.consumer.limit.daily":           "3000",
+			"payments.consumer.limit.monthly":         "0",
+			"payments.consumer.limit.alert-threshold": 0.9,
+		},
+		config.user,
+	)
+}
+
+func Test_SpendingLimits_ValidatesLimits(t *testing.T) {
+	router := spendingTestRouter(&mockSpendingLimiter{}, &mockUserConfig{user: map[string]interface{}{}})
+
+	req := httptest.NewRequest(http.MethodPut, "/spending-limits", strings.NewReader(`{"session": -1, "alert_threshold": 2}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"session": [{"code": "invalid", "message": "Limit can not be negative"}],
+				"alert_threshold": [{"code": "invalid", "message": "Alert threshold must be between 0 and 1"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
@@ -29,6 +29,7 @@ import (
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/eventbus"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -50,6 +51,8 @@ const (
 	ServiceStatusEvent EventType = "service-status"
 	// StateChangeEvent represents the state change
 	StateChangeEvent EventType = "state-change"
+	// SpendingLimitEvent represents the consumer spending limit alert
+	SpendingLimitEvent EventType = "spending-limit"
 )
 
 // Handler represents an sse handler
@@ -86,7 +89,10 @@ func (h *Handler) Subscribe(bus eventbus.Subscriber) error {
 		return err
 	}
 	err = bus.Subscribe(stateEvent.AppTopicState, h.ConsumeStateEvent)
-	return err
+	if err != nil {
+		return err
+	}
+	return bus.Subscribe(pingpongEvent.AppTopicSpendingLimit, h.ConsumeSpendingLimitEvent)
 }
 
 // Sub subscribes a user to sse
@@ -231,6 +237,7 @@ func mapState(event stateEvent.State) stateRes {
 			Earnings:           identity.Earnings,
 			EarningsTotal:      identity.EarningsTotal,
 			Stake:              new(big.Int),
+			Hermeses:           contract.NewIdentityHermesDTOs(identity.HermesBalances, identity.HermesEarnings),
 		}
 	}
 
@@ -267,3 +274,11 @@ func (h *Handler) ConsumeStateEvent(event stateEvent.State) {
 		Payload: mapState(event),
 	})
 }
+
+// ConsumeSpendingLimitEvent consumes the consumer spending limit alert
+func (h *Handler) ConsumeSpendingLimitEvent(e pingpongEvent.AppEventSpendingLimit) {
+	h.send(Event{
+		Type:    SpendingLimitEvent,
+		Payload: contract.NewSpendingLimitAlertDTO(e),
+	})
+}
@@ -33,7 +33,9 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
@@ -207,6 +209,12 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 			Balance:            big.NewInt(50),
 			Earnings:           big.NewInt(1),
 			EarningsTotal:      big.NewInt(100),
+			HermesBalances: map[common.Address]*big.Int{
+				common.HexToAddress("0x000000000000000000000000000000000000000b"): big.NewInt(50),
+			},
+			HermesEarnings: map[common.Address]pingpongEvent.Earnings{
+				common.HexToAddress("0x000000000000000000000000000000000000000b"): {LifetimeBalance: big.NewInt(100), UnsettledBalance: big.NewInt(1)},
+			},
 		},
 	}
 	h.ConsumeStateEvent(changedState)
@@ -244,7 +252,15 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
         "balance": 50,
         "earnings": 1,
 		"earnings_total": 100,
-		"stake": 0
+		"stake": 0,
+		"hermeses": [
+		  {
+		    "hermes_id": "0x000000000000000000000000000000000000000b",
+		    "balance": 50,
+		    "earnings": 1,
+		    "earnings_total": 100
+		  }
+		]
       }
     ],
     "channels": []
@@ -258,3 +274,24 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 
 	<-serveExit
 }
+
+func TestHandler_SendsSpendingLimitAlerts(t *testing.T) {
+	h := NewSSEHandler(&mockStateProvider{})
+
+	h.ConsumeSpendingLimitEvent(pingpongEvent.AppEventSpendingLimit{
+		ConsumerID: identity.FromAddress("0x1"),
+		SessionID:  "session1",
+		Period:     pingpongEvent.SpendingPeriodDaily,
+		Limit:      big.NewInt(100),
+		Spent:      big.NewInt(80),
+	})
+
+	assert.JSONEq(
+		t,
+		`{
+			"payload": {"consumer_id": "0x1", "session_id": "session1", "period": "daily", "limit": 100, "spent": 80, "reached": false},
+			"type": "spending-limit"
+		}`,
+		<-h.messages,
+	)
+}
@@ -53,6 +53,7 @@ type promiseSettler interface {
 	SettleWithBeneficiary(chainID int64, id identity.Identity, beneficiary, hermesID common.Address) error
 	GetHermesFee(chainID int64, id common.Address) (uint16, error)
 	SettleIntoStake(chainID int64, providerID identity.Identity, hermesID common.Address) error
+	Forecast(chainID int64, providerID identity.Identity, hermesID common.Address) (pingpong.SettlementForecast, error)
 }
 
 type settlementHistoryProvider interface {
@@ -320,6 +321,62 @@ func (te *transactorEndpoint) SettlementHistory(resp http.ResponseWriter, req *h
 	utils.WriteAsJSON(response, resp)
 }
 
+// swagger:operation GET /transactor/settle/forecast SettlementForecast
+// ---
+// summary: Returns settlement forecast
+// description: Projects provider earnings from the unsettled balance growth and estimates when and at which amount the next settlement happens
+// parameters:
+//   - in: query
+//     name: provider_id
+//     description: Provider identity
+//     type: string
+//     required: true
+//   - in: query
+//     name: hermes_id
+//     description: Hermes ID, the configured hermes is used if empty
+//     type: string
+// responses:
+//   200:
+//     description: Settlement forecast
+//     schema:
+//       "$ref": "#/definitions/SettlementForecastDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Provider has no channel with hermes
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (te *transactorEndpoint) SettlementForecast(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	providerID := req.URL.Query().Get("provider_id")
+	if providerID == "" {
+		utils.SendError(resp, errors.New("provider_id is required"), http.StatusBadRequest)
+		return
+	}
+	hermesID := te.hermesAddress
+	if hermes := req.URL.Query().Get("hermes_id"); hermes != "" {
+		hermesID = common.HexToAddress(hermes)
+	}
+
+	chainID := config.GetInt64(config.FlagChainID)
+	forecast, err := te.promiseSettler.Forecast(chainID, identity.FromAddress(providerID), hermesID)
+	if err == pingpong.ErrNothingToSettle {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSettlementForecastDTO(forecast), resp)
+}
+
 // swagger:operation POST /transactor/stake/decrease Decrease Stake
 // ---
 // summary: Decreases stake
@@ -431,6 +488,7 @@ func AddRoutesForTransactor(router *httprouter.Router, transactor Transactor, pr
 	router.POST("/transactor/settle/sync", te.SettleSync)
 	router.POST("/transactor/settle/async", te.SettleAsync)
 	router.GET("/transactor/settle/history", te.SettlementHistory)
+	router.GET("/transactor/settle/forecast", te.SettlementForecast)
 	router.POST("/transactor/stake/increase/sync", te.SettleIntoStakeSync)
 	router.POST("/transactor/stake/increase/async", te.SettleIntoStakeAsync)
 	router.POST("/transactor/stake/decrease", te.DecreaseStake)
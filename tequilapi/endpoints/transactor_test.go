@@ -91,6 +91,50 @@ func Test_Get_TransactorFees(t *testing.T) {
 	assert.JSONEq(t, `{"registration":1, "settlement":1, "hermes":11, "decreaseStake":1}`, resp.Body.String())
 }
 
+func Test_SettlementForecast(t *testing.T) {
+	router := httprouter.New()
+	next := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	AddRoutesForTransactor(router, &registry.Transactor{}, &mockSettler{
+		forecastToReturn: pingpong.SettlementForecast{
+			ProviderID:       identity.FromAddress("0x0000000000000000000000000000000000000001"),
+			HermesID:         common.HexToAddress("0x0000000000000000000000000000000000000002"),
+			UnsettledBalance: big.NewInt(100),
+			EarningsPerHour:  big.NewInt(10),
+			EarningsPerDay:   big.NewInt(240),
+			EarningsPerMonth: big.NewInt(7200),
+			TransactorFee:    big.NewInt(5),
+			HermesFee:        250,
+			SettleAt:         big.NewInt(200),
+			SettlementFees:   big.NewInt(10),
+			NextSettlement:   &next,
+		},
+	}, &settlementHistoryProviderMock{}, common.Address{})
+
+	req := httptest.NewRequest(http.MethodGet, "/transactor/settle/forecast?provider_id=0x0000000000000000000000000000000000000001", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"provider_id": "0x0000000000000000000000000000000000000001",
+		"hermes_id": "0x0000000000000000000000000000000000000002",
+		"unsettled_balance": 100,
+		"earnings_per_hour": 10,
+		"earnings_per_day": 240,
+		"earnings_per_month": 7200,
+		"transactor_fee": 5,
+		"hermes_fee": 250,
+		"settle_at": 200,
+		"settlement_fees": 10,
+		"next_settlement_at": "2020-10-01T12:00:00Z"
+	}`, resp.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/transactor/settle/forecast", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
 func Test_SettleAsync_OK(t *testing.T) {
 	mockResponse := ""
 	server := newTestTransactorServer(http.StatusAccepted, mockResponse)
@@ -337,6 +381,8 @@ type mockSettler struct {
 
 	feeToReturn      uint16
 	feeErrorToReturn error
+
+	forecastToReturn pingpong.SettlementForecast
 }
 
 func (ms *mockSettler) ForceSettle(_ int64, _ identity.Identity, _ common.Address) error {
@@ -355,6 +401,10 @@ func (ms *mockSettler) GetHermesFee(_ int64, _ common.Address) (uint16, error) {
 	return ms.feeToReturn, ms.feeErrorToReturn
 }
 
+func (ms *mockSettler) Forecast(_ int64, _ identity.Identity, _ common.Address) (pingpong.SettlementForecast, error) {
+	return ms.forecastToReturn, ms.errToReturn
+}
+
 type settlementHistoryProviderMock struct {
 	settlementHistoryToReturn []pingpong.SettlementHistoryEntry
 	errToReturn               error
@@ -83,6 +83,7 @@ func (t *Tracer) EndStage(key string) {
 	s, ok := t.findStage(key)
 	if !ok {
 		log.Error().Msgf("Stage %s was not started", key)
+		return
 	}
 
 	s.end = time.Now()
@@ -109,6 +110,31 @@ func (t *Tracer) Finish(eventPublisher eventbus.Publisher, id string) string {
 	return strings.Join(strs, ", ")
 }
 
+// FailStage records the error the stage for given key failed with, the stage still has to be ended.
+func (t *Tracer) FailStage(key string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.findStage(key)
+	if !ok {
+		log.Error().Msgf("Stage %s was not started", key)
+		return
+	}
+	s.err = err
+}
+
+// Stages returns the traced stages in the order they were started.
+func (t *Tracer) Stages() []Stage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stages := make([]Stage, len(t.stages))
+	for i, s := range t.stages {
+		stages[i] = Stage{Key: s.key, Start: s.start, End: s.end, Err: s.err}
+	}
+	return stages
+}
+
 func (t *Tracer) findStage(key string) (*stage, bool) {
 	for _, s := range t.stages {
 		if s.key == key {
@@ -135,6 +161,14 @@ func (t *Tracer) publishStageEvent(eventPublisher eventbus.Publisher, id string,
 type stage struct {
 	key        string
 	start, end time.Time
+	err        error
+}
+
+// Stage represents a traced stage, its end time is zero if the stage did not end.
+type Stage struct {
+	Key        string
+	Start, End time.Time
+	Err        error
 }
 
 // Event represents a published Trace event.
@@ -108,6 +108,79 @@ func ExcludeRoute(ip net.IP) error {
 	return excludeRoute(ip, gw)
 }
 
+// ExcludeSubnet routes given subnet around VPN tunnel via default gateway.
+func ExcludeSubnet(subnet net.IPNet) error {
+	gw, err := gateway.DiscoverGateway()
+	if err != nil {
+		return fmt.Errorf("failed to get default gateway: %w", err)
+	}
+
+	if defaultRouteManager != nil {
+		err := defaultRouteManager.db.Store(routeRecordBucket, &route{
+			Record: strings.Join([]string{subnet.String(), gw.String()}, routeRecordDelimeter),
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to save %s record", routeRecordBucket)
+		}
+	}
+
+	return excludeSubnet(subnet, gw)
+}
+
+// AddRoute routes given subnet through VPN tunnel.
+func AddRoute(subnet net.IPNet, iface string) error {
+	return addRoute(subnet, iface)
+}
+
+// DeleteRoute removes route of the given subnet, added either through VPN tunnel or around it.
+func DeleteRoute(subnet net.IPNet) error {
+	if defaultRouteManager != nil {
+		var records []route
+		if err := defaultRouteManager.db.GetAllFrom(routeRecordBucket, &records); err != nil {
+			log.Error().Err(err).Msgf("Failed to get %s records", routeRecordBucket)
+		}
+		for _, r := range records {
+			if strings.HasPrefix(r.Record, subnet.String()+routeRecordDelimeter) {
+				if err := defaultRouteManager.db.Delete(routeRecordBucket, &r); err != nil {
+					log.Error().Err(err).Msgf("Failed to delete %s record", r.Record)
+				}
+			}
+		}
+	}
+
+	return removeRoute(subnet)
+}
+
+// ConfigureRoutes routes consumer traffic through VPN tunnel: endpoint of the tunnel is always excluded,
+// only included subnets are routed through the tunnel when given (all traffic otherwise)
+// and excluded subnets are routed around the tunnel.
+func ConfigureRoutes(iface string, endpoint net.IP, include, exclude []net.IPNet) error {
+	// Loopback endpoint is a local relay of the p2p stream transport, it is never routed through the tunnel.
+	if !endpoint.IsLoopback() {
+		if err := ExcludeRoute(endpoint); err != nil {
+			return fmt.Errorf("could not exclude route %s: %w", endpoint.String(), err)
+		}
+	}
+
+	if len(include) == 0 {
+		if err := AddDefaultRoute(iface); err != nil {
+			return fmt.Errorf("could not add default route for %s: %w", iface, err)
+		}
+	}
+	for _, subnet := range include {
+		if err := AddRoute(subnet, iface); err != nil {
+			return fmt.Errorf("could not add route %s for %s: %w", subnet.String(), iface, err)
+		}
+	}
+
+	for _, subnet := range exclude {
+		if err := ExcludeSubnet(subnet); err != nil {
+			return fmt.Errorf("could not exclude route %s: %w", subnet.String(), err)
+		}
+	}
+	return nil
+}
+
 // AddDefaultRoute adds default VPN tunnel route.
 func AddDefaultRoute(iface string) error {
 	return addDefaultRoute(iface)
@@ -32,10 +32,22 @@ func excludeRoute(ip, gw net.IP) error {
 	return cmdutil.SudoExec("route", "add", "-host", ip.String(), gw.String())
 }
 
+func excludeSubnet(subnet net.IPNet, gw net.IP) error {
+	return cmdutil.SudoExec("route", "add", "-net", subnet.String(), gw.String())
+}
+
+func addRoute(subnet net.IPNet, iface string) error {
+	return cmdutil.SudoExec("route", "add", "-net", subnet.String(), "-interface", iface)
+}
+
 func deleteRoute(ip, gw string) error {
 	return cmdutil.SudoExec("route", "delete", ip, gw)
 }
 
+func removeRoute(subnet net.IPNet) error {
+	return cmdutil.SudoExec("route", "delete", "-net", subnet.String())
+}
+
 func addDefaultRoute(iface string) error {
 	if err := cmdutil.SudoExec("route", "add", "-net", "0.0.0.0/1", "-interface", iface); err != nil {
 		return err
@@ -35,10 +35,22 @@ func excludeRoute(ip, gw net.IP) error {
 	return cmdutil.SudoExec("ip", "route", "add", ip.String(), "via", gw.String())
 }
 
+func excludeSubnet(subnet net.IPNet, gw net.IP) error {
+	return cmdutil.SudoExec("ip", "route", "replace", subnet.String(), "via", gw.String())
+}
+
+func addRoute(subnet net.IPNet, iface string) error {
+	return cmdutil.SudoExec("ip", "route", "replace", subnet.String(), "dev", iface)
+}
+
 func deleteRoute(ip, gw string) error {
 	return cmdutil.SudoExec("ip", "route", "delete", ip, "via", gw)
 }
 
+func removeRoute(subnet net.IPNet) error {
+	return cmdutil.SudoExec("ip", "route", "delete", subnet.String())
+}
+
 func addDefaultRoute(iface string) error {
 	if err := cmdutil.SudoExec("ip", "route", "add", "0.0.0.0/1", "dev", iface); err != nil {
 		return err
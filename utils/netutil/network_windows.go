@@ -22,6 +22,7 @@ import (
 	"net"
 	"os/exec"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -37,8 +38,27 @@ func excludeRoute(ip, gw net.IP) error {
 	return errors.Wrap(err, string(out))
 }
 
+func excludeSubnet(subnet net.IPNet, gw net.IP) error {
+	out, err := exec.Command("powershell", "-Command", "route add "+subnet.String()+" "+gw.String()).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
+func addRoute(subnet net.IPNet, name string) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	out, err := exec.Command("powershell", "-Command", "route add "+subnet.String()+" "+gw+" if "+id).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
 func deleteRoute(ip, gw string) error {
-	out, err := exec.Command("powershell", "-Command", "route delete "+ip+"/32").CombinedOutput()
+	// Excluded subnets are stored with the mask, single IPs without it.
+	if !strings.Contains(ip, "/") {
+		ip += "/32"
+	}
+	out, err := exec.Command("powershell", "-Command", "route delete "+ip).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to delete route: %w, %s", err, string(out))
 	}
@@ -46,6 +66,10 @@ func deleteRoute(ip, gw string) error {
 	return nil
 }
 
+func removeRoute(subnet net.IPNet) error {
+	return deleteRoute(subnet.String(), "")
+}
+
 func addDefaultRoute(name string) error {
 	id, gw, err := interfaceInfo(name)
 	if err != nil {